@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 )
 
@@ -33,6 +34,18 @@ type HwMgrAdaptorIntf interface {
 	SetupAdaptor(mgr ctrl.Manager) error
 	HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error)
 	HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error
+	RetainNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error
+	RestoreNodeBMCSecret(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool, node *hwmgmtv1alpha1.Node) error
+	Capabilities(hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.AdaptorCapability
+	GetCapacity(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*pluginv1alpha1.CapacityStatus, error)
+	PlanConsolidation(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, resourcePoolIds []string) ([]pluginv1alpha1.ConsolidationMove, error)
+	ExecuteConsolidationMove(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, move *pluginv1alpha1.ConsolidationMove) error
+	DecommissionNode(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool, node *hwmgmtv1alpha1.Node, report *utils.DecommissionReport) error
+	ReleaseNode(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool, node *hwmgmtv1alpha1.Node) error
+	GetFreeNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, query utils.FreeNodeQuery) ([]utils.FreeNode, error)
+	SetNodePowerState(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool, node *hwmgmtv1alpha1.Node, state utils.PowerState) error
+	SelfTest(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.SelfTestStep
+	CheckAllocations(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool, nodes []hwmgmtv1alpha1.Node) ([]pluginv1alpha1.ConsistencyIssue, error)
 }
 
 // Define the HwMgrAdaptor structures
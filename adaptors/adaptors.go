@@ -18,10 +18,16 @@ package adaptors
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
@@ -29,6 +35,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -36,28 +43,86 @@ import (
 	// Import the adaptors
 	dellhwmgr "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/loopback"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/rest"
 )
 
 // Supported adaptor IDs
 const (
 	LoopbackAdaptorID  = "loopback"
 	DellHwMgrAdaptorID = "dell-hwmgr"
+	RestAdaptorID      = "rest"
 )
 
+// SupportedAdaptorIDs is the registry of adaptors built into the plugin. Each adaptor ships its own RBAC, generated
+// from the markers in its package, so a deployment need only grant the permissions of the adaptors it enables.
+var SupportedAdaptorIDs = []string{LoopbackAdaptorID, DellHwMgrAdaptorID, RestAdaptorID}
+
+// ErrUnsupportedAdaptor is returned when a HardwareManager specifies an adaptor that is not setup in this deployment
+var ErrUnsupportedAdaptor = errors.New("unsupported adaptor ID")
+
+// ParseEnabledAdaptors parses a comma-separated list of adaptor IDs, returning nil if the list is empty
+func ParseEnabledAdaptors(value string) ([]string, error) {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if !slices.Contains(SupportedAdaptorIDs, id) {
+			return nil, fmt.Errorf("unsupported adaptor ID %q, expected one of: %s", id, strings.Join(SupportedAdaptorIDs, ", "))
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 // HwMgrAdaptorController
 type HwMgrAdaptorController struct {
 	client.Client
 	Scheme    *runtime.Scheme
 	Logger    *slog.Logger
 	Namespace string
-	adaptors  map[string]adaptorinterface.HwMgrAdaptorIntf
+	// EmulatedBMCAddr is the bind address of the emulated BMC server of the loopback adaptor
+	EmulatedBMCAddr string
+	// EnabledAdaptors are the IDs of the adaptors to setup. All supported adaptors are setup if empty.
+	EnabledAdaptors []string
+	// Recorder emits the events for NodePools, such as on a stall
+	Recorder record.EventRecorder
+	adaptors map[string]adaptorinterface.HwMgrAdaptorIntf
+	// lastErrors holds the last error returned by the adaptor for each NodePool, keyed by namespaced name
+	lastErrors sync.Map
 }
 
 func (c *HwMgrAdaptorController) SetupWithManager(mgr ctrl.Manager) error {
-	// Setup the supported adaptors
+	enabled := c.EnabledAdaptors
+	if len(enabled) == 0 {
+		enabled = SupportedAdaptorIDs
+	}
+
+	// The adaptors make their changes as the tenant of the NodePool being processed, when impersonation is enabled
+	tenantClient := utils.NewTenantClient(c.Client, mgr.GetConfig())
+
+	// Setup the enabled adaptors. Each adaptor applies its changes as a field owner of its own, so the fields it
+	// manages can be told apart from those of the other adaptors and controllers.
 	c.adaptors = make(map[string]adaptorinterface.HwMgrAdaptorIntf)
-	c.adaptors[LoopbackAdaptorID] = loopback.NewAdaptor(c.Client, c.Scheme, c.Logger, c.Namespace)
-	c.adaptors[DellHwMgrAdaptorID] = dellhwmgr.NewAdaptor(c.Client, c.Scheme, c.Logger, c.Namespace)
+	for _, id := range enabled {
+		adaptorClient := utils.NewFieldOwnerClient(tenantClient, utils.AdaptorFieldOwner(id))
+		switch id {
+		case LoopbackAdaptorID:
+			loopbackAdaptor := loopback.NewAdaptor(adaptorClient, c.Scheme, c.Logger, c.Namespace)
+			loopbackAdaptor.EmulatedBMCAddr = c.EmulatedBMCAddr
+			c.adaptors[LoopbackAdaptorID] = loopbackAdaptor
+		case DellHwMgrAdaptorID:
+			c.adaptors[DellHwMgrAdaptorID] = dellhwmgr.NewAdaptor(adaptorClient, c.Scheme, c.Logger, c.Namespace)
+		case RestAdaptorID:
+			c.adaptors[RestAdaptorID] = rest.NewAdaptor(adaptorClient, c.Scheme, c.Logger, c.Namespace)
+		default:
+			return fmt.Errorf("unsupported adaptor ID: %s", id)
+		}
+	}
+	c.Logger.Info("Enabled adaptors", slog.Any("ids", enabled))
 
 	for id, adaptor := range c.adaptors {
 		if err := adaptor.SetupAdaptor(mgr); err != nil {
@@ -90,6 +155,10 @@ func (c *HwMgrAdaptorController) getHwMgr(ctx context.Context, nodepool *hwmgmtv
 		if hwmgr.Spec.DellData == nil {
 			return nil, fmt.Errorf("required config data missing from HardwareManager: name=%s", hwmgr.Name)
 		}
+	case pluginv1alpha1.SupportedAdaptors.Rest:
+		if hwmgr.Spec.RestData == nil {
+			return nil, fmt.Errorf("required config data missing from HardwareManager: name=%s", hwmgr.Name)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported adaptorId (%s) HardwareManager: name=%s", hwmgr.Spec.AdaptorID, hwmgr.Name)
 	}
@@ -97,33 +166,111 @@ func (c *HwMgrAdaptorController) getHwMgr(ctx context.Context, nodepool *hwmgmtv
 	return hwmgr, nil
 }
 
+// getAdaptor returns the adaptor specified by the HardwareManager, or an error wrapping ErrUnsupportedAdaptor if it is
+// not setup in this deployment
+func (c *HwMgrAdaptorController) getAdaptor(hwmgr *pluginv1alpha1.HardwareManager) (adaptorinterface.HwMgrAdaptorIntf, error) {
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+	adaptor, exists := c.adaptors[adaptorID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAdaptor, adaptorID)
+	}
+	return adaptor, nil
+}
+
 // HandleNodePool calls the applicable adaptor handler to process the NodePool CR
 func (c *HwMgrAdaptorController) HandleNodePool(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", nodepool.Spec.HwMgrId))
+
+	if utils.IsNodePoolPauseRequested(nodepool) {
+		if !utils.IsNodePoolPaused(nodepool) {
+			c.Logger.InfoContext(ctx, "Pausing NodePool processing")
+			if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
+				utils.NodePoolPaused, utils.ReasonPaused, metav1.ConditionTrue,
+				"Processing paused by annotation "+utils.PausedAnnotation); err != nil {
+				return utils.RequeueWithShortInterval(),
+					fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+			}
+		}
+
+		// Processing resumes when the annotation is removed, which triggers a new reconcile
+		return utils.DoNotRequeue(), nil
+	}
+
+	if utils.IsNodePoolPaused(nodepool) {
+		c.Logger.InfoContext(ctx, "Resuming NodePool processing")
+		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
+			utils.NodePoolPaused, utils.ReasonResumed, metav1.ConditionFalse,
+			"Processing resumed"); err != nil {
+			return utils.RequeueWithShortInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+	}
+
 	hwmgr, err := c.getHwMgr(ctx, nodepool)
 	if err != nil {
-		c.Logger.Error("failed to get adaptor instance", slog.String("error", err.Error()))
+		c.Logger.ErrorContext(ctx, "failed to get adaptor instance", slog.String("error", err.Error()))
 
-		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-			"Unable to find HardwareManager instance: "+nodepool.Spec.HwMgrId); err != nil {
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Failed, "Unable to find HardwareManager instance: "+nodepool.Spec.HwMgrId).
+			Update(ctx, c.Client); err != nil {
+			return utils.RequeueWithMediumInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+
+		return utils.DoNotRequeue(), nil
+	}
+
+	selected, err := utils.HardwareManagerSelectsNodePool(hwmgr, nodepool)
+	if !selected {
+		message := "NodePool does not match the nodePoolSelector of HardwareManager " + hwmgr.Name
+		if err != nil {
+			message = err.Error()
+		}
+		c.Logger.InfoContext(ctx, "Skipping NodePool not selected by HardwareManager", slog.String("reason", message))
+
+		// The Provisioned condition is left as is, so that the NodePool is processed from where it was once selected
+		if err := utils.UpdateNodePoolNotSelectedCondition(ctx, c.Client, nodepool, utils.ReasonNotSelected,
+			message); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 		}
 
+		// Processing resumes when the NodePool labels are updated, which triggers a new reconcile
 		return utils.DoNotRequeue(), nil
 	}
 
+	if !utils.HardwareManagerWatchesNodePool(hwmgr, nodepool) {
+		message := fmt.Sprintf("NodePool namespace %s is not in the watchNamespaces of HardwareManager %s", nodepool.Namespace, hwmgr.Name)
+		c.Logger.InfoContext(ctx, "Skipping NodePool outside HardwareManager watch namespaces", slog.String("reason", message))
+
+		if err := utils.UpdateNodePoolNotSelectedCondition(ctx, c.Client, nodepool, utils.ReasonOutsideWatchNamespaces,
+			message); err != nil {
+			return utils.RequeueWithMediumInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+
+		// Processing resumes when the HardwareManager is updated, which triggers a new reconcile
+		return utils.DoNotRequeue(), nil
+	}
+
+	if err := utils.UpdateNodePoolNotSelectedCondition(ctx, c.Client, nodepool, utils.ReasonSelected, ""); err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
 	adaptorID := string(hwmgr.Spec.AdaptorID)
 
-	// Validate the specified adaptor ID
-	adaptor, exists := c.adaptors[adaptorID]
-	if !exists {
-		c.Logger.Error("unsupported adaptor ID", "adaptorID", adaptorID)
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		c.Logger.ErrorContext(ctx, "unsupported adaptor ID", slog.String("adaptorID", adaptorID))
 
-		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-			"Unsupported adaptor ID specified: "+adaptorID); err != nil {
+		message := "Unsupported adaptor ID specified: " + adaptorID
+		if slices.Contains(SupportedAdaptorIDs, adaptorID) {
+			message = "Adaptor not enabled in this deployment: " + adaptorID
+		}
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Failed, message).
+			Update(ctx, c.Client); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 		}
@@ -131,7 +278,60 @@ func (c *HwMgrAdaptorController) HandleNodePool(ctx context.Context, nodepool *h
 		return utils.DoNotRequeue(), nil
 	}
 
+	if utils.IsAdaptorDisabled(hwmgr.Spec.AdaptorID) {
+		// Processing resumes on a later requeue once the adaptor is re-enabled in the PluginConfig
+		c.Logger.InfoContext(ctx, "Adaptor disabled by PluginConfig, skipping NodePool processing",
+			slog.String("adaptorID", adaptorID))
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	maintenancePools := utils.GetNodePoolMaintenancePools(hwmgr, nodepool)
+	if err := utils.UpdateNodePoolMaintenanceCondition(ctx, c.Client, nodepool, maintenancePools); err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	if len(maintenancePools) > 0 && !utils.IsNodePoolProvisionedCompleted(nodepool) {
+		// A provisioned NodePool continues to be processed, as the free nodes of the pools are excluded from
+		// allocation by the adaptor. Processing resumes when the HardwareManager is updated, which triggers a new
+		// reconcile.
+		c.Logger.InfoContext(ctx, "Holding NodePool for resource pool maintenance",
+			slog.Any("maintenancePools", maintenancePools))
+		return utils.DoNotRequeue(), nil
+	}
+
+	if result, held, err := c.checkProvisioningWindow(ctx, hwmgr, nodepool); held || err != nil {
+		return result, err
+	}
+
+	if result, held, err := c.checkPowerBudget(ctx, hwmgr, nodepool); held || err != nil {
+		return result, err
+	}
+
+	ctx, authorized, err := c.authorizeTenant(ctx, hwmgr, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+	if !authorized {
+		// Changes to RBAC are not watched, so the access is checked again on a later requeue
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	if err := c.recordNodePoolPlan(ctx, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
 	result, err := adaptor.HandleNodePool(ctx, hwmgr, nodepool)
+	c.recordBackendError(nodepool, err)
+	if stallErr := c.checkNodePoolStall(ctx, hwmgr, nodepool); stallErr != nil {
+		c.Logger.ErrorContext(ctx, "failed to check NodePool for stall", slog.String("error", stallErr.Error()))
+	}
+	if delay, throttled := sdk.GetThrottleDelay(err); throttled {
+		// Requeue for the delay requested by the backend, rather than the error backoff of the controller
+		c.Logger.InfoContext(ctx, "Backend is rate limiting requests, requeuing NodePool",
+			slog.Duration("retryAfter", delay), slog.String("error", err.Error()))
+		sdk.RecordThrottleRequeue(hwmgr.Name)
+		return utils.RequeueWithCustomInterval(delay), nil
+	}
 	if err != nil {
 		return result, fmt.Errorf("failed HandleNodePool for adaptorID %s: %w", adaptorID, err)
 	}
@@ -146,6 +346,92 @@ func (c *HwMgrAdaptorController) HandleNodePool(ctx context.Context, nodepool *h
 	return result, nil
 }
 
+// checkProvisioningWindow holds a NodePool with pending hardware changes outside of its provisioning windows, returning
+// true if the NodePool is held. A NodePool is requeued for the start of the next window, as the passing of time does
+// not trigger a reconcile.
+func (c *HwMgrAdaptorController) checkProvisioningWindow(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, bool, error) {
+
+	windows, err := utils.GetProvisioningWindows(hwmgr, nodepool)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), true, fmt.Errorf("invalid provisioning windows: %w", err)
+	}
+	open, next, err := utils.IsWithinProvisioningWindow(windows, time.Now())
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), true, fmt.Errorf("invalid provisioning windows: %w", err)
+	}
+
+	// A provisioned NodePool without a pending spec change is not waiting on the window
+	pending := !utils.IsNodePoolProvisionedCompleted(nodepool) ||
+		nodepool.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration
+	waiting := !open && pending
+	if err := utils.UpdateNodePoolWindowCondition(ctx, c.Client, nodepool, !waiting, next); err != nil {
+		return utils.RequeueWithShortInterval(), true,
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	if !waiting {
+		return ctrl.Result{}, false, nil
+	}
+
+	c.Logger.InfoContext(ctx, "Holding NodePool until the next provisioning window",
+		slog.Time("nextWindow", next))
+	if next.IsZero() {
+		// Processing resumes when the windows are updated, which triggers a new reconcile
+		return utils.DoNotRequeue(), true, nil
+	}
+	return utils.RequeueWithCustomInterval(time.Until(next)), true, nil
+}
+
+// checkPowerBudget holds a NodePool with pending hardware changes whose allocation would exceed an enforced power
+// budget, on top of the power committed to the other NodePools of the hardware manager, returning true if the NodePool
+// is held. A NodePool is requeued to check the budget again, as the release of other NodePools does not trigger a
+// reconcile.
+func (c *HwMgrAdaptorController) checkPowerBudget(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, bool, error) {
+
+	// The power of a provisioned NodePool without a pending spec change is already committed
+	pending := !utils.IsNodePoolProvisionedCompleted(nodepool) ||
+		nodepool.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration
+	if len(hwmgr.Spec.PowerBudgets) == 0 || !pending {
+		if err := utils.UpdateNodePoolPowerBudgetCondition(ctx, c.Client, nodepool, nil); err != nil {
+			return utils.RequeueWithShortInterval(), true,
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return ctrl.Result{}, false, nil
+	}
+
+	var others []hwmgmtv1alpha1.NodePool
+	for _, ns := range utils.GetHardwareManagerWatchNamespaces(hwmgr) {
+		nodepools := &hwmgmtv1alpha1.NodePoolList{}
+		if err := c.Client.List(ctx, nodepools, client.InNamespace(ns)); err != nil {
+			return utils.RequeueWithShortInterval(), true, fmt.Errorf("failed to list NodePools: %w", err)
+		}
+		others = append(others, nodepools.Items...)
+	}
+
+	violations := utils.CheckNodePoolPowerBudgets(hwmgr, nodepool, others)
+	if err := utils.UpdateNodePoolPowerBudgetCondition(ctx, c.Client, nodepool, violations); err != nil {
+		return utils.RequeueWithShortInterval(), true,
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	if len(violations) == 0 {
+		return ctrl.Result{}, false, nil
+	}
+
+	message := utils.FormatPowerBudgetViolations(violations)
+	if !utils.IsPowerBudgetEnforcedViolation(violations) {
+		c.Logger.WarnContext(ctx, "Allocating NodePool beyond its power budget", slog.String("reason", message))
+		return ctrl.Result{}, false, nil
+	}
+
+	c.Logger.InfoContext(ctx, "Holding NodePool for power budget", slog.String("reason", message))
+	return utils.RequeueWithMediumInterval(), true, nil
+}
+
 // HandleNodePool calls the applicable adaptor handler to process the NodePool CR deletion
 func (c *HwMgrAdaptorController) HandleNodePoolDeletion(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
 	hwmgr, err := c.getHwMgr(ctx, nodepool)
@@ -155,16 +441,315 @@ func (c *HwMgrAdaptorController) HandleNodePoolDeletion(ctx context.Context, nod
 
 	adaptorID := string(hwmgr.Spec.AdaptorID)
 
-	// Validate the specified adaptor ID
-	adaptor, exists := c.adaptors[adaptorID]
-	if !exists {
-		c.Logger.Error("unsupported adaptor ID", "adaptorID", adaptorID)
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		c.Logger.ErrorContext(ctx, "unsupported adaptor ID", slog.String("adaptorID", adaptorID))
+		return nil
+	}
+
+	// The release is made as the tenant if its service account is still authorized, falling back to the plugin
+	// identity so that the deletion of the NodePool is not blocked by a change to the tenant RBAC
+	if tenantCtx, authorized, err := c.authorizeTenant(ctx, hwmgr, nodepool); err == nil && authorized {
+		ctx = tenantCtx
+	} else {
+		c.Logger.InfoContext(ctx, "Releasing NodePool without tenant impersonation")
+	}
+
+	// Free any allocation slots held by the NodePool, so that queued allocations can proceed
+	sdk.GetAllocationThrottle(hwmgr.Name, utils.GetMaxConcurrentAllocations(hwmgr)).Release(nodepool.Name)
+	c.lastErrors.Delete(client.ObjectKeyFromObject(nodepool))
+
+	if policy := utils.GetNodePoolDeletionPolicy(hwmgr, nodepool); policy == pluginv1alpha1.DeletionPolicies.Retain {
+		// The Node CRs and bmc-secrets are removed with the NodePool by garbage collection, leaving the backend
+		// allocation in place
+		c.Logger.InfoContext(ctx, "Retaining backend hardware allocation for deleted NodePool",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("cloudID", nodepool.Spec.CloudID),
+			slog.String("deletionPolicy", string(policy)),
+			slog.Any("nodeNames", nodepool.Status.Properties.NodeNames))
+		if err := adaptor.RetainNodePool(ctx, hwmgr, nodepool); err != nil {
+			return fmt.Errorf("failed RetainNodePool for adaptorID %s: %w", adaptorID, err)
+		}
 		return nil
 	}
 
+	windows, err := utils.GetProvisioningWindows(hwmgr, nodepool)
+	if err != nil {
+		return fmt.Errorf("invalid provisioning windows: %w", err)
+	}
+	open, next, err := utils.IsWithinProvisioningWindow(windows, time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid provisioning windows: %w", err)
+	}
+	if err := utils.UpdateNodePoolWindowCondition(ctx, c.Client, nodepool, open, next); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	if !open {
+		c.Logger.InfoContext(ctx, "Deferring release of NodePool until the next provisioning window",
+			slog.String("nodepool", nodepool.Name),
+			slog.Time("nextWindow", next))
+		return fmt.Errorf("%w: %s", sdk.ErrReleaseDeferred, utils.FormatWaitingForWindow(next))
+	}
+
+	// When a cloud is torn down, worker nodes are released ahead of the control plane
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.Client.List(ctx, nodepools, client.InNamespace(nodepool.Namespace)); err != nil {
+		return fmt.Errorf("failed to list NodePools: %w", err)
+	}
+	if blockers := utils.GetNodePoolTeardownBlockers(nodepool, nodepools.Items); len(blockers) > 0 {
+		message := fmt.Sprintf("Waiting for the release of worker NodePools: %s", strings.Join(blockers, ", "))
+		c.Logger.InfoContext(ctx, "Deferring release of NodePool",
+			slog.String("nodepool", nodepool.Name),
+			slog.Any("blockers", blockers))
+		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
+			utils.NodePoolTeardown, utils.ReasonWaitingForWorkers, metav1.ConditionFalse, message); err != nil {
+			return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return fmt.Errorf("%w: %s", sdk.ErrReleaseDeferred, message)
+	}
+
 	if err := adaptor.HandleNodePoolDeletion(ctx, hwmgr, nodepool); err != nil {
+		if errors.Is(err, sdk.ErrReleaseDeferred) {
+			if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
+				utils.NodePoolTeardown, utils.ReasonReleaseRateLimited, metav1.ConditionFalse, err.Error()); err != nil {
+				return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+			}
+		}
 		return fmt.Errorf("failed HandleNodePoolDeletion for adaptorID %s: %w", adaptorID, err)
 	}
 
 	return nil
 }
+
+// RestoreNodeBMCSecret calls the applicable adaptor handler to re-create the bmc-secret for a node
+func (c *HwMgrAdaptorController) RestoreNodeBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, node *hwmgmtv1alpha1.Node) error {
+	hwmgr, err := c.getHwMgr(ctx, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get HardwareManager CR (%s): %w", nodepool.Spec.HwMgrId, err)
+	}
+
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return err
+	}
+
+	if err := adaptor.RestoreNodeBMCSecret(ctx, hwmgr, nodepool, node); err != nil {
+		return fmt.Errorf("failed RestoreNodeBMCSecret for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return nil
+}
+
+// Capabilities calls the applicable adaptor handler to report the optional features supported for the hardware
+// manager, sorted
+func (c *HwMgrAdaptorController) Capabilities(hwmgr *pluginv1alpha1.HardwareManager) ([]pluginv1alpha1.AdaptorCapability, error) {
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := slices.Clone(adaptor.Capabilities(hwmgr))
+	slices.Sort(capabilities)
+	return slices.Compact(capabilities), nil
+}
+
+// GetCapacity calls the applicable adaptor handler to query the node capacity of the hardware manager, through the
+// inventory cache. A nil capacity is returned if the adaptor does not support capacity reporting.
+func (c *HwMgrAdaptorController) GetCapacity(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*pluginv1alpha1.CapacityStatus, error) {
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity, err := sdk.CachedInventoryQuery(hwmgr, "capacity", func() (*pluginv1alpha1.CapacityStatus, error) {
+		return adaptor.GetCapacity(ctx, hwmgr) // nolint: wrapcheck
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed GetCapacity for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return capacity, nil
+}
+
+// PlanConsolidation calls the applicable adaptor handler to plan the re-packing of the allocations in the resource
+// pools. sdk.ErrNotSupported is returned if the adaptor does not support migrating allocations.
+func (c *HwMgrAdaptorController) PlanConsolidation(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	resourcePoolIds []string) ([]pluginv1alpha1.ConsolidationMove, error) {
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return nil, err
+	}
+
+	moves, err := adaptor.PlanConsolidation(ctx, hwmgr, resourcePoolIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed PlanConsolidation for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return moves, nil
+}
+
+// ExecuteConsolidationMove calls the applicable adaptor handler to migrate an allocation, per a consolidation plan
+func (c *HwMgrAdaptorController) ExecuteConsolidationMove(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	move *pluginv1alpha1.ConsolidationMove) error {
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return err
+	}
+
+	if err := adaptor.ExecuteConsolidationMove(ctx, hwmgr, move); err != nil {
+		return fmt.Errorf("failed ExecuteConsolidationMove for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return nil
+}
+
+// DecommissionNode calls the applicable adaptor handler to power down, optionally wipe, and release a node, filling in
+// the backend details of the decommission report. sdk.ErrNotSupported is returned if the adaptor does not support
+// decommissioning nodes.
+func (c *HwMgrAdaptorController) DecommissionNode(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	report *utils.DecommissionReport) error {
+	hwmgr, err := c.getHwMgr(ctx, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get HardwareManager CR (%s): %w", nodepool.Spec.HwMgrId, err)
+	}
+
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return err
+	}
+
+	if err := adaptor.DecommissionNode(ctx, hwmgr, nodepool, node, report); err != nil {
+		return fmt.Errorf("failed DecommissionNode for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return nil
+}
+
+// ReleaseNode calls the applicable adaptor handler to release a node from its NodePool back to the free nodes of its
+// resource pool. sdk.ErrNotSupported is returned if the adaptor does not support releasing a single node.
+func (c *HwMgrAdaptorController) ReleaseNode(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+	hwmgr, err := c.getHwMgr(ctx, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get HardwareManager CR (%s): %w", nodepool.Spec.HwMgrId, err)
+	}
+
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return err
+	}
+
+	if err := adaptor.ReleaseNode(ctx, hwmgr, nodepool, node); err != nil {
+		return fmt.Errorf("failed ReleaseNode for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return nil
+}
+
+// SetNodePowerState calls the applicable adaptor handler to power a node on or off. sdk.ErrNotSupported is returned if
+// the adaptor does not support power control.
+func (c *HwMgrAdaptorController) SetNodePowerState(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	state utils.PowerState) error {
+	hwmgr, err := c.getHwMgr(ctx, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get HardwareManager CR (%s): %w", nodepool.Spec.HwMgrId, err)
+	}
+
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return err
+	}
+
+	if err := adaptor.SetNodePowerState(ctx, hwmgr, nodepool, node, state); err != nil {
+		return fmt.Errorf("failed SetNodePowerState for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return nil
+}
+
+// SelfTest calls the applicable adaptor handler to run a non-destructive self-test of the connectivity to the backend,
+// returning the results of the steps run
+func (c *HwMgrAdaptorController) SelfTest(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]pluginv1alpha1.SelfTestStep, error) {
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptor.SelfTest(ctx, hwmgr), nil
+}
+
+// CheckAllocations calls the applicable adaptor handler to cross-check the allocation records of a NodePool against
+// its Nodes, repairing the records left inconsistent by an interrupted allocation. sdk.ErrNotSupported is returned if
+// the adaptor does not hold allocation records.
+func (c *HwMgrAdaptorController) CheckAllocations(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodes []hwmgmtv1alpha1.Node) ([]pluginv1alpha1.ConsistencyIssue, error) {
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := adaptor.CheckAllocations(ctx, hwmgr, nodepool, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed CheckAllocations for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return issues, nil
+}
+
+// GetFreeNodes calls the applicable adaptor handler to list the free nodes of a resource pool that could be allocated
+// to a prospective nodegroup, through the inventory cache. sdk.ErrNotSupported is returned if the adaptor does not support listing free nodes.
+func (c *HwMgrAdaptorController) GetFreeNodes(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	query utils.FreeNodeQuery) ([]utils.FreeNode, error) {
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	adaptor, err := c.getAdaptor(hwmgr)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("freeNodes/%s/%s", query.ResourcePoolId, query.HwProfile)
+	if query.Selector != nil {
+		key += fmt.Sprintf("/%+v", *query.Selector)
+	}
+	freenodes, err := sdk.CachedInventoryQuery(hwmgr, key, func() ([]utils.FreeNode, error) {
+		return adaptor.GetFreeNodes(ctx, hwmgr, query) // nolint: wrapcheck
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed GetFreeNodes for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return freenodes, nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adaptors
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// nodePoolClient serves a single HardwareManager and NodePool, keeping the changes made to the NodePool
+type nodePoolClient struct {
+	client.Client
+	hwmgr    *pluginv1alpha1.HardwareManager
+	nodepool *hwmgmtv1alpha1.NodePool
+}
+
+func (c *nodePoolClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	switch typed := obj.(type) {
+	case *pluginv1alpha1.HardwareManager:
+		if key.Name == c.hwmgr.Name && key.Namespace == c.hwmgr.Namespace {
+			c.hwmgr.DeepCopyInto(typed)
+			return nil
+		}
+	case *hwmgmtv1alpha1.NodePool:
+		if key.Name == c.nodepool.Name && key.Namespace == c.nodepool.Namespace {
+			c.nodepool.DeepCopyInto(typed)
+			return nil
+		}
+	}
+	return k8serrors.NewNotFound(pluginv1alpha1.GroupVersion.WithResource("").GroupResource(), key.Name)
+}
+
+func (c *nodePoolClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	obj.(*hwmgmtv1alpha1.NodePool).DeepCopyInto(c.nodepool)
+	return nil
+}
+
+func (c *nodePoolClient) Status() client.SubResourceWriter {
+	return &nodePoolStatusWriter{c: c}
+}
+
+type nodePoolStatusWriter struct {
+	client.SubResourceWriter
+	c *nodePoolClient
+}
+
+func (w *nodePoolStatusWriter) Update(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+	w.c.nodepool.Status = obj.(*hwmgmtv1alpha1.NodePool).Status
+	return nil
+}
+
+// nodePoolAdaptor records the NodePools it is called to process
+type nodePoolAdaptor struct {
+	adaptorinterface.HwMgrAdaptorIntf
+	processed []string
+}
+
+func (a *nodePoolAdaptor) HandleNodePool(
+	_ context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	a.processed = append(a.processed, nodepool.Name)
+	return utils.DoNotRequeue(), nil
+}
+
+var _ = Describe("NodePool selection", func() {
+	var (
+		ctx      context.Context
+		adaptor  *nodePoolAdaptor
+		c        *nodePoolClient
+		r        *HwMgrAdaptorController
+		nodepool *hwmgmtv1alpha1.NodePool
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		adaptor = &nodePoolAdaptor{}
+		c = &nodePoolClient{
+			hwmgr: &pluginv1alpha1.HardwareManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "test"},
+				Spec: pluginv1alpha1.HardwareManagerSpec{
+					AdaptorID: LoopbackAdaptorID,
+					NodePoolSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"tenant": "tenant-a"},
+					},
+				},
+			},
+			nodepool: &hwmgmtv1alpha1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "nodepool",
+					Namespace: "test",
+					Labels:    map[string]string{"tenant": "tenant-b"},
+				},
+				Spec: hwmgmtv1alpha1.NodePoolSpec{HwMgrId: "hwmgr"},
+			},
+		}
+		r = &HwMgrAdaptorController{
+			Client:    c,
+			Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Namespace: "test",
+			adaptors:  map[string]adaptorinterface.HwMgrAdaptorIntf{LoopbackAdaptorID: adaptor},
+		}
+	})
+
+	handle := func() {
+		nodepool = c.nodepool.DeepCopy()
+		_, err := r.HandleNodePool(ctx, nodepool)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("processes a rejected NodePool once it is relabelled to match the selector", func() {
+		handle()
+		Expect(adaptor.processed).To(BeEmpty())
+		Expect(utils.IsNodePoolNotSelected(c.nodepool)).To(BeTrue())
+		Expect(meta.FindStatusCondition(c.nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))).To(BeNil())
+
+		c.nodepool.Labels["tenant"] = "tenant-a"
+		handle()
+		Expect(adaptor.processed).To(Equal([]string{"nodepool"}))
+		condition := meta.FindStatusCondition(c.nodepool.Status.Conditions, string(utils.NodePoolNotSelected))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(utils.ReasonSelected)))
+	})
+
+	It("processes a NodePool outside the watch namespaces once its namespace is watched", func() {
+		c.nodepool.Labels["tenant"] = "tenant-a"
+		c.hwmgr.Spec.WatchNamespaces = []string{"tenant-a"}
+		handle()
+		Expect(adaptor.processed).To(BeEmpty())
+		condition := meta.FindStatusCondition(c.nodepool.Status.Conditions, string(utils.NodePoolNotSelected))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(utils.ReasonOutsideWatchNamespaces)))
+		Expect(meta.FindStatusCondition(c.nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))).To(BeNil())
+
+		c.hwmgr.Spec.WatchNamespaces = append(c.hwmgr.Spec.WatchNamespaces, "test")
+		handle()
+		Expect(adaptor.processed).To(Equal([]string{"nodepool"}))
+		Expect(utils.IsNodePoolNotSelected(c.nodepool)).To(BeFalse())
+	})
+
+	It("does not set the NotSelected condition on a selected NodePool", func() {
+		c.nodepool.Labels["tenant"] = "tenant-a"
+		handle()
+		Expect(adaptor.processed).To(Equal([]string{"nodepool"}))
+		Expect(meta.FindStatusCondition(c.nodepool.Status.Conditions, string(utils.NodePoolNotSelected))).To(BeNil())
+	})
+})
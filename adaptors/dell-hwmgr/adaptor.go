@@ -22,7 +22,9 @@ import (
 	"log/slog"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/controller"
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -102,6 +104,11 @@ func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.
 			return NodePoolFSMNoop
 		}
 
+		if sdk.IsNodePoolAllocationQueued(nodepool) {
+			a.Logger.InfoContext(ctx, "Retrying throttled Create NodePool request")
+			return NodePoolFSMCreate
+		}
+
 		return NodePoolFSMProcessing
 	}
 
@@ -126,7 +133,21 @@ func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.Hard
 	case NodePoolFSMSpecChanged:
 		return a.HandleNodePoolSpecChanged(ctx, hwmgrClient, hwmgr, nodepool)
 	case NodePoolFSMNoop:
-		// Nothing to do
+		if utils.IsNodePoolProvisionedCompleted(nodepool) {
+			// Periodically refresh the power status of the allocated nodes
+			if err := a.RefreshNodePowerStatus(ctx, hwmgrClient, hwmgr, nodepool); err != nil {
+				a.Logger.InfoContext(ctx, "Failed to refresh node power status", slog.String("error", err.Error()))
+			}
+			// Resync the node hardware details from the backend, if enabled and due
+			if utils.IsNodeResyncDue(hwmgr, nodepool) {
+				if err := a.ResyncNodeHardware(ctx, hwmgrClient, hwmgr, nodepool); err != nil {
+					a.Logger.InfoContext(ctx, "Failed to resync node hardware", slog.String("error", err.Error()))
+				} else if err := utils.SetNodeResyncTime(ctx, a.Client, nodepool); err != nil {
+					a.Logger.InfoContext(ctx, "Failed to record node resync time", slog.String("error", err.Error()))
+				}
+			}
+			return utils.RequeueWithLongInterval(), nil
+		}
 		return result, nil
 	}
 
@@ -149,3 +170,109 @@ func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alp
 
 	return nil
 }
+
+// RetainNodePool is called for a NodePool deleted under the Retain deletion policy. The resource group is left in
+// place on the hardware manager, with nothing to record.
+func (a *Adaptor) RetainNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	return nil
+}
+
+// RestoreNodeBMCSecret re-creates the bmc-secret for a node from the credentials held by the hardware manager
+func (a *Adaptor) RestoreNodeBMCSecret(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+
+	hwmgrClient, clientErr := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
+	if clientErr != nil {
+		a.Logger.InfoContext(ctx, "NewClientWithResponses error", slog.String("error", clientErr.Error()))
+		return fmt.Errorf("failed to setup hwmgr client: %w", clientErr)
+	}
+
+	rsp, err := hwmgrClient.GetResource(ctx, node)
+	if err != nil {
+		return fmt.Errorf("failed to get resource for node %s: %w", node.Name, err)
+	}
+	if rsp == nil || rsp.Resource == nil {
+		return fmt.Errorf("resource data missing from response for node %s", node.Name)
+	}
+
+	resource := hwmgrapi.RhprotoResource{ResourceAttribute: rsp.Resource.ResourceAttribute}
+	if err := a.ValidateNodeConfig(ctx, resource); err != nil {
+		return fmt.Errorf("invalid resource for node %s: %w", node.Name, err)
+	}
+
+	return a.CreateBMCSecret(ctx, hwmgrClient, nodepool, node.Name, node.Spec.GroupName, resource)
+}
+
+// Capabilities reports the features of the Dell adaptor. Firmware and BIOS settings are applied by the hardware manager
+// on a change of hardware profile.
+func (a *Adaptor) Capabilities(hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.AdaptorCapability {
+	return []pluginv1alpha1.AdaptorCapability{
+		pluginv1alpha1.CapabilityFirmwareUpdate,
+		pluginv1alpha1.CapabilityBIOSConfig,
+	}
+}
+
+// GetCapacity is not supported by the Dell adaptor, as the hardware manager does not report the free nodes of its
+// resource pools
+func (a *Adaptor) GetCapacity(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*pluginv1alpha1.CapacityStatus, error) {
+	return nil, nil
+}
+
+// PlanConsolidation is not supported by the Dell adaptor, as the hardware manager does not support migrating an
+// allocation to a different node
+func (a *Adaptor) PlanConsolidation(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, resourcePoolIds []string) ([]pluginv1alpha1.ConsolidationMove, error) {
+	return nil, sdk.ErrNotSupported
+}
+
+// ExecuteConsolidationMove is not supported by the Dell adaptor
+func (a *Adaptor) ExecuteConsolidationMove(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, move *pluginv1alpha1.ConsolidationMove) error {
+	return sdk.ErrNotSupported
+}
+
+// DecommissionNode is not supported by the Dell adaptor, as the hardware manager does not report the disk wipe status of a node
+func (a *Adaptor) DecommissionNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	report *utils.DecommissionReport) error {
+	return sdk.ErrNotSupported
+}
+
+// ReleaseNode is not supported by the Dell adaptor, as the hardware manager API has no endpoint to shrink an allocation
+func (a *Adaptor) ReleaseNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+	return sdk.ErrNotSupported
+}
+
+// GetFreeNodes is not supported by the Dell adaptor, as the hardware manager does not report the free nodes of its
+// resource pools
+func (a *Adaptor) GetFreeNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, query utils.FreeNodeQuery) ([]utils.FreeNode, error) {
+	return nil, sdk.ErrNotSupported
+}
+
+// SetNodePowerState is not supported by the Dell adaptor, as the hardware manager API exposes no power actions
+func (a *Adaptor) SetNodePowerState(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	state utils.PowerState) error {
+	return sdk.ErrNotSupported
+}
+
+// CheckAllocations is not supported by the Dell adaptor, as the allocation records are held by the hardware manager, which resolves an interrupted
+// allocation when the NodePool is next processed
+func (a *Adaptor) CheckAllocations(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodes []hwmgmtv1alpha1.Node) ([]pluginv1alpha1.ConsistencyIssue, error) {
+	return nil, sdk.ErrNotSupported
+}
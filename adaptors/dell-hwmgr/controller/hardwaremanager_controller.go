@@ -21,15 +21,21 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
+	"time"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
@@ -48,6 +54,8 @@ type HardwareManagerReconciler struct {
 //+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -56,6 +64,7 @@ type HardwareManagerReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	_ = log.FromContext(ctx)
+	ctx = logging.NewReconcileContext(ctx)
 	result = utils.DoNotRequeue()
 
 	// Fetch the CR:
@@ -82,6 +91,34 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
 
+	// Changes to the credentials are detected by checksum, so the backend connection is re-validated promptly
+	configChanged := hwmgr.Status.ObservedGeneration != 0 && hwmgr.Status.ObservedGeneration != hwmgr.Generation
+	checksum, checksumErr := utils.GetAuthSecretChecksum(ctx, r.Client, hwmgr)
+	if checksumErr != nil {
+		r.Logger.InfoContext(ctx, "Unable to compute auth secret checksum", slog.String("error", checksumErr.Error()))
+	} else if utils.IsAuthSecretChanged(hwmgr, checksum) && hwmgr.GetAnnotations()[utils.AuthSecretChecksumAnnotation] != "" {
+		r.Logger.InfoContext(ctx, "Auth secret changed, re-validating backend connection")
+		configChanged = true
+	}
+
+	// Rotated CA certificates are likewise detected by checksum. In-flight operations complete with the clients they
+	// were started with, as each new backend client is built with the current CA bundles.
+	caChecksum, caChecksumErr := utils.GetCaBundleChecksum(ctx, r.Client, hwmgr)
+	if caChecksumErr != nil {
+		r.Logger.InfoContext(ctx, "Unable to compute CA bundle checksum", slog.String("error", caChecksumErr.Error()))
+	} else if utils.IsCaBundleChanged(hwmgr, caChecksum) && hwmgr.GetAnnotations()[utils.CaBundleChecksumAnnotation] != "" {
+		r.Logger.InfoContext(ctx, "CA bundle changed, re-validating backend connection")
+		configChanged = true
+	}
+
+	if configChanged {
+		// Backend clients are built from the current spec on each use, so only the failures and certificate recorded
+		// against the previous configuration need to be discarded for the change to take effect
+		r.Logger.InfoContext(ctx, "HardwareManager configuration changed, resetting backend circuit breaker")
+		sdk.ResetCircuitBreaker(hwmgr.Name)
+		sdk.ResetBackendCertificate(hwmgr.Name)
+	}
+
 	hwmgr.Status.ObservedGeneration = hwmgr.Generation
 
 	if hwmgr.Spec.DellData == nil {
@@ -94,7 +131,7 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			err = fmt.Errorf("failed to update status for hardware manager (%s) with validation failure: %w", hwmgr.Name, updateErr)
 			return
 		}
-		r.Logger.Error("HardwareManager CR missing dellData configuration field", slog.String("name", hwmgr.Name))
+		r.Logger.ErrorContext(ctx, "HardwareManager CR missing dellData configuration field", slog.String("name", hwmgr.Name))
 		return
 	}
 
@@ -113,7 +150,7 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			err = fmt.Errorf("failed to update status for hardware manager (%s) with authentication failure: %w", hwmgr.Name, updateErr)
 			return
 		}
-		r.Logger.Error("Failed to establish connection to hardware manager", slog.String("name", hwmgr.Name), slog.String("error", clientErr.Error()))
+		r.Logger.ErrorContext(ctx, "Failed to establish connection to hardware manager", slog.String("name", hwmgr.Name), slog.String("error", clientErr.Error()))
 		return
 	}
 
@@ -128,7 +165,7 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			err = fmt.Errorf("failed to update status for hardware manager (%s) with authentication failure: %w", hwmgr.Name, updateErr)
 			return
 		}
-		r.Logger.Error("Failed to query resource pools", slog.String("name", hwmgr.Name), slog.String("error", clientErr.Error()))
+		r.Logger.ErrorContext(ctx, "Failed to query resource pools", slog.String("name", hwmgr.Name), slog.String("error", clientErr.Error()))
 		return
 	}
 
@@ -154,6 +191,13 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		}
 	}
 
+	// The backend certificate is observed by the client during validation, and persisted with the Validation condition
+	if cert, observed := sdk.GetBackendCertificate(hwmgr.Name); observed {
+		utils.SetCertificateExpiringCondition(hwmgr, cert.Subject, cert.NotAfter, time.Now())
+	} else {
+		meta.RemoveStatusCondition(&hwmgr.Status.Conditions, string(pluginv1alpha1.ConditionTypes.CertificateExpiring))
+	}
+
 	if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
 		pluginv1alpha1.ConditionTypes.Validation,
 		pluginv1alpha1.ConditionReasons.Completed,
@@ -163,6 +207,20 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return
 	}
 
+	if caChecksumErr == nil && utils.IsCaBundleChanged(hwmgr, caChecksum) {
+		if updateErr := utils.SetCaBundleChecksum(ctx, r.Client, hwmgr, caChecksum); updateErr != nil {
+			err = fmt.Errorf("failed to record CA bundle checksum for hardware manager (%s): %w", hwmgr.Name, updateErr)
+			return
+		}
+	}
+
+	if checksumErr == nil && utils.IsAuthSecretChanged(hwmgr, checksum) {
+		if updateErr := utils.SetAuthSecretChecksum(ctx, r.Client, hwmgr, checksum); updateErr != nil {
+			err = fmt.Errorf("failed to record auth secret checksum for hardware manager (%s): %w", hwmgr.Name, updateErr)
+			return
+		}
+	}
+
 	return
 }
 
@@ -179,9 +237,11 @@ func (r *HardwareManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Logger.Info("Setting up Dell controller", slog.String("adaptorId", string(r.AdaptorID)))
 	if err := ctrl.NewControllerManagedBy(mgr).
 		Named(string(r.AdaptorID)).
-		For(&pluginv1alpha1.HardwareManager{}).
-		WithEventFilter(filterEvents(r.AdaptorID)).
-		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{})).
+		For(&pluginv1alpha1.HardwareManager{}, builder.WithPredicates(
+			filterEvents(r.AdaptorID),
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(utils.MapAuthSecretToHardwareManagers(r.Client, r.AdaptorID))).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(utils.MapCaBundleToHardwareManagers(r.Client, r.AdaptorID))).
 		Complete(r); err != nil {
 		return fmt.Errorf("failed to setup controller for %s: %w", r.AdaptorID, err)
 	}
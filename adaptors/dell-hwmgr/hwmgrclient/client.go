@@ -25,25 +25,30 @@ import (
 
 	"github.com/oapi-codegen/oapi-codegen/v2/pkg/securityprovider"
 	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	RoleKey       = "role"
 	DefaultTenant = "default_tenant"
+
+	// ListPageSize is the number of items requested per page in list queries
+	ListPageSize = 100
 )
 
-type JobStatus int
+type JobStatus = sdk.JobStatus
 
 const (
-	JobStatusInProgress = iota
-	JobStatusCompleted
-	JobStatusFailed
-	JobStatusUnknown
+	JobStatusInProgress = sdk.JobStatusInProgress
+	JobStatusCompleted  = sdk.JobStatusCompleted
+	JobStatusFailed     = sdk.JobStatusFailed
+	JobStatusUnknown    = sdk.JobStatusUnknown
 )
 
 // HardwareManagerClient provides functions for calling the hardware manager APIs
@@ -95,14 +100,13 @@ func (c *HardwareManagerClient) GetToken(ctx context.Context) (string, error) {
 		GrantType: &grant_type,
 	}
 
-	tokenrsp, err := c.HwmgrClient.GetTokenWithResponse(ctx, req)
+	tokenrsp, err := c.HwmgrClient.GetTokenWithResponse(utils.WithReadOnlyExemption(ctx), req)
 	if err != nil {
 		return "", fmt.Errorf("failed to get token: response: %v, err: %w", tokenrsp, err)
 	}
 
 	if tokenrsp.StatusCode() != http.StatusOK {
-		return "", fmt.Errorf("token request failed with status %s (%d), message=%s",
-			tokenrsp.Status(), tokenrsp.StatusCode(), string(tokenrsp.Body))
+		return "", newBackendError("token request", tokenrsp.HTTPResponse, tokenrsp.Body)
 	}
 
 	var tokenData hwmgrapi.RhprotoGetTokenResponseBody
@@ -131,30 +135,28 @@ func NewClientWithResponses(
 	}
 
 	// If the HardwareManager CR includes certificates, get the bundle to add to the client
-	var caBundle string
-	if hwmgr.Spec.DellData.CaBundleName != nil {
-		cm, err := utils.GetConfigmap(ctx, rtclient, *hwmgr.Spec.DellData.CaBundleName, hwmgr.Namespace)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get configmap: %s", err.Error())
-		}
-
-		caBundle, err = utils.GetConfigMapField(cm, "ca-bundle.pem")
-		if err != nil {
-			return nil, fmt.Errorf("failed to get certificate bundle from configmap: %s", err.Error())
-		}
+	caBundle, err := sdk.GetCaBundle(ctx, rtclient, hwmgr.Namespace, hwmgr.Spec.DellData.CaBundleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA bundle: %w", err)
 	}
 
-	config := utils.OAuthClientConfig{
-		CaBundle: []byte(caBundle),
+	proxyCaBundle, err := sdk.GetProxyCaBundle(ctx, rtclient, hwmgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy CA bundle: %w", err)
 	}
 
-	tr, err := utils.GetTransportWithCaBundle(config, hwmgr.Spec.DellData.InsecureSkipTLSVerify, utils.IsHardwareManagerLogMessagesEnabled(hwmgr))
+	httpClient, err := sdk.NewHTTPClient(sdk.HTTPClientConfig{
+		CaBundle:              caBundle,
+		Proxy:                 hwmgr.Spec.Proxy,
+		ProxyCaBundle:         proxyCaBundle,
+		InsecureSkipTLSVerify: hwmgr.Spec.DellData.InsecureSkipTLSVerify,
+		LogMessages:           utils.IsHardwareManagerLogMessagesEnabled(hwmgr),
+		HwMgrName:             hwmgr.Name,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get http transport: %w", err)
+		return nil, fmt.Errorf("failed to setup http client: %w", err)
 	}
 
-	httpClient := &http.Client{Transport: tr}
-
 	// Create the hwmgrapi client, along with a bearer token
 	hwmgrClient.HwmgrClient, err = hwmgrapi.NewClientWithResponses(
 		hwmgr.Spec.DellData.ApiUrl,
@@ -197,8 +199,7 @@ func (c *HardwareManagerClient) GetResourceGroup(ctx context.Context, nodepool *
 	}
 
 	if response.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("resource group get failed with status %s (%d), message=%s",
-			response.Status(), response.StatusCode(), string(response.Body))
+		return nil, newBackendError("resource group get "+rgId, response.HTTPResponse, response.Body)
 	}
 
 	return response.JSON200, nil
@@ -253,7 +254,6 @@ func (c *HardwareManagerClient) ResourceGroupFromNodePool(nodepool *hwmgmtv1alph
 }
 
 // CreateResourceGroup sends a request to the hardware manager, returns a jobId
-// TODO: Improve error handling for different status codes
 func (c *HardwareManagerClient) CreateResourceGroup(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
 	rg := c.ResourceGroupFromNodePool(nodepool)
 	rgId := *rg.ResourceGroup.Id
@@ -276,9 +276,7 @@ func (c *HardwareManagerClient) CreateResourceGroup(ctx context.Context, nodepoo
 	}
 
 	if rgResponse.StatusCode() != http.StatusOK {
-		// TODO: Remove this log
-		c.Logger.InfoContext(ctx, "Failure from CreateResourceGroupWithResponse", slog.String("message", *rgResponse.JSONDefault.Message), slog.Any("response", rgResponse.JSONDefault))
-		return "", fmt.Errorf("failed to create resource group %s, bad status: %s, code: %d, response: %v", rgId, rgResponse.Status(), rgResponse.StatusCode(), rgResponse)
+		return "", newBackendError("create resource group "+rgId, rgResponse.HTTPResponse, rgResponse.Body)
 	}
 
 	// Return the job ID for the request
@@ -287,52 +285,42 @@ func (c *HardwareManagerClient) CreateResourceGroup(ctx context.Context, nodepoo
 
 // CheckJobStatus queries the hardware manager for the status of a job
 func (c *HardwareManagerClient) CheckJobStatus(ctx context.Context, jobId string) (JobStatus, string, error) {
-	failReason := ""
+	status, _, failReason, err := c.CheckJobProgress(ctx, jobId)
+	return status, failReason, err
+}
+
+// CheckJobProgress queries the hardware manager for the status of a job, along with its phase while it is running
+func (c *HardwareManagerClient) CheckJobProgress(ctx context.Context, jobId string) (JobStatus, JobPhase, string, error) {
 	tenant := c.GetTenant()
 	response, err := c.HwmgrClient.VerifyRequestStatusWithResponse(ctx, tenant, jobId)
 	if err != nil {
-		return JobStatusUnknown, failReason, fmt.Errorf("failed to query for job status: id: %s, response: %v, err: %w", jobId, response, err)
+		return JobStatusUnknown, "", "", fmt.Errorf("failed to query for job status: id: %s, response: %v, err: %w", jobId, response, err)
 	}
 
 	if response.StatusCode() != http.StatusOK {
-		return JobStatusUnknown, failReason, fmt.Errorf("job query failed for %s: %s", jobId, *response.JSONDefault.Message)
+		return JobStatusUnknown, "", "", newBackendError("job query "+jobId, response.HTTPResponse, response.Body)
 	}
 
 	status := response.JSON200
 	if status == nil || status.Brief == nil || status.Brief.Status == nil {
 		c.Logger.InfoContext(ctx, "Job progress check missing data", slog.Any("status", status))
-		return JobStatusUnknown, failReason, fmt.Errorf("job progress check missing data, jobId=%s: %w", jobId, err)
+		return JobStatusUnknown, "", "", fmt.Errorf("job progress check missing data, jobId=%s: %w", jobId, err)
 	}
 
 	// Process the status response
-	switch *status.Brief.Status {
-	case "started":
-		c.Logger.InfoContext(ctx, "Job has started")
-		return JobStatusInProgress, failReason, nil
-	case "pending":
-		c.Logger.InfoContext(ctx, "Job is pending")
-		return JobStatusInProgress, failReason, nil
-	case "completed":
+	jobStatus, phase, failReason := parseJobStatus(*status.Brief)
+	switch jobStatus {
+	case JobStatusInProgress:
+		c.Logger.InfoContext(ctx, "Job is in progress", slog.String("phase", string(phase)))
+	case JobStatusCompleted:
 		c.Logger.InfoContext(ctx, "Job has completed")
-	case "failed":
-		if status.Brief.FailReason != nil {
-			failReason = *status.Brief.FailReason
-		} else {
-			failReason = "unknown"
-		}
+	case JobStatusFailed:
 		c.Logger.InfoContext(ctx, "Job has failed", slog.Any("status", status), slog.String("failReason", failReason))
-		return JobStatusFailed, failReason, nil
 	default:
-		if status.Brief.FailReason != nil {
-			failReason = *status.Brief.FailReason
-		} else {
-			failReason = "unknown"
-		}
 		c.Logger.InfoContext(ctx, "Job status is unknown", slog.Any("status", status), slog.String("failReason", failReason))
-		return JobStatusUnknown, failReason, nil
 	}
 
-	return JobStatusCompleted, failReason, nil
+	return jobStatus, phase, failReason, nil
 }
 
 // DeleteResourceGroup asks the hardware manager to delete the resource group associated with the specified nodepool
@@ -341,25 +329,69 @@ func (c *HardwareManagerClient) DeleteResourceGroup(ctx context.Context, nodepoo
 	tenant := c.GetTenant()
 
 	response, err := c.HwmgrClient.DeleteResourceGroupWithResponse(ctx, tenant, rgId)
-	if err != nil || response.StatusCode() != http.StatusOK {
+	if err != nil {
 		return "", fmt.Errorf("failed to delete resource group %s: response: %v, err: %w", rgId, response, err)
 	}
 
+	if response.StatusCode() != http.StatusOK {
+		return "", newBackendError("delete resource group "+rgId, response.HTTPResponse, response.Body)
+	}
+
 	return *response.JSON200.Jobid, nil
 }
 
-// GetResourcePools queries the hardware manager to get the resource pool list
+// GetResourcePools queries the hardware manager to get the resource pool list, fetching all pages
 func (c *HardwareManagerClient) GetResourcePools(ctx context.Context) (*hwmgrapi.ApiprotoResourcePoolsResp, error) {
+	var resp *hwmgrapi.ApiprotoResourcePoolsResp
+	pools, err := sdk.PaginateOffsetTotal(ctx, ListPageSize,
+		func(ctx context.Context, offset, limit int) ([]hwmgrapi.ApiprotoResourcePool, int, error) {
+			page, err := c.getResourcePoolsPage(ctx, offset, limit)
+			if err != nil {
+				return nil, 0, err
+			}
+			if resp == nil {
+				resp = page
+			}
+
+			total := -1
+			if page.Pagination != nil && page.Pagination.Total != nil {
+				total = int(*page.Pagination.Total)
+			}
+			if page.ResourcePools == nil {
+				return nil, total, nil
+			}
+			return *page.ResourcePools, total, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource pools: %w", err)
+	}
+
+	resp.ResourcePools = &pools
+	resp.Pagination = nil
+	return resp, nil
+}
+
+// getResourcePoolsPage queries the hardware manager to get a page of the resource pool list
+func (c *HardwareManagerClient) getResourcePoolsPage(ctx context.Context, offset, limit int) (*hwmgrapi.ApiprotoResourcePoolsResp, error) {
 	tenant := c.GetTenant()
-	body := hwmgrapi.GetResourcePoolsJSONRequestBody{}
-	response, err := c.HwmgrClient.GetResourcePoolsWithResponse(ctx, tenant, body)
+	body := hwmgrapi.GetResourcePoolsJSONRequestBody{
+		Pagination: &hwmgrapi.ApiprotoPagination{
+			Offset: ptr.To(int64(offset)),
+			Limit:  ptr.To(int64(limit)),
+		},
+	}
+	// The resource pools are queried with a POST, which does not change the backend
+	response, err := c.HwmgrClient.GetResourcePoolsWithResponse(utils.WithReadOnlyExemption(ctx), tenant, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resource pools: response: %v, err: %w", response, err)
 	}
 
 	if response.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("resource pool get failed with status %s (%d), message=%s",
-			response.Status(), response.StatusCode(), string(response.Body))
+		return nil, newBackendError("resource pool get", response.HTTPResponse, response.Body)
+	}
+
+	if response.JSON200 == nil {
+		return nil, fmt.Errorf("resource pool get returned an empty response")
 	}
 
 	return response.JSON200, nil
@@ -374,8 +406,7 @@ func (c *HardwareManagerClient) GetSecret(ctx context.Context, secretKey string)
 	}
 
 	if response.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("get secret failed with status %s (%d), message=%s",
-			response.Status(), response.StatusCode(), string(response.Body))
+		return nil, newBackendError("get secret "+secretKey, response.HTTPResponse, response.Body)
 	}
 
 	return response.JSON200, nil
@@ -392,7 +423,10 @@ func (c *HardwareManagerClient) ValidateResourceGroup(
 		for _, nodegroup := range nodepool.Spec.NodeGroup {
 			nodegroupName := nodegroup.NodePoolData.Name
 			if resource, exists := resourceSelector[nodegroupName]; exists {
-				if resource.NumResources != nil {
+				if resource.NumResources == nil && utils.IsNodeGroupPlaceholder(nodegroup) {
+					// The backend may omit the count of a placeholder nodegroup, as it holds no resources
+					continue
+				} else if resource.NumResources != nil {
 					// Ensure expected number of nodes are present
 					if float32(nodegroup.Size) != *resource.NumResources {
 						return fmt.Errorf("invalid num of resources for node %s\n expected: %f found: %f",
@@ -412,7 +446,7 @@ func (c *HardwareManagerClient) ValidateResourceGroup(
 					return fmt.Errorf("missing resource pool id for node %s\n expected: %s",
 						nodegroupName, nodegroup.NodePoolData.ResourcePoolId)
 				}
-			} else {
+			} else if !utils.IsNodeGroupPlaceholder(nodegroup) {
 				return fmt.Errorf("validation failed, %s node does not exist in resource group", nodegroupName)
 			}
 		}
@@ -431,13 +465,31 @@ func (c *HardwareManagerClient) GetResource(ctx context.Context, node *hwmgmtv1a
 	}
 
 	if response.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("resource get failed with status %s (%d), message=%s",
-			response.Status(), response.StatusCode(), string(response.Body))
+		return nil, newBackendError("resource get", response.HTTPResponse, response.Body)
 	}
 
 	return response.JSON200, nil
 }
 
+// GetServerInventory queries the hardware manager to get the server inventory data for a node
+func (c *HardwareManagerClient) GetServerInventory(ctx context.Context, node *hwmgmtv1alpha1.Node) (*hwmgrapi.ApiprotoServer, error) {
+	tenant := c.GetTenant()
+	response, err := c.HwmgrClient.GetServerInventoryWithResponse(ctx, tenant, node.Spec.HwMgrNodeId, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server inventory: response: %v, err: %w", response, err)
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return nil, newBackendError("server inventory get", response.HTTPResponse, response.Body)
+	}
+
+	if response.JSON200 == nil || response.JSON200.Server == nil {
+		return nil, fmt.Errorf("server inventory response missing server data")
+	}
+
+	return response.JSON200.Server, nil
+}
+
 // UpdateResourceProfile sends a request to update the resource profile for a node
 func (c *HardwareManagerClient) UpdateResourceProfile(ctx context.Context, node *hwmgmtv1alpha1.Node, newHwProfile string) (string, error) {
 	tenant := c.GetTenant()
@@ -461,8 +513,7 @@ func (c *HardwareManagerClient) UpdateResourceProfile(ctx context.Context, node
 	}
 
 	if response.StatusCode() != http.StatusOK {
-		return "", fmt.Errorf("resource get failed with status %s (%d), message=%s",
-			response.Status(), response.StatusCode(), string(response.Body))
+		return "", newBackendError("update resource profile", response.HTTPResponse, response.Body)
 	}
 
 	return *response.JSON200.Response.Jobid, nil
@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwmgrclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+)
+
+// gRPC status codes returned by the hardware manager in the error response body
+const (
+	grpcInvalidArgument    = "3"
+	grpcNotFound           = "5"
+	grpcAlreadyExists      = "6"
+	grpcPermissionDenied   = "7"
+	grpcResourceExhausted  = "8"
+	grpcFailedPrecondition = "9"
+	grpcAborted            = "10"
+	grpcUnavailable        = "14"
+	grpcUnauthenticated    = "16"
+)
+
+// dellErrorMapper translates the error responses of the hardware manager, which carry a gRPC status in the body
+var dellErrorMapper = &sdk.ErrorMapper{
+	ParseBody: parseErrorBody,
+	Rules: []sdk.ErrorRule{
+		// Allocation requests that cannot be satisfied by the free resources of a pool
+		{Codes: []string{grpcFailedPrecondition, grpcResourceExhausted}, MessageContains: "insufficient",
+			Reason: sdk.ReasonInsufficientResources, Class: sdk.ErrorClassTransient},
+		{Codes: []string{grpcFailedPrecondition, grpcResourceExhausted}, MessageContains: "not enough",
+			Reason: sdk.ReasonInsufficientResources, Class: sdk.ErrorClassTransient},
+		{Codes: []string{grpcResourceExhausted}, Reason: sdk.ReasonBackendThrottled, Class: sdk.ErrorClassTransient},
+		{Codes: []string{grpcInvalidArgument, grpcFailedPrecondition}, Reason: sdk.ReasonBackendRejected, Class: sdk.ErrorClassPermanent},
+		{Codes: []string{grpcNotFound}, Reason: sdk.ReasonBackendNotFound, Class: sdk.ErrorClassPermanent},
+		{Codes: []string{grpcAlreadyExists}, Reason: sdk.ReasonBackendConflict, Class: sdk.ErrorClassPermanent},
+		{Codes: []string{grpcAborted}, Reason: sdk.ReasonBackendConflict, Class: sdk.ErrorClassTransient},
+		{Codes: []string{grpcPermissionDenied}, Reason: sdk.ReasonBackendAuthFailed, Class: sdk.ErrorClassPermanent},
+		// The token is refreshed with each new client, so an expired token is resolved by a retry
+		{Codes: []string{grpcUnauthenticated}, Reason: sdk.ReasonBackendAuthFailed, Class: sdk.ErrorClassTransient},
+		{Codes: []string{grpcUnavailable}, Reason: sdk.ReasonBackendUnavailable, Class: sdk.ErrorClassTransient},
+	},
+}
+
+// parseErrorBody extracts the gRPC status code and message from an error response body
+func parseErrorBody(body []byte) (string, string) {
+	var status struct {
+		Code    *int32 `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", ""
+	}
+
+	code := ""
+	if status.Code != nil {
+		code = strconv.Itoa(int(*status.Code))
+	}
+	return code, status.Message
+}
+
+// newBackendError translates an error response from the hardware manager into an sdk.BackendError
+func newBackendError(operation string, resp *http.Response, body []byte) error {
+	return dellErrorMapper.MapResponse(operation, resp, body)
+}
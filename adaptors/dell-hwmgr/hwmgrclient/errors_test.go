@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwmgrclient
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Error mapping", func() {
+	DescribeTable("maps error responses to condition reasons",
+		func(statusCode int, body string, reason hwmgmtv1alpha1.ConditionReason, class sdk.ErrorClass) {
+			resp := &http.Response{StatusCode: statusCode}
+			backendErr, ok := sdk.AsBackendError(newBackendError("create resource group rg-1", resp, []byte(body)))
+			Expect(ok).To(BeTrue())
+			Expect(backendErr.Reason).To(Equal(reason))
+			Expect(backendErr.Class).To(Equal(class))
+		},
+		Entry("insufficient resources", http.StatusBadRequest,
+			`{"code": 9, "message": "Insufficient resources in pool master"}`,
+			sdk.ReasonInsufficientResources, sdk.ErrorClassTransient),
+		Entry("not enough free servers", http.StatusTooManyRequests,
+			`{"code": 8, "message": "not enough free servers"}`,
+			sdk.ReasonInsufficientResources, sdk.ErrorClassTransient),
+		Entry("rate limited", http.StatusTooManyRequests,
+			`{"code": 8, "message": "rate limit exceeded"}`,
+			sdk.ReasonBackendThrottled, sdk.ErrorClassTransient),
+		Entry("invalid argument", http.StatusBadRequest,
+			`{"code": 3, "message": "invalid resource profile"}`,
+			sdk.ReasonBackendRejected, sdk.ErrorClassPermanent),
+		Entry("resource group exists", http.StatusConflict,
+			`{"code": 6, "message": "resource group already exists"}`,
+			sdk.ReasonBackendConflict, sdk.ErrorClassPermanent),
+		Entry("expired token", http.StatusUnauthorized,
+			`{"code": 16, "message": "token expired"}`,
+			sdk.ReasonBackendAuthFailed, sdk.ErrorClassTransient),
+		Entry("permission denied", http.StatusForbidden,
+			`{"code": 7, "message": "tenant access denied"}`,
+			sdk.ReasonBackendAuthFailed, sdk.ErrorClassPermanent),
+		Entry("unavailable", http.StatusServiceUnavailable,
+			`{"code": 14, "message": "upstream connect error"}`,
+			sdk.ReasonBackendUnavailable, sdk.ErrorClassTransient),
+		Entry("non-JSON gateway error", http.StatusBadGateway,
+			`<html>Bad Gateway</html>`,
+			sdk.ReasonBackendUnavailable, sdk.ErrorClassTransient),
+		Entry("unrecognized server error", http.StatusInternalServerError,
+			`{"code": 13, "message": "internal error"}`,
+			sdk.ReasonBackendFailure, sdk.ErrorClassTransient),
+	)
+
+	It("reports the vendor code and message", func() {
+		err := newBackendError("create resource group rg-1", &http.Response{StatusCode: http.StatusBadRequest},
+			[]byte(`{"code": 3, "message": "invalid resource profile"}`))
+		Expect(err.Error()).To(Equal(
+			"create resource group rg-1 failed with status 400, code 3: BackendRejected: invalid resource profile"))
+
+		err = newBackendError("resource pool get", &http.Response{StatusCode: http.StatusBadGateway}, []byte("Bad Gateway\n"))
+		Expect(err.Error()).To(Equal("resource pool get failed with status 502: BackendUnavailable: Bad Gateway"))
+	})
+
+	It("records the Retry-After delay of throttled responses", func() {
+		resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"120"}}}
+		err := newBackendError("create resource group rg-1", resp, []byte(`{"code": 8, "message": "rate limit exceeded"}`))
+		delay, throttled := sdk.GetThrottleDelay(fmt.Errorf("failed CreateResourceGroup: %w", err))
+		Expect(throttled).To(BeTrue())
+		Expect(delay).To(Equal(2 * time.Minute))
+	})
+})
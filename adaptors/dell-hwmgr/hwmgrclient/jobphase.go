@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwmgrclient
+
+import (
+	"strings"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+)
+
+// JobPhase is the phase of a running hardware manager job, as reported in the status of the job
+type JobPhase string
+
+const (
+	JobPhasePending         JobPhase = "Pending"
+	JobPhaseStarted         JobPhase = "Started"
+	JobPhaseValidating      JobPhase = "Validating"
+	JobPhaseApplyingProfile JobPhase = "ApplyingProfile"
+	JobPhaseRebooting       JobPhase = "Rebooting"
+	JobPhaseVerifying       JobPhase = "Verifying"
+)
+
+// jobPhases maps the in-progress statuses reported by the hardware manager, normalized to lowercase without
+// separators, to the phase of the job
+var jobPhases = map[string]JobPhase{
+	"pending":         JobPhasePending,
+	"started":         JobPhaseStarted,
+	"validating":      JobPhaseValidating,
+	"applyingprofile": JobPhaseApplyingProfile,
+	"rebooting":       JobPhaseRebooting,
+	"verifying":       JobPhaseVerifying,
+}
+
+// parseJobStatus maps the brief status of a job to its status and, for a running job, its phase. The failure reason
+// is returned for a failed job, or one with an unknown status.
+func parseJobStatus(brief hwmgrapi.RhprotoJobStatusBrief) (JobStatus, JobPhase, string) {
+	status := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(*brief.Status))
+	if phase, exists := jobPhases[status]; exists {
+		return JobStatusInProgress, phase, ""
+	}
+	if status == "completed" {
+		return JobStatusCompleted, "", ""
+	}
+
+	failReason := "unknown"
+	if brief.FailReason != nil {
+		failReason = *brief.FailReason
+	}
+	if status == "failed" {
+		return JobStatusFailed, "", failReason
+	}
+	return JobStatusUnknown, "", failReason
+}
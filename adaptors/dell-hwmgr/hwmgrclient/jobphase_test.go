@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwmgrclient
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+)
+
+var _ = Describe("Job phases", func() {
+	DescribeTable("maps job statuses to phases",
+		func(status string, jobStatus JobStatus, phase JobPhase, failReason string) {
+			brief := hwmgrapi.RhprotoJobStatusBrief{Status: ptr.To(status), FailReason: ptr.To("bmc timeout")}
+			gotStatus, gotPhase, gotReason := parseJobStatus(brief)
+			Expect(gotStatus).To(Equal(jobStatus))
+			Expect(gotPhase).To(Equal(phase))
+			Expect(gotReason).To(Equal(failReason))
+		},
+		Entry("pending", "pending", JobStatusInProgress, JobPhasePending, ""),
+		Entry("started", "started", JobStatusInProgress, JobPhaseStarted, ""),
+		Entry("validating", "Validating", JobStatusInProgress, JobPhaseValidating, ""),
+		Entry("applying profile", "applying-profile", JobStatusInProgress, JobPhaseApplyingProfile, ""),
+		Entry("rebooting", "rebooting", JobStatusInProgress, JobPhaseRebooting, ""),
+		Entry("verifying", "VERIFYING", JobStatusInProgress, JobPhaseVerifying, ""),
+		Entry("completed", "completed", JobStatusCompleted, JobPhase(""), ""),
+		Entry("failed", "failed", JobStatusFailed, JobPhase(""), "bmc timeout"),
+		Entry("unknown", "paused", JobStatusUnknown, JobPhase(""), "bmc timeout"),
+	)
+})
@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwmgrclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Tenant scoping", func() {
+	var (
+		server *httptest.Server
+		paths  []string
+	)
+
+	BeforeEach(func() {
+		paths = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("{}"))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func(tenant *string) *HardwareManagerClient {
+		apiClient, err := hwmgrapi.NewClientWithResponses(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		return &HardwareManagerClient{
+			HwmgrClient: apiClient,
+			hwmgr: &pluginv1alpha1.HardwareManager{
+				Spec: pluginv1alpha1.HardwareManagerSpec{
+					DellData: &pluginv1alpha1.DellData{ApiUrl: server.URL, Tenant: tenant},
+				},
+			},
+		}
+	}
+
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		Spec: hwmgmtv1alpha1.NodePoolSpec{CloudID: "cloud-1"},
+	}
+
+	DescribeTable("passes the tenant in the path of outgoing requests",
+		func(tenant *string, expected string) {
+			c := newClient(tenant)
+
+			_, err := c.GetResourceGroup(context.TODO(), nodepool)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = c.getResourcePoolsPage(context.TODO(), 0, ListPageSize)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(paths).To(Equal([]string{
+				"/v1/tenants/" + expected + "/resourcegroups/rhplugin-rg-cloud-1",
+				"/v1/tenants/" + expected + "/search/resourcepools",
+			}))
+		},
+		Entry("configured tenant", ptr.To("tenant-a"), "tenant-a"),
+		Entry("unset tenant", nil, DefaultTenant),
+		Entry("empty tenant", ptr.To(""), DefaultTenant),
+	)
+})
@@ -21,9 +21,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
+	"strconv"
 
 	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
@@ -31,6 +35,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
 )
 
 const (
@@ -66,25 +71,37 @@ type BMCCredentials struct {
 	Password string `json:"bmc_password"`
 }
 
-func bmcSecretName(nodename string) string {
-	return fmt.Sprintf("%s-bmc-secret", nodename)
-}
-
 // AllocateNode processes a NodePool CR, allocating a free node for each specified nodegroup as needed
 func (a *Adaptor) AllocateNode(
 	ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	namer *utils.NodeNamer,
 	nodepool *hwmgmtv1alpha1.NodePool,
 	resource hwmgrapi.RhprotoResource,
 	nodegroupName string) (string, error) {
-	nodename := utils.GenerateNodeName()
+	backendName := ""
+	if resource.Name != nil {
+		backendName = *resource.Name
+	}
+	nodename, err := namer.Generate(ctx, nodegroupName, backendName)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate node name: %w", err)
+	}
 	ctx = logging.AppendCtx(ctx, slog.String("nodename", nodename))
 
 	if err := a.ValidateNodeConfig(ctx, resource); err != nil {
 		return "", fmt.Errorf("failed to validate resource configuration: %w", err)
 	}
 
-	if err := a.CreateBMCSecret(ctx, hwmgrClient, nodepool, nodename, resource); err != nil {
+	// The node is selected by the backend, so a blocked node is rejected and the allocation retried
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if nodegroup.NodePoolData.Name == nodegroupName && utils.IsNodeBlocked(hwmgr, nodegroup.NodePoolData.ResourcePoolId, *resource.Id) {
+			return "", fmt.Errorf("backend allocated blocked node %s for nodegroup %s", *resource.Id, nodegroupName)
+		}
+	}
+
+	if err := a.CreateBMCSecret(ctx, hwmgrClient, nodepool, nodename, nodegroupName, resource); err != nil {
 		return "", fmt.Errorf("failed to create bmc-secret when allocating node %s: %w", nodename, err)
 	}
 
@@ -92,7 +109,7 @@ func (a *Adaptor) AllocateNode(
 		return "", fmt.Errorf("failed to create allocated node (%s): %w", *resource.Id, err)
 	}
 
-	if err := a.SetInitialNodeStatus(ctx, nodename, resource); err != nil {
+	if err := a.SetInitialNodeStatus(ctx, hwmgr, nodepool.Namespace, nodename, resource); err != nil {
 		return nodename, fmt.Errorf("failed to update node status (%s): %w", *resource.Id, err)
 	}
 
@@ -211,7 +228,7 @@ func (a *Adaptor) CreateBMCSecret(
 	ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
 	nodepool *hwmgmtv1alpha1.NodePool,
-	nodename string,
+	nodename, groupname string,
 	resource hwmgrapi.RhprotoResource) error {
 	a.Logger.InfoContext(ctx, "Creating bmc-secret")
 
@@ -226,13 +243,13 @@ func (a *Adaptor) CreateBMCSecret(
 		return fmt.Errorf("unable to parse BMC credentials (%s)", remoteSecretKey)
 	}
 
-	secretName := bmcSecretName(nodename)
+	secretName := utils.BMCSecretName(nodename)
 
 	blockDeletion := true
 	bmcSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secretName,
-			Namespace: a.Namespace,
+			Namespace: nodepool.Namespace,
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         nodepool.APIVersion,
 				Kind:               nodepool.Kind,
@@ -247,7 +264,16 @@ func (a *Adaptor) CreateBMCSecret(
 		},
 	}
 
-	if err = utils.CreateOrUpdateK8sCR(ctx, a.Client, bmcSecret, nil, utils.UPDATE); err != nil {
+	if err := utils.SetNodeMetadata(bmcSecret, nodepool, groupname); err != nil {
+		return fmt.Errorf("failed to set metadata for bmc-secret of node %s: %w", nodename, err)
+	}
+
+	provenance := utils.NewBMCSecretProvenance(pluginv1alpha1.SupportedAdaptors.Dell, nodepool, utils.CredentialsFromSecretAPI)
+	if err := sdk.ApplyBMCSecretProvenance(ctx, a.Client, a.Namespace, bmcSecret, provenance); err != nil {
+		return fmt.Errorf("failed to record provenance for bmc-secret of node %s: %w", nodename, err)
+	}
+
+	if err = utils.ApplyK8sCR(ctx, a.Client, bmcSecret, nil); err != nil {
 		return fmt.Errorf("failed to create bmc-secret for node %s: %w", nodename, err)
 	}
 
@@ -283,7 +309,7 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 	node := &hwmgmtv1alpha1.Node{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      nodename,
-			Namespace: a.Namespace,
+			Namespace: nodepool.Namespace,
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         nodepool.APIVersion,
 				Kind:               nodepool.Kind,
@@ -301,6 +327,19 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 		},
 	}
 
+	netconfig, err := utils.GetNodeGroupNetworkConfig(nodepool, nodegroupName)
+	if err != nil {
+		return fmt.Errorf("failed to get network config for nodegroup %s: %w", nodegroupName, err)
+	}
+
+	if err := utils.SetNodeNetworkConfig(node, netconfig); err != nil {
+		return fmt.Errorf("failed to set network config for node %s: %w", nodename, err)
+	}
+
+	if err := utils.SetNodeMetadata(node, nodepool, nodegroupName); err != nil {
+		return fmt.Errorf("failed to set metadata for node %s: %w", nodename, err)
+	}
+
 	if err := a.Client.Create(ctx, node); err != nil {
 		return fmt.Errorf("failed to create Node: %w", err)
 	}
@@ -309,13 +348,17 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 }
 
 // SetInitialNodeStatus updates a Node CR status field with additional node information from the RhprotoResource
-func (a *Adaptor) SetInitialNodeStatus(ctx context.Context, nodename string, resource hwmgrapi.RhprotoResource) error {
+func (a *Adaptor) SetInitialNodeStatus(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	namespace, nodename string,
+	resource hwmgrapi.RhprotoResource) error {
 	a.Logger.InfoContext(ctx, "Updating node")
 
 	node := &hwmgmtv1alpha1.Node{}
 
 	if err := utils.RetryOnConflictOrRetriableOrNotFound(retry.DefaultRetry, func() error {
-		return a.Get(ctx, types.NamespacedName{Name: nodename, Namespace: a.Namespace}, node)
+		return a.Get(ctx, types.NamespacedName{Name: nodename, Namespace: namespace}, node)
 	}); err != nil {
 		return fmt.Errorf("failed to get Node for update: %w", err)
 	}
@@ -325,27 +368,294 @@ func (a *Adaptor) SetInitialNodeStatus(ctx context.Context, nodename string, res
 		return fmt.Errorf("unable to parse %s from resource", ExtensionsVirtualMediaUrl)
 	}
 
-	node.Status.BMC = &hwmgmtv1alpha1.BMC{
-		Address:         virtualMediaUrl,
-		CredentialsName: bmcSecretName(nodename),
+	bmcAddress, err := utils.FormatBMCAddress(hwmgr, node.Spec.HwProfile, virtualMediaUrl)
+	if err != nil {
+		return fmt.Errorf("invalid BMC address for node %s: %w", nodename, err)
 	}
 
-	var parseErr error
-	if node.Status.Interfaces, parseErr = a.getNodeInterfaces(resource); parseErr != nil {
-		return fmt.Errorf("invalid interface list: %w", parseErr)
+	interfaces, err := a.getNodeInterfaces(resource)
+	if err != nil {
+		return fmt.Errorf("invalid interface list: %w", err)
 	}
 
-	utils.SetStatusCondition(&node.Status.Conditions,
-		string(hwmgmtv1alpha1.Provisioned),
-		string(hwmgmtv1alpha1.Completed),
-		metav1.ConditionTrue,
-		"Provisioned")
+	// The backend does not report interface roles, so only those of the hardware profile are applied
+	roles := utils.ResolveInterfaceRoles(interfaces, nil, utils.GetHwProfileInterfaceRoles(hwmgr, node.Spec.HwProfile))
+	if err := sdk.PublishNodeInterfaceRoles(ctx, a.Client, node, roles); err != nil {
+		return err
+	}
 
-	node.Status.HwProfile = node.Spec.HwProfile
+	if err := sdk.ApplyNodeSecrets(ctx, a.Client, hwmgr, node, nil); err != nil {
+		return err
+	}
 
-	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node); err != nil {
+	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+		node.Status.BMC = &hwmgmtv1alpha1.BMC{
+			Address:         bmcAddress,
+			CredentialsName: utils.BMCSecretName(nodename),
+		}
+		node.Status.Interfaces = interfaces
+
+		// The backend does not report the security state, so a node allocated with a hardware profile that defines
+		// security requirements cannot be verified, and is failed
+		if utils.VerifyNodeSecurity(hwmgr, node, utils.NodeSecurityState{}) {
+			utils.SetStatusCondition(&node.Status.Conditions,
+				string(hwmgmtv1alpha1.Provisioned),
+				string(hwmgmtv1alpha1.Completed),
+				metav1.ConditionTrue,
+				"Provisioned")
+		}
+
+		node.Status.HwProfile = node.Spec.HwProfile
+	}); err != nil {
 		return fmt.Errorf("failed to update status for node %s: %w", nodename, err)
 	}
 
 	return nil
 }
+
+// getServerPowerStatus determines the power state and boot progress of a server from its inventory data
+func getServerPowerStatus(server *hwmgrapi.ApiprotoServer) (utils.PowerState, utils.BootProgress) {
+	if server.Status == nil || server.Status.PowerState == nil {
+		return utils.PowerStateUnknown, utils.BootProgressUnknown
+	}
+
+	powerState := utils.NormalizePowerState(*server.Status.PowerState)
+	switch powerState {
+	case utils.PowerStateOff:
+		return powerState, utils.BootProgressNone
+	case utils.PowerStateOn:
+		// The OS details are only populated once the OS is up and reporting to the BMC
+		if osDetails := server.Status.OSDetails; osDetails != nil &&
+			((osDetails.HostName != nil && *osDetails.HostName != "") ||
+				(osDetails.OSVersion != nil && *osDetails.OSVersion != "")) {
+			return powerState, utils.BootProgressOSRunning
+		}
+		return powerState, utils.BootProgressBooting
+	default:
+		return powerState, utils.BootProgressUnknown
+	}
+}
+
+// getServerAssetInfo extracts the asset details of a server from its inventory data
+func getServerAssetInfo(server *hwmgrapi.ApiprotoServer) utils.NodeAssetInfo {
+	if server.Status == nil {
+		return utils.NodeAssetInfo{}
+	}
+
+	return utils.NodeAssetInfo{
+		SerialNumber: ptr.Deref(server.Status.SerialNumber, ""),
+		AssetTag:     ptr.Deref(server.Status.AssetTag, ""),
+		Model:        ptr.Deref(server.Status.Model, ""),
+		Vendor:       ptr.Deref(server.Status.Manufacturer, ""),
+	}
+}
+
+// getServerBootCapabilities extracts the boot capabilities of a server from its inventory data. The boot devices are
+// those of its boot order, and the BMC address of the node, which is its virtual media URL, is reported if the BMC
+// exposes virtual media.
+func getServerBootCapabilities(server *hwmgrapi.ApiprotoServer, node *hwmgmtv1alpha1.Node) utils.NodeBootCapabilities {
+	if server.Status == nil {
+		return utils.NodeBootCapabilities{}
+	}
+
+	var bootDevices []string
+	for _, boot := range ptr.Deref(server.Status.Boot, nil) {
+		bootDevices = append(bootDevices, ptr.Deref(boot.AliasBootOrder, nil)...)
+	}
+
+	var virtualMediaURLs []string
+	for _, bmc := range ptr.Deref(server.Status.BMC, nil) {
+		if len(ptr.Deref(bmc.VMedia, nil)) > 0 && node.Status.BMC != nil {
+			virtualMediaURLs = append(virtualMediaURLs, node.Status.BMC.Address)
+		}
+	}
+
+	return utils.NewNodeBootCapabilities(bootDevices, virtualMediaURLs)
+}
+
+// getServerInstallHints extracts the hostname and provisioning network details configured for the operating system of
+// a server. Static addressing is only reported if its netmask is valid, given as a prefix length or a dotted mask.
+func getServerInstallHints(server *hwmgrapi.ApiprotoServer) utils.NodeInstallHints {
+	if server.Spec == nil || server.Spec.OSConfig == nil || server.Spec.OSConfig.NetworkingDetails == nil {
+		return utils.NodeInstallHints{}
+	}
+
+	details := server.Spec.OSConfig.NetworkingDetails
+	hints := utils.NodeInstallHints{Hostname: ptr.Deref(details.HostName, "")}
+	mode, err := utils.ParseIPConfigMode(ptr.Deref(details.BootProto, ""))
+	if err != nil || mode != utils.IPConfigStatic {
+		if err == nil {
+			hints.IPConfig = mode
+		}
+		return hints
+	}
+
+	if details.IPDetails == nil {
+		return hints
+	}
+	address := net.ParseIP(ptr.Deref(details.IPDetails.IPAddress, ""))
+	netmask := ptr.Deref(details.IPDetails.NetMask, "")
+	prefix, err := strconv.Atoi(netmask)
+	if err != nil {
+		if mask := net.ParseIP(netmask).To4(); mask != nil {
+			prefix, _ = net.IPMask(mask).Size()
+		}
+	}
+	if address == nil || prefix == 0 {
+		return hints
+	}
+
+	hints.IPConfig = utils.IPConfigStatic
+	hints.Static = &utils.StaticIPConfig{
+		Address:    fmt.Sprintf("%s/%d", address, prefix),
+		Gateway:    ptr.Deref(details.IPDetails.Gateway, ""),
+		DNSServers: ptr.Deref(details.DNSServer, nil),
+	}
+	return hints
+}
+
+// RefreshNodePowerStatus queries the hardware manager to update the power state and boot progress, along with the asset
+// details, boot capabilities and install hints, of the allocated nodes. The server inventory is served from the
+// inventory cache.
+func (a *Adaptor) RefreshNodePowerStatus(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+
+		server, err := sdk.CachedInventoryQuery(hwmgr, "server/"+node.Spec.HwMgrNodeId, func() (*hwmgrapi.ApiprotoServer, error) {
+			return hwmgrClient.GetServerInventory(ctx, node) // nolint: wrapcheck
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get server inventory for node %s: %w", node.Name, err)
+		}
+
+		if err := sdk.PublishNodeAssetInfo(ctx, a.Client, node, getServerAssetInfo(server)); err != nil {
+			return err
+		}
+
+		if err := sdk.PublishNodeBootCapabilities(ctx, a.Client, node, getServerBootCapabilities(server, node)); err != nil {
+			return err
+		}
+
+		if err := sdk.PublishNodeInstallHints(ctx, a.Client, node, getServerInstallHints(server)); err != nil {
+			return err
+		}
+
+		powerState, bootProgress := getServerPowerStatus(server)
+		if !utils.SetNodePowerStatus(node, powerState, bootProgress) {
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Node power status changed",
+			slog.String("nodename", node.Name),
+			slog.String("powerState", string(powerState)),
+			slog.String("bootProgress", string(bootProgress)))
+		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+			utils.SetNodePowerStatus(node, powerState, bootProgress)
+		}); err != nil {
+			return fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ResyncNodeHardware refreshes the interfaces and their roles, and the BMC address of the allocated nodes from the
+// hardware manager, then checks the allocation of the NodePool for drift
+func (a *Adaptor) ResyncNodeHardware(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	var backend []utils.BackendNodeState
+	queue := sdk.GetNodeStatusQueue(hwmgr.Name, hwmgr.Spec.MaxNodeStatusUpdatesPerSecond)
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+
+		rsp, err := hwmgrClient.GetResource(ctx, node)
+		if err != nil {
+			return fmt.Errorf("failed to get resource for node %s: %w", node.Name, err)
+		}
+		if rsp == nil || rsp.Resource == nil {
+			return fmt.Errorf("resource data missing from response for node %s", node.Name)
+		}
+
+		backend = append(backend, utils.BackendNodeState{
+			NodeName:       node.Name,
+			GroupName:      node.Spec.GroupName,
+			HwProfile:      ptr.Deref(rsp.Resource.ResourceProfileID, ""),
+			ResourcePoolId: ptr.Deref(rsp.Resource.ResourcePoolId, ""),
+		})
+
+		// The interface and BMC details are parsed from the resource extensions
+		resource := hwmgrapi.RhprotoResource{Extensions: rsp.Resource.Extensions}
+
+		interfaces, err := a.getNodeInterfaces(resource)
+		if err != nil {
+			return fmt.Errorf("invalid interface list for node %s: %w", node.Name, err)
+		}
+
+		virtualMediaUrl, err := a.parseExtensionVirtualMediaUrl(resource)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s from resource for node %s: %w", ExtensionsVirtualMediaUrl, node.Name, err)
+		}
+
+		roles := utils.ResolveInterfaceRoles(interfaces, nil, utils.GetHwProfileInterfaceRoles(hwmgr, node.Spec.HwProfile))
+		if err := sdk.PublishNodeInterfaceRoles(ctx, a.Client, node, roles); err != nil {
+			return err
+		}
+
+		if err := sdk.ApplyNodeSecrets(ctx, a.Client, hwmgr, node, nil); err != nil {
+			return err
+		}
+
+		bmcAddress, err := utils.FormatBMCAddress(hwmgr, node.Spec.HwProfile, virtualMediaUrl)
+		if err != nil {
+			a.Logger.InfoContext(ctx, "Skipping BMC address resync",
+				slog.String("nodename", node.Name),
+				slog.String("error", err.Error()))
+			if node.Status.BMC != nil {
+				bmcAddress = node.Status.BMC.Address
+			}
+		}
+
+		if !utils.ApplyNodeHardwareResync(node, interfaces, bmcAddress) {
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Node hardware changed", slog.String("nodename", node.Name))
+		queue.Enqueue(node, func(latest *hwmgmtv1alpha1.Node) bool {
+			return utils.ApplyNodeHardwareResync(latest, interfaces, bmcAddress)
+		})
+	}
+
+	// The status writes are spread out at the rate limit of the hardware manager, across the NodePools being resynced
+	if err := queue.Flush(ctx, a.Client); err != nil {
+		return fmt.Errorf("failed to update node status: %w", err)
+	}
+
+	drift, err := utils.DetectNodePoolDrift(nodepool, nodelist, backend)
+	if err != nil {
+		return fmt.Errorf("failed to check drift for NodePool %s: %w", nodepool.Name, err)
+	}
+	if err := utils.UpdateNodePoolDriftCondition(ctx, a.Client, nodepool, drift); err != nil {
+		return fmt.Errorf("failed to update drift status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
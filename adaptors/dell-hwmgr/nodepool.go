@@ -18,6 +18,7 @@ package dellhwmgr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
@@ -26,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
@@ -36,6 +38,23 @@ import (
 
 // ValidateNodePool performs basic validation of the nodepool data
 func (a *Adaptor) ValidateNodePool(nodepool *hwmgmtv1alpha1.NodePool) error {
+	if err := utils.ValidateNodePoolNodeGroupSizes(nodepool); err != nil {
+		return fmt.Errorf("invalid nodegroup size: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNetworkConfig(nodepool); err != nil {
+		return fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNodeNameTemplate(nodepool); err != nil {
+		return fmt.Errorf("invalid node naming policy: %w", err)
+	}
+
+	// Nodes are allocated as a resource group, so nodes allocated outside the plugin cannot be imported
+	if _, exists := nodepool.Spec.Extensions[utils.AdoptNodesKey]; exists {
+		return utils.NewInputError("node adoption is not supported by the dell-hwmgr adaptor")
+	}
+
 	return nil
 }
 
@@ -46,16 +65,14 @@ func (a *Adaptor) HandleNodePoolCreate(
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
-	conditionType := hwmgmtv1alpha1.Provisioned
 	var conditionReason hwmgmtv1alpha1.ConditionReason
-	var conditionStatus metav1.ConditionStatus
 	var message string
 
 	// Validate the nodepool data
 	if validationErr := a.ValidateNodePool(nodepool); validationErr != nil {
-		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-			"NodePool configuration invalid: "+validationErr.Error()); err != nil {
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Failed, "NodePool configuration invalid: "+validationErr.Error()).
+			Update(ctx, a.Client); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 		}
@@ -63,29 +80,42 @@ func (a *Adaptor) HandleNodePoolCreate(
 		return utils.DoNotRequeue(), nil
 	}
 
+	// The resource group is provisioned as a whole, holding an allocation slot for each of its nodes
+	size := utils.GetNodePoolSize(nodepool)
+	throttle := sdk.GetAllocationThrottle(hwmgr.Name, utils.GetMaxConcurrentAllocations(hwmgr))
+	if !throttle.TryAcquire(nodepool.Name, size) {
+		a.Logger.InfoContext(ctx, "NodePool allocation throttled", slog.Int("queuedNodes", size),
+			slog.Int("maxConcurrentAllocations", utils.GetMaxConcurrentAllocations(hwmgr)))
+		if err := sdk.MarkNodePoolAllocationQueued(ctx, a.Client, nodepool, size); err != nil {
+			return utils.RequeueWithMediumInterval(), err
+		}
+		return utils.RequeueWithShortInterval(), nil
+	}
+
 	if err := a.ProcessNewNodePool(ctx, hwmgrClient, hwmgr, nodepool); err != nil {
-		a.Logger.Error("failed createNodePool", "err", err)
+		throttle.Release(nodepool.Name)
+		if errors.Is(err, utils.ErrReadOnly) {
+			// The resource group is created once the plugin leaves read-only mode
+			return utils.RequeueWithLongInterval(), err
+		}
+		a.Logger.ErrorContext(ctx, "failed createNodePool", slog.String("error", err.Error()))
+		if err := sdk.ReportBackendError(ctx, a.Client, nodepool, err); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
 		conditionReason = hwmgmtv1alpha1.Failed
-		conditionStatus = metav1.ConditionFalse
 		message = "Creation request failed: " + err.Error()
 	} else {
 		conditionReason = hwmgmtv1alpha1.InProgress
-		conditionStatus = metav1.ConditionFalse
 		message = "Handling creation"
 	}
 
-	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-		conditionType, conditionReason, conditionStatus, message); err != nil {
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithProvisioned(conditionReason, message).
+		WithObservedGeneration().
+		Update(ctx, a.Client); err != nil {
 		return utils.RequeueWithMediumInterval(),
 			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
-
-	// Update the Node Pool hwMgrPlugin status
-	if err := utils.UpdateNodePoolPluginStatus(ctx, a.Client, nodepool); err != nil {
-		return utils.RequeueWithShortInterval(),
-			fmt.Errorf("failed to update hwMgrPlugin observedGeneration for NodePool %s: Status: %w",
-				nodepool.Name, err)
-	}
 	return utils.DoNotRequeue(), nil
 }
 
@@ -101,14 +131,19 @@ func (a *Adaptor) ProcessNewNodePool(ctx context.Context,
 	if err != nil {
 		return fmt.Errorf("failed CreateResourceGroup: %w", err)
 	}
+	sdk.InvalidateInventoryCache(hwmgr.Name)
 
 	// Add the jobId in an annotation
-	utils.SetJobId(nodepool, jobId)
+	utils.SetJobId(nodepool, jobId, "CreateResourceGroup")
 
 	if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, nodepool, nil, utils.PATCH); err != nil {
 		return fmt.Errorf("failed to annotate nodepool %s: %w", nodepool.Name, err)
 	}
 
+	if err := utils.UpdateBackendJobStatus(ctx, a.Client, nodepool); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
 	return nil
 }
 
@@ -130,8 +165,12 @@ func (a *Adaptor) HandleNodePoolProcessing(
 
 	ctx = logging.AppendCtx(ctx, slog.String("jobId", jobId))
 
+	// Re-establish the allocation slots held while the resource group is being provisioned
+	throttle := sdk.GetAllocationThrottle(hwmgr.Name, utils.GetMaxConcurrentAllocations(hwmgr))
+	throttle.Set(nodepool.Name, utils.GetNodePoolSize(nodepool))
+
 	// Query the hardware manager for the job status
-	status, failReason, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+	status, phase, failReason, err := hwmgrClient.CheckJobProgress(ctx, jobId)
 	if err != nil {
 		a.Logger.InfoContext(ctx, "Resource group check failed", slog.String("error", err.Error()))
 		return result, fmt.Errorf("failed to check job progress, jobId=%s: %w", jobId, err)
@@ -140,44 +179,46 @@ func (a *Adaptor) HandleNodePoolProcessing(
 	// Process the status response
 	switch status {
 	case hwmgrclient.JobStatusInProgress:
+		if err := sdk.UpdateJobPhase(ctx, a.Client, nodepool, jobId, string(phase)); err != nil {
+			return utils.RequeueWithShortInterval(), err // nolint: wrapcheck
+		}
 		return utils.RequeueWithShortInterval(), nil
 	case hwmgrclient.JobStatusFailed:
+		throttle.Release(nodepool.Name)
 		a.Logger.InfoContext(ctx, "Resource group creation failed", slog.String("failReason", failReason))
-		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-			fmt.Sprintf("Resource group creation failed: %s", failReason)); err != nil {
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Failed, fmt.Sprintf("Resource group creation failed: %s", failReason)).
+			Update(ctx, a.Client); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 		}
 		return result, fmt.Errorf("resource group creation failed, jobId=%s: %s", jobId, failReason)
 	case hwmgrclient.JobStatusCompleted:
+		throttle.Release(nodepool.Name)
 		a.Logger.InfoContext(ctx, "Job has completed")
 	default:
 		a.Logger.InfoContext(ctx, "Resource group check returned unknown status", slog.String("failReason", failReason))
 		return result, fmt.Errorf("failed to check job progress, jobId=%s: %s", jobId, failReason)
 	}
 
+	if result, wait := sdk.WaitForAllocationRetry(nodepool); wait {
+		a.Logger.InfoContext(ctx, "Waiting to retry NodePool allocation")
+		return result, nil
+	}
+
 	// The job has completed. Get the resource group data from the hardware manager
 	rg, err := hwmgrClient.GetResourceGroup(ctx, nodepool)
 	if err != nil {
 		a.Logger.InfoContext(ctx, "Failed GetResourceGroup", slog.String("error", err.Error()))
-
-		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-			"Failed to get resource group: "+err.Error()); err != nil {
-			return utils.RequeueWithMediumInterval(),
-				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
-		}
-
-		return utils.DoNotRequeue(), nil
+		return sdk.RetryNodePoolAllocation(ctx, a.Client, hwmgr, nodepool, fmt.Errorf("failed to get resource group: %w", err))
 	}
 
 	a.Logger.InfoContext(ctx, fmt.Sprintf("Validating ResourceGroup %s with nodepool %s", *rg.Id, nodepool.Name))
 	if err := hwmgrClient.ValidateResourceGroup(ctx, nodepool, *rg); err != nil {
 		a.Logger.InfoContext(ctx, fmt.Sprintf("Validation failed for ResourceGroup %s with nodepool %s", *rg.Id, nodepool.Name), slog.String("error", err.Error()))
-		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-			"Failed to validate resource group: "+err.Error()); err != nil {
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Failed, "Failed to validate resource group: "+err.Error()).
+			Update(ctx, a.Client); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 		}
@@ -191,8 +232,24 @@ func (a *Adaptor) HandleNodePoolProcessing(
 		return utils.RequeueWithMediumInterval(), fmt.Errorf("failed to query node list: %w", err)
 	}
 
+	namer, err := utils.NewNodeNamer(a.Client, nodepool.Namespace, hwmgr, nodepool)
+	if err != nil {
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Failed, "Invalid node naming policy: "+err.Error()).
+			Update(ctx, a.Client); err != nil {
+			return utils.RequeueWithMediumInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+
+		return utils.DoNotRequeue(), nil
+	}
+
 	// Create the Node CRs corresponding to the allocated resources
 	for nodegroupName, resourceSelector := range *rg.ResourceSelectors {
+		if resourceSelector.Resources == nil {
+			// A placeholder nodegroup holds no resources
+			continue
+		}
 		for _, node := range *resourceSelector.Resources {
 			nodename := utils.FindNodeInList(nodelist, nodepool.Spec.HwMgrId, *node.Id)
 			if nodename != "" {
@@ -207,9 +264,9 @@ func (a *Adaptor) HandleNodePoolProcessing(
 					a.Logger.InfoContext(ctx, "Node previously allocated, but not in nodepool properties",
 						slog.String("nodename", nodename),
 						slog.String("nodeId", *node.Id))
-					if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-						hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-						fmt.Sprintf("Failed with partially allocated node: %s, %s", nodename, *node.Id)); err != nil {
+					if err := utils.NewNodePoolStatusBuilder(nodepool).
+						WithProvisioned(hwmgmtv1alpha1.Failed, fmt.Sprintf("Failed with partially allocated node: %s, %s", nodename, *node.Id)).
+						Update(ctx, a.Client); err != nil {
 						return utils.RequeueWithMediumInterval(),
 							fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 					}
@@ -217,16 +274,17 @@ func (a *Adaptor) HandleNodePoolProcessing(
 					return utils.DoNotRequeue(), nil
 				}
 			}
-			if nodename, err := a.AllocateNode(ctx, hwmgrClient, nodepool, node, nodegroupName); err != nil {
+			if nodename, err := a.AllocateNode(ctx, hwmgrClient, hwmgr, namer, nodepool, node, nodegroupName); err != nil {
 				a.Logger.InfoContext(ctx, "Failed allocating node", slog.String("err", err.Error()))
-				if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-					hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-					fmt.Sprintf("Failed to allocate node (%s): %s", *node.Name, err.Error())); err != nil {
+
+				// Record the nodes allocated so far, so they are recognized when the allocation is retried
+				if err := utils.UpdateNodePoolProperties(ctx, a.Client, nodepool); err != nil {
 					return utils.RequeueWithMediumInterval(),
 						fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 				}
 
-				return utils.DoNotRequeue(), nil
+				return sdk.RetryNodePoolAllocation(ctx, a.Client, hwmgr, nodepool,
+					fmt.Errorf("failed to allocate node (%s): %w", *node.Name, err))
 			} else {
 				nodepool.Status.Properties.NodeNames = append(nodepool.Status.Properties.NodeNames, nodename)
 			}
@@ -241,8 +299,13 @@ func (a *Adaptor) HandleNodePoolProcessing(
 
 	a.Logger.InfoContext(ctx, "NodePool request is fully allocated")
 
-	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-		hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Completed, metav1.ConditionTrue, "Created"); err != nil {
+	if err := sdk.ResetAllocationRetries(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithProvisioned(hwmgmtv1alpha1.Completed, "Created").
+		Update(ctx, a.Client); err != nil {
 		return utils.RequeueWithMediumInterval(),
 			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
@@ -252,6 +315,11 @@ func (a *Adaptor) HandleNodePoolProcessing(
 		return ctrl.Result{}, fmt.Errorf("failed to clear annotation from nodepool %s: %w", nodepool.Name, err)
 	}
 
+	if err := utils.UpdateBackendJobStatus(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
 	result = utils.DoNotRequeue()
 
 	return result, nil
@@ -265,11 +333,17 @@ func (a *Adaptor) ReleaseNodePool(ctx context.Context,
 
 	a.Logger.InfoContext(ctx, "Processing ReleaseNodePool request")
 
+	// The nodes of the resource group are released by a single request, which is counted against the rate limit
+	if !sdk.GetReleaseLimiter(hwmgr.Name, hwmgr.Spec.MaxNodeReleasesPerMinute).Allow() {
+		return fmt.Errorf("%w: release rate limit reached", sdk.ErrReleaseDeferred)
+	}
+
 	// Issue a resource group deletion request to the hardware manager
 	jobId, err := hwmgrClient.DeleteResourceGroup(ctx, nodepool)
 	if err != nil {
 		return fmt.Errorf("failed CreateResourceGroup: %w", err)
 	}
+	sdk.InvalidateInventoryCache(hwmgr.Name)
 
 	ctx = logging.AppendCtx(ctx, slog.String("jobId", jobId))
 
@@ -319,7 +393,7 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		}
 
 		// Query the hardware manager for the job status
-		status, failReason, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+		status, phase, failReason, err := hwmgrClient.CheckJobProgress(ctx, jobId)
 		if err != nil {
 			a.Logger.InfoContext(ctx, "Profile update job progress check failed", slog.String("error", err.Error()))
 			return result, fmt.Errorf("failed to check profile update job progress, jobId=%s: %w", jobId, err)
@@ -328,6 +402,9 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		// Process the status response
 		switch status {
 		case hwmgrclient.JobStatusInProgress:
+			if err := sdk.UpdateJobPhase(ctx, a.Client, node, jobId, string(phase)); err != nil {
+				return utils.RequeueWithShortInterval(), err // nolint: wrapcheck
+			}
 			return utils.RequeueWithShortInterval(), nil
 		case hwmgrclient.JobStatusFailed:
 			a.Logger.InfoContext(ctx, "Profile update creation failed", slog.String("failReason", failReason))
@@ -350,8 +427,9 @@ func (a *Adaptor) handleNodePoolConfiguring(
 
 		// Node update is complete
 		a.Logger.InfoContext(ctx, "Node update complete", slog.String("nodename", node.Name))
-		node.Status.HwProfile = node.Spec.HwProfile
-		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node); err != nil {
+		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+			node.Status.HwProfile = node.Spec.HwProfile
+		}); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
 		}
 
@@ -360,6 +438,10 @@ func (a *Adaptor) handleNodePoolConfiguring(
 			return ctrl.Result{}, fmt.Errorf("failed to clear annotation from node %s: %w", node.Name, err)
 		}
 
+		if err := utils.UpdateBackendJobStatus(ctx, a.Client, node); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+		}
+
 		return utils.RequeueImmediately(), nil
 	}
 
@@ -397,26 +479,28 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		node.Spec.HwProfile = newHwProfile
 
 		// Record the jobId in an annotation
-		utils.SetJobId(node, jobId)
+		utils.SetJobId(node, jobId, "UpdateResourceProfile")
 
 		if err = a.Client.Patch(ctx, node, patch); err != nil {
 			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to patch Node %s in namespace %s: %w", node.Name, node.Namespace, err)
 		}
 
+		if err := utils.UpdateBackendJobStatus(ctx, a.Client, node); err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+		}
+
 		// Requeue to check update progress
 		return utils.RequeueWithMediumInterval(), nil
 	}
 
 	// All nodes have been updated
 	a.Logger.InfoContext(ctx, "All nodes have been updated to new profile")
-	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-		hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.ConfigApplied, metav1.ConditionTrue, string(hwmgmtv1alpha1.ConfigSuccess)); err != nil {
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithConfigApplied().
+		WithObservedGeneration().
+		Update(ctx, a.Client); err != nil {
 		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
-	// Update the Node Pool hwMgrPlugin status
-	if err = utils.UpdateNodePoolPluginStatus(ctx, a.Client, nodepool); err != nil {
-		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update hwMgrPlugin observedGeneration Status: %w", err)
-	}
 
 	return result, nil
 }
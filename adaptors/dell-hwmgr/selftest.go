@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// SelfTest authenticates with the hardware manager, lists its resource pools, and fetches the server inventory of an
+// allocated node, if any
+func (a *Adaptor) SelfTest(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.SelfTestStep {
+	test := &sdk.SelfTest{}
+
+	var hwmgrClient *hwmgrclient.HardwareManagerClient
+	test.Run(sdk.SelfTestStepAuthenticate, func() (string, error) {
+		if hwmgr.Spec.DellData == nil {
+			return "", errors.New("missing dellData configuration field")
+		}
+		var err error
+		if hwmgrClient, err = hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr); err != nil {
+			return "", fmt.Errorf("authentication failure: %w", err)
+		}
+		return "Authenticated with " + hwmgr.Spec.DellData.ApiUrl, nil
+	})
+
+	test.Run(sdk.SelfTestStepListResourcePools, func() (string, error) {
+		resp, err := hwmgrClient.GetResourcePools(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to query resource pools: %w", err)
+		}
+
+		var pools []string
+		if resp.ResourcePools != nil {
+			for _, pool := range *resp.ResourcePools {
+				if pool.Id != nil {
+					pools = append(pools, *pool.Id)
+				}
+			}
+		}
+		slices.Sort(pools)
+		return sdk.DescribeResourcePools(pools), nil
+	})
+
+	test.Run(sdk.SelfTestStepGetNode, func() (string, error) {
+		node, err := sdk.GetSelfTestNode(ctx, a.Client, hwmgr)
+		if err != nil {
+			return "", err
+		}
+		if node == nil {
+			return "No allocated nodes to fetch", nil
+		}
+
+		if _, err := hwmgrClient.GetServerInventory(ctx, node); err != nil {
+			return "", fmt.Errorf("failed to get server inventory of node %s: %w", node.Name, err)
+		}
+		return fmt.Sprintf("Fetched server inventory of node %s (%s)", node.Name, node.Spec.HwMgrNodeId), nil
+	})
+
+	return test.Steps
+}
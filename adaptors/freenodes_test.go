@@ -0,0 +1,153 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adaptors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	dellhwmgr "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// freeNodesAdaptor serves the free nodes of its resource pools, recording the queries it is called with
+type freeNodesAdaptor struct {
+	adaptorinterface.HwMgrAdaptorIntf
+	freenodes map[string][]utils.FreeNode
+	queries   []utils.FreeNodeQuery
+	err       error
+}
+
+func (a *freeNodesAdaptor) GetFreeNodes(
+	_ context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	query utils.FreeNodeQuery) ([]utils.FreeNode, error) {
+	a.queries = append(a.queries, query)
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a.freenodes[query.ResourcePoolId], nil
+}
+
+var _ = Describe("Free nodes", func() {
+	var (
+		ctx     context.Context
+		adaptor *freeNodesAdaptor
+		c       *HwMgrAdaptorController
+		hwmgr   *pluginv1alpha1.HardwareManager
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		adaptor = &freeNodesAdaptor{
+			freenodes: map[string][]utils.FreeNode{
+				"pool1": {{NodeId: "node1", ResourcePoolId: "pool1"}, {NodeId: "node2", ResourcePoolId: "pool1"}},
+				"pool2": {{NodeId: "node3", ResourcePoolId: "pool2"}},
+			},
+		}
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		c = &HwMgrAdaptorController{
+			Logger: logger,
+			adaptors: map[string]adaptorinterface.HwMgrAdaptorIntf{
+				LoopbackAdaptorID:  adaptor,
+				DellHwMgrAdaptorID: dellhwmgr.NewAdaptor(nil, nil, logger, "test"),
+			},
+		}
+		hwmgr = &pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "test"},
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				AdaptorID:      LoopbackAdaptorID,
+				InventoryCache: &pluginv1alpha1.InventoryCacheConfig{},
+			},
+		}
+		DeferCleanup(sdk.InvalidateInventoryCache, hwmgr.Name)
+	})
+
+	It("lists the free nodes of the queried resource pool", func() {
+		selector := &utils.NodeSelector{MinCPUs: 8}
+		query := utils.FreeNodeQuery{ResourcePoolId: "pool1", Selector: selector, HwProfile: "profile1"}
+
+		freenodes, err := c.GetFreeNodes(ctx, hwmgr, query)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freenodes).To(Equal(adaptor.freenodes["pool1"]))
+		Expect(adaptor.queries).To(Equal([]utils.FreeNodeQuery{query}))
+	})
+
+	It("caches the free nodes of each query", func() {
+		pool1 := utils.FreeNodeQuery{ResourcePoolId: "pool1"}
+		for range 2 {
+			freenodes, err := c.GetFreeNodes(ctx, hwmgr, pool1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(freenodes).To(Equal(adaptor.freenodes["pool1"]))
+		}
+		Expect(adaptor.queries).To(HaveLen(1))
+
+		// Queries that differ in resource pool, hardware profile or selector are not served from the same entry
+		for _, query := range []utils.FreeNodeQuery{
+			{ResourcePoolId: "pool2"},
+			{ResourcePoolId: "pool1", HwProfile: "profile1"},
+			{ResourcePoolId: "pool1", Selector: &utils.NodeSelector{MinCPUs: 8}},
+			{ResourcePoolId: "pool1", Selector: &utils.NodeSelector{MinCPUs: 16}},
+		} {
+			_, err := c.GetFreeNodes(ctx, hwmgr, query)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(adaptor.queries).To(HaveLen(5))
+
+		// Allocations invalidate the cached free nodes
+		sdk.InvalidateInventoryCache(hwmgr.Name)
+		_, err := c.GetFreeNodes(ctx, hwmgr, pool1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(adaptor.queries).To(HaveLen(6))
+	})
+
+	It("does not cache a failed query", func() {
+		adaptor.err = errors.New("backend unavailable")
+		query := utils.FreeNodeQuery{ResourcePoolId: "pool1"}
+
+		_, err := c.GetFreeNodes(ctx, hwmgr, query)
+		Expect(err).To(MatchError(ContainSubstring("backend unavailable")))
+
+		adaptor.err = nil
+		freenodes, err := c.GetFreeNodes(ctx, hwmgr, query)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freenodes).To(Equal(adaptor.freenodes["pool1"]))
+		Expect(adaptor.queries).To(HaveLen(2))
+	})
+
+	It("reports that the Dell adaptor does not support listing free nodes", func() {
+		hwmgr.Spec.AdaptorID = DellHwMgrAdaptorID
+		_, err := c.GetFreeNodes(ctx, hwmgr, utils.FreeNodeQuery{ResourcePoolId: "pool1"})
+		Expect(errors.Is(err, sdk.ErrNotSupported)).To(BeTrue())
+	})
+
+	It("rejects an unsupported adaptor", func() {
+		hwmgr.Spec.AdaptorID = "unknown"
+		_, err := c.GetFreeNodes(ctx, hwmgr, utils.FreeNodeQuery{ResourcePoolId: "pool1"})
+		Expect(errors.Is(err, ErrUnsupportedAdaptor)).To(BeTrue())
+		Expect(adaptor.queries).To(BeEmpty())
+	})
+})
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/loopback/controller"
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
@@ -38,6 +39,10 @@ type Adaptor struct {
 	Logger    *slog.Logger
 	Namespace string
 	AdaptorID pluginv1alpha1.HardwareManagerAdaptorID
+	// EmulatedBMCAddr is the address the emulated BMC server binds to, with the server disabled if empty or "0"
+	EmulatedBMCAddr string
+
+	index *allocationIndex
 }
 
 func NewAdaptor(client client.Client, scheme *runtime.Scheme, logger *slog.Logger, namespace string) *Adaptor {
@@ -46,6 +51,7 @@ func NewAdaptor(client client.Client, scheme *runtime.Scheme, logger *slog.Logge
 		Scheme:    scheme,
 		Logger:    logger.With("adaptor", "loopback"),
 		Namespace: namespace,
+		index:     newAllocationIndex(),
 	}
 }
 
@@ -62,6 +68,18 @@ func (a *Adaptor) SetupAdaptor(mgr ctrl.Manager) error {
 		return fmt.Errorf("unable to setup loopback adaptor: %w", err)
 	}
 
+	if err := a.setupInventoryController(mgr); err != nil {
+		return fmt.Errorf("unable to setup loopback adaptor: %w", err)
+	}
+
+	if err := a.setupEmulatedBMC(mgr); err != nil {
+		return fmt.Errorf("unable to setup loopback adaptor: %w", err)
+	}
+
+	if err := a.setupAllocationJanitor(mgr); err != nil {
+		return fmt.Errorf("unable to setup loopback adaptor: %w", err)
+	}
+
 	return nil
 }
 
@@ -72,13 +90,14 @@ const (
 	NodePoolFSMCreate = iota
 	NodePoolFSMProcessing
 	NodePoolFSMSpecChanged
+	NodePoolFSMExtend
 	NodePoolFSMNoop
 )
 
-func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) fsmAction {
+func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (fsmAction, error) {
 	if len(nodepool.Status.Conditions) == 0 {
 		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
-		return NodePoolFSMCreate
+		return NodePoolFSMCreate, nil
 	}
 
 	provisionedCondition := meta.FindStatusCondition(
@@ -88,31 +107,74 @@ func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.
 		if provisionedCondition.Status == metav1.ConditionTrue {
 			// Check if the generation has changed
 			if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
+				// A spec change that grows the nodegroups is handled as an extension, adding the new nodes
+				nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+				if err != nil {
+					return NodePoolFSMNoop, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+				}
+				if utils.IsNodePoolExtension(nodepool, nodelist) {
+					a.Logger.InfoContext(ctx, "Handling NodePool extension")
+					return NodePoolFSMExtend, nil
+				}
 				a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
-				return NodePoolFSMSpecChanged
+				return NodePoolFSMSpecChanged, nil
 			}
 			a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
-			return NodePoolFSMNoop
+			return NodePoolFSMNoop, nil
 		}
 
-		return NodePoolFSMProcessing
+		if provisionedCondition.Reason == string(hwmgmtv1alpha1.Failed) {
+			a.Logger.InfoContext(ctx, "NodePool request in Failed state")
+			return NodePoolFSMNoop, nil
+		}
+
+		return NodePoolFSMProcessing, nil
 	}
 
-	return NodePoolFSMNoop
+	return NodePoolFSMNoop, nil
 }
 
 func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 	result := utils.DoNotRequeue()
 
-	switch a.determineAction(ctx, nodepool) {
+	action, err := a.determineAction(ctx, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	switch action {
 	case NodePoolFSMCreate:
 		return a.HandleNodePoolCreate(ctx, hwmgr, nodepool)
 	case NodePoolFSMProcessing:
 		return a.HandleNodePoolProcessing(ctx, hwmgr, nodepool)
 	case NodePoolFSMSpecChanged:
 		return a.HandleNodePoolSpecChanged(ctx, hwmgr, nodepool)
+	case NodePoolFSMExtend:
+		return a.HandleNodePoolExtend(ctx, hwmgr, nodepool)
 	case NodePoolFSMNoop:
-		// Nothing to do
+		if utils.IsNodePoolProvisionedCompleted(nodepool) {
+			// Periodically refresh the power status of the allocated nodes
+			if err := a.RefreshNodePowerStatus(ctx, nodepool); err != nil {
+				a.Logger.InfoContext(ctx, "Failed to refresh node power status", slog.String("error", err.Error()))
+			}
+			// Apply the power cap of the energy policy to the allocated nodes
+			if err := a.ApplyNodePowerCaps(ctx, hwmgr, nodepool); err != nil {
+				a.Logger.InfoContext(ctx, "Failed to apply node power caps", slog.String("error", err.Error()))
+			}
+			// Resync the node hardware details from the backend, if enabled and due
+			if utils.IsNodeResyncDue(hwmgr, nodepool) {
+				if err := a.ResyncNodeHardware(ctx, hwmgr, nodepool); err != nil {
+					a.Logger.InfoContext(ctx, "Failed to resync node hardware", slog.String("error", err.Error()))
+				} else if err := utils.SetNodeResyncTime(ctx, a.Client, nodepool); err != nil {
+					a.Logger.InfoContext(ctx, "Failed to record node resync time", slog.String("error", err.Error()))
+				}
+			}
+			// Heal failed nodes from the spares, and replenish the spares
+			if err := a.ReconcileSpares(ctx, hwmgr, nodepool); err != nil {
+				a.Logger.InfoContext(ctx, "Failed to reconcile spare nodes", slog.String("error", err.Error()))
+			}
+			return utils.RequeueWithMediumInterval(), nil
+		}
 		return result, nil
 	}
 
@@ -128,3 +190,130 @@ func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alp
 
 	return nil
 }
+
+// RestoreNodeBMCSecret re-creates the bmc-secret for a node from the nodelist configmap
+func (a *Adaptor) RestoreNodeBMCSecret(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+
+	_, resources, _, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	info, exists := resources.Nodes[node.Spec.HwMgrNodeId]
+	if !exists {
+		return fmt.Errorf("unable to find nodeinfo for %s", node.Spec.HwMgrNodeId)
+	}
+
+	return a.CreateBMCSecret(ctx, nodepool, node.Name, node.Spec.GroupName, info.BMC.UsernameBase64, info.BMC.PasswordBase64)
+}
+
+// Capabilities reports the features of the loopback adaptor, which simulates all optional features
+func (a *Adaptor) Capabilities(hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.AdaptorCapability {
+	return []pluginv1alpha1.AdaptorCapability{
+		pluginv1alpha1.CapabilityPowerControl,
+		pluginv1alpha1.CapabilityFirmwareUpdate,
+		pluginv1alpha1.CapabilityBIOSConfig,
+		pluginv1alpha1.CapabilityScaleUp,
+		pluginv1alpha1.CapabilityScaleDown,
+		pluginv1alpha1.CapabilityDecommission,
+		pluginv1alpha1.CapabilityConsolidation,
+		pluginv1alpha1.CapabilityCapacityReporting,
+		pluginv1alpha1.CapabilityFreeNodeQuery,
+		pluginv1alpha1.CapabilityConsistencyCheck,
+	}
+}
+
+// getPoolCapacity counts the nodes of a resource pool, given the IDs of the nodes indexed under it. Nodes held as
+// spares are counted as reserved, and free nodes that are blocked as blocked. Failed nodes, or the nodes of a pool
+// under maintenance, are not counted as free.
+func getPoolCapacity(hwmgr *pluginv1alpha1.HardwareManager, poolID string, nodeIds []string, resources cmResources,
+	inuse, reserved map[string]bool) pluginv1alpha1.ResourcePoolCapacity {
+	pool := pluginv1alpha1.ResourcePoolCapacity{ResourcePoolId: poolID}
+	for _, nodeId := range nodeIds {
+		node, exists := resources.Nodes[nodeId]
+		if !exists || node.ResourcePoolID != poolID {
+			continue
+		}
+		pool.TotalNodes++
+		switch {
+		case reserved[nodeId]:
+			pool.ReservedNodes++
+		case inuse[nodeId]:
+			// An allocated node is neither free nor blocked
+		case utils.IsNodeBlocked(hwmgr, poolID, nodeId):
+			pool.BlockedNodes++
+		case !node.Failed && !utils.IsResourcePoolInMaintenance(hwmgr, poolID):
+			pool.FreeNodes++
+		}
+	}
+	return pool
+}
+
+// GetCapacity reports the node capacity of each resource pool in the nodelist configmap
+func (a *Adaptor) GetCapacity(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*pluginv1alpha1.CapacityStatus, error) {
+	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	inuse := getNodesInUse(allocations)
+	reserved := make(map[string]bool)
+	for _, cloud := range allocations.Clouds {
+		for _, spares := range cloud.Spares {
+			for _, nodeId := range spares {
+				reserved[nodeId] = true
+			}
+		}
+	}
+
+	capacity := &pluginv1alpha1.CapacityStatus{LastUpdated: metav1.Now()}
+	for _, poolID := range resources.ResourcePools {
+		pool := getPoolCapacity(hwmgr, poolID, a.index.getPoolNodes(poolID), resources, inuse, reserved)
+		capacity.TotalNodes += pool.TotalNodes
+		capacity.FreeNodes += pool.FreeNodes
+		capacity.ReservedNodes += pool.ReservedNodes
+		capacity.BlockedNodes += pool.BlockedNodes
+		capacity.ResourcePools = append(capacity.ResourcePools, pool)
+	}
+
+	return capacity, nil
+}
+
+// GetFreeNodes lists the free nodes of a resource pool in the nodelist configmap that could be allocated to a
+// prospective nodegroup, in allocation order. Failed nodes, and nodes that cannot hold the storage layout of the
+// hardware profile, are excluded.
+func (a *Adaptor) GetFreeNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, query utils.FreeNodeQuery) ([]utils.FreeNode, error) {
+	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	if !slices.Contains(resources.ResourcePools, query.ResourcePoolId) {
+		return nil, utils.NewInputError("unknown resource pool: %s", query.ResourcePoolId)
+	}
+
+	storage := utils.GetHwProfileStorageLayout(hwmgr, query.HwProfile)
+	if err := utils.ValidateStorageLayout(storage); err != nil {
+		return nil, utils.NewInputError("invalid storage layout for hardware profile %s: %s", query.HwProfile, err.Error())
+	}
+
+	freenodes := []utils.FreeNode{}
+	for _, nodeId := range a.getFreeNodesInPool(hwmgr, resources, allocations, nil, query.ResourcePoolId, query.Selector) {
+		info := resources.Nodes[nodeId]
+		if info.Failed || utils.CheckHwProfileCompatibility(hwmgr, query.HwProfile, info.capabilities()) != nil {
+			continue
+		}
+		freenodes = append(freenodes, utils.FreeNode{
+			NodeId:         nodeId,
+			ResourcePoolId: info.ResourcePoolID,
+			Attributes:     info.attributes(),
+			Site:           info.Site,
+		})
+	}
+
+	return freenodes, nil
+}
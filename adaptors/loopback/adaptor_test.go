@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Pool capacity", func() {
+	resources := cmResources{
+		ResourcePools: []string{"pool1", "pool2"},
+		Nodes: map[string]cmNodeInfo{
+			"node1": {ResourcePoolID: "pool1"},
+			"node2": {ResourcePoolID: "pool1"},
+			"node3": {ResourcePoolID: "pool1"},
+			"node4": {ResourcePoolID: "pool1", Failed: true},
+			"node5": {ResourcePoolID: "pool2"},
+		},
+	}
+	nodeIds := []string{"node1", "node2", "node3", "node4"}
+
+	DescribeTable("counts the nodes of the pool",
+		func(spec pluginv1alpha1.HardwareManagerSpec, nodeIds []string, inuse, reserved map[string]bool,
+			expected pluginv1alpha1.ResourcePoolCapacity) {
+			hwmgr := &pluginv1alpha1.HardwareManager{Spec: spec}
+			expected.ResourcePoolId = "pool1"
+			Expect(getPoolCapacity(hwmgr, "pool1", nodeIds, resources, inuse, reserved)).To(Equal(expected))
+		},
+		Entry("with all healthy nodes free", pluginv1alpha1.HardwareManagerSpec{}, nodeIds, nil, nil,
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 4, FreeNodes: 3}),
+		Entry("skipping indexed nodes that are unknown or in another pool", pluginv1alpha1.HardwareManagerSpec{},
+			[]string{"node1", "node5", "node9"}, nil, nil,
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 1, FreeNodes: 1}),
+		Entry("with allocated nodes neither free nor blocked",
+			pluginv1alpha1.HardwareManagerSpec{BlockedNodes: []pluginv1alpha1.BlockedNode{{NodeId: "node1"}}},
+			nodeIds, map[string]bool{"node1": true, "node2": true}, nil,
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 4, FreeNodes: 1}),
+		Entry("with spares reserved ahead of being allocated or blocked",
+			pluginv1alpha1.HardwareManagerSpec{BlockedNodes: []pluginv1alpha1.BlockedNode{{NodeId: "node2"}}},
+			nodeIds, map[string]bool{"node1": true}, map[string]bool{"node1": true, "node2": true},
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 4, FreeNodes: 1, ReservedNodes: 2}),
+		Entry("with free nodes blocked in any pool or in this pool",
+			pluginv1alpha1.HardwareManagerSpec{BlockedNodes: []pluginv1alpha1.BlockedNode{
+				{NodeId: "node1"},
+				{NodeId: "node2", ResourcePoolId: "pool1"},
+				{NodeId: "node3", ResourcePoolId: "pool2"},
+			}},
+			nodeIds, nil, nil,
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 4, FreeNodes: 1, BlockedNodes: 2}),
+		Entry("with a failed node blocked rather than free",
+			pluginv1alpha1.HardwareManagerSpec{BlockedNodes: []pluginv1alpha1.BlockedNode{{NodeId: "node4"}}},
+			nodeIds, nil, nil,
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 4, FreeNodes: 3, BlockedNodes: 1}),
+		Entry("with no free nodes under maintenance",
+			pluginv1alpha1.HardwareManagerSpec{
+				MaintenancePools: []string{"pool1"},
+				BlockedNodes:     []pluginv1alpha1.BlockedNode{{NodeId: "node1"}},
+			},
+			nodeIds, map[string]bool{"node2": true}, map[string]bool{"node3": true},
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 4, ReservedNodes: 1, BlockedNodes: 1}),
+		Entry("with maintenance of another pool ignored",
+			pluginv1alpha1.HardwareManagerSpec{MaintenancePools: []string{"pool2"}}, nodeIds, nil, nil,
+			pluginv1alpha1.ResourcePoolCapacity{TotalNodes: 4, FreeNodes: 3}),
+	)
+})
@@ -0,0 +1,210 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// allocationIndex is an in-memory index of the nodelist configmap, mapping each resource pool to its nodes and free
+// nodes, and each cloud to the nodes it holds. The index is shared by all reconciles of the adaptor and is updated
+// incrementally from the parsed configmap data whenever the configmap resourceVersion changes, so that free node
+// lookups cost the size of the resource pool rather than a scan of the full inventory.
+type allocationIndex struct {
+	mu              sync.RWMutex
+	resourceVersion string
+	// nodePools maps each node ID to its resource pool
+	nodePools map[string]string
+	// poolNodes and freeNodes map each resource pool to its nodes, and to those that are not in use
+	poolNodes map[string]map[string]bool
+	freeNodes map[string]map[string]bool
+	// nodeOwners maps each node in use to the cloud that holds it, which is empty for decommissioned nodes
+	nodeOwners map[string]string
+	// cloudNodes maps each cloud to the nodes it holds
+	cloudNodes map[string]map[string]bool
+}
+
+func newAllocationIndex() *allocationIndex {
+	return &allocationIndex{
+		nodePools:  make(map[string]string),
+		poolNodes:  make(map[string]map[string]bool),
+		freeNodes:  make(map[string]map[string]bool),
+		nodeOwners: make(map[string]string),
+		cloudNodes: make(map[string]map[string]bool),
+	}
+}
+
+// getNodeOwners returns the cloud that holds each node in use, with an empty cloud ID for decommissioned nodes
+func getNodeOwners(allocations cmAllocations) map[string]string {
+	owners := make(map[string]string)
+	for i := range allocations.Clouds {
+		cloud := &allocations.Clouds[i]
+		for _, nodeId := range cloud.nodesInUse() {
+			owners[nodeId] = cloud.CloudID
+		}
+	}
+	for _, nodeId := range allocations.Decommissioned {
+		owners[nodeId] = ""
+	}
+	return owners
+}
+
+// sync brings the index up to date with the parsed configmap data, updating only the entries that have changed since
+// the last sync. The data is not retained by the index.
+func (idx *allocationIndex) sync(resourceVersion string, resources cmResources, allocations cmAllocations) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if resourceVersion != "" && resourceVersion == idx.resourceVersion {
+		return
+	}
+
+	owners := getNodeOwners(allocations)
+	for nodeId, owner := range idx.nodeOwners {
+		if current, inuse := owners[nodeId]; !inuse || current != owner {
+			idx.release(nodeId)
+		}
+	}
+
+	for nodeId := range idx.nodePools {
+		if _, exists := resources.Nodes[nodeId]; !exists {
+			idx.removeNode(nodeId)
+		}
+	}
+	for nodeId, info := range resources.Nodes {
+		if poolID, exists := idx.nodePools[nodeId]; !exists || poolID != info.ResourcePoolID {
+			idx.removeNode(nodeId)
+			idx.addNode(nodeId, info.ResourcePoolID)
+		}
+	}
+
+	for nodeId, owner := range owners {
+		if _, inuse := idx.nodeOwners[nodeId]; !inuse {
+			idx.claim(nodeId, owner)
+		}
+	}
+
+	idx.resourceVersion = resourceVersion
+}
+
+// invalidate forces the next sync to bring the index up to date, regardless of the resourceVersion
+func (idx *allocationIndex) invalidate() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.resourceVersion = ""
+}
+
+// addNode must be called with the lock held
+func (idx *allocationIndex) addNode(nodeId, poolID string) {
+	idx.nodePools[nodeId] = poolID
+	addToSet(idx.poolNodes, poolID, nodeId)
+	if _, inuse := idx.nodeOwners[nodeId]; !inuse {
+		addToSet(idx.freeNodes, poolID, nodeId)
+	}
+}
+
+// removeNode must be called with the lock held
+func (idx *allocationIndex) removeNode(nodeId string) {
+	poolID, exists := idx.nodePools[nodeId]
+	if !exists {
+		return
+	}
+	delete(idx.nodePools, nodeId)
+	removeFromSet(idx.poolNodes, poolID, nodeId)
+	removeFromSet(idx.freeNodes, poolID, nodeId)
+}
+
+// claim must be called with the lock held
+func (idx *allocationIndex) claim(nodeId, owner string) {
+	idx.nodeOwners[nodeId] = owner
+	if owner != "" {
+		addToSet(idx.cloudNodes, owner, nodeId)
+	}
+	if poolID, exists := idx.nodePools[nodeId]; exists {
+		removeFromSet(idx.freeNodes, poolID, nodeId)
+	}
+}
+
+// release must be called with the lock held
+func (idx *allocationIndex) release(nodeId string) {
+	owner := idx.nodeOwners[nodeId]
+	delete(idx.nodeOwners, nodeId)
+	if owner != "" {
+		removeFromSet(idx.cloudNodes, owner, nodeId)
+	}
+	if poolID, exists := idx.nodePools[nodeId]; exists {
+		addToSet(idx.freeNodes, poolID, nodeId)
+	}
+}
+
+func addToSet(sets map[string]map[string]bool, key, member string) {
+	if sets[key] == nil {
+		sets[key] = make(map[string]bool)
+	}
+	sets[key][member] = true
+}
+
+func removeFromSet(sets map[string]map[string]bool, key, member string) {
+	delete(sets[key], member)
+	if len(sets[key]) == 0 {
+		delete(sets, key)
+	}
+}
+
+func setMembers(set map[string]bool) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	slices.Sort(members)
+	return members
+}
+
+// getFreeNodes returns the nodes of the resource pool that are not in use. The index reflects the configmap as of the
+// last sync, so the in-use nodes of the cloud being updated by the caller, if any, are taken from the caller's copy of
+// its allocation to account for the claims and releases that are yet to be saved.
+func (idx *allocationIndex) getFreeNodes(poolID string, cloud *cmAllocatedCloud) []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if cloud == nil {
+		return setMembers(idx.freeNodes[poolID])
+	}
+
+	free := maps.Clone(idx.freeNodes[poolID])
+	if free == nil {
+		free = make(map[string]bool)
+	}
+	for nodeId := range idx.cloudNodes[cloud.CloudID] {
+		if idx.nodePools[nodeId] == poolID {
+			free[nodeId] = true
+		}
+	}
+	for _, nodeId := range cloud.nodesInUse() {
+		delete(free, nodeId)
+	}
+	return setMembers(free)
+}
+
+// getPoolNodes returns the nodes of the resource pool
+func (idx *allocationIndex) getPoolNodes(poolID string) []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return setMembers(idx.poolNodes[poolID])
+}
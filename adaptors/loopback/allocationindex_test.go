@@ -0,0 +1,179 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newIndexResources returns the resources of the nodes, mapped to their resource pools
+func newIndexResources(nodePools map[string]string) cmResources {
+	resources := cmResources{
+		SchemaVersion: resourcesSchema.Version(),
+		Nodes:         make(map[string]cmNodeInfo),
+	}
+	for nodeId, poolID := range nodePools {
+		resources.Nodes[nodeId] = cmNodeInfo{ResourcePoolID: poolID}
+		if !slices.Contains(resources.ResourcePools, poolID) {
+			resources.ResourcePools = append(resources.ResourcePools, poolID)
+		}
+	}
+	return resources
+}
+
+// newIndexAllocations returns the allocations of the clouds, each holding the listed nodes in its master nodegroup
+func newIndexAllocations(cloudNodes map[string][]string, decommissioned ...string) cmAllocations {
+	allocations := cmAllocations{
+		SchemaVersion:  allocationsSchema.Version(),
+		Decommissioned: decommissioned,
+	}
+	for cloudID, nodes := range cloudNodes {
+		allocations.Clouds = append(allocations.Clouds, cmAllocatedCloud{
+			CloudID:    cloudID,
+			Nodegroups: map[string][]string{"master": nodes},
+		})
+	}
+	return allocations
+}
+
+var _ = Describe("Allocation index", func() {
+	var idx *allocationIndex
+
+	BeforeEach(func() {
+		idx = newAllocationIndex()
+		idx.sync("1",
+			newIndexResources(map[string]string{
+				"node1": "pool1", "node2": "pool1", "node3": "pool1", "node4": "pool1", "node5": "pool2",
+			}),
+			newIndexAllocations(map[string][]string{"cloud1": {"node1"}}))
+	})
+
+	It("indexes the nodes and free nodes of each resource pool", func() {
+		Expect(idx.getPoolNodes("pool1")).To(Equal([]string{"node1", "node2", "node3", "node4"}))
+		Expect(idx.getFreeNodes("pool1", nil)).To(Equal([]string{"node2", "node3", "node4"}))
+		Expect(idx.getFreeNodes("pool2", nil)).To(Equal([]string{"node5"}))
+		Expect(idx.getFreeNodes("pool3", nil)).To(BeEmpty())
+	})
+
+	It("applies the changes of a later configmap incrementally", func() {
+		// cloud1 releases node1 and claims node2, node3 moves to pool2, node4 is removed, and node5 is decommissioned
+		idx.sync("2",
+			newIndexResources(map[string]string{
+				"node1": "pool1", "node2": "pool1", "node3": "pool2", "node5": "pool2",
+			}),
+			newIndexAllocations(map[string][]string{"cloud1": {"node2"}}, "node5"))
+
+		Expect(idx.getPoolNodes("pool1")).To(Equal([]string{"node1", "node2"}))
+		Expect(idx.getPoolNodes("pool2")).To(Equal([]string{"node3", "node5"}))
+		Expect(idx.getFreeNodes("pool1", nil)).To(Equal([]string{"node1"}))
+		Expect(idx.getFreeNodes("pool2", nil)).To(Equal([]string{"node3"}))
+		Expect(idx.nodeOwners).To(Equal(map[string]string{"node2": "cloud1", "node5": ""}))
+		Expect(idx.cloudNodes).To(Equal(map[string]map[string]bool{"cloud1": {"node2": true}}))
+		Expect(idx.resourceVersion).To(Equal("2"))
+	})
+
+	It("takes the in-use nodes of the cloud being updated from the caller's copy", func() {
+		cloud := &cmAllocatedCloud{
+			CloudID:    "cloud1",
+			Nodegroups: map[string][]string{"master": {"node3"}},
+		}
+		Expect(idx.getFreeNodes("pool1", cloud)).To(Equal([]string{"node1", "node2", "node4"}))
+
+		// The index itself is not changed by the lookup
+		Expect(idx.getFreeNodes("pool1", nil)).To(Equal([]string{"node2", "node3", "node4"}))
+	})
+
+	It("skips a sync when the resourceVersion is unchanged", func() {
+		claimed := newIndexAllocations(map[string][]string{"cloud1": {"node1", "node2"}})
+		resources := newIndexResources(map[string]string{
+			"node1": "pool1", "node2": "pool1", "node3": "pool1", "node4": "pool1", "node5": "pool2",
+		})
+
+		idx.sync("1", resources, claimed)
+		Expect(idx.getFreeNodes("pool1", nil)).To(Equal([]string{"node2", "node3", "node4"}))
+
+		// A sync without a resourceVersion is always applied
+		idx.sync("", resources, claimed)
+		Expect(idx.getFreeNodes("pool1", nil)).To(Equal([]string{"node3", "node4"}))
+	})
+
+	It("applies a sync with an unchanged resourceVersion after being invalidated", func() {
+		idx.invalidate()
+		idx.sync("1",
+			newIndexResources(map[string]string{
+				"node1": "pool1", "node2": "pool1", "node3": "pool1", "node4": "pool1", "node5": "pool2",
+			}),
+			newIndexAllocations(map[string][]string{"cloud1": {"node1", "node2"}}))
+
+		Expect(idx.getFreeNodes("pool1", nil)).To(Equal([]string{"node3", "node4"}))
+		Expect(idx.resourceVersion).To(Equal("1"))
+	})
+
+	It("serves concurrent readers while syncing", func() {
+		const readers = 8
+		const syncs = 100
+
+		resources := newIndexResources(map[string]string{
+			"node1": "pool1", "node2": "pool1", "node3": "pool1", "node4": "pool1", "node5": "pool2",
+		})
+		states := [][]string{
+			{"node2", "node3", "node4"},
+			{"node1", "node3"},
+		}
+
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		results := make([][][]string, readers)
+		for i := range readers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					results[i] = append(results[i], idx.getFreeNodes("pool1", nil))
+					_ = idx.getPoolNodes("pool1")
+				}
+			}()
+		}
+
+		for i := range syncs {
+			allocations := newIndexAllocations(map[string][]string{"cloud1": {"node1"}})
+			if i%2 == 0 {
+				allocations = newIndexAllocations(map[string][]string{"cloud1": {"node2", "node4"}})
+			}
+			idx.sync(fmt.Sprintf("%d", i+2), resources, allocations)
+		}
+		close(done)
+		wg.Wait()
+
+		// Each lookup sees the index as of one sync or another, never partially applied
+		for i := range readers {
+			for _, free := range results[i] {
+				Expect(states).To(ContainElement(free))
+			}
+		}
+	})
+})
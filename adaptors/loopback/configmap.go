@@ -17,29 +17,157 @@ limitations under the License.
 package loopback
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"log/slog"
 	"slices"
+	"strings"
+	"time"
 
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
 )
 
 // Struct definitions for the nodelist configmap
 type cmBmcInfo struct {
 	Address        string `json:"address,omitempty"`
+	AddressIPv6    string `json:"address-ipv6,omitempty"`
 	UsernameBase64 string `json:"username-base64,omitempty"`
 	PasswordBase64 string `json:"password-base64,omitempty"`
 }
 
 type cmNodeInfo struct {
-	ResourcePoolID string                      `json:"poolID,omitempty"`
-	BMC            *cmBmcInfo                  `json:"bmc,omitempty"`
-	Interfaces     []*hwmgmtv1alpha1.Interface `json:"interfaces,omitempty"`
+	ResourcePoolID   string                      `json:"poolID,omitempty"`
+	BMC              *cmBmcInfo                  `json:"bmc,omitempty"`
+	Interfaces       []*hwmgmtv1alpha1.Interface `json:"interfaces,omitempty"`
+	InterfaceRoles   map[string]string           `json:"interfaceRoles,omitempty"`
+	SecretData       map[string]string           `json:"secretData,omitempty"`
+	PowerState       string                      `json:"powerState,omitempty"`
+	BootProgress     string                      `json:"bootProgress,omitempty"`
+	Failed           bool                        `json:"failed,omitempty"`
+	CPUs             int                         `json:"cpus,omitempty"`
+	MemoryGiB        int                         `json:"memoryGiB,omitempty"`
+	NICModels        []string                    `json:"nicModels,omitempty"`
+	Location         string                      `json:"location,omitempty"`
+	Site             string                      `json:"site,omitempty"`
+	PhysicalDisks    int                         `json:"physicalDisks,omitempty"`
+	SerialNumber     string                      `json:"serialNumber,omitempty"`
+	AssetTag         string                      `json:"assetTag,omitempty"`
+	Model            string                      `json:"model,omitempty"`
+	Vendor           string                      `json:"vendor,omitempty"`
+	DiskSerials      []string                    `json:"diskSerials,omitempty"`
+	Rack             string                      `json:"rack,omitempty"`
+	Chassis          string                      `json:"chassis,omitempty"`
+	PDU              string                      `json:"pdu,omitempty"`
+	MinPowerCap      int                         `json:"minPowerCapWatts,omitempty"`
+	MaxPowerCap      int                         `json:"maxPowerCapWatts,omitempty"`
+	BootDevices      []string                    `json:"bootDevices,omitempty"`
+	VirtualMediaURLs []string                    `json:"virtualMediaURLs,omitempty"`
+	SecureBoot       string                      `json:"secureBoot,omitempty"`
+	TPMVersion       string                      `json:"tpmVersion,omitempty"`
+	InstallHints     *utils.NodeInstallHints     `json:"installHints,omitempty"`
+}
+
+// attributes returns the simulated hardware attributes of the node, for matching against a node selector
+func (info cmNodeInfo) attributes() utils.NodeAttributes {
+	return utils.NodeAttributes{
+		CPUs:      info.CPUs,
+		MemoryGiB: info.MemoryGiB,
+		NICModels: info.NICModels,
+		Location:  info.Location,
+	}
+}
+
+// bootCapabilities returns the simulated boot capabilities of the node
+func (info cmNodeInfo) bootCapabilities() utils.NodeBootCapabilities {
+	return utils.NewNodeBootCapabilities(info.BootDevices, info.VirtualMediaURLs)
+}
+
+// assetInfo returns the simulated asset details of the node
+func (info cmNodeInfo) assetInfo() utils.NodeAssetInfo {
+	return utils.NodeAssetInfo{
+		SerialNumber: info.SerialNumber,
+		AssetTag:     info.AssetTag,
+		Model:        info.Model,
+		Vendor:       info.Vendor,
+	}
+}
+
+// installHints returns the simulated hostname and provisioning network details intended for the node
+func (info cmNodeInfo) installHints() utils.NodeInstallHints {
+	if info.InstallHints == nil {
+		return utils.NodeInstallHints{}
+	}
+	return *info.InstallHints
+}
+
+// failureDomain returns the simulated failure domain metadata of the node, for spreading the nodes of a nodegroup
+func (info cmNodeInfo) failureDomain() utils.FailureDomain {
+	return utils.FailureDomain{
+		Rack:    info.Rack,
+		Chassis: info.Chassis,
+		PDU:     info.PDU,
+	}
+}
+
+// locality returns the simulated location metadata of the node, for matching against the locality preference of a
+// NodePool
+func (info cmNodeInfo) locality() utils.NodeLocality {
+	return utils.NodeLocality{
+		Site:     info.Site,
+		Location: info.Location,
+	}
+}
+
+// capabilities returns the simulated hardware capabilities of the node, for checking against a hardware profile
+func (info cmNodeInfo) capabilities() utils.NodeCapabilities {
+	return utils.NodeCapabilities{
+		PhysicalDisks: info.PhysicalDisks,
+		Interfaces:    len(info.Interfaces),
+		Security:      info.securityState(),
+	}
+}
+
+// securityState returns the simulated platform security state of the node
+func (info cmNodeInfo) securityState() utils.NodeSecurityState {
+	return utils.NodeSecurityState{
+		SecureBoot: utils.SecureBootState(info.SecureBoot),
+		TPMVersion: info.TPMVersion,
+	}
+}
+
+// applySecurityRequirements simulates the configuration of the security requirements, enabling secure boot if
+// required and supported by the node, and returns the resulting security state. The configmap is not updated.
+func (info cmNodeInfo) applySecurityRequirements(requirements *pluginv1alpha1.SecurityRequirements) utils.NodeSecurityState {
+	state := info.securityState()
+	if utils.NeedsSecureBootEnabled(requirements, state) {
+		state.SecureBoot = utils.SecureBootEnabled
+	}
+	return state
+}
+
+// applyStorageLayout simulates the configuration of the storage layout, which fails if the node does not have enough
+// physical disks. The number of physical disks is not checked if not specified for the node.
+func (info cmNodeInfo) applyStorageLayout(layout *pluginv1alpha1.StorageLayout) error {
+	required := utils.GetStorageLayoutPhysicalDisks(layout)
+	if info.PhysicalDisks != 0 && info.PhysicalDisks < required {
+		return fmt.Errorf("storage layout requires %d physical disks, but node has %d", required, info.PhysicalDisks)
+	}
+	return nil
 }
 
 type cmResources struct {
+	// SchemaVersion is the version of the resourcesSchema the data was written with
+	SchemaVersion int                   `json:"schemaVersion" yaml:"schemaVersion"`
 	ResourcePools []string              `json:"resourcepools" yaml:"resourcepools"`
 	Nodes         map[string]cmNodeInfo `json:"nodes" yaml:"nodes"`
 }
@@ -47,10 +175,88 @@ type cmResources struct {
 type cmAllocatedCloud struct {
 	CloudID    string              `json:"cloudID" yaml:"cloudID"`
 	Nodegroups map[string][]string `json:"nodegroups" yaml:"nodegroups"`
+	// Spares holds the node IDs reserved as spares, keyed by nodegroup name
+	Spares map[string][]string `json:"spares,omitempty" yaml:"spares,omitempty"`
+	// Replaced maps the names of healed nodes to the spare node ID swapped in
+	Replaced map[string]string `json:"replaced,omitempty" yaml:"replaced,omitempty"`
+	// Retired holds the IDs of failed nodes that have been replaced, which are not reallocated
+	Retired []string `json:"retired,omitempty" yaml:"retired,omitempty"`
+	// Adopted maps the names of nodes imported from an existing backend allocation to their node ID
+	Adopted map[string]string `json:"adopted,omitempty" yaml:"adopted,omitempty"`
+	// Migrated maps the names of nodes migrated by a consolidation to the node ID they were moved to
+	Migrated map[string]string `json:"migrated,omitempty" yaml:"migrated,omitempty"`
+	// Pending maps the names of claimed nodes to their node ID until the Node CR is confirmed to exist, so that an
+	// interrupted allocation is resumed
+	Pending map[string]string `json:"pending,omitempty" yaml:"pending,omitempty"`
+	// OrphanedSince is the time the cloud was first found to have no NodePool, with its allocations released once the
+	// cleanup grace period has elapsed
+	OrphanedSince *metav1.Time `json:"orphanedSince,omitempty" yaml:"orphanedSince,omitempty"`
+	// Retained is set for the cloud of a NodePool deleted under the Retain deletion policy, whose allocations are kept
+	// until the cloud is adopted by a new NodePool
+	Retained bool `json:"retained,omitempty" yaml:"retained,omitempty"`
+}
+
+// cmNodeHistory records the allocations of a node, retained across releases for wear leveling
+type cmNodeHistory struct {
+	AllocationCount int          `json:"allocationCount" yaml:"allocationCount"`
+	LastAllocated   *metav1.Time `json:"lastAllocated,omitempty" yaml:"lastAllocated,omitempty"`
 }
 
 type cmAllocations struct {
-	Clouds []cmAllocatedCloud `json:"clouds" yaml:"clouds"`
+	// SchemaVersion is the version of the allocationsSchema the data was written with
+	SchemaVersion int                `json:"schemaVersion" yaml:"schemaVersion"`
+	Clouds        []cmAllocatedCloud `json:"clouds" yaml:"clouds"`
+	// History holds the allocation history of each node, keyed by node ID
+	History map[string]cmNodeHistory `json:"history,omitempty" yaml:"history,omitempty"`
+	// Decommissioned holds the IDs of decommissioned nodes, which are not reallocated
+	Decommissioned []string `json:"decommissioned,omitempty" yaml:"decommissioned,omitempty"`
+}
+
+// getCloud returns the allocation of the cloud, or nil if the cloud has no allocation
+func (allocations *cmAllocations) getCloud(cloudID string) *cmAllocatedCloud {
+	for i := range allocations.Clouds {
+		if allocations.Clouds[i].CloudID == cloudID {
+			return &allocations.Clouds[i]
+		}
+	}
+	return nil
+}
+
+// recordAllocation updates the allocation history of a node
+func (allocations *cmAllocations) recordAllocation(nodeId string) {
+	if allocations.History == nil {
+		allocations.History = make(map[string]cmNodeHistory)
+	}
+
+	now := metav1.Now()
+	history := allocations.History[nodeId]
+	history.AllocationCount++
+	history.LastAllocated = &now
+	allocations.History[nodeId] = history
+}
+
+// compareNodeWear orders nodes by least recent allocation, with nodes that have never been allocated first, then by
+// fewest allocations and finally by node ID
+func (allocations *cmAllocations) compareNodeWear(a, b string) int {
+	historyA, historyB := allocations.History[a], allocations.History[b]
+
+	switch {
+	case historyA.LastAllocated == nil && historyB.LastAllocated != nil:
+		return -1
+	case historyA.LastAllocated != nil && historyB.LastAllocated == nil:
+		return 1
+	case historyA.LastAllocated != nil && !historyA.LastAllocated.Equal(historyB.LastAllocated):
+		if historyA.LastAllocated.Before(historyB.LastAllocated) {
+			return -1
+		}
+		return 1
+	}
+
+	if historyA.AllocationCount != historyB.AllocationCount {
+		return cmp.Compare(historyA.AllocationCount, historyB.AllocationCount)
+	}
+
+	return strings.Compare(a, b)
 }
 
 const (
@@ -59,30 +265,167 @@ const (
 	cmName         = "loopback-adaptor-nodelist"
 )
 
-// getFreeNodesInPool compares the parsed configmap data to get the list of free nodes for a given resource pool
-func getFreeNodesInPool(resources cmResources, allocations cmAllocations, poolID string) (freenodes []string) {
+// Schemas of the data in the nodelist configmap. Any change to the format of the data that is not backward compatible
+// must append a converter to the schema, so that the configmap is upgraded in place on an upgrade of the plugin.
+var (
+	resourcesSchema = sdk.Schema{
+		Name:       resourcesKey,
+		Converters: []sdk.SchemaConverter{versionSchema},
+	}
+	allocationsSchema = sdk.Schema{
+		Name:       allocationsKey,
+		Converters: []sdk.SchemaConverter{versionSchema},
+	}
+)
+
+// versionSchema converts unversioned data to version 1, which only introduced the schemaVersion field
+func versionSchema(data map[string]any) error {
+	return nil
+}
+
+// decodeConfigMapData parses the data of a key of the nodelist configmap, converting it to the current version of its
+// schema
+func (a *Adaptor) decodeConfigMapData(ctx context.Context, cm *corev1.ConfigMap, key string, schema sdk.Schema, out any) error {
+	data, err := utils.GetConfigMapField(cm, key)
+	if err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	version, err := schema.Decode([]byte(data), out)
+	if err != nil {
+		return fmt.Errorf("failed to decode data from ConfigMap %s: %w", cm.Name, err)
+	}
+	if version != schema.Version() {
+		// The converted data is written back on the next update of the key
+		a.Logger.DebugContext(ctx, "Converted nodelist configmap data to current schema version",
+			slog.String("key", key),
+			slog.Int("fromVersion", version),
+			slog.Int("toVersion", schema.Version()))
+	}
+
+	return nil
+}
+
+// nodesInUse returns the nodes that are allocated to the cloud, held as its spares, retired, or migrated to
+func (cloud *cmAllocatedCloud) nodesInUse() (nodes []string) {
+	for groupname := range cloud.Nodegroups {
+		nodes = append(nodes, cloud.Nodegroups[groupname]...)
+	}
+	for groupname := range cloud.Spares {
+		nodes = append(nodes, cloud.Spares[groupname]...)
+	}
+	for _, nodeId := range cloud.Replaced {
+		nodes = append(nodes, nodeId)
+	}
+	nodes = append(nodes, cloud.Retired...)
+	for _, nodeId := range cloud.Adopted {
+		nodes = append(nodes, nodeId)
+	}
+	for _, nodeId := range cloud.Migrated {
+		nodes = append(nodes, nodeId)
+	}
+	for _, nodeId := range cloud.Pending {
+		nodes = append(nodes, nodeId)
+	}
+	return
+}
+
+// getNodesInUse returns the set of nodes that are allocated, held as spares, retired, or migrated to
+func getNodesInUse(allocations cmAllocations) map[string]bool {
 	inuse := make(map[string]bool)
-	for _, cloud := range allocations.Clouds {
-		for groupname := range cloud.Nodegroups {
-			for _, nodename := range cloud.Nodegroups[groupname] {
-				inuse[nodename] = true
+	for nodeId := range getNodeOwners(allocations) {
+		inuse[nodeId] = true
+	}
+	return inuse
+}
+
+// getDoublyAllocatedNodes returns the nodes that are held by more than one cloud, or by a cloud after being
+// decommissioned
+func (allocations *cmAllocations) getDoublyAllocatedNodes() map[string]bool {
+	doubly := make(map[string]bool)
+	owners := make(map[string]string)
+	for _, nodeId := range allocations.Decommissioned {
+		owners[nodeId] = ""
+	}
+	for i := range allocations.Clouds {
+		cloud := &allocations.Clouds[i]
+		for _, nodeId := range cloud.nodesInUse() {
+			if owner, inuse := owners[nodeId]; inuse && owner != cloud.CloudID {
+				doubly[nodeId] = true
 			}
+			owners[nodeId] = cloud.CloudID
 		}
 	}
+	return doubly
+}
 
-	for nodename, node := range resources.Nodes {
-		// Check if the node belongs to the specified resource pool
-		if node.ResourcePoolID == poolID {
-			// Only add to the freenodes if not in use
-			if _, used := inuse[nodename]; !used {
-				freenodes = append(freenodes, nodename)
-			}
+// getFreeNodesInPool looks up the free nodes for a given resource pool in the allocation index, returning those that
+// match the node selector, if any, with the least recently allocated nodes first to distribute wear across the nodes.
+// The cloud, if any, is the allocation being updated by the caller, whose unsaved changes are taken into account. A
+// resource pool under maintenance has no free nodes, and blocked nodes are never free.
+func (a *Adaptor) getFreeNodesInPool(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	resources cmResources,
+	allocations cmAllocations,
+	cloud *cmAllocatedCloud,
+	poolID string,
+	selector *utils.NodeSelector) (freenodes []string) {
+
+	if utils.IsResourcePoolInMaintenance(hwmgr, poolID) {
+		return nil
+	}
+
+	for _, nodeId := range a.index.getFreeNodes(poolID, cloud) {
+		// The index may have been synced with a newer configmap than the caller's copy
+		node, exists := resources.Nodes[nodeId]
+		if exists && node.ResourcePoolID == poolID && selector.Matches(node.attributes()) && !utils.IsNodeBlocked(hwmgr, poolID, nodeId) {
+			freenodes = append(freenodes, nodeId)
 		}
 	}
 
+	slices.SortFunc(freenodes, allocations.compareNodeWear)
 	return
 }
 
+// getPendingAdoptedNodes returns the nodes of the nodegroup that are yet to be adopted, verifying that each is a free
+// node in the resource pool of the nodegroup
+func (a *Adaptor) getPendingAdoptedNodes(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodegroup hwmgmtv1alpha1.NodeGroup,
+	resources cmResources,
+	allocations cmAllocations,
+	cloud *cmAllocatedCloud) ([]string, error) {
+
+	nodeIds, err := utils.GetNodeGroupAdoptedNodes(nodepool, nodegroup.NodePoolData.Name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adopted nodes: %w", err)
+	}
+
+	adopted := make(map[string]bool)
+	if cloud != nil {
+		for _, nodeId := range cloud.Adopted {
+			adopted[nodeId] = true
+		}
+	}
+
+	freenodes := a.getFreeNodesInPool(hwmgr, resources, allocations, cloud, nodegroup.NodePoolData.ResourcePoolId, nil)
+
+	var pending []string
+	for _, nodeId := range nodeIds {
+		if adopted[nodeId] {
+			continue
+		}
+		if !slices.Contains(freenodes, nodeId) {
+			return nil, fmt.Errorf("adopted node %s is not available in resource pool %s",
+				nodeId, nodegroup.NodePoolData.ResourcePoolId)
+		}
+		pending = append(pending, nodeId)
+	}
+
+	return pending, nil
+}
+
 // GetCurrentResources parses the nodelist configmap to get the current available and allocated resource lists
 func (a *Adaptor) GetCurrentResources(ctx context.Context) (
 	cm *corev1.ConfigMap, resources cmResources, allocations cmAllocations, err error) {
@@ -92,22 +435,89 @@ func (a *Adaptor) GetCurrentResources(ctx context.Context) (
 		return
 	}
 
-	resources, err = utils.ExtractDataFromConfigMap[cmResources](cm, resourcesKey)
-	if err != nil {
+	if err = a.decodeConfigMapData(ctx, cm, resourcesKey, resourcesSchema, &resources); err != nil {
 		err = fmt.Errorf("unable to parse resources from configmap: %w", err)
 		return
 	}
 
-	allocations, err = utils.ExtractDataFromConfigMap[cmAllocations](cm, allocationsKey)
-	if err != nil {
+	if _, exists := cm.Data[allocationsKey]; !exists {
 		// Allocated node field may not be present
-		a.Logger.InfoContext(ctx, "unable to parse allocations from configmap")
-		err = nil
+		a.Logger.InfoContext(ctx, "no allocations in configmap")
+		allocations.SchemaVersion = allocationsSchema.Version()
+	} else if err = a.decodeConfigMapData(ctx, cm, allocationsKey, allocationsSchema, &allocations); err != nil {
+		// Allocations written by a newer plugin must not be overwritten
+		err = fmt.Errorf("unable to parse allocations from configmap: %w", err)
+		return
 	}
 
+	a.index.sync(cm.ResourceVersion, resources, allocations)
+
 	return
 }
 
+// configMapUpdateBackoff spaces out the attempts to apply a change to the nodelist configmap on conflicting updates,
+// giving the cached configmap time to catch up with the concurrent update before it is re-read
+var configMapUpdateBackoff = wait.Backoff{
+	Steps:    8,
+	Duration: 10 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+}
+
+// allocationChange applies a change to the allocations read from the nodelist configmap, returning whether there is
+// anything to save. A change may be applied more than once, to the allocations re-read after a conflicting update, so
+// it must be derived from the data it is given rather than from an earlier read. Changes to the resources are saved by
+// setting them in the configmap data.
+type allocationChange func(cm *corev1.ConfigMap, resources cmResources, allocations *cmAllocations) (bool, error)
+
+// updateAllocations applies the change to the allocations of the nodelist configmap and saves them. The update is
+// guarded by the resourceVersion of the configmap it was applied to, so that a concurrent update is detected as a
+// conflict rather than overwritten, in which case the configmap is re-read and the change re-applied.
+func (a *Adaptor) updateAllocations(ctx context.Context, change allocationChange) error {
+	attempt := 0
+	return retry.RetryOnConflict(configMapUpdateBackoff, func() error { // nolint: wrapcheck
+		attempt++
+		cm, resources, allocations, err := a.GetCurrentResources(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get current resources: %w", err)
+		}
+
+		doubly := allocations.getDoublyAllocatedNodes()
+		changed, err := change(cm, resources, &allocations)
+		if err != nil || !changed {
+			return err
+		}
+
+		// A free node lookup may have been served by an index synced with an older configmap by a concurrent reader,
+		// in which case the change is re-applied to a fresh read as if the update had conflicted
+		for nodeId := range allocations.getDoublyAllocatedNodes() {
+			if !doubly[nodeId] {
+				a.index.invalidate()
+				a.Logger.InfoContext(ctx, "Node allocated concurrently, re-applying allocation change",
+					slog.String("nodeId", nodeId),
+					slog.Int("attempt", attempt))
+				return errors.NewConflict(corev1.Resource("configmaps"), cm.Name,
+					fmt.Errorf("node %s is already allocated", nodeId))
+			}
+		}
+
+		yamlString, err := yaml.Marshal(&allocations)
+		if err != nil {
+			return fmt.Errorf("unable to marshal allocated data: %w", err)
+		}
+		cm.Data[allocationsKey] = string(yamlString)
+		if err := a.Client.Update(ctx, cm); err != nil {
+			if errors.IsConflict(err) {
+				a.Logger.InfoContext(ctx, "Configmap updated concurrently, re-applying allocation change",
+					slog.Int("attempt", attempt))
+				return err // nolint: wrapcheck
+			}
+			return fmt.Errorf("failed to update configmap: %w", err)
+		}
+		return nil
+	})
+}
+
 // GetAllocatedNodes gets a list of nodes allocated for the specified NodePool CR
 func (a *Adaptor) GetAllocatedNodes(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (allocatedNodes []string, err error) {
 	cloudID := nodepool.Spec.CloudID
@@ -0,0 +1,444 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// configMapClient stores a single configmap, rejecting updates made against a stale resourceVersion as the API server
+// does. The beforeUpdate hook, if set, is called on each update before the resourceVersion is checked.
+type configMapClient struct {
+	client.Client
+	mu           sync.Mutex
+	cm           *corev1.ConfigMap
+	updates      int
+	beforeUpdate func(c *configMapClient)
+}
+
+func (c *configMapClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key.Name != c.cm.Name || key.Namespace != c.cm.Namespace {
+		return k8serrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+	}
+	c.cm.DeepCopyInto(obj.(*corev1.ConfigMap))
+	return nil
+}
+
+func (c *configMapClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	if c.beforeUpdate != nil {
+		hook := c.beforeUpdate
+		c.beforeUpdate = nil
+		hook(c)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cm := obj.(*corev1.ConfigMap)
+	if cm.ResourceVersion != c.cm.ResourceVersion {
+		return k8serrors.NewConflict(corev1.Resource("configmaps"), cm.Name,
+			fmt.Errorf("the object has been modified"))
+	}
+	c.store(cm)
+	return nil
+}
+
+// store must be called with the lock held
+func (c *configMapClient) store(cm *corev1.ConfigMap) {
+	version, _ := strconv.Atoi(c.cm.ResourceVersion)
+	c.cm = cm.DeepCopy()
+	c.cm.ResourceVersion = strconv.Itoa(version + 1)
+	c.updates++
+}
+
+// setAllocations replaces the allocations in the stored configmap, as a concurrent writer would
+func (c *configMapClient) setAllocations(allocations cmAllocations) {
+	data, err := yaml.Marshal(&allocations)
+	Expect(err).ToNot(HaveOccurred())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cm := c.cm.DeepCopy()
+	cm.Data[allocationsKey] = string(data)
+	c.store(cm)
+}
+
+// setResources replaces the resources in the stored configmap, as an edit of the nodelist would
+func (c *configMapClient) setResources(resources cmResources) {
+	data, err := yaml.Marshal(&resources)
+	Expect(err).ToNot(HaveOccurred())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cm := c.cm.DeepCopy()
+	cm.Data[resourcesKey] = string(data)
+	c.store(cm)
+}
+
+func (c *configMapClient) getAllocations() cmAllocations {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var allocations cmAllocations
+	Expect(yaml.Unmarshal([]byte(c.cm.Data[allocationsKey]), &allocations)).To(Succeed())
+	return allocations
+}
+
+func newConfigMapClient(nodes int) *configMapClient {
+	resources := cmResources{
+		SchemaVersion: resourcesSchema.Version(),
+		ResourcePools: []string{"pool1"},
+		Nodes:         make(map[string]cmNodeInfo),
+	}
+	for i := 1; i <= nodes; i++ {
+		resources.Nodes[fmt.Sprintf("node%d", i)] = cmNodeInfo{ResourcePoolID: "pool1"}
+	}
+	data, err := yaml.Marshal(&resources)
+	Expect(err).ToNot(HaveOccurred())
+
+	return &configMapClient{
+		cm: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            cmName,
+				Namespace:       "test",
+				ResourceVersion: "1",
+			},
+			Data: map[string]string{
+				resourcesKey: string(data),
+			},
+		},
+	}
+}
+
+// claimClient serves the nodelist configmap and reports the named Node CRs as existing
+type claimClient struct {
+	*configMapClient
+	nodes []string
+}
+
+func (c *claimClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*hwmgmtv1alpha1.Node); !ok {
+		return c.configMapClient.Get(ctx, key, obj, opts...)
+	}
+	if !slices.Contains(c.nodes, key.Name) {
+		return k8serrors.NewNotFound(hwmgmtv1alpha1.GroupVersion.WithResource("nodes").GroupResource(), key.Name)
+	}
+	obj.SetName(key.Name)
+	obj.SetNamespace(key.Namespace)
+	return nil
+}
+
+// claimFreeNode returns a change that allocates the first free node in the pool to a nodegroup of the cloud
+func claimFreeNode(a *Adaptor, cloudID string, claimed *string) allocationChange {
+	return func(_ *corev1.ConfigMap, resources cmResources, allocations *cmAllocations) (bool, error) {
+		cloud := allocations.getCloud(cloudID)
+		if cloud == nil {
+			allocations.Clouds = append(allocations.Clouds, cmAllocatedCloud{
+				CloudID:    cloudID,
+				Nodegroups: make(map[string][]string),
+			})
+			cloud = &allocations.Clouds[len(allocations.Clouds)-1]
+		}
+
+		freenodes := a.getFreeNodesInPool(&pluginv1alpha1.HardwareManager{}, resources, *allocations, cloud, "pool1", nil)
+		if len(freenodes) == 0 {
+			return false, fmt.Errorf("no free nodes for cloud %s", cloudID)
+		}
+		*claimed = freenodes[0]
+		cloud.Nodegroups["master"] = append(cloud.Nodegroups["master"], freenodes[0])
+		allocations.recordAllocation(freenodes[0])
+		return true, nil
+	}
+}
+
+var _ = Describe("Allocation updates", func() {
+	var (
+		ctx context.Context
+		c   *configMapClient
+		a   *Adaptor
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = newConfigMapClient(8)
+		a = NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test")
+	})
+
+	It("re-applies the change after a conflicting update", func() {
+		c.beforeUpdate = func(c *configMapClient) {
+			c.setAllocations(cmAllocations{
+				SchemaVersion: allocationsSchema.Version(),
+				Clouds: []cmAllocatedCloud{{
+					CloudID:    "cloud-other",
+					Nodegroups: map[string][]string{"master": {"node1"}},
+				}},
+			})
+		}
+
+		var claimed string
+		Expect(a.updateAllocations(ctx, claimFreeNode(a, "cloud1", &claimed))).To(Succeed())
+		Expect(claimed).To(Equal("node2"))
+
+		allocations := c.getAllocations()
+		Expect(allocations.getCloud("cloud-other").Nodegroups["master"]).To(Equal([]string{"node1"}))
+		Expect(allocations.getCloud("cloud1").Nodegroups["master"]).To(Equal([]string{"node2"}))
+	})
+
+	It("does not save a change that has nothing to update", func() {
+		Expect(a.updateAllocations(ctx, func(*corev1.ConfigMap, cmResources, *cmAllocations) (bool, error) {
+			return false, nil
+		})).To(Succeed())
+		Expect(c.updates).To(BeZero())
+	})
+
+	It("allocates distinct nodes to concurrent allocations from the same pool", func() {
+		const clouds = 6
+
+		var wg sync.WaitGroup
+		claimed := make([]string, clouds)
+		errs := make([]error, clouds)
+		for i := range clouds {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = a.updateAllocations(ctx, claimFreeNode(a, fmt.Sprintf("cloud%d", i), &claimed[i]))
+			}()
+		}
+		wg.Wait()
+
+		for i := range clouds {
+			Expect(errs[i]).ToNot(HaveOccurred())
+		}
+		Expect(claimed).To(HaveLen(clouds))
+
+		allocations := c.getAllocations()
+		Expect(allocations.Clouds).To(HaveLen(clouds))
+		Expect(allocations.getDoublyAllocatedNodes()).To(BeEmpty())
+		for i := range clouds {
+			Expect(allocations.getCloud(fmt.Sprintf("cloud%d", i)).Nodegroups["master"]).To(Equal([]string{claimed[i]}))
+		}
+	})
+
+	It("detects a node allocated from a stale index", func() {
+		var ignored string
+		Expect(a.updateAllocations(ctx, claimFreeNode(a, "cloud1", &ignored))).To(Succeed())
+
+		// Sync the index with the configmap as it was before the claim, as a concurrent reader may
+		a.index.sync("stale", cmResources{
+			Nodes: map[string]cmNodeInfo{"node1": {ResourcePoolID: "pool1"}},
+		}, cmAllocations{})
+
+		var claimed string
+		Expect(a.updateAllocations(ctx, claimFreeNode(a, "cloud2", &claimed))).To(Succeed())
+		Expect(claimed).ToNot(Equal("node1"))
+
+		allocations := c.getAllocations()
+		Expect(allocations.getDoublyAllocatedNodes()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Node claims", func() {
+	var (
+		ctx      context.Context
+		c        *claimClient
+		a        *Adaptor
+		hwmgr    *pluginv1alpha1.HardwareManager
+		nodepool *hwmgmtv1alpha1.NodePool
+	)
+
+	// claimNodes makes the claims for the nodepool in a single update of the allocations, as AllocateNode does
+	claimNodes := func() []nodeClaim {
+		var claims []nodeClaim
+		sim := a.getSimulator(ctx, hwmgr)
+		Expect(a.updateAllocations(ctx, func(_ *corev1.ConfigMap, resources cmResources, allocations *cmAllocations) (bool, error) {
+			var changed bool
+			var allocErr, err error
+			claims, changed, allocErr, err = a.claimNodes(ctx, hwmgr, nodepool, sim, resources, allocations)
+			Expect(allocErr).ToNot(HaveOccurred())
+			return changed, err
+		})).To(Succeed())
+		return claims
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = &claimClient{configMapClient: newConfigMapClient(4)}
+		a = NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test")
+		hwmgr = &pluginv1alpha1.HardwareManager{ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "test"}}
+		nodepool = &hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "test"},
+			Spec: hwmgmtv1alpha1.NodePoolSpec{
+				CloudID: "cloud1",
+				HwMgrId: "hwmgr",
+				NodeGroup: []hwmgmtv1alpha1.NodeGroup{{
+					NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "master", ResourcePoolId: "pool1"},
+					Size:         1,
+				}},
+			},
+		}
+	})
+
+	It("resumes a pending claim that has no Node CR", func() {
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{{
+				CloudID:    "cloud1",
+				Nodegroups: map[string][]string{"master": {"master-0"}},
+				Pending:    map[string]string{"master-0": "node3"},
+			}},
+		})
+		updates := c.updates
+
+		claims := claimNodes()
+		Expect(claims).To(HaveLen(1))
+		Expect(claims[0].nodename).To(Equal("master-0"))
+		Expect(claims[0].nodeId).To(Equal("node3"))
+		Expect(claims[0].nodegroup.NodePoolData.Name).To(Equal("master"))
+
+		// Nothing is claimed again, so the allocations are left as they are
+		Expect(c.updates).To(Equal(updates))
+		allocations := c.getAllocations()
+		Expect(allocations.getCloud("cloud1").Pending).To(Equal(map[string]string{"master-0": "node3"}))
+	})
+
+	It("drops a pending claim once its Node CR exists", func() {
+		c.nodes = []string{"master-0"}
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{{
+				CloudID:    "cloud1",
+				Nodegroups: map[string][]string{"master": {"master-0"}},
+				Pending:    map[string]string{"master-0": "node3"},
+			}},
+		})
+
+		Expect(claimNodes()).To(BeEmpty())
+
+		allocations := c.getAllocations()
+		cloud := allocations.getCloud("cloud1")
+		Expect(cloud.Pending).To(BeEmpty())
+		Expect(cloud.Nodegroups["master"]).To(Equal([]string{"master-0"}))
+	})
+
+	It("drops a pending claim that is no longer part of the allocation", func() {
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{{
+				CloudID:    "cloud1",
+				Nodegroups: map[string][]string{"master": {"master-0"}},
+				Pending:    map[string]string{"master-0": "node3", "worker-0": "node4"},
+			}},
+		})
+
+		claims := claimNodes()
+		Expect(claims).To(HaveLen(1))
+		Expect(claims[0].nodename).To(Equal("master-0"))
+		allocations := c.getAllocations()
+		Expect(allocations.getCloud("cloud1").Pending).To(Equal(map[string]string{"master-0": "node3"}))
+	})
+
+	It("claims the nodes again after a conflicting update", func() {
+		c.beforeUpdate = func(c *configMapClient) {
+			c.setAllocations(cmAllocations{
+				SchemaVersion: allocationsSchema.Version(),
+				Clouds: []cmAllocatedCloud{{
+					CloudID:    "cloud-other",
+					Nodegroups: map[string][]string{"master": {"node1"}},
+				}},
+			})
+		}
+
+		claims := claimNodes()
+		Expect(claims).To(HaveLen(1))
+		Expect(claims[0].nodeId).To(Equal("node2"))
+
+		allocations := c.getAllocations()
+		Expect(allocations.getDoublyAllocatedNodes()).To(BeEmpty())
+		cloud := allocations.getCloud("cloud1")
+		Expect(cloud.Nodegroups["master"]).To(Equal([]string{claims[0].nodename}))
+		Expect(cloud.Pending).To(Equal(map[string]string{claims[0].nodename: "node2"}))
+	})
+})
+
+var _ = Describe("Node wear", func() {
+	older := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	DescribeTable("orders the nodes by wear",
+		func(history map[string]cmNodeHistory, a, b string, expected int) {
+			allocations := cmAllocations{History: history}
+			Expect(allocations.compareNodeWear(a, b)).To(Equal(expected))
+			Expect(allocations.compareNodeWear(b, a)).To(Equal(-expected))
+		},
+		Entry("a never-allocated node before an allocated node",
+			map[string]cmNodeHistory{"node1": {LastAllocated: &older, AllocationCount: 1}},
+			"node2", "node1", -1),
+		Entry("the least recently allocated node first",
+			map[string]cmNodeHistory{
+				"node1": {LastAllocated: &newer, AllocationCount: 1},
+				"node2": {LastAllocated: &older, AllocationCount: 3},
+			},
+			"node2", "node1", -1),
+		Entry("the node allocated fewer times first, for the same allocation time",
+			map[string]cmNodeHistory{
+				"node1": {LastAllocated: &older, AllocationCount: 2},
+				"node2": {LastAllocated: &older, AllocationCount: 1},
+			},
+			"node2", "node1", -1),
+		Entry("by node ID for never-allocated nodes",
+			nil,
+			"node1", "node2", -1),
+		Entry("by node ID for the same history",
+			map[string]cmNodeHistory{
+				"node1": {LastAllocated: &older, AllocationCount: 1},
+				"node2": {LastAllocated: &older, AllocationCount: 1},
+			},
+			"node1", "node2", -1),
+		Entry("a node as equal to itself",
+			map[string]cmNodeHistory{"node1": {LastAllocated: &older, AllocationCount: 1}},
+			"node1", "node1", 0),
+	)
+
+	It("sorts the free nodes with the least worn first", func() {
+		allocations := cmAllocations{History: map[string]cmNodeHistory{
+			"node1": {LastAllocated: &newer, AllocationCount: 1},
+			"node2": {LastAllocated: &older, AllocationCount: 2},
+			"node4": {LastAllocated: &older, AllocationCount: 1},
+		}}
+		nodes := []string{"node1", "node2", "node3", "node4", "node5"}
+		slices.SortFunc(nodes, allocations.compareNodeWear)
+		Expect(nodes).To(Equal([]string{"node3", "node5", "node4", "node2", "node1"}))
+	})
+})
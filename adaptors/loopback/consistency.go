@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// CheckAllocations cross-checks the allocation of the NodePool in the nodelist configmap against its Nodes. Allocated
+// node names with no Node CR, which are left behind if the plugin restarts part way through an allocation, are
+// released. Only the nodegroups of the NodePool are checked, as the cloud may be shared with other NodePools. Nodes
+// missing from the allocation are reported, but not repaired, as the allocation cannot be safely reconstructed.
+func (a *Adaptor) CheckAllocations(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodes []hwmgmtv1alpha1.Node) ([]pluginv1alpha1.ConsistencyIssue, error) {
+
+	var issues []pluginv1alpha1.ConsistencyIssue
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, _ cmResources, allocations *cmAllocations) (bool, error) {
+		issues = nil
+		index := slices.IndexFunc(allocations.Clouds, func(cloud cmAllocatedCloud) bool {
+			return cloud.CloudID == nodepool.Spec.CloudID
+		})
+		if index < 0 {
+			for _, node := range nodes {
+				issues = append(issues, pluginv1alpha1.ConsistencyIssue{
+					Kind:     utils.ConsistencyUnallocatedNode,
+					NodePool: nodepool.Name,
+					Name:     node.Name,
+					Message:  fmt.Sprintf("cloud %s has no allocation", nodepool.Spec.CloudID),
+				})
+			}
+			return false, nil
+		}
+		cloud := &allocations.Clouds[index]
+
+		exists := make(map[string]bool)
+		for _, node := range nodes {
+			exists[node.Name] = true
+			if !slices.Contains(cloud.Nodegroups[node.Spec.GroupName], node.Name) {
+				issues = append(issues, pluginv1alpha1.ConsistencyIssue{
+					Kind:     utils.ConsistencyUnallocatedNode,
+					NodePool: nodepool.Name,
+					Name:     node.Name,
+					Message:  fmt.Sprintf("node is not allocated to nodegroup %s", node.Spec.GroupName),
+				})
+			}
+		}
+
+		changed := false
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			groupname := nodegroup.NodePoolData.Name
+			allocated, ok := cloud.Nodegroups[groupname]
+			if !ok {
+				continue
+			}
+			cloud.Nodegroups[groupname] = slices.DeleteFunc(allocated, func(nodename string) bool {
+				if _, pending := cloud.Pending[nodename]; pending || exists[nodename] {
+					// Pending claims are resumed by the allocation
+					return false
+				}
+
+				a.Logger.InfoContext(ctx, "Releasing allocation with no Node",
+					slog.String("nodepool", nodepool.Name),
+					slog.String("nodegroup", groupname),
+					slog.String("nodename", nodename))
+				delete(cloud.Replaced, nodename)
+				delete(cloud.Adopted, nodename)
+				delete(cloud.Migrated, nodename)
+				issues = append(issues, pluginv1alpha1.ConsistencyIssue{
+					Kind:     utils.ConsistencyOrphanedAllocation,
+					NodePool: nodepool.Name,
+					Name:     nodename,
+					Repaired: true,
+					Message:  fmt.Sprintf("released from nodegroup %s", groupname),
+				})
+				changed = true
+				return true
+			})
+		}
+
+		return changed, nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to check allocations of NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return issues, nil
+}
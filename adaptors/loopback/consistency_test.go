@@ -0,0 +1,134 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Allocation consistency", func() {
+	var (
+		ctx   context.Context
+		c     *configMapClient
+		a     *Adaptor
+		hwmgr *pluginv1alpha1.HardwareManager
+	)
+
+	newNodePool := func(name string, groupnames ...string) *hwmgmtv1alpha1.NodePool {
+		nodepool := &hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec:       hwmgmtv1alpha1.NodePoolSpec{CloudID: "cloud1"},
+		}
+		for _, groupname := range groupnames {
+			nodepool.Spec.NodeGroup = append(nodepool.Spec.NodeGroup, hwmgmtv1alpha1.NodeGroup{
+				NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: groupname, ResourcePoolId: "pool1"},
+				Size:         1,
+			})
+		}
+		return nodepool
+	}
+
+	newNode := func(name, groupname string) hwmgmtv1alpha1.Node {
+		node := hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+		node.Spec.GroupName = groupname
+		return node
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = newConfigMapClient(4)
+		a = NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test")
+		hwmgr = &pluginv1alpha1.HardwareManager{ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "test"}}
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{{
+				CloudID: "cloud1",
+				Nodegroups: map[string][]string{
+					"master": {"master-node1", "master-node2"},
+					"worker": {"worker-node1"},
+				},
+				Pending: map[string]string{"master-node2": "node2"},
+			}},
+		})
+	})
+
+	It("releases the allocations with no Node, keeping pending claims", func() {
+		nodepool := newNodePool("np1", "master", "worker")
+		issues, err := a.CheckAllocations(ctx, hwmgr, nodepool, []hwmgmtv1alpha1.Node{newNode("master-node1", "master")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(ConsistOf(pluginv1alpha1.ConsistencyIssue{
+			Kind:     utils.ConsistencyOrphanedAllocation,
+			NodePool: "np1",
+			Name:     "worker-node1",
+			Repaired: true,
+			Message:  "released from nodegroup worker",
+		}))
+
+		allocations := c.getAllocations()
+		Expect(allocations.getCloud("cloud1").Nodegroups).To(Equal(map[string][]string{
+			"master": {"master-node1", "master-node2"},
+			"worker": {},
+		}))
+	})
+
+	It("reports the Nodes missing from the allocation", func() {
+		nodepool := newNodePool("np1", "master", "worker")
+		nodes := []hwmgmtv1alpha1.Node{
+			newNode("master-node1", "master"),
+			newNode("worker-node1", "worker"),
+			newNode("worker-node2", "worker"),
+		}
+		issues, err := a.CheckAllocations(ctx, hwmgr, nodepool, nodes)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(ConsistOf(pluginv1alpha1.ConsistencyIssue{
+			Kind:     utils.ConsistencyUnallocatedNode,
+			NodePool: "np1",
+			Name:     "worker-node2",
+			Message:  "node is not allocated to nodegroup worker",
+		}))
+		Expect(c.updates).To(Equal(1))
+	})
+
+	It("checks only the nodegroups of the NodePool in a shared cloud", func() {
+		// The master and worker nodegroups of the cloud are held by separate NodePools
+		masters := newNodePool("np-masters", "master")
+		issues, err := a.CheckAllocations(ctx, hwmgr, masters, []hwmgmtv1alpha1.Node{newNode("master-node1", "master")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+
+		workers := newNodePool("np-workers", "worker")
+		issues, err = a.CheckAllocations(ctx, hwmgr, workers, []hwmgmtv1alpha1.Node{newNode("worker-node1", "worker")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+
+		allocations := c.getAllocations()
+		Expect(allocations.getCloud("cloud1").Nodegroups).To(Equal(map[string][]string{
+			"master": {"master-node1", "master-node2"},
+			"worker": {"worker-node1"},
+		}))
+	})
+})
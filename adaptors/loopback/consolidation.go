@@ -0,0 +1,246 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getHwMgrNodes returns the Node CRs of the hardware manager, across its watch namespaces
+func (a *Adaptor) getHwMgrNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]hwmgmtv1alpha1.Node, error) {
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	if err := a.Client.List(ctx, nodelist); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	return slices.DeleteFunc(nodelist.Items, func(node hwmgmtv1alpha1.Node) bool {
+		return node.Spec.HwMgrId != hwmgr.Name
+	}), nil
+}
+
+// isNodeMigratable returns true if the allocation of the node can be migrated. Spares, and nodes that were adopted or
+// healed with a spare, remain in place.
+func isNodeMigratable(allocations cmAllocations, node *hwmgmtv1alpha1.Node) bool {
+	for _, cloud := range allocations.Clouds {
+		if _, adopted := cloud.Adopted[node.Name]; adopted {
+			return false
+		}
+		if _, replaced := cloud.Replaced[node.Name]; replaced {
+			return false
+		}
+		for _, nodenames := range cloud.Nodegroups {
+			if slices.Contains(nodenames, node.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PlanConsolidation plans the migration of allocated nodes to the free nodes of their resource pool with the lowest
+// node IDs, so that the free capacity of each pool forms a contiguous range at the end of the pool. A node is only
+// moved to a free node that satisfies the node selector and storage layout of its nodegroup.
+func (a *Adaptor) PlanConsolidation(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	resourcePoolIds []string) ([]pluginv1alpha1.ConsolidationMove, error) {
+
+	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	nodes, err := a.getHwMgrNodes(ctx, hwmgr)
+	if err != nil {
+		return nil, err
+	}
+
+	inuse := getNodesInUse(allocations)
+	for _, node := range nodes {
+		inuse[node.Spec.HwMgrNodeId] = true
+	}
+
+	nodepools := make(map[types.NamespacedName]*hwmgmtv1alpha1.NodePool)
+	var moves []pluginv1alpha1.ConsolidationMove
+	for _, poolID := range resourcePoolIds {
+		if !slices.Contains(resources.ResourcePools, poolID) {
+			return nil, utils.NewInputError("unknown resource pool: %s", poolID)
+		}
+
+		var freenodes []string
+		for nodeId, info := range resources.Nodes {
+			if info.ResourcePoolID == poolID && !inuse[nodeId] && !info.Failed {
+				freenodes = append(freenodes, nodeId)
+			}
+		}
+		slices.Sort(freenodes)
+
+		// Consider the allocated nodes from the highest node ID
+		var allocated []*hwmgmtv1alpha1.Node
+		for i := range nodes {
+			node := &nodes[i]
+			if resources.Nodes[node.Spec.HwMgrNodeId].ResourcePoolID == poolID && isNodeMigratable(allocations, node) &&
+				!utils.IsNodeFailed(node) {
+				allocated = append(allocated, node)
+			}
+		}
+		slices.SortFunc(allocated, func(x, y *hwmgmtv1alpha1.Node) int {
+			return strings.Compare(y.Spec.HwMgrNodeId, x.Spec.HwMgrNodeId)
+		})
+
+		for _, node := range allocated {
+			key := types.NamespacedName{Name: node.Spec.NodePool, Namespace: node.Namespace}
+			nodepool, exists := nodepools[key]
+			if !exists {
+				nodepool = &hwmgmtv1alpha1.NodePool{}
+				if err := a.Client.Get(ctx, key, nodepool); err != nil {
+					return nil, fmt.Errorf("failed to get NodePool %s: %w", node.Spec.NodePool, err)
+				}
+				nodepools[key] = nodepool
+			}
+
+			selector, err := utils.GetNodeGroupNodeSelector(nodepool, node.Spec.GroupName)
+			if err != nil {
+				return nil, fmt.Errorf("invalid node selector: %w", err)
+			}
+			target := slices.IndexFunc(freenodes, func(nodeId string) bool {
+				info := resources.Nodes[nodeId]
+				return nodeId < node.Spec.HwMgrNodeId && selector.Matches(info.attributes()) &&
+					utils.CheckHwProfileCompatibility(hwmgr, node.Spec.HwProfile, info.capabilities()) == nil
+			})
+			if target < 0 {
+				continue
+			}
+
+			moves = append(moves, pluginv1alpha1.ConsolidationMove{
+				NodePool:       node.Spec.NodePool,
+				Node:           node.Name,
+				ResourcePoolId: poolID,
+				SourceNodeId:   node.Spec.HwMgrNodeId,
+				TargetNodeId:   freenodes[target],
+			})
+			freenodes = slices.Delete(freenodes, target, target+1)
+		}
+	}
+
+	return moves, nil
+}
+
+// ExecuteConsolidationMove migrates the allocation of a node to the target node. As with the replacement of a failed
+// node by a spare, the Node CR is retained and updated with the details of the target node.
+func (a *Adaptor) ExecuteConsolidationMove(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	move *pluginv1alpha1.ConsolidationMove) error {
+
+	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	nodes, err := a.getHwMgrNodes(ctx, hwmgr)
+	if err != nil {
+		return err
+	}
+
+	// The Node CR is in the namespace of its NodePool, which may be any of the watch namespaces of the hardware manager
+	index := slices.IndexFunc(nodes, func(node hwmgmtv1alpha1.Node) bool {
+		return node.Name == move.Node && node.Spec.NodePool == move.NodePool
+	})
+	if index == -1 {
+		return fmt.Errorf("failed to get node %s: not found", move.Node)
+	}
+	node := nodes[index].DeepCopy()
+
+	switch node.Spec.HwMgrNodeId {
+	case move.TargetNodeId:
+		// The move was executed by an earlier reconcile
+		return nil
+	case move.SourceNodeId:
+	default:
+		return fmt.Errorf("node %s is no longer allocated node %s", node.Name, move.SourceNodeId)
+	}
+
+	info, exists := resources.Nodes[move.TargetNodeId]
+	if !exists {
+		return fmt.Errorf("unable to find nodeinfo for %s", move.TargetNodeId)
+	}
+	if getNodesInUse(allocations)[move.TargetNodeId] || info.Failed || utils.FindNodeInList(
+		hwmgmtv1alpha1.NodeList{Items: nodes}, hwmgr.Name, move.TargetNodeId) != "" {
+		return fmt.Errorf("target node %s is no longer free", move.TargetNodeId)
+	}
+
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Name: node.Spec.NodePool, Namespace: node.Namespace}, nodepool); err != nil {
+		return fmt.Errorf("failed to get NodePool %s: %w", node.Spec.NodePool, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Migrating node allocation",
+		slog.String("nodename", node.Name),
+		slog.String("sourceNodeId", move.SourceNodeId),
+		slog.String("targetNodeId", move.TargetNodeId))
+
+	// Claim the target in the configmap before updating the node, checking again that it is free if the configmap has
+	// been updated concurrently
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, _ cmResources, allocations *cmAllocations) (bool, error) {
+		cloud := allocations.getCloud(nodepool.Spec.CloudID)
+		if cloud == nil {
+			return false, fmt.Errorf("unable to find allocations for cloud %s", nodepool.Spec.CloudID)
+		}
+		if getNodesInUse(*allocations)[move.TargetNodeId] {
+			return false, fmt.Errorf("target node %s is no longer free", move.TargetNodeId)
+		}
+
+		if cloud.Migrated == nil {
+			cloud.Migrated = make(map[string]string)
+		}
+		cloud.Migrated[node.Name] = move.TargetNodeId
+		delete(cloud.Pending, node.Name)
+		allocations.recordAllocation(move.TargetNodeId)
+		return true, nil
+	}); err != nil {
+		return fmt.Errorf("failed to claim target node %s: %w", move.TargetNodeId, err)
+	}
+
+	if err := a.CreateBMCSecret(ctx, nodepool, node.Name, node.Spec.GroupName, info.BMC.UsernameBase64, info.BMC.PasswordBase64); err != nil {
+		return fmt.Errorf("failed to update bmc-secret for node %s: %w", node.Name, err)
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.HwMgrNodeId = move.TargetNodeId
+	if err := a.Client.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to patch Node %s: %w", node.Name, err)
+	}
+
+	storage := utils.GetHwProfileStorageLayout(hwmgr, node.Spec.HwProfile)
+	if _, err := a.UpdateNodeStatus(ctx, hwmgr, node.Namespace, node.Name, info, node.Spec.HwProfile, storage); err != nil {
+		return fmt.Errorf("failed to update node status (%s): %w", node.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// consolidationClient serves a set of Node and NodePool CRs alongside the nodelist configmap, recording the names of
+// the patched Node CRs
+type consolidationClient struct {
+	*configMapClient
+	nodes     []hwmgmtv1alpha1.Node
+	nodepools []hwmgmtv1alpha1.NodePool
+	patched   []string
+}
+
+func (c *consolidationClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	nodepool, ok := obj.(*hwmgmtv1alpha1.NodePool)
+	if !ok {
+		return c.configMapClient.Get(ctx, key, obj, opts...)
+	}
+	for i := range c.nodepools {
+		if c.nodepools[i].Name == key.Name && c.nodepools[i].Namespace == key.Namespace {
+			c.nodepools[i].DeepCopyInto(nodepool)
+			return nil
+		}
+	}
+	return k8serrors.NewNotFound(hwmgmtv1alpha1.GroupVersion.WithResource("nodepools").GroupResource(), key.Name)
+}
+
+func (c *consolidationClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	nodelist := list.(*hwmgmtv1alpha1.NodeList)
+	nodelist.Items = nil
+	for i := range c.nodes {
+		nodelist.Items = append(nodelist.Items, *c.nodes[i].DeepCopy())
+	}
+	return nil
+}
+
+func (c *consolidationClient) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	c.patched = append(c.patched, obj.GetName())
+	return nil
+}
+
+// newConsolidationNode returns a Node CR allocated by the loopback adaptor, which is named after its node ID
+func newConsolidationNode(nodeId string) hwmgmtv1alpha1.Node {
+	return hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeId, Namespace: "test"},
+		Spec: hwmgmtv1alpha1.NodeSpec{
+			NodePool:    "np1",
+			GroupName:   "master",
+			HwMgrId:     "hwmgr",
+			HwMgrNodeId: nodeId,
+		},
+	}
+}
+
+var _ = Describe("Consolidation", func() {
+	var (
+		ctx   context.Context
+		c     *consolidationClient
+		a     *Adaptor
+		hwmgr *pluginv1alpha1.HardwareManager
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = &consolidationClient{
+			configMapClient: newConfigMapClient(6),
+			nodes: []hwmgmtv1alpha1.Node{
+				newConsolidationNode("node3"),
+				newConsolidationNode("node5"),
+			},
+			nodepools: []hwmgmtv1alpha1.NodePool{{
+				ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "test"},
+				Spec: hwmgmtv1alpha1.NodePoolSpec{
+					CloudID: "cloud1",
+					HwMgrId: "hwmgr",
+					NodeGroup: []hwmgmtv1alpha1.NodeGroup{{
+						NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "master", ResourcePoolId: "pool1"},
+						Size:         2,
+					}},
+				},
+			}},
+		}
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{{
+				CloudID:    "cloud1",
+				Nodegroups: map[string][]string{"master": {"node3", "node5"}},
+			}},
+		})
+		a = NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test")
+		hwmgr = &pluginv1alpha1.HardwareManager{ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "test"}}
+	})
+
+	It("plans moves packing the allocated nodes into the lowest free nodes of the pool", func() {
+		moves, err := a.PlanConsolidation(ctx, hwmgr, []string{"pool1"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moves).To(Equal([]pluginv1alpha1.ConsolidationMove{
+			{NodePool: "np1", Node: "node5", ResourcePoolId: "pool1", SourceNodeId: "node5", TargetNodeId: "node1"},
+			{NodePool: "np1", Node: "node3", ResourcePoolId: "pool1", SourceNodeId: "node3", TargetNodeId: "node2"},
+		}))
+	})
+
+	It("plans no moves when the pool is already packed", func() {
+		c.nodes = []hwmgmtv1alpha1.Node{
+			newConsolidationNode("node1"),
+			newConsolidationNode("node2"),
+		}
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{{
+				CloudID:    "cloud1",
+				Nodegroups: map[string][]string{"master": {"node1", "node2"}},
+			}},
+		})
+
+		moves, err := a.PlanConsolidation(ctx, hwmgr, []string{"pool1"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moves).To(BeEmpty())
+	})
+
+	It("rejects an unknown resource pool", func() {
+		_, err := a.PlanConsolidation(ctx, hwmgr, []string{"pool2"})
+		Expect(utils.IsInputError(err)).To(BeTrue())
+	})
+
+	When("the target node is no longer free", func() {
+		move := &pluginv1alpha1.ConsolidationMove{
+			NodePool:       "np1",
+			Node:           "node5",
+			ResourcePoolId: "pool1",
+			SourceNodeId:   "node5",
+			TargetNodeId:   "node1",
+		}
+
+		allocatedElsewhere := cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{
+				{
+					CloudID:    "cloud1",
+					Nodegroups: map[string][]string{"master": {"node3", "node5"}},
+				},
+				{
+					CloudID:    "cloud-other",
+					Nodegroups: map[string][]string{"master": {"node1"}},
+				},
+			},
+		}
+
+		It("fails the move without changing the allocations or the node", func() {
+			c.setAllocations(allocatedElsewhere)
+			updates := c.updates
+
+			err := a.ExecuteConsolidationMove(ctx, hwmgr, move)
+			Expect(err).To(MatchError(ContainSubstring("target node node1 is no longer free")))
+			Expect(c.updates).To(Equal(updates))
+			Expect(c.patched).To(BeEmpty())
+		})
+
+		It("fails the move when the target is claimed concurrently", func() {
+			c.beforeUpdate = func(c *configMapClient) {
+				c.setAllocations(allocatedElsewhere)
+			}
+
+			err := a.ExecuteConsolidationMove(ctx, hwmgr, move)
+			Expect(err).To(MatchError(ContainSubstring("target node node1 is no longer free")))
+			Expect(c.patched).To(BeEmpty())
+
+			allocations := c.getAllocations()
+			Expect(allocations.getCloud("cloud1").Migrated).To(BeEmpty())
+			Expect(allocations.getCloud("cloud-other").Nodegroups["master"]).To(Equal([]string{"node1"}))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DecommissionNode simulates the decommission of a node, powering it down, wiping its disks if requested and
+// releasing it from the NodePool allocation. The node is recorded as decommissioned in the nodelist configmap, so
+// that it is not reallocated.
+func (a *Adaptor) DecommissionNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	report *utils.DecommissionReport) error {
+
+	nodeId := node.Spec.HwMgrNodeId
+	a.Logger.InfoContext(ctx, "Decommissioning node",
+		slog.String("nodeId", nodeId),
+		slog.String("mode", string(report.Mode)))
+
+	if err := a.updateAllocations(ctx, func(cm *corev1.ConfigMap, resources cmResources, allocations *cmAllocations) (bool, error) {
+		info, exists := resources.Nodes[nodeId]
+		if !exists {
+			return false, fmt.Errorf("unable to find nodeinfo for %s", nodeId)
+		}
+
+		// Power down the node
+		info.PowerState = string(utils.PowerStateOff)
+		info.BootProgress = string(utils.BootProgressNone)
+		resources.Nodes[nodeId] = info
+
+		report.SerialNumber = info.SerialNumber
+		report.PoweredOff = true
+		report.Disks = info.wipeDisks(report.Mode)
+
+		// Release the node from the allocation of the NodePool
+		allocations.releaseNode(nodepool.Spec.CloudID, node)
+		if !slices.Contains(allocations.Decommissioned, nodeId) {
+			allocations.Decommissioned = append(allocations.Decommissioned, nodeId)
+		}
+
+		yamlString, err := yaml.Marshal(&resources)
+		if err != nil {
+			return false, fmt.Errorf("unable to marshal resources: %w", err)
+		}
+		cm.Data[resourcesKey] = string(yamlString)
+		return true, nil
+	}); err != nil {
+		return fmt.Errorf("failed to decommission node %s: %w", node.Name, err)
+	}
+
+	return nil
+}
+
+// wipeDisks simulates the wipe of the disks of the node, which always succeeds. Disks are identified by their serial
+// number if specified for the node, or by index otherwise.
+func (info cmNodeInfo) wipeDisks(mode utils.DecommissionMode) []utils.DecommissionDiskReport {
+	status := utils.WipeStatusNotRequested
+	if mode == utils.DecommissionModeWipe {
+		status = utils.WipeStatusCompleted
+	}
+
+	count := max(info.PhysicalDisks, len(info.DiskSerials))
+	disks := make([]utils.DecommissionDiskReport, 0, count)
+	for i := 0; i < count; i++ {
+		disk := utils.DecommissionDiskReport{Id: fmt.Sprintf("disk%d", i), WipeStatus: status}
+		if i < len(info.DiskSerials) {
+			disk.SerialNumber = info.DiskSerials[i]
+		}
+		disks = append(disks, disk)
+	}
+
+	return disks
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// applyPowerCap returns the power cap achieved by the simulated node for the requested cap, which is limited to the
+// range supported by the node, if specified in the nodelist configmap
+func (info cmNodeInfo) applyPowerCap(requested int) int {
+	switch {
+	case info.MinPowerCap > 0 && requested < info.MinPowerCap:
+		return info.MinPowerCap
+	case info.MaxPowerCap > 0 && requested > info.MaxPowerCap:
+		return info.MaxPowerCap
+	default:
+		return requested
+	}
+}
+
+// ApplyNodePowerCaps applies the power cap of the energy policy to the allocated nodes, reporting the achieved cap in
+// the PowerCap condition of each node
+func (a *Adaptor) ApplyNodePowerCaps(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	requested, err := utils.GetNodePoolPowerCap(hwmgr, nodepool)
+	if err != nil {
+		return fmt.Errorf("invalid energy policy: %w", err)
+	}
+
+	_, resources, _, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		info, exists := resources.Nodes[node.Spec.HwMgrNodeId]
+		if !exists {
+			continue
+		}
+
+		achieved := info.applyPowerCap(requested)
+		if !utils.SetNodePowerCapStatus(node, requested, achieved, nil) {
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Node power cap changed",
+			slog.String("nodename", node.Name),
+			slog.Int("requested", requested),
+			slog.Int("achieved", achieved))
+		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+			utils.SetNodePowerCapStatus(node, requested, achieved, nil)
+		}); err != nil {
+			return fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
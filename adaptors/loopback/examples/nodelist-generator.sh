@@ -3,6 +3,7 @@
 
 PROG=$(basename "$0")
 declare -A POOLS=()
+declare -A ATTRIBUTES=()
 
 USERNAME_BASE64=$(echo -n "admin" | base64)
 PASSWORD_BASE64=$(echo -n "mypass" | base64)
@@ -12,10 +13,12 @@ function usage {
 Usage: ${PROG} ...
 Parameters:
     --resourcepool <name:prefix:size>
+    --attributes <name:cpus:memoryGiB:nicmodel[,nicmodel...]:location>
 
 Example:
 
-${0} --resourcepool master:dummy-sp-64g:5 --resourcepool worker:dummy-dp-128g:3
+${0} --resourcepool master:dummy-sp-64g:5 --resourcepool worker:dummy-dp-128g:3 \\
+    --attributes worker:64:128:e810:rack-1
 
 EOF
     exit 1
@@ -41,6 +44,35 @@ function resourcepools {
     done
 }
 
+function attributes {
+    local pool="$1"
+    local value="${ATTRIBUTES[${pool}]}"
+    if [ -z "${value}" ]; then
+        return
+    fi
+
+    cpus=$(echo "${value}" | awk -F: '{print $1}')
+    memory=$(echo "${value}" | awk -F: '{print $2}')
+    nics=$(echo "${value}" | awk -F: '{print $3}')
+    location=$(echo "${value}" | awk -F: '{print $4}')
+
+    if [ -n "${cpus}" ]; then
+        echo "        cpus: ${cpus}"
+    fi
+    if [ -n "${memory}" ]; then
+        echo "        memoryGiB: ${memory}"
+    fi
+    if [ -n "${nics}" ]; then
+        echo "        nicModels:"
+        for nic in ${nics//,/ }; do
+            echo "          - ${nic}"
+        done
+    fi
+    if [ -n "${location}" ]; then
+        echo "        location: ${location}"
+    fi
+}
+
 function nodes {
     echo "    nodes:"
     group=0
@@ -59,6 +91,9 @@ function nodes {
             cat <<EOF
       ${nodename}:
         poolID: ${pool}
+EOF
+            attributes "${pool}"
+            cat <<EOF
         bmc:
           address: "idrac-virtualmedia+https://${ip}/redfish/v1/Systems/System.Embedded.1"
           username-base64: ${USERNAME_BASE64}
@@ -79,11 +114,12 @@ EOF
 longopts=(
     "help"
     "resourcepool:"
+    "attributes:"
 )
 
 longopts_str=$(IFS=,; echo "${longopts[*]}")
 
-if ! OPTS=$(getopt -o "hp:" --long "${longopts_str}" --name "$0" -- "$@"); then
+if ! OPTS=$(getopt -o "hp:a:" --long "${longopts_str}" --name "$0" -- "$@"); then
     usage
     exit 1
 fi
@@ -100,6 +136,12 @@ while :; do
             POOLS+=(["${name}"]="${prefix}:${size}")
             shift 2
             ;;
+        -a|--attributes)
+            value="$2"
+            name=$(echo "${value}" | awk -F: '{print $1}')
+            ATTRIBUTES+=(["${name}"]="${value#*:}")
+            shift 2
+            ;;
         --)
             shift
             break                                                                                                                                                                              ;;
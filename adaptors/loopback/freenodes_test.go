@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+var _ = Describe("Free nodes", func() {
+	var (
+		ctx   context.Context
+		c     *configMapClient
+		a     *Adaptor
+		hwmgr *pluginv1alpha1.HardwareManager
+	)
+
+	// getFreeNodeIds returns the IDs of the free nodes listed for the query
+	getFreeNodeIds := func(query utils.FreeNodeQuery) []string {
+		freenodes, err := a.GetFreeNodes(ctx, hwmgr, query)
+		Expect(err).ToNot(HaveOccurred())
+		nodeIds := []string{}
+		for _, freenode := range freenodes {
+			nodeIds = append(nodeIds, freenode.NodeId)
+		}
+		return nodeIds
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = newConfigMapClient(0)
+		c.setResources(cmResources{
+			SchemaVersion: resourcesSchema.Version(),
+			ResourcePools: []string{"pool1", "pool2"},
+			Nodes: map[string]cmNodeInfo{
+				"node1": {ResourcePoolID: "pool1", CPUs: 8, Location: "rack1"},
+				"node2": {ResourcePoolID: "pool1", CPUs: 16, Location: "rack1"},
+				"node3": {ResourcePoolID: "pool1", CPUs: 16, Location: "rack2"},
+				"node4": {ResourcePoolID: "pool1", CPUs: 32, Location: "rack2"},
+				"node5": {ResourcePoolID: "pool2", CPUs: 32, Location: "rack1"},
+			},
+		})
+		a = NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test")
+		hwmgr = &pluginv1alpha1.HardwareManager{ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "test"}}
+	})
+
+	It("lists the free nodes of the resource pool", func() {
+		freenodes, err := a.GetFreeNodes(ctx, hwmgr, utils.FreeNodeQuery{ResourcePoolId: "pool2"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freenodes).To(Equal([]utils.FreeNode{{
+			NodeId:         "node5",
+			ResourcePoolId: "pool2",
+			Attributes:     utils.NodeAttributes{CPUs: 32, Location: "rack1"},
+		}}))
+
+		Expect(getFreeNodeIds(utils.FreeNodeQuery{ResourcePoolId: "pool1"})).To(
+			Equal([]string{"node1", "node2", "node3", "node4"}))
+	})
+
+	It("lists only the free nodes that match the node selector", func() {
+		Expect(getFreeNodeIds(utils.FreeNodeQuery{
+			ResourcePoolId: "pool1",
+			Selector:       &utils.NodeSelector{MinCPUs: 16},
+		})).To(Equal([]string{"node2", "node3", "node4"}))
+
+		Expect(getFreeNodeIds(utils.FreeNodeQuery{
+			ResourcePoolId: "pool1",
+			Selector:       &utils.NodeSelector{MinCPUs: 16, Locations: []string{"rack1"}},
+		})).To(Equal([]string{"node2"}))
+
+		Expect(getFreeNodeIds(utils.FreeNodeQuery{
+			ResourcePoolId: "pool1",
+			Selector:       &utils.NodeSelector{MinCPUs: 64},
+		})).To(BeEmpty())
+	})
+
+	It("excludes the blocked nodes", func() {
+		hwmgr.Spec.BlockedNodes = []pluginv1alpha1.BlockedNode{
+			{NodeId: "node1"},
+			{NodeId: "node2", ResourcePoolId: "pool1"},
+			{NodeId: "node3", ResourcePoolId: "pool2"},
+		}
+
+		Expect(getFreeNodeIds(utils.FreeNodeQuery{ResourcePoolId: "pool1"})).To(Equal([]string{"node3", "node4"}))
+	})
+
+	It("excludes the allocated, pending and decommissioned nodes", func() {
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{{
+				CloudID:    "cloud1",
+				Nodegroups: map[string][]string{"master": {"node1"}},
+				Pending:    map[string]string{"master-1": "node2"},
+			}},
+			Decommissioned: []string{"node3"},
+		})
+
+		Expect(getFreeNodeIds(utils.FreeNodeQuery{ResourcePoolId: "pool1"})).To(Equal([]string{"node4"}))
+	})
+
+	It("excludes the failed nodes", func() {
+		resources := cmResources{
+			SchemaVersion: resourcesSchema.Version(),
+			ResourcePools: []string{"pool1"},
+			Nodes: map[string]cmNodeInfo{
+				"node1": {ResourcePoolID: "pool1"},
+				"node2": {ResourcePoolID: "pool1", Failed: true},
+			},
+		}
+		c.setResources(resources)
+
+		Expect(getFreeNodeIds(utils.FreeNodeQuery{ResourcePoolId: "pool1"})).To(Equal([]string{"node1"}))
+	})
+
+	It("rejects an unknown resource pool", func() {
+		_, err := a.GetFreeNodes(ctx, hwmgr, utils.FreeNodeQuery{ResourcePoolId: "pool3"})
+		Expect(utils.IsInputError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,90 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// getNodeGroupTarget returns the number of nodes to allocate to the nodegroup. A nodegroup with a BestEffort
+// fulfillment policy is allocated up to the nodes available to it: the nodes already allocated, the nodes pending
+// adoption, and the free nodes that satisfy its node selector and hardware profile.
+func (a *Adaptor) getNodeGroupTarget(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodegroup hwmgmtv1alpha1.NodeGroup,
+	resources cmResources,
+	allocations cmAllocations,
+	cloud *cmAllocatedCloud) (int, error) {
+
+	groupname := nodegroup.NodePoolData.Name
+	if !utils.IsNodeGroupBestEffort(nodepool, groupname) {
+		return nodegroup.Size, nil
+	}
+
+	allocated := 0
+	if cloud != nil {
+		allocated = len(cloud.Nodegroups[groupname])
+	}
+	if allocated >= nodegroup.Size {
+		return nodegroup.Size, nil
+	}
+
+	pending, err := a.getPendingAdoptedNodes(hwmgr, nodepool, nodegroup, resources, allocations, cloud)
+	if err != nil {
+		return 0, err
+	}
+
+	selector, err := utils.GetNodeGroupNodeSelector(nodepool, groupname)
+	if err != nil {
+		return 0, fmt.Errorf("invalid node selector: %w", err)
+	}
+
+	freenodes := a.getFreeNodesInPool(hwmgr, resources, allocations, cloud, nodegroup.NodePoolData.ResourcePoolId, selector)
+	// With no required count, only the compatible candidates are returned
+	freenodes, _ = utils.FilterProfileCompatibleCandidates(hwmgr, nodegroup.NodePoolData.HwProfile,
+		nodegroup.NodePoolData.ResourcePoolId, 0, freenodes, func(nodeId string) utils.NodeCapabilities {
+			return resources.Nodes[nodeId].capabilities()
+		})
+
+	// Adopted nodes are free nodes of the pool, so may also be among the candidates
+	available := make(map[string]bool)
+	for _, nodeId := range append(pending, freenodes...) {
+		available[nodeId] = true
+	}
+
+	return min(nodegroup.Size, allocated+len(available)), nil
+}
+
+// reportNodePoolFulfillment reports the shortfall of the BestEffort nodegroups of an allocated NodePool
+func (a *Adaptor) reportNodePoolFulfillment(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	if err := utils.UpdateNodePoolFulfillmentCondition(ctx, a.Client, nodepool, nodelist); err != nil {
+		return fmt.Errorf("failed to update fulfillment status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// Annotations on the nodelist configmap requesting changes to the simulated inventory. Each annotation is removed
+// once the request has been processed.
+const (
+	// AddResourcePoolsAnnotation holds a comma-separated list of resource pools to add
+	AddResourcePoolsAnnotation = "hwmgr-plugin.oran.openshift.io/addResourcePools"
+	// RemoveResourcePoolsAnnotation holds a comma-separated list of resource pools to remove, which must have no nodes
+	RemoveResourcePoolsAnnotation = "hwmgr-plugin.oran.openshift.io/removeResourcePools"
+	// AddNodesAnnotation holds a YAML map of node ID to node info, in the format of the configmap resources
+	AddNodesAnnotation = "hwmgr-plugin.oran.openshift.io/addNodes"
+	// RemoveNodesAnnotation holds a comma-separated list of node IDs to remove, which must not be in use
+	RemoveNodesAnnotation = "hwmgr-plugin.oran.openshift.io/removeNodes"
+	// FailNodesAnnotation holds a comma-separated list of node IDs to mark as failed
+	FailNodesAnnotation = "hwmgr-plugin.oran.openshift.io/failNodes"
+	// RecoverNodesAnnotation holds a comma-separated list of node IDs to mark as healthy
+	RecoverNodesAnnotation = "hwmgr-plugin.oran.openshift.io/recoverNodes"
+	// InventoryErrorAnnotation is set on the nodelist configmap with the reason a request was rejected
+	InventoryErrorAnnotation = "hwmgr-plugin.oran.openshift.io/inventoryError"
+)
+
+var inventoryRequestAnnotations = []string{
+	AddResourcePoolsAnnotation,
+	RemoveResourcePoolsAnnotation,
+	AddNodesAnnotation,
+	RemoveNodesAnnotation,
+	FailNodesAnnotation,
+	RecoverNodesAnnotation,
+}
+
+// inventoryReconciler applies the inventory change requests annotated on the nodelist configmap
+type inventoryReconciler struct {
+	*Adaptor
+}
+
+// parseIdList splits a comma-separated annotation value, ignoring empty entries
+func parseIdList(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// applyInventoryRequests updates the resources with the requested changes. Pools are added before nodes are added
+// or updated, and nodes are removed before pools are removed, so a single request can populate or clear a pool.
+func applyInventoryRequests(annotations map[string]string, resources *cmResources, allocations cmAllocations) error {
+	for _, poolID := range parseIdList(annotations[AddResourcePoolsAnnotation]) {
+		if !slices.Contains(resources.ResourcePools, poolID) {
+			resources.ResourcePools = append(resources.ResourcePools, poolID)
+		}
+	}
+
+	if data := annotations[AddNodesAnnotation]; data != "" {
+		var nodes map[string]cmNodeInfo
+		if err := yaml.Unmarshal([]byte(data), &nodes); err != nil {
+			return fmt.Errorf("failed to parse %s annotation: %w", AddNodesAnnotation, err)
+		}
+		for nodeId, info := range nodes {
+			if _, exists := resources.Nodes[nodeId]; exists {
+				return fmt.Errorf("node %s already exists", nodeId)
+			}
+			if !slices.Contains(resources.ResourcePools, info.ResourcePoolID) {
+				return fmt.Errorf("node %s specifies unknown resource pool %s", nodeId, info.ResourcePoolID)
+			}
+			if info.BMC == nil {
+				return fmt.Errorf("node %s is missing bmc info", nodeId)
+			}
+			if resources.Nodes == nil {
+				resources.Nodes = make(map[string]cmNodeInfo)
+			}
+			resources.Nodes[nodeId] = info
+		}
+	}
+
+	for _, health := range []struct {
+		annotation string
+		failed     bool
+	}{{FailNodesAnnotation, true}, {RecoverNodesAnnotation, false}} {
+		for _, nodeId := range parseIdList(annotations[health.annotation]) {
+			info, exists := resources.Nodes[nodeId]
+			if !exists {
+				return fmt.Errorf("unknown node %s in %s annotation", nodeId, health.annotation)
+			}
+			info.Failed = health.failed
+			resources.Nodes[nodeId] = info
+		}
+	}
+
+	inuse := getNodesInUse(allocations)
+	for _, nodeId := range parseIdList(annotations[RemoveNodesAnnotation]) {
+		if _, exists := resources.Nodes[nodeId]; !exists {
+			return fmt.Errorf("unknown node %s in %s annotation", nodeId, RemoveNodesAnnotation)
+		}
+		if inuse[nodeId] {
+			return fmt.Errorf("node %s is in use and cannot be removed", nodeId)
+		}
+		delete(resources.Nodes, nodeId)
+	}
+
+	for _, poolID := range parseIdList(annotations[RemoveResourcePoolsAnnotation]) {
+		for nodeId, info := range resources.Nodes {
+			if info.ResourcePoolID == poolID {
+				return fmt.Errorf("resource pool %s still has node %s", poolID, nodeId)
+			}
+		}
+		resources.ResourcePools = slices.DeleteFunc(resources.ResourcePools, func(id string) bool { return id == poolID })
+	}
+
+	return nil
+}
+
+// Reconcile processes the inventory change requests annotated on the nodelist configmap
+func (r *inventoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.NewReconcileContext(ctx)
+
+	cm, resources, allocations, err := r.GetCurrentResources(ctx)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	annotations := cm.GetAnnotations()
+	if !slices.ContainsFunc(inventoryRequestAnnotations, func(annotation string) bool {
+		_, exists := annotations[annotation]
+		return exists
+	}) {
+		return utils.DoNotRequeue(), nil
+	}
+
+	requestErr := applyInventoryRequests(annotations, &resources, allocations)
+	if requestErr != nil {
+		// The request is rejected as a whole, leaving the inventory untouched
+		r.Logger.InfoContext(ctx, "Rejecting loopback inventory request", slog.String("error", requestErr.Error()))
+		annotations[InventoryErrorAnnotation] = requestErr.Error()
+	} else {
+		yamlString, err := yaml.Marshal(&resources)
+		if err != nil {
+			return utils.DoNotRequeue(), fmt.Errorf("unable to marshal resources: %w", err)
+		}
+		cm.Data[resourcesKey] = string(yamlString)
+		delete(annotations, InventoryErrorAnnotation)
+		r.Logger.InfoContext(ctx, "Applied loopback inventory request",
+			slog.Int("resourcePools", len(resources.ResourcePools)),
+			slog.Int("nodes", len(resources.Nodes)))
+	}
+
+	for _, annotation := range inventoryRequestAnnotations {
+		delete(annotations, annotation)
+	}
+	cm.SetAnnotations(annotations)
+
+	if err := r.Client.Update(ctx, cm); err != nil {
+		return utils.RequeueImmediately(), fmt.Errorf("failed to update configmap: %w", err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// setupInventoryController registers the controller that processes inventory change requests on the nodelist
+// configmap
+func (a *Adaptor) setupInventoryController(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("loopback-inventory").
+		For(&corev1.ConfigMap{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			return object.GetName() == cmName && object.GetNamespace() == a.Namespace
+		})).
+		Complete(&inventoryReconciler{Adaptor: a}); err != nil {
+		return fmt.Errorf("failed to setup loopback inventory controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Inventory requests", func() {
+	var (
+		resources   cmResources
+		allocations cmAllocations
+	)
+
+	BeforeEach(func() {
+		resources = cmResources{
+			ResourcePools: []string{"pool1"},
+			Nodes: map[string]cmNodeInfo{
+				"node1": {ResourcePoolID: "pool1", BMC: &cmBmcInfo{Address: "idrac-redfish://192.168.1.1"}},
+				"node2": {ResourcePoolID: "pool1", BMC: &cmBmcInfo{Address: "idrac-redfish://192.168.1.2"}},
+			},
+		}
+		allocations = cmAllocations{
+			Clouds: []cmAllocatedCloud{{
+				CloudID:    "cloud1",
+				Nodegroups: map[string][]string{"master": {"node1"}},
+			}},
+		}
+	})
+
+	It("adds a pool and populates it in a single request", func() {
+		annotations := map[string]string{
+			AddResourcePoolsAnnotation: "pool2, pool3",
+			AddNodesAnnotation:         "node3:\n  poolID: pool2\n  bmc:\n    address: idrac-redfish://192.168.1.3\n",
+		}
+
+		Expect(applyInventoryRequests(annotations, &resources, allocations)).To(Succeed())
+		Expect(resources.ResourcePools).To(Equal([]string{"pool1", "pool2", "pool3"}))
+		Expect(resources.Nodes).To(HaveKeyWithValue("node3", cmNodeInfo{
+			ResourcePoolID: "pool2",
+			BMC:            &cmBmcInfo{Address: "idrac-redfish://192.168.1.3"},
+		}))
+	})
+
+	It("removes a node and clears its pool in a single request", func() {
+		resources.ResourcePools = append(resources.ResourcePools, "pool2")
+		resources.Nodes["node3"] = cmNodeInfo{ResourcePoolID: "pool2", BMC: &cmBmcInfo{}}
+		annotations := map[string]string{
+			RemoveNodesAnnotation:         "node3",
+			RemoveResourcePoolsAnnotation: "pool2",
+		}
+
+		Expect(applyInventoryRequests(annotations, &resources, allocations)).To(Succeed())
+		Expect(resources.ResourcePools).To(Equal([]string{"pool1"}))
+		Expect(resources.Nodes).ToNot(HaveKey("node3"))
+	})
+
+	It("marks nodes as failed and recovers them", func() {
+		Expect(applyInventoryRequests(map[string]string{FailNodesAnnotation: "node1,node2"}, &resources, allocations)).
+			To(Succeed())
+		Expect(resources.Nodes["node1"].Failed).To(BeTrue())
+		Expect(resources.Nodes["node2"].Failed).To(BeTrue())
+
+		Expect(applyInventoryRequests(map[string]string{RecoverNodesAnnotation: "node2"}, &resources, allocations)).
+			To(Succeed())
+		Expect(resources.Nodes["node1"].Failed).To(BeTrue())
+		Expect(resources.Nodes["node2"].Failed).To(BeFalse())
+	})
+
+	DescribeTable("rejects an invalid request",
+		func(annotations map[string]string, message string) {
+			Expect(applyInventoryRequests(annotations, &resources, allocations)).To(MatchError(HavePrefix(message)))
+		},
+		Entry("removing a node in use", map[string]string{RemoveNodesAnnotation: "node1"},
+			"node node1 is in use and cannot be removed"),
+		Entry("removing an unknown node", map[string]string{RemoveNodesAnnotation: "node9"},
+			"unknown node node9 in "+RemoveNodesAnnotation+" annotation"),
+		Entry("removing a pool with nodes", map[string]string{RemoveResourcePoolsAnnotation: "pool1"},
+			"resource pool pool1 still has node"),
+		Entry("adding an existing node",
+			map[string]string{AddNodesAnnotation: "node2:\n  poolID: pool1\n  bmc:\n    address: x\n"},
+			"node node2 already exists"),
+		Entry("adding a node to an unknown pool",
+			map[string]string{AddNodesAnnotation: "node3:\n  poolID: pool9\n  bmc:\n    address: x\n"},
+			"node node3 specifies unknown resource pool pool9"),
+		Entry("adding a node without bmc info", map[string]string{AddNodesAnnotation: "node3:\n  poolID: pool1\n"},
+			"node node3 is missing bmc info"),
+		Entry("failing an unknown node", map[string]string{FailNodesAnnotation: "node9"},
+			"unknown node node9 in "+FailNodesAnnotation+" annotation"),
+	)
+
+	It("leaves the inventory untouched when any part of the request is invalid", func() {
+		c := newConfigMapClient(2)
+		data, err := yaml.Marshal(&allocations)
+		Expect(err).ToNot(HaveOccurred())
+		c.cm.Data[allocationsKey] = string(data)
+		original := c.cm.Data[resourcesKey]
+		c.cm.Annotations = map[string]string{
+			AddResourcePoolsAnnotation: "pool2",
+			FailNodesAnnotation:        "node2",
+			RemoveNodesAnnotation:      "node1",
+		}
+
+		r := &inventoryReconciler{
+			Adaptor: NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test"),
+		}
+		_, err = r.Reconcile(context.Background(), ctrl.Request{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(c.cm.Data[resourcesKey]).To(Equal(original))
+		Expect(c.cm.Annotations).To(Equal(map[string]string{
+			InventoryErrorAnnotation: "node node1 is in use and cannot be removed",
+		}))
+	})
+})
@@ -0,0 +1,156 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;delete
+
+const allocationJanitorInterval = 5 * time.Minute
+
+// setupAllocationJanitor registers the periodic release of allocations for clouds that no longer have a NodePool
+func (a *Adaptor) setupAllocationJanitor(mgr manager.Manager) error {
+	if err := mgr.Add(manager.RunnableFunc(a.runAllocationJanitor)); err != nil {
+		return fmt.Errorf("failed to add allocation janitor: %w", err)
+	}
+
+	return nil
+}
+
+// runAllocationJanitor cleans up orphaned allocations at each interval until the context is canceled
+func (a *Adaptor) runAllocationJanitor(ctx context.Context) error {
+	ticker := time.NewTicker(allocationJanitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := a.cleanupOrphanedAllocations(ctx); err != nil {
+				a.Logger.ErrorContext(ctx, "Failed to clean up orphaned allocations", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// cleanupOrphanedAllocations releases the nodes allocated to clouds with no NodePool, such as after a failed
+// finalizer run, once they have been orphaned for the grace period. The clouds retained under the Retain deletion
+// policy are kept. BMC secrets whose owning NodePool no longer exists are deleted once they are older than the grace
+// period.
+func (a *Adaptor) cleanupOrphanedAllocations(ctx context.Context) error {
+	gracePeriod, enabled := utils.GetAllocationCleanupGracePeriod()
+	if !enabled {
+		return nil
+	}
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := a.Client.List(ctx, nodepools); err != nil {
+		return fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	clouds := make(map[string]bool)
+	owners := make(map[types.UID]bool)
+	for _, nodepool := range nodepools.Items {
+		clouds[nodepool.Spec.CloudID] = true
+		owners[nodepool.UID] = true
+	}
+
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, _ cmResources, allocations *cmAllocations) (bool, error) {
+		changed := false
+		now := metav1.Now()
+		retained := allocations.Clouds[:0]
+		for _, cloud := range allocations.Clouds {
+			switch {
+			case clouds[cloud.CloudID]:
+				// A retained cloud adopted by a new NodePool is released with it, or by the janitor once orphaned
+				if cloud.OrphanedSince != nil || cloud.Retained {
+					cloud.OrphanedSince = nil
+					cloud.Retained = false
+					changed = true
+				}
+			case cloud.Retained:
+			case cloud.OrphanedSince == nil:
+				a.Logger.InfoContext(ctx, "Allocated cloud has no NodePool",
+					slog.String("cloudID", cloud.CloudID),
+					slog.String("gracePeriod", gracePeriod.String()))
+				cloud.OrphanedSince = &now
+				changed = true
+			case now.Sub(cloud.OrphanedSince.Time) >= gracePeriod:
+				a.Logger.InfoContext(ctx, "Releasing allocations of orphaned cloud",
+					slog.String("cloudID", cloud.CloudID),
+					slog.String("orphanedSince", cloud.OrphanedSince.UTC().Format(time.RFC3339)))
+				changed = true
+				continue
+			}
+			retained = append(retained, cloud)
+		}
+
+		allocations.Clouds = retained
+		return changed, nil
+	}); err != nil {
+		return fmt.Errorf("failed to release orphaned allocations: %w", err)
+	}
+
+	return a.cleanupOrphanedSecrets(ctx, owners, gracePeriod)
+}
+
+// cleanupOrphanedSecrets deletes the secrets owned by a NodePool that no longer exists, which would otherwise be left
+// behind if the garbage collector is unable to resolve the owner. Secrets newer than the grace period are skipped, as
+// they may belong to a NodePool created since the NodePools were listed.
+func (a *Adaptor) cleanupOrphanedSecrets(ctx context.Context, owners map[types.UID]bool, gracePeriod time.Duration) error {
+	secrets := &corev1.SecretList{}
+	if err := a.Client.List(ctx, secrets); err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if time.Since(secret.CreationTimestamp.Time) < gracePeriod {
+			continue
+		}
+		for _, owner := range secret.OwnerReferences {
+			if owner.Kind != "NodePool" || owners[owner.UID] {
+				continue
+			}
+
+			a.Logger.InfoContext(ctx, "Deleting secret of deleted NodePool",
+				slog.String("secret", secret.Name),
+				slog.String("nodepool", owner.Name))
+			if err := a.Client.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete secret %s: %w", secret.Name, err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
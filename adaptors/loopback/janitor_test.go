@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// janitorClient serves the nodelist configmap and lists the given NodePools, with no secrets
+type janitorClient struct {
+	*configMapClient
+	nodepools []hwmgmtv1alpha1.NodePool
+}
+
+func (c *janitorClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	switch typed := list.(type) {
+	case *hwmgmtv1alpha1.NodePoolList:
+		typed.Items = append([]hwmgmtv1alpha1.NodePool(nil), c.nodepools...)
+	case *corev1.SecretList:
+		typed.Items = nil
+	}
+	return nil
+}
+
+var _ = Describe("Allocation janitor", func() {
+	var (
+		ctx      context.Context
+		c        *janitorClient
+		a        *Adaptor
+		nodepool *hwmgmtv1alpha1.NodePool
+	)
+
+	getCloud := func(cloudID string) *cmAllocatedCloud {
+		allocations := c.getAllocations()
+		return allocations.getCloud(cloudID)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = &janitorClient{configMapClient: newConfigMapClient(2)}
+		c.setAllocations(cmAllocations{
+			SchemaVersion: allocationsSchema.Version(),
+			Clouds: []cmAllocatedCloud{
+				{CloudID: "cloud1", Nodegroups: map[string][]string{"controller": {"np1-node1"}}},
+			},
+		})
+		a = NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test")
+		nodepool = &hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "test"},
+			Spec:       hwmgmtv1alpha1.NodePoolSpec{CloudID: "cloud1"},
+		}
+	})
+
+	It("keeps the allocations of a cloud with a NodePool", func() {
+		c.nodepools = []hwmgmtv1alpha1.NodePool{*nodepool}
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		Expect(getCloud("cloud1")).ToNot(BeNil())
+		Expect(getCloud("cloud1").OrphanedSince).To(BeNil())
+	})
+
+	It("releases an orphaned cloud once the grace period has elapsed", func() {
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		cloud := getCloud("cloud1")
+		Expect(cloud).ToNot(BeNil())
+		Expect(cloud.OrphanedSince).ToNot(BeNil())
+
+		// The cloud is kept within the grace period
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		Expect(getCloud("cloud1")).ToNot(BeNil())
+
+		allocations := c.getAllocations()
+		allocations.Clouds[0].OrphanedSince = &metav1.Time{
+			Time: time.Now().Add(-utils.DefaultAllocationCleanupGracePeriod - time.Minute)}
+		c.setAllocations(allocations)
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		Expect(getCloud("cloud1")).To(BeNil())
+	})
+
+	It("clears the orphaned mark of a cloud once a NodePool is created for it", func() {
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		Expect(getCloud("cloud1").OrphanedSince).ToNot(BeNil())
+
+		c.nodepools = []hwmgmtv1alpha1.NodePool{*nodepool}
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		Expect(getCloud("cloud1")).ToNot(BeNil())
+		Expect(getCloud("cloud1").OrphanedSince).To(BeNil())
+	})
+
+	It("keeps a cloud retained under the Retain deletion policy", func() {
+		Expect(a.RetainNodePool(ctx, &pluginv1alpha1.HardwareManager{}, nodepool)).To(Succeed())
+		Expect(getCloud("cloud1").Retained).To(BeTrue())
+
+		allocations := c.getAllocations()
+		allocations.Clouds[0].OrphanedSince = &metav1.Time{
+			Time: time.Now().Add(-utils.DefaultAllocationCleanupGracePeriod - time.Minute)}
+		c.setAllocations(allocations)
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		Expect(getCloud("cloud1")).ToNot(BeNil())
+
+		// A new NodePool for the cloud adopts the allocation, which is released by the janitor once orphaned again
+		c.nodepools = []hwmgmtv1alpha1.NodePool{*nodepool}
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		cloud := getCloud("cloud1")
+		Expect(cloud.Retained).To(BeFalse())
+		Expect(cloud.OrphanedSince).To(BeNil())
+
+		c.nodepools = nil
+		Expect(a.cleanupOrphanedAllocations(ctx)).To(Succeed())
+		Expect(getCloud("cloud1").OrphanedSince).ToNot(BeNil())
+	})
+})
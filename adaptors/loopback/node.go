@@ -21,29 +21,87 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
-	"sigs.k8s.io/yaml"
 )
 
-// AllocateNode processes a NodePool CR, allocating a free node for each specified nodegroup as needed
-func (a *Adaptor) AllocateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
-	cloudID := nodepool.Spec.CloudID
+// nodeClaim is a free node claimed for a nodegroup in the allocations of the nodelist configmap, for which the Node CR
+// is yet to be created
+type nodeClaim struct {
+	nodename  string
+	nodeId    string
+	nodegroup hwmgmtv1alpha1.NodeGroup
+	info      cmNodeInfo
+	storage   *pluginv1alpha1.StorageLayout
+	adopted   bool
+}
 
-	// Inject a delay before allocating node
-	time.Sleep(10 * time.Second)
+// AllocateNode processes a NodePool CR, allocating free nodes for the specified nodegroups as needed. The nodes are
+// claimed with a single update of the nodelist configmap, which is re-read and the claims made again on a conflict with
+// a concurrent update, before the Node CRs are created.
+func (a *Adaptor) AllocateNode(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	sim := a.getSimulator(ctx, hwmgr)
+
+	// Inject a delay before allocating nodes
+	time.Sleep(sim.allocationDelay(hwmgr.Spec.LoopbackData))
+
+	var claims []nodeClaim
+	var allocErr error
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, resources cmResources, allocations *cmAllocations) (bool, error) {
+		var changed bool
+		var err error
+		claims, changed, allocErr, err = a.claimNodes(ctx, hwmgr, nodepool, sim, resources, allocations)
+		return changed, err
+	}); err != nil {
+		return err // nolint: wrapcheck
+	}
+	sdk.InvalidateInventoryCache(hwmgr.Name)
 
-	cm, resources, allocations, err := a.GetCurrentResources(ctx)
-	if err != nil {
-		return fmt.Errorf("unable to get current resources: %w", err)
+	for _, claim := range claims {
+		groupname := claim.nodegroup.NodePoolData.Name
+		hwprofile := claim.nodegroup.NodePoolData.HwProfile
+
+		if err := a.CreateBMCSecret(ctx, nodepool, claim.nodename, groupname, claim.info.BMC.UsernameBase64, claim.info.BMC.PasswordBase64); err != nil {
+			return fmt.Errorf("failed to create bmc-secret when allocating node %s, nodeId %s: %w", claim.nodename, claim.nodeId, err)
+		}
+
+		if err := a.CreateNode(ctx, nodepool, nodepool.Spec.CloudID, claim.nodename, claim.nodeId, groupname, hwprofile, claim.adopted); err != nil {
+			return fmt.Errorf("failed to create allocated node (%s): %w", claim.nodename, err)
+		}
+
+		if _, err := a.UpdateNodeStatus(ctx, hwmgr, nodepool.Namespace, claim.nodename, claim.info, hwprofile, claim.storage); err != nil {
+			return fmt.Errorf("failed to update node status (%s): %w", claim.nodename, err)
+		}
 	}
 
+	// Nodes claimed before a failure are retained, so the allocation resumes from them when retried
+	return allocErr
+}
+
+// claimNodes claims the free nodes needed to complete each nodegroup in the allocations, returning whether there are
+// claims to save. Previously claimed nodes without a Node CR are claimed again, so that an interrupted allocation is
+// resumed. An allocation failure part way through is returned as allocErr, with the nodes claimed up to that point to
+// be saved, while any other error is returned as err.
+func (a *Adaptor) claimNodes(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	sim *simulator,
+	resources cmResources,
+	allocations *cmAllocations) (claims []nodeClaim, changed bool, allocErr, err error) {
+
+	cloudID := nodepool.Spec.CloudID
+
 	var cloud *cmAllocatedCloud
 	for i, iter := range allocations.Clouds {
 		if iter.CloudID == cloudID {
@@ -57,68 +115,261 @@ func (a *Adaptor) AllocateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.Nod
 		cloud = &allocations.Clouds[len(allocations.Clouds)-1]
 	}
 
-	// Check available resources
+	namer, err := utils.NewNodeNamer(a.Client, nodepool.Namespace, hwmgr, nodepool)
+	if err != nil {
+		return nil, false, nil, fmt.Errorf("invalid node naming policy: %w", err)
+	}
+
+	// Names in the allocations may not yet have a Node CR
+	for _, iter := range allocations.Clouds {
+		for _, nodenames := range iter.Nodegroups {
+			namer.Reserve(nodenames...)
+		}
+	}
+
+	// Failure domains of the allocated nodes, keyed by nodegroup, for the spread policies
+	allocatedDomains, err := a.getAllocatedFailureDomains(ctx, nodepool, resources)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	claims, changed, err = a.resumePendingClaims(ctx, hwmgr, nodepool, resources, cloud)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	resumed := len(claims)
+
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
-		used := cloud.Nodegroups[nodegroup.NodePoolData.Name]
-		remaining := nodegroup.Size - len(used)
-		if remaining <= 0 {
-			// This group is allocated
-			a.Logger.InfoContext(ctx, "nodegroup is fully allocated", slog.String("nodegroup", nodegroup.NodePoolData.Name))
+		groupname := nodegroup.NodePoolData.Name
+
+		// A BestEffort nodegroup is only allocated the nodes available to it
+		if nodegroup.Size, allocErr = a.getNodeGroupTarget(hwmgr, nodepool, nodegroup, resources, *allocations, cloud); allocErr != nil {
+			break
+		}
+
+		var domains []utils.FailureDomain
+		for _, domain := range allocatedDomains[groupname] {
+			domains = append(domains, domain)
+		}
+		for _, claim := range claims {
+			if claim.nodegroup.NodePoolData.Name == groupname {
+				domains = append(domains, claim.info.failureDomain())
+			}
+		}
+
+		if _, registered := cloud.Nodegroups[groupname]; !registered && utils.IsNodeGroupPlaceholder(nodegroup) {
+			// A placeholder nodegroup reserves no nodes, but is registered so that the allocation is recorded even
+			// when no nodes are claimed, and the nodegroup can later be extended
+			cloud.Nodegroups[groupname] = []string{}
+			changed = true
+		}
+
+		for len(cloud.Nodegroups[groupname]) < nodegroup.Size {
+			var claim *nodeClaim
+			if claim, allocErr = a.claimNode(ctx, hwmgr, nodepool, nodegroup, sim, namer, resources, allocations, cloud,
+				domains); allocErr != nil {
+				break
+			}
+			claims = append(claims, *claim)
+			domains = append(domains, claim.info.failureDomain())
+		}
+		if allocErr != nil {
+			break
+		}
+		a.Logger.InfoContext(ctx, "nodegroup is fully allocated", slog.String("nodegroup", groupname))
+	}
+
+	return claims, changed || len(claims) > resumed, allocErr, nil
+}
+
+// resumePendingClaims returns the claims for the pending nodes of the allocation that do not yet have a Node CR,
+// dropping the pending nodes whose Node CR exists, and returns true if the pending nodes have changed
+func (a *Adaptor) resumePendingClaims(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	resources cmResources,
+	cloud *cmAllocatedCloud) (claims []nodeClaim, changed bool, err error) {
+
+	for nodename, nodeId := range cloud.Pending {
+		exists, err := utils.DoesK8SResourceExist(ctx, a.Client, nodename, nodepool.Namespace, &hwmgmtv1alpha1.Node{})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query node %s: %w", nodename, err)
+		}
+		if exists {
+			delete(cloud.Pending, nodename)
+			changed = true
 			continue
 		}
 
-		freenodes := getFreeNodesInPool(resources, allocations, nodegroup.NodePoolData.ResourcePoolId)
-		if remaining > len(freenodes) {
-			return fmt.Errorf("not enough free resources remaining in resource pool %s", nodegroup.NodePoolData.ResourcePoolId)
+		index := slices.IndexFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return slices.Contains(cloud.Nodegroups[nodegroup.NodePoolData.Name], nodename)
+		})
+		info, found := resources.Nodes[nodeId]
+		if index < 0 || !found {
+			// The claim is no longer part of the allocation
+			delete(cloud.Pending, nodename)
+			changed = true
+			continue
 		}
 
-		nodename := utils.GenerateNodeName()
+		nodegroup := nodepool.Spec.NodeGroup[index]
+		_, adopted := cloud.Adopted[nodename]
+		var storage *pluginv1alpha1.StorageLayout
+		if !adopted {
+			storage = utils.GetHwProfileStorageLayout(hwmgr, nodegroup.NodePoolData.HwProfile)
+		}
 
-		// Grab the first node
-		nodeId := freenodes[0]
+		a.Logger.InfoContext(ctx, "Resuming allocation of claimed node",
+			slog.String("nodename", nodename),
+			slog.String("nodeId", nodeId))
+		claims = append(claims, nodeClaim{
+			nodename:  nodename,
+			nodeId:    nodeId,
+			nodegroup: nodegroup,
+			info:      info,
+			storage:   storage,
+			adopted:   adopted,
+		})
+	}
 
-		nodeinfo, exists := resources.Nodes[nodeId]
-		if !exists {
-			return fmt.Errorf("unable to find nodeinfo for %s", nodename)
+	return claims, changed, nil
+}
+
+// claimNode claims a node for the nodegroup in the allocations, importing a pending adopted node before any free node
+func (a *Adaptor) claimNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodegroup hwmgmtv1alpha1.NodeGroup,
+	sim *simulator,
+	namer *utils.NodeNamer,
+	resources cmResources,
+	allocations *cmAllocations,
+	cloud *cmAllocatedCloud,
+	domains []utils.FailureDomain) (*nodeClaim, error) {
+
+	groupname := nodegroup.NodePoolData.Name
+	remaining := nodegroup.Size - len(cloud.Nodegroups[groupname])
+
+	// Nodes already allocated in the backend are imported before any free nodes are allocated
+	pending, err := a.getPendingAdoptedNodes(hwmgr, nodepool, nodegroup, resources, *allocations, cloud)
+	if err != nil {
+		return nil, err
+	}
+
+	storage := utils.GetHwProfileStorageLayout(hwmgr, nodegroup.NodePoolData.HwProfile)
+	if err := utils.ValidateStorageLayout(storage); err != nil {
+		return nil, fmt.Errorf("invalid storage layout for hardware profile %s: %w", nodegroup.NodePoolData.HwProfile, err)
+	}
+
+	var nodeId string
+	adopted := len(pending) > 0
+	if adopted {
+		nodeId = pending[0]
+		// Adopted nodes retain the storage configuration of their existing allocation
+		storage = nil
+	} else {
+		selector, err := utils.GetNodeGroupNodeSelector(nodepool, groupname)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector: %w", err)
+		}
+
+		freenodes := a.getFreeNodesInPool(hwmgr, resources, *allocations, cloud, nodegroup.NodePoolData.ResourcePoolId, selector)
+		// Nodes that cannot satisfy the hardware profile are skipped, failing early if they are needed
+		freenodes, err = utils.FilterProfileCompatibleCandidates(hwmgr, nodegroup.NodePoolData.HwProfile,
+			nodegroup.NodePoolData.ResourcePoolId, remaining, freenodes, func(nodeId string) utils.NodeCapabilities {
+				return resources.Nodes[nodeId].capabilities()
+			})
+		if err != nil {
+			return nil, err
+		}
+		if remaining > len(freenodes) {
+			return nil, fmt.Errorf("not enough free resources remaining in resource pool %s", nodegroup.NodePoolData.ResourcePoolId)
 		}
 
-		if err := a.CreateBMCSecret(ctx, nodepool, nodename, nodeinfo.BMC.UsernameBase64, nodeinfo.BMC.PasswordBase64); err != nil {
-			return fmt.Errorf("failed to create bmc-secret when allocating node %s, nodeId %s: %w", nodename, nodeId, err)
+		preference, err := utils.GetNodePoolLocalityPreference(nodepool)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locality preference: %w", err)
 		}
 
-		cloud.Nodegroups[nodegroup.NodePoolData.Name] = append(cloud.Nodegroups[nodegroup.NodePoolData.Name], nodename)
+		freenodes, err = utils.FilterLocalCandidates(preference, nodepool, freenodes, func(nodeId string) utils.NodeLocality {
+			return resources.Nodes[nodeId].locality()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to satisfy locality preference for nodegroup %s in resource pool %s: %w",
+				groupname, nodegroup.NodePoolData.ResourcePoolId, err)
+		}
 
-		// Update the configmap
-		yamlString, err := yaml.Marshal(&allocations)
+		policy, err := utils.GetNodeGroupSpreadPolicy(nodepool, groupname)
 		if err != nil {
-			return fmt.Errorf("unable to marshal allocated data: %w", err)
+			return nil, fmt.Errorf("invalid spread policy: %w", err)
 		}
-		cm.Data[allocationsKey] = string(yamlString)
-		if err := a.Client.Update(ctx, cm); err != nil {
-			return fmt.Errorf("failed to update configmap: %w", err)
+
+		freenodes, err = utils.FilterSpreadCandidates(policy, freenodes, domains, func(nodeId string) utils.FailureDomain {
+			return resources.Nodes[nodeId].failureDomain()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to satisfy spread policy for nodegroup %s in resource pool %s: %w",
+				groupname, nodegroup.NodePoolData.ResourcePoolId, err)
 		}
 
-		if err := a.CreateNode(ctx, nodepool, cloudID, nodename, nodeId, nodegroup.NodePoolData.Name, nodegroup.NodePoolData.HwProfile); err != nil {
-			return fmt.Errorf("failed to create allocated node (%s): %w", nodename, err)
+		nodeId = sim.chooseNode(hwmgr.Spec.LoopbackData, freenodes)
+		if preference != nil &&
+			resources.Nodes[nodeId].locality().Get(preference.TopologyKey) != utils.GetNodePoolLocality(nodepool, preference.TopologyKey) {
+			a.Logger.InfoContext(ctx, "No local node free, falling back to a node in another location",
+				slog.String("nodegroup", groupname),
+				slog.String("nodeId", nodeId),
+				slog.String("topologyKey", string(preference.TopologyKey)))
 		}
 
-		if err := a.UpdateNodeStatus(ctx, nodename, nodeinfo, nodegroup.NodePoolData.HwProfile); err != nil {
-			return fmt.Errorf("failed to update node status (%s): %w", nodename, err)
+		if sim.injectAllocationFailure(hwmgr.Spec.LoopbackData) {
+			return nil, fmt.Errorf("simulated allocation failure for node %s in resource pool %s",
+				nodeId, nodegroup.NodePoolData.ResourcePoolId)
 		}
 	}
 
-	return nil
-}
+	nodeinfo, exists := resources.Nodes[nodeId]
+	if !exists {
+		return nil, fmt.Errorf("unable to find nodeinfo for %s", nodeId)
+	}
+
+	nodename, err := namer.Generate(ctx, groupname, nodeId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate name for node %s: %w", nodeId, err)
+	}
 
-func bmcSecretName(nodename string) string {
-	return fmt.Sprintf("%s-bmc-secret", nodename)
+	cloud.Nodegroups[groupname] = append(cloud.Nodegroups[groupname], nodename)
+	allocations.recordAllocation(nodeId)
+	if cloud.Pending == nil {
+		cloud.Pending = make(map[string]string)
+	}
+	cloud.Pending[nodename] = nodeId
+	if adopted {
+		a.Logger.InfoContext(ctx, "Adopting node from existing backend allocation",
+			slog.String("nodename", nodename),
+			slog.String("nodeId", nodeId))
+		if cloud.Adopted == nil {
+			cloud.Adopted = make(map[string]string)
+		}
+		cloud.Adopted[nodename] = nodeId
+	}
+
+	return &nodeClaim{
+		nodename:  nodename,
+		nodeId:    nodeId,
+		nodegroup: nodegroup,
+		info:      nodeinfo,
+		storage:   storage,
+		adopted:   adopted,
+	}, nil
 }
 
 // CreateBMCSecret creates the bmc-secret for a node
-func (a *Adaptor) CreateBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, nodename, usernameBase64, passwordBase64 string) error {
+func (a *Adaptor) CreateBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, nodename, groupname, usernameBase64, passwordBase64 string) error {
 	a.Logger.InfoContext(ctx, "Creating bmc-secret:", slog.String("nodename", nodename))
 
-	secretName := bmcSecretName(nodename)
+	secretName := utils.BMCSecretName(nodename)
 
 	username, err := base64.StdEncoding.DecodeString(usernameBase64)
 	if err != nil {
@@ -134,7 +385,7 @@ func (a *Adaptor) CreateBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.
 	bmcSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secretName,
-			Namespace: a.Namespace,
+			Namespace: nodepool.Namespace,
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         nodepool.APIVersion,
 				Kind:               nodepool.Kind,
@@ -149,15 +400,24 @@ func (a *Adaptor) CreateBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.
 		},
 	}
 
-	if err = utils.CreateOrUpdateK8sCR(ctx, a.Client, bmcSecret, nil, utils.UPDATE); err != nil {
+	if err := utils.SetNodeMetadata(bmcSecret, nodepool, groupname); err != nil {
+		return fmt.Errorf("failed to set metadata for bmc-secret of node %s: %w", nodename, err)
+	}
+
+	provenance := utils.NewBMCSecretProvenance(pluginv1alpha1.SupportedAdaptors.Loopback, nodepool, utils.CredentialsFromConfigMap)
+	if err := sdk.ApplyBMCSecretProvenance(ctx, a.Client, a.Namespace, bmcSecret, provenance); err != nil {
+		return fmt.Errorf("failed to record provenance for bmc-secret of node %s: %w", nodename, err)
+	}
+
+	if err = utils.ApplyK8sCR(ctx, a.Client, bmcSecret, nil); err != nil {
 		return fmt.Errorf("failed to create bmc-secret for node %s: %w", nodename, err)
 	}
 
 	return nil
 }
 
-// CreateNode creates a Node CR with specified attributes
-func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, cloudID, nodename, nodeId, groupname, hwprofile string) error {
+// CreateNode creates a Node CR with specified attributes, marking it if adopted from an existing backend allocation
+func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, cloudID, nodename, nodeId, groupname, hwprofile string, adopted bool) error {
 	a.Logger.InfoContext(ctx, "Creating node",
 		slog.String("nodegroup name", groupname),
 		slog.String("nodename", nodename),
@@ -167,7 +427,7 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 	node := &hwmgmtv1alpha1.Node{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      nodename,
-			Namespace: a.Namespace,
+			Namespace: nodepool.Namespace,
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         nodepool.APIVersion,
 				Kind:               nodepool.Kind,
@@ -185,6 +445,23 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 		},
 	}
 
+	netconfig, err := utils.GetNodeGroupNetworkConfig(nodepool, groupname)
+	if err != nil {
+		return fmt.Errorf("failed to get network config for nodegroup %s: %w", groupname, err)
+	}
+
+	if err := utils.SetNodeNetworkConfig(node, netconfig); err != nil {
+		return fmt.Errorf("failed to set network config for node %s: %w", nodename, err)
+	}
+
+	if err := utils.SetNodeMetadata(node, nodepool, groupname); err != nil {
+		return fmt.Errorf("failed to set metadata for node %s: %w", nodename, err)
+	}
+
+	if adopted {
+		utils.SetNodeAdopted(node)
+	}
+
 	if err := a.Client.Create(ctx, node); err != nil {
 		return fmt.Errorf("failed to create Node: %w", err)
 	}
@@ -192,35 +469,293 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 	return nil
 }
 
-// UpdateNodeStatus updates a Node CR status field with additional node information from the nodelist configmap
-func (a *Adaptor) UpdateNodeStatus(ctx context.Context, nodename string, info cmNodeInfo, hwprofile string) error {
+// UpdateNodeStatus updates a Node CR status field with additional node information from the nodelist configmap,
+// applying the storage layout of the hardware profile, if any. The node is marked as provisioned once it passes the
+// readiness checks of the hardware manager, returning true if provisioned.
+func (a *Adaptor) UpdateNodeStatus(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	namespace, nodename string,
+	info cmNodeInfo,
+	hwprofile string,
+	storage *pluginv1alpha1.StorageLayout) (bool, error) {
 	a.Logger.InfoContext(ctx, "Updating node", slog.String("nodename", nodename))
 
+	// Inject a delay before updating the node status
+	time.Sleep(a.getSimulator(ctx, hwmgr).statusUpdateDelay(hwmgr.Spec.LoopbackData))
+
 	node := &hwmgmtv1alpha1.Node{}
 
 	if err := utils.RetryOnConflictOrRetriableOrNotFound(retry.DefaultRetry, func() error {
-		return a.Get(ctx, types.NamespacedName{Name: nodename, Namespace: a.Namespace}, node)
+		return a.Get(ctx, types.NamespacedName{Name: nodename, Namespace: namespace}, node)
 	}); err != nil {
-		return fmt.Errorf("failed to get Node for update: %w", err)
+		return false, fmt.Errorf("failed to get Node for update: %w", err)
+	}
+
+	if err := sdk.PublishNodeAssetInfo(ctx, a.Client, node, info.assetInfo()); err != nil {
+		return false, err
+	}
+
+	if err := sdk.PublishNodeBootCapabilities(ctx, a.Client, node, info.bootCapabilities()); err != nil {
+		return false, err
+	}
+
+	roles := utils.ResolveInterfaceRoles(info.Interfaces, info.InterfaceRoles, utils.GetHwProfileInterfaceRoles(hwmgr, hwprofile))
+	if err := sdk.PublishNodeInterfaceRoles(ctx, a.Client, node, roles); err != nil {
+		return false, err
+	}
+
+	if err := sdk.PublishNodeInstallHints(ctx, a.Client, node, info.installHints()); err != nil {
+		return false, err
+	}
+
+	if err := sdk.ApplyNodeSecrets(ctx, a.Client, hwmgr, node, info.SecretData); err != nil {
+		return false, err
+	}
+
+	bmcAddress, err := getBMCAddress(hwmgr, hwprofile, node.Spec.HwMgrNodeId, info)
+	if err != nil {
+		return false, err
 	}
 
 	a.Logger.InfoContext(ctx, "Adding info to node",
 		slog.String("nodename", nodename),
 		slog.Any("info", info))
-	node.Status.BMC = &hwmgmtv1alpha1.BMC{
-		Address:         info.BMC.Address,
-		CredentialsName: bmcSecretName(nodename),
-	}
-	node.Status.Interfaces = info.Interfaces
-
-	utils.SetStatusCondition(&node.Status.Conditions,
-		string(hwmgmtv1alpha1.Provisioned),
-		string(hwmgmtv1alpha1.Completed),
-		metav1.ConditionTrue,
-		"Provisioned")
-	node.Status.HwProfile = hwprofile
-	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node); err != nil {
-		return fmt.Errorf("failed to update status for node %s: %w", nodename, err)
+	var storageErr error
+	if storage != nil {
+		storageErr = info.applyStorageLayout(storage)
+	}
+	security := info.applySecurityRequirements(utils.GetHwProfileSecurity(hwmgr, hwprofile))
+
+	var ready bool
+	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+		node.Status.BMC = &hwmgmtv1alpha1.BMC{
+			Address:         bmcAddress,
+			CredentialsName: utils.BMCSecretName(nodename),
+		}
+		node.Status.Interfaces = info.Interfaces
+
+		node.Status.HwProfile = hwprofile
+		if storage != nil {
+			utils.SetNodeStorageCondition(node, storage, storageErr)
+		}
+		utils.SetNodePowerStatus(node, info.getPowerState(), info.getBootProgress())
+		ready = utils.VerifyNodeSecurity(hwmgr, node, security) &&
+			sdk.NewBMCProber(a.Client, hwmgr).ProbeNode(ctx, node) && sdk.NewReadinessChecker(hwmgr).MarkNodeProvisionedIfReady(node)
+	}); err != nil {
+		return false, fmt.Errorf("failed to update status for node %s: %w", nodename, err)
+	}
+
+	return ready, nil
+}
+
+// UpdatePendingNodes re-evaluates the readiness checks of the allocated nodes that are not yet provisioned, picking up
+// changes to the nodelist configmap, and returns the number of nodes still pending
+func (a *Adaptor) UpdatePendingNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (int, error) {
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	_, resources, _, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	pending := 0
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+			continue
+		}
+
+		info, exists := resources.Nodes[node.Spec.HwMgrNodeId]
+		if !exists {
+			return 0, fmt.Errorf("unable to find nodeinfo for %s", node.Spec.HwMgrNodeId)
+		}
+
+		// The storage layout was applied on allocation
+		ready, err := a.UpdateNodeStatus(ctx, hwmgr, node.Namespace, node.Name, info, node.Spec.HwProfile, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update node status (%s): %w", node.Name, err)
+		}
+		if !ready {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+// getPowerState returns the simulated power state of the node, which defaults to On
+func (info cmNodeInfo) getPowerState() utils.PowerState {
+	if info.PowerState == "" {
+		return utils.PowerStateOn
+	}
+	return utils.NormalizePowerState(info.PowerState)
+}
+
+// getBootProgress returns the simulated boot progress of the node, which defaults to OSRunning for a powered-on node
+func (info cmNodeInfo) getBootProgress() utils.BootProgress {
+	switch {
+	case info.BootProgress != "":
+		return utils.NormalizeBootProgress(info.BootProgress)
+	case info.getPowerState() == utils.PowerStateOff:
+		return utils.BootProgressNone
+	default:
+		return utils.BootProgressOSRunning
+	}
+}
+
+// RefreshNodePowerStatus updates the power state and boot progress, along with the asset details, of the allocated nodes
+// from the nodelist configmap
+func (a *Adaptor) RefreshNodePowerStatus(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	_, resources, _, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		info, exists := resources.Nodes[node.Spec.HwMgrNodeId]
+		if !exists {
+			continue
+		}
+
+		if err := sdk.PublishNodeAssetInfo(ctx, a.Client, node, info.assetInfo()); err != nil {
+			return err
+		}
+
+		if err := sdk.PublishNodeBootCapabilities(ctx, a.Client, node, info.bootCapabilities()); err != nil {
+			return err
+		}
+
+		if !utils.SetNodePowerStatus(node, info.getPowerState(), info.getBootProgress()) {
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Node power status changed",
+			slog.String("nodename", node.Name),
+			slog.String("powerState", string(info.getPowerState())),
+			slog.String("bootProgress", string(info.getBootProgress())))
+		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+			utils.SetNodePowerStatus(node, info.getPowerState(), info.getBootProgress())
+		}); err != nil {
+			return fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ResyncNodeHardware refreshes the interfaces, along with their roles and the install hints, and BMC address of the
+// allocated nodes from the nodelist configmap, then checks the allocation of the NodePool for drift
+func (a *Adaptor) ResyncNodeHardware(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	queue := sdk.GetNodeStatusQueue(hwmgr.Name, hwmgr.Spec.MaxNodeStatusUpdatesPerSecond)
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		info, exists := resources.Nodes[node.Spec.HwMgrNodeId]
+		if !exists || info.BMC == nil {
+			continue
+		}
+
+		roles := utils.ResolveInterfaceRoles(info.Interfaces, info.InterfaceRoles, utils.GetHwProfileInterfaceRoles(hwmgr, node.Spec.HwProfile))
+		if err := sdk.PublishNodeInterfaceRoles(ctx, a.Client, node, roles); err != nil {
+			return err
+		}
+
+		if err := sdk.PublishNodeInstallHints(ctx, a.Client, node, info.installHints()); err != nil {
+			return err
+		}
+
+		if err := sdk.ApplyNodeSecrets(ctx, a.Client, hwmgr, node, info.SecretData); err != nil {
+			return err
+		}
+
+		bmcAddress, err := getBMCAddress(hwmgr, node.Spec.HwProfile, node.Spec.HwMgrNodeId, info)
+		if err != nil {
+			a.Logger.InfoContext(ctx, "Skipping BMC address resync",
+				slog.String("nodename", node.Name),
+				slog.String("error", err.Error()))
+			if node.Status.BMC != nil {
+				bmcAddress = node.Status.BMC.Address
+			}
+		}
+
+		if !utils.ApplyNodeHardwareResync(node, info.Interfaces, bmcAddress) {
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Node hardware changed", slog.String("nodename", node.Name))
+		queue.Enqueue(node, func(latest *hwmgmtv1alpha1.Node) bool {
+			return utils.ApplyNodeHardwareResync(latest, info.Interfaces, bmcAddress)
+		})
+	}
+
+	// The status writes are spread out at the rate limit of the hardware manager, across the NodePools being resynced
+	if err := queue.Flush(ctx, a.Client); err != nil {
+		return fmt.Errorf("failed to update node status: %w", err)
+	}
+
+	return a.checkNodePoolDrift(ctx, nodepool, nodelist, resources, allocations)
+}
+
+// checkNodePoolDrift compares the allocation of the NodePool in the nodelist configmap against its requested
+// configuration. The hardware profile applied to each node is taken from its Node status.
+func (a *Adaptor) checkNodePoolDrift(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodelist *hwmgmtv1alpha1.NodeList,
+	resources cmResources,
+	allocations cmAllocations) error {
+
+	nodes := make(map[string]*hwmgmtv1alpha1.Node)
+	for i := range nodelist.Items {
+		nodes[nodelist.Items[i].Name] = &nodelist.Items[i]
+	}
+
+	var backend []utils.BackendNodeState
+	for _, cloud := range allocations.Clouds {
+		if cloud.CloudID != nodepool.Spec.CloudID {
+			continue
+		}
+		for groupname, nodenames := range cloud.Nodegroups {
+			for _, nodename := range nodenames {
+				state := utils.BackendNodeState{NodeName: nodename, GroupName: groupname}
+				if node, exists := nodes[nodename]; exists {
+					state.HwProfile = node.Status.HwProfile
+					state.ResourcePoolId = resources.Nodes[node.Spec.HwMgrNodeId].ResourcePoolID
+				}
+				backend = append(backend, state)
+			}
+		}
+	}
+
+	drift, err := utils.DetectNodePoolDrift(nodepool, nodelist, backend)
+	if err != nil {
+		return fmt.Errorf("failed to check drift for NodePool %s: %w", nodepool.Name, err)
+	}
+	if len(drift) > 0 {
+		a.Logger.InfoContext(ctx, "NodePool allocation has drifted", slog.Any("drift", drift))
+	}
+
+	if err := utils.UpdateNodePoolDriftCondition(ctx, a.Client, nodepool, drift); err != nil {
+		return fmt.Errorf("failed to update drift status for NodePool %s: %w", nodepool.Name, err)
 	}
 
 	return nil
@@ -23,13 +23,12 @@ import (
 	"slices"
 	"time"
 
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
 )
 
 // CheckNodePoolProgress checks to see if a NodePool is fully allocated, allocating additional resources as needed
@@ -48,16 +47,13 @@ func (a *Adaptor) CheckNodePoolProgress(
 		return
 	}
 
-	for _, nodegroup := range nodepool.Spec.NodeGroup {
-		a.Logger.InfoContext(ctx, "Allocating node for CheckNodePoolProgress request:",
-			slog.String("cloudID", cloudID),
-			slog.String("nodegroup name", nodegroup.NodePoolData.Name),
-		)
-
-		if err = a.AllocateNode(ctx, nodepool); err != nil {
-			err = fmt.Errorf("failed to allocate node: %w", err)
-			return
-		}
+	a.Logger.InfoContext(ctx, "Allocating nodes for CheckNodePoolProgress request:",
+		slog.String("cloudID", cloudID),
+	)
+
+	if err = a.AllocateNode(ctx, hwmgr, nodepool); err != nil {
+		err = fmt.Errorf("failed to allocate node: %w", err)
+		return
 	}
 
 	return
@@ -68,31 +64,25 @@ func (a *Adaptor) HandleNodePoolCreate(
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
-	conditionType := hwmgmtv1alpha1.Provisioned
 	var conditionReason hwmgmtv1alpha1.ConditionReason
-	var conditionStatus metav1.ConditionStatus
 	var message string
 
 	if err := a.ProcessNewNodePool(ctx, hwmgr, nodepool); err != nil {
-		a.Logger.Error("failed createNodePool", "err", err)
+		a.Logger.ErrorContext(ctx, "failed createNodePool", slog.String("error", err.Error()))
 		conditionReason = hwmgmtv1alpha1.Failed
-		conditionStatus = metav1.ConditionFalse
 		message = "Creation request failed: " + err.Error()
 	} else {
 		conditionReason = hwmgmtv1alpha1.InProgress
-		conditionStatus = metav1.ConditionFalse
 		message = "Handling creation"
 	}
 
-	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-		conditionType, conditionReason, conditionStatus, message); err != nil {
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithProvisioned(conditionReason, message).
+		WithObservedGeneration().
+		Update(ctx, a.Client); err != nil {
 		return utils.RequeueWithMediumInterval(),
 			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
-	// Update the Node Pool hwMgrPlugin status
-	if err := utils.UpdateNodePoolPluginStatus(ctx, a.Client, nodepool); err != nil {
-		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update hwMgrPlugin observedGeneration Status: %w", err)
-	}
 
 	return utils.DoNotRequeue(), nil
 }
@@ -102,9 +92,15 @@ func (a *Adaptor) HandleNodePoolProcessing(
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
+	if result, wait := sdk.WaitForAllocationRetry(nodepool); wait {
+		a.Logger.InfoContext(ctx, "Waiting to retry NodePool allocation")
+		return result, nil
+	}
+
 	full, err := a.CheckNodePoolProgress(ctx, hwmgr, nodepool)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed CheckNodePoolProgress: %w", err)
+		a.Logger.InfoContext(ctx, "NodePool allocation failed", slog.String("error", err.Error()))
+		return sdk.RetryNodePoolAllocation(ctx, a.Client, hwmgr, nodepool, fmt.Errorf("failed CheckNodePoolProgress: %w", err))
 	}
 
 	allocatedNodes, err := a.GetAllocatedNodes(ctx, nodepool)
@@ -120,136 +116,120 @@ func (a *Adaptor) HandleNodePoolProcessing(
 
 	var result ctrl.Result
 
+	pending := 0
 	if full {
-		a.Logger.InfoContext(ctx, "NodePool request is fully allocated")
+		if pending, err = a.UpdatePendingNodes(ctx, hwmgr, nodepool); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update pending nodes for %s: %w", nodepool.Name, err)
+		}
+	}
+
+	if full && pending > 0 {
+		a.Logger.InfoContext(ctx, "NodePool is waiting for node readiness checks", slog.Int("pendingNodes", pending))
 
-		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Completed, metav1.ConditionTrue, "Created"); err != nil {
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.InProgress, fmt.Sprintf("Waiting for %d nodes to pass readiness checks", pending)).
+			Update(ctx, a.Client); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 		}
 
-		result = utils.DoNotRequeue()
-	} else {
-		a.Logger.InfoContext(ctx, "NodePool request in progress")
 		result = utils.RequeueWithShortInterval()
-	}
+	} else if full {
+		a.Logger.InfoContext(ctx, "NodePool request is fully allocated")
 
-	return result, nil
-}
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Completed, "Created").
+			Update(ctx, a.Client); err != nil {
+			return utils.RequeueWithMediumInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
 
-func (a *Adaptor) checkNodeUpgradeProcess(
-	ctx context.Context,
-	allocatedNodes []string) ([]*hwmgmtv1alpha1.Node, []*hwmgmtv1alpha1.Node, error) {
+		if err := sdk.ResetAllocationRetries(ctx, a.Client, nodepool); err != nil {
+			a.Logger.InfoContext(ctx, "Failed to reset allocation retries", slog.String("error", err.Error()))
+		}
 
-	var upgradedNodes []*hwmgmtv1alpha1.Node
-	var nodesStillUpgrading []*hwmgmtv1alpha1.Node
+		if err := a.reportNodePoolFulfillment(ctx, nodepool); err != nil {
+			a.Logger.InfoContext(ctx, "Failed to report nodepool fulfillment", slog.String("error", err.Error()))
+		}
 
-	for _, name := range allocatedNodes {
-		// Fetch the latest version of each node to ensure up-to-date status
-		updatedNode, err := utils.GetNode(ctx, a.Logger, a.Client, a.Namespace, name)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to get node %s: %w", name, err)
+		if err := a.CheckNodePoolPlacement(ctx, nodepool); err != nil {
+			a.Logger.InfoContext(ctx, "Failed to check node placement", slog.String("error", err.Error()))
 		}
 
-		if updatedNode.Status.HwProfile == updatedNode.Spec.HwProfile {
-			// Node has completed the upgrade
-			upgradedNodes = append(upgradedNodes, updatedNode)
-		} else {
-			updatedNode.Status.HwProfile = updatedNode.Spec.HwProfile
-			if err := utils.UpdateK8sCRStatus(ctx, a.Client, updatedNode); err != nil {
-				return nil, nil, fmt.Errorf("failed to update status for node %s: %w", updatedNode.Name, err)
-			}
-			nodesStillUpgrading = append(nodesStillUpgrading, updatedNode)
+		// Pre-allocate the spares, which do not hold up the provisioning of the nodepool
+		if err := a.ReconcileSpares(ctx, hwmgr, nodepool); err != nil {
+			a.Logger.InfoContext(ctx, "Failed to reconcile spare nodes", slog.String("error", err.Error()))
 		}
+
+		result = utils.DoNotRequeue()
+	} else {
+		a.Logger.InfoContext(ctx, "NodePool request in progress")
+		result = utils.RequeueWithShortInterval()
 	}
 
-	return upgradedNodes, nodesStillUpgrading, nil
+	return result, nil
 }
 
-func (a *Adaptor) handleNodePoolConfiguring(
+// HandleNodePoolExtend allocates the nodes added to the nodegroups of a provisioned NodePool. The existing nodes are
+// left untouched, and the nodes added are reported in the Extended condition once they are ready.
+func (a *Adaptor) HandleNodePoolExtend(
 	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
-	var nodesToCheck []*hwmgmtv1alpha1.Node // To track nodes that we actually attempted to upgrade
-	var result ctrl.Result
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
 
-	a.Logger.InfoContext(ctx, "Handling Node Pool Configuring")
+	extension, err := sdk.BeginNodePoolExtension(ctx, a.Client, nodepool, nodelist)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+	a.Logger.InfoContext(ctx, "Extending NodePool", slog.String("nodes", utils.FormatNodePoolExtension(extension)))
+
+	full, err := a.CheckNodePoolProgress(ctx, hwmgr, nodepool)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "NodePool extension failed", slog.String("error", err.Error()))
+		return sdk.FailNodePoolExtension(ctx, a.Client, nodepool, "Extension failed: "+err.Error())
+	}
 
 	allocatedNodes, err := a.GetAllocatedNodes(ctx, nodepool)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to get allocated nodes for %s: %w", nodepool.Name, err)
 	}
+	nodepool.Status.Properties.NodeNames = allocatedNodes
 
-	// Stage 1: Initiate upgrades by updating node.Spec.HwProfile as necessary
-	for _, name := range allocatedNodes {
-		node, err := utils.GetNode(ctx, a.Logger, a.Client, a.Namespace, name)
-		if err != nil {
-			return utils.RequeueWithShortInterval(), err
-		}
-		// Check each node against each nodegroup in the node pool spec
-		for _, nodegroup := range nodepool.Spec.NodeGroup {
-			if node.Spec.GroupName != nodegroup.NodePoolData.Name || node.Spec.HwProfile == nodegroup.NodePoolData.HwProfile {
-				continue
-			}
-			// Node needs an upgrade, so update Spec.HwProfile
-			patch := client.MergeFrom(node.DeepCopy())
-			node.Spec.HwProfile = nodegroup.NodePoolData.HwProfile
-			if err = a.Client.Patch(ctx, node, patch); err != nil {
-				return utils.RequeueWithShortInterval(), fmt.Errorf("failed to patch Node %s in namespace %s: %w", node.Name, node.Namespace, err)
-			}
-			nodesToCheck = append(nodesToCheck, node) // Track nodes we attempted to upgrade
-			break
-		}
+	if err := utils.UpdateNodePoolProperties(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
 
-	// Requeue if there are nodes to check
-	if len(nodesToCheck) > 0 {
-		return utils.RequeueWithCustomInterval(30 * time.Second), nil
+	if !full {
+		a.Logger.InfoContext(ctx, "NodePool extension in progress")
+		return utils.RequeueWithShortInterval(), nil
 	}
 
-	// Stage 2: Verify and track completion of upgrades
-	_, nodesStillUpgrading, err := a.checkNodeUpgradeProcess(ctx, allocatedNodes)
+	pending, err := a.UpdatePendingNodes(ctx, hwmgr, nodepool)
 	if err != nil {
-		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to check upgrade status for nodes: %w", err)
+		return ctrl.Result{}, fmt.Errorf("failed to update pending nodes for %s: %w", nodepool.Name, err)
 	}
-
-	// Update NodePool status if all nodes are upgraded
-	if len(nodesStillUpgrading) == 0 {
-		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
-			hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.ConfigApplied, metav1.ConditionTrue, string(hwmgmtv1alpha1.ConfigSuccess)); err != nil {
-			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
-		}
-		// Update the Node Pool hwMgrPlugin status
-		if err = utils.UpdateNodePoolPluginStatus(ctx, a.Client, nodepool); err != nil {
-			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update hwMgrPlugin observedGeneration Status: %w", err)
-		}
-	} else {
-		// Requeue if there are still nodes upgrading
-		return utils.RequeueWithMediumInterval(), nil
+	if pending > 0 {
+		a.Logger.InfoContext(ctx, "NodePool extension is waiting for node readiness checks", slog.Int("pendingNodes", pending))
+		return utils.RequeueWithShortInterval(), nil
 	}
 
-	return result, nil
-}
-
-func (a *Adaptor) HandleNodePoolSpecChanged(
-	ctx context.Context,
-	hwmgr *pluginv1alpha1.HardwareManager,
-	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	a.Logger.InfoContext(ctx, "NodePool extension is complete")
+	if err := sdk.CompleteNodePoolExtension(ctx, a.Client, nodepool, nodelist); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
 
-	if err := utils.UpdateNodePoolStatusCondition(
-		ctx,
-		a.Client,
-		nodepool,
-		hwmgmtv1alpha1.Configured,
-		hwmgmtv1alpha1.ConfigUpdate,
-		metav1.ConditionFalse,
-		string(hwmgmtv1alpha1.AwaitConfig)); err != nil {
-		return utils.RequeueWithMediumInterval(),
-			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	if err := a.reportNodePoolFulfillment(ctx, nodepool); err != nil {
+		a.Logger.InfoContext(ctx, "Failed to report nodepool fulfillment", slog.String("error", err.Error()))
 	}
 
-	return a.handleNodePoolConfiguring(ctx, nodepool)
+	return utils.DoNotRequeue(), nil
 }
 
 // ProcessNewNodePool processes a new NodePool CR, verifying that there are enough free resources to satisfy the request
@@ -263,14 +243,53 @@ func (a *Adaptor) ProcessNewNodePool(ctx context.Context,
 		slog.String("cloudID", cloudID),
 	)
 
+	if err := utils.ValidateNodePoolNodeGroupSizes(nodepool); err != nil {
+		return fmt.Errorf("invalid nodegroup size: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNetworkConfig(nodepool); err != nil {
+		return fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	if err := utils.ValidateNodeNameTemplate(utils.GetNodeNameTemplate(hwmgr, nodepool)); err != nil {
+		return fmt.Errorf("invalid node naming policy: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolSpareConfig(nodepool); err != nil {
+		return fmt.Errorf("invalid spare node configuration: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNodeSelectors(nodepool); err != nil {
+		return fmt.Errorf("invalid node selector: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolAdoptedNodes(nodepool); err != nil {
+		return fmt.Errorf("invalid adopted nodes: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolFulfillmentPolicies(nodepool); err != nil {
+		return fmt.Errorf("invalid fulfillment policy: %w", err)
+	}
+
 	_, resources, allocations, err := a.GetCurrentResources(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to get current resources: %w", err)
 	}
 
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
-		freenodes := getFreeNodesInPool(resources, allocations, nodegroup.NodePoolData.ResourcePoolId)
-		if nodegroup.Size > len(freenodes) {
+		// Verify that the adopted nodes are available. They are not subject to the node selector.
+		if _, err := a.getPendingAdoptedNodes(hwmgr, nodepool, nodegroup, resources, allocations, nil); err != nil {
+			return err
+		}
+
+		selector, err := utils.GetNodeGroupNodeSelector(nodepool, nodegroup.NodePoolData.Name)
+		if err != nil {
+			return fmt.Errorf("invalid node selector: %w", err)
+		}
+
+		freenodes := a.getFreeNodesInPool(hwmgr, resources, allocations, nil, nodegroup.NodePoolData.ResourcePoolId, selector)
+		// A BestEffort nodegroup is allocated whatever is available
+		if nodegroup.Size > len(freenodes) && !utils.IsNodeGroupBestEffort(nodepool, nodegroup.NodePoolData.Name) {
 			return fmt.Errorf("not enough free resources in resource pool %s: freenodes=%d", nodegroup.NodePoolData.ResourcePoolId, len(freenodes))
 		}
 	}
@@ -304,15 +323,25 @@ func (a *Adaptor) IsNodePoolFullyAllocated(ctx context.Context,
 
 	// Check allocated resources
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		target, err := a.getNodeGroupTarget(hwmgr, nodepool, nodegroup, resources, allocations, cloud)
+		if err != nil {
+			return false, err
+		}
+
 		used := cloud.Nodegroups[nodegroup.NodePoolData.Name]
-		remaining := nodegroup.Size - len(used)
+		remaining := target - len(used)
 		if remaining <= 0 {
 			// This group is allocated
 			a.Logger.InfoContext(ctx, "nodegroup is fully allocated", slog.String("nodegroup", nodegroup.NodePoolData.Name))
 			continue
 		}
 
-		freenodes := getFreeNodesInPool(resources, allocations, nodegroup.NodePoolData.ResourcePoolId)
+		selector, err := utils.GetNodeGroupNodeSelector(nodepool, nodegroup.NodePoolData.Name)
+		if err != nil {
+			return false, fmt.Errorf("invalid node selector: %w", err)
+		}
+
+		freenodes := a.getFreeNodesInPool(hwmgr, resources, allocations, nil, nodegroup.NodePoolData.ResourcePoolId, selector)
 		if remaining > len(freenodes) {
 			return false, fmt.Errorf("not enough free resources remaining in resource pool %s", nodegroup.NodePoolData.ResourcePoolId)
 		}
@@ -324,7 +353,8 @@ func (a *Adaptor) IsNodePoolFullyAllocated(ctx context.Context,
 	return true, nil
 }
 
-// ReleaseNodePool frees resources allocated to a NodePool
+// ReleaseNodePool frees resources allocated to a NodePool. The nodelist configmap is re-read and the update retried
+// on a conflict, such as with the release of other NodePools when a cloud is torn down.
 func (a *Adaptor) ReleaseNodePool(ctx context.Context,
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) error {
@@ -335,34 +365,47 @@ func (a *Adaptor) ReleaseNodePool(ctx context.Context,
 		slog.String("cloudID", cloudID),
 	)
 
-	cm, _, allocations, err := a.GetCurrentResources(ctx)
-	if err != nil {
-		return fmt.Errorf("unable to get current resources: %w", err)
-	}
+	// Inject a delay before releasing nodes
+	time.Sleep(a.getSimulator(ctx, hwmgr).releaseDelay(hwmgr.Spec.LoopbackData))
 
-	index := -1
-	for i, cloud := range allocations.Clouds {
-		if cloud.CloudID == cloudID {
-			index = i
-			break
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, _ cmResources, allocations *cmAllocations) (bool, error) {
+		index := -1
+		for i, cloud := range allocations.Clouds {
+			if cloud.CloudID == cloudID {
+				index = i
+				break
+			}
+		}
+
+		if index == -1 {
+			a.Logger.InfoContext(ctx, "no allocated nodes found", slog.String("cloudID", cloudID))
+			return false, nil
 		}
-	}
 
-	if index == -1 {
-		a.Logger.InfoContext(ctx, "no allocated nodes found", slog.String("cloudID", cloudID))
-		return nil
+		allocations.Clouds = slices.Delete[[]cmAllocatedCloud](allocations.Clouds, index, index+1)
+		return true, nil
+	}); err != nil {
+		return fmt.Errorf("failed to release allocations for cloud %s: %w", cloudID, err)
 	}
+	sdk.InvalidateInventoryCache(hwmgr.Name)
 
-	allocations.Clouds = slices.Delete[[]cmAllocatedCloud](allocations.Clouds, index, index+1)
+	return nil
+}
 
-	// Update the configmap
-	yamlString, err := yaml.Marshal(&allocations)
-	if err != nil {
-		return fmt.Errorf("unable to marshal allocated data: %w", err)
-	}
-	cm.Data[allocationsKey] = string(yamlString)
-	if err := a.Client.Update(ctx, cm); err != nil {
-		return fmt.Errorf("failed to update configmap: %w", err)
+// RetainNodePool marks the allocation of the cloud of a NodePool deleted under the Retain deletion policy as retained,
+// so that it is not released by the allocation janitor
+func (a *Adaptor) RetainNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	cloudID := nodepool.Spec.CloudID
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, _ cmResources, allocations *cmAllocations) (bool, error) {
+		cloud := allocations.getCloud(cloudID)
+		if cloud == nil || cloud.Retained {
+			return false, nil
+		}
+		cloud.Retained = true
+		cloud.OrphanedSince = nil
+		return true, nil
+	}); err != nil {
+		return fmt.Errorf("failed to retain allocations for cloud %s: %w", cloudID, err)
 	}
 
 	return nil
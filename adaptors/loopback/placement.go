@@ -0,0 +1,94 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// getAllocatedFailureDomains returns the failure domains of the nodes allocated to each nodegroup with a spread
+// policy, keyed by nodegroup and node name. Nil is returned if the NodePool has no spread policies.
+func (a *Adaptor) getAllocatedFailureDomains(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	resources cmResources) (map[string]map[string]utils.FailureDomain, error) {
+
+	policies, err := utils.GetNodePoolSpreadPolicies(nodepool)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spread policy: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	domains := make(map[string]map[string]utils.FailureDomain)
+	for _, node := range nodelist.Items {
+		if _, exists := policies[node.Spec.GroupName]; !exists {
+			continue
+		}
+		if domains[node.Spec.GroupName] == nil {
+			domains[node.Spec.GroupName] = make(map[string]utils.FailureDomain)
+		}
+		domains[node.Spec.GroupName][node.Name] = resources.Nodes[node.Spec.HwMgrNodeId].failureDomain()
+	}
+
+	return domains, nil
+}
+
+// CheckNodePoolPlacement reports, in the PlacementWarning condition of the NodePool, any nodes of a nodegroup with a
+// spread policy that share a failure domain. The condition is not set for a NodePool without spread policies.
+func (a *Adaptor) CheckNodePoolPlacement(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	policies, err := utils.GetNodePoolSpreadPolicies(nodepool)
+	if err != nil {
+		return fmt.Errorf("invalid spread policy: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	_, resources, _, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	domains, err := a.getAllocatedFailureDomains(ctx, nodepool, resources)
+	if err != nil {
+		return err
+	}
+
+	var violations []string
+	for groupname, policy := range policies {
+		violations = append(violations, utils.FindSpreadViolations(&policy, groupname, domains[groupname])...)
+	}
+	slices.Sort(violations)
+
+	if err := utils.UpdateNodePoolPlacementCondition(ctx, a.Client, nodepool, violations); err != nil {
+		return fmt.Errorf("failed to update placement status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
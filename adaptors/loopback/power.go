@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// SetNodePowerState simulates the power control of a node, updating its power state in the nodelist configmap as for
+// a reset action of the emulated BMC. The new power state is reported in the Node status straight away, as the
+// simulated node changes state immediately.
+func (a *Adaptor) SetNodePowerState(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	state utils.PowerState) error {
+
+	resetType := "On"
+	if state == utils.PowerStateOff {
+		resetType = "GracefulShutdown"
+	}
+
+	a.Logger.InfoContext(ctx, "Setting node power state",
+		slog.String("nodeId", node.Spec.HwMgrNodeId),
+		slog.String("powerState", string(state)))
+	if err := a.setEmulatedPowerState(ctx, node.Spec.HwMgrNodeId, resetType); err != nil {
+		return err
+	}
+
+	info := cmNodeInfo{PowerState: string(state)}
+	if !utils.SetNodePowerStatus(node, info.getPowerState(), info.getBootProgress()) {
+		return nil
+	}
+	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+		utils.SetNodePowerStatus(node, info.getPowerState(), info.getBootProgress())
+	}); err != nil {
+		return fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+	}
+
+	return nil
+}
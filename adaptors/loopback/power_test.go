@@ -0,0 +1,39 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+var _ = Describe("Node power status", func() {
+	DescribeTable("reports the boot progress of the node",
+		func(info cmNodeInfo, expected utils.BootProgress) {
+			Expect(info.getBootProgress()).To(Equal(expected))
+		},
+		Entry("defaults to OSRunning for a powered-on node", cmNodeInfo{}, utils.BootProgressOSRunning),
+		Entry("defaults to None for a powered-off node", cmNodeInfo{PowerState: "Off"}, utils.BootProgressNone),
+		Entry("reports the simulated boot progress", cmNodeInfo{BootProgress: "Booting"}, utils.BootProgressBooting),
+		Entry("ignores the case of the simulated boot progress", cmNodeInfo{BootProgress: "osrunning"},
+			utils.BootProgressOSRunning),
+		Entry("reports an unrecognized boot progress as Unknown", cmNodeInfo{BootProgress: "POST"},
+			utils.BootProgressUnknown),
+	)
+})
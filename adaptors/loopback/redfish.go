@@ -0,0 +1,560 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+const (
+	redfishRootPath     = "/redfish/v1/"
+	redfishSystemsPath  = "/redfish/v1/Systems"
+	redfishSessionsPath = "/redfish/v1/SessionService/Sessions"
+
+	emulatedBMCShutdownTimeout = 10 * time.Second
+	emulatedBMCReadTimeout     = 5 * time.Second
+)
+
+// getBMCAddress returns the BMC address to publish for a node, which is the emulated Redfish endpoint of the node
+// if an emulated BMC is configured for the hardware manager, and the address from the nodelist configmap, of the
+// address family selected by the hardware manager, otherwise. The address is converted to the BMC address format of
+// the hardware profile, if any.
+func getBMCAddress(hwmgr *pluginv1alpha1.HardwareManager, hwprofile, nodeId string, info cmNodeInfo) (string, error) {
+	if hwmgr != nil && hwmgr.Spec.LoopbackData != nil && hwmgr.Spec.LoopbackData.EmulatedBMC != nil {
+		baseURL := strings.TrimSuffix(hwmgr.Spec.LoopbackData.EmulatedBMC.BaseURL, "/")
+		address, err := utils.ConvertBMCAddress("redfish+"+baseURL+redfishSystemsPath+"/"+nodeId,
+			utils.GetHwProfileBMCAddressFormat(hwmgr, hwprofile))
+		if err != nil {
+			return "", fmt.Errorf("invalid emulated BMC address for node %s: %w", nodeId, err)
+		}
+		return address, nil
+	}
+	if info.BMC == nil {
+		return "", nil
+	}
+
+	address, err := utils.FormatBMCAddress(hwmgr, hwprofile, info.BMC.Address, info.BMC.AddressIPv6)
+	if err != nil {
+		return "", fmt.Errorf("invalid BMC address for node %s: %w", nodeId, err)
+	}
+	return address, nil
+}
+
+// emulatedBMCCredentials are the credentials presented to the emulated BMC
+type emulatedBMCCredentials struct {
+	username string
+	password string
+}
+
+// emulatedBootOverride is the boot source override of an emulated system, which is held in memory only
+type emulatedBootOverride struct {
+	BootSourceOverrideEnabled string `json:"BootSourceOverrideEnabled,omitempty"`
+	BootSourceOverrideTarget  string `json:"BootSourceOverrideTarget,omitempty"`
+	BootSourceOverrideMode    string `json:"BootSourceOverrideMode,omitempty"`
+}
+
+// emulatedBMC serves emulated Redfish BMC endpoints for the nodes allocated by loopback hardware managers with an
+// emulated BMC configured. Each node is exposed as a ComputerSystem, identified by its node ID, and is accessible with
+// the credentials in its bmc-secret. Power actions update the simulated power state in the nodelist configmap.
+type emulatedBMC struct {
+	adaptor *Adaptor
+
+	mu                 sync.Mutex
+	sessions           map[string]emulatedBMCCredentials
+	boot               map[string]emulatedBootOverride
+	subscriptions      map[string]emulatedSubscription
+	nextSubscriptionId int
+	nextEventId        int
+}
+
+// setupEmulatedBMC adds the emulated BMC server to the manager, if enabled
+func (a *Adaptor) setupEmulatedBMC(mgr manager.Manager) error {
+	if a.EmulatedBMCAddr == "" || a.EmulatedBMCAddr == "0" {
+		return nil
+	}
+
+	bmc := &emulatedBMC{
+		adaptor:       a,
+		sessions:      make(map[string]emulatedBMCCredentials),
+		boot:          make(map[string]emulatedBootOverride),
+		subscriptions: make(map[string]emulatedSubscription),
+	}
+
+	if err := mgr.Add(manager.RunnableFunc(bmc.run)); err != nil {
+		return fmt.Errorf("failed to add emulated BMC server: %w", err)
+	}
+
+	return nil
+}
+
+// run serves the emulated BMC endpoints until the context is canceled
+func (b *emulatedBMC) run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              b.adaptor.EmulatedBMCAddr,
+		Handler:           b.handler(),
+		ReadHeaderTimeout: emulatedBMCReadTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), emulatedBMCShutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	b.adaptor.Logger.Info("Starting emulated BMC server", slog.String("address", srv.Addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("emulated BMC server failed: %w", err)
+	}
+
+	return nil
+}
+
+// handler returns the HTTP handler for the emulated Redfish API
+func (b *emulatedBMC) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+redfishRootPath+"{$}", b.getServiceRoot)
+	mux.HandleFunc("POST "+redfishSessionsPath, b.createSession)
+	mux.HandleFunc("DELETE "+redfishSessionsPath+"/{token}", b.deleteSession)
+	mux.HandleFunc("GET "+redfishSystemsPath, b.listSystems)
+	mux.HandleFunc("GET "+redfishSystemsPath+"/{nodeId}", b.getSystem)
+	mux.HandleFunc("PATCH "+redfishSystemsPath+"/{nodeId}", b.patchSystem)
+	mux.HandleFunc("POST "+redfishSystemsPath+"/{nodeId}/Actions/ComputerSystem.Reset", b.resetSystem)
+	mux.HandleFunc("GET "+redfishEventServicePath, b.getEventService)
+	mux.HandleFunc("POST "+redfishSubscriptionsPath, b.createSubscription)
+	mux.HandleFunc("DELETE "+redfishSubscriptionsPath+"/{id}", b.deleteSubscription)
+	mux.HandleFunc("POST "+redfishEventServicePath+"/Actions/EventService.SubmitTestEvent", b.submitTestEvent)
+	return mux
+}
+
+// writeRedfishJSON writes a Redfish response body
+func writeRedfishJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("OData-Version", "4.0")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeRedfishError writes a Redfish error response
+func writeRedfishError(w http.ResponseWriter, status int, message string) {
+	writeRedfishJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"code":    "Base.1.0.GeneralError",
+			"message": message,
+		},
+	})
+}
+
+func (b *emulatedBMC) getServiceRoot(w http.ResponseWriter, r *http.Request) {
+	writeRedfishJSON(w, http.StatusOK, map[string]any{
+		"@odata.id":      redfishRootPath,
+		"Id":             "RootService",
+		"Name":           "Loopback Emulated Redfish Service",
+		"RedfishVersion": "1.6.0",
+		"Systems":        map[string]string{"@odata.id": redfishSystemsPath},
+		"SessionService": map[string]string{"@odata.id": "/redfish/v1/SessionService"},
+		"EventService":   map[string]string{"@odata.id": redfishEventServicePath},
+		"Links": map[string]any{
+			"Sessions": map[string]string{"@odata.id": redfishSessionsPath},
+		},
+	})
+}
+
+// createSession creates a session for credentials that are valid for at least one emulated system
+func (b *emulatedBMC) createSession(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		UserName string
+		Password string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeRedfishError(w, http.StatusBadRequest, "invalid session request")
+		return
+	}
+	creds := emulatedBMCCredentials{username: request.UserName, password: request.Password}
+
+	nodes, err := b.getAccessibleNodes(r.Context(), creds)
+	if err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(nodes) == 0 {
+		writeRedfishError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, "failed to generate session token")
+		return
+	}
+	token := hex.EncodeToString(buf)
+
+	b.mu.Lock()
+	b.sessions[token] = creds
+	b.mu.Unlock()
+
+	location := redfishSessionsPath + "/" + token
+	w.Header().Set("X-Auth-Token", token)
+	w.Header().Set("Location", location)
+	writeRedfishJSON(w, http.StatusCreated, map[string]string{
+		"@odata.id": location,
+		"Id":        token,
+		"UserName":  request.UserName,
+	})
+}
+
+func (b *emulatedBMC) deleteSession(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	b.mu.Lock()
+	_, exists := b.sessions[token]
+	delete(b.sessions, token)
+	b.mu.Unlock()
+
+	if !exists {
+		writeRedfishError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getCredentials returns the credentials of the request, from either its session token or basic auth
+func (b *emulatedBMC) getCredentials(r *http.Request) (emulatedBMCCredentials, bool) {
+	if token := r.Header.Get("X-Auth-Token"); token != "" {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		creds, exists := b.sessions[token]
+		return creds, exists
+	}
+
+	username, password, ok := r.BasicAuth()
+	return emulatedBMCCredentials{username: username, password: password}, ok
+}
+
+// isEmulatedNode returns true if the node is allocated by a loopback hardware manager with an emulated BMC configured
+func (b *emulatedBMC) isEmulatedNode(ctx context.Context, node *hwmgmtv1alpha1.Node) (bool, error) {
+	if node.Spec.HwMgrNodeId == "" || !node.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := b.adaptor.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: b.adaptor.Namespace}, hwmgr); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get HardwareManager %s: %w", node.Spec.HwMgrId, err)
+	}
+
+	return hwmgr.Spec.AdaptorID == pluginv1alpha1.SupportedAdaptors.Loopback &&
+		hwmgr.Spec.LoopbackData != nil && hwmgr.Spec.LoopbackData.EmulatedBMC != nil, nil
+}
+
+// isAuthorized returns true if the credentials match the bmc-secret created for the node
+func (b *emulatedBMC) isAuthorized(ctx context.Context, node *hwmgmtv1alpha1.Node, creds emulatedBMCCredentials) (bool, error) {
+	secret := &corev1.Secret{}
+	secretName := utils.BMCSecretName(node.Name)
+	if err := b.adaptor.Get(ctx, types.NamespacedName{Name: secretName, Namespace: node.Namespace}, secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get bmc-secret %s: %w", secretName, err)
+	}
+
+	usernameMatch := subtle.ConstantTimeCompare(secret.Data["username"], []byte(creds.username)) == 1
+	passwordMatch := subtle.ConstantTimeCompare(secret.Data["password"], []byte(creds.password)) == 1
+	return usernameMatch && passwordMatch, nil
+}
+
+// getAccessibleNodes returns the emulated nodes that are accessible with the credentials
+func (b *emulatedBMC) getAccessibleNodes(ctx context.Context, creds emulatedBMCCredentials) ([]*hwmgmtv1alpha1.Node, error) {
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	if err := b.adaptor.List(ctx, nodelist); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var nodes []*hwmgmtv1alpha1.Node
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		emulated, err := b.isEmulatedNode(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		if !emulated {
+			continue
+		}
+
+		authorized, err := b.isAuthorized(ctx, node, creds)
+		if err != nil {
+			return nil, err
+		}
+		if authorized {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes, nil
+}
+
+// authorizeSystem returns the node for the system requested, writing an error response if it is not found or the
+// request is not authorized for it. Systems that are not emulated are reported as not found.
+func (b *emulatedBMC) authorizeSystem(w http.ResponseWriter, r *http.Request) (*hwmgmtv1alpha1.Node, bool) {
+	creds, ok := b.getCredentials(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+		writeRedfishError(w, http.StatusUnauthorized, "authentication required")
+		return nil, false
+	}
+
+	nodes, err := b.getAccessibleNodes(r.Context(), creds)
+	if err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	nodeId := r.PathValue("nodeId")
+	for _, node := range nodes {
+		if node.Spec.HwMgrNodeId == nodeId {
+			return node, true
+		}
+	}
+
+	writeRedfishError(w, http.StatusNotFound, fmt.Sprintf("system %s not found", nodeId))
+	return nil, false
+}
+
+func (b *emulatedBMC) listSystems(w http.ResponseWriter, r *http.Request) {
+	creds, ok := b.getCredentials(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+		writeRedfishError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	nodes, err := b.getAccessibleNodes(r.Context(), creds)
+	if err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	members := []map[string]string{}
+	for _, node := range nodes {
+		members = append(members, map[string]string{"@odata.id": redfishSystemsPath + "/" + node.Spec.HwMgrNodeId})
+	}
+
+	writeRedfishJSON(w, http.StatusOK, map[string]any{
+		"@odata.id":           redfishSystemsPath,
+		"Name":                "Computer System Collection",
+		"Members":             members,
+		"Members@odata.count": len(members),
+	})
+}
+
+func (b *emulatedBMC) getSystem(w http.ResponseWriter, r *http.Request) {
+	node, ok := b.authorizeSystem(w, r)
+	if !ok {
+		return
+	}
+
+	nodeId := node.Spec.HwMgrNodeId
+	_, resources, _, err := b.adaptor.GetCurrentResources(r.Context())
+	if err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	info := resources.Nodes[nodeId]
+
+	b.mu.Lock()
+	boot := b.boot[nodeId]
+	b.mu.Unlock()
+	if boot.BootSourceOverrideEnabled == "" {
+		boot.BootSourceOverrideEnabled = "Disabled"
+	}
+
+	path := redfishSystemsPath + "/" + nodeId
+	writeRedfishJSON(w, http.StatusOK, map[string]any{
+		"@odata.id":    path,
+		"Id":           nodeId,
+		"Name":         node.Name,
+		"PowerState":   string(info.getPowerState()),
+		"Manufacturer": info.Vendor,
+		"Model":        info.Model,
+		"SerialNumber": info.SerialNumber,
+		"AssetTag":     info.AssetTag,
+		"Boot":         boot,
+		"BootProgress": map[string]string{"LastState": string(info.getBootProgress())},
+		"Status":       map[string]string{"State": "Enabled", "Health": emulatedHealth(info)},
+		"ProcessorSummary": map[string]int{
+			"Count": info.CPUs,
+		},
+		"MemorySummary": map[string]int{
+			"TotalSystemMemoryGiB": info.MemoryGiB,
+		},
+		"Actions": map[string]any{
+			"#ComputerSystem.Reset": map[string]any{
+				"target":                            path + "/Actions/ComputerSystem.Reset",
+				"ResetType@Redfish.AllowableValues": []string{"On", "ForceOn", "ForceOff", "GracefulShutdown", "GracefulRestart", "ForceRestart", "PowerCycle", "PushPowerButton", "Nmi"},
+			},
+		},
+	})
+}
+
+// emulatedHealth returns the Redfish health of the simulated node
+func emulatedHealth(info cmNodeInfo) string {
+	if info.Failed {
+		return "Critical"
+	}
+	return "OK"
+}
+
+// patchSystem updates the boot source override of the system. Other properties are ignored.
+func (b *emulatedBMC) patchSystem(w http.ResponseWriter, r *http.Request) {
+	node, ok := b.authorizeSystem(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Boot *emulatedBootOverride
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeRedfishError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if request.Boot != nil {
+		b.mu.Lock()
+		boot := b.boot[node.Spec.HwMgrNodeId]
+		if request.Boot.BootSourceOverrideEnabled != "" {
+			boot.BootSourceOverrideEnabled = request.Boot.BootSourceOverrideEnabled
+		}
+		if request.Boot.BootSourceOverrideTarget != "" {
+			boot.BootSourceOverrideTarget = request.Boot.BootSourceOverrideTarget
+		}
+		if request.Boot.BootSourceOverrideMode != "" {
+			boot.BootSourceOverrideMode = request.Boot.BootSourceOverrideMode
+		}
+		b.boot[node.Spec.HwMgrNodeId] = boot
+		b.mu.Unlock()
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resetSystem applies a reset action to the simulated power state of the system
+func (b *emulatedBMC) resetSystem(w http.ResponseWriter, r *http.Request) {
+	node, ok := b.authorizeSystem(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		ResetType string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeRedfishError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	switch request.ResetType {
+	case "On", "ForceOn", "GracefulShutdown", "ForceOff", "GracefulRestart", "ForceRestart", "PowerCycle", "PushPowerButton":
+	case "Nmi":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		writeRedfishError(w, http.StatusBadRequest, fmt.Sprintf("unsupported ResetType %q", request.ResetType))
+		return
+	}
+
+	if err := b.adaptor.setEmulatedPowerState(r.Context(), node.Spec.HwMgrNodeId, request.ResetType); err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	b.adaptor.Logger.InfoContext(r.Context(), "Emulated BMC reset",
+		slog.String("nodename", node.Name),
+		slog.String("resetType", request.ResetType))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setEmulatedPowerState updates the simulated power state of the node in the nodelist configmap for a reset action.
+// The boot progress is cleared, so that it follows the new power state.
+func (a *Adaptor) setEmulatedPowerState(ctx context.Context, nodeId, resetType string) error {
+	if err := retry.RetryOnConflict(configMapUpdateBackoff, func() error {
+		cm, resources, _, err := a.GetCurrentResources(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get current resources: %w", err)
+		}
+
+		info, exists := resources.Nodes[nodeId]
+		if !exists {
+			return fmt.Errorf("unable to find nodeinfo for %s", nodeId)
+		}
+
+		switch resetType {
+		case "GracefulShutdown", "ForceOff":
+			info.PowerState = string(utils.PowerStateOff)
+		case "PushPowerButton":
+			if info.getPowerState() == utils.PowerStateOff {
+				info.PowerState = string(utils.PowerStateOn)
+			} else {
+				info.PowerState = string(utils.PowerStateOff)
+			}
+		default:
+			info.PowerState = string(utils.PowerStateOn)
+		}
+		info.BootProgress = ""
+		resources.Nodes[nodeId] = info
+
+		yamlString, err := yaml.Marshal(&resources)
+		if err != nil {
+			return fmt.Errorf("unable to marshal resources: %w", err)
+		}
+		cm.Data[resourcesKey] = string(yamlString)
+
+		if err := a.Client.Update(ctx, cm); err != nil {
+			return fmt.Errorf("failed to update configmap: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update power state of node %s: %w", nodeId, err)
+	}
+
+	return nil
+}
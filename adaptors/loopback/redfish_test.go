@@ -0,0 +1,294 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// bmcClient serves the nodelist configmap, and the HardwareManager, Node and bmc-secret CRs read by the emulated BMC
+type bmcClient struct {
+	*configMapClient
+	hwmgrs  []pluginv1alpha1.HardwareManager
+	nodes   []hwmgmtv1alpha1.Node
+	secrets []corev1.Secret
+}
+
+func (c *bmcClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	switch typed := obj.(type) {
+	case *pluginv1alpha1.HardwareManager:
+		for i := range c.hwmgrs {
+			if c.hwmgrs[i].Name == key.Name && c.hwmgrs[i].Namespace == key.Namespace {
+				c.hwmgrs[i].DeepCopyInto(typed)
+				return nil
+			}
+		}
+		return k8serrors.NewNotFound(pluginv1alpha1.GroupVersion.WithResource("hardwaremanagers").GroupResource(), key.Name)
+	case *corev1.Secret:
+		for i := range c.secrets {
+			if c.secrets[i].Name == key.Name && c.secrets[i].Namespace == key.Namespace {
+				c.secrets[i].DeepCopyInto(typed)
+				return nil
+			}
+		}
+		return k8serrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+	}
+	return c.configMapClient.Get(ctx, key, obj, opts...)
+}
+
+func (c *bmcClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	if nodelist, ok := list.(*hwmgmtv1alpha1.NodeList); ok {
+		nodelist.Items = c.nodes
+	}
+	return nil
+}
+
+func (c *bmcClient) getPowerState(nodeId string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var resources cmResources
+	Expect(yaml.Unmarshal([]byte(c.cm.Data[resourcesKey]), &resources)).To(Succeed())
+	return string(resources.Nodes[nodeId].getPowerState())
+}
+
+var _ = Describe("Emulated BMC", func() {
+	var (
+		c      *bmcClient
+		server *httptest.Server
+	)
+
+	newNode := func(name, hwmgrId string) hwmgmtv1alpha1.Node {
+		return hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec:       hwmgmtv1alpha1.NodeSpec{HwMgrId: hwmgrId, HwMgrNodeId: name},
+		}
+	}
+
+	newSecret := func(nodename, username, password string) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: utils.BMCSecretName(nodename), Namespace: "test"},
+			Data:       map[string][]byte{"username": []byte(username), "password": []byte(password)},
+		}
+	}
+
+	newHwMgr := func(name string, data *pluginv1alpha1.LoopbackData) pluginv1alpha1.HardwareManager {
+		return pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				AdaptorID:    pluginv1alpha1.SupportedAdaptors.Loopback,
+				LoopbackData: data,
+			},
+		}
+	}
+
+	// request sends a request to the emulated BMC, optionally with basic auth or a session token
+	request := func(method, path, body string, prepare func(req *http.Request)) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		if prepare != nil {
+			prepare(req)
+		}
+		resp, err := server.Client().Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(resp.Body.Close)
+		return resp
+	}
+
+	basicAuth := func(username, password string) func(req *http.Request) {
+		return func(req *http.Request) {
+			req.SetBasicAuth(username, password)
+		}
+	}
+
+	sessionAuth := func(token string) func(req *http.Request) {
+		return func(req *http.Request) {
+			req.Header.Set("X-Auth-Token", token)
+		}
+	}
+
+	decode := func(resp *http.Response) map[string]any {
+		body := make(map[string]any)
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return body
+	}
+
+	systemPath := func(nodeId string) string {
+		return redfishSystemsPath + "/" + nodeId
+	}
+
+	resetPath := func(nodeId string) string {
+		return systemPath(nodeId) + "/Actions/ComputerSystem.Reset"
+	}
+
+	BeforeEach(func() {
+		c = &bmcClient{
+			configMapClient: newConfigMapClient(3),
+			hwmgrs: []pluginv1alpha1.HardwareManager{
+				newHwMgr("emulated", &pluginv1alpha1.LoopbackData{
+					EmulatedBMC: &pluginv1alpha1.EmulatedBMCConfig{BaseURL: "https://bmc.example.com"},
+				}),
+				newHwMgr("plain", nil),
+			},
+			nodes: []hwmgmtv1alpha1.Node{
+				newNode("node1", "emulated"),
+				newNode("node2", "emulated"),
+				newNode("node3", "plain"),
+			},
+			secrets: []corev1.Secret{
+				newSecret("node1", "admin", "secret1"),
+				newSecret("node2", "admin", "secret2"),
+				newSecret("node3", "admin", "secret1"),
+			},
+		}
+
+		bmc := &emulatedBMC{
+			adaptor:       NewAdaptor(c, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test"),
+			sessions:      make(map[string]emulatedBMCCredentials),
+			boot:          make(map[string]emulatedBootOverride),
+			subscriptions: make(map[string]emulatedSubscription),
+		}
+		server = httptest.NewServer(bmc.handler())
+		DeferCleanup(server.Close)
+	})
+
+	It("lists the emulated systems accessible with the basic auth credentials", func() {
+		resp := request(http.MethodGet, redfishSystemsPath, "", basicAuth("admin", "secret1"))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(decode(resp)["Members"]).To(ConsistOf(HaveKeyWithValue("@odata.id", systemPath("node1"))))
+
+		resp = request(http.MethodGet, redfishSystemsPath, "", basicAuth("admin", "wrong"))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(decode(resp)["Members"]).To(BeEmpty())
+
+		resp = request(http.MethodGet, redfishSystemsPath, "", nil)
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(resp.Header.Get("WWW-Authenticate")).To(Equal(`Basic realm="Redfish"`))
+	})
+
+	It("creates and deletes sessions", func() {
+		resp := request(http.MethodPost, redfishSessionsPath, `{"UserName": "admin", "Password": "wrong"}`, nil)
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		resp = request(http.MethodPost, redfishSessionsPath, `{"UserName": "admin", "Password": "secret2"}`, nil)
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		token := resp.Header.Get("X-Auth-Token")
+		Expect(token).ToNot(BeEmpty())
+		Expect(resp.Header.Get("Location")).To(Equal(redfishSessionsPath + "/" + token))
+
+		resp = request(http.MethodGet, systemPath("node2"), "", sessionAuth(token))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(decode(resp)).To(HaveKeyWithValue("Name", "node2"))
+
+		resp = request(http.MethodDelete, redfishSessionsPath+"/"+token, "", nil)
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+
+		resp = request(http.MethodGet, systemPath("node2"), "", sessionAuth(token))
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		resp = request(http.MethodDelete, redfishSessionsPath+"/"+token, "", nil)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("reports systems that are not emulated or not authorized as not found", func() {
+		// node3 has the same credentials as node1, but its hardware manager has no emulated BMC
+		resp := request(http.MethodGet, systemPath("node3"), "", basicAuth("admin", "secret1"))
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		resp = request(http.MethodGet, systemPath("node2"), "", basicAuth("admin", "secret1"))
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		resp = request(http.MethodGet, systemPath("node9"), "", basicAuth("admin", "secret1"))
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		now := metav1.Now()
+		c.nodes[0].DeletionTimestamp = &now
+		resp = request(http.MethodGet, systemPath("node1"), "", basicAuth("admin", "secret1"))
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("applies reset actions to the simulated power state", func() {
+		auth := basicAuth("admin", "secret1")
+
+		resp := request(http.MethodPost, resetPath("node1"), `{"ResetType": "ForceOff"}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(c.getPowerState("node1")).To(Equal(string(utils.PowerStateOff)))
+		Expect(c.getPowerState("node2")).To(Equal(string(utils.PowerStateOn)))
+
+		resp = request(http.MethodGet, systemPath("node1"), "", auth)
+		Expect(decode(resp)).To(HaveKeyWithValue("PowerState", string(utils.PowerStateOff)))
+
+		resp = request(http.MethodPost, resetPath("node1"), `{"ResetType": "PushPowerButton"}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(c.getPowerState("node1")).To(Equal(string(utils.PowerStateOn)))
+
+		updates := c.updates
+		resp = request(http.MethodPost, resetPath("node1"), `{"ResetType": "Nmi"}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		resp = request(http.MethodPost, resetPath("node1"), `{"ResetType": "Explode"}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(c.updates).To(Equal(updates))
+
+		resp = request(http.MethodPost, resetPath("node2"), `{"ResetType": "ForceOff"}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(c.getPowerState("node2")).To(Equal(string(utils.PowerStateOn)))
+	})
+
+	It("patches the boot source override of the system", func() {
+		auth := basicAuth("admin", "secret1")
+
+		resp := request(http.MethodGet, systemPath("node1"), "", auth)
+		Expect(decode(resp)["Boot"]).To(Equal(map[string]any{"BootSourceOverrideEnabled": "Disabled"}))
+
+		resp = request(http.MethodPatch, systemPath("node1"), `{"Boot": {"BootSourceOverrideEnabled": "Once", `+
+			`"BootSourceOverrideTarget": "Cd", "BootSourceOverrideMode": "UEFI"}}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+
+		resp = request(http.MethodPatch, systemPath("node1"), `{"Boot": {"BootSourceOverrideTarget": "Pxe"}}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+
+		resp = request(http.MethodGet, systemPath("node1"), "", auth)
+		Expect(decode(resp)["Boot"]).To(Equal(map[string]any{
+			"BootSourceOverrideEnabled": "Once",
+			"BootSourceOverrideTarget":  "Pxe",
+			"BootSourceOverrideMode":    "UEFI",
+		}))
+
+		resp = request(http.MethodPatch, systemPath("node1"), `not json`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+		resp = request(http.MethodPatch, systemPath("node2"), `{"Boot": {"BootSourceOverrideTarget": "Pxe"}}`, auth)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
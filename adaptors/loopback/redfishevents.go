@@ -0,0 +1,269 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	redfishEventServicePath  = "/redfish/v1/EventService"
+	redfishSubscriptionsPath = redfishEventServicePath + "/Subscriptions"
+	redfishChassisPath       = "/redfish/v1/Chassis"
+
+	emulatedEventDeliveryTimeout = 5 * time.Second
+)
+
+// emulatedSubscription is an event subscription on the emulated BMC, which receives the events of the systems that
+// were accessible with the credentials of its creator, optionally restricted by its origin resources
+type emulatedSubscription struct {
+	id          string
+	destination string
+	context     string
+	creds       emulatedBMCCredentials
+	nodeIds     []string
+}
+
+func (b *emulatedBMC) getEventService(w http.ResponseWriter, r *http.Request) {
+	if _, ok := b.getCredentials(r); !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+		writeRedfishError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	writeRedfishJSON(w, http.StatusOK, map[string]any{
+		"@odata.id":      redfishEventServicePath,
+		"Id":             "EventService",
+		"Name":           "Event Service",
+		"ServiceEnabled": true,
+		"Subscriptions":  map[string]string{"@odata.id": redfishSubscriptionsPath},
+		"Actions": map[string]any{
+			"#EventService.SubmitTestEvent": map[string]string{
+				"target": redfishEventServicePath + "/Actions/EventService.SubmitTestEvent",
+			},
+		},
+	})
+}
+
+// createSubscription subscribes a destination to the events of the systems accessible with the credentials
+func (b *emulatedBMC) createSubscription(w http.ResponseWriter, r *http.Request) {
+	creds, ok := b.getCredentials(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+		writeRedfishError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var request struct {
+		Destination     string
+		Context         string
+		OriginResources []struct {
+			ID string `json:"@odata.id"`
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Destination == "" {
+		writeRedfishError(w, http.StatusBadRequest, "invalid subscription request")
+		return
+	}
+
+	nodes, err := b.getAccessibleNodes(r.Context(), creds)
+	if err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var origins []string
+	for _, origin := range request.OriginResources {
+		origins = append(origins, strings.TrimSuffix(origin.ID, "/"))
+	}
+	var nodeIds []string
+	for _, node := range nodes {
+		if len(origins) == 0 || slices.Contains(origins, redfishSystemsPath+"/"+node.Spec.HwMgrNodeId) {
+			nodeIds = append(nodeIds, node.Spec.HwMgrNodeId)
+		}
+	}
+	if len(nodeIds) == 0 {
+		writeRedfishError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+
+	b.mu.Lock()
+	b.nextSubscriptionId++
+	id := strconv.Itoa(b.nextSubscriptionId)
+	b.subscriptions[id] = emulatedSubscription{
+		id:          id,
+		destination: request.Destination,
+		context:     request.Context,
+		creds:       creds,
+		nodeIds:     nodeIds,
+	}
+	b.mu.Unlock()
+
+	location := redfishSubscriptionsPath + "/" + id
+	w.Header().Set("Location", location)
+	writeRedfishJSON(w, http.StatusCreated, map[string]any{
+		"@odata.id":   location,
+		"Id":          id,
+		"Destination": request.Destination,
+		"Context":     request.Context,
+		"Protocol":    "Redfish",
+	})
+}
+
+// deleteSubscription deletes a subscription, which is only accessible with the credentials of its creator
+func (b *emulatedBMC) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	creds, ok := b.getCredentials(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+		writeRedfishError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	id := r.PathValue("id")
+	b.mu.Lock()
+	subscription, exists := b.subscriptions[id]
+	if exists && subscription.creds == creds {
+		delete(b.subscriptions, id)
+	}
+	b.mu.Unlock()
+
+	if !exists || subscription.creds != creds {
+		writeRedfishError(w, http.StatusNotFound, fmt.Sprintf("subscription %s not found", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// submitTestEvent delivers an event raised by a resource of an emulated system to the subscriptions for the system.
+// The origin of the event must be within the system or chassis of a node accessible with the credentials, such as
+// /redfish/v1/Chassis/{nodeId}/Thermal.
+func (b *emulatedBMC) submitTestEvent(w http.ResponseWriter, r *http.Request) {
+	creds, ok := b.getCredentials(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Redfish"`)
+		writeRedfishError(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var request struct {
+		MessageId         string
+		Message           string
+		MessageSeverity   string
+		OriginOfCondition string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeRedfishError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if request.MessageSeverity == "" {
+		request.MessageSeverity = "OK"
+	}
+
+	nodes, err := b.getAccessibleNodes(r.Context(), creds)
+	if err != nil {
+		writeRedfishError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	nodeId := ""
+	for _, node := range nodes {
+		id := node.Spec.HwMgrNodeId
+		for _, prefix := range []string{redfishSystemsPath + "/" + id, redfishChassisPath + "/" + id} {
+			if request.OriginOfCondition == prefix || strings.HasPrefix(request.OriginOfCondition, prefix+"/") {
+				nodeId = id
+			}
+		}
+	}
+	if nodeId == "" {
+		writeRedfishError(w, http.StatusBadRequest,
+			fmt.Sprintf("OriginOfCondition %q is not an accessible system", request.OriginOfCondition))
+		return
+	}
+
+	b.mu.Lock()
+	b.nextEventId++
+	eventId := strconv.Itoa(b.nextEventId)
+	var subscriptions []emulatedSubscription
+	for _, subscription := range b.subscriptions {
+		if slices.Contains(subscription.nodeIds, nodeId) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	b.mu.Unlock()
+
+	event := map[string]any{
+		"EventType":         "Alert",
+		"EventId":           eventId,
+		"MessageId":         request.MessageId,
+		"Message":           request.Message,
+		"MessageSeverity":   request.MessageSeverity,
+		"OriginOfCondition": map[string]string{"@odata.id": request.OriginOfCondition},
+		"EventTimestamp":    time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, subscription := range subscriptions {
+		// Events are delivered asynchronously, as by a BMC, so a slow destination does not hold up the request
+		go b.deliverEvent(subscription, eventId, event)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deliverEvent posts an event to the destination of a subscription. Failed deliveries are logged and not retried.
+func (b *emulatedBMC) deliverEvent(subscription emulatedSubscription, eventId string, event map[string]any) {
+	body, err := json.Marshal(map[string]any{
+		"@odata.type": "#Event.v1_7_0.Event",
+		"Id":          eventId,
+		"Name":        "Event Array",
+		"Context":     subscription.context,
+		"Events":      []map[string]any{event},
+	})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), emulatedEventDeliveryTimeout)
+	defer cancel()
+
+	logger := b.adaptor.Logger.With(
+		slog.String("subscription", subscription.id),
+		slog.String("destination", subscription.destination))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.destination, bytes.NewReader(body))
+	if err != nil {
+		logger.Info("Failed to deliver emulated BMC event", slog.String("error", err.Error()))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.Info("Failed to deliver emulated BMC event", slog.String("error", err.Error()))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		logger.Info("Emulated BMC event rejected", slog.String("status", resp.Status))
+	}
+}
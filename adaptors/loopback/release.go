@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// releaseNode removes the node from the allocation of the cloud, returning true if it was allocated
+func (allocations *cmAllocations) releaseNode(cloudID string, node *hwmgmtv1alpha1.Node) (released bool) {
+	for i := range allocations.Clouds {
+		cloud := &allocations.Clouds[i]
+		if cloud.CloudID != cloudID {
+			continue
+		}
+		if index := slices.Index(cloud.Nodegroups[node.Spec.GroupName], node.Name); index >= 0 {
+			cloud.Nodegroups[node.Spec.GroupName] = slices.Delete(cloud.Nodegroups[node.Spec.GroupName], index, index+1)
+			released = true
+		}
+		delete(cloud.Replaced, node.Name)
+		delete(cloud.Adopted, node.Name)
+		delete(cloud.Migrated, node.Name)
+		delete(cloud.Pending, node.Name)
+	}
+	return
+}
+
+// ReleaseNode releases the node from the allocation of the NodePool in the nodelist configmap, returning it to the free
+// nodes of its resource pool. Releasing a node that is no longer allocated succeeds, so that an interrupted release is
+// completed.
+func (a *Adaptor) ReleaseNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, _ cmResources, allocations *cmAllocations) (bool, error) {
+		if !allocations.releaseNode(nodepool.Spec.CloudID, node) {
+			return false, nil
+		}
+
+		a.Logger.InfoContext(ctx, "Releasing node from allocation",
+			slog.String("nodename", node.Name),
+			slog.String("nodeId", node.Spec.HwMgrNodeId))
+		return true, nil
+	}); err != nil {
+		return fmt.Errorf("failed to release node %s: %w", node.Name, err)
+	}
+
+	return nil
+}
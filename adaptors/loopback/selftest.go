@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// SelfTest reads the nodelist configmap, along with the resource pools and the first node defined in it. The loopback
+// adaptor has no backend credentials, so the Authenticate step verifies that the configmap can be read.
+func (a *Adaptor) SelfTest(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.SelfTestStep {
+	test := &sdk.SelfTest{}
+
+	var resources cmResources
+	test.Run(sdk.SelfTestStepAuthenticate, func() (string, error) {
+		var err error
+		if _, resources, _, err = a.GetCurrentResources(ctx); err != nil {
+			return "", fmt.Errorf("unable to read configmap %s: %w", cmName, err)
+		}
+		return "Read configmap " + cmName, nil
+	})
+
+	test.Run(sdk.SelfTestStepListResourcePools, func() (string, error) {
+		return sdk.DescribeResourcePools(resources.ResourcePools), nil
+	})
+
+	test.Run(sdk.SelfTestStepGetNode, func() (string, error) {
+		nodeIds := make([]string, 0, len(resources.Nodes))
+		for nodeId := range resources.Nodes {
+			nodeIds = append(nodeIds, nodeId)
+		}
+		if len(nodeIds) == 0 {
+			return "No nodes defined in configmap " + cmName, nil
+		}
+		slices.Sort(nodeIds)
+
+		info := resources.Nodes[nodeIds[0]]
+		return fmt.Sprintf("Fetched node %s in resource pool %s", nodeIds[0], info.ResourcePoolID), nil
+	})
+
+	return test.Steps
+}
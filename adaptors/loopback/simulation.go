@@ -0,0 +1,180 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+const (
+	defaultAllocationDelay = 10 * time.Second
+	defaultSpecChangeDelay = 5 * time.Second
+)
+
+// simulator holds the pseudo-random state of the simulation for a hardware manager. With a fixed seed, the sequence
+// of delays, selected nodes and injected faults is reproducible for a given sequence of allocations.
+type simulator struct {
+	mu     sync.Mutex
+	seed   int64
+	seeded bool
+	rng    *rand.Rand
+}
+
+// Simulators are shared by hardware manager name, so that the sequence continues across reconciles
+var simulators sync.Map
+
+// getSimulator returns the simulator for the hardware manager, re-seeding it if the configured seed has changed
+func (a *Adaptor) getSimulator(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) *simulator {
+	var seed int64
+	seeded := hwmgr.Spec.LoopbackData != nil && hwmgr.Spec.LoopbackData.Seed != nil
+	if seeded {
+		seed = *hwmgr.Spec.LoopbackData.Seed
+	}
+
+	if existing, ok := simulators.Load(hwmgr.Name); ok {
+		sim := existing.(*simulator)
+		if sim.seeded == seeded && (!seeded || sim.seed == seed) {
+			return sim
+		}
+	}
+
+	if !seeded {
+		seed = time.Now().UnixNano()
+	}
+
+	sim := &simulator{seed: seed, seeded: seeded, rng: rand.New(rand.NewSource(seed))} // nolint: gosec
+	simulators.Store(hwmgr.Name, sim)
+	a.Logger.InfoContext(ctx, "Seeded loopback simulation", slog.Int64("seed", seed), slog.Bool("configured", seeded))
+	return sim
+}
+
+// intn returns a pseudo-random number in the range [0, n)
+func (s *simulator) intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Intn(n)
+}
+
+// allocationDelay returns the simulated delay before a node allocation
+func (s *simulator) allocationDelay(data *pluginv1alpha1.LoopbackData) time.Duration {
+	if data != nil && data.Latency != nil && data.Latency.Allocate != nil {
+		return s.sampleLatency(data.Latency.Allocate)
+	}
+	if data == nil || data.MaxAllocationDelay == nil {
+		return defaultAllocationDelay
+	}
+	if data.MaxAllocationDelay.Duration <= 0 {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(s.rng.Int63n(int64(data.MaxAllocationDelay.Duration) + 1))
+}
+
+// releaseDelay returns the simulated delay before the release of the nodes of a NodePool
+func (s *simulator) releaseDelay(data *pluginv1alpha1.LoopbackData) time.Duration {
+	if data == nil || data.Latency == nil {
+		return 0
+	}
+	return s.sampleLatency(data.Latency.Release)
+}
+
+// statusUpdateDelay returns the simulated delay before an update of the status of a node
+func (s *simulator) statusUpdateDelay(data *pluginv1alpha1.LoopbackData) time.Duration {
+	if data == nil || data.Latency == nil {
+		return 0
+	}
+	return s.sampleLatency(data.Latency.StatusUpdate)
+}
+
+// specChangeDelay returns the simulated delay of a step of a NodePool spec change
+func (s *simulator) specChangeDelay(data *pluginv1alpha1.LoopbackData) time.Duration {
+	if data == nil || data.Latency == nil || data.Latency.SpecChange == nil {
+		return defaultSpecChangeDelay
+	}
+	return s.sampleLatency(data.Latency.SpecChange)
+}
+
+// sampleLatency returns a latency drawn from the configured distribution, bounded by its min and max. The LongTail
+// distribution is a log-normal distribution with the configured mean and standard deviation.
+func (s *simulator) sampleLatency(config *pluginv1alpha1.LatencyConfig) time.Duration {
+	if config == nil {
+		return 0
+	}
+
+	valueOf := func(d *metav1.Duration) float64 {
+		if d == nil {
+			return 0
+		}
+		return float64(d.Duration)
+	}
+	mean, stddev, lower := valueOf(config.Mean), valueOf(config.StdDev), valueOf(config.Min)
+	upper := math.Inf(1)
+	if config.Max != nil {
+		upper = valueOf(config.Max)
+	}
+
+	s.mu.Lock()
+	var latency float64
+	switch config.Distribution {
+	case pluginv1alpha1.LatencyDistributions.Uniform:
+		latency = mean
+		if config.Max != nil {
+			latency = lower + s.rng.Float64()*(upper-lower)
+		}
+	case pluginv1alpha1.LatencyDistributions.Normal:
+		latency = mean + s.rng.NormFloat64()*stddev
+	case pluginv1alpha1.LatencyDistributions.LongTail:
+		if mean > 0 {
+			sigma := math.Sqrt(math.Log(1 + (stddev*stddev)/(mean*mean)))
+			mu := math.Log(mean) - sigma*sigma/2
+			latency = math.Exp(mu + sigma*s.rng.NormFloat64())
+		}
+	default:
+		latency = mean
+	}
+	s.mu.Unlock()
+
+	return time.Duration(math.Max(lower, math.Min(upper, latency)))
+}
+
+// chooseNode returns the free node to allocate, which is the first, least recently allocated, node unless random node
+// selection is enabled
+func (s *simulator) chooseNode(data *pluginv1alpha1.LoopbackData, freenodes []string) string {
+	if data == nil || !data.RandomNodeSelection {
+		return freenodes[0]
+	}
+	return freenodes[s.intn(len(freenodes))]
+}
+
+// injectAllocationFailure returns true if the node allocation is to fail with a simulated fault
+func (s *simulator) injectAllocationFailure(data *pluginv1alpha1.LoopbackData) bool {
+	if data == nil || data.AllocationFailurePercent <= 0 {
+		return false
+	}
+	return s.intn(100) < data.AllocationFailurePercent
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// simulatedStep is the outcome of the pseudo-random choices made for a single simulated node allocation
+type simulatedStep struct {
+	Delay  time.Duration
+	Node   string
+	Failed bool
+}
+
+var _ = Describe("Simulation", func() {
+	var (
+		ctx context.Context
+		a   *Adaptor
+	)
+
+	data := &pluginv1alpha1.LoopbackData{
+		MaxAllocationDelay:       &metav1.Duration{Duration: time.Minute},
+		RandomNodeSelection:      true,
+		AllocationFailurePercent: 30,
+	}
+	freenodes := []string{"node1", "node2", "node3", "node4", "node5"}
+
+	newHwMgr := func(name string, seed *int64) *pluginv1alpha1.HardwareManager {
+		DeferCleanup(simulators.Delete, name)
+		loopbackData := *data
+		loopbackData.Seed = seed
+		return &pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       pluginv1alpha1.HardwareManagerSpec{LoopbackData: &loopbackData},
+		}
+	}
+
+	seed := func(value int64) *int64 {
+		return &value
+	}
+
+	simulate := func(sim *simulator, allocations int) []simulatedStep {
+		var steps []simulatedStep
+		for range allocations {
+			steps = append(steps, simulatedStep{
+				Delay:  sim.allocationDelay(data),
+				Node:   sim.chooseNode(data, freenodes),
+				Failed: sim.injectAllocationFailure(data),
+			})
+		}
+		return steps
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		a = NewAdaptor(nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), "test")
+	})
+
+	It("makes the same choices for hardware managers with the same seed", func() {
+		first := simulate(a.getSimulator(ctx, newHwMgr("sim1", seed(42))), 20)
+		second := simulate(a.getSimulator(ctx, newHwMgr("sim2", seed(42))), 20)
+		other := simulate(a.getSimulator(ctx, newHwMgr("sim3", seed(43))), 20)
+
+		Expect(second).To(Equal(first))
+		Expect(other).ToNot(Equal(first))
+		Expect(first).To(ContainElement(HaveField("Failed", true)))
+		Expect(first).To(ContainElement(HaveField("Failed", false)))
+	})
+
+	It("continues the sequence across reconciles", func() {
+		hwmgr := newHwMgr("sim1", seed(42))
+		expected := simulate(a.getSimulator(ctx, newHwMgr("sim2", seed(42))), 10)
+
+		sim := a.getSimulator(ctx, hwmgr)
+		steps := simulate(sim, 5)
+		Expect(a.getSimulator(ctx, hwmgr)).To(BeIdenticalTo(sim))
+		steps = append(steps, simulate(a.getSimulator(ctx, hwmgr), 5)...)
+		Expect(steps).To(Equal(expected))
+	})
+
+	It("re-seeds the simulator when the seed changes", func() {
+		hwmgr := newHwMgr("sim1", seed(42))
+		sim := a.getSimulator(ctx, hwmgr)
+		simulate(sim, 5)
+
+		hwmgr.Spec.LoopbackData.Seed = seed(7)
+		reseeded := a.getSimulator(ctx, hwmgr)
+		Expect(reseeded).ToNot(BeIdenticalTo(sim))
+		Expect(simulate(reseeded, 10)).To(Equal(simulate(a.getSimulator(ctx, newHwMgr("sim2", seed(7))), 10)))
+
+		hwmgr.Spec.LoopbackData.Seed = nil
+		unseeded := a.getSimulator(ctx, hwmgr)
+		Expect(unseeded).ToNot(BeIdenticalTo(reseeded))
+		Expect(unseeded.seeded).To(BeFalse())
+		Expect(a.getSimulator(ctx, hwmgr)).To(BeIdenticalTo(unseeded))
+
+		hwmgr.Spec.LoopbackData.Seed = seed(7)
+		Expect(a.getSimulator(ctx, hwmgr)).ToNot(BeIdenticalTo(unseeded))
+	})
+
+	It("selects the first free node and injects no faults by default", func() {
+		sim := a.getSimulator(ctx, newHwMgr("sim1", seed(42)))
+		for range 10 {
+			Expect(sim.chooseNode(nil, freenodes)).To(Equal("node1"))
+			Expect(sim.injectAllocationFailure(nil)).To(BeFalse())
+		}
+		Expect(sim.allocationDelay(nil)).To(Equal(defaultAllocationDelay))
+	})
+})
+
+var _ = Describe("Simulated latency", func() {
+	duration := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+
+	// sample draws latencies from a simulator with a fixed seed
+	sample := func(config *pluginv1alpha1.LatencyConfig, samples int) []time.Duration {
+		sim := &simulator{seed: 42, seeded: true, rng: rand.New(rand.NewSource(42))} // nolint: gosec
+		var latencies []time.Duration
+		for range samples {
+			latencies = append(latencies, sim.sampleLatency(config))
+		}
+		return latencies
+	}
+
+	DescribeTable("samples the configured distribution",
+		func(config *pluginv1alpha1.LatencyConfig, matcher types.GomegaMatcher) {
+			Expect(sample(config, 200)).To(HaveEach(matcher))
+		},
+		Entry("with no latency configured", nil, BeZero()),
+		Entry("with a fixed latency",
+			&pluginv1alpha1.LatencyConfig{Mean: duration(time.Second)},
+			Equal(time.Second)),
+		Entry("with a fixed latency clamped to the max",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Fixed,
+				Mean: duration(time.Second), Max: duration(500 * time.Millisecond)},
+			Equal(500*time.Millisecond)),
+		Entry("with a fixed latency clamped to the min",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Fixed,
+				Mean: duration(time.Second), Min: duration(2 * time.Second)},
+			Equal(2*time.Second)),
+		Entry("with a uniform latency between the min and max",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Uniform,
+				Min: duration(time.Second), Max: duration(2 * time.Second)},
+			And(BeNumerically(">=", time.Second), BeNumerically("<=", 2*time.Second))),
+		Entry("with a uniform latency without a max falling back to the mean",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Uniform,
+				Mean: duration(3 * time.Second), Min: duration(time.Second)},
+			Equal(3*time.Second)),
+		Entry("with a normal latency clamped to the min and max",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Normal,
+				Mean: duration(time.Second), StdDev: duration(time.Second),
+				Min: duration(500 * time.Millisecond), Max: duration(1500 * time.Millisecond)},
+			And(BeNumerically(">=", 500*time.Millisecond), BeNumerically("<=", 1500*time.Millisecond))),
+		Entry("with a normal latency never negative",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Normal,
+				Mean: duration(time.Millisecond), StdDev: duration(time.Second)},
+			BeNumerically(">=", 0)),
+		Entry("with a long-tail latency of zero mean",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.LongTail,
+				StdDev: duration(time.Second)},
+			BeZero()),
+		Entry("with a long-tail latency of zero mean raised to the min",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.LongTail,
+				StdDev: duration(time.Second), Min: duration(time.Second)},
+			Equal(time.Second)),
+		Entry("with a long-tail latency clamped to the max",
+			&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.LongTail,
+				Mean: duration(time.Second), StdDev: duration(4 * time.Second), Max: duration(3 * time.Second)},
+			And(BeNumerically(">", 0), BeNumerically("<=", 3*time.Second))),
+	)
+
+	It("clamps the negative samples of a normal latency to the min", func() {
+		latencies := sample(&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Normal,
+			Mean: duration(time.Millisecond), StdDev: duration(time.Second), Min: duration(100 * time.Millisecond)}, 200)
+		Expect(latencies).To(HaveEach(BeNumerically(">=", 100*time.Millisecond)))
+		Expect(latencies).To(ContainElement(Equal(100 * time.Millisecond)))
+		Expect(latencies).To(ContainElement(BeNumerically(">", 100*time.Millisecond)))
+	})
+
+	It("draws a long tail above the mean", func() {
+		latencies := sample(&pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.LongTail,
+			Mean: duration(time.Second), StdDev: duration(2 * time.Second)}, 1000)
+		var total time.Duration
+		for _, latency := range latencies {
+			total += latency
+		}
+		Expect(total / time.Duration(len(latencies))).To(BeNumerically("~", time.Second, 500*time.Millisecond))
+		Expect(latencies).To(ContainElement(BeNumerically(">", 4*time.Second)))
+	})
+
+	It("draws the same latencies for the same seed", func() {
+		config := &pluginv1alpha1.LatencyConfig{Distribution: pluginv1alpha1.LatencyDistributions.Normal,
+			Mean: duration(time.Second), StdDev: duration(time.Second)}
+		Expect(sample(config, 50)).To(Equal(sample(config, 50)))
+	})
+})
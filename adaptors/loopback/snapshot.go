@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// snapshotResources builds the nodelist resources holding the nodes of an inventory snapshot, all of them free, in the
+// resource pools of the snapshot. Nodes without a resource pool are not imported. BMC credentials are not captured in
+// a snapshot, so the nodes have none.
+func snapshotResources(snapshot *utils.InventorySnapshot) cmResources {
+	resources := cmResources{
+		SchemaVersion: resourcesSchema.Version(),
+		Nodes:         make(map[string]cmNodeInfo),
+	}
+
+	for _, pools := range snapshot.ResourcePools {
+		resources.ResourcePools = append(resources.ResourcePools, pools...)
+	}
+
+	for _, node := range snapshot.Nodes {
+		if node.ResourcePoolId == "" {
+			continue
+		}
+		resources.ResourcePools = append(resources.ResourcePools, node.ResourcePoolId)
+
+		info := cmNodeInfo{
+			ResourcePoolID: node.ResourcePoolId,
+			Interfaces:     node.Interfaces,
+			Failed:         node.Failed,
+			CPUs:           node.CPUs,
+			MemoryGiB:      node.MemoryGiB,
+			NICModels:      node.NICModels,
+			Location:       node.Location,
+			Site:           node.Site,
+		}
+		if node.BMCAddress != "" {
+			info.BMC = &cmBmcInfo{Address: node.BMCAddress}
+		}
+		resources.Nodes[node.NodeId] = info
+	}
+
+	slices.Sort(resources.ResourcePools)
+	resources.ResourcePools = slices.Compact(resources.ResourcePools)
+
+	return resources
+}
+
+// ImportSnapshot replaces the inventory of the nodelist configmap with the nodes of an inventory snapshot, as free
+// nodes, returning the number of nodes imported. The allocations of the snapshot are reproduced by recreating its
+// NodePools with the allocated nodes listed in their adoptNodes extension. The import is rejected if the configmap
+// holds any allocations, as the allocated nodes would no longer exist.
+func ImportSnapshot(ctx context.Context, c client.Client, namespace string, snapshot *utils.InventorySnapshot) (int, error) {
+	resources := snapshotResources(snapshot)
+	resourcesData, err := yaml.Marshal(&resources)
+	if err != nil {
+		return 0, fmt.Errorf("unable to marshal resources: %w", err)
+	}
+	allocationsData, err := yaml.Marshal(&cmAllocations{SchemaVersion: allocationsSchema.Version()})
+	if err != nil {
+		return 0, fmt.Errorf("unable to marshal allocations: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: cmName, Namespace: namespace}, cm); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to get configmap %s: %w", cmName, err)
+		}
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: cmName, Namespace: namespace},
+			Data: map[string]string{
+				resourcesKey:   string(resourcesData),
+				allocationsKey: string(allocationsData),
+			},
+		}
+		if err := c.Create(ctx, cm); err != nil {
+			return 0, fmt.Errorf("failed to create configmap %s: %w", cmName, err)
+		}
+		return len(resources.Nodes), nil
+	}
+
+	if data, exists := cm.Data[allocationsKey]; exists {
+		var allocations cmAllocations
+		if _, err := allocationsSchema.Decode([]byte(data), &allocations); err != nil {
+			return 0, fmt.Errorf("failed to decode allocations from configmap %s: %w", cmName, err)
+		}
+		if len(allocations.Clouds) > 0 {
+			return 0, fmt.Errorf("configmap %s has allocations for %d clouds, which must be released before the import",
+				cmName, len(allocations.Clouds))
+		}
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[resourcesKey] = string(resourcesData)
+	cm.Data[allocationsKey] = string(allocationsData)
+	if err := c.Update(ctx, cm); err != nil {
+		return 0, fmt.Errorf("failed to update configmap %s: %w", cmName, err)
+	}
+
+	return len(resources.Nodes), nil
+}
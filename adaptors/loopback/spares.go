@@ -0,0 +1,191 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReconcileSpares replaces failed nodes in the NodePool with ready spares, then tops up the spares of each nodegroup
+// from its spare pool, reporting the ready spare counts in the NodePool status
+func (a *Adaptor) ReconcileSpares(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	config, err := utils.GetNodePoolSpareConfig(nodepool)
+	if err != nil {
+		return fmt.Errorf("invalid spare node configuration: %w", err)
+	}
+	if len(config) == 0 {
+		return nil
+	}
+
+	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+	if allocations.getCloud(nodepool.Spec.CloudID) == nil {
+		// Spares are only maintained once the nodepool has been allocated
+		return nil
+	}
+
+	if err := a.replaceFailedNodes(ctx, hwmgr, nodepool, resources); err != nil {
+		return err
+	}
+
+	preference, err := utils.GetNodePoolLocalityPreference(nodepool)
+	if err != nil {
+		return fmt.Errorf("invalid locality preference: %w", err)
+	}
+
+	// Spares must be able to stand in for the nodes of the group
+	selectors := make(map[string]*utils.NodeSelector)
+	for groupname := range config {
+		if selectors[groupname], err = utils.GetNodeGroupNodeSelector(nodepool, groupname); err != nil {
+			return fmt.Errorf("invalid node selector: %w", err)
+		}
+	}
+
+	// Top up the spares for each nodegroup
+	var ready map[string]int
+	if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, resources cmResources, allocations *cmAllocations) (bool, error) {
+		ready = make(map[string]int)
+		cloud := allocations.getCloud(nodepool.Spec.CloudID)
+		if cloud == nil {
+			return false, nil
+		}
+		if cloud.Spares == nil {
+			cloud.Spares = make(map[string][]string)
+		}
+
+		changed := false
+		for groupname, spares := range config {
+			for len(cloud.Spares[groupname]) < spares.Count {
+				freenodes := a.getFreeNodesInPool(hwmgr, resources, *allocations, cloud, spares.SparePoolId, selectors[groupname])
+				// Spares are held to the same locality preference as the nodes they stand in for
+				freenodes, err := utils.FilterLocalCandidates(preference, nodepool, freenodes, func(nodeId string) utils.NodeLocality {
+					return resources.Nodes[nodeId].locality()
+				})
+				if err != nil {
+					freenodes = nil
+				}
+				if len(freenodes) == 0 {
+					a.Logger.InfoContext(ctx, "Insufficient free nodes for spares",
+						slog.String("nodegroup", groupname),
+						slog.String("sparePoolId", spares.SparePoolId))
+					break
+				}
+				cloud.Spares[groupname] = append(cloud.Spares[groupname], freenodes[0])
+				changed = true
+			}
+			ready[groupname] = len(cloud.Spares[groupname])
+		}
+		return changed, nil
+	}); err != nil {
+		return fmt.Errorf("failed to top up spares for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if err := utils.UpdateNodePoolSparesCondition(ctx, a.Client, nodepool, config, ready); err != nil {
+		return fmt.Errorf("failed to update spares status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
+
+// replaceFailedNodes swaps a spare into each failed node of the NodePool. The Node CR is retained, updated with the
+// details of the spare, so the swap is transparent to the consumer other than the change of BMC and interfaces.
+func (a *Adaptor) replaceFailedNodes(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	resources cmResources) error {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		failedId := node.Spec.HwMgrNodeId
+		if !resources.Nodes[failedId].Failed && !utils.IsNodeFailed(node) {
+			continue
+		}
+
+		// Claim the spare in the configmap before updating the node
+		var spareId string
+		var info cmNodeInfo
+		if err := a.updateAllocations(ctx, func(_ *corev1.ConfigMap, resources cmResources, allocations *cmAllocations) (bool, error) {
+			spareId = ""
+			cloud := allocations.getCloud(nodepool.Spec.CloudID)
+			if cloud == nil || len(cloud.Spares[node.Spec.GroupName]) == 0 {
+				return false, nil
+			}
+
+			spares := cloud.Spares[node.Spec.GroupName]
+			var exists bool
+			if info, exists = resources.Nodes[spares[0]]; !exists {
+				return false, fmt.Errorf("unable to find nodeinfo for spare %s", spares[0])
+			}
+			spareId = spares[0]
+
+			if cloud.Replaced == nil {
+				cloud.Replaced = make(map[string]string)
+			}
+			cloud.Spares[node.Spec.GroupName] = spares[1:]
+			cloud.Replaced[node.Name] = spareId
+			cloud.Retired = append(cloud.Retired, failedId)
+			allocations.recordAllocation(spareId)
+			return true, nil
+		}); err != nil {
+			return fmt.Errorf("failed to claim spare for node %s: %w", node.Name, err)
+		}
+		if spareId == "" {
+			a.Logger.InfoContext(ctx, "No spare available to replace failed node",
+				slog.String("nodename", node.Name),
+				slog.String("nodeId", failedId))
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Replaced failed node with spare",
+			slog.String("nodename", node.Name),
+			slog.String("nodeId", failedId),
+			slog.String("spareId", spareId))
+
+		if err := a.CreateBMCSecret(ctx, nodepool, node.Name, node.Spec.GroupName, info.BMC.UsernameBase64, info.BMC.PasswordBase64); err != nil {
+			return fmt.Errorf("failed to update bmc-secret for node %s: %w", node.Name, err)
+		}
+
+		patch := client.MergeFrom(node.DeepCopy())
+		node.Spec.HwMgrNodeId = spareId
+		if err := a.Client.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to patch Node %s: %w", node.Name, err)
+		}
+
+		storage := utils.GetHwProfileStorageLayout(hwmgr, node.Spec.HwProfile)
+		if _, err := a.UpdateNodeStatus(ctx, hwmgr, node.Namespace, node.Name, info, node.Spec.HwProfile, storage); err != nil {
+			return fmt.Errorf("failed to update node status (%s): %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
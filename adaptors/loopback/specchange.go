@@ -0,0 +1,240 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// specChangeTarget is a node to be updated by a spec change, with the hardware profile of its nodegroup
+type specChangeTarget struct {
+	node      *hwmgmtv1alpha1.Node
+	hwprofile string
+}
+
+// HandleNodePoolSpecChanged applies a spec change of a provisioned NodePool as a simulated multi-step workflow: the
+// change is validated against the nodes, staged, then applied to one node at a time, with a simulated delay before
+// each step. The progress is recorded in the specChangeProgress annotation of the NodePool and reported in its
+// Configured condition, so that the progress handling of clients can be tested.
+func (a *Adaptor) HandleNodePoolSpecChanged(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	progress, err := utils.GetSpecChangeProgress(nodepool)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "Restarting spec change", slog.String("error", err.Error()))
+	}
+	if progress == nil {
+		a.Logger.InfoContext(ctx, "Starting spec change", slog.Int64("generation", nodepool.Generation))
+		return a.recordSpecChangeProgress(ctx, hwmgr, nodepool, utils.NewSpecChangeProgress(nodepool))
+	}
+
+	if progress.IsDone() {
+		// A failed change is held until the NodePool spec is changed again
+		return utils.DoNotRequeue(), nil
+	}
+	if wait := time.Until(progress.NextStep); wait > 0 {
+		return utils.RequeueWithCustomInterval(wait), nil
+	}
+
+	switch progress.Phase {
+	case utils.SpecChangePhases.Validating:
+		if err := a.validateSpecChange(ctx, hwmgr, nodepool); err != nil {
+			a.Logger.InfoContext(ctx, "Spec change failed validation", slog.String("error", err.Error()))
+			progress.Phase = utils.SpecChangePhases.Failed
+			progress.Message = err.Error()
+		} else {
+			progress.Phase = utils.SpecChangePhases.Staging
+		}
+	case utils.SpecChangePhases.Staging:
+		targets, err := a.getSpecChangeTargets(ctx, nodepool)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		progress.Nodes = nil
+		for _, target := range targets {
+			progress.Nodes = append(progress.Nodes, target.node.Name)
+		}
+		progress.Phase = utils.SpecChangePhases.Applying
+	case utils.SpecChangePhases.Applying:
+		if progress.Applied < len(progress.Nodes) {
+			nodename := progress.Nodes[progress.Applied]
+			if err := a.applySpecChange(ctx, hwmgr, nodepool, nodename); err != nil {
+				return utils.RequeueWithShortInterval(), fmt.Errorf("failed to apply spec change to node %s: %w", nodename, err)
+			}
+			progress.Applied++
+		}
+		if progress.Applied == len(progress.Nodes) {
+			progress.Phase = utils.SpecChangePhases.Completed
+		}
+	}
+
+	return a.recordSpecChangeProgress(ctx, hwmgr, nodepool, progress)
+}
+
+// recordSpecChangeProgress schedules the next step of the spec change, recording its progress on the NodePool and in
+// the Configured condition. The observed generation is updated once the change has been applied.
+func (a *Adaptor) recordSpecChangeProgress(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	progress *utils.SpecChangeProgress) (ctrl.Result, error) {
+
+	result := utils.DoNotRequeue()
+	if !progress.IsDone() {
+		delay := a.getSimulator(ctx, hwmgr).specChangeDelay(hwmgr.Spec.LoopbackData)
+		progress.NextStep = time.Now().Add(delay)
+		result = utils.RequeueWithCustomInterval(delay)
+		if delay <= 0 {
+			result = utils.RequeueImmediately()
+		}
+	}
+
+	if err := utils.SetSpecChangeProgress(ctx, a.Client, nodepool, progress); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	a.Logger.InfoContext(ctx, "Spec change progress",
+		slog.String("phase", string(progress.Phase)),
+		slog.Int("applied", progress.Applied),
+		slog.Int("nodes", len(progress.Nodes)))
+
+	builder := utils.NewNodePoolStatusBuilder(nodepool)
+	switch progress.Phase {
+	case utils.SpecChangePhases.Completed:
+		builder.WithConfigApplied().WithObservedGeneration()
+	case utils.SpecChangePhases.Failed:
+		builder.WithCondition(hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.Failed, metav1.ConditionFalse, progress.Describe())
+	case utils.SpecChangePhases.Validating:
+		builder.WithCondition(hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.ConfigUpdate, metav1.ConditionFalse, progress.Describe())
+	default:
+		builder.WithCondition(hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.InProgress, metav1.ConditionFalse, progress.Describe())
+	}
+	if err := builder.Update(ctx, a.Client); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return result, nil
+}
+
+// getSpecChangeTargets returns the nodes of the NodePool whose hardware profile differs from that of their nodegroup
+func (a *Adaptor) getSpecChangeTargets(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) ([]specChangeTarget, error) {
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	var targets []specChangeTarget
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			if node.Spec.GroupName == nodegroup.NodePoolData.Name && node.Spec.HwProfile != nodegroup.NodePoolData.HwProfile {
+				targets = append(targets, specChangeTarget{node: node, hwprofile: nodegroup.NodePoolData.HwProfile})
+				break
+			}
+		}
+	}
+	return targets, nil
+}
+
+// validateSpecChange checks that each node to be updated is able to satisfy the hardware profile of its nodegroup
+func (a *Adaptor) validateSpecChange(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	targets, err := a.getSpecChangeTargets(ctx, nodepool)
+	if err != nil {
+		return err
+	}
+
+	_, resources, _, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	for _, target := range targets {
+		info, exists := resources.Nodes[target.node.Spec.HwMgrNodeId]
+		if !exists {
+			return fmt.Errorf("unable to find nodeinfo for node %s", target.node.Name)
+		}
+		if err := utils.CheckHwProfileCompatibility(hwmgr, target.hwprofile, info.capabilities()); err != nil {
+			return fmt.Errorf("node %s is incompatible with hardware profile %s: %w", target.node.Name, target.hwprofile, err)
+		}
+	}
+	return nil
+}
+
+// applySpecChange updates the hardware profile of a staged node to that of its nodegroup, applying the profile in
+// the node status
+func (a *Adaptor) applySpecChange(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodename string) error {
+
+	node, err := utils.GetNode(ctx, a.Logger, a.Client, nodepool.Namespace, nodename)
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %w", nodename, err)
+	}
+
+	hwprofile := node.Spec.HwProfile
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if node.Spec.GroupName == nodegroup.NodePoolData.Name {
+			hwprofile = nodegroup.NodePoolData.HwProfile
+			break
+		}
+	}
+
+	if node.Spec.HwProfile != hwprofile {
+		patch := client.MergeFrom(node.DeepCopy())
+		node.Spec.HwProfile = hwprofile
+		if err := a.Client.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to patch Node %s in namespace %s: %w", node.Name, node.Namespace, err)
+		}
+	}
+
+	_, resources, _, err := a.GetCurrentResources(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+	info, exists := resources.Nodes[node.Spec.HwMgrNodeId]
+	if !exists {
+		return fmt.Errorf("unable to find nodeinfo for node %s", node.Name)
+	}
+
+	a.Logger.InfoContext(ctx, "Applying hardware profile to node",
+		slog.String("nodename", node.Name),
+		slog.String("hwprofile", hwprofile))
+
+	storage := utils.GetHwProfileStorageLayout(hwmgr, hwprofile)
+	if _, err := a.UpdateNodeStatus(ctx, hwmgr, node.Namespace, node.Name, info, hwprofile, storage); err != nil {
+		return fmt.Errorf("failed to update node status (%s): %w", node.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// recordNodePoolPlan records the plan of the changes to be made to the nodes of the NodePool for a spec change, ahead
+// of the change being applied by the adaptor, so that it can be reviewed
+func (c *HwMgrAdaptorController) recordNodePoolPlan(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if !utils.IsNodePoolPlanDue(nodepool) {
+		return nil
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, c.Logger, c.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	plan := utils.ComputeNodePoolPlan(nodepool, nodelist)
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	if err := utils.SetNodePoolPlan(nodepool, plan); err != nil {
+		return err
+	}
+	if err := c.Client.Patch(ctx, nodepool, patch); err != nil {
+		return fmt.Errorf("failed to record plan on NodePool %s: %w", nodepool.Name, err)
+	}
+
+	c.Logger.InfoContext(ctx, "Recorded NodePool plan",
+		slog.Int64("generation", plan.Generation),
+		slog.Any("nodeGroups", plan.NodeGroups))
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adaptors
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var rbacMarker = regexp.MustCompile(`^//\s*\+kubebuilder:rbac:(.+)$`)
+
+// getMarkerPermissions returns the permissions granted by the kubebuilder RBAC markers of the Go sources in dir, as a
+// set of group/resource/verb, descending into the subpackages if recursive
+func getMarkerPermissions(dir string, recursive bool) map[string]bool {
+	permissions := make(map[string]bool)
+	Expect(filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err // nolint: wrapcheck
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			match := rbacMarker.FindStringSubmatch(strings.TrimSpace(line))
+			if match == nil {
+				continue
+			}
+			fields := make(map[string][]string)
+			for _, field := range strings.Split(match[1], ",") {
+				key, value, _ := strings.Cut(field, "=")
+				fields[key] = strings.Split(strings.Trim(value, `"`), ";")
+			}
+			for _, group := range fields["groups"] {
+				for _, resource := range fields["resources"] {
+					for _, verb := range fields["verbs"] {
+						permissions[group+"/"+resource+"/"+verb] = true
+					}
+				}
+			}
+		}
+		return nil
+	})).To(Succeed())
+	return permissions
+}
+
+// getRolePermissions returns the permissions granted by the ClusterRole manifest, as a set of group/resource/verb
+func getRolePermissions(path string) map[string]bool {
+	data, err := os.ReadFile(path)
+	Expect(err).ToNot(HaveOccurred())
+
+	var role rbacv1.ClusterRole
+	Expect(yaml.Unmarshal(data, &role)).To(Succeed())
+
+	permissions := make(map[string]bool)
+	for _, rule := range role.Rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, verb := range rule.Verbs {
+					permissions[group+"/"+resource+"/"+verb] = true
+				}
+			}
+		}
+	}
+	return permissions
+}
+
+var _ = Describe("Adaptor RBAC", func() {
+	It("grants each adaptor role only the permissions of its own package", func() {
+		for _, adaptorID := range SupportedAdaptorIDs {
+			role := getRolePermissions(filepath.Join("..", "config", "rbac", "adaptors", adaptorID, "role.yaml"))
+			Expect(role).To(Equal(getMarkerPermissions(adaptorID, true)), "role of adaptor %s", adaptorID)
+		}
+	})
+
+	It("grants the permissions shared by the adaptors in the manager role", func() {
+		shared := getMarkerPermissions(".", false)
+		Expect(shared).ToNot(BeEmpty())
+
+		role := getRolePermissions(filepath.Join("..", "config", "rbac", "role.yaml"))
+		for permission := range shared {
+			Expect(role).To(HaveKey(permission))
+		}
+	})
+})
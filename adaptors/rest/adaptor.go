@@ -0,0 +1,278 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/rest/controller"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/rest/restclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+type Adaptor struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Logger    *slog.Logger
+	Namespace string
+	AdaptorID pluginv1alpha1.HardwareManagerAdaptorID
+}
+
+func NewAdaptor(client client.Client, scheme *runtime.Scheme, logger *slog.Logger, namespace string) *Adaptor {
+	return &Adaptor{
+		Client:    client,
+		Scheme:    scheme,
+		Logger:    logger.With("adaptor", "rest"),
+		Namespace: namespace,
+	}
+}
+
+// SetupAdaptor sets up the Rest Adaptor
+func (a *Adaptor) SetupAdaptor(mgr ctrl.Manager) error {
+	a.Logger.Info("SetupAdaptor called for Rest")
+
+	if err := (&controller.HardwareManagerReconciler{
+		Client:    a.Client,
+		Scheme:    a.Scheme,
+		Logger:    a.Logger,
+		Namespace: a.Namespace,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to setup rest adaptor: %w", err)
+	}
+
+	return nil
+}
+
+type fsmAction int
+
+const (
+	NodePoolFSMCreate = iota
+	NodePoolFSMProcessing
+	NodePoolFSMSpecChanged
+	NodePoolFSMExtend
+	NodePoolFSMNoop
+)
+
+func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (fsmAction, error) {
+	if len(nodepool.Status.Conditions) == 0 {
+		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
+		return NodePoolFSMCreate, nil
+	}
+
+	provisionedCondition := meta.FindStatusCondition(
+		nodepool.Status.Conditions,
+		string(hwmgmtv1alpha1.Provisioned))
+
+	if provisionedCondition != nil {
+		if provisionedCondition.Status == metav1.ConditionTrue {
+			// Check if the generation has changed
+			if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
+				// A spec change that grows the nodegroups is handled as an extension, adding the new nodes
+				nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+				if err != nil {
+					return NodePoolFSMNoop, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+				}
+				if utils.IsNodePoolExtension(nodepool, nodelist) {
+					a.Logger.InfoContext(ctx, "Handling NodePool extension")
+					return NodePoolFSMExtend, nil
+				}
+				a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
+				return NodePoolFSMSpecChanged, nil
+			}
+			a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
+			return NodePoolFSMNoop, nil
+		}
+
+		if provisionedCondition.Reason == string(hwmgmtv1alpha1.Failed) {
+			a.Logger.InfoContext(ctx, "NodePool request in Failed state")
+			return NodePoolFSMNoop, nil
+		}
+
+		return NodePoolFSMProcessing, nil
+	}
+
+	return NodePoolFSMNoop, nil
+}
+
+func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	result := utils.DoNotRequeue()
+
+	restClient, clientErr := restclient.NewRestClient(ctx, a.Logger, a.Client, hwmgr)
+	if clientErr != nil {
+		a.Logger.InfoContext(ctx, "NewRestClient error", slog.String("error", clientErr.Error()))
+		return result, fmt.Errorf("failed to setup rest client: %w", clientErr)
+	}
+
+	action, err := a.determineAction(ctx, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	switch action {
+	case NodePoolFSMCreate:
+		return a.HandleNodePoolCreate(ctx, nodepool)
+	case NodePoolFSMProcessing:
+		return a.HandleNodePoolProcessing(ctx, restClient, hwmgr, nodepool)
+	case NodePoolFSMSpecChanged:
+		return a.HandleNodePoolSpecChanged(ctx, restClient, nodepool)
+	case NodePoolFSMExtend:
+		return a.HandleNodePoolExtend(ctx, restClient, hwmgr, nodepool)
+	case NodePoolFSMNoop:
+		if utils.IsNodePoolProvisionedCompleted(nodepool) {
+			// Resync the node hardware details from the backend, if enabled and due
+			if utils.IsNodeResyncDue(hwmgr, nodepool) {
+				if err := a.ResyncNodeHardware(ctx, restClient, hwmgr, nodepool); err != nil {
+					a.Logger.InfoContext(ctx, "Failed to resync node hardware", slog.String("error", err.Error()))
+				} else if err := utils.SetNodeResyncTime(ctx, a.Client, nodepool); err != nil {
+					a.Logger.InfoContext(ctx, "Failed to record node resync time", slog.String("error", err.Error()))
+				}
+			}
+			return utils.RequeueWithLongInterval(), nil
+		}
+		return result, nil
+	}
+
+	return result, nil
+}
+
+func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	a.Logger.InfoContext(ctx, "Finalizing nodepool")
+
+	restClient, clientErr := restclient.NewRestClient(ctx, a.Logger, a.Client, hwmgr)
+	if clientErr != nil {
+		a.Logger.InfoContext(ctx, "NewRestClient error", slog.String("error", clientErr.Error()))
+		return fmt.Errorf("failed to setup rest client: %w", clientErr)
+	}
+
+	if err := a.ReleaseNodePool(ctx, restClient, hwmgr, nodepool); err != nil {
+		return fmt.Errorf("failed to release nodepool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
+
+// RetainNodePool is called for a NodePool deleted under the Retain deletion policy. The nodes are left allocated on
+// the backend, with nothing to record.
+func (a *Adaptor) RetainNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	return nil
+}
+
+// RestoreNodeBMCSecret re-creates the bmc-secret for a node from the credentials reported by the backend
+func (a *Adaptor) RestoreNodeBMCSecret(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+
+	restClient, clientErr := restclient.NewRestClient(ctx, a.Logger, a.Client, hwmgr)
+	if clientErr != nil {
+		a.Logger.InfoContext(ctx, "NewRestClient error", slog.String("error", clientErr.Error()))
+		return fmt.Errorf("failed to setup rest client: %w", clientErr)
+	}
+
+	// The node details are served from the inventory cache, as the secret may be restored repeatedly
+	info, err := sdk.CachedInventoryQuery(hwmgr, "node/"+node.Spec.HwMgrNodeId, func() (*restclient.NodeInfo, error) {
+		return restClient.GetNode(ctx, allocatedNodeRequestParams(nodepool, node)) // nolint: wrapcheck
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get details for node %s: %w", node.Name, err)
+	}
+
+	return a.CreateBMCSecret(ctx, nodepool, node.Name, node.Spec.GroupName, info.BmcUsername, info.BmcPassword)
+}
+
+// Capabilities reports the features of the rest adaptor. Hardware profile changes, which may apply firmware and BIOS
+// settings, are only supported if the backend description defines an updateNode endpoint.
+func (a *Adaptor) Capabilities(hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.AdaptorCapability {
+	capabilities := []pluginv1alpha1.AdaptorCapability{pluginv1alpha1.CapabilityScaleUp}
+	if hwmgr.Spec.RestData != nil && hwmgr.Spec.RestData.Endpoints.UpdateNode != nil {
+		capabilities = append(capabilities, pluginv1alpha1.CapabilityFirmwareUpdate, pluginv1alpha1.CapabilityBIOSConfig)
+	}
+	return capabilities
+}
+
+// GetCapacity is not supported by the rest adaptor, as the declarative API does not describe the free nodes
+func (a *Adaptor) GetCapacity(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*pluginv1alpha1.CapacityStatus, error) {
+	return nil, nil
+}
+
+// PlanConsolidation is not supported by the rest adaptor, as the declarative API has no endpoint to migrate an
+// allocation to a different node
+func (a *Adaptor) PlanConsolidation(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, resourcePoolIds []string) ([]pluginv1alpha1.ConsolidationMove, error) {
+	return nil, sdk.ErrNotSupported
+}
+
+// ExecuteConsolidationMove is not supported by the rest adaptor
+func (a *Adaptor) ExecuteConsolidationMove(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, move *pluginv1alpha1.ConsolidationMove) error {
+	return sdk.ErrNotSupported
+}
+
+// DecommissionNode is not supported by the rest adaptor, as the declarative API has no endpoint to power down or wipe a node
+func (a *Adaptor) DecommissionNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	report *utils.DecommissionReport) error {
+	return sdk.ErrNotSupported
+}
+
+// ReleaseNode is not supported by the rest adaptor, as the declarative API has no endpoint to shrink an allocation
+func (a *Adaptor) ReleaseNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+	return sdk.ErrNotSupported
+}
+
+// GetFreeNodes is not supported by the rest adaptor, as the declarative API does not describe the free nodes
+func (a *Adaptor) GetFreeNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, query utils.FreeNodeQuery) ([]utils.FreeNode, error) {
+	return nil, sdk.ErrNotSupported
+}
+
+// SetNodePowerState is not supported by the rest adaptor, as the declarative API has no endpoint to control the power
+// of a node
+func (a *Adaptor) SetNodePowerState(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	state utils.PowerState) error {
+	return sdk.ErrNotSupported
+}
+
+// CheckAllocations is not supported by the rest adaptor, as the allocation records are held by the backend, which resolves an interrupted
+// allocation when the NodePool is next processed
+func (a *Adaptor) CheckAllocations(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodes []hwmgmtv1alpha1.Node) ([]pluginv1alpha1.ConsistencyIssue, error) {
+	return nil, sdk.ErrNotSupported
+}
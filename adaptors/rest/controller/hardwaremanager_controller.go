@@ -0,0 +1,236 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/rest/restclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// HardwareManagerReconciler reconciles a HardwareManager object
+type HardwareManagerReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Logger    *slog.Logger
+	Namespace string
+	AdaptorID pluginv1alpha1.HardwareManagerAdaptorID
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
+func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	_ = log.FromContext(ctx)
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	// Fetch the CR:
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			// The HardwareManager has likely been deleted
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(
+			ctx,
+			"Unable to fetch HardwareManager",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	// Make sure this is an instance for this adaptor
+	if hwmgr.Spec.AdaptorID != r.AdaptorID {
+		// Skip this CR
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	// Changes to the credentials are detected by checksum, so the backend connection is re-validated promptly
+	configChanged := hwmgr.Status.ObservedGeneration != 0 && hwmgr.Status.ObservedGeneration != hwmgr.Generation
+	checksum, checksumErr := utils.GetAuthSecretChecksum(ctx, r.Client, hwmgr)
+	if checksumErr != nil {
+		r.Logger.InfoContext(ctx, "Unable to compute auth secret checksum", slog.String("error", checksumErr.Error()))
+	} else if utils.IsAuthSecretChanged(hwmgr, checksum) && hwmgr.GetAnnotations()[utils.AuthSecretChecksumAnnotation] != "" {
+		r.Logger.InfoContext(ctx, "Auth secret changed, re-validating backend connection")
+		configChanged = true
+	}
+
+	// Rotated CA certificates are likewise detected by checksum. In-flight operations complete with the clients they
+	// were started with, as each new backend client is built with the current CA bundles.
+	caChecksum, caChecksumErr := utils.GetCaBundleChecksum(ctx, r.Client, hwmgr)
+	if caChecksumErr != nil {
+		r.Logger.InfoContext(ctx, "Unable to compute CA bundle checksum", slog.String("error", caChecksumErr.Error()))
+	} else if utils.IsCaBundleChanged(hwmgr, caChecksum) && hwmgr.GetAnnotations()[utils.CaBundleChecksumAnnotation] != "" {
+		r.Logger.InfoContext(ctx, "CA bundle changed, re-validating backend connection")
+		configChanged = true
+	}
+
+	if configChanged {
+		// Backend clients are built from the current spec on each use, so only the failures and certificate recorded
+		// against the previous configuration need to be discarded for the change to take effect
+		r.Logger.InfoContext(ctx, "HardwareManager configuration changed, resetting backend circuit breaker")
+		sdk.ResetCircuitBreaker(hwmgr.Name)
+		sdk.ResetBackendCertificate(hwmgr.Name)
+	}
+
+	hwmgr.Status.ObservedGeneration = hwmgr.Generation
+
+	if validationErr := restclient.ValidateRestData(hwmgr.Spec.RestData); validationErr != nil {
+		// Invalid data, which is not retried until the CR is updated
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			"Invalid restData configuration - "+validationErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with validation failure: %w", hwmgr.Name, updateErr)
+			return
+		}
+		r.Logger.ErrorContext(ctx, "HardwareManager CR has invalid restData configuration",
+			slog.String("name", hwmgr.Name), slog.String("error", validationErr.Error()))
+		return
+	}
+
+	result = utils.RequeueWithLongInterval()
+
+	r.Logger.InfoContext(ctx, "Validating client connection", slog.String("apiUrl", hwmgr.Spec.RestData.ApiUrl))
+
+	client, clientErr := restclient.NewRestClient(ctx, r.Logger, r.Client, hwmgr)
+	if clientErr != nil {
+		r.Logger.InfoContext(ctx, "NewRestClient error", slog.String("error", clientErr.Error()))
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			"Authentication failure - "+clientErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with authentication failure: %w", hwmgr.Name, updateErr)
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Failed to setup client for hardware manager", slog.String("name", hwmgr.Name), slog.String("error", clientErr.Error()))
+		return
+	}
+
+	pools, clientErr := client.GetResourcePools(ctx)
+	if clientErr != nil {
+		r.Logger.InfoContext(ctx, "GetResourcePools error", slog.String("error", clientErr.Error()))
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			"Failed to query resource pools - "+clientErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with resource pool query failure: %w", hwmgr.Name, updateErr)
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Failed to query resource pools", slog.String("name", hwmgr.Name), slog.String("error", clientErr.Error()))
+		return
+	}
+
+	if pools != nil {
+		// The declarative API does not describe sites, so the pools are reported under a single default site
+		slices.Sort(pools)
+		hwmgr.Status.ResourcePools = pluginv1alpha1.PerSiteResourcePoolList{restclient.DefaultSite: pools}
+	}
+
+	// The backend certificate is observed by the client during validation, and persisted with the Validation condition
+	if cert, observed := sdk.GetBackendCertificate(hwmgr.Name); observed {
+		utils.SetCertificateExpiringCondition(hwmgr, cert.Subject, cert.NotAfter, time.Now())
+	} else {
+		meta.RemoveStatusCondition(&hwmgr.Status.Conditions, string(pluginv1alpha1.ConditionTypes.CertificateExpiring))
+	}
+
+	if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+		pluginv1alpha1.ConditionTypes.Validation,
+		pluginv1alpha1.ConditionReasons.Completed,
+		metav1.ConditionTrue,
+		"Validated"); updateErr != nil {
+		err = fmt.Errorf("failed to update status for hardware manager (%s) with validation success: %w", hwmgr.Name, updateErr)
+		return
+	}
+
+	if caChecksumErr == nil && utils.IsCaBundleChanged(hwmgr, caChecksum) {
+		if updateErr := utils.SetCaBundleChecksum(ctx, r.Client, hwmgr, caChecksum); updateErr != nil {
+			err = fmt.Errorf("failed to record CA bundle checksum for hardware manager (%s): %w", hwmgr.Name, updateErr)
+			return
+		}
+	}
+
+	if checksumErr == nil && utils.IsAuthSecretChanged(hwmgr, checksum) {
+		if updateErr := utils.SetAuthSecretChecksum(ctx, r.Client, hwmgr, checksum); updateErr != nil {
+			err = fmt.Errorf("failed to record auth secret checksum for hardware manager (%s): %w", hwmgr.Name, updateErr)
+			return
+		}
+	}
+
+	return
+}
+
+func filterEvents(adaptorID pluginv1alpha1.HardwareManagerAdaptorID) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		hwmgr := object.(*pluginv1alpha1.HardwareManager)
+		return hwmgr.Spec.AdaptorID == adaptorID
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HardwareManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.AdaptorID = pluginv1alpha1.SupportedAdaptors.Rest
+	r.Logger.Info("Setting up Rest controller", slog.String("adaptorId", string(r.AdaptorID)))
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named(string(r.AdaptorID)).
+		For(&pluginv1alpha1.HardwareManager{}, builder.WithPredicates(
+			filterEvents(r.AdaptorID),
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(utils.MapAuthSecretToHardwareManagers(r.Client, r.AdaptorID))).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(utils.MapCaBundleToHardwareManagers(r.Client, r.AdaptorID))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to setup controller for %s: %w", r.AdaptorID, err)
+	}
+
+	return nil
+
+}
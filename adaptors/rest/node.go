@@ -0,0 +1,530 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/rest/restclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeRequestParams returns the template fields for a request concerning a node of the nodegroup
+func nodeRequestParams(nodepool *hwmgmtv1alpha1.NodePool, nodegroup hwmgmtv1alpha1.NodeGroup, nodeId string) restclient.RequestParams {
+	return restclient.RequestParams{
+		CloudID:         nodepool.Spec.CloudID,
+		NodePool:        nodepool.Name,
+		Group:           nodegroup.NodePoolData.Name,
+		ResourcePoolId:  nodegroup.NodePoolData.ResourcePoolId,
+		HwProfile:       nodegroup.NodePoolData.HwProfile,
+		NodeId:          nodeId,
+		ExcludedNodeIds: utils.GetNodePoolTimedOutNodes(nodepool),
+	}
+}
+
+// AllocateNodes allocates nodes from the backend for each nodegroup, as needed, creating a Node CR for each. Nodes
+// already allocated in the backend that are listed for adoption are imported first. Each newly allocated node holds a
+// slot of the allocation throttle until it is provisioned, and allocations beyond the limit are queued. The number of
+// queued nodes is returned.
+func (a *Adaptor) AllocateNodes(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	throttle *sdk.AllocationThrottle,
+	nodepool *hwmgmtv1alpha1.NodePool) (int, error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	allocated := make(map[string]int)
+	provisioning := 0
+	for _, node := range nodelist.Items {
+		allocated[node.Spec.GroupName]++
+		if !utils.IsNodeAdopted(&node) && !meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+			provisioning++
+		}
+	}
+
+	// Re-establish the slots held by the nodes of this NodePool that are still being provisioned
+	throttle.Set(nodepool.Name, provisioning)
+
+	namer, err := utils.NewNodeNamer(a.Client, nodepool.Namespace, hwmgr, nodepool)
+	if err != nil {
+		return 0, fmt.Errorf("invalid node naming policy: %w", err)
+	}
+
+	queued := 0
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		adopted, err := a.adoptNodes(ctx, restClient, namer, nodepool, nodegroup, nodelist)
+		if err != nil {
+			return 0, err
+		}
+		allocated[nodegroup.NodePoolData.Name] += adopted
+
+		for allocated[nodegroup.NodePoolData.Name] < nodegroup.Size {
+			if !throttle.TryAcquire(nodepool.Name, 1) {
+				queued += nodegroup.Size - allocated[nodegroup.NodePoolData.Name]
+				break
+			}
+
+			params := nodeRequestParams(nodepool, nodegroup, "")
+			params.Storage = utils.GetHwProfileStorageLayout(hwmgr, nodegroup.NodePoolData.HwProfile)
+			if err := utils.ValidateStorageLayout(params.Storage); err != nil {
+				throttle.Set(nodepool.Name, provisioning)
+				return 0, fmt.Errorf("invalid storage layout for hardware profile %s: %w", nodegroup.NodePoolData.HwProfile, err)
+			}
+			params.Security = utils.GetHwProfileSecurity(hwmgr, nodegroup.NodePoolData.HwProfile)
+
+			nodeId, err := restClient.AllocateNode(ctx, params)
+			if err != nil {
+				throttle.Set(nodepool.Name, provisioning)
+				return 0, fmt.Errorf("failed to allocate node for nodegroup %s: %w", nodegroup.NodePoolData.Name, err)
+			}
+			sdk.InvalidateInventoryCache(hwmgr.Name)
+
+			if utils.IsNodeBlocked(hwmgr, nodegroup.NodePoolData.ResourcePoolId, nodeId) {
+				// The node is selected by the backend, so a blocked node is released and the allocation retried
+				params.NodeId = nodeId
+				if releaseErr := restClient.ReleaseNode(ctx, params); releaseErr != nil {
+					a.Logger.ErrorContext(ctx, "Failed to release blocked node",
+						slog.String("nodeId", nodeId),
+						slog.String("error", releaseErr.Error()))
+				}
+				throttle.Set(nodepool.Name, provisioning)
+				return 0, fmt.Errorf("backend allocated blocked node %s for nodegroup %s", nodeId, nodegroup.NodePoolData.Name)
+			}
+
+			if err := a.createAllocatedNode(ctx, namer, nodepool, nodegroup, nodeId, false); err != nil {
+				// Release the node, so that it is not leaked by the backend
+				params.NodeId = nodeId
+				if releaseErr := restClient.ReleaseNode(ctx, params); releaseErr != nil {
+					a.Logger.ErrorContext(ctx, "Failed to release node after failure to create Node CR",
+						slog.String("nodeId", nodeId),
+						slog.String("error", releaseErr.Error()))
+				}
+				throttle.Set(nodepool.Name, provisioning)
+				return 0, err
+			}
+
+			allocated[nodegroup.NodePoolData.Name]++
+			provisioning++
+		}
+	}
+
+	return queued, nil
+}
+
+// adoptNodes imports the nodes of the nodegroup listed for adoption that do not yet have a Node CR, verifying that each
+// exists in the backend. The number of adopted nodes is returned.
+func (a *Adaptor) adoptNodes(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	namer *utils.NodeNamer,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodegroup hwmgmtv1alpha1.NodeGroup,
+	nodelist *hwmgmtv1alpha1.NodeList) (int, error) {
+
+	nodeIds, err := utils.GetNodeGroupAdoptedNodes(nodepool, nodegroup.NodePoolData.Name)
+	if err != nil {
+		return 0, fmt.Errorf("invalid adopted nodes: %w", err)
+	}
+
+	adopted := 0
+	for _, nodeId := range nodeIds {
+		if utils.FindNodeInList(*nodelist, nodepool.Spec.HwMgrId, nodeId) != "" {
+			// Already adopted
+			continue
+		}
+
+		if _, err := restClient.GetNode(ctx, nodeRequestParams(nodepool, nodegroup, nodeId)); err != nil {
+			return adopted, fmt.Errorf("failed to get details for adopted node %s: %w", nodeId, err)
+		}
+
+		a.Logger.InfoContext(ctx, "Adopting node from existing backend allocation", slog.String("nodeId", nodeId))
+		if err := a.createAllocatedNode(ctx, namer, nodepool, nodegroup, nodeId, true); err != nil {
+			return adopted, err
+		}
+		adopted++
+	}
+
+	return adopted, nil
+}
+
+// createAllocatedNode creates the Node CR for a node allocated from the backend, marking it if adopted from an
+// existing allocation
+func (a *Adaptor) createAllocatedNode(
+	ctx context.Context,
+	namer *utils.NodeNamer,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodegroup hwmgmtv1alpha1.NodeGroup,
+	nodeId string,
+	adopted bool) error {
+
+	nodename, err := namer.Generate(ctx, nodegroup.NodePoolData.Name, nodeId)
+	if err != nil {
+		return fmt.Errorf("failed to generate name for node %s: %w", nodeId, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Creating node",
+		slog.String("nodegroup name", nodegroup.NodePoolData.Name),
+		slog.String("nodename", nodename),
+		slog.String("nodeId", nodeId))
+
+	blockDeletion := true
+	node := &hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nodename,
+			Namespace: nodepool.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         nodepool.APIVersion,
+				Kind:               nodepool.Kind,
+				Name:               nodepool.Name,
+				UID:                nodepool.UID,
+				BlockOwnerDeletion: &blockDeletion,
+			}},
+		},
+		Spec: hwmgmtv1alpha1.NodeSpec{
+			NodePool:    nodepool.Spec.CloudID,
+			GroupName:   nodegroup.NodePoolData.Name,
+			HwProfile:   nodegroup.NodePoolData.HwProfile,
+			HwMgrId:     nodepool.Spec.HwMgrId,
+			HwMgrNodeId: nodeId,
+		},
+	}
+
+	netconfig, err := utils.GetNodeGroupNetworkConfig(nodepool, nodegroup.NodePoolData.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get network config for nodegroup %s: %w", nodegroup.NodePoolData.Name, err)
+	}
+
+	if err := utils.SetNodeNetworkConfig(node, netconfig); err != nil {
+		return fmt.Errorf("failed to set network config for node %s: %w", nodename, err)
+	}
+
+	if err := utils.SetNodeMetadata(node, nodepool, nodegroup.NodePoolData.Name); err != nil {
+		return fmt.Errorf("failed to set metadata for node %s: %w", nodename, err)
+	}
+
+	if adopted {
+		utils.SetNodeAdopted(node)
+	}
+
+	if err := a.Client.Create(ctx, node); err != nil {
+		return fmt.Errorf("failed to create Node %s: %w", nodename, err)
+	}
+
+	return nil
+}
+
+// UpdateAllocatedNodes queries the backend for the details of each allocated node that is not yet provisioned. Once
+// the node is ready, its bmc-secret is created and the Node CR status is updated, with the node marked as provisioned
+// once it passes the readiness checks of the hardware manager. A node that is not ready within the
+// provisioning timeout is released and replaced, within the retry budget, or otherwise marked as timed out. The number
+// of nodes that are not yet ready, including those being replaced, and the number of nodes that timed out without
+// retry are returned.
+func (a *Adaptor) UpdateAllocatedNodes(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (pending, timedOut int, err error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	checker := sdk.NewReadinessChecker(hwmgr)
+	prober := sdk.NewBMCProber(a.Client, hwmgr)
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		provisionedCondition := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		if provisionedCondition != nil && provisionedCondition.Status == metav1.ConditionTrue {
+			continue
+		}
+		if provisionedCondition != nil && provisionedCondition.Reason == string(utils.ReasonTimeout) {
+			timedOut++
+			continue
+		}
+
+		info, err := restClient.GetNode(ctx, allocatedNodeRequestParams(nodepool, node))
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to get details for node %s: %w", node.Name, err)
+		}
+
+		if !info.Ready {
+			if utils.IsNodeProvisioningTimedOut(hwmgr, node) {
+				retried, err := a.handleNodeProvisioningTimeout(ctx, restClient, hwmgr, nodepool, node)
+				if err != nil {
+					return 0, 0, err
+				}
+				if !retried {
+					timedOut++
+					continue
+				}
+			} else {
+				a.Logger.InfoContext(ctx, "Node is not yet ready", slog.String("nodename", node.Name))
+			}
+			pending++
+			continue
+		}
+
+		if err := a.CreateBMCSecret(ctx, nodepool, node.Name, node.Spec.GroupName, info.BmcUsername, info.BmcPassword); err != nil {
+			return 0, 0, fmt.Errorf("failed to create bmc-secret for node %s: %w", node.Name, err)
+		}
+
+		if err := sdk.PublishNodeAssetInfo(ctx, a.Client, node, info.AssetInfo); err != nil {
+			return 0, 0, err
+		}
+
+		if err := sdk.PublishNodeBootCapabilities(ctx, a.Client, node, info.BootCapabilities); err != nil {
+			return 0, 0, err
+		}
+
+		roles := utils.ResolveInterfaceRoles(info.Interfaces, info.InterfaceRoles, utils.GetHwProfileInterfaceRoles(hwmgr, node.Spec.HwProfile))
+		if err := sdk.PublishNodeInterfaceRoles(ctx, a.Client, node, roles); err != nil {
+			return 0, 0, err
+		}
+
+		if err := sdk.PublishNodeInstallHints(ctx, a.Client, node, info.InstallHints); err != nil {
+			return 0, 0, err
+		}
+
+		if err := sdk.ApplyNodeSecrets(ctx, a.Client, hwmgr, node, info.SecretData); err != nil {
+			return 0, 0, err
+		}
+
+		bmcAddress, err := utils.FormatBMCAddress(hwmgr, node.Spec.HwProfile, info.BmcAddress, info.BmcAddressIPv6)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid BMC address for node %s: %w", node.Name, err)
+		}
+
+		a.Logger.InfoContext(ctx, "Updating node", slog.String("nodename", node.Name))
+		var ready bool
+		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+			node.Status.BMC = &hwmgmtv1alpha1.BMC{
+				Address:         bmcAddress,
+				CredentialsName: utils.BMCSecretName(node.Name),
+			}
+			node.Status.Interfaces = info.Interfaces
+			node.Status.HwProfile = node.Spec.HwProfile
+			if storage := utils.GetHwProfileStorageLayout(hwmgr, node.Spec.HwProfile); storage != nil && !utils.IsNodeAdopted(node) {
+				// The storage layout is configured by the backend on allocation
+				utils.SetNodeStorageCondition(node, storage, nil)
+			}
+			// The security requirements are configured by the backend on allocation, where supported
+			ready = utils.VerifyNodeSecurity(hwmgr, node, info.Security) &&
+				prober.ProbeNode(ctx, node) && checker.MarkNodeProvisionedIfReady(node)
+		}); err != nil {
+			return 0, 0, fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+		}
+		if !ready {
+			a.Logger.InfoContext(ctx, "Node is waiting for readiness checks", slog.String("nodename", node.Name))
+			pending++
+		}
+	}
+
+	return pending, timedOut, nil
+}
+
+// handleNodeProvisioningTimeout marks a node that was not provisioned in time as timed out. If the retry budget of the
+// NodePool allows, the node is released and its Node CR deleted, so that a different node is allocated in its place.
+// Returns true if the node is being replaced.
+func (a *Adaptor) handleNodeProvisioningTimeout(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) (bool, error) {
+
+	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+		utils.SetNodeProvisioningTimedOut(hwmgr, node)
+	}); err != nil {
+		return false, fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+	}
+
+	if !utils.CanRetryNodeProvisioning(hwmgr, nodepool) {
+		a.Logger.InfoContext(ctx, "Node provisioning timed out, with no retries remaining",
+			slog.String("nodename", node.Name),
+			slog.String("nodeId", node.Spec.HwMgrNodeId))
+		return false, nil
+	}
+
+	a.Logger.InfoContext(ctx, "Node provisioning timed out, releasing node for replacement",
+		slog.String("nodename", node.Name),
+		slog.String("nodeId", node.Spec.HwMgrNodeId))
+
+	// Record the node before releasing it, so the retry is counted even if a later step fails
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	if err := utils.AddNodePoolTimedOutNode(nodepool, node.Spec.HwMgrNodeId); err != nil {
+		return false, err
+	}
+	if err := a.Client.Patch(ctx, nodepool, patch); err != nil {
+		return false, fmt.Errorf("failed to patch NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if err := restClient.ReleaseNode(ctx, allocatedNodeRequestParams(nodepool, node)); err != nil {
+		return false, fmt.Errorf("failed to release timed out node %s: %w", node.Name, err)
+	}
+
+	if err := a.Client.Delete(ctx, node); client.IgnoreNotFound(err) != nil {
+		return false, fmt.Errorf("failed to delete timed out node %s: %w", node.Name, err)
+	}
+
+	return true, nil
+}
+
+// allocatedNodeRequestParams returns the template fields for a request concerning an allocated node
+func allocatedNodeRequestParams(nodepool *hwmgmtv1alpha1.NodePool, node *hwmgmtv1alpha1.Node) restclient.RequestParams {
+	return restclient.RequestParams{
+		CloudID:   nodepool.Spec.CloudID,
+		NodePool:  nodepool.Name,
+		Group:     node.Spec.GroupName,
+		HwProfile: node.Spec.HwProfile,
+		NodeId:    node.Spec.HwMgrNodeId,
+	}
+}
+
+// CreateBMCSecret creates the bmc-secret for a node
+func (a *Adaptor) CreateBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, nodename, groupname, username, password string) error {
+	a.Logger.InfoContext(ctx, "Creating bmc-secret:", slog.String("nodename", nodename))
+
+	blockDeletion := true
+	bmcSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.BMCSecretName(nodename),
+			Namespace: nodepool.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         nodepool.APIVersion,
+				Kind:               nodepool.Kind,
+				Name:               nodepool.Name,
+				UID:                nodepool.UID,
+				BlockOwnerDeletion: &blockDeletion,
+			}},
+		},
+		Data: map[string][]byte{
+			"username": []byte(username),
+			"password": []byte(password),
+		},
+	}
+
+	if err := utils.SetNodeMetadata(bmcSecret, nodepool, groupname); err != nil {
+		return fmt.Errorf("failed to set metadata for bmc-secret of node %s: %w", nodename, err)
+	}
+
+	provenance := utils.NewBMCSecretProvenance(pluginv1alpha1.SupportedAdaptors.Rest, nodepool, utils.CredentialsFromNodeAPI)
+	if err := sdk.ApplyBMCSecretProvenance(ctx, a.Client, a.Namespace, bmcSecret, provenance); err != nil {
+		return fmt.Errorf("failed to record provenance for bmc-secret of node %s: %w", nodename, err)
+	}
+
+	if err := utils.ApplyK8sCR(ctx, a.Client, bmcSecret, nil); err != nil {
+		return fmt.Errorf("failed to create bmc-secret for node %s: %w", nodename, err)
+	}
+
+	return nil
+}
+
+// ResyncNodeHardware refreshes the interfaces and their roles, BMC address, and asset details of the allocated nodes
+// from the backend, then checks the allocation of the NodePool for drift. The backend does not report the hardware
+// profile or resource pool of a node, so only the nodegroup sizes are checked.
+func (a *Adaptor) ResyncNodeHardware(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	var backend []utils.BackendNodeState
+	queue := sdk.GetNodeStatusQueue(hwmgr.Name, hwmgr.Spec.MaxNodeStatusUpdatesPerSecond)
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		info, err := restClient.GetNode(ctx, allocatedNodeRequestParams(nodepool, node))
+		if err != nil {
+			return fmt.Errorf("failed to get details for node %s: %w", node.Name, err)
+		}
+		backend = append(backend, utils.BackendNodeState{NodeName: node.Name, GroupName: node.Spec.GroupName})
+
+		if err := sdk.PublishNodeAssetInfo(ctx, a.Client, node, info.AssetInfo); err != nil {
+			return err
+		}
+
+		if err := sdk.PublishNodeBootCapabilities(ctx, a.Client, node, info.BootCapabilities); err != nil {
+			return err
+		}
+
+		roles := utils.ResolveInterfaceRoles(info.Interfaces, info.InterfaceRoles, utils.GetHwProfileInterfaceRoles(hwmgr, node.Spec.HwProfile))
+		if err := sdk.PublishNodeInterfaceRoles(ctx, a.Client, node, roles); err != nil {
+			return err
+		}
+
+		if err := sdk.PublishNodeInstallHints(ctx, a.Client, node, info.InstallHints); err != nil {
+			return err
+		}
+
+		if err := sdk.ApplyNodeSecrets(ctx, a.Client, hwmgr, node, info.SecretData); err != nil {
+			return err
+		}
+
+		bmcAddress, err := utils.FormatBMCAddress(hwmgr, node.Spec.HwProfile, info.BmcAddress, info.BmcAddressIPv6)
+		if err != nil {
+			a.Logger.InfoContext(ctx, "Skipping BMC address resync",
+				slog.String("nodename", node.Name),
+				slog.String("error", err.Error()))
+			if node.Status.BMC != nil {
+				bmcAddress = node.Status.BMC.Address
+			}
+		}
+
+		if !utils.ApplyNodeHardwareResync(node, info.Interfaces, bmcAddress) {
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Node hardware changed", slog.String("nodename", node.Name))
+		queue.Enqueue(node, func(latest *hwmgmtv1alpha1.Node) bool {
+			return utils.ApplyNodeHardwareResync(latest, info.Interfaces, bmcAddress)
+		})
+	}
+
+	// The status writes are spread out at the rate limit of the hardware manager, across the NodePools being resynced
+	if err := queue.Flush(ctx, a.Client); err != nil {
+		return fmt.Errorf("failed to update node status: %w", err)
+	}
+
+	drift, err := utils.DetectNodePoolDrift(nodepool, nodelist, backend)
+	if err != nil {
+		return fmt.Errorf("failed to check drift for NodePool %s: %w", nodepool.Name, err)
+	}
+	if err := utils.UpdateNodePoolDriftCondition(ctx, a.Client, nodepool, drift); err != nil {
+		return fmt.Errorf("failed to update drift status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,352 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/rest/restclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValidateNodePool performs basic validation of the nodepool data
+func (a *Adaptor) ValidateNodePool(nodepool *hwmgmtv1alpha1.NodePool) error {
+	if err := utils.ValidateNodePoolNodeGroupSizes(nodepool); err != nil {
+		return fmt.Errorf("invalid nodegroup size: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNetworkConfig(nodepool); err != nil {
+		return fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNodeNameTemplate(nodepool); err != nil {
+		return fmt.Errorf("invalid node naming policy: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolAdoptedNodes(nodepool); err != nil {
+		return fmt.Errorf("invalid adopted nodes: %w", err)
+	}
+
+	return nil
+}
+
+// HandleNodePoolCreate validates a new NodePool CR. Nodes are allocated from the backend as it is processed.
+func (a *Adaptor) HandleNodePoolCreate(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	if validationErr := a.ValidateNodePool(nodepool); validationErr != nil {
+		return sdk.FailNodePool(ctx, a.Client, nodepool, "NodePool configuration invalid: "+validationErr.Error())
+	}
+
+	if err := sdk.MarkNodePoolInProgress(ctx, a.Client, nodepool, "Handling creation"); err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	}
+
+	// Update the Node Pool hwMgrPlugin status
+	if err := utils.UpdateNodePoolPluginStatus(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to update hwMgrPlugin observedGeneration for NodePool %s: Status: %w",
+				nodepool.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// HandleNodePoolProcessing allocates the nodes of an in-progress NodePool from the backend, marking the NodePool as
+// provisioned once all the nodes are ready
+func (a *Adaptor) HandleNodePoolProcessing(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	if result, wait := sdk.WaitForAllocationRetry(nodepool); wait {
+		a.Logger.InfoContext(ctx, "Waiting to retry NodePool allocation")
+		return result, nil
+	}
+
+	throttle := sdk.GetAllocationThrottle(hwmgr.Name, utils.GetMaxConcurrentAllocations(hwmgr))
+
+	queued, err := a.AllocateNodes(ctx, restClient, hwmgr, throttle, nodepool)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "NodePool allocation failed", slog.String("error", err.Error()))
+		return sdk.RetryNodePoolAllocation(ctx, a.Client, hwmgr, nodepool,
+			fmt.Errorf("failed to allocate nodes for %s: %w", nodepool.Name, err))
+	}
+
+	pending, timedOut, err := a.UpdateAllocatedNodes(ctx, restClient, hwmgr, nodepool)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update allocated nodes for %s: %w", nodepool.Name, err)
+	}
+	throttle.Set(nodepool.Name, pending)
+
+	if timedOut > 0 {
+		throttle.Release(nodepool.Name)
+		return sdk.FailNodePool(ctx, a.Client, nodepool,
+			fmt.Sprintf("%d nodes were not provisioned by the backend in time", timedOut))
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	nodepool.Status.Properties.NodeNames = nil
+	for _, node := range nodelist.Items {
+		nodepool.Status.Properties.NodeNames = append(nodepool.Status.Properties.NodeNames, node.Name)
+	}
+	slices.Sort(nodepool.Status.Properties.NodeNames)
+
+	if err := utils.UpdateNodePoolProperties(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if queued > 0 {
+		a.Logger.InfoContext(ctx, "NodePool allocation throttled", slog.Int("queuedNodes", queued),
+			slog.Int("maxConcurrentAllocations", utils.GetMaxConcurrentAllocations(hwmgr)))
+		if err := sdk.MarkNodePoolAllocationQueued(ctx, a.Client, nodepool, queued); err != nil {
+			return utils.RequeueWithMediumInterval(), err
+		}
+	} else if sdk.IsNodePoolAllocationQueued(nodepool) {
+		if err := sdk.MarkNodePoolInProgress(ctx, a.Client, nodepool, "Handling creation"); err != nil {
+			return utils.RequeueWithMediumInterval(), err
+		}
+	}
+
+	if pending > 0 || queued > 0 {
+		a.Logger.InfoContext(ctx, "NodePool request in progress", slog.Int("pendingNodes", pending))
+		return utils.RequeueWithShortInterval(), nil
+	}
+
+	throttle.Release(nodepool.Name)
+
+	if err := sdk.ResetAllocationRetries(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	a.Logger.InfoContext(ctx, "NodePool request is fully allocated")
+	if err := sdk.MarkNodePoolProvisioned(ctx, a.Client, nodepool, "Created"); err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// HandleNodePoolSpecChanged applies hardware profile changes of the nodegroups to the allocated nodes, via the
+// updateNode endpoint
+func (a *Adaptor) HandleNodePoolSpecChanged(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	configuredCondition := meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Configured))
+	if configuredCondition == nil || configuredCondition.Reason != string(hwmgmtv1alpha1.ConfigUpdate) {
+		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+			hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.ConfigUpdate, metav1.ConditionFalse,
+			string(hwmgmtv1alpha1.AwaitConfig)); err != nil {
+			return utils.RequeueWithMediumInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		for i := range nodelist.Items {
+			node := &nodelist.Items[i]
+			if node.Spec.GroupName != nodegroup.NodePoolData.Name || node.Spec.HwProfile == nodegroup.NodePoolData.HwProfile {
+				continue
+			}
+
+			if !restClient.SupportsUpdateNode() {
+				if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+					hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
+					"Hardware profile changes are not supported: no updateNode endpoint is defined"); err != nil {
+					return utils.RequeueWithMediumInterval(),
+						fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+				}
+				return utils.DoNotRequeue(), nil
+			}
+
+			a.Logger.InfoContext(ctx, "Updating node hardware profile",
+				slog.String("nodename", node.Name),
+				slog.String("hwProfile", nodegroup.NodePoolData.HwProfile))
+
+			params := nodeRequestParams(nodepool, nodegroup, node.Spec.HwMgrNodeId)
+			if err := restClient.UpdateNode(ctx, params); err != nil {
+				return utils.RequeueWithMediumInterval(),
+					fmt.Errorf("failed to update hardware profile for node %s: %w", node.Name, err)
+			}
+
+			patch := client.MergeFrom(node.DeepCopy())
+			node.Spec.HwProfile = nodegroup.NodePoolData.HwProfile
+			if err := a.Client.Patch(ctx, node, patch); err != nil {
+				return utils.RequeueWithShortInterval(),
+					fmt.Errorf("failed to patch Node %s in namespace %s: %w", node.Name, node.Namespace, err)
+			}
+
+			if err := utils.UpdateK8sCRStatus(ctx, a.Client, node, func() {
+				node.Status.HwProfile = nodegroup.NodePoolData.HwProfile
+			}); err != nil {
+				return utils.RequeueWithShortInterval(),
+					fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+			}
+		}
+	}
+
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithConfigApplied().
+		WithObservedGeneration().
+		Update(ctx, a.Client); err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// HandleNodePoolExtend allocates the nodes added to the nodegroups of a provisioned NodePool from the backend. The
+// existing nodes are left untouched, and the nodes added are reported in the Extended condition once they are ready.
+func (a *Adaptor) HandleNodePoolExtend(
+	ctx context.Context,
+	restClient *restclient.RestClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	extension, err := sdk.BeginNodePoolExtension(ctx, a.Client, nodepool, nodelist)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+	a.Logger.InfoContext(ctx, "Extending NodePool", slog.String("nodes", utils.FormatNodePoolExtension(extension)))
+
+	throttle := sdk.GetAllocationThrottle(hwmgr.Name, utils.GetMaxConcurrentAllocations(hwmgr))
+
+	queued, err := a.AllocateNodes(ctx, restClient, hwmgr, throttle, nodepool)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "NodePool extension failed", slog.String("error", err.Error()))
+		return sdk.FailNodePoolExtension(ctx, a.Client, nodepool, "Extension failed: "+err.Error())
+	}
+
+	pending, timedOut, err := a.UpdateAllocatedNodes(ctx, restClient, hwmgr, nodepool)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update allocated nodes for %s: %w", nodepool.Name, err)
+	}
+	throttle.Set(nodepool.Name, pending)
+
+	if timedOut > 0 {
+		throttle.Release(nodepool.Name)
+		return sdk.FailNodePoolExtension(ctx, a.Client, nodepool,
+			fmt.Sprintf("Extension failed: %d nodes were not provisioned by the backend in time", timedOut))
+	}
+
+	allocated, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	nodepool.Status.Properties.NodeNames = nil
+	for _, node := range allocated.Items {
+		nodepool.Status.Properties.NodeNames = append(nodepool.Status.Properties.NodeNames, node.Name)
+	}
+	slices.Sort(nodepool.Status.Properties.NodeNames)
+
+	if err := utils.UpdateNodePoolProperties(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if pending > 0 || queued > 0 {
+		a.Logger.InfoContext(ctx, "NodePool extension in progress", slog.Int("pendingNodes", pending),
+			slog.Int("queuedNodes", queued))
+		return utils.RequeueWithShortInterval(), nil
+	}
+
+	throttle.Release(nodepool.Name)
+
+	a.Logger.InfoContext(ctx, "NodePool extension is complete")
+	if err := sdk.CompleteNodePoolExtension(ctx, a.Client, nodepool, nodelist); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// ReleaseNodePool releases the nodes allocated to a NodePool back to the backend, deleting the Node CRs. Worker nodes
+// are released ahead of control plane nodes, at no more than the release rate limit of the hardware manager, with the
+// release deferred once the limit is reached.
+func (a *Adaptor) ReleaseNodePool(ctx context.Context,
+	restClient *restclient.RestClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	a.Logger.InfoContext(ctx, "Processing ReleaseNodePool request:",
+		slog.String("cloudID", nodepool.Spec.CloudID),
+	)
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	utils.SortNodesForTeardown(nodepool, nodelist.Items)
+	limiter := sdk.GetReleaseLimiter(hwmgr.Name, hwmgr.Spec.MaxNodeReleasesPerMinute)
+
+	for i, node := range nodelist.Items {
+		if !limiter.Allow() {
+			return fmt.Errorf("%w: release rate limit reached with %d of %d nodes remaining",
+				sdk.ErrReleaseDeferred, len(nodelist.Items)-i, len(nodelist.Items))
+		}
+
+		a.Logger.InfoContext(ctx, "Releasing node",
+			slog.String("nodename", node.Name),
+			slog.String("nodeId", node.Spec.HwMgrNodeId))
+
+		if err := restClient.ReleaseNode(ctx, allocatedNodeRequestParams(nodepool, &node)); err != nil {
+			return fmt.Errorf("failed to release node %s: %w", node.Name, err)
+		}
+		sdk.InvalidateInventoryCache(hwmgr.Name)
+
+		// Delete the released node, so it is not released again if a subsequent release fails
+		if err := a.Client.Delete(ctx, &node); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete node %s: %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,770 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// TokenKey is the auth secret key holding the token for Bearer authentication
+	TokenKey = "token"
+
+	// DefaultSite is the site under which the resource pools of the backend are reported
+	DefaultSite = "default"
+
+	DefaultReadyValue          = "true"
+	DefaultInterfaceName       = "{.name}"
+	DefaultInterfaceLabel      = "{.label}"
+	DefaultInterfaceMacAddress = "{.macAddress}"
+)
+
+// RequestParams are the fields available to the request templates
+type RequestParams struct {
+	CloudID        string
+	NodePool       string
+	Group          string
+	ResourcePoolId string
+	HwProfile      string
+	NodeId         string
+	// ExcludedNodeIds are the backend IDs of nodes released after timing out, which should not be reallocated
+	ExcludedNodeIds []string
+	// Storage is the storage layout of the hardware profile, if any, to be configured on allocation
+	Storage *pluginv1alpha1.StorageLayout
+	// Security is the security requirements of the hardware profile, if any, to be configured on allocation
+	Security *pluginv1alpha1.SecurityRequirements
+}
+
+// NodeInfo is the node data extracted from a getNode response
+type NodeInfo struct {
+	Ready      bool
+	BmcAddress string
+	// BmcAddressIPv6 is the IPv6 BMC address of a dual-stack node, if mapped and reported
+	BmcAddressIPv6 string
+	BmcUsername    string
+	BmcPassword    string
+	Interfaces     []*hwmgmtv1alpha1.Interface
+	AssetInfo      utils.NodeAssetInfo
+	// Roles reported by the backend, keyed by interface label
+	InterfaceRoles map[string]string
+	// Additional secret data reported by the backend, for the node secrets
+	SecretData       map[string]string
+	BootCapabilities utils.NodeBootCapabilities
+	Security         utils.NodeSecurityState
+	InstallHints     utils.NodeInstallHints
+}
+
+type requestTemplate struct {
+	method string
+	path   *template.Template
+	body   *template.Template
+}
+
+// fieldPath is a compiled JSONPath expression for a field mapping
+type fieldPath struct {
+	name string
+	jp   *jsonpath.JSONPath
+}
+
+type fieldMappings struct {
+	nodeId              *fieldPath
+	ready               *fieldPath
+	readyValue          string
+	bmcAddress          *fieldPath
+	bmcAddressIPv6      *fieldPath
+	bmcUsername         *fieldPath
+	bmcPassword         *fieldPath
+	interfaces          *fieldPath
+	interfaceName       *fieldPath
+	interfaceLabel      *fieldPath
+	interfaceMacAddress *fieldPath
+	interfaceRole       *fieldPath
+	resourcePools       *fieldPath
+	serialNumber        *fieldPath
+	assetTag            *fieldPath
+	model               *fieldPath
+	vendor              *fieldPath
+	secretData          *fieldPath
+	bootDevices         *fieldPath
+	virtualMediaURLs    *fieldPath
+	secureBoot          *fieldPath
+	tpmVersion          *fieldPath
+	hostname            *fieldPath
+	provisioningMac     *fieldPath
+	ipConfig            *fieldPath
+	ipAddress           *fieldPath
+	gateway             *fieldPath
+	dnsServers          *fieldPath
+}
+
+// compiledData is the parsed form of the declarative backend description
+type compiledData struct {
+	allocateNode      *requestTemplate
+	getNode           *requestTemplate
+	releaseNode       *requestTemplate
+	updateNode        *requestTemplate
+	listResourcePools *requestTemplate
+	mappings          fieldMappings
+}
+
+// RestClient sends the templated requests to the backend, and extracts data from the responses
+type RestClient struct {
+	compiledData
+	httpClient *http.Client
+	Logger     *slog.Logger
+	apiUrl     string
+	authScheme pluginv1alpha1.RestAuthScheme
+	username   string
+	password   string
+}
+
+var templateFuncs = template.FuncMap{
+	// json quotes a value for use in a JSON request body
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal value: %w", err)
+		}
+		return string(data), nil
+	},
+}
+
+func compileRequest(name string, req *pluginv1alpha1.RestRequestTemplate) (*requestTemplate, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	if req.Path == "" {
+		return nil, utils.NewInputError("missing path for %s endpoint", name)
+	}
+
+	compiled := &requestTemplate{method: req.Method}
+	if compiled.method == "" {
+		compiled.method = http.MethodGet
+	}
+
+	var err error
+	if compiled.path, err = template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(req.Path); err != nil {
+		return nil, utils.NewInputError("invalid path template for %s endpoint: %s", name, err.Error())
+	}
+
+	if req.Body != "" {
+		if compiled.body, err = template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(req.Body); err != nil {
+			return nil, utils.NewInputError("invalid body template for %s endpoint: %s", name, err.Error())
+		}
+	}
+
+	return compiled, nil
+}
+
+func compileJSONPath(name, expr, defaultExpr string, required bool) (*fieldPath, error) {
+	if expr == "" {
+		expr = defaultExpr
+	}
+	if expr == "" {
+		if required {
+			return nil, utils.NewInputError("missing %s mapping", name)
+		}
+		return nil, nil
+	}
+
+	// Accept expressions with or without the enclosing braces
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+
+	jp := jsonpath.New(name).AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return nil, utils.NewInputError("invalid %s mapping: %s", name, err.Error())
+	}
+
+	return &fieldPath{name: name, jp: jp}, nil
+}
+
+func compile(data *pluginv1alpha1.RestData) (*compiledData, error) {
+	compiled := &compiledData{}
+
+	requests := []struct {
+		name     string
+		req      *pluginv1alpha1.RestRequestTemplate
+		compiled **requestTemplate
+	}{
+		{"allocateNode", &data.Endpoints.AllocateNode, &compiled.allocateNode},
+		{"getNode", &data.Endpoints.GetNode, &compiled.getNode},
+		{"releaseNode", &data.Endpoints.ReleaseNode, &compiled.releaseNode},
+		{"updateNode", data.Endpoints.UpdateNode, &compiled.updateNode},
+		{"listResourcePools", data.Endpoints.ListResourcePools, &compiled.listResourcePools},
+	}
+	for _, iter := range requests {
+		var err error
+		if *iter.compiled, err = compileRequest(iter.name, iter.req); err != nil {
+			return nil, err
+		}
+	}
+
+	mappings := &data.Mappings
+	fields := []struct {
+		name        string
+		expr        string
+		defaultExpr string
+		required    bool
+		compiled    **fieldPath
+	}{
+		{"nodeId", mappings.NodeId, "", true, &compiled.mappings.nodeId},
+		{"ready", mappings.Ready, "", false, &compiled.mappings.ready},
+		{"bmcAddress", mappings.BmcAddress, "", true, &compiled.mappings.bmcAddress},
+		{"bmcAddressIPv6", mappings.BmcAddressIPv6, "", false, &compiled.mappings.bmcAddressIPv6},
+		{"bmcUsername", mappings.BmcUsername, "", true, &compiled.mappings.bmcUsername},
+		{"bmcPassword", mappings.BmcPassword, "", true, &compiled.mappings.bmcPassword},
+		{"interfaces", mappings.Interfaces, "", false, &compiled.mappings.interfaces},
+		{"interfaceName", mappings.InterfaceName, DefaultInterfaceName, false, &compiled.mappings.interfaceName},
+		{"interfaceLabel", mappings.InterfaceLabel, DefaultInterfaceLabel, false, &compiled.mappings.interfaceLabel},
+		{"interfaceMacAddress", mappings.InterfaceMacAddress, DefaultInterfaceMacAddress, false, &compiled.mappings.interfaceMacAddress},
+		{"interfaceRole", mappings.InterfaceRole, "", false, &compiled.mappings.interfaceRole},
+		{"resourcePools", mappings.ResourcePools, "", compiled.listResourcePools != nil, &compiled.mappings.resourcePools},
+		{"serialNumber", mappings.SerialNumber, "", false, &compiled.mappings.serialNumber},
+		{"assetTag", mappings.AssetTag, "", false, &compiled.mappings.assetTag},
+		{"model", mappings.Model, "", false, &compiled.mappings.model},
+		{"vendor", mappings.Vendor, "", false, &compiled.mappings.vendor},
+		{"secretData", mappings.SecretData, "", false, &compiled.mappings.secretData},
+		{"bootDevices", mappings.BootDevices, "", false, &compiled.mappings.bootDevices},
+		{"virtualMediaURLs", mappings.VirtualMediaURLs, "", false, &compiled.mappings.virtualMediaURLs},
+		{"secureBoot", mappings.SecureBoot, "", false, &compiled.mappings.secureBoot},
+		{"tpmVersion", mappings.TPMVersion, "", false, &compiled.mappings.tpmVersion},
+		{"hostname", mappings.Hostname, "", false, &compiled.mappings.hostname},
+		{"provisioningMacAddress", mappings.ProvisioningMacAddress, "", false, &compiled.mappings.provisioningMac},
+		{"ipConfig", mappings.IPConfig, "", false, &compiled.mappings.ipConfig},
+		{"ipAddress", mappings.IPAddress, "", false, &compiled.mappings.ipAddress},
+		{"gateway", mappings.Gateway, "", false, &compiled.mappings.gateway},
+		{"dnsServers", mappings.DNSServers, "", false, &compiled.mappings.dnsServers},
+	}
+	for _, iter := range fields {
+		var err error
+		if *iter.compiled, err = compileJSONPath(iter.name, iter.expr, iter.defaultExpr, iter.required); err != nil {
+			return nil, err
+		}
+	}
+
+	compiled.mappings.readyValue = mappings.ReadyValue
+	if compiled.mappings.readyValue == "" {
+		compiled.mappings.readyValue = DefaultReadyValue
+	}
+
+	return compiled, nil
+}
+
+// ValidateRestData validates the declarative backend description of a rest adaptor instance
+func ValidateRestData(data *pluginv1alpha1.RestData) error {
+	if data == nil {
+		return utils.NewInputError("missing restData configuration field")
+	}
+
+	if data.ApiUrl == "" {
+		return utils.NewInputError("missing apiUrl")
+	}
+
+	switch data.AuthScheme {
+	case "", pluginv1alpha1.RestAuthSchemes.None:
+	case pluginv1alpha1.RestAuthSchemes.Basic, pluginv1alpha1.RestAuthSchemes.Bearer:
+		if data.AuthSecret == "" {
+			return utils.NewInputError("authSecret is required for %s authentication", data.AuthScheme)
+		}
+	default:
+		return utils.NewInputError("unsupported authScheme %s", data.AuthScheme)
+	}
+
+	if _, err := compile(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewRestClient creates a client for the backend described by the restData of the HardwareManager
+func NewRestClient(
+	ctx context.Context,
+	logger *slog.Logger,
+	rtclient client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager) (*RestClient, error) {
+
+	data := hwmgr.Spec.RestData
+	if err := ValidateRestData(data); err != nil {
+		return nil, fmt.Errorf("invalid restData: %w", err)
+	}
+
+	var bearerToken, username, password string
+	if data.AuthScheme == pluginv1alpha1.RestAuthSchemes.Basic || data.AuthScheme == pluginv1alpha1.RestAuthSchemes.Bearer {
+		secret, err := utils.GetSecret(ctx, rtclient, data.AuthSecret, hwmgr.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get auth secret: %w", err)
+		}
+
+		if data.AuthScheme == pluginv1alpha1.RestAuthSchemes.Bearer {
+			if bearerToken, err = utils.GetSecretField(secret, TokenKey); err != nil {
+				return nil, fmt.Errorf("failed to get %s from secret: %s, %w", TokenKey, data.AuthSecret, err)
+			}
+		} else {
+			if username, err = utils.GetSecretField(secret, corev1.BasicAuthUsernameKey); err != nil {
+				return nil, fmt.Errorf("failed to get %s from secret: %s, %w", corev1.BasicAuthUsernameKey, data.AuthSecret, err)
+			}
+			if password, err = utils.GetSecretField(secret, corev1.BasicAuthPasswordKey); err != nil {
+				return nil, fmt.Errorf("failed to get %s from secret: %s, %w", corev1.BasicAuthPasswordKey, data.AuthSecret, err)
+			}
+		}
+	}
+
+	// If the HardwareManager CR includes certificates, get the bundle to add to the client
+	caBundle, err := sdk.GetCaBundle(ctx, rtclient, hwmgr.Namespace, data.CaBundleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA bundle: %w", err)
+	}
+
+	proxyCaBundle, err := sdk.GetProxyCaBundle(ctx, rtclient, hwmgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy CA bundle: %w", err)
+	}
+
+	httpClient, err := sdk.NewHTTPClient(sdk.HTTPClientConfig{
+		CaBundle:              caBundle,
+		Proxy:                 hwmgr.Spec.Proxy,
+		ProxyCaBundle:         proxyCaBundle,
+		InsecureSkipTLSVerify: data.InsecureSkipTLSVerify,
+		LogMessages:           utils.IsHardwareManagerLogMessagesEnabled(hwmgr),
+		BearerToken:           bearerToken,
+		HwMgrName:             hwmgr.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup http client: %w", err)
+	}
+
+	restClient, err := newRestClient(logger, data, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	restClient.username = username
+	restClient.password = password
+
+	return restClient, nil
+}
+
+// newRestClient creates a client for the backend, using the given HTTP client
+func newRestClient(logger *slog.Logger, data *pluginv1alpha1.RestData, httpClient *http.Client) (*RestClient, error) {
+	compiled, err := compile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid restData: %w", err)
+	}
+
+	return &RestClient{
+		compiledData: *compiled,
+		httpClient:   httpClient,
+		Logger:       logger,
+		apiUrl:       strings.TrimSuffix(data.ApiUrl, "/"),
+		authScheme:   data.AuthScheme,
+	}, nil
+}
+
+// restErrorMapper translates the throttled responses of the backend, which carry no vendor error code
+var restErrorMapper = &sdk.ErrorMapper{}
+
+// do renders and sends a templated request, returning the decoded JSON response, if any
+func (c *RestClient) do(ctx context.Context, req *requestTemplate, params RequestParams) (any, error) {
+	var path bytes.Buffer
+	if err := req.path.Execute(&path, params); err != nil {
+		return nil, fmt.Errorf("failed to render %s path: %w", req.path.Name(), err)
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		var buf bytes.Buffer
+		if err := req.body.Execute(&buf, params); err != nil {
+			return nil, fmt.Errorf("failed to render %s body: %w", req.body.Name(), err)
+		}
+		body = bytes.NewReader(buf.Bytes())
+	}
+
+	url := c.apiUrl + "/" + strings.TrimPrefix(path.String(), "/")
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", req.path.Name(), err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.authScheme == pluginv1alpha1.RestAuthSchemes.Basic {
+		httpReq.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", req.path.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", req.path.Name(), err)
+	}
+
+	if sdk.IsThrottled(resp) {
+		// Reported as a backend error, so that the reconcile is requeued for the delay requested by the backend
+		return nil, restErrorMapper.MapResponse(req.path.Name()+" request", resp, data)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("%s request failed with status %s (%d), message=%s",
+			req.path.Name(), resp.Status, resp.StatusCode, string(data))
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", req.path.Name(), err)
+	}
+
+	return result, nil
+}
+
+// findValues returns the values matching the JSONPath expression
+func findValues(field *fieldPath, data any) ([]any, error) {
+	results, err := field.jp.FindResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %s mapping: %w", field.name, err)
+	}
+
+	var values []any
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				values = append(values, value.Interface())
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// getString returns the first value matching the JSONPath expression as a string, or an empty string if none match
+func getString(field *fieldPath, data any) (string, error) {
+	if field == nil {
+		return "", nil
+	}
+
+	values, err := findValues(field, data)
+	if err != nil || len(values) == 0 || values[0] == nil {
+		return "", err
+	}
+
+	if s, ok := values[0].(string); ok {
+		return s, nil
+	}
+
+	// Numbers and booleans are converted to their JSON representation
+	s, err := json.Marshal(values[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to convert %s value: %w", field.name, err)
+	}
+	return string(s), nil
+}
+
+// getStringMap returns the first object matching the JSONPath expression as a map of strings, or nil if none match
+func getStringMap(field *fieldPath, data any) (map[string]string, error) {
+	if field == nil {
+		return nil, nil
+	}
+
+	values, err := findValues(field, data)
+	if err != nil || len(values) == 0 || values[0] == nil {
+		return nil, err
+	}
+
+	object, ok := values[0].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s mapping does not select an object", field.name)
+	}
+
+	result := make(map[string]string, len(object))
+	for key, value := range object {
+		if s, ok := value.(string); ok {
+			result[key] = s
+			continue
+		}
+		// Numbers and booleans are converted to their JSON representation
+		s, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s value: %w", field.name, err)
+		}
+		result[key] = string(s)
+	}
+	return result, nil
+}
+
+// getStrings returns the string values matching the JSONPath expression, with a matched list contributing each of its
+// entries. Empty and non-string values are skipped.
+func getStrings(field *fieldPath, data any) ([]string, error) {
+	if field == nil {
+		return nil, nil
+	}
+
+	values, err := findValues(field, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, value := range values {
+		items, isList := value.([]any)
+		if !isList {
+			items = []any{value}
+		}
+		for _, item := range items {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result, nil
+}
+
+// getRequiredString returns the first value matching the JSONPath expression as a string, failing if none match
+func getRequiredString(field *fieldPath, data any) (string, error) {
+	s, err := getString(field, data)
+	if err != nil {
+		return "", err
+	}
+	if s == "" {
+		return "", fmt.Errorf("no value found for %s mapping in response", field.name)
+	}
+	return s, nil
+}
+
+// AllocateNode allocates a node from the backend, returning its ID
+func (c *RestClient) AllocateNode(ctx context.Context, params RequestParams) (string, error) {
+	resp, err := c.do(ctx, c.allocateNode, params)
+	if err != nil {
+		return "", err
+	}
+
+	return getRequiredString(c.mappings.nodeId, resp)
+}
+
+// GetNode gets the details of an allocated node from the backend
+func (c *RestClient) GetNode(ctx context.Context, params RequestParams) (*NodeInfo, error) {
+	// The request may be configured with any method, but does not change the backend
+	resp, err := c.do(utils.WithReadOnlyExemption(ctx), c.getNode, params)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &NodeInfo{Ready: true}
+
+	if c.mappings.ready != nil {
+		ready, err := getString(c.mappings.ready, resp)
+		if err != nil {
+			return nil, err
+		}
+		info.Ready = ready == c.mappings.readyValue
+	}
+
+	for _, field := range []struct {
+		path  *fieldPath
+		value *string
+	}{
+		{c.mappings.bmcAddress, &info.BmcAddress},
+		{c.mappings.bmcUsername, &info.BmcUsername},
+		{c.mappings.bmcPassword, &info.BmcPassword},
+	} {
+		if *field.value, err = getString(field.path, resp); err != nil {
+			return nil, err
+		}
+		if info.Ready && *field.value == "" {
+			return nil, fmt.Errorf("no value found for %s mapping in response", field.path.name)
+		}
+	}
+
+	// The IPv6 BMC address, asset details and security state are optional, and left empty if not mapped or not
+	// reported
+	var secureBoot string
+	for _, field := range []struct {
+		path  *fieldPath
+		value *string
+	}{
+		{c.mappings.bmcAddressIPv6, &info.BmcAddressIPv6},
+		{c.mappings.serialNumber, &info.AssetInfo.SerialNumber},
+		{c.mappings.assetTag, &info.AssetInfo.AssetTag},
+		{c.mappings.model, &info.AssetInfo.Model},
+		{c.mappings.vendor, &info.AssetInfo.Vendor},
+		{c.mappings.secureBoot, &secureBoot},
+		{c.mappings.tpmVersion, &info.Security.TPMVersion},
+	} {
+		if *field.value, err = getString(field.path, resp); err != nil {
+			return nil, err
+		}
+	}
+	info.Security.SecureBoot = utils.SecureBootState(secureBoot)
+
+	if info.SecretData, err = getStringMap(c.mappings.secretData, resp); err != nil {
+		return nil, err
+	}
+
+	bootDevices, err := getStrings(c.mappings.bootDevices, resp)
+	if err != nil {
+		return nil, err
+	}
+	virtualMediaURLs, err := getStrings(c.mappings.virtualMediaURLs, resp)
+	if err != nil {
+		return nil, err
+	}
+	info.BootCapabilities = utils.NewNodeBootCapabilities(bootDevices, virtualMediaURLs)
+
+	if info.InstallHints, err = c.getInstallHints(resp); err != nil {
+		return nil, err
+	}
+
+	if c.mappings.interfaces != nil {
+		items, err := findValues(c.mappings.interfaces, resp)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 1 {
+			// The expression may select the list itself, rather than its entries
+			if list, ok := items[0].([]any); ok {
+				items = list
+			}
+		}
+
+		for _, item := range items {
+			iface := &hwmgmtv1alpha1.Interface{}
+			if iface.Name, err = getString(c.mappings.interfaceName, item); err != nil {
+				return nil, err
+			}
+			if iface.Label, err = getString(c.mappings.interfaceLabel, item); err != nil {
+				return nil, err
+			}
+			if iface.MACAddress, err = getString(c.mappings.interfaceMacAddress, item); err != nil {
+				return nil, err
+			}
+			info.Interfaces = append(info.Interfaces, iface)
+
+			role, err := getString(c.mappings.interfaceRole, item)
+			if err != nil {
+				return nil, err
+			}
+			if role != "" {
+				if info.InterfaceRoles == nil {
+					info.InterfaceRoles = make(map[string]string)
+				}
+				info.InterfaceRoles[iface.Label] = role
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// getInstallHints extracts the optional install hints from a getNode response. Static addressing is reported if an IP
+// address is returned and the IP configuration mode is not mapped.
+func (c *RestClient) getInstallHints(resp any) (hints utils.NodeInstallHints, err error) {
+	var ipConfig, ipAddress, gateway string
+	for _, field := range []struct {
+		path  *fieldPath
+		value *string
+	}{
+		{c.mappings.hostname, &hints.Hostname},
+		{c.mappings.provisioningMac, &hints.ProvisioningMACAddress},
+		{c.mappings.ipConfig, &ipConfig},
+		{c.mappings.ipAddress, &ipAddress},
+		{c.mappings.gateway, &gateway},
+	} {
+		if *field.value, err = getString(field.path, resp); err != nil {
+			return
+		}
+	}
+
+	if hints.IPConfig, err = utils.ParseIPConfigMode(ipConfig); err != nil {
+		err = fmt.Errorf("invalid value for %s mapping in response: %w", c.mappings.ipConfig.name, err)
+		return
+	}
+	if ipAddress == "" {
+		return
+	}
+	if hints.IPConfig == "" {
+		hints.IPConfig = utils.IPConfigStatic
+	}
+	if hints.IPConfig == utils.IPConfigStatic {
+		hints.Static = &utils.StaticIPConfig{Address: ipAddress, Gateway: gateway}
+		if hints.Static.DNSServers, err = getStrings(c.mappings.dnsServers, resp); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// SupportsUpdateNode returns true if an updateNode endpoint is defined, for hardware profile changes
+func (c *RestClient) SupportsUpdateNode() bool {
+	return c.updateNode != nil
+}
+
+// UpdateNode applies the hardware profile to an allocated node
+func (c *RestClient) UpdateNode(ctx context.Context, params RequestParams) error {
+	if c.updateNode == nil {
+		return fmt.Errorf("updateNode endpoint is not defined")
+	}
+
+	_, err := c.do(ctx, c.updateNode, params)
+	return err
+}
+
+// ReleaseNode releases an allocated node back to the backend
+func (c *RestClient) ReleaseNode(ctx context.Context, params RequestParams) error {
+	_, err := c.do(ctx, c.releaseNode, params)
+	return err
+}
+
+// GetResourcePools lists the resource pool IDs of the backend. If no listResourcePools endpoint is defined, nil is
+// returned without contacting the backend.
+func (c *RestClient) GetResourcePools(ctx context.Context) ([]string, error) {
+	if c.listResourcePools == nil {
+		return nil, nil
+	}
+
+	resp, err := c.do(utils.WithReadOnlyExemption(ctx), c.listResourcePools, RequestParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	return getStrings(c.mappings.resourcePools, resp)
+}
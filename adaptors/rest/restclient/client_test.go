@@ -0,0 +1,228 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+func newTestRestData(apiUrl string) *pluginv1alpha1.RestData {
+	return &pluginv1alpha1.RestData{
+		ApiUrl: apiUrl,
+		Endpoints: pluginv1alpha1.RestEndpoints{
+			AllocateNode: pluginv1alpha1.RestRequestTemplate{
+				Method: http.MethodPost,
+				Path:   "/pools/{{ .ResourcePoolId }}/allocations",
+				Body:   `{"owner": {{ json .CloudID }}, "profile": {{ json .HwProfile }}, "storage": {{ json .Storage }}}`,
+			},
+			GetNode:     pluginv1alpha1.RestRequestTemplate{Path: "/nodes/{{ .NodeId }}"},
+			ReleaseNode: pluginv1alpha1.RestRequestTemplate{Method: http.MethodDelete, Path: "/allocations/{{ .NodeId }}"},
+			ListResourcePools: &pluginv1alpha1.RestRequestTemplate{
+				Path: "/pools",
+			},
+		},
+		Mappings: pluginv1alpha1.RestFieldMappings{
+			NodeId:              ".allocation.id",
+			Ready:               "{.state}",
+			ReadyValue:          "ready",
+			BmcAddress:          ".bmc.url",
+			BmcAddressIPv6:      ".bmc.url6",
+			BmcUsername:         ".bmc.user",
+			BmcPassword:         ".bmc.pass",
+			Interfaces:          ".nics[*]",
+			InterfaceMacAddress: ".mac",
+			InterfaceRole:       ".role",
+			ResourcePools:       ".items[*].id",
+			SerialNumber:        ".inventory.serial",
+			Vendor:              ".inventory.vendor",
+			SecretData:          ".console",
+			BootDevices:         ".boot.devices",
+			VirtualMediaURLs:    ".boot.media[*].url",
+			SecureBoot:          ".security.secureBoot",
+			TPMVersion:          ".security.tpm",
+			Hostname:            ".install.hostname",
+			IPAddress:           ".install.ip",
+			Gateway:             ".install.gw",
+			DNSServers:          ".install.dns",
+		},
+	}
+}
+
+var _ = Describe("Rest client", func() {
+	var (
+		server   *httptest.Server
+		requests []string
+		bodies   []map[string]any
+		client   *RestClient
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		bodies = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+				var body map[string]any
+				Expect(json.Unmarshal(data, &body)).To(Succeed())
+				bodies = append(bodies, body)
+			}
+
+			switch r.URL.Path {
+			case "/api/pools/worker/allocations":
+				_, _ = w.Write([]byte(`{"allocation": {"id": 42}}`))
+			case "/api/nodes/42":
+				_, _ = w.Write([]byte(`{"state": "ready", "bmc": {"url": "redfish://10.0.0.42", "url6": "redfish://[fd00::42]", "user": "admin", "pass": "secret"},
+					"nics": [{"name": "eno1", "label": "boot", "mac": "aa:bb:cc:dd:ee:01", "role": "provisioning"}, {"name": "eno2", "mac": "aa:bb:cc:dd:ee:02"}],
+					"inventory": {"serial": "SN0042", "vendor": "Acme"}, "console": {"user": "console", "port": 2200},
+					"boot": {"devices": ["Pxe", "Cd"], "media": [{"url": "redfish-virtualmedia://10.0.0.42/redfish/v1/Systems/1"}]},
+					"security": {"secureBoot": "Enabled", "tpm": 2.0},
+					"install": {"hostname": "master-0.cluster1.example.com", "ip": "192.168.1.42/24", "gw": "192.168.1.1", "dns": ["192.168.1.2"]}}`))
+			case "/api/nodes/43":
+				_, _ = w.Write([]byte(`{"state": "provisioning"}`))
+			case "/api/pools":
+				_, _ = w.Write([]byte(`{"items": [{"id": "worker"}, {"id": "master"}]}`))
+			case "/api/allocations/42":
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		var err error
+		client, err = newRestClient(nil, newTestRestData(server.URL+"/api/"), server.Client())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("allocates a node from the templated request", func() {
+		nodeId, err := client.AllocateNode(context.Background(), RequestParams{
+			CloudID:        "cloud-1",
+			ResourcePoolId: "worker",
+			HwProfile:      "profile-1",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeId).To(Equal("42"))
+		Expect(requests).To(Equal([]string{"POST /api/pools/worker/allocations"}))
+		Expect(bodies).To(Equal([]map[string]any{{"owner": "cloud-1", "profile": "profile-1", "storage": nil}}))
+	})
+
+	It("passes the storage layout to the allocation request", func() {
+		_, err := client.AllocateNode(context.Background(), RequestParams{
+			CloudID:        "cloud-1",
+			ResourcePoolId: "worker",
+			HwProfile:      "profile-1",
+			Storage: &pluginv1alpha1.StorageLayout{
+				VirtualDisks: []pluginv1alpha1.VirtualDisk{{Name: "os", RAIDLevel: pluginv1alpha1.RAID1, SizeGiB: 100}},
+				BootDisk:     &pluginv1alpha1.BootDiskHints{VirtualDisk: "os"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bodies).To(HaveLen(1))
+		Expect(bodies[0]["storage"]).To(Equal(map[string]any{
+			"virtualDisks": []any{map[string]any{"name": "os", "raidLevel": "RAID1", "sizeGiB": float64(100)}},
+			"bootDisk":     map[string]any{"virtualDisk": "os"},
+		}))
+	})
+
+	It("maps the node details from the response", func() {
+		info, err := client.GetNode(context.Background(), RequestParams{NodeId: "42"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info).To(Equal(&NodeInfo{
+			Ready:          true,
+			BmcAddress:     "redfish://10.0.0.42",
+			BmcAddressIPv6: "redfish://[fd00::42]",
+			BmcUsername:    "admin",
+			BmcPassword:    "secret",
+			Interfaces: []*hwmgmtv1alpha1.Interface{
+				{Name: "eno1", Label: "boot", MACAddress: "aa:bb:cc:dd:ee:01"},
+				{Name: "eno2", MACAddress: "aa:bb:cc:dd:ee:02"},
+			},
+			AssetInfo:      utils.NodeAssetInfo{SerialNumber: "SN0042", Vendor: "Acme"},
+			InterfaceRoles: map[string]string{"boot": "provisioning"},
+			SecretData:     map[string]string{"user": "console", "port": "2200"},
+			BootCapabilities: utils.NodeBootCapabilities{
+				VirtualMedia:     true,
+				BootDevices:      []string{"Pxe", "Cd"},
+				VirtualMediaURLs: []string{"redfish-virtualmedia://10.0.0.42/redfish/v1/Systems/1"},
+			},
+			Security: utils.NodeSecurityState{SecureBoot: utils.SecureBootEnabled, TPMVersion: "2"},
+			InstallHints: utils.NodeInstallHints{
+				Hostname: "master-0.cluster1.example.com",
+				IPConfig: utils.IPConfigStatic,
+				Static: &utils.StaticIPConfig{
+					Address:    "192.168.1.42/24",
+					Gateway:    "192.168.1.1",
+					DNSServers: []string{"192.168.1.2"},
+				},
+			},
+		}))
+
+		info, err = client.GetNode(context.Background(), RequestParams{NodeId: "43"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Ready).To(BeFalse())
+		Expect(info.BootCapabilities.IsEmpty()).To(BeTrue())
+		Expect(info.Security).To(Equal(utils.NodeSecurityState{}))
+		Expect(info.InstallHints.IsEmpty()).To(BeTrue())
+	})
+
+	It("lists the resource pools", func() {
+		pools, err := client.GetResourcePools(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pools).To(Equal([]string{"worker", "master"}))
+	})
+
+	It("fails on an error status", func() {
+		Expect(client.ReleaseNode(context.Background(), RequestParams{NodeId: "42"})).To(Succeed())
+		Expect(client.ReleaseNode(context.Background(), RequestParams{NodeId: "99"})).To(MatchError(ContainSubstring("404")))
+		Expect(client.SupportsUpdateNode()).To(BeFalse())
+	})
+})
+
+var _ = Describe("Rest data validation", func() {
+	It("accepts a valid configuration", func() {
+		Expect(ValidateRestData(newTestRestData("https://backend"))).To(Succeed())
+	})
+
+	It("rejects an invalid template", func() {
+		data := newTestRestData("https://backend")
+		data.Endpoints.GetNode.Path = "/nodes/{{ .NodeId"
+		Expect(ValidateRestData(data)).To(MatchError(ContainSubstring("invalid path template for getNode")))
+	})
+
+	It("rejects a missing mapping", func() {
+		data := newTestRestData("https://backend")
+		data.Mappings.BmcAddress = ""
+		Expect(ValidateRestData(data)).To(MatchError(ContainSubstring("missing bmcAddress mapping")))
+	})
+
+	It("requires an auth secret for authentication", func() {
+		data := newTestRestData("https://backend")
+		data.AuthScheme = pluginv1alpha1.RestAuthSchemes.Bearer
+		Expect(ValidateRestData(data)).To(MatchError(ContainSubstring("authSecret is required")))
+	})
+})
@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/rest/restclient"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// SelfTest sets up an authenticated client for the backend, lists its resource pools if a listResourcePools endpoint
+// is defined, and gets an allocated node, if any. The declarative API cannot query unallocated nodes.
+func (a *Adaptor) SelfTest(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) []pluginv1alpha1.SelfTestStep {
+	test := &sdk.SelfTest{}
+
+	var client *restclient.RestClient
+	test.Run(sdk.SelfTestStepAuthenticate, func() (string, error) {
+		var err error
+		if client, err = restclient.NewRestClient(ctx, a.Logger, a.Client, hwmgr); err != nil {
+			return "", fmt.Errorf("authentication failure: %w", err)
+		}
+		return "Client configured for " + hwmgr.Spec.RestData.ApiUrl, nil
+	})
+
+	test.Run(sdk.SelfTestStepListResourcePools, func() (string, error) {
+		if hwmgr.Spec.RestData.Endpoints.ListResourcePools == nil {
+			return "No listResourcePools endpoint defined", nil
+		}
+		pools, err := client.GetResourcePools(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to query resource pools: %w", err)
+		}
+		slices.Sort(pools)
+		return sdk.DescribeResourcePools(pools), nil
+	})
+
+	test.Run(sdk.SelfTestStepGetNode, func() (string, error) {
+		node, err := sdk.GetSelfTestNode(ctx, a.Client, hwmgr)
+		if err != nil {
+			return "", err
+		}
+		if node == nil {
+			return "No allocated nodes to fetch", nil
+		}
+
+		nodepool := &hwmgmtv1alpha1.NodePool{}
+		if err := a.Client.Get(ctx, types.NamespacedName{Name: node.Spec.NodePool, Namespace: node.Namespace}, nodepool); err != nil {
+			return "", fmt.Errorf("failed to get NodePool %s of node %s: %w", node.Spec.NodePool, node.Name, err)
+		}
+
+		if _, err := client.GetNode(ctx, allocatedNodeRequestParams(nodepool, node)); err != nil {
+			return "", fmt.Errorf("failed to get node %s: %w", node.Name, err)
+		}
+		return fmt.Sprintf("Fetched node %s (%s)", node.Name, node.Spec.HwMgrNodeId), nil
+	})
+
+	return test.Steps
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// NotifyAlarms posts the alarm event records to the notification URL of the hardware manager, if alarms are enabled.
+// The notification is signed with the callback signing secret of the PluginConfig, if configured, in the same way as
+// NodePool callbacks.
+func NotifyAlarms(
+	ctx context.Context,
+	c client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	records []utils.AlarmEventRecord) error {
+
+	if hwmgr.Spec.Alarms == nil || len(records) == 0 {
+		return nil
+	}
+
+	caBundle, err := GetCaBundle(ctx, c, hwmgr.Namespace, hwmgr.Spec.Alarms.CaBundleName)
+	if err != nil {
+		return fmt.Errorf("failed to get CA bundle for alarm notifications: %w", err)
+	}
+	httpClient, err := NewHTTPClient(HTTPClientConfig{CaBundle: caBundle, MaxRetries: -1})
+	if err != nil {
+		return fmt.Errorf("failed to setup http client: %w", err)
+	}
+
+	var key []byte
+	if secretName := utils.GetPluginSettings().CallbackSigningSecret; secretName != "" {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: hwmgr.Namespace}, secret); err != nil {
+			return fmt.Errorf("failed to get callback signing secret %s: %w", secretName, err)
+		}
+		key = secret.Data[utils.CallbackSigningKey]
+	}
+
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alarm event records: %w", err)
+	}
+
+	return postAlarms(ctx, httpClient, hwmgr.Spec.Alarms.NotificationURL, key, body, utils.GetCallbackTimeout())
+}
+
+// postAlarms posts the alarm event records, signed if a key is given, requiring a 2xx response
+func postAlarms(ctx context.Context, httpClient *http.Client, notificationURL string, key, body []byte, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notificationURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create alarm notification request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(key) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(utils.CallbackTimestampHeader, timestamp)
+		req.Header.Set(utils.CallbackSignatureHeader, utils.SignCallback(key, timestamp, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post alarm notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alarm notification rejected with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
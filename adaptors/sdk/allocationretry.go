@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// RetryNodePoolAllocation handles a failure to allocate the nodes of a NodePool. A transient failure is recorded
+// against the retry budget of the NodePool, and the allocation is retried after a backoff delay, reported in the
+// Provisioned condition. Once the budget is exhausted, or for input errors, unsupported operations, permanent
+// backend errors and incompatible hardware profiles, the NodePool is failed. Backend errors are also reported in the
+// BackendError condition, and incompatible hardware profiles in the ProfileCompatible condition. A request throttled
+// by the backend is retried after the delay it requests, without consuming the retry budget, and a change blocked in
+// read-only mode is returned to be retried once the plugin leaves read-only mode.
+func RetryNodePoolAllocation(
+	ctx context.Context,
+	c client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	allocErr error) (ctrl.Result, error) {
+
+	if errors.Is(allocErr, utils.ErrReadOnly) {
+		return utils.RequeueWithLongInterval(), allocErr
+	}
+
+	if err := ReportBackendError(ctx, c, nodepool, allocErr); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	if profileErr, ok := utils.AsProfileIncompatibleError(allocErr); ok {
+		if err := utils.UpdateNodePoolStatusCondition(ctx, c, nodepool,
+			utils.NodePoolProfileCompatible, utils.ReasonProfileIncompatible, metav1.ConditionFalse,
+			profileErr.Error()); err != nil {
+			return utils.RequeueWithShortInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return FailNodePool(ctx, c, nodepool, "Allocation failed: "+allocErr.Error())
+	}
+
+	if utils.IsInputError(allocErr) || errors.Is(allocErr, ErrNotSupported) || IsPermanentBackendError(allocErr) {
+		return FailNodePool(ctx, c, nodepool, "Allocation failed: "+allocErr.Error())
+	}
+
+	if delay, throttled := GetThrottleDelay(allocErr); throttled {
+		// The backend is busy rather than failing, so the retry budget is not consumed
+		if err := MarkNodePoolInProgress(ctx, c, nodepool,
+			fmt.Sprintf("Retrying allocation in %s, as requested by the rate limiting backend: %s",
+				delay, allocErr.Error())); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		RecordThrottleRequeue(hwmgr.Name)
+		return utils.RequeueWithCustomInterval(delay), nil
+	}
+
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	failures := utils.RecordAllocationFailure(hwmgr, nodepool, time.Now())
+	if err := c.Patch(ctx, nodepool, patch); err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to record allocation failure on NodePool %s: %w", nodepool.Name, err)
+	}
+
+	maxRetries, window := utils.GetAllocationRetryBudget(hwmgr)
+	if failures > maxRetries {
+		return FailNodePool(ctx, c, nodepool,
+			fmt.Sprintf("Allocation failed after %d retries within %s: %s", maxRetries, window, allocErr.Error()))
+	}
+
+	delay := utils.GetAllocationRetryDelay(failures)
+	if err := MarkNodePoolInProgress(ctx, c, nodepool,
+		fmt.Sprintf("Retrying allocation in %s after transient failure (%d/%d): %s",
+			delay, failures, maxRetries, allocErr.Error())); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	return utils.RequeueWithCustomInterval(delay), nil
+}
+
+// WaitForAllocationRetry returns the reconcile result to delay the processing of a NodePool until the backoff delay
+// of its last allocation failure has elapsed, and whether the processing is to be delayed
+func WaitForAllocationRetry(nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, bool) {
+	if wait := utils.GetAllocationRetryWait(nodepool, time.Now()); wait > 0 {
+		return utils.RequeueWithCustomInterval(wait), true
+	}
+	return ctrl.Result{}, false
+}
+
+// ResetAllocationRetries clears the recorded allocation failures of a NodePool, restoring its full retry budget once
+// it has been provisioned, along with any reported backend error or incompatible hardware profile
+func ResetAllocationRetries(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if err := ClearBackendError(ctx, c, nodepool); err != nil {
+		return err
+	}
+
+	if meta.IsStatusConditionFalse(nodepool.Status.Conditions, string(utils.NodePoolProfileCompatible)) {
+		if err := utils.UpdateNodePoolStatusCondition(ctx, c, nodepool,
+			utils.NodePoolProfileCompatible, utils.ReasonProfileCompatible, metav1.ConditionTrue,
+			"Allocated nodes satisfy the hardware profiles"); err != nil {
+			return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+	}
+
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	if !utils.ClearAllocationFailures(nodepool) {
+		return nil
+	}
+	if err := c.Patch(ctx, nodepool, patch); err != nil {
+		return fmt.Errorf("failed to clear allocation failures from NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// PublishNodeAssetInfo records the asset details reported by the backend on the Node CR, patching the node only if
+// they have changed. As this updates the node metadata, it should be called before any changes are made to the status.
+func PublishNodeAssetInfo(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node, info utils.NodeAssetInfo) error {
+	patch := client.MergeFrom(node.DeepCopy())
+	if !utils.SetNodeAssetInfo(node, info) {
+		return nil
+	}
+
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to publish asset info for node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// PublishNodeInterfaceRoles records the roles of the interfaces in the Node status on the Node CR, patching the node
+// only if they have changed. As this updates the node metadata, it should be called before any changes are made to
+// the status.
+func PublishNodeInterfaceRoles(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node, roles []utils.NodeInterfaceRole) error {
+	patch := client.MergeFrom(node.DeepCopy())
+	changed, err := utils.SetNodeInterfaceRoles(node, roles)
+	if err != nil {
+		return fmt.Errorf("failed to set interface roles for node %s: %w", node.Name, err)
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to publish interface roles for node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// PublishNodeBootCapabilities records the boot capabilities reported by the backend on the Node CR, patching the node
+// only if they have changed. As this updates the node metadata, it should be called before any changes are made to
+// the status.
+func PublishNodeBootCapabilities(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node, caps utils.NodeBootCapabilities) error {
+	patch := client.MergeFrom(node.DeepCopy())
+	changed, err := utils.SetNodeBootCapabilities(node, caps)
+	if err != nil {
+		return fmt.Errorf("failed to set boot capabilities for node %s: %w", node.Name, err)
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to publish boot capabilities for node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// PublishNodeInstallHints records the install hints reported by the backend on the Node CR, patching the node only if
+// they have changed. The provisioning MAC address, if not reported, is taken from the interface roles published on the
+// node, so this should be called after PublishNodeInterfaceRoles. As this updates the node metadata, it should be
+// called before any changes are made to the status.
+func PublishNodeInstallHints(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node, hints utils.NodeInstallHints) error {
+	roles, err := utils.GetNodeInterfaceRoles(node)
+	if err != nil {
+		return fmt.Errorf("failed to get interface roles for node %s: %w", node.Name, err)
+	}
+
+	hints = utils.ResolveInstallHints(hints, roles)
+	if err := utils.ValidateNodeInstallHints(hints); err != nil {
+		return fmt.Errorf("invalid install hints for node %s: %w", node.Name, err)
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	changed, err := utils.SetNodeInstallHints(node, hints)
+	if err != nil {
+		return fmt.Errorf("failed to set install hints for node %s: %w", node.Name, err)
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to publish install hints for node %s: %w", node.Name, err)
+	}
+	return nil
+}
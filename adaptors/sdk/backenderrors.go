@@ -0,0 +1,225 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// ErrorClass is the retriability class of a backend error
+type ErrorClass string
+
+const (
+	// ErrorClassTransient errors are expected to clear, and the request is retried
+	ErrorClassTransient ErrorClass = "Transient"
+	// ErrorClassPermanent errors require a change to the request or the backend, and the request is not retried
+	ErrorClassPermanent ErrorClass = "Permanent"
+)
+
+// BackendError condition type and reasons, reporting the last error returned by the backend for a NodePool
+const (
+	NodePoolBackendError        hwmgmtv1alpha1.ConditionType   = "BackendError"
+	ReasonBackendUnavailable    hwmgmtv1alpha1.ConditionReason = "BackendUnavailable"
+	ReasonBackendThrottled      hwmgmtv1alpha1.ConditionReason = "BackendThrottled"
+	ReasonBackendAuthFailed     hwmgmtv1alpha1.ConditionReason = "BackendAuthFailed"
+	ReasonBackendRejected       hwmgmtv1alpha1.ConditionReason = "BackendRejected"
+	ReasonBackendNotFound       hwmgmtv1alpha1.ConditionReason = "BackendNotFound"
+	ReasonBackendConflict       hwmgmtv1alpha1.ConditionReason = "BackendConflict"
+	ReasonInsufficientResources hwmgmtv1alpha1.ConditionReason = "InsufficientResources"
+	ReasonBackendFailure        hwmgmtv1alpha1.ConditionReason = "BackendFailure"
+	ReasonBackendRecovered      hwmgmtv1alpha1.ConditionReason = "Recovered"
+)
+
+// BackendError is an error response from a backend, translated to a condition reason and retriability class
+type BackendError struct {
+	// Operation is a description of the failed request
+	Operation string
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Code is the vendor error code from the response body, if any
+	Code string
+	// Message is the vendor error message from the response body, or the raw body if it could not be parsed
+	Message string
+	Reason  hwmgmtv1alpha1.ConditionReason
+	Class   ErrorClass
+	// RetryAfter is the delay requested by the backend before retrying, from the Retry-After header of the response
+	RetryAfter time.Duration
+}
+
+func (e *BackendError) Error() string {
+	status := fmt.Sprintf("%d", e.StatusCode)
+	if e.Code != "" {
+		status += ", code " + e.Code
+	}
+	msg := fmt.Sprintf("%s failed with status %s: %s", e.Operation, status, e.Reason)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// IsTransient returns true if the request is expected to succeed when retried
+func (e *BackendError) IsTransient() bool {
+	return e.Class == ErrorClassTransient
+}
+
+// AsBackendError returns the BackendError in the chain of err, if any
+func AsBackendError(err error) (*BackendError, bool) {
+	var backendErr *BackendError
+	if errors.As(err, &backendErr) {
+		return backendErr, true
+	}
+	return nil, false
+}
+
+// IsPermanentBackendError returns true if err holds a backend error that is not expected to succeed when retried
+func IsPermanentBackendError(err error) bool {
+	backendErr, ok := AsBackendError(err)
+	return ok && !backendErr.IsTransient()
+}
+
+// ErrorRule maps the backend error responses that it matches to a condition reason and retriability class. All of
+// the specified criteria must match.
+type ErrorRule struct {
+	// StatusCodes are the HTTP status codes matched by the rule. Any status code is matched if empty
+	StatusCodes []int
+	// Codes are the vendor error codes matched by the rule. Any code is matched if empty
+	Codes []string
+	// MessageContains is matched against the vendor error message, ignoring case. Any message is matched if empty
+	MessageContains string
+
+	Reason hwmgmtv1alpha1.ConditionReason
+	Class  ErrorClass
+}
+
+func (r *ErrorRule) matches(statusCode int, code, message string) bool {
+	if len(r.StatusCodes) > 0 && !slices.Contains(r.StatusCodes, statusCode) {
+		return false
+	}
+	if len(r.Codes) > 0 && !slices.Contains(r.Codes, code) {
+		return false
+	}
+	if r.MessageContains != "" && !strings.Contains(strings.ToLower(message), strings.ToLower(r.MessageContains)) {
+		return false
+	}
+	return true
+}
+
+// DefaultErrorRules map backend error responses by HTTP status code, and are applied after the rules of the adaptor
+var DefaultErrorRules = []ErrorRule{
+	{StatusCodes: []int{http.StatusUnauthorized}, Reason: ReasonBackendAuthFailed, Class: ErrorClassTransient},
+	{StatusCodes: []int{http.StatusForbidden}, Reason: ReasonBackendAuthFailed, Class: ErrorClassPermanent},
+	{StatusCodes: []int{http.StatusNotFound}, Reason: ReasonBackendNotFound, Class: ErrorClassPermanent},
+	{StatusCodes: []int{http.StatusConflict}, Reason: ReasonBackendConflict, Class: ErrorClassTransient},
+	{StatusCodes: []int{http.StatusTooManyRequests}, Reason: ReasonBackendThrottled, Class: ErrorClassTransient},
+	{StatusCodes: []int{http.StatusBadRequest, http.StatusUnprocessableEntity}, Reason: ReasonBackendRejected, Class: ErrorClassPermanent},
+	{StatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		Reason: ReasonBackendUnavailable, Class: ErrorClassTransient},
+}
+
+// ErrorMapper translates the error responses of a backend into BackendErrors
+type ErrorMapper struct {
+	// ParseBody extracts the vendor error code and message from a response body. The raw body is used as the message
+	// if unset, or if it returns an empty message
+	ParseBody func(body []byte) (code, message string)
+	// Rules are the vendor-specific mappings, applied in order ahead of the DefaultErrorRules
+	Rules []ErrorRule
+}
+
+// Map translates an error response from the backend. A response that matches no rule is mapped to BackendFailure,
+// which is transient for server errors and permanent otherwise.
+func (m *ErrorMapper) Map(operation string, statusCode int, body []byte) *BackendError {
+	backendErr := &BackendError{
+		Operation:  operation,
+		StatusCode: statusCode,
+	}
+	if m.ParseBody != nil {
+		backendErr.Code, backendErr.Message = m.ParseBody(body)
+	}
+	if backendErr.Message == "" {
+		backendErr.Message = strings.TrimSpace(string(body))
+	}
+
+	for _, rules := range [][]ErrorRule{m.Rules, DefaultErrorRules} {
+		for i := range rules {
+			if rules[i].matches(statusCode, backendErr.Code, backendErr.Message) {
+				backendErr.Reason = rules[i].Reason
+				backendErr.Class = rules[i].Class
+				return backendErr
+			}
+		}
+	}
+
+	backendErr.Reason = ReasonBackendFailure
+	backendErr.Class = ErrorClassPermanent
+	if statusCode >= http.StatusInternalServerError {
+		backendErr.Class = ErrorClassTransient
+	}
+	return backendErr
+}
+
+// MapResponse translates an error response from the backend, as Map, also recording the Retry-After delay of the
+// response, if any
+func (m *ErrorMapper) MapResponse(operation string, resp *http.Response, body []byte) *BackendError {
+	if resp == nil {
+		return m.Map(operation, 0, body)
+	}
+	backendErr := m.Map(operation, resp.StatusCode, body)
+	backendErr.RetryAfter = getRetryAfter(resp)
+	return backendErr
+}
+
+// ReportBackendError sets the BackendError condition of the NodePool, if err holds a backend error
+func ReportBackendError(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, err error) error {
+	backendErr, ok := AsBackendError(err)
+	if !ok {
+		return nil
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		NodePoolBackendError, backendErr.Reason, metav1.ConditionTrue,
+		fmt.Sprintf("%s backend error: %s", backendErr.Class, backendErr.Error())); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// ClearBackendError resets the BackendError condition of the NodePool, if set, once the backend requests succeed
+func ClearBackendError(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if !meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(NodePoolBackendError)) {
+		return nil
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		NodePoolBackendError, ReasonBackendRecovered, metav1.ConditionFalse, "Backend requests succeeded"); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	DefaultBMCEventRequestTimeout = 10 * time.Second
+
+	redfishSubscriptionsPath = "/redfish/v1/EventService/Subscriptions"
+	redfishSystemsPathPrefix = "/redfish/v1/Systems/"
+
+	// bmcEventsPath is the path of the listener at which the events of each node are delivered, followed by the
+	// namespace and name of the node
+	bmcEventsPath = "/redfish/events"
+
+	bmcEventsMaxBodySize     = 1 << 20
+	bmcEventsReadTimeout     = 5 * time.Second
+	bmcEventsShutdownTimeout = 10 * time.Second
+)
+
+// BMCEventSubscriber subscribes to the Redfish events of the BMC of allocated nodes, which are delivered to the BMC
+// event listener of the plugin
+type BMCEventSubscriber struct {
+	client      client.Client
+	listenerURL string
+}
+
+// NewBMCEventSubscriber returns a BMC event subscriber for the hardware manager, or nil if BMC events are not enabled
+func NewBMCEventSubscriber(c client.Client, hwmgr *pluginv1alpha1.HardwareManager) *BMCEventSubscriber {
+	if hwmgr.Spec.BMCEvents == nil {
+		return nil
+	}
+
+	return &BMCEventSubscriber{
+		client:      c,
+		listenerURL: strings.TrimSuffix(hwmgr.Spec.BMCEvents.ListenerURL, "/"),
+	}
+}
+
+// Subscribe creates an event subscription on the BMC of the node, recording it in the node annotations, and returns
+// true if the annotations were changed. A node that is already subscribed, or has not yet reported its BMC details, is
+// not subscribed. The annotations are not updated on the cluster.
+func (s *BMCEventSubscriber) Subscribe(ctx context.Context, node *hwmgmtv1alpha1.Node) (bool, error) {
+	if s == nil || node.Status.BMC == nil || node.Status.BMC.Address == "" ||
+		node.GetAnnotations()[utils.BMCEventSubscriptionAnnotation] != "" {
+		return false, nil
+	}
+
+	endpoint, err := bmcEndpoint(node.Status.BMC.Address)
+	if err != nil {
+		return false, err
+	}
+	username, password, err := getBMCCredentials(ctx, s.client, node)
+	if err != nil {
+		return false, err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return false, fmt.Errorf("failed to generate event token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	subscription := map[string]any{
+		"Destination":      s.listenerURL + bmcEventsPath + "/" + node.Namespace + "/" + node.Name,
+		"Context":          token,
+		"Protocol":         "Redfish",
+		"EventFormatType":  "Event",
+		"SubscriptionType": "RedfishEvent",
+	}
+	// A BMC address that identifies the system of the node, as with a multi-system BMC, restricts the subscription
+	// to the events of that system
+	if system := bmcSystemPath(node.Status.BMC.Address); system != "" {
+		subscription["OriginResources"] = []map[string]string{{"@odata.id": system}}
+	}
+	body, err := json.Marshal(subscription)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal subscription request: %w", err)
+	}
+
+	resp, err := redfishRequest(ctx, http.MethodPost, endpoint.JoinPath(redfishSubscriptionsPath).String(), username, password, body)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("BMC event subscription request failed with status %s", resp.Status)
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return false, fmt.Errorf("BMC event subscription response has no location")
+	}
+	if parsed, err := url.Parse(location); err == nil && parsed.IsAbs() {
+		// The subscription is deleted through the endpoint of the BMC address, which may differ from the host the
+		// BMC reports
+		location = parsed.RequestURI()
+	}
+
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[utils.BMCEventSubscriptionAnnotation] = location
+	annotations[utils.BMCEventTokenAnnotation] = token
+	node.SetAnnotations(annotations)
+
+	return true, nil
+}
+
+// UnsubscribeBMCEvents deletes the event subscription recorded in the node annotations from its BMC, removing it
+// from the annotations, and returns true if the annotations were changed. A subscription already deleted from the BMC
+// is treated as deleted. The annotations are not updated on the cluster.
+func UnsubscribeBMCEvents(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node) (bool, error) {
+	location := node.GetAnnotations()[utils.BMCEventSubscriptionAnnotation]
+	if location == "" {
+		return false, nil
+	}
+
+	if node.Status.BMC != nil && node.Status.BMC.Address != "" {
+		endpoint, err := bmcEndpoint(node.Status.BMC.Address)
+		if err != nil {
+			return false, err
+		}
+		subscriptionURL, err := endpoint.Parse(location)
+		if err != nil {
+			return false, fmt.Errorf("invalid BMC event subscription %q: %w", location, err)
+		}
+		username, password, err := getBMCCredentials(ctx, c, node)
+		if err != nil {
+			return false, err
+		}
+
+		resp, err := redfishRequest(ctx, http.MethodDelete, subscriptionURL.String(), username, password, nil)
+		if err != nil {
+			return false, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode != http.StatusNotFound {
+			return false, fmt.Errorf("BMC event subscription deletion failed with status %s", resp.Status)
+		}
+	}
+
+	annotations := node.GetAnnotations()
+	delete(annotations, utils.BMCEventSubscriptionAnnotation)
+	delete(annotations, utils.BMCEventTokenAnnotation)
+	node.SetAnnotations(annotations)
+
+	return true, nil
+}
+
+// redfishRequest sends a Redfish request to the BMC with basic auth. The BMC certificate is not verified, as BMCs
+// typically use self-signed certificates.
+func redfishRequest(ctx context.Context, method, target, username, password string, body []byte) (*http.Response, error) {
+	httpClient, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true, MaxRetries: -1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup http client: %w", err)
+	}
+	httpClient.Timeout = DefaultBMCEventRequestTimeout
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(username, password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to BMC: %w", err)
+	}
+	return resp, nil
+}
+
+// bmcSystemPath returns the path of the ComputerSystem identified by the BMC address, or an empty string if the
+// address does not identify a system
+func bmcSystemPath(address string) string {
+	_, path, found := strings.Cut(address, redfishSystemsPathPrefix)
+	if !found {
+		return ""
+	}
+	id, _, _ := strings.Cut(path, "/")
+	if id == "" {
+		return ""
+	}
+	return redfishSystemsPathPrefix + id
+}
+
+// redfishEventPayload is the payload of a Redfish event delivered to the listener
+type redfishEventPayload struct {
+	Context string
+	Events  []struct {
+		EventType         string
+		MessageId         string
+		Message           string
+		Severity          string
+		MessageSeverity   string
+		OriginOfCondition *struct {
+			ID string `json:"@odata.id"`
+		}
+	}
+}
+
+// BMCEventReceiver serves the BMC event listener, reflecting significant events reported by the BMC of a node in
+// the HardwareDegraded condition and the events of the Node, and propagating them as alarms if enabled
+type BMCEventReceiver struct {
+	Client    client.Client
+	Logger    *slog.Logger
+	Recorder  record.EventRecorder
+	Addr      string
+	Namespace string
+}
+
+// SetupWithManager adds the BMC event listener to the manager, if enabled
+func (r *BMCEventReceiver) SetupWithManager(mgr manager.Manager) error {
+	if r.Addr == "" || r.Addr == "0" {
+		return nil
+	}
+
+	if err := mgr.Add(manager.RunnableFunc(r.run)); err != nil {
+		return fmt.Errorf("failed to add BMC event listener: %w", err)
+	}
+
+	return nil
+}
+
+// run serves the BMC event listener until the context is canceled
+func (r *BMCEventReceiver) run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              r.Addr,
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: bmcEventsReadTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), bmcEventsShutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	r.Logger.Info("Starting BMC event listener", slog.String("address", srv.Addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("BMC event listener failed: %w", err)
+	}
+
+	return nil
+}
+
+// Handler returns the HTTP handler of the BMC event listener
+func (r *BMCEventReceiver) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+bmcEventsPath+"/{namespace}/{name}", r.receiveEvents)
+	return mux
+}
+
+// receiveEvents handles the events delivered for a node. Events are accepted only with the token of the subscription
+// of the node as their context, so a node that is no longer subscribed rejects the events of a stale subscription.
+func (r *BMCEventReceiver) receiveEvents(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	var payload redfishEventPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, bmcEventsMaxBodySize)).Decode(&payload); err != nil {
+		http.Error(w, "invalid event payload", http.StatusBadRequest)
+		return
+	}
+
+	node := &hwmgmtv1alpha1.Node{}
+	name := types.NamespacedName{Namespace: req.PathValue("namespace"), Name: req.PathValue("name")}
+	if err := r.Client.Get(ctx, name, node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			http.Error(w, "node not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get node", http.StatusInternalServerError)
+		return
+	}
+
+	token := node.GetAnnotations()[utils.BMCEventTokenAnnotation]
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(payload.Context)) != 1 {
+		http.Error(w, "unknown subscription", http.StatusUnauthorized)
+		return
+	}
+
+	changed := false
+	var events []utils.BMCEvent
+	for _, item := range payload.Events {
+		event := utils.BMCEvent{
+			MessageId: item.MessageId,
+			Message:   item.Message,
+			Severity:  item.MessageSeverity,
+		}
+		if event.Severity == "" {
+			// Severity is deprecated in favor of MessageSeverity, but is the only one reported by older BMCs
+			event.Severity = item.Severity
+		}
+		if item.OriginOfCondition != nil {
+			event.Origin = item.OriginOfCondition.ID
+		}
+
+		if event.IsSignificant() {
+			r.Logger.InfoContext(ctx, "Received BMC event",
+				slog.String("nodename", node.Name),
+				slog.String("event", event.String()))
+			if r.Recorder != nil {
+				r.Recorder.Event(node, corev1.EventTypeWarning, string(event.Reason()), event.String())
+			}
+		}
+		if utils.SetNodeHardwareDegradedCondition(node, event) {
+			changed = true
+		}
+		events = append(events, event)
+	}
+
+	if changed {
+		if err := utils.UpdateK8sCRStatus(ctx, r.Client, node, func() {
+			for _, event := range events {
+				utils.SetNodeHardwareDegradedCondition(node, event)
+			}
+		}); err != nil {
+			r.Logger.ErrorContext(ctx, "Failed to update Node status for BMC event",
+				slog.String("nodename", node.Name),
+				slog.String("error", err.Error()))
+			http.Error(w, "failed to update node status", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// The events have been reflected on the Node, so a failure to propagate the alarms is not returned to the BMC
+	if err := r.propagateAlarms(ctx, node, events); err != nil {
+		r.Logger.ErrorContext(ctx, "Failed to propagate hardware alarms",
+			slog.String("nodename", node.Name),
+			slog.String("error", err.Error()))
+		if r.Recorder != nil {
+			r.Recorder.Event(node, corev1.EventTypeWarning, "AlarmNotificationFailed", err.Error())
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// propagateAlarms updates the active alarms of the node from the events, notifying the changes if alarms are enabled
+// for its hardware manager. The active alarms are recorded once notified, so an alarm that fails to be notified is
+// raised again by the next event.
+func (r *BMCEventReceiver) propagateAlarms(ctx context.Context, node *hwmgmtv1alpha1.Node, events []utils.BMCEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr); err != nil {
+		return fmt.Errorf("failed to get HardwareManager %s: %w", node.Spec.HwMgrId, err)
+	}
+	if hwmgr.Spec.Alarms == nil {
+		return nil
+	}
+
+	base := node.DeepCopy()
+	updated := node.DeepCopy()
+	now := time.Now()
+	var records []utils.AlarmEventRecord
+	for _, event := range events {
+		if record := utils.UpdateNodeHardwareAlarm(updated, event, now); record != nil {
+			records = append(records, *record)
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := NotifyAlarms(ctx, r.Client, hwmgr, records); err != nil {
+		return err
+	}
+	r.Logger.InfoContext(ctx, "Propagated hardware alarms",
+		slog.String("nodename", node.Name),
+		slog.Int("alarms", len(records)))
+
+	if err := r.Client.Patch(ctx, updated, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to record active alarms of node %s: %w", node.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	DefaultBMCProbeTimeout = 5 * time.Second
+
+	redfishSessionsPath = "/redfish/v1/SessionService/Sessions"
+)
+
+// BMCUnreachable condition reasons, set on a Node when its BMC is probed
+const (
+	ReasonBMCReachable            hwmgmtv1alpha1.ConditionReason = "Reachable"
+	ReasonBMCConnectionFailed     hwmgmtv1alpha1.ConditionReason = "ConnectionFailed"
+	ReasonBMCAuthenticationFailed hwmgmtv1alpha1.ConditionReason = "AuthenticationFailed"
+)
+
+// bmcProbeError is a failed BMC probe, with the condition reason that describes the failure
+type bmcProbeError struct {
+	reason hwmgmtv1alpha1.ConditionReason
+	err    error
+}
+
+func (e *bmcProbeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *bmcProbeError) Unwrap() error {
+	return e.err
+}
+
+// BMCProber probes the BMC of allocated nodes, so that a wrong BMC address or credentials reported by the backend is
+// caught before the node is marked as provisioned
+type BMCProber struct {
+	client  client.Client
+	mode    pluginv1alpha1.BMCProbeMode
+	timeout time.Duration
+}
+
+// NewBMCProber returns a BMC prober for the hardware manager, or nil if the probe is not enabled
+func NewBMCProber(c client.Client, hwmgr *pluginv1alpha1.HardwareManager) *BMCProber {
+	if hwmgr.Spec.BMCProbe == nil {
+		return nil
+	}
+
+	prober := &BMCProber{
+		client:  c,
+		mode:    hwmgr.Spec.BMCProbe.Mode,
+		timeout: DefaultBMCProbeTimeout,
+	}
+	if prober.mode == "" {
+		prober.mode = pluginv1alpha1.BMCProbeModeTCP
+	}
+	if hwmgr.Spec.BMCProbe.Timeout != nil {
+		prober.timeout = hwmgr.Spec.BMCProbe.Timeout.Duration
+	}
+
+	return prober
+}
+
+// ProbeNode probes the BMC reported in the node status, returning true if it is reachable or the probe is not enabled.
+// If the probe fails, the BMCUnreachable condition is set and the Provisioned condition is set to Failed. A node that
+// has not yet reported its BMC details is not probed. The status is not updated on the cluster.
+func (p *BMCProber) ProbeNode(ctx context.Context, node *hwmgmtv1alpha1.Node) bool {
+	if p == nil || node.Status.BMC == nil || node.Status.BMC.Address == "" {
+		return true
+	}
+
+	err := p.probe(ctx, node)
+	if err == nil {
+		if meta.FindStatusCondition(node.Status.Conditions, string(utils.NodeBMCUnreachable)) != nil {
+			utils.SetStatusCondition(&node.Status.Conditions,
+				string(utils.NodeBMCUnreachable),
+				string(ReasonBMCReachable),
+				metav1.ConditionFalse,
+				"BMC reachable at "+node.Status.BMC.Address)
+		}
+		return true
+	}
+
+	reason := ReasonBMCConnectionFailed
+	var probeErr *bmcProbeError
+	if errors.As(err, &probeErr) {
+		reason = probeErr.reason
+	}
+	utils.SetStatusCondition(&node.Status.Conditions,
+		string(utils.NodeBMCUnreachable),
+		string(reason),
+		metav1.ConditionTrue,
+		err.Error())
+	utils.SetStatusCondition(&node.Status.Conditions,
+		string(hwmgmtv1alpha1.Provisioned),
+		string(hwmgmtv1alpha1.Failed),
+		metav1.ConditionFalse,
+		"BMC probe failed: "+err.Error())
+
+	return false
+}
+
+// probe runs the configured probe against the BMC of the node
+func (p *BMCProber) probe(ctx context.Context, node *hwmgmtv1alpha1.Node) error {
+	endpoint, err := bmcEndpoint(node.Status.BMC.Address)
+	if err != nil {
+		return &bmcProbeError{reason: ReasonBMCConnectionFailed, err: err}
+	}
+
+	if p.mode != pluginv1alpha1.BMCProbeModeRedfish {
+		return probeBMCConnection(ctx, endpoint, p.timeout)
+	}
+
+	username, password, err := getBMCCredentials(ctx, p.client, node)
+	if err != nil {
+		return &bmcProbeError{reason: ReasonBMCAuthenticationFailed, err: err}
+	}
+
+	return probeRedfishSession(ctx, endpoint, username, password, p.timeout)
+}
+
+// getBMCCredentials returns the username and password of the bmc-secret of the node
+func getBMCCredentials(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node) (string, string, error) {
+	secret, err := utils.GetSecret(ctx, c, node.Status.BMC.CredentialsName, node.Namespace)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get BMC credentials: %w", err)
+	}
+	username, err := utils.GetSecretField(secret, corev1.BasicAuthUsernameKey)
+	if err != nil {
+		return "", "", err
+	}
+	password, err := utils.GetSecretField(secret, corev1.BasicAuthPasswordKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return username, password, nil
+}
+
+// bmcEndpoint returns the base URL of the BMC from its address. The address may be a bare host, with an optional port,
+// or a URL with a driver-prefixed scheme such as redfish-virtualmedia+https, where the transport is https unless the
+// scheme ends in http.
+func bmcEndpoint(address string) (*url.URL, error) {
+	// IPv6 hosts are bracketed by normalization, so the address can be parsed as a URL
+	normalized, err := utils.NormalizeBMCAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BMC address %q: %w", address, err)
+	}
+	address = normalized
+
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+
+	parsed, err := url.Parse(address)
+	if err != nil || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("invalid BMC address %q", address)
+	}
+
+	scheme := "https"
+	if strings.HasSuffix(parsed.Scheme, "http") {
+		scheme = "http"
+	}
+
+	return &url.URL{Scheme: scheme, Host: parsed.Host}, nil
+}
+
+// probeBMCConnection checks that a TCP connection can be established to the BMC
+func probeBMCConnection(ctx context.Context, endpoint *url.URL, timeout time.Duration) error {
+	port := endpoint.Port()
+	if port == "" {
+		port = "443"
+		if endpoint.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(endpoint.Hostname(), port))
+	if err != nil {
+		return &bmcProbeError{reason: ReasonBMCConnectionFailed, err: fmt.Errorf("failed to connect to BMC: %w", err)}
+	}
+	_ = conn.Close()
+
+	return nil
+}
+
+// probeRedfishSession checks that a Redfish session can be created on the BMC with the credentials, deleting the
+// session on success. The BMC certificate is not verified, as BMCs typically use self-signed certificates.
+func probeRedfishSession(ctx context.Context, endpoint *url.URL, username, password string, timeout time.Duration) error {
+	httpClient, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true, MaxRetries: -1})
+	if err != nil {
+		return fmt.Errorf("failed to setup http client: %w", err)
+	}
+	httpClient.Timeout = timeout
+
+	// The session only exists for the duration of the probe
+	ctx = utils.WithReadOnlyExemption(ctx)
+
+	body, err := json.Marshal(map[string]string{"UserName": username, "Password": password})
+	if err != nil {
+		return fmt.Errorf("failed to marshal session request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.JoinPath(redfishSessionsPath).String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create session request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &bmcProbeError{reason: ReasonBMCConnectionFailed, err: fmt.Errorf("failed to connect to BMC: %w", err)}
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &bmcProbeError{reason: ReasonBMCAuthenticationFailed,
+			err: fmt.Errorf("BMC rejected credentials with status %s", resp.Status)}
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated:
+		return &bmcProbeError{reason: ReasonBMCConnectionFailed,
+			err: fmt.Errorf("BMC session request failed with status %s", resp.Status)}
+	}
+
+	// Clean up the session, which would otherwise count against the session limit of the BMC until it expires
+	deleteRedfishSession(ctx, httpClient, endpoint, resp.Header.Get("Location"), resp.Header.Get("X-Auth-Token"))
+
+	return nil
+}
+
+// deleteRedfishSession deletes a session created by the probe. Failures are ignored, as the session expires on the BMC.
+func deleteRedfishSession(ctx context.Context, httpClient *http.Client, endpoint *url.URL, location, token string) {
+	if location == "" {
+		return
+	}
+
+	sessionURL, err := endpoint.Parse(location)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, sessionURL.String(), nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("X-Auth-Token", token)
+
+	if resp, err := httpClient.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// ErrCallbackSigningNotConfigured is returned for a NodePool callback that cannot be sent, as no signing secret is
+// configured in the PluginConfig
+var ErrCallbackSigningNotConfigured = errors.New("callback signing secret not configured")
+
+// SendNodePoolCallback posts a signed notification to the callback URL of the NodePool if it has been provisioned or
+// has failed since the last notification, recording the notified state on the NodePool once delivered. The signing
+// secret is read from the plugin namespace.
+func SendNodePoolCallback(ctx context.Context, c client.Client, namespace string, nodepool *hwmgmtv1alpha1.NodePool) error {
+	notification := utils.GetPendingNodePoolCallback(nodepool)
+	if notification == nil {
+		return nil
+	}
+
+	secretName := utils.GetPluginSettings().CallbackSigningSecret
+	if secretName == "" {
+		return ErrCallbackSigningNotConfigured
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, secret); err != nil {
+		return fmt.Errorf("failed to get callback signing secret %s: %w", secretName, err)
+	}
+	key := secret.Data[utils.CallbackSigningKey]
+	if len(key) == 0 {
+		return fmt.Errorf("callback signing secret %s has no %s entry", secretName, utils.CallbackSigningKey)
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal callback notification: %w", err)
+	}
+
+	if err := postCallback(ctx, utils.GetNodePoolCallbackURL(nodepool), key, body, utils.GetCallbackTimeout()); err != nil {
+		return err
+	}
+
+	return utils.SetNodePoolCallbackNotified(ctx, c, nodepool)
+}
+
+// postCallback posts a signed callback body, requiring a 2xx response
+func postCallback(ctx context.Context, callbackURL string, key, body []byte, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(utils.CallbackTimestampHeader, timestamp)
+	req.Header.Set(utils.CallbackSignatureHeader, utils.SignCallback(key, timestamp, body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post callback: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback rejected with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BackendCertificate describes the certificate of a backend that expires soonest, among those presented by the
+// backend in its TLS handshake
+type BackendCertificate struct {
+	Subject  string
+	NotAfter time.Time
+}
+
+// Backend certificates are tracked by name, as backend clients are typically created for each reconcile
+var backendCertificates sync.Map
+
+// GetBackendCertificate returns the certificate last observed for the named backend, and whether one was observed
+func GetBackendCertificate(name string) (BackendCertificate, bool) {
+	cert, exists := backendCertificates.Load(name)
+	if !exists {
+		return BackendCertificate{}, false
+	}
+	return cert.(BackendCertificate), true
+}
+
+// ResetBackendCertificate discards the certificate observed for the named backend, such as when its connection
+// settings change
+func ResetBackendCertificate(name string) {
+	backendCertificates.Delete(name)
+}
+
+// CertificateExpiryTransport records the certificate of the backend that expires soonest on each TLS response, so
+// that the expiry of the backend certificates can be reported without a separate connection to the backend
+type CertificateExpiryTransport struct {
+	Base  http.RoundTripper
+	HwMgr string
+}
+
+func (t *CertificateExpiryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Base.RoundTrip(req)
+	if err == nil && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		var soonest BackendCertificate
+		for _, cert := range resp.TLS.PeerCertificates {
+			if soonest.NotAfter.IsZero() || cert.NotAfter.Before(soonest.NotAfter) {
+				soonest = BackendCertificate{Subject: cert.Subject.String(), NotAfter: cert.NotAfter}
+			}
+		}
+		backendCertificates.Store(t.HwMgr, soonest)
+	}
+	return resp, err // nolint: wrapcheck
+}
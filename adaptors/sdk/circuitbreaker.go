@@ -0,0 +1,177 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCircuitBreakerThreshold = 5
+	DefaultCircuitBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned for requests rejected without being sent, while the backend is considered unavailable
+var ErrCircuitOpen = errors.New("circuit breaker is open: backend is unavailable")
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitHalfOpen
+	CircuitOpen
+)
+
+// CircuitBreaker tracks consecutive failures of a backend, rejecting requests for a cooldown period once the
+// threshold is reached. After the cooldown, a single trial request is let through to probe the backend.
+type CircuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// Circuit breakers are shared by name, as backend clients are typically created for each reconcile
+var circuitBreakers sync.Map
+
+// GetCircuitBreaker returns the circuit breaker for the named backend, creating it if needed. The settings of an
+// existing breaker are updated, so that configuration changes take effect without a restart.
+func GetCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold == 0 {
+		threshold = DefaultCircuitBreakerThreshold
+	}
+	if cooldown == 0 {
+		cooldown = DefaultCircuitBreakerCooldown
+	}
+
+	cb, loaded := circuitBreakers.LoadOrStore(name, &CircuitBreaker{name: name, threshold: threshold, cooldown: cooldown})
+	breaker := cb.(*CircuitBreaker)
+	if !loaded {
+		breaker.setState(CircuitClosed)
+		return breaker
+	}
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	breaker.threshold = threshold
+	breaker.cooldown = cooldown
+	return breaker
+}
+
+// ResetCircuitBreaker closes the circuit breaker for the named backend, if one exists. This is used when the
+// connection settings of the backend change, as failures recorded against the previous settings no longer apply.
+func ResetCircuitBreaker(name string) {
+	cb, exists := circuitBreakers.Load(name)
+	if !exists {
+		return
+	}
+
+	breaker := cb.(*CircuitBreaker)
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	breaker.failures = 0
+	breaker.trial = false
+	if breaker.state != CircuitClosed {
+		breaker.setState(CircuitClosed)
+	}
+}
+
+// State returns the current state of the circuit breaker
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// setState must be called with the lock held, or before the breaker is shared
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	cb.state = state
+	backendCircuitBreakerState.WithLabelValues(cb.name).Set(float64(state))
+}
+
+// allow returns true if a request may be sent to the backend
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.setState(CircuitHalfOpen)
+		cb.trial = true
+		return true
+	case CircuitHalfOpen:
+		// Only the trial request is let through
+		if cb.trial {
+			return false
+		}
+		cb.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a request
+func (cb *CircuitBreaker) record(success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if success {
+		cb.failures = 0
+		cb.trial = false
+		if cb.state != CircuitClosed {
+			cb.setState(CircuitClosed)
+		}
+		return
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.threshold {
+		cb.trial = false
+		cb.openedAt = time.Now()
+		cb.setState(CircuitOpen)
+	}
+}
+
+// CircuitBreakerTransport rejects requests while the circuit breaker is open. Transport errors and server errors are
+// counted as failures.
+type CircuitBreakerTransport struct {
+	Base    http.RoundTripper
+	Breaker *CircuitBreaker
+}
+
+func (t *CircuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.Breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	t.Breaker.record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	return resp, err // nolint: wrapcheck
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// MarkNodePoolInProgress sets the Provisioned condition to InProgress
+func MarkNodePoolInProgress(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, message string) error {
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithProvisioned(hwmgmtv1alpha1.InProgress, message).
+		Update(ctx, c); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// AllocationQueuedMessage prefixes the Provisioned condition message of a NodePool with allocations queued by the
+// allocation throttle
+const AllocationQueuedMessage = "Waiting for allocation capacity on the backend"
+
+// MarkNodePoolAllocationQueued sets the Provisioned condition to InProgress, with the number of nodes queued by the
+// allocation throttle
+func MarkNodePoolAllocationQueued(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, queued int) error {
+	return MarkNodePoolInProgress(ctx, c, nodepool, fmt.Sprintf("%s: %d nodes queued", AllocationQueuedMessage, queued))
+}
+
+// IsNodePoolAllocationQueued returns true if the Provisioned condition reports allocations queued by the throttle
+func IsNodePoolAllocationQueued(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+	return condition != nil && strings.HasPrefix(condition.Message, AllocationQueuedMessage)
+}
+
+// MarkNodePoolProvisioned sets the Provisioned condition to Completed, and records the observed generation
+func MarkNodePoolProvisioned(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, message string) error {
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithProvisioned(hwmgmtv1alpha1.Completed, message).
+		WithObservedGeneration().
+		Update(ctx, c); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// FailNodePool sets the Provisioned condition to Failed, returning the reconcile result for a terminal failure.
+// If the status update itself fails, the request is requeued.
+func FailNodePool(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, message string) (ctrl.Result, error) {
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithProvisioned(hwmgmtv1alpha1.Failed, message).
+		Update(ctx, c); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// SetNodeProvisioned sets the Provisioned condition on a Node CR status, without updating the CR
+func SetNodeProvisioned(node *hwmgmtv1alpha1.Node) {
+	utils.SetStatusCondition(&node.Status.Conditions,
+		string(hwmgmtv1alpha1.Provisioned),
+		string(hwmgmtv1alpha1.Completed),
+		metav1.ConditionTrue,
+		"Provisioned")
+}
@@ -0,0 +1,26 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import "errors"
+
+// ErrNotSupported is returned by adaptors for operations that the backend does not support
+var ErrNotSupported = errors.New("operation not supported by the adaptor")
+
+// ErrReleaseDeferred is returned by adaptors when the release of a NodePool cannot proceed yet, such as when the
+// backend release rate is exceeded, so that the release is retried rather than the NodePool finalizer being removed
+var ErrReleaseDeferred = errors.New("nodepool release deferred")
@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// BeginNodePoolExtension returns the nodes being added to each nodegroup of a provisioned NodePool. On the first call
+// for a spec change, the extension is recorded on the NodePool and the Extended condition is set to Extending. The
+// existing nodes are left untouched by the extension, and the Provisioned condition is not changed.
+func BeginNodePoolExtension(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodelist *hwmgmtv1alpha1.NodeList) (map[string]int, error) {
+
+	if utils.IsNodePoolExtending(nodepool) {
+		extension, err := utils.GetNodePoolExtensionAnnotation(nodepool)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extension for NodePool %s: %w", nodepool.Name, err)
+		}
+		return extension, nil
+	}
+
+	extension := utils.GetNodePoolExtension(nodepool, nodelist)
+	if err := utils.SetNodePoolExtensionAnnotation(ctx, c, nodepool, extension); err != nil {
+		return nil, fmt.Errorf("failed to record extension of NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		utils.NodePoolExtended, utils.ReasonExtending, metav1.ConditionFalse,
+		"Adding nodes: "+utils.FormatNodePoolExtension(extension)); err != nil {
+		return nil, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return extension, nil
+}
+
+// FailNodePoolExtension reports a failure to allocate the nodes of an extension in the Extended condition, returning
+// the reconcile result to retry it. The NodePool remains provisioned with its existing nodes.
+func FailNodePoolExtension(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, message string) (ctrl.Result, error) {
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		utils.NodePoolExtended, utils.ReasonExtensionFailed, metav1.ConditionFalse, message); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return utils.RequeueWithMediumInterval(), nil
+}
+
+// CompleteNodePoolExtension reports the nodes added by the extension in the Extended condition, and clears the
+// recorded extension. The observed generation is recorded unless a hardware profile change of the existing nodes
+// remains to be applied, in which case the NodePool is then handled as a generic spec change.
+func CompleteNodePoolExtension(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodelist *hwmgmtv1alpha1.NodeList) error {
+
+	extension, err := utils.GetNodePoolExtensionAnnotation(nodepool)
+	if err != nil {
+		return fmt.Errorf("invalid extension for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		utils.NodePoolExtended, utils.ReasonExtended, metav1.ConditionTrue,
+		"Added nodes: "+utils.FormatNodePoolExtension(extension)); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if err := utils.SetNodePoolExtensionAnnotation(ctx, c, nodepool, nil); err != nil {
+		return fmt.Errorf("failed to clear extension of NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if utils.IsNodePoolHwProfileApplied(nodepool, nodelist) {
+		if err := utils.UpdateNodePoolPluginStatus(ctx, c, nodepool); err != nil {
+			return fmt.Errorf("failed to update plugin status for NodePool %s: %w", nodepool.Name, err)
+		}
+	}
+
+	return nil
+}
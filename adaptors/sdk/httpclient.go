@@ -0,0 +1,285 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+const (
+	// CaBundleKey is the configmap key holding a PEM encoded CA bundle for a backend
+	CaBundleKey = "ca-bundle.pem"
+
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 1 * time.Second
+)
+
+// Methods that are safe to retry without risk of duplicating a side effect on the backend
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// Methods that do not change the backend, which are permitted in read-only mode
+var safeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+}
+
+// Status codes for which a request to the backend is retried
+var retriableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// HTTPClientConfig defines the parameters used to build an HTTP client for communicating with a backend
+type HTTPClientConfig struct {
+	// PEM encoded CA bundle used to validate the backend certificate, in addition to the default root CAs
+	CaBundle []byte
+	// Optional proxy configuration, used in place of the proxy environment of the process
+	Proxy *pluginv1alpha1.ProxyConfig
+	// PEM encoded CA bundle trusted when communicating through the proxy, in addition to the CaBundle
+	ProxyCaBundle []byte
+	// Disables TLS verification, for testing
+	InsecureSkipTLSVerify bool
+	// Enables message tracing in the logs
+	LogMessages bool
+	// Maximum number of times an idempotent request is retried. A negative value disables retries, and zero uses the
+	// default of DefaultMaxRetries
+	MaxRetries int
+	// Delay before the first retry, doubling on each subsequent attempt. Zero uses the default of DefaultRetryBackoff
+	RetryBackoff time.Duration
+	// Longest Retry-After delay of a throttled response that is waited out before a retry. Responses with a longer delay
+	// are returned to the caller. Zero uses the default of DefaultMaxRetryAfter
+	MaxRetryAfter time.Duration
+	// Optional bearer token to add to each request
+	BearerToken string
+	// Name of the HardwareManager using the client. When set, backend requests are instrumented with metrics, the
+	// backend certificate expiry is tracked, and requests are protected by a circuit breaker shared by all clients for
+	// the HardwareManager
+	HwMgrName string
+	// Number of consecutive failures that opens the circuit breaker. A negative value disables the circuit breaker,
+	// and zero uses the default of DefaultCircuitBreakerThreshold
+	CircuitBreakerThreshold int
+	// Time the circuit breaker stays open before a trial request. Zero uses the default of DefaultCircuitBreakerCooldown
+	CircuitBreakerCooldown time.Duration
+}
+
+// GetCaBundle gets the CA bundle from the named configmap, returning nil if no configmap is specified
+func GetCaBundle(ctx context.Context, c client.Client, namespace string, name *string) ([]byte, error) {
+	if name == nil || *name == "" {
+		return nil, nil
+	}
+
+	cm, err := utils.GetConfigmap(ctx, c, *name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap: %w", err)
+	}
+
+	caBundle, err := utils.GetConfigMapField(cm, CaBundleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certificate bundle from configmap: %w", err)
+	}
+
+	return []byte(caBundle), nil
+}
+
+// GetProxyCaBundle gets the CA bundle for the proxy of the hardware manager, returning nil if none is specified
+func GetProxyCaBundle(ctx context.Context, c client.Client, hwmgr *pluginv1alpha1.HardwareManager) ([]byte, error) {
+	if hwmgr.Spec.Proxy == nil {
+		return nil, nil
+	}
+
+	return GetCaBundle(ctx, c, hwmgr.Namespace, hwmgr.Spec.Proxy.CaBundleName)
+}
+
+// NewHTTPClient creates an HTTP client for communicating with a backend, with TLS and proxy configuration, optional
+// bearer token authentication, correlation ID propagation, metrics, certificate expiry tracking, a circuit breaker,
+// retries of idempotent requests on transient failures, and the blocking of mutating requests in read-only mode
+func NewHTTPClient(config HTTPClientConfig) (*http.Client, error) {
+	proxy, err := utils.GetProxyFunc(config.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy configuration: %w", err)
+	}
+
+	caBundle := config.CaBundle
+	if len(config.ProxyCaBundle) != 0 {
+		caBundle = slices.Concat(config.CaBundle, []byte("\n"), config.ProxyCaBundle)
+	}
+
+	tr, err := utils.GetTransportWithCaBundle(utils.OAuthClientConfig{CaBundle: caBundle, Proxy: proxy},
+		config.InsecureSkipTLSVerify, config.LogMessages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get http transport: %w", err)
+	}
+
+	if config.BearerToken != "" {
+		tr = &BearerTokenTransport{Base: tr, Token: config.BearerToken}
+	}
+
+	tr = &CorrelationIdTransport{Base: tr}
+
+	if config.HwMgrName != "" {
+		tr = &CertificateExpiryTransport{Base: tr, HwMgr: config.HwMgrName}
+		tr = &MetricsTransport{Base: tr, HwMgr: config.HwMgrName}
+		if config.CircuitBreakerThreshold >= 0 {
+			tr = &CircuitBreakerTransport{
+				Base:    tr,
+				Breaker: GetCircuitBreaker(config.HwMgrName, config.CircuitBreakerThreshold, config.CircuitBreakerCooldown),
+			}
+		}
+	}
+
+	if config.MaxRetries >= 0 {
+		tr = &RetryTransport{
+			Base:          tr,
+			MaxRetries:    config.MaxRetries,
+			Backoff:       config.RetryBackoff,
+			MaxRetryAfter: config.MaxRetryAfter,
+		}
+	}
+
+	tr = &ReadOnlyTransport{Base: tr}
+
+	return &http.Client{Transport: tr}, nil
+}
+
+// ReadOnlyTransport rejects requests that may change the backend while the plugin is in read-only mode, other than
+// those made with a context exempted by utils.WithReadOnlyExemption
+type ReadOnlyTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *ReadOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if utils.IsReadOnly() && !slices.Contains(safeMethods, req.Method) && !utils.IsReadOnlyExempt(req.Context()) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: refusing to send %s request to %s", utils.ErrReadOnly, req.Method, req.URL.Redacted())
+	}
+	return t.Base.RoundTrip(req) // nolint: wrapcheck
+}
+
+// BearerTokenTransport adds an Authorization header with a bearer token to each request
+type BearerTokenTransport struct {
+	Base  http.RoundTripper
+	Token string
+}
+
+func (t *BearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	return t.Base.RoundTrip(req) // nolint: wrapcheck
+}
+
+// CorrelationIdTransport propagates the correlation ID from the request context to the backend, via the
+// X-Correlation-ID header, so that backend logs can be correlated with the plugin logs
+type CorrelationIdTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *CorrelationIdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := logging.GetCorrelationId(req.Context()); id != "" && req.Header.Get(logging.CorrelationIdHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(logging.CorrelationIdHeader, id)
+	}
+	return t.Base.RoundTrip(req) // nolint: wrapcheck
+}
+
+// RetryTransport retries idempotent requests that fail with a transport error or a retriable status code, with
+// exponential backoff. The Retry-After delay of a response is honored in place of the backoff, up to MaxRetryAfter.
+type RetryTransport struct {
+	Base          http.RoundTripper
+	MaxRetries    int
+	Backoff       time.Duration
+	MaxRetryAfter time.Duration
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxRetries := t.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	backoff := t.Backoff
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	maxRetryAfter := t.MaxRetryAfter
+	if maxRetryAfter == 0 {
+		maxRetryAfter = DefaultMaxRetryAfter
+	}
+
+	if !slices.Contains(idempotentMethods, req.Method) || (req.Body != nil && req.GetBody == nil) {
+		// The request cannot be safely retried
+		return t.Base.RoundTrip(req) // nolint: wrapcheck
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body for retry: %w", err)
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.Base.RoundTrip(attemptReq)
+		if attempt >= maxRetries || errors.Is(err, ErrCircuitOpen) || (err == nil && !slices.Contains(retriableStatusCodes, resp.StatusCode)) {
+			return resp, err // nolint: wrapcheck
+		}
+
+		delay := backoff << attempt
+		if retryAfter := getRetryAfter(resp); retryAfter > 0 {
+			if retryAfter > maxRetryAfter {
+				// Waiting would block the reconcile, so the caller requeues it instead
+				return resp, nil
+			}
+			delay = retryAfter
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, fmt.Errorf("request cancelled during retry backoff: %w", req.Context().Err())
+		case <-time.After(delay):
+		}
+	}
+}
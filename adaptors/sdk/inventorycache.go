@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"sync"
+	"time"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+const (
+	// InventoryRefreshAnnotation is set on a HardwareManager to discard its cached inventory. The cache is discarded
+	// each time the value changes, such as to the current time.
+	InventoryRefreshAnnotation = "hwmgr-plugin.oran.openshift.io/refreshInventory"
+
+	DefaultInventoryCacheTTL = 30 * time.Second
+)
+
+type inventoryCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// inventoryCache holds the results of the backend inventory queries of a HardwareManager, keyed by query
+type inventoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]inventoryCacheEntry
+	generation int64
+	refresh    string
+}
+
+// Inventory caches are shared by HardwareManager name, as adaptors and backend clients are not long-lived
+var inventoryCaches sync.Map
+
+// getInventoryCache returns the inventory cache for the HardwareManager, creating it if needed. The cache is emptied
+// when the HardwareManager spec or its refresh annotation changes, so that the cached results reflect the current
+// configuration. A stale copy of the HardwareManager, at an earlier generation, does not empty the cache.
+func getInventoryCache(hwmgr *pluginv1alpha1.HardwareManager) *inventoryCache {
+	value, _ := inventoryCaches.LoadOrStore(hwmgr.Name, &inventoryCache{entries: make(map[string]inventoryCacheEntry)})
+	cache := value.(*inventoryCache)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	refresh := hwmgr.GetAnnotations()[InventoryRefreshAnnotation]
+	if hwmgr.Generation > cache.generation || (refresh != "" && refresh != cache.refresh) {
+		cache.entries = make(map[string]inventoryCacheEntry)
+		cache.generation = hwmgr.Generation
+		cache.refresh = refresh
+	}
+	return cache
+}
+
+// GetInventoryCacheTTL returns the inventory cache TTL for a hardware manager, and whether caching is enabled
+func GetInventoryCacheTTL(hwmgr *pluginv1alpha1.HardwareManager) (time.Duration, bool) {
+	if hwmgr.Spec.InventoryCache == nil {
+		return 0, false
+	}
+
+	if hwmgr.Spec.InventoryCache.TTL != nil {
+		return hwmgr.Spec.InventoryCache.TTL.Duration, hwmgr.Spec.InventoryCache.TTL.Duration > 0
+	}
+
+	return DefaultInventoryCacheTTL, true
+}
+
+// CachedInventoryQuery returns the cached result of the inventory query identified by key, calling query to fetch the
+// result from the backend if there is no unexpired entry. Errors are not cached. The query is always called if caching
+// is not enabled for the HardwareManager. Cached results are shared by callers, so must not be modified.
+func CachedInventoryQuery[T any](hwmgr *pluginv1alpha1.HardwareManager, key string, query func() (T, error)) (T, error) {
+	ttl, enabled := GetInventoryCacheTTL(hwmgr)
+	if !enabled {
+		return query()
+	}
+
+	cache := getInventoryCache(hwmgr)
+
+	cache.mu.Lock()
+	entry, exists := cache.entries[key]
+	cache.mu.Unlock()
+	if exists && time.Now().Before(entry.expires) {
+		if value, ok := entry.value.(T); ok {
+			backendInventoryCacheQueries.WithLabelValues(hwmgr.Name, "hit").Inc()
+			return value, nil
+		}
+	}
+
+	backendInventoryCacheQueries.WithLabelValues(hwmgr.Name, "miss").Inc()
+	value, err := query()
+	if err != nil {
+		return value, err
+	}
+
+	cache.mu.Lock()
+	cache.entries[key] = inventoryCacheEntry{value: value, expires: time.Now().Add(ttl)}
+	cache.mu.Unlock()
+
+	return value, nil
+}
+
+// InvalidateInventoryCache discards the cached inventory of the named HardwareManager. This is called when nodes are
+// allocated or released, as the free nodes and node details of the backend are changed.
+func InvalidateInventoryCache(name string) {
+	value, exists := inventoryCaches.Load(name)
+	if !exists {
+		return
+	}
+
+	cache := value.(*inventoryCache)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries = make(map[string]inventoryCacheEntry)
+}
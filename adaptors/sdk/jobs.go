@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// JobStatus is the status of an asynchronous backend job
+type JobStatus int
+
+const (
+	JobStatusInProgress JobStatus = iota
+	JobStatusCompleted
+	JobStatusFailed
+	JobStatusUnknown
+)
+
+// JobChecker queries a backend for the status of a job, returning a failure reason for failed or unknown status
+type JobChecker func(ctx context.Context, jobId string) (status JobStatus, failReason string, err error)
+
+// JobResult is the outcome of a single poll of a job tracked on a CR
+type JobResult struct {
+	JobId      string
+	Status     JobStatus
+	FailReason string
+}
+
+// Done returns true if the job has reached a terminal state
+func (r JobResult) Done() bool {
+	return r.Status == JobStatusCompleted || r.Status == JobStatusFailed
+}
+
+// PollJob polls the status of the job tracked by the jobId annotation on the object. Jobs are polled
+// without blocking: the caller is expected to requeue with the result from JobPollRequeue until the job is done.
+func PollJob(ctx context.Context, object client.Object, check JobChecker) (JobResult, error) {
+	jobId := utils.GetJobId(object)
+	if jobId == "" {
+		return JobResult{}, fmt.Errorf("jobId annotation is missing or empty from %s", object.GetName())
+	}
+
+	status, failReason, err := check(ctx, jobId)
+	if err != nil {
+		return JobResult{JobId: jobId, Status: JobStatusUnknown}, fmt.Errorf("failed to check job progress, jobId=%s: %w", jobId, err)
+	}
+
+	return JobResult{JobId: jobId, Status: status, FailReason: failReason}, nil
+}
+
+// StartJob records the jobId annotation on the object, patching the CR, so the job is tracked across reconciles.
+// The job is also added to the job references of the object, and surfaced in its BackendJob status condition.
+func StartJob(ctx context.Context, c client.Client, object client.Object, jobId, operation string) error {
+	utils.SetJobId(object, jobId, operation)
+	if err := utils.CreateOrUpdateK8sCR(ctx, c, object, nil, utils.PATCH); err != nil {
+		return fmt.Errorf("failed to annotate %s with jobId %s: %w", object.GetName(), jobId, err)
+	}
+	if err := utils.UpdateBackendJobStatus(ctx, c, object); err != nil {
+		return fmt.Errorf("failed to record jobId %s: %w", jobId, err)
+	}
+	return nil
+}
+
+// UpdateJobPhase records the phase reported by the backend for the running job on the object, patching the CR and
+// updating its BackendJobPhase status condition if the phase has changed
+func UpdateJobPhase(ctx context.Context, c client.Client, object client.Object, jobId, phase string) error {
+	if phase == "" || !utils.SetJobPhase(object, jobId, phase) {
+		return nil
+	}
+	if err := utils.CreateOrUpdateK8sCR(ctx, c, object, nil, utils.PATCH); err != nil {
+		return fmt.Errorf("failed to record phase %s of jobId %s on %s: %w", phase, jobId, object.GetName(), err)
+	}
+	if err := utils.UpdateBackendJobStatus(ctx, c, object); err != nil {
+		return fmt.Errorf("failed to record phase %s of jobId %s: %w", phase, jobId, err)
+	}
+	return nil
+}
+
+// FinishJob clears the jobId annotation from the object, patching the CR, and marks the job as completed
+func FinishJob(ctx context.Context, c client.Client, object client.Object) error {
+	utils.ClearJobId(object)
+	if err := utils.CreateOrUpdateK8sCR(ctx, c, object, nil, utils.PATCH); err != nil {
+		return fmt.Errorf("failed to clear jobId annotation from %s: %w", object.GetName(), err)
+	}
+	if err := utils.UpdateBackendJobStatus(ctx, c, object); err != nil {
+		return fmt.Errorf("failed to record job completion: %w", err)
+	}
+	return nil
+}
+
+// JobPollRequeue returns the requeue result for a job that is still in progress
+func JobPollRequeue() ctrl.Result {
+	return utils.RequeueWithShortInterval()
+}
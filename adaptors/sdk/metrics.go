@@ -0,0 +1,212 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+const metricsSubsystem = "hwmgr_plugin_backend"
+
+var (
+	backendRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "requests_total",
+			Help:      "Number of requests sent to hardware manager backends, by endpoint and response code",
+		},
+		[]string{"hwmgr", "method", "endpoint", "code"},
+	)
+
+	backendRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests sent to hardware manager backends, by endpoint",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"hwmgr", "method", "endpoint"},
+	)
+
+	backendAuthFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "auth_failures_total",
+			Help:      "Number of requests to hardware manager backends rejected as unauthorized or forbidden",
+		},
+		[]string{"hwmgr", "method", "endpoint"},
+	)
+
+	backendThrottledResponses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "throttled_responses_total",
+			Help:      "Number of requests to hardware manager backends rejected by the backend rate limit",
+		},
+		[]string{"hwmgr", "method", "endpoint"},
+	)
+
+	backendThrottleRequeues = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "throttle_requeues_total",
+			Help:      "Number of NodePool reconciles requeued for the delay requested by a rate limiting backend",
+		},
+		[]string{"hwmgr"},
+	)
+
+	backendCircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "circuit_breaker_state",
+			Help:      "State of the circuit breaker for a hardware manager backend: 0=closed, 1=half-open, 2=open",
+		},
+		[]string{"hwmgr"},
+	)
+
+	backendAllocationsInProgress = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "allocations_in_progress",
+			Help:      "Number of nodes being actively provisioned against a hardware manager backend",
+		},
+		[]string{"hwmgr"},
+	)
+
+	backendAllocationsQueued = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "allocations_queued",
+			Help:      "Number of NodePools waiting for allocation capacity on a hardware manager backend",
+		},
+		[]string{"hwmgr"},
+	)
+
+	backendReleasesDeferred = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "releases_deferred_total",
+			Help:      "Number of node releases deferred by the release rate limit of a hardware manager backend",
+		},
+		[]string{"hwmgr"},
+	)
+
+	backendInventoryCacheQueries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "inventory_cache_queries_total",
+			Help:      "Number of backend inventory queries served by the inventory cache, by result: hit or miss",
+		},
+		[]string{"hwmgr", "result"},
+	)
+
+	nodeStatusUpdatesPending = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "node_status_updates_pending",
+			Help:      "Number of nodes with Node status updates queued for the write rate limit of a hardware manager",
+		},
+		[]string{"hwmgr"},
+	)
+
+	nodeStatusUpdatesCoalesced = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "node_status_updates_coalesced_total",
+			Help:      "Number of Node status updates coalesced with an update already queued for the node",
+		},
+		[]string{"hwmgr"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		backendRequests,
+		backendRequestDuration,
+		backendAuthFailures,
+		backendThrottledResponses,
+		backendThrottleRequeues,
+		backendCircuitBreakerState,
+		backendAllocationsInProgress,
+		backendAllocationsQueued,
+		backendReleasesDeferred,
+		backendInventoryCacheQueries,
+		nodeStatusUpdatesPending,
+		nodeStatusUpdatesCoalesced,
+	)
+}
+
+// Path segments that identify an instance, such as a UUID or numeric ID, are collapsed to bound the label cardinality
+var idSegmentRegex = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F-]{16,}|.*[0-9].*[0-9].*[0-9].*)$`)
+
+// metricsEndpoint returns the request path, with instance identifiers replaced by a placeholder
+func metricsEndpoint(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, segment := range segments {
+		if idSegmentRegex.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// RecordThrottleRequeue counts a NodePool reconcile requeued for the delay requested by a rate limiting backend
+func RecordThrottleRequeue(hwmgr string) {
+	backendThrottleRequeues.WithLabelValues(hwmgr).Inc()
+}
+
+// MetricsTransport records the request count, latency, response code, auth failures, and throttled responses of each
+// request to a backend, labeled by HardwareManager name
+type MetricsTransport struct {
+	Base  http.RoundTripper
+	HwMgr string
+}
+
+func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if utils.GetPluginSettings().DisableBackendRequestMetrics {
+		return t.Base.RoundTrip(req) // nolint: wrapcheck
+	}
+
+	endpoint := metricsEndpoint(req)
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	backendRequestDuration.WithLabelValues(t.HwMgr, req.Method, endpoint).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			backendAuthFailures.WithLabelValues(t.HwMgr, req.Method, endpoint).Inc()
+		}
+		if IsThrottled(resp) {
+			backendThrottledResponses.WithLabelValues(t.HwMgr, req.Method, endpoint).Inc()
+		}
+	}
+	backendRequests.WithLabelValues(t.HwMgr, req.Method, endpoint, code).Inc()
+
+	return resp, err // nolint: wrapcheck
+}
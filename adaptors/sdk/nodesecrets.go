@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// ApplyNodeSecrets creates or updates the additional secrets of a node defined by the hardware manager, rendered with
+// the secret data reported by the backend for the node. Each secret is owned by the Node, so that it is deleted with
+// it, and secrets that are no longer defined for the node are deleted.
+func ApplyNodeSecrets(
+	ctx context.Context,
+	c client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	backend map[string]string) error {
+
+	values := utils.NewNodeSecretValues(node, backend)
+	desired := make(map[string]bool)
+	for _, tmpl := range utils.GetNodeSecretTemplates(hwmgr, node.Spec.HwProfile) {
+		data, err := utils.RenderNodeSecretData(tmpl, values)
+		if err != nil {
+			return fmt.Errorf("failed to render secrets for node %s: %w", node.Name, err)
+		}
+
+		secretType := corev1.SecretTypeOpaque
+		if tmpl.Type != "" {
+			secretType = corev1.SecretType(tmpl.Type)
+		}
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      utils.NodeSecretName(node.Name, tmpl.Name),
+				Namespace: node.Namespace,
+				Labels:    map[string]string{utils.NodeSecretLabel: node.Name},
+			},
+			Type: secretType,
+			Data: data,
+		}
+		if err := utils.ApplyK8sCR(ctx, c, secret, node); err != nil {
+			return fmt.Errorf("failed to apply secret %s: %w", secret.Name, err)
+		}
+		desired[secret.Name] = true
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, client.InNamespace(node.Namespace), client.MatchingLabels{utils.NodeSecretLabel: node.Name}); err != nil {
+		return fmt.Errorf("failed to list secrets for node %s: %w", node.Name, err)
+	}
+	for i := range secrets.Items {
+		if desired[secrets.Items[i].Name] {
+			continue
+		}
+		if err := client.IgnoreNotFound(c.Delete(ctx, &secrets.Items[i])); err != nil {
+			return fmt.Errorf("failed to delete secret %s: %w", secrets.Items[i].Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxPages bounds the number of pages fetched by a single iteration, protecting against backends that never
+// return a final page
+const MaxPages = 1000
+
+// PageFetcher fetches a page of items from a backend, given the token returned with the previous page. The first
+// page is requested with an empty token, and an empty next token indicates the last page.
+type PageFetcher[T any] func(ctx context.Context, pageToken string) (items []T, nextPageToken string, err error)
+
+// OffsetPageFetcher fetches a page of at most limit items from a backend, starting at the given offset
+type OffsetPageFetcher[T any] func(ctx context.Context, offset, limit int) (items []T, err error)
+
+// ForEachPage calls fn for each page of items returned by the fetcher, stopping at the last page or on error
+func ForEachPage[T any](ctx context.Context, fetch PageFetcher[T], fn func(items []T) error) error {
+	pageToken := ""
+	for page := 0; page < MaxPages; page++ {
+		items, next, err := fetch(ctx, pageToken)
+		if err != nil {
+			return fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+
+		if err := fn(items); err != nil {
+			return err
+		}
+
+		if next == "" {
+			return nil
+		}
+		if next == pageToken {
+			return fmt.Errorf("backend returned a repeated page token on page %d", page)
+		}
+		pageToken = next
+	}
+
+	return fmt.Errorf("exceeded maximum of %d pages", MaxPages)
+}
+
+// Paginate collects all items returned by a token-based fetcher
+func Paginate[T any](ctx context.Context, fetch PageFetcher[T]) ([]T, error) {
+	var all []T
+	if err := ForEachPage(ctx, fetch, func(items []T) error {
+		all = append(all, items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// PaginateOffset collects all items returned by an offset-based fetcher, requesting pages of pageSize items until a
+// short page is returned
+func PaginateOffset[T any](ctx context.Context, pageSize int, fetch OffsetPageFetcher[T]) ([]T, error) {
+	return PaginateOffsetTotal(ctx, pageSize, func(ctx context.Context, offset, limit int) ([]T, int, error) {
+		items, err := fetch(ctx, offset, limit)
+		return items, -1, err
+	})
+}
+
+// OffsetTotalPageFetcher fetches a page of at most limit items from a backend, starting at the given offset, along with
+// the total number of items reported by the backend, or -1 if the total is not reported
+type OffsetTotalPageFetcher[T any] func(ctx context.Context, offset, limit int) (items []T, total int, err error)
+
+// PaginateOffsetTotal collects all items returned by an offset-based fetcher, requesting pages of pageSize items until
+// the reported total is reached or a short page is returned. A page larger than pageSize indicates that the backend has
+// ignored the limit and returned the full list, which ends the iteration.
+func PaginateOffsetTotal[T any](ctx context.Context, pageSize int, fetch OffsetTotalPageFetcher[T]) ([]T, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+
+	var all []T
+	for page := 0; page < MaxPages; page++ {
+		items, total, err := fetch(ctx, page*pageSize, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+
+		if page == 0 && len(items) > pageSize {
+			return items, nil
+		}
+
+		all = append(all, items...)
+		if len(items) < pageSize || (total >= 0 && len(all) >= total) {
+			return all, nil
+		}
+	}
+
+	return nil, fmt.Errorf("exceeded maximum of %d pages", MaxPages)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// ApplyBMCSecretProvenance records the provenance of the credentials on a bmc-secret, signing it with the provenance
+// signing secret, read from the plugin namespace, if one is configured in the PluginConfig. The secret data must be
+// set before calling this, and the secret is not updated on the cluster.
+func ApplyBMCSecretProvenance(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	secret *corev1.Secret,
+	provenance utils.BMCSecretProvenance) error {
+
+	var key []byte
+	if secretName := utils.GetPluginSettings().ProvenanceSigningSecret; secretName != "" {
+		signingSecret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, signingSecret); err != nil {
+			return fmt.Errorf("failed to get provenance signing secret %s: %w", secretName, err)
+		}
+		key = signingSecret.Data[utils.ProvenanceSigningKey]
+		if len(key) == 0 {
+			return fmt.Errorf("provenance signing secret %s has no %s entry", secretName, utils.ProvenanceSigningKey)
+		}
+	}
+
+	utils.SetBMCSecretProvenance(secret, provenance, key)
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultThrottleDelay is the delay before a throttled request is retried, when the backend does not specify one
+	DefaultThrottleDelay = 30 * time.Second
+	// DefaultMaxRetryAfter is the longest Retry-After delay waited out by the RetryTransport. Longer delays are
+	// returned to the caller, to requeue the reconcile rather than block it.
+	DefaultMaxRetryAfter = 30 * time.Second
+	// MaxThrottleDelay caps the delay requested by a backend, to guard against a misconfigured Retry-After header
+	MaxThrottleDelay = 1 * time.Hour
+)
+
+// ParseRetryAfter returns the delay specified by a Retry-After header value, either in seconds or as an HTTP date,
+// capped at MaxThrottleDelay. Zero is returned if the value is empty, invalid, or already elapsed.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		if seconds > int64(MaxThrottleDelay/time.Second) {
+			return MaxThrottleDelay
+		}
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	}
+
+	return min(max(delay, 0), MaxThrottleDelay)
+}
+
+// getRetryAfter returns the delay specified by the Retry-After header of a response, if any
+func getRetryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	return ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+// IsThrottled returns true if the response indicates that the backend is rate limiting requests, either with a 429
+// status or with a 503 status specifying a Retry-After delay
+func IsThrottled(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusServiceUnavailable && getRetryAfter(resp) > 0)
+}
+
+// GetThrottleDelay returns the delay requested by the backend before retrying, if err holds a backend error for a
+// throttled request. A throttled request without a Retry-After delay is retried after DefaultThrottleDelay.
+func GetThrottleDelay(err error) (time.Duration, bool) {
+	backendErr, ok := AsBackendError(err)
+	if !ok || !backendErr.IsTransient() {
+		return 0, false
+	}
+	if backendErr.RetryAfter > 0 {
+		return backendErr.RetryAfter, true
+	}
+	if backendErr.Reason == ReasonBackendThrottled {
+		return DefaultThrottleDelay, true
+	}
+	return 0, false
+}
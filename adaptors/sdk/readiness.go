@@ -0,0 +1,133 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Readiness check condition reasons
+const (
+	ReasonReadinessPassed  hwmgmtv1alpha1.ConditionReason = "Passed"
+	ReasonReadinessPending hwmgmtv1alpha1.ConditionReason = "Pending"
+)
+
+// ReadinessCheckFunc evaluates a readiness check for a node, returning whether the check passes and a message
+// describing the result
+type ReadinessCheckFunc func(node *hwmgmtv1alpha1.Node) (bool, string)
+
+// ReadinessChecker gates the Provisioned condition of a node on the readiness checks required by the hardware manager.
+// Default implementations evaluate the Node status, and adaptors with more detailed backend data may register their own.
+type ReadinessChecker struct {
+	required []pluginv1alpha1.ReadinessCheck
+	checks   map[pluginv1alpha1.ReadinessCheck]ReadinessCheckFunc
+}
+
+// NewReadinessChecker returns a readiness checker for the checks required by the hardware manager
+func NewReadinessChecker(hwmgr *pluginv1alpha1.HardwareManager) *ReadinessChecker {
+	return &ReadinessChecker{
+		required: hwmgr.Spec.ReadinessChecks,
+		checks: map[pluginv1alpha1.ReadinessCheck]ReadinessCheckFunc{
+			pluginv1alpha1.ReadinessCheckBMCReachable:         checkBMCReachable,
+			pluginv1alpha1.ReadinessCheckFirmwareCompliant:    checkFirmwareCompliant,
+			pluginv1alpha1.ReadinessCheckBIOSApplied:          checkBIOSApplied,
+			pluginv1alpha1.ReadinessCheckInterfacesDiscovered: checkInterfacesDiscovered,
+		},
+	}
+}
+
+// Register replaces the implementation of a readiness check
+func (r *ReadinessChecker) Register(check pluginv1alpha1.ReadinessCheck, fn ReadinessCheckFunc) {
+	r.checks[check] = fn
+}
+
+// MarkNodeProvisionedIfReady evaluates the required readiness checks, setting a condition on the node for each, and
+// sets the Provisioned condition to Completed if all pass, or to InProgress with the pending checks otherwise. Returns
+// true if the node is provisioned. The status is not updated on the cluster.
+func (r *ReadinessChecker) MarkNodeProvisionedIfReady(node *hwmgmtv1alpha1.Node) bool {
+	var pending []string
+	for _, check := range r.required {
+		fn, exists := r.checks[check]
+		if !exists {
+			continue
+		}
+
+		passed, message := fn(node)
+		reason, status := ReasonReadinessPassed, metav1.ConditionTrue
+		if !passed {
+			reason, status = ReasonReadinessPending, metav1.ConditionFalse
+			pending = append(pending, string(check))
+		}
+		utils.SetStatusCondition(&node.Status.Conditions, string(check), string(reason), status, message)
+	}
+
+	if len(pending) > 0 {
+		utils.SetStatusCondition(&node.Status.Conditions,
+			string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.InProgress),
+			metav1.ConditionFalse,
+			"Waiting for readiness checks: "+strings.Join(pending, ", "))
+		return false
+	}
+
+	SetNodeProvisioned(node)
+	return true
+}
+
+// checkBMCReachable passes once the BMC details are reported, and the power state, if reported, is known
+func checkBMCReachable(node *hwmgmtv1alpha1.Node) (bool, string) {
+	if node.Status.BMC == nil || node.Status.BMC.Address == "" || node.Status.BMC.CredentialsName == "" {
+		return false, "BMC details not yet reported"
+	}
+	if condition := meta.FindStatusCondition(node.Status.Conditions, string(utils.NodePowerState)); condition != nil &&
+		condition.Reason == string(utils.PowerStateUnknown) {
+		return false, "BMC not responding to power state queries"
+	}
+	return true, "BMC reachable at " + node.Status.BMC.Address
+}
+
+// checkFirmwareCompliant passes when no backend job, such as a firmware update, is in progress on the node
+func checkFirmwareCompliant(node *hwmgmtv1alpha1.Node) (bool, string) {
+	if jobId := utils.GetJobId(node); jobId != "" {
+		return false, fmt.Sprintf("Firmware update in progress (job %s)", jobId)
+	}
+	return true, "Firmware compliant"
+}
+
+// checkBIOSApplied passes once the hardware profile, with its BIOS settings, is applied to the node
+func checkBIOSApplied(node *hwmgmtv1alpha1.Node) (bool, string) {
+	if node.Status.HwProfile != node.Spec.HwProfile {
+		return false, fmt.Sprintf("Hardware profile %s not yet applied", node.Spec.HwProfile)
+	}
+	return true, fmt.Sprintf("Hardware profile %s applied", node.Spec.HwProfile)
+}
+
+// checkInterfacesDiscovered passes once the interfaces of the node are reported
+func checkInterfacesDiscovered(node *hwmgmtv1alpha1.Node) (bool, string) {
+	if len(node.Status.Interfaces) == 0 {
+		return false, "No interfaces discovered"
+	}
+	return true, fmt.Sprintf("%d interfaces discovered", len(node.Status.Interfaces))
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// ReleaseLimiter limits the rate of node releases sent to a backend. Up to a minute's worth of releases may be sent in
+// a burst, such as when a NodePool is deleted, after which releases are spread out at the configured rate.
+type ReleaseLimiter struct {
+	name string
+
+	mu      sync.Mutex
+	limit   int
+	limiter *rate.Limiter
+}
+
+// Release limiters are shared by HardwareManager name, as the bulk deletion of NodePools spans reconciles
+var releaseLimiters sync.Map
+
+// GetReleaseLimiter returns the release limiter for the named backend, updating its limit of releases per minute. A
+// limit of zero disables rate limiting.
+func GetReleaseLimiter(name string, perMinute int) *ReleaseLimiter {
+	l, _ := releaseLimiters.LoadOrStore(name, &ReleaseLimiter{name: name})
+	limiter := l.(*ReleaseLimiter)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if limiter.limit != perMinute || limiter.limiter == nil {
+		limiter.limit = perMinute
+		limiter.limiter = rate.NewLimiter(rate.Limit(float64(perMinute)/60), perMinute)
+	}
+	return limiter
+}
+
+// Allow returns true if a node release may be sent to the backend now, consuming a slot of the rate limit
+func (l *ReleaseLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return true
+	}
+
+	if !l.limiter.Allow() {
+		backendReleasesDeferred.WithLabelValues(l.name).Inc()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SchemaVersionKey is the field that records the schema version of the bookkeeping data persisted by an adaptor
+const SchemaVersionKey = "schemaVersion"
+
+// SchemaConverter converts the unstructured bookkeeping data of one schema version to the next
+type SchemaConverter func(data map[string]any) error
+
+// Schema describes the versions of the bookkeeping data persisted by an adaptor, such as in a configmap. Converters[i]
+// converts data of version i to version i+1, so the current version is the number of converters, with version 0 being
+// data written before the schema was versioned. Converters are only ever appended, as data of any earlier version may
+// be read after an upgrade of the plugin.
+type Schema struct {
+	Name       string
+	Converters []SchemaConverter
+}
+
+// Version returns the current version of the schema
+func (s Schema) Version() int {
+	return len(s.Converters)
+}
+
+// Decode parses the YAML bookkeeping data, converting it from the version it was written with to the current version
+// before it is unmarshalled into out, which should record the version in a SchemaVersionKey field so that it is
+// written back with the current version. The version the data was written with is returned. Data written with a newer
+// version, by a later release of the plugin, is rejected rather than risk losing fields when written back.
+func (s Schema) Decode(data []byte, out any) (int, error) {
+	raw := make(map[string]any)
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return 0, fmt.Errorf("failed to parse %s data: %w", s.Name, err)
+	}
+	if raw == nil {
+		// Empty data
+		raw = make(map[string]any)
+	}
+
+	version := 0
+	if value, exists := raw[SchemaVersionKey]; exists {
+		number, ok := value.(float64)
+		if !ok || number != float64(int(number)) || number < 0 {
+			return 0, fmt.Errorf("invalid %s %s: %v", s.Name, SchemaVersionKey, value)
+		}
+		version = int(number)
+	}
+	if version > s.Version() {
+		return version, fmt.Errorf("%s data has schema version %d, but only versions up to %d are supported",
+			s.Name, version, s.Version())
+	}
+
+	for v := version; v < s.Version(); v++ {
+		if err := s.Converters[v](raw); err != nil {
+			return version, fmt.Errorf("failed to convert %s data from schema version %d to %d: %w", s.Name, v, v+1, err)
+		}
+	}
+	raw[SchemaVersionKey] = s.Version()
+
+	converted, err := yaml.Marshal(raw)
+	if err != nil {
+		return version, fmt.Errorf("failed to marshal converted %s data: %w", s.Name, err)
+	}
+	if err := yaml.Unmarshal(converted, out); err != nil {
+		return version, fmt.Errorf("failed to parse converted %s data: %w", s.Name, err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,1056 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Pagination", func() {
+	pages := map[string][]int{
+		"":  {1, 2},
+		"b": {3, 4},
+		"c": {5},
+	}
+	next := map[string]string{"": "b", "b": "c", "c": ""}
+
+	It("collects all token-based pages", func() {
+		items, err := Paginate(context.Background(), func(_ context.Context, token string) ([]int, string, error) {
+			return pages[token], next[token], nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(items).To(Equal([]int{1, 2, 3, 4, 5}))
+	})
+
+	It("fails on a repeated page token", func() {
+		_, err := Paginate(context.Background(), func(_ context.Context, _ string) ([]int, string, error) {
+			return []int{1}, "same", nil
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("propagates fetch errors", func() {
+		_, err := Paginate(context.Background(), func(_ context.Context, _ string) ([]int, string, error) {
+			return nil, "", fmt.Errorf("backend unavailable")
+		})
+		Expect(err).To(MatchError(ContainSubstring("backend unavailable")))
+	})
+
+	It("collects all offset-based pages", func() {
+		data := []int{1, 2, 3, 4, 5}
+		items, err := PaginateOffset(context.Background(), 2, func(_ context.Context, offset, limit int) ([]int, error) {
+			return data[offset:min(offset+limit, len(data))], nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(items).To(Equal(data))
+	})
+
+	It("stops at the reported total", func() {
+		data := []int{1, 2, 3, 4}
+		calls := 0
+		items, err := PaginateOffsetTotal(context.Background(), 2, func(_ context.Context, offset, limit int) ([]int, int, error) {
+			calls++
+			return data[offset:min(offset+limit, len(data))], len(data), nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(items).To(Equal(data))
+		Expect(calls).To(Equal(2))
+	})
+
+	It("accepts the full list from a backend that ignores the limit", func() {
+		data := []int{1, 2, 3, 4, 5}
+		calls := 0
+		items, err := PaginateOffset(context.Background(), 2, func(_ context.Context, _, _ int) ([]int, error) {
+			calls++
+			return data, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(items).To(Equal(data))
+		Expect(calls).To(Equal(1))
+	})
+})
+
+var _ = Describe("HTTP client", func() {
+	It("retries idempotent requests on retriable status codes", func() {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Authorization")).To(Equal("Bearer abc"))
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		c, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true, BearerToken: "abc", RetryBackoff: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := c.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(calls.Load()).To(Equal(int32(3)))
+	})
+
+	It("propagates the correlation ID to the backend", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get(logging.CorrelationIdHeader)).To(Equal("abc-123"))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		c, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		req, err := http.NewRequestWithContext(logging.WithCorrelationId(context.Background(), "abc-123"), http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		resp, err := c.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("does not retry non-idempotent requests", func() {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		c, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true, RetryBackoff: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := c.Post(server.URL, "application/json", strings.NewReader("{}"))
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(calls.Load()).To(Equal(int32(1)))
+	})
+
+	It("sends requests through the configured proxy", func() {
+		var host string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.Host
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+
+		c, err := NewHTTPClient(HTTPClientConfig{
+			InsecureSkipTLSVerify: true,
+			Proxy:                 &pluginv1alpha1.ProxyConfig{HTTPProxy: proxy.URL, NoProxy: "internal.example.com"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := c.Get("http://hwmgr.example.com/api/v1/pools")
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(host).To(Equal("hwmgr.example.com"))
+	})
+
+	It("tracks the expiry of the backend certificate", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		_, exists := GetBackendCertificate("cert-test")
+		Expect(exists).To(BeFalse())
+
+		c, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true, HwMgrName: "cert-test"})
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := c.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		cert, exists := GetBackendCertificate("cert-test")
+		Expect(exists).To(BeTrue())
+		Expect(cert.NotAfter).To(Equal(server.Certificate().NotAfter))
+	})
+
+	It("honors the Retry-After delay of throttled responses", func() {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		c, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true, RetryBackoff: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		start := time.Now()
+		resp, err := c.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(calls.Load()).To(Equal(int32(2)))
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+	})
+
+	It("returns throttled responses with a long Retry-After delay to the caller", func() {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		c, err := NewHTTPClient(HTTPClientConfig{InsecureSkipTLSVerify: true, RetryBackoff: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := c.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(calls.Load()).To(Equal(int32(1)))
+
+		backendErr := (&ErrorMapper{}).MapResponse("get pools", resp, nil)
+		Expect(backendErr.Reason).To(Equal(ReasonBackendThrottled))
+		Expect(backendErr.RetryAfter).To(Equal(2 * time.Minute))
+	})
+
+	It("rejects an invalid proxy URL", func() {
+		_, err := NewHTTPClient(HTTPClientConfig{
+			InsecureSkipTLSVerify: true,
+			Proxy:                 &pluginv1alpha1.ProxyConfig{HTTPSProxy: "proxy.example.com:3128"},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Backend metrics", func() {
+	It("collapses instance identifiers in the endpoint label", func() {
+		req := httptest.NewRequest(http.MethodGet, "/v1/tenants/default/groups/6f1f5f0e-2c1d-4c56-9a36-1d0bba5b0a21/jobs/1234", nil)
+		Expect(metricsEndpoint(req)).To(Equal("/v1/tenants/default/groups/{id}/jobs/{id}"))
+	})
+
+	It("opens the circuit breaker after consecutive failures", func() {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		c, err := NewHTTPClient(HTTPClientConfig{
+			InsecureSkipTLSVerify:   true,
+			MaxRetries:              -1,
+			HwMgrName:               "breaker-test",
+			CircuitBreakerThreshold: 2,
+			CircuitBreakerCooldown:  time.Hour,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 2; i++ {
+			resp, err := c.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+		}
+
+		_, err = c.Get(server.URL)
+		Expect(err).To(MatchError(ErrCircuitOpen))
+		Expect(calls.Load()).To(Equal(int32(2)))
+		Expect(GetCircuitBreaker("breaker-test", 0, 0).State()).To(Equal(CircuitOpen))
+	})
+
+	It("closes the circuit breaker on reset", func() {
+		breaker := GetCircuitBreaker("reset-test", 1, time.Hour)
+		breaker.record(false)
+		Expect(breaker.State()).To(Equal(CircuitOpen))
+		Expect(breaker.allow()).To(BeFalse())
+
+		ResetCircuitBreaker("reset-test")
+		Expect(breaker.State()).To(Equal(CircuitClosed))
+		Expect(breaker.allow()).To(BeTrue())
+	})
+})
+
+var _ = Describe("Allocation throttle", func() {
+	It("queues allocations beyond the limit until slots are released", func() {
+		throttle := GetAllocationThrottle("throttle-test", 3)
+		Expect(throttle.TryAcquire("np1", 2)).To(BeTrue())
+		Expect(throttle.TryAcquire("np2", 2)).To(BeFalse())
+		Expect(throttle.TryAcquire("np2", 1)).To(BeTrue())
+		Expect(throttle.InUse()).To(Equal(3))
+
+		throttle.Set("np1", 1)
+		Expect(throttle.InUse()).To(Equal(2))
+		Expect(throttle.TryAcquire("np2", 1)).To(BeTrue())
+
+		throttle.Release("np1")
+		throttle.Release("np2")
+		Expect(throttle.InUse()).To(BeZero())
+	})
+
+	It("grants a request larger than the limit when idle", func() {
+		throttle := GetAllocationThrottle("throttle-large-test", 2)
+		Expect(throttle.TryAcquire("np1", 5)).To(BeTrue())
+		Expect(throttle.TryAcquire("np2", 1)).To(BeFalse())
+
+		throttle.Release("np1")
+		Expect(throttle.TryAcquire("np2", 1)).To(BeTrue())
+	})
+
+	It("does not throttle when the limit is zero", func() {
+		throttle := GetAllocationThrottle("throttle-unlimited-test", 0)
+		for i := 0; i < 10; i++ {
+			Expect(throttle.TryAcquire("np1", 10)).To(BeTrue())
+		}
+	})
+})
+
+var _ = Describe("Readiness checker", func() {
+	newNode := func() *hwmgmtv1alpha1.Node {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Name = "node1"
+		node.Spec.HwProfile = "profile-v2"
+		node.Status.HwProfile = "profile-v1"
+		node.Status.BMC = &hwmgmtv1alpha1.BMC{Address: "idrac-virtualmedia+https://10.0.0.1", CredentialsName: "node1-bmc-secret"}
+		return node
+	}
+
+	It("marks the node provisioned when no checks are required", func() {
+		node := newNode()
+		Expect(NewReadinessChecker(&pluginv1alpha1.HardwareManager{}).MarkNodeProvisionedIfReady(node)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))).To(BeTrue())
+	})
+
+	It("reports each check and waits for the pending ones", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		hwmgr.Spec.ReadinessChecks = []pluginv1alpha1.ReadinessCheck{
+			pluginv1alpha1.ReadinessCheckBMCReachable,
+			pluginv1alpha1.ReadinessCheckBIOSApplied,
+			pluginv1alpha1.ReadinessCheckInterfacesDiscovered,
+		}
+		checker := NewReadinessChecker(hwmgr)
+
+		node := newNode()
+		Expect(checker.MarkNodeProvisionedIfReady(node)).To(BeFalse())
+		Expect(meta.IsStatusConditionTrue(node.Status.Conditions, string(pluginv1alpha1.ReadinessCheckBMCReachable))).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(node.Status.Conditions, string(pluginv1alpha1.ReadinessCheckBIOSApplied))).To(BeTrue())
+		provisioned := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		Expect(provisioned).ToNot(BeNil())
+		Expect(provisioned.Reason).To(Equal(string(hwmgmtv1alpha1.InProgress)))
+		Expect(provisioned.Message).To(Equal("Waiting for readiness checks: BIOSApplied, InterfacesDiscovered"))
+
+		node.Status.HwProfile = node.Spec.HwProfile
+		node.Status.Interfaces = []*hwmgmtv1alpha1.Interface{{Name: "eth0", MACAddress: "00:00:00:01:20:30"}}
+		Expect(checker.MarkNodeProvisionedIfReady(node)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))).To(BeTrue())
+	})
+
+	It("uses registered checks in place of the defaults", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		hwmgr.Spec.ReadinessChecks = []pluginv1alpha1.ReadinessCheck{pluginv1alpha1.ReadinessCheckFirmwareCompliant}
+		checker := NewReadinessChecker(hwmgr)
+		checker.Register(pluginv1alpha1.ReadinessCheckFirmwareCompliant, func(node *hwmgmtv1alpha1.Node) (bool, string) {
+			return false, "Firmware below baseline"
+		})
+
+		node := newNode()
+		Expect(checker.MarkNodeProvisionedIfReady(node)).To(BeFalse())
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(pluginv1alpha1.ReadinessCheckFirmwareCompliant))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Message).To(Equal("Firmware below baseline"))
+	})
+})
+
+var _ = Describe("BMC probe", func() {
+	newNode := func(address string) *hwmgmtv1alpha1.Node {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Name = "node1"
+		node.Status.BMC = &hwmgmtv1alpha1.BMC{Address: address, CredentialsName: "node1-bmc-secret"}
+		return node
+	}
+
+	newHwMgr := func() *pluginv1alpha1.HardwareManager {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		hwmgr.Spec.BMCProbe = &pluginv1alpha1.BMCProbeConfig{Timeout: &metav1.Duration{Duration: time.Second}}
+		return hwmgr
+	}
+
+	It("derives the endpoint from the BMC address", func() {
+		endpoint, err := bmcEndpoint("idrac-virtualmedia+https://10.0.0.1/redfish/v1/Systems/System.Embedded.1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(endpoint.String()).To(Equal("https://10.0.0.1"))
+
+		endpoint, err = bmcEndpoint("redfish+http://10.0.0.1:8000/redfish/v1/Systems/1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(endpoint.String()).To(Equal("http://10.0.0.1:8000"))
+
+		endpoint, err = bmcEndpoint("10.0.0.1:8443")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(endpoint.String()).To(Equal("https://10.0.0.1:8443"))
+
+		endpoint, err = bmcEndpoint("redfish+https://[fd00::1]:8443/redfish/v1/Systems/1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(endpoint.String()).To(Equal("https://[fd00::1]:8443"))
+
+		endpoint, err = bmcEndpoint("fd00::1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(endpoint.String()).To(Equal("https://[fd00::1]"))
+		Expect(endpoint.Hostname()).To(Equal("fd00::1"))
+	})
+
+	It("does not probe when disabled", func() {
+		Expect(NewBMCProber(nil, &pluginv1alpha1.HardwareManager{})).To(BeNil())
+		Expect(NewBMCProber(nil, &pluginv1alpha1.HardwareManager{}).ProbeNode(context.Background(), newNode("192.0.2.1"))).To(BeTrue())
+	})
+
+	It("passes when the BMC accepts connections", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		node := newNode(strings.Replace(server.URL, "http://", "redfish+http://", 1))
+		Expect(NewBMCProber(nil, newHwMgr()).ProbeNode(context.Background(), node)).To(BeTrue())
+		Expect(meta.FindStatusCondition(node.Status.Conditions, string(utils.NodeBMCUnreachable))).To(BeNil())
+	})
+
+	It("fails the node when the BMC is unreachable", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		address := strings.Replace(server.URL, "http://", "redfish+http://", 1)
+		server.Close()
+
+		node := newNode(address)
+		Expect(NewBMCProber(nil, newHwMgr()).ProbeNode(context.Background(), node)).To(BeFalse())
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(utils.NodeBMCUnreachable))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(ReasonBMCConnectionFailed)))
+		provisioned := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		Expect(provisioned).ToNot(BeNil())
+		Expect(provisioned.Reason).To(Equal(string(hwmgmtv1alpha1.Failed)))
+	})
+
+	It("creates and deletes a Redfish session", func() {
+		var deleted atomic.Bool
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+				w.Header().Set("X-Auth-Token", "token")
+				w.WriteHeader(http.StatusCreated)
+			case http.MethodDelete:
+				deleted.Store(r.Header.Get("X-Auth-Token") == "token")
+			}
+		}))
+		defer server.Close()
+
+		endpoint, err := bmcEndpoint(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(probeRedfishSession(context.Background(), endpoint, "admin", "password", time.Second)).To(Succeed())
+		Expect(deleted.Load()).To(BeTrue())
+	})
+
+	It("reports rejected Redfish credentials", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		endpoint, err := bmcEndpoint(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		err = probeRedfishSession(context.Background(), endpoint, "admin", "wrong", time.Second)
+		var probeErr *bmcProbeError
+		Expect(errors.As(err, &probeErr)).To(BeTrue())
+		Expect(probeErr.reason).To(Equal(ReasonBMCAuthenticationFailed))
+	})
+})
+
+var _ = Describe("Release limiter", func() {
+	It("defers releases once a minute's worth have been sent", func() {
+		limiter := GetReleaseLimiter("release-limit-test", 3)
+		for i := 0; i < 3; i++ {
+			Expect(limiter.Allow()).To(BeTrue())
+		}
+		Expect(limiter.Allow()).To(BeFalse())
+
+		// A change of limit takes effect immediately
+		Expect(GetReleaseLimiter("release-limit-test", 5).Allow()).To(BeTrue())
+	})
+
+	It("does not limit releases when the limit is zero", func() {
+		limiter := GetReleaseLimiter("release-unlimited-test", 0)
+		for i := 0; i < 100; i++ {
+			Expect(limiter.Allow()).To(BeTrue())
+		}
+	})
+})
+
+var _ = Describe("Backend error mapping", func() {
+	mapper := &ErrorMapper{
+		ParseBody: func(body []byte) (string, string) {
+			code, message, _ := strings.Cut(string(body), ":")
+			return code, message
+		},
+		Rules: []ErrorRule{
+			{Codes: []string{"E42"}, MessageContains: "no capacity", Reason: ReasonInsufficientResources, Class: ErrorClassTransient},
+		},
+	}
+
+	It("applies the adaptor rules ahead of the defaults", func() {
+		backendErr := mapper.Map("allocate node", http.StatusBadRequest, []byte("E42:No capacity in pool"))
+		Expect(backendErr.Reason).To(Equal(ReasonInsufficientResources))
+		Expect(backendErr.IsTransient()).To(BeTrue())
+		Expect(backendErr.Error()).To(Equal("allocate node failed with status 400, code E42: InsufficientResources: No capacity in pool"))
+
+		backendErr = mapper.Map("allocate node", http.StatusBadRequest, []byte("E7:invalid profile"))
+		Expect(backendErr.Reason).To(Equal(ReasonBackendRejected))
+		Expect(backendErr.IsTransient()).To(BeFalse())
+	})
+
+	It("classifies unmatched responses by status code", func() {
+		Expect(mapper.Map("op", http.StatusInternalServerError, nil).Class).To(Equal(ErrorClassTransient))
+		Expect(mapper.Map("op", http.StatusTeapot, nil).Class).To(Equal(ErrorClassPermanent))
+		Expect(mapper.Map("op", http.StatusTeapot, nil).Reason).To(Equal(ReasonBackendFailure))
+	})
+
+	It("finds backend errors through wrapping", func() {
+		err := fmt.Errorf("failed CreateResourceGroup: %w", mapper.Map("op", http.StatusNotFound, nil))
+		Expect(IsPermanentBackendError(err)).To(BeTrue())
+		Expect(IsPermanentBackendError(errors.New("connection refused"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("Backend rate limiting", func() {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	DescribeTable("parses Retry-After header values",
+		func(value string, expected time.Duration) {
+			Expect(ParseRetryAfter(value, now)).To(Equal(expected))
+		},
+		Entry("delay in seconds", "90", 90*time.Second),
+		Entry("HTTP date", "Sat, 01 Jun 2024 12:02:00 GMT", 2*time.Minute),
+		Entry("elapsed HTTP date", "Sat, 01 Jun 2024 11:00:00 GMT", time.Duration(0)),
+		Entry("excessive delay", "86400", MaxThrottleDelay),
+		Entry("negative delay", "-5", time.Duration(0)),
+		Entry("invalid value", "soon", time.Duration(0)),
+		Entry("empty value", "", time.Duration(0)),
+	)
+
+	It("identifies throttled responses", func() {
+		retryAfter := http.Header{"Retry-After": []string{"10"}}
+		Expect(IsThrottled(&http.Response{StatusCode: http.StatusTooManyRequests})).To(BeTrue())
+		Expect(IsThrottled(&http.Response{StatusCode: http.StatusServiceUnavailable, Header: retryAfter})).To(BeTrue())
+		Expect(IsThrottled(&http.Response{StatusCode: http.StatusServiceUnavailable})).To(BeFalse())
+		Expect(IsThrottled(&http.Response{StatusCode: http.StatusBadRequest, Header: retryAfter})).To(BeFalse())
+	})
+
+	It("returns the throttle delay of backend errors", func() {
+		mapper := &ErrorMapper{}
+
+		delay, throttled := GetThrottleDelay(fmt.Errorf("failed allocation: %w",
+			mapper.Map("allocate node", http.StatusTooManyRequests, nil)))
+		Expect(throttled).To(BeTrue())
+		Expect(delay).To(Equal(DefaultThrottleDelay))
+
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"45"}}}
+		delay, throttled = GetThrottleDelay(mapper.MapResponse("allocate node", resp, nil))
+		Expect(throttled).To(BeTrue())
+		Expect(delay).To(Equal(45 * time.Second))
+
+		_, throttled = GetThrottleDelay(mapper.Map("allocate node", http.StatusServiceUnavailable, nil))
+		Expect(throttled).To(BeFalse())
+		_, throttled = GetThrottleDelay(errors.New("connection refused"))
+		Expect(throttled).To(BeFalse())
+	})
+})
+
+var _ = Describe("NodePool callback", func() {
+	It("posts a signed notification", func() {
+		var timestamp, signature, body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			timestamp = r.Header.Get(utils.CallbackTimestampHeader)
+			signature = r.Header.Get(utils.CallbackSignatureHeader)
+		}))
+		defer server.Close()
+
+		Expect(postCallback(context.Background(), server.URL, []byte("secret"), []byte(`{"status":"Failed"}`), time.Second)).To(Succeed())
+		Expect(body).To(Equal(`{"status":"Failed"}`))
+		Expect(timestamp).ToNot(BeEmpty())
+		Expect(signature).To(Equal(utils.SignCallback([]byte("secret"), timestamp, []byte(body))))
+	})
+
+	It("reports a rejected notification", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := postCallback(context.Background(), server.URL, []byte("secret"), []byte(`{}`), time.Second)
+		Expect(err).To(MatchError(ContainSubstring("status 403")))
+	})
+
+	It("does not send without a signing secret", func() {
+		nodepool := &hwmgmtv1alpha1.NodePool{
+			Spec: hwmgmtv1alpha1.NodePoolSpec{Extensions: map[string]string{utils.CallbackURLKey: "https://example.com"}},
+			Status: hwmgmtv1alpha1.NodePoolStatus{Conditions: []metav1.Condition{{
+				Type: string(hwmgmtv1alpha1.Provisioned), Status: metav1.ConditionTrue, Reason: string(hwmgmtv1alpha1.Completed),
+			}}},
+		}
+		Expect(SendNodePoolCallback(context.Background(), nil, "plugin", nodepool)).To(MatchError(ErrCallbackSigningNotConfigured))
+	})
+})
+
+var _ = Describe("Self-test", func() {
+	It("records the steps run, stopping at the first failure", func() {
+		test := &SelfTest{}
+		Expect(test.Run(SelfTestStepAuthenticate, func() (string, error) { return "Authenticated", nil })).To(BeTrue())
+		Expect(test.Run(SelfTestStepListResourcePools, func() (string, error) { return "", errors.New("timed out") })).To(BeFalse())
+		Expect(test.Run(SelfTestStepGetNode, func() (string, error) {
+			Fail("step run after a failure")
+			return "", nil
+		})).To(BeFalse())
+
+		Expect(test.Passed()).To(BeFalse())
+		Expect(test.Steps).To(HaveLen(2))
+		Expect(test.Steps[0].Passed).To(BeTrue())
+		Expect(test.Steps[0].Message).To(Equal("Authenticated"))
+		Expect(test.Steps[1].Name).To(Equal(SelfTestStepListResourcePools))
+		Expect(test.Steps[1].Passed).To(BeFalse())
+		Expect(test.Steps[1].Message).To(Equal("timed out"))
+	})
+})
+
+var _ = Describe("Inventory cache", func() {
+	newHwMgr := func(name string) *pluginv1alpha1.HardwareManager {
+		return &pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 1},
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				InventoryCache: &pluginv1alpha1.InventoryCacheConfig{},
+			},
+		}
+	}
+
+	var calls int
+	query := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	BeforeEach(func() {
+		calls = 0
+	})
+
+	It("serves repeated queries from the cache until invalidated", func() {
+		hwmgr := newHwMgr("cache-invalidate")
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(1))
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(1))
+		Expect(CachedInventoryQuery(hwmgr, "nodes", query)).To(Equal(2))
+
+		InvalidateInventoryCache(hwmgr.Name)
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(3))
+	})
+
+	It("discards the cache on a spec change or refresh request", func() {
+		hwmgr := newHwMgr("cache-refresh")
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(1))
+
+		hwmgr.Generation = 2
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(2))
+
+		hwmgr.Annotations = map[string]string{InventoryRefreshAnnotation: "now"}
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(3))
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(3))
+	})
+
+	It("does not cache errors, or when disabled", func() {
+		hwmgr := newHwMgr("cache-errors")
+		_, err := CachedInventoryQuery(hwmgr, "pools", func() (int, error) { return 0, errors.New("unavailable") })
+		Expect(err).To(HaveOccurred())
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(1))
+
+		hwmgr = newHwMgr("cache-disabled")
+		hwmgr.Spec.InventoryCache = nil
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(2))
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(3))
+	})
+
+	It("expires entries after the TTL", func() {
+		hwmgr := newHwMgr("cache-ttl")
+		hwmgr.Spec.InventoryCache.TTL = &metav1.Duration{Duration: 10 * time.Millisecond}
+		Expect(CachedInventoryQuery(hwmgr, "pools", query)).To(Equal(1))
+		Eventually(func() (int, error) { return CachedInventoryQuery(hwmgr, "pools", query) }).Should(BeNumerically(">", 1))
+	})
+})
+
+var _ = Describe("Schema versioning", func() {
+	type record struct {
+		SchemaVersion int      `json:"schemaVersion"`
+		Names         []string `json:"names"`
+	}
+
+	// Version 1 renamed the "items" field to "names"
+	schema := Schema{
+		Name: "records",
+		Converters: []SchemaConverter{
+			func(data map[string]any) error {
+				data["names"] = data["items"]
+				delete(data, "items")
+				return nil
+			},
+		},
+	}
+
+	It("converts unversioned data to the current version", func() {
+		var out record
+		version, err := schema.Decode([]byte("items: [a, b]\n"), &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version).To(Equal(0))
+		Expect(out).To(Equal(record{SchemaVersion: 1, Names: []string{"a", "b"}}))
+	})
+
+	It("decodes current data without conversion", func() {
+		var out record
+		version, err := schema.Decode([]byte("schemaVersion: 1\nnames: [c]\n"), &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version).To(Equal(1))
+		Expect(out).To(Equal(record{SchemaVersion: 1, Names: []string{"c"}}))
+
+		version, err = schema.Decode([]byte(""), &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version).To(Equal(0))
+	})
+
+	It("rejects data written with a newer or invalid version", func() {
+		var out record
+		_, err := schema.Decode([]byte("schemaVersion: 2\nnames: [c]\n"), &out)
+		Expect(err).To(MatchError(ContainSubstring("only versions up to 1 are supported")))
+
+		_, err = schema.Decode([]byte("schemaVersion: one\n"), &out)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// objectClient serves Get requests from a set of objects, for code that reads the bmc-secret or Node without an API
+// server
+type objectClient struct {
+	client.Client
+	objects []client.Object
+}
+
+func (c objectClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	for _, object := range c.objects {
+		if object.GetName() == key.Name && object.GetNamespace() == key.Namespace &&
+			reflect.TypeOf(object) == reflect.TypeOf(obj) {
+			reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(object.DeepCopyObject()).Elem())
+			return nil
+		}
+	}
+	return k8serrors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+var _ = Describe("BMC events", func() {
+	newNode := func(address string) *hwmgmtv1alpha1.Node {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Name = "node1"
+		node.Namespace = "test"
+		node.Status.BMC = &hwmgmtv1alpha1.BMC{Address: address, CredentialsName: "node1-bmc-secret"}
+		return node
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1-bmc-secret", Namespace: "test"},
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("admin"),
+			corev1.BasicAuthPasswordKey: []byte("password"),
+		},
+	}
+
+	newHwMgr := func() *pluginv1alpha1.HardwareManager {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		hwmgr.Spec.BMCEvents = &pluginv1alpha1.BMCEventConfig{ListenerURL: "https://events.example.com/"}
+		return hwmgr
+	}
+
+	It("derives the system from the BMC address", func() {
+		Expect(bmcSystemPath("redfish+https://10.0.0.1/redfish/v1/Systems/1")).To(Equal("/redfish/v1/Systems/1"))
+		Expect(bmcSystemPath("idrac-virtualmedia://10.0.0.1/redfish/v1/Systems/System.Embedded.1/")).To(
+			Equal("/redfish/v1/Systems/System.Embedded.1"))
+		Expect(bmcSystemPath("10.0.0.1")).To(BeEmpty())
+	})
+
+	It("does not subscribe when disabled", func() {
+		Expect(NewBMCEventSubscriber(nil, &pluginv1alpha1.HardwareManager{})).To(BeNil())
+		changed, err := NewBMCEventSubscriber(nil, &pluginv1alpha1.HardwareManager{}).Subscribe(context.Background(), newNode("10.0.0.1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+	})
+
+	It("subscribes and unsubscribes the node", func() {
+		var request map[string]any
+		var deleted atomic.Bool
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == redfishSubscriptionsPath:
+				_ = json.NewDecoder(r.Body).Decode(&request)
+				w.Header().Set("Location", "https://bmc.example.com"+redfishSubscriptionsPath+"/7")
+				w.WriteHeader(http.StatusCreated)
+			case r.Method == http.MethodDelete && r.URL.Path == redfishSubscriptionsPath+"/7":
+				deleted.Store(true)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		c := objectClient{objects: []client.Object{secret}}
+		node := newNode(strings.Replace(server.URL, "https://", "redfish+https://", 1) + "/redfish/v1/Systems/1")
+		changed, err := NewBMCEventSubscriber(c, newHwMgr()).Subscribe(context.Background(), node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(request).To(HaveKeyWithValue("Destination", "https://events.example.com/redfish/events/test/node1"))
+		Expect(request).To(HaveKeyWithValue("Context", node.Annotations[utils.BMCEventTokenAnnotation]))
+		Expect(request["OriginResources"]).To(ConsistOf(HaveKeyWithValue("@odata.id", "/redfish/v1/Systems/1")))
+		Expect(node.Annotations).To(HaveKeyWithValue(utils.BMCEventSubscriptionAnnotation, redfishSubscriptionsPath+"/7"))
+
+		// An existing subscription is not replaced
+		changed, err = NewBMCEventSubscriber(c, newHwMgr()).Subscribe(context.Background(), node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		changed, err = UnsubscribeBMCEvents(context.Background(), c, node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(deleted.Load()).To(BeTrue())
+		Expect(node.Annotations).ToNot(HaveKey(utils.BMCEventSubscriptionAnnotation))
+		Expect(node.Annotations).ToNot(HaveKey(utils.BMCEventTokenAnnotation))
+	})
+
+	It("rejects events without the token of the subscription", func() {
+		node := newNode("10.0.0.1")
+		node.Annotations = map[string]string{utils.BMCEventTokenAnnotation: "token"}
+		receiver := &BMCEventReceiver{
+			Client: objectClient{objects: []client.Object{node}},
+			Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+
+		post := func(path, body string) int {
+			recorder := httptest.NewRecorder()
+			receiver.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+			return recorder.Code
+		}
+
+		Expect(post("/redfish/events/test/node1", "not json")).To(Equal(http.StatusBadRequest))
+		Expect(post("/redfish/events/test/node2", `{"Context":"token"}`)).To(Equal(http.StatusNotFound))
+		Expect(post("/redfish/events/test/node1", `{"Context":"wrong"}`)).To(Equal(http.StatusUnauthorized))
+		Expect(post("/redfish/events/test/node1", `{"Context":"token","Events":[{"MessageSeverity":"OK"}]}`)).To(
+			Equal(http.StatusNoContent))
+	})
+})
+
+var _ = Describe("Alarm notifications", func() {
+	It("does not notify when alarms are disabled", func() {
+		Expect(NotifyAlarms(context.Background(), nil, &pluginv1alpha1.HardwareManager{},
+			[]utils.AlarmEventRecord{{AlarmEventRecordID: "1"}})).To(Succeed())
+	})
+
+	It("posts the signed alarm event records", func() {
+		key := []byte("secret")
+		var records []utils.AlarmEventRecord
+		status := http.StatusNoContent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(r.Header.Get(utils.CallbackSignatureHeader)).To(
+				Equal(utils.SignCallback(key, r.Header.Get(utils.CallbackTimestampHeader), body)))
+			Expect(json.Unmarshal(body, &records)).To(Succeed())
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		sent := []utils.AlarmEventRecord{{
+			AlarmEventRecordID: "3d1f6b1e-0000-5000-8000-000000000001",
+			ResourceID:         "dummy-sp-64g-0",
+			PerceivedSeverity:  utils.AlarmSeverityCritical,
+			AlarmRaisedTime:    metav1.NewTime(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)),
+		}}
+		body, err := json.Marshal(sent)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(postAlarms(context.Background(), server.Client(), server.URL+"/alarms", key, body, time.Second)).To(Succeed())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].AlarmEventRecordID).To(Equal(sent[0].AlarmEventRecordID))
+		Expect(records[0].PerceivedSeverity).To(Equal(utils.AlarmSeverityCritical))
+		Expect(records[0].AlarmRaisedTime.Equal(&sent[0].AlarmRaisedTime)).To(BeTrue())
+
+		status = http.StatusBadRequest
+		Expect(postAlarms(context.Background(), server.Client(), server.URL+"/alarms", key, body, time.Second)).To(
+			MatchError(ContainSubstring("status 400")))
+	})
+})
+
+var _ = Describe("Read-only mode", func() {
+	AfterEach(func() {
+		utils.SetReadOnly(false)
+	})
+
+	It("blocks mutating backend requests", func() {
+		var methods []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		httpClient := &http.Client{Transport: &ReadOnlyTransport{Base: http.DefaultTransport}}
+		send := func(ctx context.Context, method string) error {
+			req, err := http.NewRequestWithContext(ctx, method, server.URL+"/nodes", strings.NewReader("{}"))
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := httpClient.Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		}
+
+		Expect(send(context.Background(), http.MethodPost)).To(Succeed())
+
+		utils.SetReadOnly(true)
+		Expect(send(context.Background(), http.MethodGet)).To(Succeed())
+		Expect(send(context.Background(), http.MethodPost)).To(MatchError(utils.ErrReadOnly))
+		Expect(send(context.Background(), http.MethodDelete)).To(MatchError(utils.ErrReadOnly))
+		Expect(send(utils.WithReadOnlyExemption(context.Background()), http.MethodPost)).To(Succeed())
+		Expect(methods).To(Equal([]string{http.MethodPost, http.MethodGet, http.MethodPost}))
+	})
+
+	It("leaves an allocation blocked in read-only mode to be retried", func() {
+		nodepool := &hwmgmtv1alpha1.NodePool{}
+		nodepool.Name = "nodepool"
+		utils.SetStatusCondition(&nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.InProgress), metav1.ConditionFalse, "Handling creation")
+		expected := nodepool.DeepCopy()
+
+		allocErr := fmt.Errorf("failed to create node: %w", utils.ErrReadOnly)
+		result, err := RetryNodePoolAllocation(context.Background(), nil, &pluginv1alpha1.HardwareManager{}, nodepool,
+			allocErr)
+		Expect(err).To(MatchError(utils.ErrReadOnly))
+		Expect(result).To(Equal(utils.RequeueWithLongInterval()))
+		Expect(nodepool).To(Equal(expected))
+	})
+})
+
+var _ = Describe("Node status queue", func() {
+	var written []string
+
+	BeforeEach(func() {
+		written = nil
+		writeNodeStatus = func(_ context.Context, _ client.Client, object client.Object, mutate func()) error {
+			mutate()
+			node := object.(*hwmgmtv1alpha1.Node)
+			written = append(written, node.Name+"="+node.Status.HwProfile)
+			return nil
+		}
+		DeferCleanup(func() { writeNodeStatus = utils.UpdateK8sCRStatus })
+	})
+
+	newNode := func(name string) *hwmgmtv1alpha1.Node {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Name = name
+		node.Namespace = "test"
+		return node
+	}
+	setProfile := func(hwprofile string) NodeStatusUpdate {
+		return func(node *hwmgmtv1alpha1.Node) bool {
+			if node.Status.HwProfile == hwprofile {
+				return false
+			}
+			node.Status.HwProfile = hwprofile
+			return true
+		}
+	}
+
+	It("coalesces the updates queued for a node into a single write", func() {
+		c := objectClient{objects: []client.Object{newNode("node1"), newNode("node2")}}
+		queue := GetNodeStatusQueue("status-queue-coalesce-test", 100)
+
+		queue.Enqueue(newNode("node1"), setProfile("profile-a"))
+		queue.Enqueue(newNode("node2"), setProfile("profile-a"))
+		queue.Enqueue(newNode("node1"), setProfile("profile-b"))
+		Expect(queue.Pending()).To(Equal(2))
+
+		Expect(queue.Flush(context.Background(), c)).To(Succeed())
+		Expect(queue.Pending()).To(BeZero())
+		Expect(written).To(Equal([]string{"node1=profile-b", "node2=profile-a"}))
+	})
+
+	It("skips unchanged and deleted nodes", func() {
+		c := objectClient{objects: []client.Object{newNode("node1")}}
+		queue := GetNodeStatusQueue("status-queue-skip-test", 100)
+
+		queue.Enqueue(newNode("node1"), setProfile(""))
+		queue.Enqueue(newNode("node3"), setProfile("profile-a"))
+		Expect(queue.Flush(context.Background(), c)).To(Succeed())
+		Expect(written).To(BeEmpty())
+	})
+
+	It("leaves the updates queued when the context is done", func() {
+		c := objectClient{objects: []client.Object{newNode("node1"), newNode("node2"), newNode("node3")}}
+		queue := GetNodeStatusQueue("status-queue-limit-test", 1)
+
+		for _, name := range []string{"node1", "node2", "node3"} {
+			queue.Enqueue(newNode(name), setProfile("profile-a"))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		Expect(queue.Flush(ctx, c)).ToNot(Succeed())
+		Expect(written).To(Equal([]string{"node1=profile-a"}))
+		Expect(queue.Pending()).To(Equal(2))
+	})
+})
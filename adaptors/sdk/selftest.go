@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Self-test steps, run in order by each adaptor
+const (
+	SelfTestStepAuthenticate      = "Authenticate"
+	SelfTestStepListResourcePools = "ListResourcePools"
+	SelfTestStepGetNode           = "GetNode"
+)
+
+// SelfTest records the results of the steps of a HardwareManager self-test. The steps are non-destructive, and each
+// depends on the previous, so the steps following a failure are not run.
+type SelfTest struct {
+	Steps  []pluginv1alpha1.SelfTestStep
+	failed bool
+}
+
+// Run runs a step of the self-test, recording its outcome and duration, and returns true if it passed. The step
+// returns a message describing its outcome on success.
+func (t *SelfTest) Run(name string, step func() (string, error)) bool {
+	if t.failed {
+		return false
+	}
+
+	start := time.Now()
+	message, err := step()
+	result := pluginv1alpha1.SelfTestStep{
+		Name:                 name,
+		Passed:               err == nil,
+		Message:              message,
+		DurationMilliseconds: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Message = err.Error()
+		t.failed = true
+	}
+
+	t.Steps = append(t.Steps, result)
+	return !t.failed
+}
+
+// Passed returns true if all steps run have passed
+func (t *SelfTest) Passed() bool {
+	return !t.failed
+}
+
+// DescribeResourcePools returns the message for a successful resource pool query
+func DescribeResourcePools(pools []string) string {
+	if len(pools) == 0 {
+		return "No resource pools found"
+	}
+	return fmt.Sprintf("Found %d resource pools: %s", len(pools), strings.Join(pools, ", "))
+}
+
+// GetSelfTestNode returns the allocated node of the hardware manager, first by name, whose details are fetched by the
+// self-test of adaptors that can only query allocated nodes. Nil is returned if the hardware manager has no nodes.
+func GetSelfTestNode(ctx context.Context, c client.Client, hwmgr *pluginv1alpha1.HardwareManager) (*hwmgmtv1alpha1.Node, error) {
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	if err := c.List(ctx, nodelist); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodes := slices.DeleteFunc(nodelist.Items, func(node hwmgmtv1alpha1.Node) bool {
+		return node.Spec.HwMgrId != hwmgr.Name || node.Spec.HwMgrNodeId == ""
+	})
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	node := slices.MinFunc(nodes, func(a, b hwmgmtv1alpha1.Node) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return &node, nil
+}
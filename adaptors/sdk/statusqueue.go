@@ -0,0 +1,198 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// DefaultNodeStatusUpdatesPerSecond is the rate of Node status writes for a hardware manager that does not set
+// maxNodeStatusUpdatesPerSecond
+const DefaultNodeStatusUpdatesPerSecond = 10
+
+// NodeStatusUpdate makes a change to the status of a Node, returning true if the status has changed
+type NodeStatusUpdate func(node *hwmgmtv1alpha1.Node) bool
+
+// NodeStatusQueue batches the Node status updates of a hardware manager, such as the interface refreshes of a periodic
+// resync, so that they are written to the API server at a limited rate rather than in a spike across a large fleet.
+// The updates queued for a node are coalesced into a single write, made to the latest copy of the node when it is
+// written.
+type NodeStatusQueue struct {
+	name string
+
+	mu      sync.Mutex
+	limit   int
+	limiter *rate.Limiter
+	pending map[types.NamespacedName][]NodeStatusUpdate
+	order   []types.NamespacedName
+}
+
+// Node status queues are shared by HardwareManager name, so that the writes of concurrent reconciles are held to the
+// same rate
+var nodeStatusQueues sync.Map
+
+// writeNodeStatus writes the status of a Node, replaced in tests
+var writeNodeStatus = utils.UpdateK8sCRStatus
+
+// GetNodeStatusQueue returns the Node status queue for the named hardware manager, updating its limit of writes per
+// second. A limit of zero uses DefaultNodeStatusUpdatesPerSecond.
+func GetNodeStatusQueue(name string, perSecond int) *NodeStatusQueue {
+	q, _ := nodeStatusQueues.LoadOrStore(name, &NodeStatusQueue{
+		name:    name,
+		pending: make(map[types.NamespacedName][]NodeStatusUpdate),
+	})
+	queue := q.(*NodeStatusQueue)
+
+	if perSecond <= 0 {
+		perSecond = DefaultNodeStatusUpdatesPerSecond
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if queue.limit != perSecond || queue.limiter == nil {
+		queue.limit = perSecond
+		queue.limiter = rate.NewLimiter(rate.Limit(perSecond), perSecond)
+	}
+	return queue
+}
+
+// Enqueue queues an update of the status of the node. If an update is already queued for the node, the updates are
+// coalesced and written together.
+func (q *NodeStatusQueue) Enqueue(node *hwmgmtv1alpha1.Node, update NodeStatusUpdate) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := client.ObjectKeyFromObject(node)
+	if _, exists := q.pending[key]; exists {
+		nodeStatusUpdatesCoalesced.WithLabelValues(q.name).Inc()
+	} else {
+		q.order = append(q.order, key)
+	}
+	q.pending[key] = append(q.pending[key], update)
+	nodeStatusUpdatesPending.WithLabelValues(q.name).Set(float64(len(q.order)))
+}
+
+// Pending returns the number of nodes with queued updates
+func (q *NodeStatusQueue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.order)
+}
+
+// Flush writes the queued updates in the order the nodes were queued, waiting on the rate limit before each write.
+// Concurrent flushes share the queue, each writing the next queued node. The flush returns once the queue is empty, or
+// when the context is done, leaving the remaining updates queued. Nodes that no longer exist are skipped, and a
+// failure to write a node does not hold up the others.
+func (q *NodeStatusQueue) Flush(ctx context.Context, c client.Client) error {
+	var errs []error
+	for {
+		key, updates, ok := q.next()
+		if !ok {
+			break
+		}
+
+		if err := q.getLimiter().Wait(ctx); err != nil {
+			// The context is done, so the node is put back for a later flush
+			q.requeue(key, updates)
+			errs = append(errs, fmt.Errorf("node status updates deferred: %w", err))
+			break
+		}
+
+		if err := q.write(ctx, c, key, updates); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// write applies the updates to the latest copy of the node, writing its status if changed
+func (q *NodeStatusQueue) write(ctx context.Context, c client.Client, key types.NamespacedName, updates []NodeStatusUpdate) error {
+	node := &hwmgmtv1alpha1.Node{}
+	if err := c.Get(ctx, key, node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %s: %w", key.Name, err)
+	}
+
+	changed := false
+	for _, update := range updates {
+		if update(node) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	// The updates are made again to the latest copy of the node on a conflict
+	if err := writeNodeStatus(ctx, c, node, func() {
+		for _, update := range updates {
+			update(node)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to update status for node %s: %w", key.Name, err)
+	}
+	return nil
+}
+
+// next removes the first queued node from the queue, returning its updates
+func (q *NodeStatusQueue) next() (types.NamespacedName, []NodeStatusUpdate, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.order) == 0 {
+		return types.NamespacedName{}, nil, false
+	}
+
+	key := q.order[0]
+	q.order = q.order[1:]
+	updates := q.pending[key]
+	delete(q.pending, key)
+	nodeStatusUpdatesPending.WithLabelValues(q.name).Set(float64(len(q.order)))
+	return key, updates, true
+}
+
+// requeue puts the updates of a node back at the front of the queue, ahead of any queued since
+func (q *NodeStatusQueue) requeue(key types.NamespacedName, updates []NodeStatusUpdate) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.pending[key]; !exists {
+		q.order = append([]types.NamespacedName{key}, q.order...)
+	}
+	q.pending[key] = append(updates, q.pending[key]...)
+	nodeStatusUpdatesPending.WithLabelValues(q.name).Set(float64(len(q.order)))
+}
+
+func (q *NodeStatusQueue) getLimiter() *rate.Limiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.limiter
+}
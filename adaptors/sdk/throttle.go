@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"sync"
+)
+
+// AllocationThrottle limits the number of nodes being actively provisioned against a backend at once. Slots are held
+// per key, typically a NodePool, so that the holdings can be re-established on each reconcile, such as after a
+// restart of the plugin.
+type AllocationThrottle struct {
+	name string
+
+	mu     sync.Mutex
+	limit  int
+	held   map[string]int
+	inUse  int
+	queued map[string]bool
+}
+
+// Allocation throttles are shared by HardwareManager name, as adaptors are reconciled across NodePools
+var allocationThrottles sync.Map
+
+// GetAllocationThrottle returns the allocation throttle for the named backend, updating its limit. A limit of zero
+// disables throttling.
+func GetAllocationThrottle(name string, limit int) *AllocationThrottle {
+	t, _ := allocationThrottles.LoadOrStore(name, &AllocationThrottle{
+		name:   name,
+		held:   make(map[string]int),
+		queued: make(map[string]bool),
+	})
+	throttle := t.(*AllocationThrottle)
+
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+	throttle.limit = limit
+	return throttle
+}
+
+// TryAcquire takes count slots for the key, returning false if this would exceed the limit. A request larger than the
+// limit is granted when no other slots are held, so that it is not starved.
+func (t *AllocationThrottle) TryAcquire(key string, count int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.limit > 0 && t.inUse+count > t.limit && t.inUse > 0 {
+		t.queued[key] = true
+		t.updateMetrics()
+		return false
+	}
+
+	delete(t.queued, key)
+	t.held[key] += count
+	t.inUse += count
+	t.updateMetrics()
+	return true
+}
+
+// Set records the number of slots held by the key, regardless of the limit, to reflect the nodes that are currently
+// being provisioned
+func (t *AllocationThrottle) Set(key string, count int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.set(key, count)
+}
+
+// Release frees all slots held by the key, and removes it from the queue
+func (t *AllocationThrottle) Release(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.queued, key)
+	t.set(key, 0)
+}
+
+// set must be called with the lock held
+func (t *AllocationThrottle) set(key string, count int) {
+	t.inUse += count - t.held[key]
+	if count > 0 {
+		t.held[key] = count
+	} else {
+		delete(t.held, key)
+	}
+	t.updateMetrics()
+}
+
+// InUse returns the number of slots currently held
+func (t *AllocationThrottle) InUse() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inUse
+}
+
+// updateMetrics must be called with the lock held
+func (t *AllocationThrottle) updateMetrics() {
+	backendAllocationsInProgress.WithLabelValues(t.name).Set(float64(t.inUse))
+	backendAllocationsQueued.WithLabelValues(t.name).Set(float64(len(t.queued)))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// recordBackendError records the last error returned by the adaptor for the NodePool, for the stall diagnostics. A
+// nil error leaves the last error in place, as a stalled NodePool may be polled without error.
+func (c *HwMgrAdaptorController) recordBackendError(nodepool *hwmgmtv1alpha1.NodePool, err error) {
+	if err != nil {
+		c.lastErrors.Store(client.ObjectKeyFromObject(nodepool), err.Error())
+	}
+}
+
+// getBackendError returns the last error returned by the adaptor for the NodePool, if any
+func (c *HwMgrAdaptorController) getBackendError(nodepool *hwmgmtv1alpha1.NodePool) string {
+	if lastError, exists := c.lastErrors.Load(client.ObjectKeyFromObject(nodepool)); exists {
+		return lastError.(string)
+	}
+	return ""
+}
+
+// checkNodePoolStall tracks the progress of a NodePool being provisioned, setting the Stalled condition and emitting
+// an event once it has made no progress for the stall timeout of the hardware manager
+func (c *HwMgrAdaptorController) checkNodePoolStall(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if _, enabled := utils.GetStallTimeout(hwmgr); !enabled {
+		return nil
+	}
+
+	// The adaptor may have updated the NodePool, so work from the latest copy
+	current := &hwmgmtv1alpha1.NodePool{}
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(nodepool), current); err != nil {
+		return fmt.Errorf("failed to get NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if !utils.IsNodePoolProcessing(current) {
+		c.lastErrors.Delete(client.ObjectKeyFromObject(current))
+		patch := client.MergeFrom(current.DeepCopy())
+		if utils.ClearNodePoolProgress(current) {
+			if err := c.Client.Patch(ctx, current, patch); err != nil {
+				return fmt.Errorf("failed to clear progress of NodePool %s: %w", current.Name, err)
+			}
+		}
+		if _, err := utils.UpdateNodePoolStalledCondition(ctx, c.Client, current, false, time.Time{}, nil); err != nil {
+			return fmt.Errorf("failed to update stalled status for NodePool %s: %w", current.Name, err)
+		}
+		return nil
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, c.Logger, c.Client, current)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for NodePool %s: %w", current.Name, err)
+	}
+
+	now := time.Now()
+	patch := client.MergeFrom(current.DeepCopy())
+	if utils.RecordNodePoolProgress(current, utils.CountNodePoolProgress(nodelist, now)) {
+		if err := c.Client.Patch(ctx, current, patch); err != nil {
+			return fmt.Errorf("failed to record progress of NodePool %s: %w", current.Name, err)
+		}
+	}
+
+	stalled := utils.IsNodePoolStalled(hwmgr, current, now)
+	var since time.Time
+	var diagnostics []string
+	if stalled {
+		since = utils.GetNodePoolProgress(current).Time
+		diagnostics = utils.GetNodePoolStallDiagnostics(current, nodelist, c.getBackendError(current))
+	}
+
+	newlyStalled, err := utils.UpdateNodePoolStalledCondition(ctx, c.Client, current, stalled, since, diagnostics)
+	if err != nil {
+		return fmt.Errorf("failed to update stalled status for NodePool %s: %w", current.Name, err)
+	}
+
+	if newlyStalled {
+		c.Logger.WarnContext(ctx, "NodePool provisioning has stalled",
+			slog.Time("since", since),
+			slog.Any("diagnostics", diagnostics))
+		if c.Recorder != nil {
+			c.Recorder.Eventf(current, corev1.EventTypeWarning, string(utils.ReasonNoProgress),
+				"NodePool has made no progress since %s: %s", since.UTC().Format(time.RFC3339), strings.Join(diagnostics, "; "))
+		}
+	}
+
+	return nil
+}
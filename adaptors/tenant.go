@@ -0,0 +1,63 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=impersonate
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// authorizeTenant checks that the tenant service account of the NodePool is permitted to make the changes triggered by
+// the NodePool, when tenant impersonation is enabled on the HardwareManager, reporting the outcome in the
+// TenantAuthorized condition. The returned context impersonates the service account for the changes made by the
+// adaptor in the namespace of the NodePool. The context is returned unchanged if impersonation is not enabled.
+func (c *HwMgrAdaptorController) authorizeTenant(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (context.Context, bool, error) {
+
+	name, accessErr := utils.GetNodePoolTenantServiceAccount(hwmgr, nodepool)
+	if accessErr == nil && name == "" {
+		return ctx, true, nil
+	}
+
+	if accessErr == nil {
+		accessErr = utils.CheckTenantAccess(ctx, c.Client, nodepool.Namespace, name)
+		if accessErr != nil && !utils.IsInputError(accessErr) {
+			return ctx, false, accessErr // nolint: wrapcheck
+		}
+	}
+
+	if err := utils.UpdateNodePoolTenantCondition(ctx, c.Client, nodepool, name, accessErr); err != nil {
+		return ctx, false, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if accessErr != nil {
+		c.Logger.InfoContext(ctx, "Tenant service account is not authorized", slog.String("reason", accessErr.Error()))
+		return ctx, false, nil
+	}
+
+	return utils.WithTenant(ctx, nodepool.Namespace, name), true, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConsolidationPhase is the phase of a consolidation operation
+type ConsolidationPhase string
+
+// ConsolidationPhases define the phases of a consolidation operation
+var ConsolidationPhases = struct {
+	Planned   ConsolidationPhase
+	Executing ConsolidationPhase
+	Completed ConsolidationPhase
+	Failed    ConsolidationPhase
+}{
+	Planned:   "Planned",
+	Executing: "Executing",
+	Completed: "Completed",
+	Failed:    "Failed",
+}
+
+// ConsolidationSpec defines the desired state of Consolidation
+type ConsolidationSpec struct {
+	// HwMgrId is the name of the HardwareManager whose allocations are consolidated
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	HwMgrId string `json:"hwMgrId"`
+
+	// ResourcePoolIds are the resource pools in which the allocations are re-packed
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ResourcePoolIds []string `json:"resourcePoolIds"`
+
+	// Approved approves the execution of the plan reported in the status. The plan is computed once, on creation,
+	// and no allocation is migrated until it is approved
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Approved bool `json:"approved,omitempty"`
+}
+
+// ConsolidationMove describes the migration of an allocated node to a different node of the same resource pool
+type ConsolidationMove struct {
+	// NodePool is the name of the NodePool of the node
+	NodePool string `json:"nodePool"`
+
+	// Node is the name of the Node CR that is migrated
+	Node string `json:"node"`
+
+	// ResourcePoolId is the resource pool of the node
+	ResourcePoolId string `json:"resourcePoolId"`
+
+	// SourceNodeId is the backend node currently allocated to the Node CR
+	SourceNodeId string `json:"sourceNodeId"`
+
+	// TargetNodeId is the backend node to which the allocation is migrated
+	TargetNodeId string `json:"targetNodeId"`
+
+	// Completed indicates that the move has been executed
+	// +optional
+	Completed bool `json:"completed,omitempty"`
+}
+
+// ConsolidationStatus defines the observed state of Consolidation
+type ConsolidationStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is the current phase of the consolidation
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Phase ConsolidationPhase `json:"phase,omitempty"`
+
+	// Moves is the consolidation plan, with the migrations executed in order once approved
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Moves []ConsolidationMove `json:"moves,omitempty"`
+
+	// Conditions describe the state of the Consolidation resource.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=consolidations,scope=Namespaced
+// +kubebuilder:printcolumn:name="HwMgr Id",type="string",JSONPath=".spec.hwMgrId"
+// +kubebuilder:printcolumn:name="Approved",type="boolean",JSONPath=".spec.approved"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the Consolidation resource."
+// +kubebuilder:printcolumn:name="Details",type="string",JSONPath=".status.conditions[-1:].message"
+
+// Consolidation is the Schema for the consolidations API, re-packing the allocations of a hardware manager to free
+// up contiguous capacity in its resource pools, with a plan that is executed once approved
+type Consolidation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConsolidationSpec   `json:"spec,omitempty"`
+	Status ConsolidationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ConsolidationList contains a list of Consolidation
+type ConsolidationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Consolidation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Consolidation{}, &ConsolidationList{})
+}
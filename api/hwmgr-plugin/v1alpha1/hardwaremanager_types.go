@@ -27,9 +27,35 @@ type HardwareManagerAdaptorID string
 var SupportedAdaptors = struct {
 	Loopback HardwareManagerAdaptorID
 	Dell     HardwareManagerAdaptorID
+	Rest     HardwareManagerAdaptorID
 }{
 	Loopback: "loopback",
 	Dell:     "dell-hwmgr",
+	Rest:     "rest",
+}
+
+// DeletionPolicy defines the handling of the backend hardware allocation when a NodePool is deleted
+type DeletionPolicy string
+
+// DeletionPolicies define the supported deletion policies
+var DeletionPolicies = struct {
+	Release DeletionPolicy
+	Retain  DeletionPolicy
+}{
+	Release: "Release",
+	Retain:  "Retain",
+}
+
+// PowerBudgetEnforcement defines the handling of allocations that exceed a power budget
+type PowerBudgetEnforcement string
+
+// PowerBudgetEnforcements define the supported power budget enforcements
+var PowerBudgetEnforcements = struct {
+	Enforce PowerBudgetEnforcement
+	Warn    PowerBudgetEnforcement
+}{
+	Enforce: "Enforce",
+	Warn:    "Warn",
 }
 
 // ConditionType is a string representing the condition's type
@@ -37,9 +63,19 @@ type ConditionType string
 
 // ConditionTypes define the different types of conditions that will be set
 var ConditionTypes = struct {
-	Validation ConditionType
+	Validation          ConditionType
+	RemoteHub           ConditionType
+	Applied             ConditionType
+	Consolidated        ConditionType
+	CertificateExpiring ConditionType
+	Instantiated        ConditionType
 }{
-	Validation: "Validation",
+	Validation:          "Validation",
+	RemoteHub:           "RemoteHub",
+	Applied:             "Applied",
+	Consolidated:        "Consolidated",
+	CertificateExpiring: "CertificateExpiring",
+	Instantiated:        "Instantiated",
 }
 
 // ConditionReason is a string representing the condition's reason
@@ -47,13 +83,17 @@ type ConditionReason string
 
 // ConditionReasons define the different reasons that conditions will be set for
 var ConditionReasons = struct {
-	Completed  ConditionReason
-	Failed     ConditionReason
-	InProgress ConditionReason
+	Completed   ConditionReason
+	Failed      ConditionReason
+	InProgress  ConditionReason
+	Expiring    ConditionReason
+	NotExpiring ConditionReason
 }{
-	Completed:  "Completed",
-	Failed:     "Failed",
-	InProgress: "InProgress",
+	Completed:   "Completed",
+	Failed:      "Failed",
+	InProgress:  "InProgress",
+	Expiring:    "Expiring",
+	NotExpiring: "NotExpiring",
 }
 
 // OAuthGrantType is a string representing the OAuth2 grant type
@@ -68,21 +108,156 @@ var OAuthGrantTypes = struct {
 	Password:          "password",
 }
 
+// RestAuthScheme is a string representing the authentication scheme used with a REST backend
+type RestAuthScheme string
+
+// RestAuthSchemes define the supported authentication schemes for the rest adaptor
+var RestAuthSchemes = struct {
+	None   RestAuthScheme
+	Basic  RestAuthScheme
+	Bearer RestAuthScheme
+}{
+	None:   "None",
+	Basic:  "Basic",
+	Bearer: "Bearer",
+}
+
 // LoopbackData defines configuration data for loopback adaptor instance
 type LoopbackData struct {
 	// A test string
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	AddtionalInfo string `json:"additionalInfo,omitempty"`
+
+	// Seed seeds the pseudo-random aspects of the simulation, the allocation delays, node selection and injected
+	// faults, so that a run can be reproduced exactly. A time-based seed is used if unset, with the seed in use logged
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Seed *int64 `json:"seed,omitempty"`
+
+	// MaxAllocationDelay randomizes the simulated delay before each node allocation, up to the given duration. A fixed
+	// delay of 10s is used if unset. The allocate latency takes precedence, if set
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaxAllocationDelay *metav1.Duration `json:"maxAllocationDelay,omitempty"`
+
+	// Latency defines the distributions of the simulated latencies of the backend operations, for performance testing
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Latency *SimulatedLatency `json:"latency,omitempty"`
+
+	// RandomNodeSelection allocates a random free node, rather than the least recently allocated node
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	RandomNodeSelection bool `json:"randomNodeSelection,omitempty"`
+
+	// AllocationFailurePercent is the percentage of node allocations that fail with a simulated transient error,
+	// which is retried on a later reconcile
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AllocationFailurePercent int `json:"allocationFailurePercent,omitempty"`
+
+	// EmulatedBMC publishes emulated Redfish BMC endpoints, served by the plugin, as the BMC addresses of the allocated
+	// nodes. The emulated BMC server must be enabled on the plugin with the --emulated-bmc-bind-address flag
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EmulatedBMC *EmulatedBMCConfig `json:"emulatedBMC,omitempty"`
+}
+
+// LatencyDistribution is the distribution of a simulated latency
+// +kubebuilder:validation:Enum=Fixed;Uniform;Normal;LongTail
+type LatencyDistribution string
+
+// LatencyDistributions defines the supported latency distributions
+var LatencyDistributions = struct {
+	Fixed    LatencyDistribution
+	Uniform  LatencyDistribution
+	Normal   LatencyDistribution
+	LongTail LatencyDistribution
+}{
+	Fixed:    "Fixed",
+	Uniform:  "Uniform",
+	Normal:   "Normal",
+	LongTail: "LongTail",
+}
+
+// LatencyConfig defines the distribution of the simulated latency of a backend operation
+type LatencyConfig struct {
+	// Distribution of the latency. Fixed uses the mean, Uniform is spread evenly between min and max, Normal follows a
+	// normal distribution of the given mean and standard deviation, and LongTail follows a log-normal distribution of
+	// the given mean and standard deviation, skewed towards occasional long latencies. Defaults to Fixed
+	// +optional
+	// +kubebuilder:default=Fixed
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Distribution LatencyDistribution `json:"distribution,omitempty"`
+
+	// Mean latency, for the Fixed, Normal and LongTail distributions
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Mean *metav1.Duration `json:"mean,omitempty"`
+
+	// StdDev is the standard deviation of the latency, for the Normal and LongTail distributions
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	StdDev *metav1.Duration `json:"stdDev,omitempty"`
+
+	// Min is the lower bound of the latency, for all distributions. Defaults to 0
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Min *metav1.Duration `json:"min,omitempty"`
+
+	// Max is the upper bound of the latency, for all distributions, and is required for the Uniform distribution.
+	// Unbounded if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Max *metav1.Duration `json:"max,omitempty"`
+}
+
+// SimulatedLatency defines the simulated latencies of the backend operations of a loopback adaptor instance
+type SimulatedLatency struct {
+	// Allocate is the latency before each node allocation pass
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Allocate *LatencyConfig `json:"allocate,omitempty"`
+
+	// Release is the latency before the nodes of a NodePool are released
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Release *LatencyConfig `json:"release,omitempty"`
+
+	// StatusUpdate is the latency before each update of the status of a node from the backend
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	StatusUpdate *LatencyConfig `json:"statusUpdate,omitempty"`
+
+	// SpecChange is the latency of each step of a NodePool spec change: the validation, the staging, and the apply to
+	// each node. A fixed latency of 5s is used if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SpecChange *LatencyConfig `json:"specChange,omitempty"`
+}
+
+// EmulatedBMCConfig defines the emulated Redfish BMC endpoints of a loopback adaptor instance
+type EmulatedBMCConfig struct {
+	// BaseURL is the URL at which the emulated BMC server of the plugin is reachable by the consumers of the nodes,
+	// such as http://hwmgr-plugin-emulated-bmc.oran-hwmgr-plugin.svc:8083
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BaseURL string `json:"baseURL"`
 }
 
 // DellData defines configuration data for dell-hwmgr adaptor instance
 type DellData struct {
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	// +required
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	AuthSecret string `json:"authSecret"`
 
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
 	// +required
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	ApiUrl string `json:"apiUrl"`
@@ -103,43 +278,1176 @@ type DellData struct {
 	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
 }
 
-// HardwareManagerSpec defines the desired state of HardwareManager
-type HardwareManagerSpec struct {
-	// Important: Run "make" to regenerate code after modifying this file
+// RestRequestTemplate defines a request to a backend endpoint. The path and body are Go templates, with the fields
+// .CloudID, .NodePool, .Group, .ResourcePoolId, .HwProfile, .NodeId, and .Storage, the storage layout of the hardware
+// profile, and a json function to quote a value
+type RestRequestTemplate struct {
+	// Method is the HTTP method of the request. Defaults to GET
+	// +optional
+	// +kubebuilder:validation:Enum=GET;POST;PUT;PATCH;DELETE
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Method string `json:"method,omitempty"`
 
-	// The adaptor ID
+	// Path is the template for the request path, relative to the API URL
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=loopback;dell-hwmgr
+	// +required
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
-	AdaptorID HardwareManagerAdaptorID `json:"adaptorId"`
+	Path string `json:"path"`
 
-	// Config data for an instance of the loopback adaptor
+	// Body is the template for the JSON request body
+	// +optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
-	LoopbackData *LoopbackData `json:"loopbackData,omitempty"`
+	Body string `json:"body,omitempty"`
+}
 
-	// Config data for an instance of the dell-hwmgr adaptor
+// RestEndpoints defines the backend requests used by the rest adaptor
+type RestEndpoints struct {
+	// AllocateNode allocates a node from the .ResourcePoolId resource pool for the nodegroup
+	// +kubebuilder:validation:Required
+	// +required
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
-	DellData *DellData `json:"dellData,omitempty"`
+	AllocateNode RestRequestTemplate `json:"allocateNode"`
+
+	// GetNode gets the details of the allocated node identified by .NodeId
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	GetNode RestRequestTemplate `json:"getNode"`
+
+	// ReleaseNode releases the allocated node identified by .NodeId
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ReleaseNode RestRequestTemplate `json:"releaseNode"`
+
+	// UpdateNode applies the .HwProfile hardware profile to the node identified by .NodeId. If not specified,
+	// hardware profile changes are not supported
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	UpdateNode *RestRequestTemplate `json:"updateNode,omitempty"`
+
+	// ListResourcePools lists the resource pools of the backend, used to validate the connection
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ListResourcePools *RestRequestTemplate `json:"listResourcePools,omitempty"`
 }
 
-type ResourcePoolList []string
-type PerSiteResourcePoolList map[string]ResourcePoolList
+// RestFieldMappings defines the JSONPath expressions used to extract data from the backend responses
+type RestFieldMappings struct {
+	// NodeId is the ID of the allocated node, in the allocateNode response
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeId string `json:"nodeId"`
 
-// HardwareManagerStatus defines the observed state of HardwareManager
-type HardwareManagerStatus struct {
-	// +operator-sdk:csv:customresourcedefinitions:type=status
-	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready indicates, in the getNode response, whether the node is ready. The node is ready when the value is true,
+	// or matches ReadyValue if specified. If not specified, nodes are ready once allocated
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Ready string `json:"ready,omitempty"`
 
-	// Conditions describe the state of the UpdateService resource.
-	// +patchMergeKey=type
-	// +patchStrategy=merge
-	// +kubebuilder:validation:Optional
-	// +operator-sdk:csv:customresourcedefinitions:type=status
-	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ReadyValue is the value of the Ready field for a ready node. Defaults to true
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ReadyValue string `json:"readyValue,omitempty"`
 
-	// ResourcePools provides a per-site list of resource pools
-	// +operator-sdk:csv:customresourcedefinitions:type=status
-	ResourcePools PerSiteResourcePoolList `json:"resourcePools,omitempty"`
+	// BmcAddress is the BMC address of the node, in the getNode response
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BmcAddress string `json:"bmcAddress"`
+
+	// BmcAddressIPv6 is the IPv6 BMC address of a dual-stack node, in the getNode response, with the address
+	// published selected by the bmcAddressFamily of the hardware manager
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BmcAddressIPv6 string `json:"bmcAddressIPv6,omitempty"`
+
+	// BmcUsername is the BMC username of the node, in the getNode response
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BmcUsername string `json:"bmcUsername"`
+
+	// BmcPassword is the BMC password of the node, in the getNode response
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BmcPassword string `json:"bmcPassword"`
+
+	// Interfaces is the list of interfaces of the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Interfaces string `json:"interfaces,omitempty"`
+
+	// InterfaceName is the name of an interface, relative to an entry of the Interfaces list
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	InterfaceName string `json:"interfaceName,omitempty"`
+
+	// InterfaceLabel is the label of an interface, relative to an entry of the Interfaces list
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	InterfaceLabel string `json:"interfaceLabel,omitempty"`
+
+	// InterfaceMacAddress is the MAC address of an interface, relative to an entry of the Interfaces list
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	InterfaceMacAddress string `json:"interfaceMacAddress,omitempty"`
+
+	// InterfaceRole is the role of an interface, relative to an entry of the Interfaces list. A role reported by the
+	// backend takes precedence over the interface roles of the hardware profile
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	InterfaceRole string `json:"interfaceRole,omitempty"`
+
+	// ResourcePools is the list of resource pool IDs, in the listResourcePools response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ResourcePools string `json:"resourcePools,omitempty"`
+
+	// SerialNumber is the serial number of the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// AssetTag is the asset tag of the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AssetTag string `json:"assetTag,omitempty"`
+
+	// Model is the model name of the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Model string `json:"model,omitempty"`
+
+	// Vendor is the vendor name of the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Vendor string `json:"vendor,omitempty"`
+
+	// SecretData is an object of additional secret data of the node, such as console credentials, in the getNode
+	// response. Its entries are available to the templates of the nodeSecrets as .Backend
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SecretData string `json:"secretData,omitempty"`
+
+	// BootDevices is the list of devices the node supports booting from, such as Pxe or Cd, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BootDevices string `json:"bootDevices,omitempty"`
+
+	// VirtualMediaURLs is the list of BMC URLs through which a boot image can be attached to the node as virtual
+	// media, in the getNode response. Virtual media boot is reported as supported if any URL is returned
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	VirtualMediaURLs string `json:"virtualMediaURLs,omitempty"`
+
+	// SecureBoot is the secure boot state of the node, in the getNode response: Enabled, Disabled or Unsupported
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SecureBoot string `json:"secureBoot,omitempty"`
+
+	// TPMVersion is the version of the enabled TPM of the node, such as 2.0, or None if the node has no enabled TPM,
+	// in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	TPMVersion string `json:"tpmVersion,omitempty"`
+
+	// Hostname is the hostname intended for the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Hostname string `json:"hostname,omitempty"`
+
+	// ProvisioningMacAddress is the MAC address of the provisioning interface of the node, in the getNode response. If
+	// not mapped, the MAC address of the interface with the provisioning role is reported
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ProvisioningMacAddress string `json:"provisioningMacAddress,omitempty"`
+
+	// IPConfig is how the provisioning interface of the node is addressed, dhcp or static, in the getNode response. If
+	// not mapped, static addressing is reported if an IP address is returned
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	IPConfig string `json:"ipConfig,omitempty"`
+
+	// IPAddress is the static IP address of the provisioning interface of the node, with its prefix length, in the
+	// getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// Gateway is the default gateway for the static addressing of the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Gateway string `json:"gateway,omitempty"`
+
+	// DNSServers is the list of DNS servers for the static addressing of the node, in the getNode response
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DNSServers string `json:"dnsServers,omitempty"`
+}
+
+// RestData defines configuration data for a rest adaptor instance, which integrates a backend from a declarative
+// description of its API
+type RestData struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ApiUrl string `json:"apiUrl"`
+
+	// AuthScheme is the authentication scheme for the backend. Defaults to None
+	// +optional
+	// +kubebuilder:validation:Enum=None;Basic;Bearer
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AuthScheme RestAuthScheme `json:"authScheme,omitempty"`
+
+	// AuthSecret references a secret with the "username" and "password" keys for Basic authentication, or the
+	// "token" key for Bearer authentication
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AuthSecret string `json:"authSecret,omitempty"`
+
+	// CaBundleName references a config map that contains a set of custom CA certificates to be used when communicating
+	// with a hardware manager that has its TLS certificate signed by a non-public CA certificate.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	CaBundleName *string `json:"caBundleName,omitempty"`
+
+	// insecureSkipTLSVerify indicates that the plugin should not confirm the validity of the TLS certificate of the hardware manager.
+	// This is insecure and is not recommended.
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// Endpoints defines the backend requests
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Endpoints RestEndpoints `json:"endpoints"`
+
+	// Mappings defines the extraction of data from the backend responses
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Mappings RestFieldMappings `json:"mappings"`
+}
+
+// RemoteHubConfig defines the connection data for a remote hub cluster serving NodePool CRs
+type RemoteHubConfig struct {
+	// KubeconfigSecret references a secret, in the plugin namespace, with a "kubeconfig" key providing access to the remote hub
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	KubeconfigSecret string `json:"kubeconfigSecret"`
+
+	// Namespace is the namespace on the remote hub that is monitored for NodePool CRs
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Namespace string `json:"namespace"`
+
+	// SyncInterval is the interval between synchronization passes with the remote hub. Defaults to 30s
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+}
+
+// InventoryExportConfig defines the periodic export of the hardware manager inventory to a ConfigMap
+type InventoryExportConfig struct {
+	// Interval is the interval between inventory exports. Defaults to 10m
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// NodeNamingConfig defines the naming policy for Node CRs, and their bmc-secrets, created by the plugin
+type NodeNamingConfig struct {
+	// Template for Node CR names, supporting the placeholders {cloudID}, {nodepool}, {group}, {index}, {backendName},
+	// and {uuid}. The template must include at least one of {index}, {backendName}, or {uuid}. Defaults to {uuid}
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Template string `json:"template,omitempty"`
+}
+
+// NodeResyncConfig defines the configuration for the periodic resync of Node hardware details from the backend
+type NodeResyncConfig struct {
+	// Interval between resyncs of the interfaces and BMC address of allocated nodes. Defaults to 1h
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// InventoryCacheConfig defines the caching of backend inventory queries
+type InventoryCacheConfig struct {
+	// TTL is the time a cached query result is used before the backend is queried again. Defaults to 30s
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// StallDetectionConfig defines the detection of NodePools that make no progress while being provisioned
+type StallDetectionConfig struct {
+	// Timeout is the time without progress, such as a node being allocated or provisioned, after which a NodePool
+	// being provisioned is reported as stalled. Defaults to 30m
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// EnergyPolicy defines the power settings applied by the adaptor to the allocated nodes, for energy saving
+type EnergyPolicy struct {
+	// PowerCapWatts is the limit on the power consumption of each allocated node, in watts. The backend may adjust
+	// the cap to the range supported by the node, with the achieved cap reported on the Node
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	PowerCapWatts int `json:"powerCapWatts"`
+}
+
+// PowerBudget defines the limit on the estimated power draw of the nodes allocated from a resource pool, for sites
+// with constrained power
+type PowerBudget struct {
+	// ResourcePoolId is the resource pool, or site, to which the budget applies
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ResourcePoolId string `json:"resourcePoolId"`
+
+	// BudgetWatts is the limit on the estimated power draw of the nodes allocated from the resource pool, in watts
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BudgetWatts int `json:"budgetWatts"`
+
+	// DefaultNodePowerWatts is the estimated power draw of a node whose hardware profile does not define an
+	// estimatedPowerWatts, in watts. Such nodes are not counted against the budget if unset
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DefaultNodePowerWatts int `json:"defaultNodePowerWatts,omitempty"`
+
+	// Enforcement is the handling of NodePools whose allocation would exceed the budget. With Enforce, the NodePool is
+	// held until the budget allows. With Warn, the NodePool is processed and marked with the PowerBudgetExceeded
+	// condition. Defaults to Enforce
+	// +optional
+	// +kubebuilder:validation:Enum=Enforce;Warn
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Enforcement PowerBudgetEnforcement `json:"enforcement,omitempty"`
+}
+
+// ProvisioningWindow defines a recurring window of time during which hardware changes are permitted
+type ProvisioningWindow struct {
+	// Schedule is a cron expression of five fields (minute, hour, day of month, month, day of week) giving the start
+	// times of the window
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Schedule string `json:"schedule"`
+
+	// Duration is the length of the window from each start time
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone in which the schedule is evaluated. Defaults to UTC
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// NodeProvisioningConfig defines the handling of nodes that are not provisioned by the backend in time
+type NodeProvisioningConfig struct {
+	// Timeout for the backend to report an allocated node as ready. Defaults to 1h
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// MaxRetries is the number of timed-out nodes of a NodePool that are released and replaced with a different node.
+	// Once exhausted, a further timeout fails the NodePool. Defaults to 0, with no retries
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+// AllocationRetryConfig defines the retry budget for transient failures to allocate the nodes of a NodePool
+type AllocationRetryConfig struct {
+	// MaxRetries is the number of transient allocation failures of a NodePool that are retried within the window.
+	// Once exhausted, a further failure fails the NodePool. Defaults to 5
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	// Window is the period over which allocation failures count against the budget. Defaults to 1h
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Window *metav1.Duration `json:"window,omitempty"`
+}
+
+// ProxyConfig defines the proxy used to communicate with the hardware manager backend, in place of the proxy
+// environment of the plugin
+type ProxyConfig struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames, domain suffixes, and CIDRs for which the proxy is not used
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NoProxy string `json:"noProxy,omitempty"`
+
+	// CaBundleName references a config map that contains a set of custom CA certificates to be trusted when
+	// communicating through the proxy, such as for a proxy that re-signs TLS connections
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	CaBundleName *string `json:"caBundleName,omitempty"`
+}
+
+// RAIDLevel is the RAID level of a virtual disk
+// +kubebuilder:validation:Enum=RAID0;RAID1;RAID5;RAID6;RAID10
+type RAIDLevel string
+
+const (
+	RAID0  RAIDLevel = "RAID0"
+	RAID1  RAIDLevel = "RAID1"
+	RAID5  RAIDLevel = "RAID5"
+	RAID6  RAIDLevel = "RAID6"
+	RAID10 RAIDLevel = "RAID10"
+)
+
+// VirtualDisk defines a virtual disk to be created from the physical disks of a node
+type VirtualDisk struct {
+	// Name identifies the virtual disk within the storage layout
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// RAIDLevel is the RAID level of the virtual disk
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	RAIDLevel RAIDLevel `json:"raidLevel"`
+
+	// SizeGiB is the size of the virtual disk, in GiB. Zero, the default, uses the remaining capacity, which is
+	// allowed for only one virtual disk of the layout
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SizeGiB int `json:"sizeGiB,omitempty"`
+
+	// PhysicalDisks is the number of physical disks used by the virtual disk. Defaults to the minimum required for
+	// the RAID level
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	PhysicalDisks int `json:"physicalDisks,omitempty"`
+}
+
+// BootDiskHints identify the disk on which the operating system is installed
+type BootDiskHints struct {
+	// VirtualDisk is the name of the virtual disk of the layout to boot from
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	VirtualDisk string `json:"virtualDisk,omitempty"`
+
+	// DeviceName is the device name of the boot disk, such as /dev/sda
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeviceName string `json:"deviceName,omitempty"`
+
+	// MinSizeGiB is the minimum size of the boot disk, in GiB
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MinSizeGiB int `json:"minSizeGiB,omitempty"`
+}
+
+// StorageLayout defines the storage configuration applied to a node on allocation
+type StorageLayout struct {
+	// VirtualDisks are the virtual disks to be created, in order
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	VirtualDisks []VirtualDisk `json:"virtualDisks,omitempty"`
+
+	// BootDisk provides hints to identify the boot disk
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BootDisk *BootDiskHints `json:"bootDisk,omitempty"`
+}
+
+// HardwareProfile defines settings applied by the plugin for a hardware profile, in addition to those applied by the
+// backend for the profile name
+type HardwareProfile struct {
+	// Name is the hardware profile name, as referenced by the hwProfile of a nodegroup
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// Storage is the storage layout of the nodes allocated with the profile, for adaptors that configure storage
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Storage *StorageLayout `json:"storage,omitempty"`
+
+	// InterfaceRoles tags the interfaces of the nodes allocated with the profile with their roles, by interface label.
+	// A role reported by the backend for an interface takes precedence.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	InterfaceRoles []InterfaceRoleTag `json:"interfaceRoles,omitempty"`
+
+	// MinInterfaces is the minimum number of interfaces required of the nodes allocated with the profile, for adaptors
+	// that validate free nodes against the profile before allocation
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MinInterfaces int `json:"minInterfaces,omitempty"`
+
+	// Security defines the platform security settings required of the nodes allocated with the profile. A node that
+	// does not satisfy the requirements, once configured by adaptors that support it, is not marked as provisioned
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Security *SecurityRequirements `json:"security,omitempty"`
+
+	// EstimatedPowerWatts is the estimated power draw of the nodes allocated with the profile, in watts, counted
+	// against the power budget of their resource pool. The power cap of the nodes, if lower, is used instead
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EstimatedPowerWatts int `json:"estimatedPowerWatts,omitempty"`
+
+	// BMCAddressFormat converts the BMC addresses reported by the backend for the nodes allocated with the profile to the
+	// format expected by the consumers of the nodes, such as the BMC address scheme of a BareMetalHost. The normalized
+	// address reported by the backend is published if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BMCAddressFormat *BMCAddressFormat `json:"bmcAddressFormat,omitempty"`
+}
+
+// BMCAddressScheme is the scheme of a published BMC address, following the BMC address schemes of a BareMetalHost
+// +kubebuilder:validation:Enum=ipmi;redfish;redfish+http;redfish+https;redfish-virtualmedia;redfish-virtualmedia+http;redfish-virtualmedia+https;idrac-redfish;idrac-redfish+http;idrac-redfish+https;idrac-virtualmedia;idrac-virtualmedia+http;idrac-virtualmedia+https
+type BMCAddressScheme string
+
+// BMCAddressFormat defines the conversion of the BMC addresses reported by the backend, which may be Redfish URLs,
+// vendor-specific URLs or bare addresses, to a consistent format
+type BMCAddressFormat struct {
+	// Scheme is the scheme of the published BMC address, replacing the scheme reported by the backend, if any. The
+	// host and port reported by the backend are retained
+	// +kubebuilder:validation:Required
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Scheme BMCAddressScheme `json:"scheme"`
+
+	// SystemPath is the Redfish system path published with a Redfish scheme when the backend reports an address without
+	// one, such as a bare IP address. Defaults to /redfish/v1/Systems/System.Embedded.1 for the idrac schemes, and
+	// /redfish/v1/Systems/1 otherwise
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SystemPath string `json:"systemPath,omitempty"`
+}
+
+// TPMVersion is the version of a Trusted Platform Module
+// +kubebuilder:validation:Enum="1.2";"2.0"
+type TPMVersion string
+
+const (
+	TPMVersion12 TPMVersion = "1.2"
+	TPMVersion20 TPMVersion = "2.0"
+)
+
+// TPMRequirement requires the nodes to have an enabled TPM
+type TPMRequirement struct {
+	// MinVersion is the minimum version of the TPM. Any version is accepted if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MinVersion TPMVersion `json:"minVersion,omitempty"`
+}
+
+// SecurityRequirements defines the platform security settings required of the nodes allocated with a hardware profile
+type SecurityRequirements struct {
+	// SecureBoot requires UEFI secure boot to be enabled. Adaptors that support it enable secure boot on allocation
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SecureBoot bool `json:"secureBoot,omitempty"`
+
+	// TPM requires an enabled TPM
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	TPM *TPMRequirement `json:"tpm,omitempty"`
+}
+
+// InterfaceRoleTag assigns a role to a node interface, identified by its label
+type InterfaceRoleTag struct {
+	// Label is the label of the interface, as reported in the Node status
+	// +kubebuilder:validation:Required
+	// +required
+	Label string `json:"label"`
+
+	// Role is the role of the interface
+	// +kubebuilder:validation:Enum=bmc;provisioning;data;storage
+	// +required
+	Role string `json:"role"`
+}
+
+// ReadinessCheck is a sub-condition of node readiness, reported as a condition of the same name on the Node
+// +kubebuilder:validation:Enum=BMCReachable;FirmwareCompliant;BIOSApplied;InterfacesDiscovered
+type ReadinessCheck string
+
+const (
+	ReadinessCheckBMCReachable         ReadinessCheck = "BMCReachable"
+	ReadinessCheckFirmwareCompliant    ReadinessCheck = "FirmwareCompliant"
+	ReadinessCheckBIOSApplied          ReadinessCheck = "BIOSApplied"
+	ReadinessCheckInterfacesDiscovered ReadinessCheck = "InterfacesDiscovered"
+)
+
+// BMCProbeMode selects how the BMC of a node is probed
+// +kubebuilder:validation:Enum=TCP;Redfish
+type BMCProbeMode string
+
+const (
+	// BMCProbeModeTCP checks that a TCP connection can be established to the BMC address
+	BMCProbeModeTCP BMCProbeMode = "TCP"
+	// BMCProbeModeRedfish checks that a Redfish session can be created with the credentials of the bmc-secret
+	BMCProbeModeRedfish BMCProbeMode = "Redfish"
+)
+
+// BMCAddressFamily selects the IP address family of the BMC address published for each node
+type BMCAddressFamily string
+
+// BMCAddressFamilies define the supported BMC address families
+var BMCAddressFamilies = struct {
+	IPv4 BMCAddressFamily
+	IPv6 BMCAddressFamily
+	Dual BMCAddressFamily
+}{
+	IPv4: "IPv4",
+	IPv6: "IPv6",
+	Dual: "Dual",
+}
+
+// BMCProbeConfig defines the probe of the BMC of each node by the plugin before the node is marked as provisioned
+type BMCProbeConfig struct {
+	// Mode selects how the BMC is probed. Defaults to TCP
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Mode BMCProbeMode `json:"mode,omitempty"`
+
+	// Timeout is the time allowed for each probe. Defaults to 5s
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// BMCEventConfig defines the subscription of the plugin to the Redfish events of the BMC of each node
+type BMCEventConfig struct {
+	// ListenerURL is the URL at which the BMC event listener of the plugin is reachable by the BMCs, such as
+	// https://hwmgr-plugin-bmc-events.example.com. The listener must be enabled on the plugin with the
+	// --bmc-events-bind-address flag
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ListenerURL string `json:"listenerURL"`
+}
+
+// AlarmConfig defines the propagation of the hardware alarms of the nodes to the O2IMS alarm subsystem
+type AlarmConfig struct {
+	// NotificationURL is the URL to which the alarm event records of the nodes are posted, such as the hardware alarm
+	// endpoint of the O2IMS alarm server
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NotificationURL string `json:"notificationURL"`
+
+	// CaBundleName references a config map that contains a set of custom CA certificates to be used when posting to
+	// a notification URL with a TLS certificate signed by a non-public CA certificate.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	CaBundleName *string `json:"caBundleName,omitempty"`
+}
+
+// NodeSecretTemplate defines an additional secret created for each allocated node. The data values are Go templates,
+// rendered with the node details and the secret data reported by the backend for the node:
+//
+//	{{ .NodeName }}, {{ .NodeId }}, {{ .GroupName }}, {{ .HwProfile }}, {{ .Backend.<key> }}
+type NodeSecretTemplate struct {
+	// Name is the suffix of the secret name, which is <nodename>-<name>
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Name string `json:"name"`
+
+	// Type is the type of the secret. Defaults to Opaque
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Type string `json:"type,omitempty"`
+
+	// HwProfiles restricts the secret to the nodes allocated with one of the listed hardware profiles. The secret is
+	// created for all nodes if unset
+	// +optional
+	// +listType=set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	HwProfiles []string `json:"hwProfiles,omitempty"`
+
+	// Data are the templates of the secret data values, by key
+	// +kubebuilder:validation:MinProperties=1
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Data map[string]string `json:"data"`
+}
+
+// HardwareManagerSpec defines the desired state of HardwareManager. The config data of the selected adaptor is
+// validated at admission, and config data for any other adaptor is rejected.
+// +kubebuilder:validation:XValidation:rule="self.adaptorId != 'dell-hwmgr' || has(self.dellData)",message="dellData is required for the dell-hwmgr adaptor"
+// +kubebuilder:validation:XValidation:rule="self.adaptorId != 'rest' || has(self.restData)",message="restData is required for the rest adaptor"
+// +kubebuilder:validation:XValidation:rule="!has(self.loopbackData) || self.adaptorId == 'loopback'",message="loopbackData is only valid for the loopback adaptor"
+// +kubebuilder:validation:XValidation:rule="!has(self.dellData) || self.adaptorId == 'dell-hwmgr'",message="dellData is only valid for the dell-hwmgr adaptor"
+// +kubebuilder:validation:XValidation:rule="!has(self.restData) || self.adaptorId == 'rest'",message="restData is only valid for the rest adaptor"
+// +kubebuilder:validation:XValidation:rule="!has(self.alarms) || has(self.bmcEvents)",message="alarms requires bmcEvents"
+type HardwareManagerSpec struct {
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// The adaptor ID
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=loopback;dell-hwmgr;rest
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AdaptorID HardwareManagerAdaptorID `json:"adaptorId"`
+
+	// Config data for an instance of the loopback adaptor
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	LoopbackData *LoopbackData `json:"loopbackData,omitempty"`
+
+	// Config data for an instance of the dell-hwmgr adaptor
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DellData *DellData `json:"dellData,omitempty"`
+
+	// Config data for an instance of the rest adaptor
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	RestData *RestData `json:"restData,omitempty"`
+
+	// RemoteHub configures the plugin to serve NodePool CRs from a remote hub cluster, rather than the local cluster
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	RemoteHub *RemoteHubConfig `json:"remoteHub,omitempty"`
+
+	// InventoryExport enables the export of the resource pools and nodes of the hardware manager, in the O2IMS
+	// Infrastructure Inventory format, to the <name>-inventory ConfigMap
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	InventoryExport *InventoryExportConfig `json:"inventoryExport,omitempty"`
+
+	// NodeNaming configures the naming policy for Node CRs created for this hardware manager
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeNaming *NodeNamingConfig `json:"nodeNaming,omitempty"`
+
+	// NodeResync enables the periodic resync of Node interfaces and BMC address from the backend, to pick up
+	// changes such as after hardware service
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeResync *NodeResyncConfig `json:"nodeResync,omitempty"`
+
+	// InventoryCache enables the caching of backend inventory queries, such as for free nodes and node details, to
+	// reduce the query load against slow backends. The cache is invalidated when nodes are allocated or released.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	InventoryCache *InventoryCacheConfig `json:"inventoryCache,omitempty"`
+
+	// DeletionPolicy is the default handling of the backend hardware allocation when a NodePool is deleted. With
+	// Release, the hardware is released back to the backend. With Retain, the Node CRs are removed but the backend
+	// allocation is retained, for debugging and forensics. Defaults to Release
+	// +optional
+	// +kubebuilder:validation:Enum=Release;Retain
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// MaxConcurrentAllocations limits the number of nodes being actively provisioned against the backend at once,
+	// queuing further allocations until the in-progress nodes are ready. Zero, the default, is unlimited
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaxConcurrentAllocations int `json:"maxConcurrentAllocations,omitempty"`
+
+	// MaxNodeReleasesPerMinute limits the rate of node releases sent to the backend, across NodePools, so that the bulk
+	// deletion of NodePools, such as in the teardown of a cloud, does not overload the backend. Releases beyond the
+	// limit are deferred until the rate allows. Zero, the default, is unlimited
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaxNodeReleasesPerMinute int `json:"maxNodeReleasesPerMinute,omitempty"`
+
+	// MaxNodeStatusUpdatesPerSecond limits the rate of Node status writes batched by the plugin, such as the updates of
+	// a periodic node resync, so that the resync of a large fleet does not spike the write load on the API server.
+	// Updates beyond the limit are queued, with the updates queued for the same node coalesced. Defaults to 10
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaxNodeStatusUpdatesPerSecond int `json:"maxNodeStatusUpdatesPerSecond,omitempty"`
+
+	// TenantImpersonation performs the changes triggered by a NodePool, such as the creation of its Node CRs and
+	// bmc-secrets, as the tenant service account named by the hwmgr-plugin.oran.openshift.io/tenantServiceAccount
+	// annotation of the NodePool, so that audit logs attribute the changes to the requesting team. The service account
+	// must be permitted to make the changes in the namespace of the NodePool. Defaults to false
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	TenantImpersonation bool `json:"tenantImpersonation,omitempty"`
+
+	// NodeProvisioning enables a timeout for allocated nodes to be provisioned by the backend, optionally replacing
+	// nodes that time out
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeProvisioning *NodeProvisioningConfig `json:"nodeProvisioning,omitempty"`
+
+	// AllocationRetry defines the retry budget for transient failures to allocate the nodes of each NodePool, which
+	// are retried with backoff until the budget is exhausted. Defaults to 5 retries within 1h
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AllocationRetry *AllocationRetryConfig `json:"allocationRetry,omitempty"`
+
+	// StallDetection enables the reporting of NodePools that make no progress while being provisioned, through the
+	// Stalled condition and an event, to surface silent hangs in the backend
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	StallDetection *StallDetectionConfig `json:"stallDetection,omitempty"`
+
+	// NodePoolSelector restricts the hardware manager to the NodePools whose labels match the selector, allowing
+	// multiple hardware managers to split the NodePools between tenants. NodePools that are not selected are not
+	// processed. All NodePools are selected if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodePoolSelector *metav1.LabelSelector `json:"nodePoolSelector,omitempty"`
+
+	// WatchNamespaces restricts the hardware manager to the NodePools in the listed namespaces, with the Node CRs and
+	// bmc-secrets of each NodePool created in its namespace. The plugin watches only its own namespace and those listed
+	// by the HardwareManagers at startup, so a restart is required for a change to take effect. Defaults to the
+	// namespace of the HardwareManager
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:MaxLength=63
+	// +kubebuilder:validation:items:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// HwProfiles defines the settings applied by the plugin for each hardware profile, such as the storage layout
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	HwProfiles []HardwareProfile `json:"hwProfiles,omitempty"`
+
+	// ReadinessChecks are the sub-conditions that a node must satisfy before it is marked as provisioned, each
+	// reported as a separate condition on the Node. No checks are required if unset
+	// +optional
+	// +listType=set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ReadinessChecks []ReadinessCheck `json:"readinessChecks,omitempty"`
+
+	// BMCProbe enables the probe of the BMC address of each node from the plugin before it is marked as provisioned,
+	// failing the node if the BMC address or credentials reported by the backend are wrong. Disabled if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BMCProbe *BMCProbeConfig `json:"bmcProbe,omitempty"`
+
+	// BMCEvents enables the subscription to the Redfish events of the BMC of each provisioned node, reflecting
+	// thermal, power and health events in the HardwareDegraded condition and the events of the Node. Disabled if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BMCEvents *BMCEventConfig `json:"bmcEvents,omitempty"`
+
+	// Alarms enables the propagation of the hardware alarms reported by the BMC events of each node, such as fan or
+	// power supply failures, to the O2IMS alarm subsystem as alarm event records linked to the Node. Requires bmcEvents.
+	// Disabled if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Alarms *AlarmConfig `json:"alarms,omitempty"`
+
+	// BMCAddressFamily selects which BMC address is published in the Node status, for backends reporting IPv4, IPv6,
+	// or both. With IPv4 or IPv6, the address of that family is published, failing the node if the backend reports
+	// only the other family. With Dual, the first address reported by the backend is published. Addresses given as a
+	// hostname are accepted with any family. Defaults to Dual
+	// +optional
+	// +kubebuilder:validation:Enum=IPv4;IPv6;Dual
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BMCAddressFamily BMCAddressFamily `json:"bmcAddressFamily,omitempty"`
+
+	// NodeSecrets are the additional secrets created for each allocated node alongside its bmc-secret, such as serial
+	// console or secondary BMC user credentials. Each secret is owned by its Node, and is deleted with it
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeSecrets []NodeSecretTemplate `json:"nodeSecrets,omitempty"`
+
+	// EnergyPolicy defines the power cap applied to the nodes allocated by the hardware manager, for energy saving.
+	// It can be overridden for a NodePool through the energyPolicy extension. Nodes are not capped if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	EnergyPolicy *EnergyPolicy `json:"energyPolicy,omitempty"`
+
+	// Proxy defines the proxy used by the adaptor to communicate with the backend, allowing hardware managers in the
+	// same cluster to use different proxy paths. The proxy environment of the plugin is used if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+	// MaintenancePools are the resource pools under planned hardware maintenance. The free nodes of these pools are
+	// excluded from allocation, and NodePools that draw nodes or spares from them are marked with the
+	// MaintenancePending condition. NodePools that are not yet provisioned are held until the maintenance ends
+	// +optional
+	// +listType=set
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	MaintenancePools []string `json:"maintenancePools,omitempty"`
+
+	// BlockedNodes are known-bad nodes that are never selected for allocation, either in any resource pool or in a
+	// given resource pool. Blocked nodes remain visible in the inventory export, with a LOCKED admin state
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BlockedNodes []BlockedNode `json:"blockedNodes,omitempty"`
+
+	// ProvisioningWindows are the windows of time during which hardware changes are permitted. Outside of these
+	// windows, allocations and releases are deferred and the affected NodePools are marked with the WaitingForWindow
+	// condition. Hardware changes are permitted at any time if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ProvisioningWindows []ProvisioningWindow `json:"provisioningWindows,omitempty"`
+
+	// PowerBudgets limit the estimated power draw of the nodes allocated from each resource pool, estimated from the
+	// hardware profiles of the nodes. NodePools whose allocation would exceed a budget are held or warned, according to
+	// the enforcement of the budget. Allocations are not limited by power if unset
+	// +optional
+	// +listType=map
+	// +listMapKey=resourcePoolId
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	PowerBudgets []PowerBudget `json:"powerBudgets,omitempty"`
+}
+
+type ResourcePoolList []string
+type PerSiteResourcePoolList map[string]ResourcePoolList
+
+// BlockedNode identifies a node that is blocked from allocation
+type BlockedNode struct {
+	// NodeId is the backend identifier of the node
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodeId string `json:"nodeId"`
+
+	// ResourcePoolId limits the block to the given resource pool. The node is blocked in all resource pools if unset
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	ResourcePoolId string `json:"resourcePoolId,omitempty"`
+
+	// Reason describes why the node is blocked, such as a hardware fault ticket
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Reason string `json:"reason,omitempty"`
+}
+
+// ResourcePoolCapacity describes the node capacity of a resource pool
+type ResourcePoolCapacity struct {
+	// ResourcePoolId is the identifier of the resource pool
+	ResourcePoolId string `json:"resourcePoolId"`
+
+	// TotalNodes is the number of nodes in the resource pool
+	TotalNodes int `json:"totalNodes"`
+
+	// FreeNodes is the number of nodes available for allocation
+	FreeNodes int `json:"freeNodes"`
+
+	// ReservedNodes is the number of nodes held in reserve, such as spares, and not available for allocation
+	ReservedNodes int `json:"reservedNodes"`
+
+	// BlockedNodes is the number of unallocated nodes that are blocked from allocation
+	// +optional
+	BlockedNodes int `json:"blockedNodes,omitempty"`
+}
+
+// CapacityStatus describes the aggregate node capacity of a hardware manager
+type CapacityStatus struct {
+	// TotalNodes is the number of nodes across all resource pools
+	TotalNodes int `json:"totalNodes"`
+
+	// FreeNodes is the number of nodes available for allocation across all resource pools
+	FreeNodes int `json:"freeNodes"`
+
+	// ReservedNodes is the number of nodes held in reserve across all resource pools
+	ReservedNodes int `json:"reservedNodes"`
+
+	// BlockedNodes is the number of unallocated nodes that are blocked from allocation across all resource pools
+	// +optional
+	BlockedNodes int `json:"blockedNodes,omitempty"`
+
+	// ResourcePools provides the capacity of each resource pool
+	// +optional
+	ResourcePools []ResourcePoolCapacity `json:"resourcePools,omitempty"`
+
+	// LastUpdated is the time the capacity was last refreshed from the backend
+	LastUpdated metav1.Time `json:"lastUpdated"`
+}
+
+// AdaptorCapability is an optional feature of an adaptor, published so that higher layers can adapt their behavior
+// rather than attempting an operation that is not supported
+// +kubebuilder:validation:Enum=PowerControl;FirmwareUpdate;BIOSConfig;ScaleUp;ScaleDown;Decommission;Consolidation;CapacityReporting;FreeNodeQuery;ConsistencyCheck
+type AdaptorCapability string
+
+const (
+	// CapabilityPowerControl is the powering on and off of nodes, with the desired power state annotation
+	CapabilityPowerControl AdaptorCapability = "PowerControl"
+	// CapabilityFirmwareUpdate is the update of node firmware on a change of hardware profile
+	CapabilityFirmwareUpdate AdaptorCapability = "FirmwareUpdate"
+	// CapabilityBIOSConfig is the update of node BIOS settings on a change of hardware profile
+	CapabilityBIOSConfig AdaptorCapability = "BIOSConfig"
+	// CapabilityScaleUp is the extension of a provisioned NodePool with additional nodes
+	CapabilityScaleUp AdaptorCapability = "ScaleUp"
+	// CapabilityScaleDown is the release of individual nodes from a provisioned NodePool
+	CapabilityScaleDown AdaptorCapability = "ScaleDown"
+	// CapabilityDecommission is the decommissioning of nodes
+	CapabilityDecommission AdaptorCapability = "Decommission"
+	// CapabilityConsolidation is the re-packing of allocations across the nodes of resource pools
+	CapabilityConsolidation AdaptorCapability = "Consolidation"
+	// CapabilityCapacityReporting is the reporting of the node capacity of the hardware manager
+	CapabilityCapacityReporting AdaptorCapability = "CapacityReporting"
+	// CapabilityFreeNodeQuery is the listing of the free nodes of resource pools
+	CapabilityFreeNodeQuery AdaptorCapability = "FreeNodeQuery"
+	// CapabilityConsistencyCheck is the cross-checking of allocation records against the Nodes at startup
+	CapabilityConsistencyCheck AdaptorCapability = "ConsistencyCheck"
+)
+
+// SelfTestStep is the result of a step of a HardwareManager self-test
+type SelfTestStep struct {
+	// Name is the name of the step, such as Authenticate, ListResourcePools or GetNode
+	Name string `json:"name"`
+
+	// Passed is true if the step succeeded
+	Passed bool `json:"passed"`
+
+	// Message describes the outcome of the step
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// DurationMilliseconds is the time taken by the step
+	DurationMilliseconds int64 `json:"durationMilliseconds"`
+}
+
+// SelfTestStatus describes the results of the last self-test of a hardware manager
+type SelfTestStatus struct {
+	// Trigger is the value of the selfTest annotation that requested the self-test
+	Trigger string `json:"trigger"`
+
+	// Passed is true if all steps of the self-test succeeded
+	Passed bool `json:"passed"`
+
+	// StartTime is the time the self-test started
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is the time the self-test completed
+	CompletionTime metav1.Time `json:"completionTime"`
+
+	// Steps are the results of the steps run, in order. The steps following a failed step are not run
+	// +optional
+	Steps []SelfTestStep `json:"steps,omitempty"`
+}
+
+// SnapshotStatus describes the last inventory snapshot of the hardware manager
+type SnapshotStatus struct {
+	// Trigger is the value of the snapshot annotation that requested the snapshot
+	Trigger string `json:"trigger"`
+
+	// CaptureTime is the time the snapshot was captured
+	CaptureTime metav1.Time `json:"captureTime"`
+
+	// ConfigMap is the name of the configmap holding the snapshot
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// Nodes is the number of nodes in the snapshot, free and allocated
+	Nodes int `json:"nodes"`
+
+	// Message describes any parts of the inventory that could not be captured, or why the snapshot failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ConsistencyIssue is an inconsistency found between the Nodes, bmc-secrets and allocation records of a hardware
+// manager, such as may be left by a restart of the plugin part way through an allocation
+type ConsistencyIssue struct {
+	// Kind is the kind of inconsistency: OrphanedBMCSecret, MissingBMCSecret, OrphanedAllocation or UnallocatedNode
+	Kind string `json:"kind"`
+
+	// NodePool is the name of the NodePool the inconsistency was found in
+	NodePool string `json:"nodePool"`
+
+	// Name is the name of the affected secret, node or allocation record
+	Name string `json:"name"`
+
+	// Repaired is true if the inconsistency was repaired
+	Repaired bool `json:"repaired"`
+
+	// Message describes the repair, or why the inconsistency was not repaired
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ConsistencyCheckStatus describes the results of the consistency check run at startup of the plugin
+type ConsistencyCheckStatus struct {
+	// CompletionTime is the time the consistency check completed
+	CompletionTime metav1.Time `json:"completionTime"`
+
+	// Issues are the inconsistencies found by the check
+	// +optional
+	Issues []ConsistencyIssue `json:"issues,omitempty"`
+}
+
+// HardwareManagerStatus defines the observed state of HardwareManager
+type HardwareManagerStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the state of the UpdateService resource.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ResourcePools provides a per-site list of resource pools
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ResourcePools PerSiteResourcePoolList `json:"resourcePools,omitempty"`
+
+	// Capacity provides the node capacity of the hardware manager, refreshed periodically, for adaptors that support
+	// capacity reporting
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Capacity *CapacityStatus `json:"capacity,omitempty"`
+
+	// Capabilities lists the optional features supported by the adaptor for the hardware manager, as configured
+	// +optional
+	// +listType=set
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Capabilities []AdaptorCapability `json:"capabilities,omitempty"`
+
+	// SelfTest provides the results of the last self-test of the hardware manager, requested with the selfTest
+	// annotation
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	SelfTest *SelfTestStatus `json:"selfTest,omitempty"`
+
+	// Snapshot provides the details of the last inventory snapshot of the hardware manager, requested with the snapshot
+	// annotation
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Snapshot *SnapshotStatus `json:"snapshot,omitempty"`
+
+	// ConsistencyCheck provides the results of the consistency check of the Nodes, bmc-secrets and allocation records
+	// of the hardware manager, run at startup of the plugin
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ConsistencyCheck *ConsistencyCheckStatus `json:"consistencyCheck,omitempty"`
 }
 
 // +operator-sdk:csv:customresourcedefinitions:resources={{Service,v1,policy-engine-service}}
@@ -149,6 +1457,9 @@ type HardwareManagerStatus struct {
 // +kubebuilder:resource:shortName=hwmgr;hwmgrs
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the HardwareManager resource."
 // +kubebuilder:printcolumn:name="Adaptor ID",type="string",JSONPath=".status.adaptorId",description="The adaptor ID.",priority=1
+// +kubebuilder:printcolumn:name="Total Nodes",type="integer",JSONPath=".status.capacity.totalNodes",description="The total number of nodes.",priority=1
+// +kubebuilder:printcolumn:name="Free Nodes",type="integer",JSONPath=".status.capacity.freeNodes",description="The number of free nodes.",priority=1
+// +kubebuilder:printcolumn:name="Reserved Nodes",type="integer",JSONPath=".status.capacity.reservedNodes",description="The number of reserved nodes.",priority=1
 // +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[-1:].reason"
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[-1:].status"
 // +kubebuilder:printcolumn:name="Details",type="string",JSONPath=".status.conditions[-1:].message"
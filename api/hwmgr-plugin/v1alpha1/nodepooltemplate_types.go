@@ -0,0 +1,195 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodePoolInstanceState is the state of a NodePool instantiated from a NodePoolTemplate
+type NodePoolInstanceState string
+
+// NodePoolInstanceStates define the states of the NodePools instantiated from a NodePoolTemplate
+var NodePoolInstanceStates = struct {
+	Created NodePoolInstanceState
+	Exists  NodePoolInstanceState
+	Failed  NodePoolInstanceState
+}{
+	Created: "Created",
+	Exists:  "Exists",
+	Failed:  "Failed",
+}
+
+// NodePoolTemplateNodeGroup defines a nodegroup of the NodePools instantiated from a NodePoolTemplate
+type NodePoolTemplateNodeGroup struct {
+	// Name is the name of the nodegroup
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Role is the role of the nodes of the nodegroup
+	// +kubebuilder:validation:Enum=master;worker
+	Role string `json:"role"`
+
+	// HwProfile is the hardware profile of the nodes of the nodegroup
+	// +kubebuilder:validation:MinLength=1
+	HwProfile string `json:"hwProfile"`
+
+	// ResourcePoolId is the resource pool from which the nodes of the nodegroup are allocated
+	// +kubebuilder:validation:MinLength=1
+	ResourcePoolId string `json:"resourcePoolId"`
+
+	// Size is the default number of nodes in the nodegroup, which can be overridden per instance
+	// +kubebuilder:validation:Minimum=0
+	Size int `json:"size"`
+}
+
+// NodePoolTemplateContent defines the spec of the NodePools instantiated from a NodePoolTemplate. The string fields and
+// extension values may reference parameters as ${name}.
+type NodePoolTemplateContent struct {
+	// HwMgrId is the identifier of the HardwareManager serving the NodePools
+	// +kubebuilder:validation:MinLength=1
+	HwMgrId string `json:"hwMgrId"`
+
+	// Location is the geographical location of the NodePools
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// Site is the site of the NodePools, defaulting to the site of each instance
+	// +optional
+	Site string `json:"site,omitempty"`
+
+	// NodeGroups are the nodegroups of the NodePools
+	// +kubebuilder:validation:MinItems=1
+	NodeGroups []NodePoolTemplateNodeGroup `json:"nodeGroups"`
+
+	// Extensions are the extensions of the NodePools
+	// +optional
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// NodePoolInstance defines a NodePool instantiated from a NodePoolTemplate
+type NodePoolInstance struct {
+	// Name is the name of the NodePool, created in the namespace of the NodePoolTemplate
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// CloudID is the O-Cloud identifier of the NodePool
+	// +kubebuilder:validation:MinLength=1
+	CloudID string `json:"cloudID"`
+
+	// Site overrides the site of the template for the NodePool
+	// +optional
+	Site string `json:"site,omitempty"`
+
+	// Sizes override the size of the nodegroups of the template, keyed by nodegroup name
+	// +optional
+	Sizes map[string]int `json:"sizes,omitempty"`
+
+	// Parameters override the parameters of the template for the NodePool
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// NodePoolTemplateSpec defines the desired state of NodePoolTemplate
+// +kubebuilder:validation:XValidation:rule="has(self.template) != has(self.nodePoolRef)",message="exactly one of template or nodePoolRef must be specified"
+type NodePoolTemplateSpec struct {
+	// Template is the spec of the NodePools to instantiate
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Template *NodePoolTemplateContent `json:"template,omitempty"`
+
+	// NodePoolRef is the name of an existing NodePool in the same namespace that is cloned for each instance,
+	// as an alternative to the template
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodePoolRef string `json:"nodePoolRef,omitempty"`
+
+	// Parameters are the default values of the parameters referenced by the template. The cloudID, name, and site
+	// parameters are always defined by the instance.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Instances are the NodePools instantiated from the template. NodePools are created if they do not exist, and are
+	// never updated or deleted by the template.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Instances []NodePoolInstance `json:"instances"`
+}
+
+// NodePoolInstanceStatus is the result of the instantiation of a NodePool
+type NodePoolInstanceStatus struct {
+	// Name is the name of the NodePool
+	Name string `json:"name"`
+
+	// State is the result of the instantiation
+	State NodePoolInstanceState `json:"state"`
+
+	// Message describes the failure of the instantiation
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// NodePoolTemplateStatus defines the observed state of NodePoolTemplate
+type NodePoolTemplateStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Instances are the results of the instantiation of each NodePool
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Instances []NodePoolInstanceStatus `json:"instances,omitempty"`
+
+	// Conditions describe the state of the NodePoolTemplate resource.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=nodepooltemplates,scope=Namespaced
+// +kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.nodePoolRef"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the NodePoolTemplate resource."
+// +kubebuilder:printcolumn:name="Details",type="string",JSONPath=".status.conditions[-1:].message"
+
+// NodePoolTemplate is the Schema for the nodepooltemplates API, instantiating similar NodePools from a template or an
+// existing NodePool, with parameters substituted for each instance
+type NodePoolTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodePoolTemplateSpec   `json:"spec,omitempty"`
+	Status NodePoolTemplateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NodePoolTemplateList contains a list of NodePoolTemplate
+type NodePoolTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodePoolTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodePoolTemplate{}, &NodePoolTemplateList{})
+}
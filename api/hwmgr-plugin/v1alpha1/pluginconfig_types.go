@@ -0,0 +1,173 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PluginConfigName is the name of the PluginConfig CR holding the plugin-wide settings. Other instances are ignored.
+const PluginConfigName = "default"
+
+// LogLevel defines the verbosity of the plugin logs
+type LogLevel string
+
+// LogLevels define the supported log levels
+var LogLevels = struct {
+	Debug LogLevel
+	Info  LogLevel
+	Warn  LogLevel
+	Error LogLevel
+}{
+	Debug: "debug",
+	Info:  "info",
+	Warn:  "warn",
+	Error: "error",
+}
+
+// MetricsConfig defines the options for the metrics exposed by the plugin
+type MetricsConfig struct {
+	// DisableBackendRequestMetrics stops the recording of the per-request count, latency and auth failure metrics
+	// for requests sent to hardware manager backends
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DisableBackendRequestMetrics bool `json:"disableBackendRequestMetrics,omitempty"`
+}
+
+// AllocationCleanupConfig defines the release of backend allocations for clouds that no longer have a NodePool, such
+// as after a failed NodePool deletion
+type AllocationCleanupConfig struct {
+	// Disabled stops the release of orphaned allocations
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Disabled bool `json:"disabled,omitempty"`
+
+	// GracePeriod is the time for which the allocations of a cloud must have had no NodePool before they are
+	// released. Defaults to 1h
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// CallbackConfig defines the notifications posted to the callback URL of a NodePool when it is provisioned or fails
+type CallbackConfig struct {
+	// SigningSecret is the name of the secret, in the plugin namespace, holding the HMAC-SHA256 key used to sign
+	// callback notifications under its "key" entry. Callbacks are not sent unless a signing secret is configured
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SigningSecret string `json:"signingSecret,omitempty"`
+
+	// Timeout is the time allowed for each callback request. Defaults to 10s
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// BMCSecretProvenanceConfig defines the signing of the provenance metadata recorded on bmc-secrets
+type BMCSecretProvenanceConfig struct {
+	// SigningSecret is the name of the secret, in the plugin namespace, holding the HMAC-SHA256 key used to sign the
+	// provenance of bmc-secrets under its "key" entry. The provenance is recorded unsigned if not set
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	SigningSecret string `json:"signingSecret,omitempty"`
+}
+
+// PluginConfigSpec defines the desired state of PluginConfig
+type PluginConfigSpec struct {
+	// LogLevel sets the verbosity of the plugin logs. Defaults to info
+	// +optional
+	// +kubebuilder:validation:Enum=debug;info;warn;error
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	LogLevel LogLevel `json:"logLevel,omitempty"`
+
+	// DisabledAdaptors lists the adaptors for which NodePool processing is suspended. NodePools for a HardwareManager
+	// using a disabled adaptor are left untouched until the adaptor is re-enabled.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DisabledAdaptors []HardwareManagerAdaptorID `json:"disabledAdaptors,omitempty"`
+
+	// DefaultMaxConcurrentAllocations limits the number of nodes being actively provisioned at once against each
+	// backend, for HardwareManagers that do not set maxConcurrentAllocations. Zero, the default, is unlimited
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	DefaultMaxConcurrentAllocations int `json:"defaultMaxConcurrentAllocations,omitempty"`
+
+	// Metrics configures the metrics exposed by the plugin
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Metrics *MetricsConfig `json:"metrics,omitempty"`
+
+	// AllocationCleanup configures the release of orphaned backend allocations, for adaptors that support it.
+	// Enabled by default
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AllocationCleanup *AllocationCleanupConfig `json:"allocationCleanup,omitempty"`
+
+	// Callbacks configures the notifications posted to the callback URL of NodePools
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Callbacks *CallbackConfig `json:"callbacks,omitempty"`
+
+	// BMCSecretProvenance configures the signing of the provenance metadata recorded on bmc-secrets
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	BMCSecretProvenance *BMCSecretProvenanceConfig `json:"bmcSecretProvenance,omitempty"`
+}
+
+// PluginConfigStatus defines the observed state of PluginConfig
+type PluginConfigStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the state of the PluginConfig resource.
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=pluginconfigs,scope=Namespaced
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the PluginConfig resource."
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[-1:].reason"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[-1:].status"
+// +kubebuilder:printcolumn:name="Details",type="string",JSONPath=".status.conditions[-1:].message"
+
+// PluginConfig is the Schema for the pluginconfigs API, holding plugin-wide settings that are applied at runtime
+type PluginConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PluginConfigSpec   `json:"spec,omitempty"`
+	Status PluginConfigStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PluginConfigList contains a list of PluginConfig
+type PluginConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PluginConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PluginConfig{}, &PluginConfigList{})
+}
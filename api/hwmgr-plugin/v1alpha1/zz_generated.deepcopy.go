@@ -26,32 +26,244 @@ import (
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DellData) DeepCopyInto(out *DellData) {
+func (in *AlarmConfig) DeepCopyInto(out *AlarmConfig) {
 	*out = *in
 	if in.CaBundleName != nil {
 		in, out := &in.CaBundleName, &out.CaBundleName
 		*out = new(string)
 		**out = **in
 	}
-	if in.Tenant != nil {
-		in, out := &in.Tenant, &out.Tenant
-		*out = new(string)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlarmConfig.
+func (in *AlarmConfig) DeepCopy() *AlarmConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlarmConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationCleanupConfig) DeepCopyInto(out *AllocationCleanupConfig) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DellData.
-func (in *DellData) DeepCopy() *DellData {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationCleanupConfig.
+func (in *AllocationCleanupConfig) DeepCopy() *AllocationCleanupConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(DellData)
+	out := new(AllocationCleanupConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HardwareManager) DeepCopyInto(out *HardwareManager) {
+func (in *AllocationRetryConfig) DeepCopyInto(out *AllocationRetryConfig) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int)
+		**out = **in
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationRetryConfig.
+func (in *AllocationRetryConfig) DeepCopy() *AllocationRetryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationRetryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCAddressFormat) DeepCopyInto(out *BMCAddressFormat) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCAddressFormat.
+func (in *BMCAddressFormat) DeepCopy() *BMCAddressFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCAddressFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCEventConfig) DeepCopyInto(out *BMCEventConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCEventConfig.
+func (in *BMCEventConfig) DeepCopy() *BMCEventConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCEventConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCProbeConfig) DeepCopyInto(out *BMCProbeConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCProbeConfig.
+func (in *BMCProbeConfig) DeepCopy() *BMCProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BMCSecretProvenanceConfig) DeepCopyInto(out *BMCSecretProvenanceConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BMCSecretProvenanceConfig.
+func (in *BMCSecretProvenanceConfig) DeepCopy() *BMCSecretProvenanceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BMCSecretProvenanceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockedNode) DeepCopyInto(out *BlockedNode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockedNode.
+func (in *BlockedNode) DeepCopy() *BlockedNode {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockedNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootDiskHints) DeepCopyInto(out *BootDiskHints) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootDiskHints.
+func (in *BootDiskHints) DeepCopy() *BootDiskHints {
+	if in == nil {
+		return nil
+	}
+	out := new(BootDiskHints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CallbackConfig) DeepCopyInto(out *CallbackConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CallbackConfig.
+func (in *CallbackConfig) DeepCopy() *CallbackConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CallbackConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityStatus) DeepCopyInto(out *CapacityStatus) {
+	*out = *in
+	if in.ResourcePools != nil {
+		in, out := &in.ResourcePools, &out.ResourcePools
+		*out = make([]ResourcePoolCapacity, len(*in))
+		copy(*out, *in)
+	}
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityStatus.
+func (in *CapacityStatus) DeepCopy() *CapacityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyCheckStatus) DeepCopyInto(out *ConsistencyCheckStatus) {
+	*out = *in
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]ConsistencyIssue, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyCheckStatus.
+func (in *ConsistencyCheckStatus) DeepCopy() *ConsistencyCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyIssue) DeepCopyInto(out *ConsistencyIssue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyIssue.
+func (in *ConsistencyIssue) DeepCopy() *ConsistencyIssue {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyIssue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Consolidation) DeepCopyInto(out *Consolidation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -59,18 +271,18 @@ func (in *HardwareManager) DeepCopyInto(out *HardwareManager) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManager.
-func (in *HardwareManager) DeepCopy() *HardwareManager {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Consolidation.
+func (in *Consolidation) DeepCopy() *Consolidation {
 	if in == nil {
 		return nil
 	}
-	out := new(HardwareManager)
+	out := new(Consolidation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HardwareManager) DeepCopyObject() runtime.Object {
+func (in *Consolidation) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -78,31 +290,31 @@ func (in *HardwareManager) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HardwareManagerList) DeepCopyInto(out *HardwareManagerList) {
+func (in *ConsolidationList) DeepCopyInto(out *ConsolidationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]HardwareManager, len(*in))
+		*out = make([]Consolidation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManagerList.
-func (in *HardwareManagerList) DeepCopy() *HardwareManagerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsolidationList.
+func (in *ConsolidationList) DeepCopy() *ConsolidationList {
 	if in == nil {
 		return nil
 	}
-	out := new(HardwareManagerList)
+	out := new(ConsolidationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HardwareManagerList) DeepCopyObject() runtime.Object {
+func (in *ConsolidationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -110,33 +322,48 @@ func (in *HardwareManagerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HardwareManagerSpec) DeepCopyInto(out *HardwareManagerSpec) {
+func (in *ConsolidationMove) DeepCopyInto(out *ConsolidationMove) {
 	*out = *in
-	if in.LoopbackData != nil {
-		in, out := &in.LoopbackData, &out.LoopbackData
-		*out = new(LoopbackData)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsolidationMove.
+func (in *ConsolidationMove) DeepCopy() *ConsolidationMove {
+	if in == nil {
+		return nil
 	}
-	if in.DellData != nil {
-		in, out := &in.DellData, &out.DellData
-		*out = new(DellData)
-		(*in).DeepCopyInto(*out)
+	out := new(ConsolidationMove)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsolidationSpec) DeepCopyInto(out *ConsolidationSpec) {
+	*out = *in
+	if in.ResourcePoolIds != nil {
+		in, out := &in.ResourcePoolIds, &out.ResourcePoolIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManagerSpec.
-func (in *HardwareManagerSpec) DeepCopy() *HardwareManagerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsolidationSpec.
+func (in *ConsolidationSpec) DeepCopy() *ConsolidationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HardwareManagerSpec)
+	out := new(ConsolidationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HardwareManagerStatus) DeepCopyInto(out *HardwareManagerStatus) {
+func (in *ConsolidationStatus) DeepCopyInto(out *ConsolidationStatus) {
 	*out = *in
+	if in.Moves != nil {
+		in, out := &in.Moves, &out.Moves
+		*out = make([]ConsolidationMove, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -144,86 +371,1046 @@ func (in *HardwareManagerStatus) DeepCopyInto(out *HardwareManagerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ResourcePools != nil {
-		in, out := &in.ResourcePools, &out.ResourcePools
-		*out = make(PerSiteResourcePoolList, len(*in))
-		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = make(ResourcePoolList, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManagerStatus.
-func (in *HardwareManagerStatus) DeepCopy() *HardwareManagerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsolidationStatus.
+func (in *ConsolidationStatus) DeepCopy() *ConsolidationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HardwareManagerStatus)
+	out := new(ConsolidationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoopbackData) DeepCopyInto(out *LoopbackData) {
+func (in *DellData) DeepCopyInto(out *DellData) {
 	*out = *in
+	if in.CaBundleName != nil {
+		in, out := &in.CaBundleName, &out.CaBundleName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tenant != nil {
+		in, out := &in.Tenant, &out.Tenant
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoopbackData.
-func (in *LoopbackData) DeepCopy() *LoopbackData {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DellData.
+func (in *DellData) DeepCopy() *DellData {
 	if in == nil {
 		return nil
 	}
-	out := new(LoopbackData)
+	out := new(DellData)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in PerSiteResourcePoolList) DeepCopyInto(out *PerSiteResourcePoolList) {
-	{
-		in := &in
-		*out = make(PerSiteResourcePoolList, len(*in))
-		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = make(ResourcePoolList, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
-		}
-	}
+func (in *EmulatedBMCConfig) DeepCopyInto(out *EmulatedBMCConfig) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerSiteResourcePoolList.
-func (in PerSiteResourcePoolList) DeepCopy() PerSiteResourcePoolList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmulatedBMCConfig.
+func (in *EmulatedBMCConfig) DeepCopy() *EmulatedBMCConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(PerSiteResourcePoolList)
+	out := new(EmulatedBMCConfig)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ResourcePoolList) DeepCopyInto(out *ResourcePoolList) {
-	{
-		in := &in
-		*out = make(ResourcePoolList, len(*in))
-		copy(*out, *in)
-	}
+func (in *EnergyPolicy) DeepCopyInto(out *EnergyPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnergyPolicy.
+func (in *EnergyPolicy) DeepCopy() *EnergyPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(EnergyPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareManager) DeepCopyInto(out *HardwareManager) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManager.
+func (in *HardwareManager) DeepCopy() *HardwareManager {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareManager) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareManagerList) DeepCopyInto(out *HardwareManagerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HardwareManager, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManagerList.
+func (in *HardwareManagerList) DeepCopy() *HardwareManagerList {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareManagerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareManagerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareManagerSpec) DeepCopyInto(out *HardwareManagerSpec) {
+	*out = *in
+	if in.LoopbackData != nil {
+		in, out := &in.LoopbackData, &out.LoopbackData
+		*out = new(LoopbackData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DellData != nil {
+		in, out := &in.DellData, &out.DellData
+		*out = new(DellData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestData != nil {
+		in, out := &in.RestData, &out.RestData
+		*out = new(RestData)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteHub != nil {
+		in, out := &in.RemoteHub, &out.RemoteHub
+		*out = new(RemoteHubConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InventoryExport != nil {
+		in, out := &in.InventoryExport, &out.InventoryExport
+		*out = new(InventoryExportConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeNaming != nil {
+		in, out := &in.NodeNaming, &out.NodeNaming
+		*out = new(NodeNamingConfig)
+		**out = **in
+	}
+	if in.NodeResync != nil {
+		in, out := &in.NodeResync, &out.NodeResync
+		*out = new(NodeResyncConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InventoryCache != nil {
+		in, out := &in.InventoryCache, &out.InventoryCache
+		*out = new(InventoryCacheConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeProvisioning != nil {
+		in, out := &in.NodeProvisioning, &out.NodeProvisioning
+		*out = new(NodeProvisioningConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllocationRetry != nil {
+		in, out := &in.AllocationRetry, &out.AllocationRetry
+		*out = new(AllocationRetryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StallDetection != nil {
+		in, out := &in.StallDetection, &out.StallDetection
+		*out = new(StallDetectionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePoolSelector != nil {
+		in, out := &in.NodePoolSelector, &out.NodePoolSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WatchNamespaces != nil {
+		in, out := &in.WatchNamespaces, &out.WatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HwProfiles != nil {
+		in, out := &in.HwProfiles, &out.HwProfiles
+		*out = make([]HardwareProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReadinessChecks != nil {
+		in, out := &in.ReadinessChecks, &out.ReadinessChecks
+		*out = make([]ReadinessCheck, len(*in))
+		copy(*out, *in)
+	}
+	if in.BMCProbe != nil {
+		in, out := &in.BMCProbe, &out.BMCProbe
+		*out = new(BMCProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BMCEvents != nil {
+		in, out := &in.BMCEvents, &out.BMCEvents
+		*out = new(BMCEventConfig)
+		**out = **in
+	}
+	if in.Alarms != nil {
+		in, out := &in.Alarms, &out.Alarms
+		*out = new(AlarmConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSecrets != nil {
+		in, out := &in.NodeSecrets, &out.NodeSecrets
+		*out = make([]NodeSecretTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnergyPolicy != nil {
+		in, out := &in.EnergyPolicy, &out.EnergyPolicy
+		*out = new(EnergyPolicy)
+		**out = **in
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenancePools != nil {
+		in, out := &in.MaintenancePools, &out.MaintenancePools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlockedNodes != nil {
+		in, out := &in.BlockedNodes, &out.BlockedNodes
+		*out = make([]BlockedNode, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProvisioningWindows != nil {
+		in, out := &in.ProvisioningWindows, &out.ProvisioningWindows
+		*out = make([]ProvisioningWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.PowerBudgets != nil {
+		in, out := &in.PowerBudgets, &out.PowerBudgets
+		*out = make([]PowerBudget, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManagerSpec.
+func (in *HardwareManagerSpec) DeepCopy() *HardwareManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareManagerStatus) DeepCopyInto(out *HardwareManagerStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourcePools != nil {
+		in, out := &in.ResourcePools, &out.ResourcePools
+		*out = make(PerSiteResourcePoolList, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(ResourcePoolList, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(CapacityStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]AdaptorCapability, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelfTest != nil {
+		in, out := &in.SelfTest, &out.SelfTest
+		*out = new(SelfTestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(SnapshotStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConsistencyCheck != nil {
+		in, out := &in.ConsistencyCheck, &out.ConsistencyCheck
+		*out = new(ConsistencyCheckStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManagerStatus.
+func (in *HardwareManagerStatus) DeepCopy() *HardwareManagerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareManagerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareProfile) DeepCopyInto(out *HardwareProfile) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(StorageLayout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InterfaceRoles != nil {
+		in, out := &in.InterfaceRoles, &out.InterfaceRoles
+		*out = make([]InterfaceRoleTag, len(*in))
+		copy(*out, *in)
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(SecurityRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BMCAddressFormat != nil {
+		in, out := &in.BMCAddressFormat, &out.BMCAddressFormat
+		*out = new(BMCAddressFormat)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfile.
+func (in *HardwareProfile) DeepCopy() *HardwareProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceRoleTag) DeepCopyInto(out *InterfaceRoleTag) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceRoleTag.
+func (in *InterfaceRoleTag) DeepCopy() *InterfaceRoleTag {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceRoleTag)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InventoryCacheConfig) DeepCopyInto(out *InventoryCacheConfig) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InventoryCacheConfig.
+func (in *InventoryCacheConfig) DeepCopy() *InventoryCacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InventoryCacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InventoryExportConfig) DeepCopyInto(out *InventoryExportConfig) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InventoryExportConfig.
+func (in *InventoryExportConfig) DeepCopy() *InventoryExportConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InventoryExportConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatencyConfig) DeepCopyInto(out *LatencyConfig) {
+	*out = *in
+	if in.Mean != nil {
+		in, out := &in.Mean, &out.Mean
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StdDev != nil {
+		in, out := &in.StdDev, &out.StdDev
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatencyConfig.
+func (in *LatencyConfig) DeepCopy() *LatencyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LatencyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoopbackData) DeepCopyInto(out *LoopbackData) {
+	*out = *in
+	if in.Seed != nil {
+		in, out := &in.Seed, &out.Seed
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxAllocationDelay != nil {
+		in, out := &in.MaxAllocationDelay, &out.MaxAllocationDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(SimulatedLatency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EmulatedBMC != nil {
+		in, out := &in.EmulatedBMC, &out.EmulatedBMC
+		*out = new(EmulatedBMCConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoopbackData.
+func (in *LoopbackData) DeepCopy() *LoopbackData {
+	if in == nil {
+		return nil
+	}
+	out := new(LoopbackData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsConfig) DeepCopyInto(out *MetricsConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsConfig.
+func (in *MetricsConfig) DeepCopy() *MetricsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeNamingConfig) DeepCopyInto(out *NodeNamingConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeNamingConfig.
+func (in *NodeNamingConfig) DeepCopy() *NodeNamingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeNamingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolInstance) DeepCopyInto(out *NodePoolInstance) {
+	*out = *in
+	if in.Sizes != nil {
+		in, out := &in.Sizes, &out.Sizes
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolInstance.
+func (in *NodePoolInstance) DeepCopy() *NodePoolInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolInstanceStatus) DeepCopyInto(out *NodePoolInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolInstanceStatus.
+func (in *NodePoolInstanceStatus) DeepCopy() *NodePoolInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTemplate) DeepCopyInto(out *NodePoolTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTemplate.
+func (in *NodePoolTemplate) DeepCopy() *NodePoolTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePoolTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTemplateContent) DeepCopyInto(out *NodePoolTemplateContent) {
+	*out = *in
+	if in.NodeGroups != nil {
+		in, out := &in.NodeGroups, &out.NodeGroups
+		*out = make([]NodePoolTemplateNodeGroup, len(*in))
+		copy(*out, *in)
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTemplateContent.
+func (in *NodePoolTemplateContent) DeepCopy() *NodePoolTemplateContent {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTemplateContent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTemplateList) DeepCopyInto(out *NodePoolTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodePoolTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTemplateList.
+func (in *NodePoolTemplateList) DeepCopy() *NodePoolTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePoolTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTemplateNodeGroup) DeepCopyInto(out *NodePoolTemplateNodeGroup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTemplateNodeGroup.
+func (in *NodePoolTemplateNodeGroup) DeepCopy() *NodePoolTemplateNodeGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTemplateNodeGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTemplateSpec) DeepCopyInto(out *NodePoolTemplateSpec) {
+	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(NodePoolTemplateContent)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]NodePoolInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTemplateSpec.
+func (in *NodePoolTemplateSpec) DeepCopy() *NodePoolTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTemplateStatus) DeepCopyInto(out *NodePoolTemplateStatus) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]NodePoolInstanceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTemplateStatus.
+func (in *NodePoolTemplateStatus) DeepCopy() *NodePoolTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeProvisioningConfig) DeepCopyInto(out *NodeProvisioningConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeProvisioningConfig.
+func (in *NodeProvisioningConfig) DeepCopy() *NodeProvisioningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeProvisioningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeResyncConfig) DeepCopyInto(out *NodeResyncConfig) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeResyncConfig.
+func (in *NodeResyncConfig) DeepCopy() *NodeResyncConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeResyncConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSecretTemplate) DeepCopyInto(out *NodeSecretTemplate) {
+	*out = *in
+	if in.HwProfiles != nil {
+		in, out := &in.HwProfiles, &out.HwProfiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSecretTemplate.
+func (in *NodeSecretTemplate) DeepCopy() *NodeSecretTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSecretTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in PerSiteResourcePoolList) DeepCopyInto(out *PerSiteResourcePoolList) {
+	{
+		in := &in
+		*out = make(PerSiteResourcePoolList, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(ResourcePoolList, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerSiteResourcePoolList.
+func (in PerSiteResourcePoolList) DeepCopy() PerSiteResourcePoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(PerSiteResourcePoolList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginConfig) DeepCopyInto(out *PluginConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginConfig.
+func (in *PluginConfig) DeepCopy() *PluginConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PluginConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginConfigList) DeepCopyInto(out *PluginConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PluginConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginConfigList.
+func (in *PluginConfigList) DeepCopy() *PluginConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PluginConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginConfigSpec) DeepCopyInto(out *PluginConfigSpec) {
+	*out = *in
+	if in.DisabledAdaptors != nil {
+		in, out := &in.DisabledAdaptors, &out.DisabledAdaptors
+		*out = make([]HardwareManagerAdaptorID, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(MetricsConfig)
+		**out = **in
+	}
+	if in.AllocationCleanup != nil {
+		in, out := &in.AllocationCleanup, &out.AllocationCleanup
+		*out = new(AllocationCleanupConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Callbacks != nil {
+		in, out := &in.Callbacks, &out.Callbacks
+		*out = new(CallbackConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BMCSecretProvenance != nil {
+		in, out := &in.BMCSecretProvenance, &out.BMCSecretProvenance
+		*out = new(BMCSecretProvenanceConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginConfigSpec.
+func (in *PluginConfigSpec) DeepCopy() *PluginConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginConfigStatus) DeepCopyInto(out *PluginConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginConfigStatus.
+func (in *PluginConfigStatus) DeepCopy() *PluginConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerBudget) DeepCopyInto(out *PowerBudget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerBudget.
+func (in *PowerBudget) DeepCopy() *PowerBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningWindow) DeepCopyInto(out *ProvisioningWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningWindow.
+func (in *ProvisioningWindow) DeepCopy() *ProvisioningWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
+	*out = *in
+	if in.CaBundleName != nil {
+		in, out := &in.CaBundleName, &out.CaBundleName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyConfig.
+func (in *ProxyConfig) DeepCopy() *ProxyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteHubConfig) DeepCopyInto(out *RemoteHubConfig) {
+	*out = *in
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteHubConfig.
+func (in *RemoteHubConfig) DeepCopy() *RemoteHubConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteHubConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourcePoolCapacity) DeepCopyInto(out *ResourcePoolCapacity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcePoolCapacity.
+func (in *ResourcePoolCapacity) DeepCopy() *ResourcePoolCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourcePoolCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ResourcePoolList) DeepCopyInto(out *ResourcePoolList) {
+	{
+		in := &in
+		*out = make(ResourcePoolList, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcePoolList.
@@ -235,3 +1422,266 @@ func (in ResourcePoolList) DeepCopy() ResourcePoolList {
 	in.DeepCopyInto(out)
 	return *out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestData) DeepCopyInto(out *RestData) {
+	*out = *in
+	if in.CaBundleName != nil {
+		in, out := &in.CaBundleName, &out.CaBundleName
+		*out = new(string)
+		**out = **in
+	}
+	in.Endpoints.DeepCopyInto(&out.Endpoints)
+	out.Mappings = in.Mappings
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestData.
+func (in *RestData) DeepCopy() *RestData {
+	if in == nil {
+		return nil
+	}
+	out := new(RestData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestEndpoints) DeepCopyInto(out *RestEndpoints) {
+	*out = *in
+	out.AllocateNode = in.AllocateNode
+	out.GetNode = in.GetNode
+	out.ReleaseNode = in.ReleaseNode
+	if in.UpdateNode != nil {
+		in, out := &in.UpdateNode, &out.UpdateNode
+		*out = new(RestRequestTemplate)
+		**out = **in
+	}
+	if in.ListResourcePools != nil {
+		in, out := &in.ListResourcePools, &out.ListResourcePools
+		*out = new(RestRequestTemplate)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestEndpoints.
+func (in *RestEndpoints) DeepCopy() *RestEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(RestEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestFieldMappings) DeepCopyInto(out *RestFieldMappings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestFieldMappings.
+func (in *RestFieldMappings) DeepCopy() *RestFieldMappings {
+	if in == nil {
+		return nil
+	}
+	out := new(RestFieldMappings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestRequestTemplate) DeepCopyInto(out *RestRequestTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestRequestTemplate.
+func (in *RestRequestTemplate) DeepCopy() *RestRequestTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(RestRequestTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityRequirements) DeepCopyInto(out *SecurityRequirements) {
+	*out = *in
+	if in.TPM != nil {
+		in, out := &in.TPM, &out.TPM
+		*out = new(TPMRequirement)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityRequirements.
+func (in *SecurityRequirements) DeepCopy() *SecurityRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfTestStatus) DeepCopyInto(out *SelfTestStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]SelfTestStep, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfTestStatus.
+func (in *SelfTestStatus) DeepCopy() *SelfTestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfTestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfTestStep) DeepCopyInto(out *SelfTestStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfTestStep.
+func (in *SelfTestStep) DeepCopy() *SelfTestStep {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfTestStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SimulatedLatency) DeepCopyInto(out *SimulatedLatency) {
+	*out = *in
+	if in.Allocate != nil {
+		in, out := &in.Allocate, &out.Allocate
+		*out = new(LatencyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Release != nil {
+		in, out := &in.Release, &out.Release
+		*out = new(LatencyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatusUpdate != nil {
+		in, out := &in.StatusUpdate, &out.StatusUpdate
+		*out = new(LatencyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpecChange != nil {
+		in, out := &in.SpecChange, &out.SpecChange
+		*out = new(LatencyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SimulatedLatency.
+func (in *SimulatedLatency) DeepCopy() *SimulatedLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(SimulatedLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotStatus) DeepCopyInto(out *SnapshotStatus) {
+	*out = *in
+	in.CaptureTime.DeepCopyInto(&out.CaptureTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotStatus.
+func (in *SnapshotStatus) DeepCopy() *SnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StallDetectionConfig) DeepCopyInto(out *StallDetectionConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StallDetectionConfig.
+func (in *StallDetectionConfig) DeepCopy() *StallDetectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StallDetectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageLayout) DeepCopyInto(out *StorageLayout) {
+	*out = *in
+	if in.VirtualDisks != nil {
+		in, out := &in.VirtualDisks, &out.VirtualDisks
+		*out = make([]VirtualDisk, len(*in))
+		copy(*out, *in)
+	}
+	if in.BootDisk != nil {
+		in, out := &in.BootDisk, &out.BootDisk
+		*out = new(BootDiskHints)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageLayout.
+func (in *StorageLayout) DeepCopy() *StorageLayout {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageLayout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TPMRequirement) DeepCopyInto(out *TPMRequirement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TPMRequirement.
+func (in *TPMRequirement) DeepCopy() *TPMRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(TPMRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualDisk) DeepCopyInto(out *VirtualDisk) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualDisk.
+func (in *VirtualDisk) DeepCopy() *VirtualDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualDisk)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,544 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// hwmgrctl is an operational CLI for the O-Cloud Hardware Manager Plugin, for debugging provisioning issues in the
+// field. It interacts with the plugin through its CRs, and requires no access to the backend hardware managers.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/loopback"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	defaultNamespace = "oran-hwmgr-plugin"
+
+	snapshotPollInterval = 2 * time.Second
+	snapshotTimeout      = 2 * time.Minute
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(hwmgmtv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(pluginv1alpha1.AddToScheme(scheme))
+}
+
+// command is a hwmgrctl subcommand
+type command struct {
+	usage       string
+	description string
+	nargs       int
+	run         func(ctx context.Context, c client.Client, namespace string, args []string) error
+}
+
+var commands = map[string]command{
+	"pools": {
+		usage:       "pools [hwmgr]",
+		description: "List the resource pools and free nodes of each HardwareManager",
+		run:         listPools,
+	},
+	"allocation": {
+		usage:       "allocation <cloudID>",
+		description: "Show the NodePools and nodes allocated for a cloud",
+		nargs:       1,
+		run:         showAllocation,
+	},
+	"release-node": {
+		usage:       "release-node <node> [wipe]",
+		description: "Force-release a node from its NodePool, via the decommission workflow, optionally wiping its disks",
+		nargs:       1,
+		run:         releaseNode,
+	},
+	"free-node": {
+		usage:       "free-node <node> <reason> [message]",
+		description: "Release a node from its NodePool back to the free nodes of its resource pool, with a reason code",
+		nargs:       2,
+		run:         freeNode,
+	},
+	"resync": {
+		usage:       "resync <nodepool>",
+		description: "Trigger a resync of the node hardware details of a NodePool from the backend",
+		nargs:       1,
+		run:         resyncNodePool,
+	},
+	"snapshot": {
+		usage:       "snapshot <hwmgr> [file]",
+		description: "Capture the inventory and allocation state of a HardwareManager, as YAML, or JSON for a .json file",
+		nargs:       1,
+		run:         snapshotHwMgr,
+	},
+	"import-snapshot": {
+		usage:       "import-snapshot <file> <hwmgr>",
+		description: "Import an inventory snapshot into a loopback HardwareManager, recreating its NodePools",
+		nargs:       2,
+		run:         importSnapshot,
+	},
+	"verify-bmc-secret": {
+		usage:       "verify-bmc-secret <node>",
+		description: "Show the provenance of the bmc-secret of a node, verifying its signature",
+		nargs:       1,
+		run:         verifyBMCSecret,
+	},
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <command> [args]\n\nCommands:\n", os.Args[0])
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %-30s %s\n", commands[name].usage, commands[name].description)
+	}
+	fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func _main() int {
+	var namespace string
+	flag.StringVar(&namespace, "namespace", defaultNamespace, "The namespace of the plugin")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		return 1
+	}
+
+	cmd, exists := commands[args[0]]
+	if !exists || len(args)-1 < cmd.nargs {
+		flag.Usage()
+		return 1
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: unable to get kubeconfig: %v\n", err)
+		return 1
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: unable to create client: %v\n", err)
+		return 1
+	}
+
+	if err := cmd.run(context.Background(), c, namespace, args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func main() {
+	os.Exit(_main())
+}
+
+// listPools prints the resource pools of each HardwareManager, with the capacity reported by the adaptor
+func listPools(ctx context.Context, c client.Client, namespace string, args []string) error {
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := c.List(ctx, hwmgrs, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list HardwareManagers: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HWMGR\tADAPTOR\tSITE\tPOOL\tTOTAL\tFREE\tRESERVED")
+	for _, hwmgr := range hwmgrs.Items {
+		if len(args) > 0 && hwmgr.Name != args[0] {
+			continue
+		}
+
+		capacity := make(map[string]pluginv1alpha1.ResourcePoolCapacity)
+		if hwmgr.Status.Capacity != nil {
+			for _, pool := range hwmgr.Status.Capacity.ResourcePools {
+				capacity[pool.ResourcePoolId] = pool
+			}
+		}
+
+		sites := make([]string, 0, len(hwmgr.Status.ResourcePools))
+		for site := range hwmgr.Status.ResourcePools {
+			sites = append(sites, site)
+		}
+		sort.Strings(sites)
+
+		for _, site := range sites {
+			for _, poolId := range hwmgr.Status.ResourcePools[site] {
+				total, free, reserved := "-", "-", "-"
+				if pool, exists := capacity[poolId]; exists {
+					total = fmt.Sprint(pool.TotalNodes)
+					free = fmt.Sprint(pool.FreeNodes)
+					reserved = fmt.Sprint(pool.ReservedNodes)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", hwmgr.Name, hwmgr.Spec.AdaptorID, site, poolId, total, free, reserved)
+			}
+		}
+	}
+
+	return w.Flush() // nolint: wrapcheck
+}
+
+// showAllocation prints the NodePools of a cloud, with the status of each of their nodes
+func showAllocation(ctx context.Context, c client.Client, namespace string, args []string) error {
+	cloudID := args[0]
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.List(ctx, nodepools, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	nodes := &hwmgmtv1alpha1.NodeList{}
+	if err := c.List(ctx, nodes, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list Nodes: %w", err)
+	}
+
+	found := false
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODEPOOL\tHWMGR\tNODE\tGROUP\tNODE ID\tPROVISIONED\tDETAILS")
+	for _, nodepool := range nodepools.Items {
+		if nodepool.Spec.CloudID != cloudID {
+			continue
+		}
+		found = true
+
+		condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		fmt.Fprintf(w, "%s\t%s\t\t\t\t%s\n", nodepool.Name, nodepool.Spec.HwMgrId, conditionSummary(condition))
+
+		for _, node := range nodes.Items {
+			if node.Spec.NodePool != nodepool.Name {
+				continue
+			}
+			condition := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+			details := utils.GetNodeDegradedReason(&node)
+			fmt.Fprintf(w, "\t\t%s\t%s\t%s\t%s\t%s\n",
+				node.Name, node.Spec.GroupName, node.Spec.HwMgrNodeId, conditionSummary(condition), details)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	if !found {
+		return fmt.Errorf("no NodePool found for cloud %s", cloudID)
+	}
+	return nil
+}
+
+// conditionSummary formats the status and reason of a condition
+func conditionSummary(condition *metav1.Condition) string {
+	if condition == nil {
+		return "Unknown"
+	}
+	return fmt.Sprintf("%s (%s)", condition.Status, condition.Reason)
+}
+
+// releaseNode annotates the Node for decommission, which releases it from the NodePool allocation and deletes the
+// Node CR. The disks are retained unless wipe is specified.
+func releaseNode(ctx context.Context, c client.Client, namespace string, args []string) error {
+	mode := utils.DecommissionModeRetain
+	if len(args) > 1 {
+		if args[1] != string(utils.DecommissionModeWipe) {
+			return fmt.Errorf("unexpected argument %q", args[1])
+		}
+		mode = utils.DecommissionModeWipe
+	}
+
+	node := &hwmgmtv1alpha1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: args[0], Namespace: namespace}, node); err != nil {
+		return fmt.Errorf("failed to get Node %s: %w", args[0], err)
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[utils.DecommissionAnnotation] = string(mode)
+	node.SetAnnotations(annotations)
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to annotate Node %s: %w", node.Name, err)
+	}
+
+	fmt.Printf("Node %s released with mode %s. The decommission report is saved to configmap %s\n",
+		node.Name, mode, utils.DecommissionReportName(node.Name))
+	return nil
+}
+
+// freeNode annotates the Node for release from its NodePool, with the reason code and optional message recorded in the
+// NodePool status
+func freeNode(ctx context.Context, c client.Client, namespace string, args []string) error {
+	node := &hwmgmtv1alpha1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: args[0], Namespace: namespace}, node); err != nil {
+		return fmt.Errorf("failed to get Node %s: %w", args[0], err)
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[utils.ReleaseAnnotation] = args[1]
+	if len(args) > 2 {
+		annotations[utils.ReleaseMessageAnnotation] = strings.Join(args[2:], " ")
+	}
+	node.SetAnnotations(annotations)
+
+	// The reason code is validated before the Node is annotated, rather than reported on the Node status
+	if _, _, _, err := utils.GetNodeReleaseReason(node); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	if err := c.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to annotate Node %s: %w", node.Name, err)
+	}
+
+	fmt.Printf("Node %s released with reason %s. The release is recorded in the %s condition of NodePool %s\n",
+		node.Name, args[1], utils.NodePoolNodeReleased, node.Spec.NodePool)
+	return nil
+}
+
+// resyncNodePool clears the last resync time of the NodePool, so that its nodes are resynced on the next reconcile
+func resyncNodePool(ctx context.Context, c client.Client, namespace string, args []string) error {
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	if err := c.Get(ctx, client.ObjectKey{Name: args[0], Namespace: namespace}, nodepool); err != nil {
+		return fmt.Errorf("failed to get NodePool %s: %w", args[0], err)
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := c.Get(ctx, client.ObjectKey{Name: nodepool.Spec.HwMgrId, Namespace: namespace}, hwmgr); err != nil {
+		return fmt.Errorf("failed to get HardwareManager %s: %w", nodepool.Spec.HwMgrId, err)
+	}
+	if _, enabled := utils.GetNodeResyncInterval(hwmgr); !enabled {
+		return fmt.Errorf("node resync is not enabled in HardwareManager %s", hwmgr.Name)
+	}
+
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	annotations := nodepool.GetAnnotations()
+	delete(annotations, utils.LastNodeResyncAnnotation)
+	nodepool.SetAnnotations(annotations)
+	if err := c.Patch(ctx, nodepool, patch); err != nil {
+		return fmt.Errorf("failed to patch NodePool %s: %w", nodepool.Name, err)
+	}
+
+	fmt.Printf("Resync triggered for NodePool %s\n", nodepool.Name)
+	return nil
+}
+
+// verifyBMCSecret prints the provenance recorded on the bmc-secret of a node, and verifies its signature against the
+// provenance signing secret configured in the PluginConfig
+func verifyBMCSecret(ctx context.Context, c client.Client, namespace string, args []string) error {
+	secret := &corev1.Secret{}
+	secretName := utils.BMCSecretName(args[0])
+	if err := c.Get(ctx, client.ObjectKey{Name: secretName, Namespace: namespace}, secret); err != nil {
+		return fmt.Errorf("failed to get bmc-secret %s: %w", secretName, err)
+	}
+
+	provenance, err := utils.GetBMCSecretProvenance(secret)
+	if err != nil {
+		return err // nolint: wrapcheck
+	}
+	if provenance == nil {
+		return fmt.Errorf("no provenance recorded on bmc-secret %s", secretName)
+	}
+
+	fmt.Printf("Source:      %s\n", provenance.Source)
+	fmt.Printf("Retrieved:   %s\n", provenance.RetrievedAt.Format(time.RFC3339))
+	fmt.Printf("Method:      %s\n", provenance.Method)
+
+	config := &pluginv1alpha1.PluginConfig{}
+	if err := c.Get(ctx, client.ObjectKey{Name: pluginv1alpha1.PluginConfigName, Namespace: namespace}, config); err != nil {
+		return fmt.Errorf("failed to get PluginConfig %s: %w", pluginv1alpha1.PluginConfigName, err)
+	}
+	if config.Spec.BMCSecretProvenance == nil || config.Spec.BMCSecretProvenance.SigningSecret == "" {
+		return fmt.Errorf("no provenance signing secret is configured in PluginConfig %s", config.Name)
+	}
+
+	signingSecret := &corev1.Secret{}
+	signingSecretName := config.Spec.BMCSecretProvenance.SigningSecret
+	if err := c.Get(ctx, client.ObjectKey{Name: signingSecretName, Namespace: namespace}, signingSecret); err != nil {
+		return fmt.Errorf("failed to get provenance signing secret %s: %w", signingSecretName, err)
+	}
+
+	if _, err := utils.VerifyBMCSecretProvenance(secret, signingSecret.Data[utils.ProvenanceSigningKey]); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	fmt.Println("Signature:   verified")
+	return nil
+}
+
+// snapshotHwMgr requests an inventory snapshot of the HardwareManager with the snapshot annotation, and writes the
+// snapshot saved by the plugin to the file, or to stdout if none is specified
+func snapshotHwMgr(ctx context.Context, c client.Client, namespace string, args []string) error {
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := c.Get(ctx, client.ObjectKey{Name: args[0], Namespace: namespace}, hwmgr); err != nil {
+		return fmt.Errorf("failed to get HardwareManager %s: %w", args[0], err)
+	}
+
+	trigger := time.Now().UTC().Format(time.RFC3339Nano)
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	annotations := hwmgr.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[utils.SnapshotAnnotation] = trigger
+	hwmgr.SetAnnotations(annotations)
+	if err := c.Patch(ctx, hwmgr, patch); err != nil {
+		return fmt.Errorf("failed to annotate HardwareManager %s: %w", hwmgr.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+	for hwmgr.Status.Snapshot == nil || hwmgr.Status.Snapshot.Trigger != trigger {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the snapshot of HardwareManager %s", hwmgr.Name)
+		case <-time.After(snapshotPollInterval):
+		}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(hwmgr), hwmgr); err != nil {
+			return fmt.Errorf("failed to get HardwareManager %s: %w", hwmgr.Name, err)
+		}
+	}
+
+	status := hwmgr.Status.Snapshot
+	if status.ConfigMap == "" {
+		return errors.New(status.Message)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: status.ConfigMap, Namespace: namespace}, cm); err != nil {
+		return fmt.Errorf("failed to get snapshot configmap %s: %w", status.ConfigMap, err)
+	}
+	data := []byte(cm.Data[utils.InventorySnapshotKey])
+
+	if len(args) > 1 && strings.HasSuffix(args[1], ".json") {
+		jsonData, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return fmt.Errorf("failed to convert snapshot to JSON: %w", err)
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, jsonData, "", "  "); err != nil {
+			return fmt.Errorf("failed to format snapshot: %w", err)
+		}
+		data = append(indented.Bytes(), '\n')
+	}
+
+	if status.Message != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", status.Message)
+	}
+
+	if len(args) < 2 {
+		_, err := os.Stdout.Write(data)
+		return err // nolint: wrapcheck
+	}
+	if err := os.WriteFile(args[1], data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Snapshot of %d nodes of HardwareManager %s written to %s\n", status.Nodes, hwmgr.Name, args[1])
+	return nil
+}
+
+// importSnapshot loads the nodes of an inventory snapshot into the nodelist of a loopback HardwareManager, as free
+// nodes, and recreates the NodePools of the snapshot for it, adopting the nodes that were allocated to them
+func importSnapshot(ctx context.Context, c client.Client, namespace string, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	snapshot, err := utils.ParseInventorySnapshot(data)
+	if err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := c.Get(ctx, client.ObjectKey{Name: args[1], Namespace: namespace}, hwmgr); err != nil {
+		return fmt.Errorf("failed to get HardwareManager %s: %w", args[1], err)
+	}
+	if hwmgr.Spec.AdaptorID != pluginv1alpha1.SupportedAdaptors.Loopback {
+		return fmt.Errorf("snapshots can only be imported into a loopback HardwareManager, but %s uses adaptor %s",
+			hwmgr.Name, hwmgr.Spec.AdaptorID)
+	}
+
+	imported, err := loopback.ImportSnapshot(ctx, c, namespace, snapshot)
+	if err != nil {
+		return err // nolint: wrapcheck
+	}
+	fmt.Printf("Imported %d nodes of HardwareManager %s, captured at %s\n",
+		imported, snapshot.HwMgrId, snapshot.CapturedAt.Format(time.RFC3339))
+
+	watchNamespaces := utils.GetHardwareManagerWatchNamespaces(hwmgr)
+	for _, entry := range snapshot.NodePools {
+		nodepool := &hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: entry.Name, Namespace: entry.Namespace},
+			Spec:       *entry.Spec.DeepCopy(),
+		}
+		if !slices.Contains(watchNamespaces, nodepool.Namespace) {
+			nodepool.Namespace = hwmgr.Namespace
+		}
+		nodepool.Spec.HwMgrId = hwmgr.Name
+
+		adopted, err := yaml.Marshal(snapshot.GetAdoptedNodes(entry.Name))
+		if err != nil {
+			return fmt.Errorf("failed to marshal adopted nodes of NodePool %s: %w", entry.Name, err)
+		}
+		if nodepool.Spec.Extensions == nil {
+			nodepool.Spec.Extensions = make(map[string]string)
+		}
+		nodepool.Spec.Extensions[utils.AdoptNodesKey] = string(adopted)
+
+		if err := c.Create(ctx, nodepool); err != nil {
+			if k8serrors.IsAlreadyExists(err) {
+				fmt.Printf("NodePool %s/%s already exists, skipped\n", nodepool.Namespace, nodepool.Name)
+				continue
+			}
+			return fmt.Errorf("failed to create NodePool %s: %w", nodepool.Name, err)
+		}
+		fmt.Printf("Created NodePool %s/%s\n", nodepool.Namespace, nodepool.Name)
+	}
+
+	return nil
+}
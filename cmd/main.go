@@ -33,19 +33,35 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 
+	capabilitiescontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/capabilities"
+	capacitycontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/capacity"
+	consistencycontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/consistency"
+	consolidationcontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/consolidation"
+	inventorycontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/inventory"
+	nodepooltemplatecontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/nodepooltemplate"
 	o2imshardwaremanagementcontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/o2ims-hardwaremanagement"
+	pluginconfigcontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/pluginconfig"
+	remotehubcontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/remotehub"
+	selftestcontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/selftest"
+	snapshotcontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/snapshot"
+	summarycontroller "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/summary"
+	o2imshardwaremanagementwebhook "github.com/openshift-kni/oran-hwmgr-plugin/internal/webhook/o2ims-hardwaremanagement"
 
 	//+kubebuilder:scaffold:imports
 
@@ -71,9 +87,21 @@ func _main() int {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var apiServerAddr string
+	var enableWebhooks bool
+	var emulatedBMCAddr string
+	var bmcEventsAddr string
+	var enabledAdaptors string
+	var readOnly bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&apiServerAddr, "api-bind-address", ":8082", "The address the API server binds to.")
+	flag.StringVar(&emulatedBMCAddr, "emulated-bmc-bind-address", "0",
+		"The address the emulated BMC server of the loopback adaptor binds to. Set this to '0' to disable it.")
+	flag.StringVar(&bmcEventsAddr, "bmc-events-bind-address", "0",
+		"The address the listener for the Redfish events of the node BMCs binds to. Set this to '0' to disable it.")
+	flag.StringVar(&enabledAdaptors, "enabled-adaptors", os.Getenv("ENABLED_ADAPTORS"),
+		"Comma-separated list of the adaptors to enable. All adaptors are enabled if empty. "+
+			"Defaults to the value of the ENABLED_ADAPTORS env variable")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -81,6 +109,11 @@ func _main() int {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", os.Getenv("ENABLE_WEBHOOKS") == "true",
+		"If set, the admission webhooks will be served. Defaults to the value of the ENABLE_WEBHOOKS env variable")
+	flag.BoolVar(&readOnly, "read-only", os.Getenv("READ_ONLY") == "true",
+		"If set, the plugin reconciles and reports status, but makes no other changes to CRs and no mutating requests "+
+			"to backends, such as while restoring a hub from backup. Defaults to the value of the READ_ONLY env variable")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,6 +122,12 @@ func _main() int {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	enabledAdaptorIDs, err := adaptors.ParseEnabledAdaptors(enabledAdaptors)
+	if err != nil {
+		setupLog.Error(err, "invalid enabled adaptors")
+		return 1
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -109,13 +148,24 @@ func _main() int {
 		TLSOpts: tlsOpts,
 	})
 
+	utils.SetReadOnly(readOnly)
+	if readOnly {
+		setupLog.Info("Starting in read-only mode: CR changes other than status, and mutating backend requests, are blocked")
+	}
+
 	myNamespace := os.Getenv("MY_POD_NAMESPACE")
 	if myNamespace == "" {
 		setupLog.Error(fmt.Errorf("unable to find env variable MY_POD_NAMESPACE"), "unable to determine namespace")
 		return 1
 	}
 
-	namespaces := [...]string{myNamespace} // List of Namespaces
+	// The namespaces watched by the plugin are read from the HardwareManagers at startup
+	namespaces, err := getWatchNamespaces(myNamespace, enabledAdaptorIDs)
+	if err != nil {
+		setupLog.Error(err, "unable to determine watch namespaces, watching only the plugin namespace")
+		namespaces = []string{myNamespace}
+	}
+	setupLog.Info("Watching namespaces", "namespaces", namespaces)
 	defaultNamespaces := make(map[string]cache.Config)
 
 	for _, ns := range namespaces {
@@ -138,6 +188,15 @@ func _main() int {
 			DefaultNamespaces: defaultNamespaces,
 		},
 
+		// Changes made by the controllers are blocked in read-only mode
+		NewClient: func(config *rest.Config, options client.Options) (client.Client, error) {
+			c, err := client.New(config, options)
+			if err != nil {
+				return nil, err // nolint: wrapcheck
+			}
+			return utils.NewReadOnlyClient(c), nil
+		},
+
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -155,11 +214,24 @@ func _main() int {
 		return 1
 	}
 
-	hwmgrAdaptor := &adaptors.HwMgrAdaptorController{
+	if err = (&pluginconfigcontroller.PluginConfigReconciler{
 		Client:    mgr.GetClient(),
 		Scheme:    mgr.GetScheme(),
-		Logger:    slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With("controller", "adaptors"),
+		Logger:    slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "PluginConfig"),
 		Namespace: myNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PluginConfig")
+		return 1
+	}
+
+	hwmgrAdaptor := &adaptors.HwMgrAdaptorController{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Logger:          slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "adaptors"),
+		Namespace:       myNamespace,
+		EmulatedBMCAddr: emulatedBMCAddr,
+		EnabledAdaptors: enabledAdaptorIDs,
+		Recorder:        mgr.GetEventRecorderFor("oran-hwmgr-plugin"),
 	}
 	if err = hwmgrAdaptor.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to setup adaptor controller")
@@ -170,13 +242,163 @@ func _main() int {
 		Manager:      mgr,
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
-		Logger:       slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With("controller", "NodePool"),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "NodePool"),
 		Namespace:    myNamespace,
 		HwMgrAdaptor: hwmgrAdaptor,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NodePool")
 		return 1
 	}
+
+	if err = (&o2imshardwaremanagementcontroller.NodeReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "Node"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+		Recorder:     mgr.GetEventRecorderFor("oran-hwmgr-plugin"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Node")
+		return 1
+	}
+
+	if err = (&sdk.BMCEventReceiver{
+		Client:    mgr.GetClient(),
+		Logger:    slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("server", "BMCEvents"),
+		Recorder:  mgr.GetEventRecorderFor("oran-hwmgr-plugin"),
+		Addr:      bmcEventsAddr,
+		Namespace: myNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to setup BMC event listener")
+		return 1
+	}
+
+	if err = (&o2imshardwaremanagementcontroller.NodePoolStatusReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Logger:    slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "NodePoolStatus"),
+		Namespace: myNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NodePoolStatus")
+		return 1
+	}
+
+	if err = (&remotehubcontroller.RemoteHubReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Logger:    slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "RemoteHub"),
+		Namespace: myNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RemoteHub")
+		return 1
+	}
+
+	if err = (&inventorycontroller.InventoryExportReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "InventoryExport"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "InventoryExport")
+		return 1
+	}
+
+	if err = (&summarycontroller.StatusSummaryReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Logger:    slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "StatusSummary"),
+		Namespace: myNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "StatusSummary")
+		return 1
+	}
+
+	if err = (&capabilitiescontroller.CapabilitiesReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "Capabilities"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Capabilities")
+		return 1
+	}
+
+	if err = (&capacitycontroller.CapacityReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "Capacity"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Capacity")
+		return 1
+	}
+
+	if err = (&selftestcontroller.SelfTestReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "SelfTest"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SelfTest")
+		return 1
+	}
+
+	if err = (&snapshotcontroller.SnapshotReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "Snapshot"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Snapshot")
+		return 1
+	}
+
+	if err = (&consistencycontroller.ConsistencyReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "Consistency"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Consistency")
+		return 1
+	}
+
+	if err = (&consolidationcontroller.ConsolidationReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "Consolidation"),
+		Namespace:    myNamespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Consolidation")
+		return 1
+	}
+
+	if err = (&nodepooltemplatecontroller.NodePoolTemplateReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Logger: slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("controller", "NodePoolTemplate"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NodePoolTemplate")
+		return 1
+	}
+
+	if enableWebhooks {
+		if err = (&o2imshardwaremanagementwebhook.NodePoolWebhook{
+			Client:    mgr.GetClient(),
+			Logger:    slog.New(logging.NewLoggingContextHandler(logging.LogLevel)).With("webhook", "NodePool"),
+			Namespace: myNamespace,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NodePool")
+			return 1
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -194,7 +416,7 @@ func _main() int {
 	defer cancel()
 	go func() {
 		setupLog.Info("starting API server")
-		err = server.RunServer(ctx, apiServerAddr)
+		err = server.RunServer(ctx, apiServerAddr, mgr.GetClient(), myNamespace, hwmgrAdaptor)
 		if err != nil {
 			setupLog.Error(err, "unable to start API server")
 			serverErrors <- err
@@ -222,6 +444,22 @@ func _main() int {
 	}
 }
 
+// getWatchNamespaces returns the namespaces to be watched by the plugin, based on the HardwareManagers in the plugin
+// namespace. A client without a cache is used, as the manager is not yet created.
+func getWatchNamespaces(namespace string, enabledAdaptors []string) ([]string, error) {
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := c.List(context.Background(), hwmgrs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list HardwareManagers: %w", err)
+	}
+
+	return utils.GetPluginWatchNamespaces(namespace, hwmgrs.Items, enabledAdaptors), nil
+}
+
 func main() {
 	os.Exit(_main())
 }
@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// CapabilitiesReconciler publishes the optional features supported by the adaptor of each HardwareManager in its
+// status. The capabilities of an adaptor may depend on the configuration of the HardwareManager, so they are refreshed
+// on each change of its spec.
+type CapabilitiesReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+
+// Reconcile queries the adaptor for the capabilities of a HardwareManager and records them in the status
+func (r *CapabilitiesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch HardwareManager", slog.String("error", err.Error()))
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	capabilities, capabilitiesErr := r.HwMgrAdaptor.Capabilities(hwmgr)
+	if capabilitiesErr != nil {
+		// The adaptor is not enabled in this deployment, so there are no capabilities to report
+		r.Logger.InfoContext(ctx, "Capabilities query failed", slog.String("error", capabilitiesErr.Error()))
+		return
+	}
+
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	if !utils.SetHardwareManagerCapabilities(hwmgr, capabilities) {
+		return
+	}
+	if err = r.Client.Status().Patch(ctx, hwmgr, patch); err != nil {
+		err = fmt.Errorf("failed to update capabilities for hardware manager (%s): %w", hwmgr.Name, err)
+		return
+	}
+
+	r.Logger.InfoContext(ctx, "Published adaptor capabilities", slog.Any("capabilities", capabilities))
+
+	return
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CapabilitiesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("capabilities").
+		For(&pluginv1alpha1.HardwareManager{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create capabilities controller: %w", err)
+	}
+
+	return nil
+}
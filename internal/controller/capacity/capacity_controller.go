@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+const (
+	// DefaultCapacityRefreshInterval is the interval at which the capacity of a hardware manager is refreshed
+	DefaultCapacityRefreshInterval = 5 * time.Minute
+)
+
+// CapacityReconciler periodically refreshes the node capacity reported in the status of each HardwareManager
+type CapacityReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+
+// Reconcile queries the adaptor for the capacity of a HardwareManager and records it in the status
+func (r *CapacityReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch HardwareManager", slog.String("error", err.Error()))
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	capacity, capacityErr := r.HwMgrAdaptor.GetCapacity(ctx, hwmgr)
+	if capacityErr != nil {
+		r.Logger.InfoContext(ctx, "Capacity query failed", slog.String("error", capacityErr.Error()))
+		return utils.RequeueWithMediumInterval(), nil
+	}
+	if capacity == nil {
+		// Capacity reporting is not supported by the adaptor
+		return
+	}
+
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	hwmgr.Status.Capacity = capacity
+	if err = r.Client.Status().Patch(ctx, hwmgr, patch); err != nil {
+		err = fmt.Errorf("failed to update capacity for hardware manager (%s): %w", hwmgr.Name, err)
+		return
+	}
+
+	r.Logger.DebugContext(ctx, "Refreshed capacity",
+		slog.Int("totalNodes", capacity.TotalNodes),
+		slog.Int("freeNodes", capacity.FreeNodes),
+		slog.Int("reservedNodes", capacity.ReservedNodes))
+
+	return utils.RequeueWithCustomInterval(DefaultCapacityRefreshInterval), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CapacityReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("capacity").
+		For(&pluginv1alpha1.HardwareManager{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create capacity controller: %w", err)
+	}
+
+	return nil
+}
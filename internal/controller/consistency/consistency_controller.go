@@ -0,0 +1,219 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consistency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// ConsistencyReconciler cross-checks the Nodes, bmc-secrets and allocation records of each HardwareManager once at
+// startup of the plugin, repairing the inconsistencies left by a restart part way through an allocation
+type ConsistencyReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+
+	// checked records the HardwareManagers that have been checked since startup
+	checked sync.Map
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;delete
+
+// Reconcile runs the consistency check of a HardwareManager, if it has not yet been checked since startup, and records
+// the results in the status
+func (r *ConsistencyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	if _, done := r.checked.Load(req.Name); done {
+		return
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch HardwareManager", slog.String("error", err.Error()))
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	if !utils.IsHardwareManagerValidationCompleted(hwmgr) {
+		// The adaptor is unable to reach the backend until the HardwareManager has been validated
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	r.Logger.InfoContext(ctx, "Running consistency check")
+	issues, err := r.check(ctx, hwmgr)
+	if err != nil {
+		r.Logger.InfoContext(ctx, "Consistency check failed", slog.String("error", err.Error()))
+		return utils.RequeueWithMediumInterval(), nil
+	}
+	utils.SortConsistencyIssues(issues)
+
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	hwmgr.Status.ConsistencyCheck = &pluginv1alpha1.ConsistencyCheckStatus{
+		CompletionTime: metav1.Now(),
+		Issues:         issues,
+	}
+	if err = r.Client.Status().Patch(ctx, hwmgr, patch); err != nil {
+		err = fmt.Errorf("failed to update consistency check results for hardware manager (%s): %w", hwmgr.Name, err)
+		return
+	}
+
+	r.checked.Store(hwmgr.Name, true)
+	r.Logger.InfoContext(ctx, "Consistency check completed", slog.Int("issues", len(issues)))
+
+	return
+}
+
+// check cross-checks the settled NodePools of the HardwareManager, returning the inconsistencies found
+func (r *ConsistencyReconciler) check(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]pluginv1alpha1.ConsistencyIssue, error) {
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := r.Client.List(ctx, nodepools); err != nil {
+		return nil, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	var issues []pluginv1alpha1.ConsistencyIssue
+	for i := range nodepools.Items {
+		nodepool := &nodepools.Items[i]
+		// The nodes of a split NodePool are checked with each of its child NodePools
+		if nodepool.Spec.HwMgrId != hwmgr.Name || utils.IsSplitNodePool(nodepool) || !utils.IsNodePoolSettled(nodepool) {
+			continue
+		}
+
+		found, err := r.checkNodePool(ctx, hwmgr, nodepool)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+
+	return issues, nil
+}
+
+// checkNodePool cross-checks the Nodes, bmc-secrets and allocation records of a NodePool, repairing the
+// inconsistencies that can be safely resolved
+func (r *ConsistencyReconciler) checkNodePool(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) ([]pluginv1alpha1.ConsistencyIssue, error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, r.Logger, r.Client, nodepool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := r.Client.List(ctx, secrets, client.InNamespace(nodepool.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list secrets in namespace %s: %w", nodepool.Namespace, err)
+	}
+
+	var issues []pluginv1alpha1.ConsistencyIssue
+	for _, name := range utils.FindOrphanedBMCSecrets(nodepool, nodelist.Items, secrets.Items) {
+		issue := pluginv1alpha1.ConsistencyIssue{
+			Kind:     utils.ConsistencyOrphanedBMCSecret,
+			NodePool: nodepool.Name,
+			Name:     name,
+		}
+
+		r.Logger.InfoContext(ctx, "Deleting bmc-secret with no Node",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("secret", name))
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nodepool.Namespace}}
+		if err := r.Client.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			issue.Message = fmt.Sprintf("failed to delete secret: %s", err.Error())
+		} else {
+			issue.Repaired = true
+			issue.Message = "deleted"
+		}
+		issues = append(issues, issue)
+	}
+
+	for _, name := range utils.FindNodesMissingBMCSecret(nodelist.Items, secrets.Items) {
+		issue := pluginv1alpha1.ConsistencyIssue{
+			Kind:     utils.ConsistencyMissingBMCSecret,
+			NodePool: nodepool.Name,
+			Name:     name,
+		}
+
+		r.Logger.InfoContext(ctx, "Restoring missing bmc-secret",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("nodename", name))
+		for j := range nodelist.Items {
+			if nodelist.Items[j].Name != name {
+				continue
+			}
+			if err := r.HwMgrAdaptor.RestoreNodeBMCSecret(ctx, nodepool, &nodelist.Items[j]); err != nil {
+				issue.Message = fmt.Sprintf("failed to restore bmc-secret: %s", err.Error())
+			} else {
+				issue.Repaired = true
+				issue.Message = "restored"
+			}
+		}
+		issues = append(issues, issue)
+	}
+
+	found, err := r.HwMgrAdaptor.CheckAllocations(ctx, hwmgr, nodepool, nodelist.Items)
+	if err != nil && !errors.Is(err, sdk.ErrNotSupported) {
+		return nil, fmt.Errorf("failed to check allocations of NodePool %s: %w", nodepool.Name, err)
+	}
+	issues = append(issues, found...)
+
+	return issues, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ConsistencyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("consistency").
+		For(&pluginv1alpha1.HardwareManager{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create consistency controller: %w", err)
+	}
+
+	return nil
+}
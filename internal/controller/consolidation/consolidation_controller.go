@@ -0,0 +1,223 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consolidation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// ConsolidationReconciler plans the consolidation of the allocations of a HardwareManager, and executes the plan once
+// it is approved
+type ConsolidationReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=consolidations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=consolidations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+
+// Reconcile advances the Consolidation through its plan, approve, and execute phases. The moves of an approved plan are
+// executed one per reconcile, recording the progress in the status.
+func (r *ConsolidationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	consolidation := &pluginv1alpha1.Consolidation{}
+	if err = r.Client.Get(ctx, req.NamespacedName, consolidation); err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch Consolidation", slog.String("error", err.Error()))
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("consolidation", consolidation.Name))
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", consolidation.Spec.HwMgrId))
+
+	phase := consolidation.Status.Phase
+	if phase == pluginv1alpha1.ConsolidationPhases.Completed || phase == pluginv1alpha1.ConsolidationPhases.Failed {
+		return
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, types.NamespacedName{Name: consolidation.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr); err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = r.updateStatus(ctx, consolidation, pluginv1alpha1.ConsolidationPhases.Failed,
+				"Unable to find HardwareManager instance: "+consolidation.Spec.HwMgrId)
+			return
+		}
+		err = fmt.Errorf("failed to get HardwareManager %s: %w", consolidation.Spec.HwMgrId, err)
+		return
+	}
+
+	switch phase {
+	case "":
+		return r.plan(ctx, hwmgr, consolidation)
+	case pluginv1alpha1.ConsolidationPhases.Planned:
+		if !consolidation.Spec.Approved {
+			// Execution starts when the plan is approved, which triggers a new reconcile
+			return
+		}
+		r.Logger.InfoContext(ctx, "Consolidation plan approved, starting execution")
+		fallthrough
+	default:
+		return r.execute(ctx, hwmgr, consolidation)
+	}
+}
+
+// plan computes the moves of the consolidation, with the plan held for approval
+func (r *ConsolidationReconciler) plan(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	consolidation *pluginv1alpha1.Consolidation) (ctrl.Result, error) {
+
+	moves, err := r.HwMgrAdaptor.PlanConsolidation(ctx, hwmgr, consolidation.Spec.ResourcePoolIds)
+	if err != nil {
+		if !errors.Is(err, sdk.ErrNotSupported) && !utils.IsInputError(err) {
+			r.Logger.InfoContext(ctx, "Consolidation planning failed", slog.String("error", err.Error()))
+			return utils.RequeueWithMediumInterval(), nil
+		}
+		return utils.DoNotRequeue(), r.updateStatus(ctx, consolidation, pluginv1alpha1.ConsolidationPhases.Failed,
+			"Unable to plan consolidation: "+err.Error())
+	}
+
+	consolidation.Status.Moves = moves
+	if len(moves) == 0 {
+		r.Logger.InfoContext(ctx, "No moves required to consolidate the resource pools")
+		return utils.DoNotRequeue(), r.updateStatus(ctx, consolidation, pluginv1alpha1.ConsolidationPhases.Completed,
+			"Resource pools are already consolidated")
+	}
+
+	r.Logger.InfoContext(ctx, "Planned consolidation", slog.Int("moves", len(moves)))
+	message := fmt.Sprintf("Planned %d moves, awaiting approval", len(moves))
+	if consolidation.Spec.Approved {
+		message = fmt.Sprintf("Planned %d moves", len(moves))
+	}
+	if err := r.updateStatus(ctx, consolidation, pluginv1alpha1.ConsolidationPhases.Planned, message); err != nil {
+		return utils.DoNotRequeue(), err
+	}
+
+	if consolidation.Spec.Approved {
+		// Approved on creation, so the plan is executed without waiting for a spec change
+		return utils.RequeueImmediately(), nil
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// execute runs the next pending move of the plan
+func (r *ConsolidationReconciler) execute(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	consolidation *pluginv1alpha1.Consolidation) (ctrl.Result, error) {
+
+	completed := 0
+	for i := range consolidation.Status.Moves {
+		move := &consolidation.Status.Moves[i]
+		if move.Completed {
+			completed++
+			continue
+		}
+
+		if err := r.HwMgrAdaptor.ExecuteConsolidationMove(ctx, hwmgr, move); err != nil {
+			r.Logger.InfoContext(ctx, "Consolidation move failed",
+				slog.String("node", move.Node),
+				slog.String("error", err.Error()))
+			return utils.DoNotRequeue(), r.updateStatus(ctx, consolidation, pluginv1alpha1.ConsolidationPhases.Failed,
+				fmt.Sprintf("Failed to move node %s from %s to %s: %s", move.Node, move.SourceNodeId, move.TargetNodeId, err.Error()))
+		}
+
+		move.Completed = true
+		completed++
+		if completed < len(consolidation.Status.Moves) {
+			if err := r.updateStatus(ctx, consolidation, pluginv1alpha1.ConsolidationPhases.Executing,
+				fmt.Sprintf("Completed %d of %d moves", completed, len(consolidation.Status.Moves))); err != nil {
+				return utils.DoNotRequeue(), err
+			}
+			return utils.RequeueImmediately(), nil
+		}
+	}
+
+	r.Logger.InfoContext(ctx, "Consolidation completed", slog.Int("moves", completed))
+	return utils.DoNotRequeue(), r.updateStatus(ctx, consolidation, pluginv1alpha1.ConsolidationPhases.Completed,
+		fmt.Sprintf("Completed %d moves", completed))
+}
+
+func (r *ConsolidationReconciler) updateStatus(
+	ctx context.Context,
+	consolidation *pluginv1alpha1.Consolidation,
+	phase pluginv1alpha1.ConsolidationPhase,
+	message string) error {
+
+	reason, status := pluginv1alpha1.ConditionReasons.InProgress, metav1.ConditionFalse
+	switch phase {
+	case pluginv1alpha1.ConsolidationPhases.Completed:
+		reason, status = pluginv1alpha1.ConditionReasons.Completed, metav1.ConditionTrue
+	case pluginv1alpha1.ConsolidationPhases.Failed:
+		reason = pluginv1alpha1.ConditionReasons.Failed
+	}
+
+	// The status is that of the generation being processed, should the Consolidation be read again on a conflict
+	generation, moves := consolidation.Generation, consolidation.Status.Moves
+	if err := utils.UpdateK8sCRStatus(ctx, r.Client, consolidation, func() {
+		consolidation.Status.ObservedGeneration = generation
+		consolidation.Status.Phase = phase
+		consolidation.Status.Moves = moves
+		utils.SetStatusCondition(&consolidation.Status.Conditions,
+			string(pluginv1alpha1.ConditionTypes.Consolidated),
+			string(reason),
+			status,
+			message)
+	}); err != nil {
+		return fmt.Errorf("failed to update status for Consolidation %s: %w", consolidation.Name, err)
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ConsolidationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&pluginv1alpha1.Consolidation{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create consolidation controller: %w", err)
+	}
+
+	return nil
+}
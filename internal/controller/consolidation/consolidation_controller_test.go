@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consolidation
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// consolidationClient serves a single Consolidation and HardwareManager, recording the status changes made to the
+// Consolidation
+type consolidationClient struct {
+	client.Client
+	consolidation *pluginv1alpha1.Consolidation
+	hwmgr         *pluginv1alpha1.HardwareManager
+	statusUpdates int
+	phase         pluginv1alpha1.ConsolidationPhase
+}
+
+func (c *consolidationClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	switch typed := obj.(type) {
+	case *pluginv1alpha1.Consolidation:
+		if key.Name == c.consolidation.Name && key.Namespace == c.consolidation.Namespace {
+			c.consolidation.DeepCopyInto(typed)
+			return nil
+		}
+	case *pluginv1alpha1.HardwareManager:
+		if key.Name == c.hwmgr.Name && key.Namespace == c.hwmgr.Namespace {
+			c.hwmgr.DeepCopyInto(typed)
+			return nil
+		}
+	}
+	return k8serrors.NewNotFound(pluginv1alpha1.GroupVersion.WithResource("").GroupResource(), key.Name)
+}
+
+func (c *consolidationClient) Status() client.SubResourceWriter {
+	return &consolidationStatusWriter{c: c}
+}
+
+type consolidationStatusWriter struct {
+	client.SubResourceWriter
+	c *consolidationClient
+}
+
+func (w *consolidationStatusWriter) Update(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+	w.c.statusUpdates++
+	w.c.phase = obj.(*pluginv1alpha1.Consolidation).Status.Phase
+	return nil
+}
+
+var _ = Describe("Consolidation reconcile", func() {
+	var (
+		ctx context.Context
+		c   *consolidationClient
+		r   *ConsolidationReconciler
+		req ctrl.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = &consolidationClient{
+			consolidation: &pluginv1alpha1.Consolidation{
+				ObjectMeta: metav1.ObjectMeta{Name: "consolidation", Namespace: "test"},
+				Spec: pluginv1alpha1.ConsolidationSpec{
+					HwMgrId:         "hwmgr",
+					ResourcePoolIds: []string{"pool1"},
+				},
+				Status: pluginv1alpha1.ConsolidationStatus{
+					Phase: pluginv1alpha1.ConsolidationPhases.Planned,
+					Moves: []pluginv1alpha1.ConsolidationMove{{
+						NodePool:       "np1",
+						Node:           "node5",
+						ResourcePoolId: "pool1",
+						SourceNodeId:   "node5",
+						TargetNodeId:   "node1",
+					}},
+				},
+			},
+			hwmgr: &pluginv1alpha1.HardwareManager{
+				ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "test"},
+				Spec:       pluginv1alpha1.HardwareManagerSpec{AdaptorID: pluginv1alpha1.SupportedAdaptors.Loopback},
+			},
+		}
+		// No adaptors are setup, so any move that is executed fails
+		r = &ConsolidationReconciler{
+			Client:       c,
+			Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Namespace:    "test",
+			HwMgrAdaptor: &adaptors.HwMgrAdaptorController{},
+		}
+		req = ctrl.Request{NamespacedName: types.NamespacedName{Name: "consolidation", Namespace: "test"}}
+	})
+
+	It("does not execute a plan that is not approved", func() {
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(utils.DoNotRequeue()))
+		Expect(c.statusUpdates).To(BeZero())
+	})
+
+	It("executes the plan once it is approved", func() {
+		c.consolidation.Spec.Approved = true
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(utils.DoNotRequeue()))
+		Expect(c.statusUpdates).To(Equal(1))
+		Expect(c.phase).To(Equal(pluginv1alpha1.ConsolidationPhases.Failed))
+	})
+})
@@ -0,0 +1,286 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// AdaptorInventory queries the adaptor of a hardware manager for the inventory that is not recorded in Node CRs
+type AdaptorInventory interface {
+	GetFreeNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, query utils.FreeNodeQuery) ([]utils.FreeNode, error)
+	GetCapacity(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*pluginv1alpha1.CapacityStatus, error)
+}
+
+// Inventory is a snapshot of the resource pools and resources of a hardware manager, rendered in the O2IMS
+// Infrastructure Inventory data model
+type Inventory struct {
+	HwMgrId       string                       `json:"hwMgrId"`
+	GeneratedAt   string                       `json:"generatedAt"`
+	ResourcePools []generated.ResourcePoolInfo `json:"resourcePools"`
+	Resources     []generated.ResourceInfo     `json:"resources"`
+	// Capacity is the node capacity of each resource pool, if reported by the adaptor
+	Capacity []pluginv1alpha1.ResourcePoolCapacity `json:"capacity"`
+}
+
+// GetResourcePool returns the resource pool with the specified ID, if present
+func (inv *Inventory) GetResourcePool(resourcePoolId string) *generated.ResourcePoolInfo {
+	for i := range inv.ResourcePools {
+		if inv.ResourcePools[i].ResourcePoolId == resourcePoolId {
+			return &inv.ResourcePools[i]
+		}
+	}
+	return nil
+}
+
+// GetResource returns the resource with the specified ID, if present
+func (inv *Inventory) GetResource(resourceId string) *generated.ResourceInfo {
+	for i := range inv.Resources {
+		if inv.Resources[i].ResourceId == resourceId {
+			return &inv.Resources[i]
+		}
+	}
+	return nil
+}
+
+// GetResourcePoolCapacity returns the capacity of the resource pool with the specified ID, if reported
+func (inv *Inventory) GetResourcePoolCapacity(resourcePoolId string) *pluginv1alpha1.ResourcePoolCapacity {
+	for i := range inv.Capacity {
+		if inv.Capacity[i].ResourcePoolId == resourcePoolId {
+			return &inv.Capacity[i]
+		}
+	}
+	return nil
+}
+
+// GetResourcePoolResources returns the resources belonging to the specified resource pool
+func (inv *Inventory) GetResourcePoolResources(resourcePoolId string) []generated.ResourceInfo {
+	resources := []generated.ResourceInfo{}
+	for _, resource := range inv.Resources {
+		if resource.ResourcePoolId == resourcePoolId {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// Collect builds the inventory for a hardware manager from its status and the Node CRs it has allocated, across its
+// watch namespaces, along with the free nodes and capacity of each resource pool as reported by its adaptor. The free
+// nodes are omitted if the adaptor does not support listing them.
+func Collect(
+	ctx context.Context,
+	c client.Client,
+	adaptor AdaptorInventory,
+	hwmgr *pluginv1alpha1.HardwareManager) (*Inventory, error) {
+
+	inv := &Inventory{
+		HwMgrId:       hwmgr.Name,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		ResourcePools: []generated.ResourcePoolInfo{},
+		Resources:     []generated.ResourceInfo{},
+		Capacity:      []pluginv1alpha1.ResourcePoolCapacity{},
+	}
+
+	addPool := func(poolId, siteId string) {
+		if poolId == "" || inv.GetResourcePool(poolId) != nil {
+			return
+		}
+		pool := generated.ResourcePoolInfo{
+			ResourcePoolId: poolId,
+			Name:           poolId,
+			Description:    fmt.Sprintf("Resource pool %s of hardware manager %s", poolId, hwmgr.Name),
+		}
+		if siteId != "" {
+			pool.SiteId = &siteId
+		}
+		inv.ResourcePools = append(inv.ResourcePools, pool)
+	}
+
+	for siteId, pools := range hwmgr.Status.ResourcePools {
+		for _, poolId := range pools {
+			addPool(poolId, siteId)
+		}
+	}
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	nodes := &hwmgmtv1alpha1.NodeList{}
+	for _, namespace := range utils.GetHardwareManagerWatchNamespaces(hwmgr) {
+		namespaced := &hwmgmtv1alpha1.NodePoolList{}
+		if err := c.List(ctx, namespaced, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list nodepools: %w", err)
+		}
+		nodepools.Items = append(nodepools.Items, namespaced.Items...)
+
+		namespacedNodes := &hwmgmtv1alpha1.NodeList{}
+		if err := c.List(ctx, namespacedNodes, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+		nodes.Items = append(nodes.Items, namespacedNodes.Items...)
+	}
+
+	// Map each nodegroup to its resource pool
+	groupPools := make(map[string]string)
+	for _, nodepool := range nodepools.Items {
+		if nodepool.Spec.HwMgrId != hwmgr.Name {
+			continue
+		}
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			groupPools[nodepool.Namespace+"/"+nodepool.Name+"/"+nodegroup.NodePoolData.Name] = nodegroup.NodePoolData.ResourcePoolId
+			addPool(nodegroup.NodePoolData.ResourcePoolId, nodepool.Spec.Site)
+		}
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.HwMgrId != hwmgr.Name {
+			continue
+		}
+		resource := nodeToResource(node, groupPools[node.Namespace+"/"+node.Spec.NodePool+"/"+node.Spec.GroupName])
+		if resource.AdminState == generated.ResourceInfoAdminStateUNLOCKED && utils.IsNodeBlocked(hwmgr, resource.ResourcePoolId, resource.ResourceId) {
+			resource.AdminState = generated.ResourceInfoAdminStateLOCKED
+		}
+		inv.Resources = append(inv.Resources, resource)
+	}
+
+	// Blocked nodes that are not allocated remain visible, locked
+	for _, blocked := range hwmgr.Spec.BlockedNodes {
+		if inv.GetResource(blocked.NodeId) == nil {
+			inv.Resources = append(inv.Resources, blockedNodeToResource(blocked))
+		}
+	}
+
+	capacity, err := adaptor.GetCapacity(ctx, hwmgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capacity: %w", err)
+	}
+	if capacity != nil {
+		for _, poolCapacity := range capacity.ResourcePools {
+			addPool(poolCapacity.ResourcePoolId, "")
+			inv.Capacity = append(inv.Capacity, poolCapacity)
+		}
+	}
+
+	for _, pool := range inv.ResourcePools {
+		freenodes, err := adaptor.GetFreeNodes(ctx, hwmgr, utils.FreeNodeQuery{ResourcePoolId: pool.ResourcePoolId})
+		if errors.Is(err, sdk.ErrNotSupported) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get free nodes of resource pool %s: %w", pool.ResourcePoolId, err)
+		}
+		for _, freenode := range freenodes {
+			if inv.GetResource(freenode.NodeId) == nil {
+				inv.Resources = append(inv.Resources, freeNodeToResource(freenode))
+			}
+		}
+	}
+
+	slices.SortFunc(inv.ResourcePools, func(a, b generated.ResourcePoolInfo) int {
+		return strings.Compare(a.ResourcePoolId, b.ResourcePoolId)
+	})
+	slices.SortFunc(inv.Resources, func(a, b generated.ResourceInfo) int {
+		return strings.Compare(a.ResourceId, b.ResourceId)
+	})
+	slices.SortFunc(inv.Capacity, func(a, b pluginv1alpha1.ResourcePoolCapacity) int {
+		return strings.Compare(a.ResourcePoolId, b.ResourcePoolId)
+	})
+
+	return inv, nil
+}
+
+// nodeToResource translates a Node CR into an O2IMS resource
+func nodeToResource(node *hwmgmtv1alpha1.Node, resourcePoolId string) generated.ResourceInfo {
+	resource := generated.ResourceInfo{
+		ResourceId:       node.Spec.HwMgrNodeId,
+		ResourcePoolId:   resourcePoolId,
+		Name:             node.Name,
+		Description:      fmt.Sprintf("Node %s allocated to nodepool %s, nodegroup %s", node.Name, node.Spec.NodePool, node.Spec.GroupName),
+		AdminState:       generated.ResourceInfoAdminStateUNLOCKED,
+		OperationalState: generated.ResourceInfoOperationalStateUNKNOWN,
+		UsageState:       generated.ACTIVE,
+	}
+
+	if node.GetDeletionTimestamp() != nil {
+		resource.AdminState = generated.ResourceInfoAdminStateSHUTTINGDOWN
+	}
+
+	if provisioned := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)); provisioned != nil {
+		switch {
+		case provisioned.Status == metav1.ConditionTrue:
+			resource.OperationalState = generated.ResourceInfoOperationalStateENABLED
+		case provisioned.Reason == string(hwmgmtv1alpha1.Failed):
+			resource.OperationalState = generated.ResourceInfoOperationalStateDISABLED
+		}
+	}
+
+	if utils.GetNodePowerState(node) == utils.PowerStateOff {
+		resource.OperationalState = generated.ResourceInfoOperationalStateDISABLED
+	}
+
+	if utils.GetJobId(node) != "" {
+		// An update is in progress on the node
+		resource.UsageState = generated.BUSY
+	}
+
+	return resource
+}
+
+// blockedNodeToResource translates an unallocated blocked node into a locked O2IMS resource
+func blockedNodeToResource(blocked pluginv1alpha1.BlockedNode) generated.ResourceInfo {
+	description := fmt.Sprintf("Node %s blocked from allocation", blocked.NodeId)
+	if blocked.Reason != "" {
+		description += ": " + blocked.Reason
+	}
+
+	return generated.ResourceInfo{
+		ResourceId:       blocked.NodeId,
+		ResourcePoolId:   blocked.ResourcePoolId,
+		Name:             blocked.NodeId,
+		Description:      description,
+		AdminState:       generated.ResourceInfoAdminStateLOCKED,
+		OperationalState: generated.ResourceInfoOperationalStateUNKNOWN,
+		UsageState:       generated.IDLE,
+	}
+}
+
+// freeNodeToResource translates a free node into an idle O2IMS resource
+func freeNodeToResource(freenode utils.FreeNode) generated.ResourceInfo {
+	return generated.ResourceInfo{
+		ResourceId:       freenode.NodeId,
+		ResourcePoolId:   freenode.ResourcePoolId,
+		Name:             freenode.NodeId,
+		Description:      fmt.Sprintf("Node %s free for allocation", freenode.NodeId),
+		AdminState:       generated.ResourceInfoAdminStateUNLOCKED,
+		OperationalState: generated.ResourceInfoOperationalStateUNKNOWN,
+		UsageState:       generated.IDLE,
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+const (
+	// ExportInventoryAnnotation requests an immediate export of the inventory when set or changed on a HardwareManager
+	ExportInventoryAnnotation = "hwmgr-plugin.oran.openshift.io/exportInventory"
+
+	// InventoryKey is the ConfigMap key holding the exported inventory
+	InventoryKey = "inventory.json"
+
+	defaultExportInterval = 10 * time.Minute
+)
+
+// InventoryConfigMapName returns the name of the ConfigMap holding the exported inventory for a hardware manager
+func InventoryConfigMapName(hwmgrName string) string {
+	return fmt.Sprintf("%s-inventory", hwmgrName)
+}
+
+// InventoryExportReconciler periodically exports the inventory of HardwareManagers configured for inventory export
+type InventoryExportReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;patch;watch
+
+// Reconcile exports the inventory of a HardwareManager to its inventory ConfigMap
+func (r *InventoryExportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch HardwareManager", slog.String("error", err.Error()))
+		return
+	}
+
+	if hwmgr.Spec.InventoryExport == nil {
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	interval := defaultExportInterval
+	if hwmgr.Spec.InventoryExport.Interval != nil {
+		interval = hwmgr.Spec.InventoryExport.Interval.Duration
+	}
+
+	if err = r.export(ctx, hwmgr); err != nil {
+		r.Logger.InfoContext(ctx, "Inventory export failed", slog.String("error", err.Error()))
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	return utils.RequeueWithCustomInterval(interval), nil
+}
+
+func (r *InventoryExportReconciler) export(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) error {
+	inv, err := Collect(ctx, r.Client, r.HwMgrAdaptor, hwmgr)
+	if err != nil {
+		return fmt.Errorf("failed to collect inventory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal inventory: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      InventoryConfigMapName(hwmgr.Name),
+			Namespace: r.Namespace,
+		},
+		Data: map[string]string{
+			InventoryKey: string(data),
+		},
+	}
+
+	if err := utils.ApplyK8sCR(ctx, r.Client, cm, hwmgr); err != nil {
+		return fmt.Errorf("failed to update inventory configmap: %w", err)
+	}
+
+	r.Logger.InfoContext(ctx, "Exported inventory",
+		slog.Int("resourcePools", len(inv.ResourcePools)),
+		slog.Int("resources", len(inv.Resources)))
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *InventoryExportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("inventory").
+		For(&pluginv1alpha1.HardwareManager{}).
+		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create inventory controller: %w", err)
+	}
+
+	return nil
+}
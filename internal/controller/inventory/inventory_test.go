@@ -0,0 +1,208 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// inventoryClient lists a fixed set of NodePools and Nodes, honouring the namespace of the list options
+type inventoryClient struct {
+	client.Client
+	nodepools []hwmgmtv1alpha1.NodePool
+	nodes     []hwmgmtv1alpha1.Node
+}
+
+func (c *inventoryClient) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	options := &client.ListOptions{}
+	options.ApplyOptions(opts)
+
+	switch typed := list.(type) {
+	case *hwmgmtv1alpha1.NodePoolList:
+		for i := range c.nodepools {
+			if c.nodepools[i].Namespace == options.Namespace {
+				typed.Items = append(typed.Items, *c.nodepools[i].DeepCopy())
+			}
+		}
+	case *hwmgmtv1alpha1.NodeList:
+		for i := range c.nodes {
+			if c.nodes[i].Namespace == options.Namespace {
+				typed.Items = append(typed.Items, *c.nodes[i].DeepCopy())
+			}
+		}
+	}
+	return nil
+}
+
+// inventoryAdaptor reports the free nodes and capacity of each resource pool
+type inventoryAdaptor struct {
+	freenodes    map[string][]utils.FreeNode
+	freenodesErr error
+	capacity     *pluginv1alpha1.CapacityStatus
+}
+
+func (a *inventoryAdaptor) GetFreeNodes(
+	_ context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	query utils.FreeNodeQuery) ([]utils.FreeNode, error) {
+	if a.freenodesErr != nil {
+		return nil, a.freenodesErr
+	}
+	return a.freenodes[query.ResourcePoolId], nil
+}
+
+func (a *inventoryAdaptor) GetCapacity(_ context.Context, _ *pluginv1alpha1.HardwareManager) (*pluginv1alpha1.CapacityStatus, error) {
+	return a.capacity, nil
+}
+
+var _ = Describe("Inventory collection", func() {
+	var (
+		ctx     context.Context
+		c       *inventoryClient
+		adaptor *inventoryAdaptor
+		hwmgr   *pluginv1alpha1.HardwareManager
+	)
+
+	newNodePool := func(namespace string) hwmgmtv1alpha1.NodePool {
+		return hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: namespace},
+			Spec: hwmgmtv1alpha1.NodePoolSpec{
+				HwMgrId: "hwmgr",
+				NodeGroup: []hwmgmtv1alpha1.NodeGroup{{
+					NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "master", ResourcePoolId: "pool1"},
+					Size:         1,
+				}},
+			},
+		}
+	}
+
+	newNode := func(namespace, nodeId string) hwmgmtv1alpha1.Node {
+		return hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeId, Namespace: namespace},
+			Spec: hwmgmtv1alpha1.NodeSpec{
+				NodePool:    "np1",
+				GroupName:   "master",
+				HwMgrId:     "hwmgr",
+				HwMgrNodeId: nodeId,
+			},
+		}
+	}
+
+	resourceStates := func(inv *Inventory) map[string]generated.ResourceInfoUsageState {
+		states := make(map[string]generated.ResourceInfoUsageState)
+		for _, resource := range inv.Resources {
+			states[resource.ResourceId] = resource.UsageState
+		}
+		return states
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = &inventoryClient{
+			nodepools: []hwmgmtv1alpha1.NodePool{newNodePool("tenant-a"), newNodePool("tenant-b")},
+			nodes:     []hwmgmtv1alpha1.Node{newNode("tenant-a", "node1"), newNode("tenant-b", "node9")},
+		}
+		adaptor = &inventoryAdaptor{
+			freenodes: map[string][]utils.FreeNode{
+				"pool1": {{NodeId: "node2", ResourcePoolId: "pool1"}},
+				"pool2": {{NodeId: "node4", ResourcePoolId: "pool2"}},
+			},
+			capacity: &pluginv1alpha1.CapacityStatus{
+				TotalNodes:   4,
+				FreeNodes:    2,
+				BlockedNodes: 1,
+				ResourcePools: []pluginv1alpha1.ResourcePoolCapacity{
+					{ResourcePoolId: "pool2", TotalNodes: 1, FreeNodes: 1},
+					{ResourcePoolId: "pool1", TotalNodes: 3, FreeNodes: 1, BlockedNodes: 1},
+				},
+			},
+		}
+		hwmgr = &pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "plugin"},
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				WatchNamespaces: []string{"tenant-a"},
+				BlockedNodes:    []pluginv1alpha1.BlockedNode{{NodeId: "node3", ResourcePoolId: "pool1"}},
+			},
+			Status: pluginv1alpha1.HardwareManagerStatus{
+				ResourcePools: pluginv1alpha1.PerSiteResourcePoolList{"site1": {"pool1"}},
+			},
+		}
+	})
+
+	It("includes the allocated, blocked and free nodes, and the capacity of each resource pool", func() {
+		inv, err := Collect(ctx, c, adaptor, hwmgr)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(inv.HwMgrId).To(Equal("hwmgr"))
+		Expect(inv.ResourcePools).To(HaveLen(2))
+		Expect(inv.ResourcePools[0].ResourcePoolId).To(Equal("pool1"))
+		Expect(inv.ResourcePools[0].SiteId).To(HaveValue(Equal("site1")))
+		Expect(inv.ResourcePools[1].ResourcePoolId).To(Equal("pool2"))
+
+		// The node allocated to the NodePool outside the watch namespaces is not included
+		Expect(resourceStates(inv)).To(Equal(map[string]generated.ResourceInfoUsageState{
+			"node1": generated.ACTIVE,
+			"node2": generated.IDLE,
+			"node3": generated.IDLE,
+			"node4": generated.IDLE,
+		}))
+		Expect(inv.GetResource("node2").ResourcePoolId).To(Equal("pool1"))
+		Expect(inv.GetResource("node2").AdminState).To(Equal(generated.ResourceInfoAdminStateUNLOCKED))
+		Expect(inv.GetResource("node3").AdminState).To(Equal(generated.ResourceInfoAdminStateLOCKED))
+		Expect(inv.GetResourcePoolResources("pool2")).To(HaveLen(1))
+
+		Expect(inv.Capacity).To(Equal([]pluginv1alpha1.ResourcePoolCapacity{
+			{ResourcePoolId: "pool1", TotalNodes: 3, FreeNodes: 1, BlockedNodes: 1},
+			{ResourcePoolId: "pool2", TotalNodes: 1, FreeNodes: 1},
+		}))
+		Expect(inv.GetResourcePoolCapacity("pool2").FreeNodes).To(Equal(1))
+	})
+
+	It("omits the free nodes and capacity when not supported by the adaptor", func() {
+		adaptor.freenodesErr = sdk.ErrNotSupported
+		adaptor.capacity = nil
+
+		inv, err := Collect(ctx, c, adaptor, hwmgr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inv.ResourcePools).To(HaveLen(1))
+		Expect(resourceStates(inv)).To(Equal(map[string]generated.ResourceInfoUsageState{
+			"node1": generated.ACTIVE,
+			"node3": generated.IDLE,
+		}))
+		Expect(inv.Capacity).To(BeEmpty())
+		Expect(inv.GetResourcePoolCapacity("pool1")).To(BeNil())
+	})
+
+	It("fails when the free nodes cannot be listed", func() {
+		adaptor.freenodesErr = errors.New("backend unavailable")
+
+		_, err := Collect(ctx, c, adaptor, hwmgr)
+		Expect(err).To(MatchError(ContainSubstring("backend unavailable")))
+	})
+})
@@ -0,0 +1,190 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepooltemplate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodePoolTemplateReconciler instantiates the NodePools of a NodePoolTemplate
+type NodePoolTemplateReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Logger *slog.Logger
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=nodepooltemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=nodepooltemplates/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch;create
+
+// Reconcile creates the missing NodePool instances of the NodePoolTemplate, recording the result for each instance in
+// the status. Existing NodePools are left as they are, so changes to the template only apply to new instances.
+func (r *NodePoolTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	tmpl := &pluginv1alpha1.NodePoolTemplate{}
+	if err = r.Client.Get(ctx, req.NamespacedName, tmpl); err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch NodePoolTemplate", slog.String("error", err.Error()))
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("nodepooltemplate", tmpl.Name))
+
+	var source *hwmgmtv1alpha1.NodePool
+	if tmpl.Spec.NodePoolRef != "" {
+		source = &hwmgmtv1alpha1.NodePool{}
+		if err = r.Client.Get(ctx, types.NamespacedName{Name: tmpl.Spec.NodePoolRef, Namespace: tmpl.Namespace}, source); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				err = fmt.Errorf("failed to get NodePool %s: %w", tmpl.Spec.NodePoolRef, err)
+				return
+			}
+			// The source may not have been created yet
+			r.Logger.InfoContext(ctx, "Unable to find source NodePool", slog.String("nodePoolRef", tmpl.Spec.NodePoolRef))
+			tmpl.Status.Instances = nil
+			return utils.RequeueWithMediumInterval(), r.updateStatus(ctx, tmpl, metav1.ConditionFalse,
+				pluginv1alpha1.ConditionReasons.Failed, "Unable to find source NodePool: "+tmpl.Spec.NodePoolRef)
+		}
+	}
+
+	retry := false
+	failed := 0
+	tmpl.Status.Instances = nil
+	for i := range tmpl.Spec.Instances {
+		instance := &tmpl.Spec.Instances[i]
+		state, instErr := r.instantiate(ctx, tmpl, source, instance)
+		status := pluginv1alpha1.NodePoolInstanceStatus{Name: instance.Name, State: state}
+		if instErr != nil {
+			r.Logger.InfoContext(ctx, "Failed to instantiate NodePool",
+				slog.String("nodepool", instance.Name),
+				slog.String("error", instErr.Error()))
+			status.Message = instErr.Error()
+			failed++
+			// Invalid instances are retried once the template is updated
+			retry = retry || !utils.IsInputError(instErr)
+		}
+		tmpl.Status.Instances = append(tmpl.Status.Instances, status)
+	}
+
+	if retry {
+		result = utils.RequeueWithMediumInterval()
+	}
+
+	if failed > 0 {
+		err = r.updateStatus(ctx, tmpl, metav1.ConditionFalse, pluginv1alpha1.ConditionReasons.Failed,
+			fmt.Sprintf("Failed to instantiate %d of %d NodePools", failed, len(tmpl.Spec.Instances)))
+		return
+	}
+
+	err = r.updateStatus(ctx, tmpl, metav1.ConditionTrue, pluginv1alpha1.ConditionReasons.Completed,
+		fmt.Sprintf("Instantiated %d NodePools", len(tmpl.Spec.Instances)))
+	return
+}
+
+// instantiate creates the NodePool of an instance, if it does not already exist
+func (r *NodePoolTemplateReconciler) instantiate(
+	ctx context.Context,
+	tmpl *pluginv1alpha1.NodePoolTemplate,
+	source *hwmgmtv1alpha1.NodePool,
+	instance *pluginv1alpha1.NodePoolInstance) (pluginv1alpha1.NodePoolInstanceState, error) {
+
+	existing := &hwmgmtv1alpha1.NodePool{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: instance.Name, Namespace: tmpl.Namespace}, existing)
+	if err == nil {
+		if existing.Labels[utils.NodePoolTemplateLabel] == tmpl.Name {
+			return pluginv1alpha1.NodePoolInstanceStates.Created, nil
+		}
+		// A NodePool that was not instantiated from this template is never taken over
+		return pluginv1alpha1.NodePoolInstanceStates.Exists, nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		return pluginv1alpha1.NodePoolInstanceStates.Failed, fmt.Errorf("failed to get NodePool %s: %w", instance.Name, err)
+	}
+
+	nodepool, err := utils.InstantiateNodePool(tmpl, source, instance)
+	if err != nil {
+		return pluginv1alpha1.NodePoolInstanceStates.Failed, err
+	}
+
+	if err := r.Client.Create(ctx, nodepool); err != nil {
+		if k8serrors.IsInvalid(err) || k8serrors.IsForbidden(err) {
+			// Rejected by validation or the NodePool webhook, which won't change until the template is updated
+			return pluginv1alpha1.NodePoolInstanceStates.Failed, utils.NewInputError("%s", err.Error())
+		}
+		return pluginv1alpha1.NodePoolInstanceStates.Failed, fmt.Errorf("failed to create NodePool %s: %w", instance.Name, err)
+	}
+
+	r.Logger.InfoContext(ctx, "Instantiated NodePool",
+		slog.String("nodepool", nodepool.Name),
+		slog.String("cloudID", nodepool.Spec.CloudID))
+	return pluginv1alpha1.NodePoolInstanceStates.Created, nil
+}
+
+func (r *NodePoolTemplateReconciler) updateStatus(
+	ctx context.Context,
+	tmpl *pluginv1alpha1.NodePoolTemplate,
+	status metav1.ConditionStatus,
+	reason pluginv1alpha1.ConditionReason,
+	message string) error {
+
+	// The status is that of the generation being processed, should the NodePoolTemplate be read again on a conflict
+	generation, instances := tmpl.Generation, tmpl.Status.Instances
+	if err := utils.UpdateK8sCRStatus(ctx, r.Client, tmpl, func() {
+		tmpl.Status.ObservedGeneration = generation
+		tmpl.Status.Instances = instances
+		utils.SetStatusCondition(&tmpl.Status.Conditions,
+			string(pluginv1alpha1.ConditionTypes.Instantiated),
+			string(reason),
+			status,
+			message)
+	}); err != nil {
+		return fmt.Errorf("failed to update status for NodePoolTemplate %s: %w", tmpl.Name, err)
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodePoolTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&pluginv1alpha1.NodePoolTemplate{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create nodepooltemplate controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,458 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodeReconciler reconciles a Node object, correcting drift from the desired state of the Node and its bmc-secret
+// that results from manual edits, rather than waiting for the NodePool to be reconciled
+type NodeReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+	Recorder     record.EventRecorder
+}
+
+// Reconcile ensures the Node has its finalizer, the plugin-managed status fields are intact and the bmc-secret exists.
+// A Node annotated for decommission is decommissioned through the adaptor and deleted, a Node annotated for release is
+// released through the adaptor and deleted, and the desired power state of a provisioned Node is applied through the
+// adaptor.
+func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	ctx = logging.AppendCtx(ctx, slog.String("nodename", req.Name))
+
+	defer func() {
+		if errors.Is(err, utils.ErrReadOnly) {
+			// The status continues to be reported, with the change made once the plugin leaves read-only mode
+			r.Logger.InfoContext(ctx, "Node change blocked", slog.String("reason", err.Error()))
+			result, err = utils.RequeueWithLongInterval(), nil
+		}
+	}()
+
+	node := &hwmgmtv1alpha1.Node{}
+	if err = r.Client.Get(ctx, req.NamespacedName, node); err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch Node", slog.String("error", err.Error()))
+		return
+	}
+
+	if node.GetDeletionTimestamp() != nil {
+		if controllerutil.ContainsFinalizer(node, utils.NodeFinalizer) {
+			return r.handleNodeDeletion(ctx, node)
+		}
+		return
+	}
+
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	if err = r.Client.Get(ctx, types.NamespacedName{Name: node.Spec.NodePool, Namespace: node.Namespace}, nodepool); err != nil {
+		if k8serrors.IsNotFound(err) {
+			// The node is not managed through a NodePool, or is being garbage collected with it
+			err = nil
+			return
+		}
+		err = fmt.Errorf("failed to get NodePool %s: %w", node.Spec.NodePool, err)
+		return
+	}
+	if nodepool.GetDeletionTimestamp() != nil {
+		// The NodePool deletion takes care of the nodes
+		return
+	}
+
+	if !controllerutil.ContainsFinalizer(node, utils.NodeFinalizer) {
+		r.Logger.InfoContext(ctx, "Adding finalizer to Node")
+		patch := client.MergeFrom(node.DeepCopy())
+		controllerutil.AddFinalizer(node, utils.NodeFinalizer)
+		if err = r.Client.Patch(ctx, node, patch); err != nil {
+			return utils.RequeueImmediately(), fmt.Errorf("failed to add finalizer to node %s: %w", node.Name, err)
+		}
+	}
+
+	if mode, requested, modeErr := utils.GetNodeDecommissionMode(node); requested {
+		return r.handleNodeDecommission(ctx, nodepool, node, mode, modeErr)
+	}
+
+	if reason, message, requested, reasonErr := utils.GetNodeReleaseReason(node); requested {
+		return r.handleNodeRelease(ctx, nodepool, node, reason, message, reasonErr)
+	}
+
+	// The power state is not controlled until the node is provisioned, so as not to interfere with the provisioning
+	if state, requested, stateErr := utils.GetNodeDesiredPowerState(node); requested &&
+		meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+		if result, err = r.reconcileNodePowerState(ctx, nodepool, node, state, stateErr); err != nil {
+			return
+		}
+	}
+
+	// BMC events are subscribed once the node is provisioned, so as not to interfere with the provisioning
+	if meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+		if subscribeErr := r.reconcileBMCEventSubscription(ctx, nodepool, node); subscribeErr != nil {
+			r.Logger.InfoContext(ctx, "Unable to subscribe to BMC events", slog.String("error", subscribeErr.Error()))
+			result = utils.RequeueWithMediumInterval()
+		}
+	}
+
+	corrections := utils.ApplyNodeStatusDrift(node)
+
+	// The bmc-secret is created before the BMC details are published in the Node status
+	var uncorrected error
+	if node.Status.BMC != nil {
+		restored, err := r.ensureBMCSecret(ctx, nodepool, node)
+		if err != nil {
+			uncorrected = err
+		} else if restored {
+			corrections = append(corrections, "bmc-secret restored")
+		}
+	}
+
+	if len(corrections) == 0 && uncorrected == nil {
+		return
+	}
+
+	r.Logger.InfoContext(ctx, "Corrected Node drift", slog.Any("corrections", corrections))
+	if err = utils.UpdateK8sCRStatus(ctx, r.Client, node, func() {
+		utils.ApplyNodeStatusDrift(node)
+		utils.SetNodeDriftCondition(node, corrections, uncorrected)
+	}); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+	}
+
+	if uncorrected != nil {
+		r.Logger.InfoContext(ctx, "Unable to correct Node drift", slog.String("error", uncorrected.Error()))
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	return
+}
+
+// ensureBMCSecret re-creates the bmc-secret for the node through the adaptor if it has been deleted, returning true
+// if the secret was restored
+func (r *NodeReconciler) ensureBMCSecret(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) (bool, error) {
+
+	secret := &corev1.Secret{}
+	name := types.NamespacedName{Name: utils.BMCSecretName(node.Name), Namespace: node.Namespace}
+	if err := r.Client.Get(ctx, name, secret); err == nil {
+		return false, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get bmc-secret %s: %w", name.Name, err)
+	}
+
+	r.Logger.InfoContext(ctx, "Restoring deleted bmc-secret", slog.String("secret", name.Name))
+	if err := r.HwMgrAdaptor.RestoreNodeBMCSecret(ctx, nodepool, node); err != nil {
+		return false, fmt.Errorf("failed to restore bmc-secret %s: %w", name.Name, err)
+	}
+
+	return true, nil
+}
+
+// handleNodeDecommission powers down, optionally wipes, and releases the node through the adaptor, saves the
+// decommission report to a configmap, then deletes the Node CR. The report is saved before the node is deleted, so an
+// existing report indicates the backend decommission has completed.
+func (r *NodeReconciler) handleNodeDecommission(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	mode utils.DecommissionMode,
+	modeErr error) (ctrl.Result, error) {
+
+	if modeErr != nil {
+		r.Logger.InfoContext(ctx, "Rejecting Node decommission request", slog.String("error", modeErr.Error()))
+		return r.setDecommissionFailed(ctx, node, modeErr.Error())
+	}
+
+	reportName := types.NamespacedName{Name: utils.DecommissionReportName(node.Name), Namespace: r.Namespace}
+	if err := r.Client.Get(ctx, reportName, &corev1.ConfigMap{}); err == nil {
+		return r.deleteDecommissionedNode(ctx, node)
+	} else if !k8serrors.IsNotFound(err) {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get decommission report %s: %w", reportName.Name, err)
+	}
+
+	report := &utils.DecommissionReport{
+		NodeName:    node.Name,
+		NodePool:    nodepool.Name,
+		CloudID:     nodepool.Spec.CloudID,
+		HwMgrId:     nodepool.Spec.HwMgrId,
+		HwMgrNodeId: node.Spec.HwMgrNodeId,
+		Mode:        mode,
+		StartTime:   metav1.Now(),
+	}
+
+	r.Logger.InfoContext(ctx, "Decommissioning Node", slog.String("mode", string(mode)))
+	if err := r.HwMgrAdaptor.DecommissionNode(ctx, nodepool, node, report); err != nil {
+		if !errors.Is(err, sdk.ErrNotSupported) && !utils.IsInputError(err) {
+			r.Logger.InfoContext(ctx, "Node decommission failed", slog.String("error", err.Error()))
+			return utils.RequeueWithMediumInterval(), nil
+		}
+		return r.setDecommissionFailed(ctx, node, "Unable to decommission node: "+err.Error())
+	}
+	report.CompletionTime = metav1.Now()
+
+	cm, err := utils.NewDecommissionReportConfigMap(r.Namespace, report)
+	if err != nil {
+		return utils.DoNotRequeue(), err
+	}
+	if err := r.Client.Create(ctx, cm); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to create decommission report %s: %w", cm.Name, err)
+	}
+
+	r.Logger.InfoContext(ctx, "Saved Node decommission report", slog.String("configmap", cm.Name))
+
+	return r.deleteDecommissionedNode(ctx, node)
+}
+
+// reconcileNodePowerState applies the desired power state of the node through the adaptor, if it differs from the
+// power state last reported in the Node status. The outcome is reported in the PowerControl condition, with transient
+// failures retried.
+func (r *NodeReconciler) reconcileNodePowerState(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	state utils.PowerState,
+	stateErr error) (ctrl.Result, error) {
+
+	result := utils.DoNotRequeue()
+	err := stateErr
+	if err == nil && utils.GetNodePowerState(node) != state {
+		r.Logger.InfoContext(ctx, "Applying Node power state", slog.String("powerState", string(state)))
+		err = r.HwMgrAdaptor.SetNodePowerState(ctx, nodepool, node, state)
+	}
+	if err != nil {
+		r.Logger.InfoContext(ctx, "Unable to apply Node power state", slog.String("error", err.Error()))
+		if !errors.Is(err, sdk.ErrNotSupported) && !utils.IsInputError(err) {
+			result = utils.RequeueWithMediumInterval()
+		}
+	}
+
+	if !utils.SetNodePowerControlStatus(node, state, err) {
+		return result, nil
+	}
+	if updateErr := utils.UpdateK8sCRStatus(ctx, r.Client, node, func() {
+		utils.SetNodePowerControlStatus(node, state, err)
+	}); updateErr != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for node %s: %w", node.Name, updateErr)
+	}
+
+	return result, nil
+}
+
+// reconcileBMCEventSubscription subscribes to the events of the BMC of the node if BMC events are enabled for its
+// hardware manager, and removes an existing subscription if they have since been disabled
+func (r *NodeReconciler) reconcileBMCEventSubscription(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) error {
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: nodepool.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr); err != nil {
+		return fmt.Errorf("failed to get HardwareManager %s: %w", nodepool.Spec.HwMgrId, err)
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	var changed bool
+	var err error
+	if subscriber := sdk.NewBMCEventSubscriber(r.Client, hwmgr); subscriber != nil {
+		changed, err = subscriber.Subscribe(ctx, node)
+	} else {
+		changed, err = sdk.UnsubscribeBMCEvents(ctx, r.Client, node)
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	r.Logger.InfoContext(ctx, "Updated BMC event subscription",
+		slog.String("subscription", node.GetAnnotations()[utils.BMCEventSubscriptionAnnotation]))
+	if err := r.Client.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to patch node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// setDecommissionFailed reports a decommission failure on the node. The decommission is retried on the next update
+// to the Node.
+func (r *NodeReconciler) setDecommissionFailed(ctx context.Context, node *hwmgmtv1alpha1.Node, message string) (ctrl.Result, error) {
+	if err := utils.UpdateK8sCRStatus(ctx, r.Client, node, func() {
+		utils.SetNodeDecommissionFailed(node, message)
+	}); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// handleNodeRelease releases the node from the NodePool allocation through the adaptor, records the release with its
+// reason code in the NodePool status and an event, then deletes the Node CR. The release is recorded before the node is
+// deleted, so that an interrupted release is recorded again when retried.
+func (r *NodeReconciler) handleNodeRelease(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	reason utils.ReleaseReason,
+	message string,
+	reasonErr error) (ctrl.Result, error) {
+
+	if reasonErr != nil {
+		r.Logger.InfoContext(ctx, "Rejecting Node release request", slog.String("error", reasonErr.Error()))
+		return r.setReleaseFailed(ctx, node, reasonErr.Error())
+	}
+
+	r.Logger.InfoContext(ctx, "Releasing Node",
+		slog.String("reason", string(reason)),
+		slog.String("message", message))
+	if err := r.HwMgrAdaptor.ReleaseNode(ctx, nodepool, node); err != nil {
+		if !errors.Is(err, sdk.ErrNotSupported) && !utils.IsInputError(err) {
+			r.Logger.InfoContext(ctx, "Node release failed", slog.String("error", err.Error()))
+			return utils.RequeueWithMediumInterval(), nil
+		}
+		return r.setReleaseFailed(ctx, node, "Unable to release node: "+err.Error())
+	}
+
+	if err := utils.NewNodePoolStatusBuilder(nodepool).
+		WithNodeReleased(node, reason, message).
+		Update(ctx, r.Client); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(nodepool, corev1.EventTypeNormal, string(utils.NodePoolNodeReleased),
+			utils.FormatNodeRelease(node, reason, message))
+	}
+
+	r.Logger.InfoContext(ctx, "Deleting released Node")
+	if err := r.Client.Delete(ctx, node); client.IgnoreNotFound(err) != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete released node %s: %w", node.Name, err)
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// setReleaseFailed reports a release failure on the node. The release is retried on the next update to the Node.
+func (r *NodeReconciler) setReleaseFailed(ctx context.Context, node *hwmgmtv1alpha1.Node, message string) (ctrl.Result, error) {
+	if err := utils.UpdateK8sCRStatus(ctx, r.Client, node, func() {
+		utils.SetNodeReleaseFailed(node, message)
+	}); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// deleteDecommissionedNode deletes the Node CR of a decommissioned node, with its bmc-secret removed by the finalizer
+func (r *NodeReconciler) deleteDecommissionedNode(ctx context.Context, node *hwmgmtv1alpha1.Node) (ctrl.Result, error) {
+	r.Logger.InfoContext(ctx, "Deleting decommissioned Node")
+	if err := r.Client.Delete(ctx, node); client.IgnoreNotFound(err) != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete decommissioned node %s: %w", node.Name, err)
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// handleNodeDeletion removes the BMC event subscription and the bmc-secret of a deleted node, which would otherwise
+// be retained until the NodePool is deleted, then removes the finalizer. A BMC that cannot be reached does not hold up
+// the deletion, as the events of a stale subscription are rejected by the listener. The active alarms of the node are
+// cleared, on a best-effort basis.
+func (r *NodeReconciler) handleNodeDeletion(ctx context.Context, node *hwmgmtv1alpha1.Node) (ctrl.Result, error) {
+	r.Logger.InfoContext(ctx, "Node is being deleted")
+
+	if _, err := sdk.UnsubscribeBMCEvents(ctx, r.Client, node.DeepCopy()); err != nil {
+		r.Logger.InfoContext(ctx, "Unable to remove BMC event subscription", slog.String("error", err.Error()))
+	}
+
+	if records := utils.ClearNodeHardwareAlarms(node.DeepCopy(), time.Now()); len(records) > 0 {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr)
+		if err == nil {
+			err = sdk.NotifyAlarms(ctx, r.Client, hwmgr, records)
+		}
+		if err != nil {
+			r.Logger.InfoContext(ctx, "Unable to clear hardware alarms", slog.String("error", err.Error()))
+		}
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.BMCSecretName(node.Name),
+			Namespace: node.Namespace,
+		},
+	}
+	if err := r.Client.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete bmc-secret for node %s: %w", node.Name, err)
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	controllerutil.RemoveFinalizer(node, utils.NodeFinalizer)
+	if err := r.Client.Patch(ctx, node, patch); err != nil {
+		return utils.RequeueImmediately(), fmt.Errorf("failed to remove finalizer from node %s: %w", node.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// mapBMCSecretToNode enqueues the node for a bmc-secret, so that a deleted secret is restored. The bmc-secret is in
+// the namespace of the node.
+func (r *NodeReconciler) mapBMCSecretToNode(ctx context.Context, obj client.Object) []reconcile.Request {
+	nodename := utils.BMCSecretNodeName(obj.GetName())
+	if nodename == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: nodename, Namespace: obj.GetNamespace()}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&hwmgmtv1alpha1.Node{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.mapBMCSecretToNode)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	return nil
+}
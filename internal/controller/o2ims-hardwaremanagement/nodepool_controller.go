@@ -18,18 +18,23 @@ package o2imshardwaremanagement
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
-	"k8s.io/apimachinery/pkg/api/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 )
@@ -58,7 +63,7 @@ func (r *NodePoolReconciler) SetupIndexer(ctx context.Context) error {
 	return nil
 }
 
-//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools/finalizers,verbs=update
 //+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;create;list;watch;update;patch;delete
@@ -74,10 +79,19 @@ func (r *NodePoolReconciler) SetupIndexer(ctx context.Context) error {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	_ = log.FromContext(ctx)
+	ctx = logging.NewReconcileContext(ctx)
 	result = utils.DoNotRequeue()
 
 	ctx = logging.AppendCtx(ctx, slog.String("nodepool", req.Name))
 
+	defer func() {
+		if errors.Is(err, utils.ErrReadOnly) {
+			// The status continues to be reported, with the change made once the plugin leaves read-only mode
+			r.Logger.InfoContext(ctx, "NodePool change blocked", slog.String("reason", err.Error()))
+			result, err = utils.RequeueWithLongInterval(), nil
+		}
+	}()
+
 	if !r.indexerEnabled {
 		if err = r.SetupIndexer(ctx); err != nil {
 			err = fmt.Errorf("failed to setup indexer: %w", err)
@@ -90,7 +104,7 @@ func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	// Fetch the nodepool:
 	nodepool := &hwmgmtv1alpha1.NodePool{}
 	if err = r.Client.Get(ctx, req.NamespacedName, nodepool); err != nil {
-		if errors.IsNotFound(err) {
+		if k8serrors.IsNotFound(err) {
 			// The NodePool has likely been deleted
 			err = nil
 			return
@@ -110,8 +124,17 @@ func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 	if nodepool.GetDeletionTimestamp() != nil {
 		// Handle deletion
 		r.Logger.InfoContext(ctx, "Nodepool is being deleted")
+		if utils.IsSplitNodePool(nodepool) && controllerutil.ContainsFinalizer(nodepool, utils.NodepoolFinalizer) {
+			return r.handleSplitNodePoolDeletion(ctx, nodepool)
+		}
 		if controllerutil.ContainsFinalizer(nodepool, utils.NodepoolFinalizer) {
 			if err := r.HwMgrAdaptor.HandleNodePoolDeletion(ctx, nodepool); err != nil {
+				if errors.Is(err, sdk.ErrReleaseDeferred) {
+					// The release is retried, retaining the finalizer so the backend bookkeeping stays consistent
+					r.Logger.InfoContext(ctx, "NodePool release deferred", slog.String("reason", err.Error()))
+					return utils.RequeueWithShortInterval(), nil
+				}
+
 				// Log the failure and continue, to remove the finalizer and allow the deletion
 				r.Logger.InfoContext(ctx, "Failed HandleNodePoolDeletion", slog.String("error", err.Error()))
 			}
@@ -125,20 +148,51 @@ func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 		return utils.DoNotRequeue(), nil
 	}
 
-	// Hand off the CR to the adaptor
-	result, err = r.HwMgrAdaptor.HandleNodePool(ctx, nodepool)
+	// Notify the callback URL of the provisioned state recorded by a previous reconcile, as the status update
+	// triggers a new reconcile
+	callbackErr := sdk.SendNodePoolCallback(ctx, r.Client, r.Namespace, nodepool)
+	switch {
+	case errors.Is(callbackErr, sdk.ErrCallbackSigningNotConfigured):
+		r.Logger.InfoContext(ctx, "Skipping NodePool callback", slog.String("reason", callbackErr.Error()))
+		callbackErr = nil
+	case callbackErr != nil:
+		r.Logger.InfoContext(ctx, "Failed to deliver NodePool callback", slog.String("error", callbackErr.Error()))
+	}
+
+	if utils.IsSplitNodePool(nodepool) {
+		// The nodegroups are handed off to the adaptors of their HardwareManagers through the split NodePools
+		result, err = r.reconcileSplitNodePool(ctx, nodepool)
+	} else {
+		// Hand off the CR to the adaptor
+		result, err = r.HwMgrAdaptor.HandleNodePool(ctx, nodepool)
+		if err != nil {
+			err = fmt.Errorf("failed HandleNodePool: %w", err)
+		}
+	}
 	if err != nil {
-		err = fmt.Errorf("failed HandleNodePool: %w", err)
 		return
 	}
 
+	// An undelivered callback is retried on a requeue
+	if callbackErr != nil && !result.Requeue &&
+		(result.RequeueAfter == 0 || result.RequeueAfter > utils.RequeueWithMediumInterval().RequeueAfter) {
+		result = utils.RequeueWithMediumInterval()
+	}
+
 	return
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// SetupWithManager sets up the controller with the Manager. NodePools are also reconciled when the configuration of
+// their HardwareManager changes, so that edits take effect without waiting for a requeue, and when the NodePools split
+// from them change.
 func (r *NodePoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&hwmgmtv1alpha1.NodePool{}).
+		Watches(&pluginv1alpha1.HardwareManager{},
+			handler.EnqueueRequestsFromMapFunc(utils.MapHardwareManagerToNodePools(r.Client)),
+			builder.WithPredicates(utils.HardwareManagerConfigChanged())).
+		Watches(&hwmgmtv1alpha1.NodePool{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSplitNodePoolToParent)).
 		Complete(r); err != nil {
 		return fmt.Errorf("failed to create controller: %w", err)
 	}
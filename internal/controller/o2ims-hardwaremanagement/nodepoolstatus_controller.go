@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodePoolStatusReconciler rolls the conditions of the Nodes of a NodePool up into the NodesReady condition of the
+// NodePool, keeping it current as the Node statuses change outside of NodePool reconciles
+type NodePoolStatusReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Logger    *slog.Logger
+	Namespace string
+}
+
+// Reconcile summarizes the conditions of the Nodes of the NodePool
+func (r *NodePoolStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	ctx = logging.AppendCtx(ctx, slog.String("nodepool", req.Name))
+
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	if err = r.Client.Get(ctx, req.NamespacedName, nodepool); err != nil {
+		if errors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch NodePool", slog.String("error", err.Error()))
+		return
+	}
+	if nodepool.GetDeletionTimestamp() != nil {
+		return
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, r.Logger, r.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+	if len(nodelist.Items) == 0 {
+		// Nothing to summarize until nodes are allocated
+		return
+	}
+
+	summary := utils.SummarizeNodeStatus(nodelist.Items)
+	if err = utils.UpdateNodePoolNodesReadyCondition(ctx, r.Client, nodepool, summary); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return
+}
+
+// mapNodeToNodePool enqueues the NodePool of a Node
+func (r *NodePoolStatusReconciler) mapNodeToNodePool(ctx context.Context, obj client.Object) []reconcile.Request {
+	node, ok := obj.(*hwmgmtv1alpha1.Node)
+	if !ok || node.Spec.NodePool == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: node.Spec.NodePool, Namespace: node.Namespace}}}
+}
+
+// SetupWithManager sets up the controller with the Manager. NodePool updates that do not change its spec are ignored,
+// as the status of the NodePool is driven by its Nodes.
+func (r *NodePoolStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("nodepool-status").
+		For(&hwmgmtv1alpha1.NodePool{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&hwmgmtv1alpha1.Node{}, handler.EnqueueRequestsFromMapFunc(r.mapNodeToNodePool)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// reconcileSplitNodePool fans a NodePool served by multiple HardwareManagers out into a NodePool for each
+// HardwareManager, which are processed by their adaptors, and merges their status into that of the NodePool
+func (r *NodePoolReconciler) reconcileSplitNodePool(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	desired, err := utils.BuildSplitNodePools(nodepool)
+	if err == nil {
+		hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+		if listErr := r.Client.List(ctx, hwmgrs, client.InNamespace(r.Namespace)); listErr != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to list HardwareManagers: %w", listErr)
+		}
+		err = utils.ValidateSplitNodePoolAdaptors(nodepool, hwmgrs.Items)
+	}
+	if err != nil {
+		r.Logger.InfoContext(ctx, "Invalid nodegroup hardware managers", slog.String("error", err.Error()))
+		if err := utils.NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Failed, "Invalid nodegroup hardware managers: "+err.Error()).
+			Update(ctx, r.Client); err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return utils.DoNotRequeue(), nil
+	}
+
+	if !controllerutil.ContainsFinalizer(nodepool, utils.NodepoolFinalizer) {
+		if err := utils.NodepoolAddFinalizer(ctx, r.Client, nodepool); err != nil {
+			return utils.RequeueImmediately(), fmt.Errorf("failed to add finalizer to nodepool: %w", err)
+		}
+	}
+
+	updated := false
+	names := make(map[string]bool)
+	children := make([]hwmgmtv1alpha1.NodePool, 0, len(desired))
+	for _, child := range desired {
+		names[child.Name] = true
+		current, changed, err := r.syncSplitNodePool(ctx, nodepool, child)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		updated = updated || changed
+		children = append(children, *current)
+	}
+
+	// Remove the NodePools of HardwareManagers that no longer serve any nodegroup, releasing their nodes
+	existing, err := r.listSplitNodePools(ctx, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+	for i := range existing.Items {
+		stale := &existing.Items[i]
+		if names[stale.Name] || stale.GetDeletionTimestamp() != nil {
+			continue
+		}
+		r.Logger.InfoContext(ctx, "Deleting split NodePool no longer serving any nodegroup",
+			slog.String("splitNodePool", stale.Name))
+		if err := r.Client.Delete(ctx, stale); client.IgnoreNotFound(err) != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete split NodePool %s: %w", stale.Name, err)
+		}
+	}
+
+	if updated {
+		// The status is merged once the split NodePools have been processed, on the reconcile triggered by their update
+		return utils.DoNotRequeue(), nil
+	}
+
+	if utils.MergeSplitNodePoolStatus(nodepool, children) {
+		if err := utils.UpdateK8sCRStatus(ctx, r.Client, nodepool, func() {
+			utils.MergeSplitNodePoolStatus(nodepool, children)
+		}); err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// syncSplitNodePool creates or updates a split NodePool from its desired state, returning the current NodePool and
+// whether it was changed
+func (r *NodePoolReconciler) syncSplitNodePool(
+	ctx context.Context,
+	nodepool, desired *hwmgmtv1alpha1.NodePool) (*hwmgmtv1alpha1.NodePool, bool, error) {
+
+	current := &hwmgmtv1alpha1.NodePool{}
+	exists, err := utils.DoesK8SResourceExist(ctx, r.Client, desired.Name, desired.Namespace, current)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query split NodePool %s: %w", desired.Name, err)
+	}
+
+	if !exists {
+		r.Logger.InfoContext(ctx, "Creating split NodePool",
+			slog.String("splitNodePool", desired.Name),
+			slog.String("hwMgrId", desired.Spec.HwMgrId))
+		if err := controllerutil.SetControllerReference(nodepool, desired, r.Scheme); err != nil {
+			return nil, false, fmt.Errorf("failed to set owner of split NodePool %s: %w", desired.Name, err)
+		}
+		if err := r.Client.Create(ctx, desired); err != nil {
+			return nil, false, fmt.Errorf("failed to create split NodePool %s: %w", desired.Name, err)
+		}
+		return desired, true, nil
+	}
+
+	if current.Labels[utils.SplitParentLabel] != nodepool.Name {
+		return nil, false, fmt.Errorf("nodepool %s exists and is not split from nodepool %s", current.Name, nodepool.Name)
+	}
+
+	// Labels and annotations set on the split NodePool by the plugin, such as the standard labels, are retained
+	if equality.Semantic.DeepEqual(current.Spec, desired.Spec) &&
+		isSubset(desired.Labels, current.Labels) &&
+		isSubset(desired.Annotations, current.Annotations) {
+		return current, false, nil
+	}
+
+	r.Logger.InfoContext(ctx, "Updating split NodePool", slog.String("splitNodePool", current.Name))
+	patch := client.MergeFrom(current.DeepCopy())
+	current.Spec = desired.Spec
+	if current.Labels == nil {
+		current.Labels = make(map[string]string)
+	}
+	maps.Copy(current.Labels, desired.Labels)
+	if current.Annotations == nil {
+		current.Annotations = make(map[string]string)
+	}
+	maps.Copy(current.Annotations, desired.Annotations)
+	if err := r.Client.Patch(ctx, current, patch); err != nil {
+		return nil, false, fmt.Errorf("failed to patch split NodePool %s: %w", current.Name, err)
+	}
+	return current, true, nil
+}
+
+// isSubset returns true if all the entries of a are in b
+func isSubset(a, b map[string]string) bool {
+	for key, value := range a {
+		if current, exists := b[key]; !exists || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// listSplitNodePools returns the NodePools split from the parent NodePool
+func (r *NodePoolReconciler) listSplitNodePools(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (*hwmgmtv1alpha1.NodePoolList, error) {
+	children := &hwmgmtv1alpha1.NodePoolList{}
+	if err := r.Client.List(ctx, children,
+		client.InNamespace(nodepool.Namespace),
+		client.MatchingLabels{utils.SplitParentLabel: nodepool.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list split NodePools of %s: %w", nodepool.Name, err)
+	}
+	return children, nil
+}
+
+// handleSplitNodePoolDeletion deletes the split NodePools of a NodePool being deleted, retaining its finalizer until
+// their nodes have been released by their adaptors
+func (r *NodePoolReconciler) handleSplitNodePoolDeletion(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	children, err := r.listSplitNodePools(ctx, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	for i := range children.Items {
+		child := &children.Items[i]
+		if child.GetDeletionTimestamp() != nil {
+			continue
+		}
+		r.Logger.InfoContext(ctx, "Deleting split NodePool", slog.String("splitNodePool", child.Name))
+		if err := r.Client.Delete(ctx, child); client.IgnoreNotFound(err) != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete split NodePool %s: %w", child.Name, err)
+		}
+	}
+
+	if len(children.Items) > 0 {
+		// Wait for the split NodePools to be finalized
+		return utils.RequeueWithShortInterval(), nil
+	}
+
+	if err := utils.NodepoolRemoveFinalizer(ctx, r.Client, nodepool); err != nil {
+		return utils.RequeueImmediately(), fmt.Errorf("failed to remove finalizer from nodepool: %w", err)
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// mapSplitNodePoolToParent enqueues the parent of a split NodePool, so that its status is merged as the split NodePools
+// are processed
+func (r *NodePoolReconciler) mapSplitNodePoolToParent(ctx context.Context, obj client.Object) []reconcile.Request {
+	parent, exists := obj.GetLabels()[utils.SplitParentLabel]
+	if !exists {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: parent, Namespace: obj.GetNamespace()}}}
+}
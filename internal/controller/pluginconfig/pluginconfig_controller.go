@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pluginconfig
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// PluginConfigReconciler applies the plugin-wide settings from the PluginConfig CR at runtime
+type PluginConfigReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Logger    *slog.Logger
+	Namespace string
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=pluginconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=pluginconfigs/status,verbs=get;update;patch
+
+// Reconcile applies the settings of the PluginConfig, restoring the defaults if it is deleted
+func (r *PluginConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	ctx = logging.AppendCtx(ctx, slog.String("pluginconfig", req.Name))
+
+	config := &pluginv1alpha1.PluginConfig{}
+	if err = r.Client.Get(ctx, req.NamespacedName, config); err != nil {
+		if errors.IsNotFound(err) {
+			if req.Name == pluginv1alpha1.PluginConfigName {
+				r.Logger.InfoContext(ctx, "PluginConfig deleted, restoring default settings")
+				r.applySettings(nil)
+			}
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch PluginConfig", slog.String("error", err.Error()))
+		return
+	}
+
+	if config.Name != pluginv1alpha1.PluginConfigName {
+		r.Logger.InfoContext(ctx, "Ignoring PluginConfig with unsupported name")
+		err = r.updateStatus(ctx, config, pluginv1alpha1.ConditionReasons.Failed, metav1.ConditionFalse,
+			fmt.Sprintf("Ignored: only the PluginConfig named %s is applied", pluginv1alpha1.PluginConfigName))
+		return
+	}
+
+	settings, parseErr := utils.ParsePluginSettings(&config.Spec)
+	if parseErr != nil {
+		r.Logger.InfoContext(ctx, "Invalid PluginConfig, retaining current settings", slog.String("error", parseErr.Error()))
+		err = r.updateStatus(ctx, config, pluginv1alpha1.ConditionReasons.Failed, metav1.ConditionFalse,
+			"Invalid settings: "+parseErr.Error())
+		return
+	}
+
+	r.applySettings(settings)
+	r.Logger.InfoContext(ctx, "Applied plugin settings", slog.Any("settings", config.Spec))
+
+	err = r.updateStatus(ctx, config, pluginv1alpha1.ConditionReasons.Completed, metav1.ConditionTrue, "Settings applied")
+	return
+}
+
+// applySettings replaces the plugin-wide settings, with nil restoring the defaults
+func (r *PluginConfigReconciler) applySettings(settings *utils.PluginSettings) {
+	utils.SetPluginSettings(settings)
+	logging.LogLevel.Set(utils.GetPluginSettings().LogLevel)
+}
+
+func (r *PluginConfigReconciler) updateStatus(
+	ctx context.Context,
+	config *pluginv1alpha1.PluginConfig,
+	reason pluginv1alpha1.ConditionReason,
+	status metav1.ConditionStatus,
+	message string) error {
+
+	// The status is that of the generation being processed, should the PluginConfig be read again on a conflict
+	generation := config.Generation
+	if err := utils.UpdateK8sCRStatus(ctx, r.Client, config, func() {
+		config.Status.ObservedGeneration = generation
+		utils.SetStatusCondition(&config.Status.Conditions,
+			string(pluginv1alpha1.ConditionTypes.Applied),
+			string(reason),
+			status,
+			message)
+	}); err != nil {
+		return fmt.Errorf("failed to update status for PluginConfig %s: %w", config.Name, err)
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PluginConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&pluginv1alpha1.PluginConfig{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,470 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotehub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// RemoteHubLabel is set on local mirrors of remote NodePool CRs, identifying the HardwareManager serving the remote hub
+	RemoteHubLabel = "hwmgr-plugin.oran.openshift.io/remote-hub"
+
+	// RemoteGenerationAnnotation records the generation of the remote NodePool that was last mirrored locally
+	RemoteGenerationAnnotation = "hwmgr-plugin.oran.openshift.io/remote-generation"
+
+	// RemoteHubFinalizer is set on remote NodePool CRs to ensure the local mirror is released before deletion
+	RemoteHubFinalizer = "oran-hwmgr-plugin/remote-hub-finalizer"
+
+	kubeconfigKey       = "kubeconfig"
+	defaultSyncInterval = 30 * time.Second
+)
+
+type remoteClient struct {
+	client.Client
+	secretName    string
+	secretVersion string
+}
+
+// RemoteHubReconciler synchronizes NodePool CRs from a remote hub with local mirrors that are processed by the adaptors,
+// reporting the resulting status, Node CRs, and bmc-secrets back to the remote hub
+type RemoteHubReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Logger    *slog.Logger
+	Namespace string
+
+	clients sync.Map
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile performs a synchronization pass for a HardwareManager configured with a remote hub
+func (r *RemoteHubReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if k8serrors.IsNotFound(err) {
+			r.clients.Delete(req.Name)
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch HardwareManager", slog.String("error", err.Error()))
+		return
+	}
+
+	if hwmgr.Spec.RemoteHub == nil {
+		r.clients.Delete(hwmgr.Name)
+		if err = r.deleteLocalMirrors(ctx, hwmgr); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	interval := defaultSyncInterval
+	if hwmgr.Spec.RemoteHub.SyncInterval != nil {
+		interval = hwmgr.Spec.RemoteHub.SyncInterval.Duration
+	}
+
+	remote, clientErr := r.getRemoteClient(ctx, hwmgr)
+	if clientErr == nil {
+		clientErr = r.syncRemoteHub(ctx, remote, hwmgr)
+	}
+
+	if clientErr != nil {
+		r.Logger.InfoContext(ctx, "Remote hub synchronization failed", slog.String("error", clientErr.Error()))
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.RemoteHub,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			"Remote hub synchronization failed: "+clientErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s): %w", hwmgr.Name, updateErr)
+			return
+		}
+		return utils.RequeueWithCustomInterval(interval), nil
+	}
+
+	if !meta.IsStatusConditionTrue(hwmgr.Status.Conditions, string(pluginv1alpha1.ConditionTypes.RemoteHub)) {
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.RemoteHub,
+			pluginv1alpha1.ConditionReasons.Completed,
+			metav1.ConditionTrue,
+			"Synchronized with remote hub"); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s): %w", hwmgr.Name, updateErr)
+			return
+		}
+	}
+
+	return utils.RequeueWithCustomInterval(interval), nil
+}
+
+// getRemoteClient returns a client for the remote hub, rebuilding it if the kubeconfig secret, or its content, has changed
+func (r *RemoteHubReconciler) getRemoteClient(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (client.Client, error) {
+	secret, err := utils.GetSecret(ctx, r.Client, hwmgr.Spec.RemoteHub.KubeconfigSecret, r.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret: %w", err)
+	}
+
+	if cached, ok := r.clients.Load(hwmgr.Name); ok {
+		if rc := cached.(*remoteClient); rc.secretName == secret.Name && rc.secretVersion == secret.ResourceVersion {
+			return rc.Client, nil
+		}
+	}
+
+	kubeconfig, err := utils.GetSecretField(secret, kubeconfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig from secret %s: %w", secret.Name, err)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig from secret %s: %w", secret.Name, err)
+	}
+
+	base, err := client.New(config, client.Options{Scheme: r.Scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remote hub client: %w", err)
+	}
+	c := utils.NewReadOnlyClient(base)
+
+	r.Logger.InfoContext(ctx, "Created remote hub client", slog.String("host", config.Host))
+	r.clients.Store(hwmgr.Name, &remoteClient{Client: c, secretName: secret.Name, secretVersion: secret.ResourceVersion})
+
+	return c, nil
+}
+
+// deleteLocalMirrors deletes the local mirrors of a HardwareManager whose remote hub has been removed, releasing their
+// nodes. The finalizers of the remote NodePools are left in place, as the remote hub is no longer reachable.
+func (r *RemoteHubReconciler) deleteLocalMirrors(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) error {
+	mirrors := &hwmgmtv1alpha1.NodePoolList{}
+	if err := r.Client.List(ctx, mirrors, client.InNamespace(r.Namespace),
+		client.MatchingLabels{RemoteHubLabel: hwmgr.Name}); err != nil {
+		return fmt.Errorf("failed to list local mirrors: %w", err)
+	}
+
+	for i := range mirrors.Items {
+		mirror := &mirrors.Items[i]
+		if mirror.GetDeletionTimestamp() != nil {
+			continue
+		}
+		r.Logger.InfoContext(ctx, "Remote hub removed, deleting local mirror", slog.String("nodepool", mirror.Name))
+		if err := r.Client.Delete(ctx, mirror); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete local mirror %s: %w", mirror.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// syncRemoteHub mirrors remote NodePool CRs served by this HardwareManager, and reports local progress back to the hub.
+// A failure to sync one NodePool does not hold up the others, with the failures returned once all have been synced.
+func (r *RemoteHubReconciler) syncRemoteHub(ctx context.Context, remote client.Client, hwmgr *pluginv1alpha1.HardwareManager) error {
+	remoteNodePools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := remote.List(ctx, remoteNodePools, client.InNamespace(hwmgr.Spec.RemoteHub.Namespace)); err != nil {
+		return fmt.Errorf("failed to list remote nodepools: %w", err)
+	}
+
+	var errs []error
+	for i := range remoteNodePools.Items {
+		remoteNodePool := &remoteNodePools.Items[i]
+		if remoteNodePool.Spec.HwMgrId != hwmgr.Name {
+			continue
+		}
+
+		npCtx := logging.AppendCtx(ctx, slog.String("nodepool", remoteNodePool.Name))
+		if err := r.syncNodePool(npCtx, remote, hwmgr, remoteNodePool); err != nil {
+			r.Logger.InfoContext(npCtx, "Failed to sync remote nodepool", slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("failed to sync nodepool %s: %w", remoteNodePool.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *RemoteHubReconciler) syncNodePool(
+	ctx context.Context,
+	remote client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	remoteNodePool *hwmgmtv1alpha1.NodePool) error {
+
+	local := &hwmgmtv1alpha1.NodePool{}
+	exists, err := utils.DoesK8SResourceExist(ctx, r.Client, remoteNodePool.Name, r.Namespace, local)
+	if err != nil {
+		return fmt.Errorf("failed to query local mirror: %w", err)
+	}
+
+	if remoteNodePool.GetDeletionTimestamp() != nil {
+		if exists {
+			if local.GetDeletionTimestamp() == nil {
+				r.Logger.InfoContext(ctx, "Remote nodepool is being deleted, deleting local mirror")
+				if err := r.Client.Delete(ctx, local); client.IgnoreNotFound(err) != nil {
+					return fmt.Errorf("failed to delete local mirror: %w", err)
+				}
+			}
+			// Wait for the local mirror to be finalized before releasing the remote nodepool
+			return nil
+		}
+
+		if controllerutil.RemoveFinalizer(remoteNodePool, RemoteHubFinalizer) {
+			if err := remote.Update(ctx, remoteNodePool); err != nil {
+				return fmt.Errorf("failed to remove finalizer from remote nodepool: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if controllerutil.AddFinalizer(remoteNodePool, RemoteHubFinalizer) {
+		if err := remote.Update(ctx, remoteNodePool); err != nil {
+			return fmt.Errorf("failed to add finalizer to remote nodepool: %w", err)
+		}
+	}
+
+	remoteGeneration := strconv.FormatInt(remoteNodePool.Generation, 10)
+
+	if !exists {
+		r.Logger.InfoContext(ctx, "Creating local mirror of remote nodepool")
+		local = &hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        remoteNodePool.Name,
+				Namespace:   r.Namespace,
+				Labels:      map[string]string{RemoteHubLabel: hwmgr.Name},
+				Annotations: map[string]string{RemoteGenerationAnnotation: remoteGeneration},
+			},
+			Spec: *remoteNodePool.Spec.DeepCopy(),
+		}
+		if err := r.Client.Create(ctx, local); err != nil {
+			return fmt.Errorf("failed to create local mirror: %w", err)
+		}
+		return nil
+	}
+
+	if local.Labels[RemoteHubLabel] != hwmgr.Name {
+		return fmt.Errorf("local nodepool %s exists and is not a mirror for hardware manager %s", local.Name, hwmgr.Name)
+	}
+
+	if !equality.Semantic.DeepEqual(local.Spec, remoteNodePool.Spec) {
+		r.Logger.InfoContext(ctx, "Updating local mirror with remote nodepool spec change")
+		patch := client.MergeFrom(local.DeepCopy())
+		local.Spec = *remoteNodePool.Spec.DeepCopy()
+		if local.Annotations == nil {
+			local.Annotations = make(map[string]string)
+		}
+		local.Annotations[RemoteGenerationAnnotation] = remoteGeneration
+		if err := r.Client.Patch(ctx, local, patch); err != nil {
+			return fmt.Errorf("failed to patch local mirror: %w", err)
+		}
+		return nil
+	}
+
+	if err := r.syncNodePoolStatus(ctx, remote, local, remoteNodePool); err != nil {
+		return err
+	}
+
+	return r.syncNodes(ctx, remote, local, remoteNodePool)
+}
+
+// syncNodePoolStatus reports the local mirror status to the remote nodepool
+func (r *RemoteHubReconciler) syncNodePoolStatus(
+	ctx context.Context,
+	remote client.Client,
+	local, remoteNodePool *hwmgmtv1alpha1.NodePool) error {
+
+	status := *local.Status.DeepCopy()
+	status.HwMgrPlugin.ObservedGeneration = remoteNodePool.Status.HwMgrPlugin.ObservedGeneration
+	if local.Status.HwMgrPlugin.ObservedGeneration == local.Generation {
+		if generation, err := strconv.ParseInt(local.Annotations[RemoteGenerationAnnotation], 10, 64); err == nil {
+			status.HwMgrPlugin.ObservedGeneration = generation
+		}
+	}
+
+	if equality.Semantic.DeepEqual(status, remoteNodePool.Status) {
+		return nil
+	}
+
+	remoteNodePool.Status = status
+	if err := remote.Status().Update(ctx, remoteNodePool); err != nil {
+		return fmt.Errorf("failed to update remote nodepool status: %w", err)
+	}
+
+	return nil
+}
+
+// syncNodes copies the Node CRs, and their bmc-secrets, allocated to the local mirror to the remote hub, deleting those
+// of the nodes since released
+func (r *RemoteHubReconciler) syncNodes(
+	ctx context.Context,
+	remote client.Client,
+	local, remoteNodePool *hwmgmtv1alpha1.NodePool) error {
+
+	blockDeletion := true
+	ownerRefs := []metav1.OwnerReference{{
+		APIVersion:         hwmgmtv1alpha1.GroupVersion.String(),
+		Kind:               "NodePool",
+		Name:               remoteNodePool.Name,
+		UID:                remoteNodePool.UID,
+		BlockOwnerDeletion: &blockDeletion,
+	}}
+
+	for _, nodename := range local.Status.Properties.NodeNames {
+		node, err := utils.GetNode(ctx, r.Logger, r.Client, r.Namespace, nodename)
+		if err != nil {
+			return fmt.Errorf("failed to get node %s: %w", nodename, err)
+		}
+
+		if node.Status.BMC != nil && node.Status.BMC.CredentialsName != "" {
+			secret, err := utils.GetSecret(ctx, r.Client, node.Status.BMC.CredentialsName, r.Namespace)
+			if err != nil {
+				return fmt.Errorf("failed to get bmc-secret for node %s: %w", nodename, err)
+			}
+
+			remoteSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            secret.Name,
+					Namespace:       remoteNodePool.Namespace,
+					OwnerReferences: ownerRefs,
+				},
+				Data: secret.Data,
+			}
+			if err := utils.ApplyK8sCR(ctx, remote, remoteSecret, nil); err != nil {
+				return fmt.Errorf("failed to sync bmc-secret for node %s: %w", nodename, err)
+			}
+		}
+
+		remoteNode := &hwmgmtv1alpha1.Node{}
+		exists, err := utils.DoesK8SResourceExist(ctx, remote, node.Name, remoteNodePool.Namespace, remoteNode)
+		if err != nil {
+			return fmt.Errorf("failed to query remote node %s: %w", nodename, err)
+		}
+
+		if !exists {
+			remoteNode = &hwmgmtv1alpha1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            node.Name,
+					Namespace:       remoteNodePool.Namespace,
+					Labels:          node.Labels,
+					Annotations:     node.Annotations,
+					OwnerReferences: ownerRefs,
+				},
+				Spec: *node.Spec.DeepCopy(),
+			}
+			remoteNode.Spec.NodePool = remoteNodePool.Name
+			if err := remote.Create(ctx, remoteNode); err != nil {
+				return fmt.Errorf("failed to create remote node %s: %w", nodename, err)
+			}
+		} else if !equality.Semantic.DeepEqual(remoteNode.Spec.HwProfile, node.Spec.HwProfile) {
+			patch := client.MergeFrom(remoteNode.DeepCopy())
+			remoteNode.Spec.HwProfile = node.Spec.HwProfile
+			if err := remote.Patch(ctx, remoteNode, patch); err != nil {
+				return fmt.Errorf("failed to patch remote node %s: %w", nodename, err)
+			}
+		}
+
+		if !equality.Semantic.DeepEqual(remoteNode.Status, node.Status) {
+			// The remote Node status mirrors the local Node, so is replaced rather than merged
+			if err := utils.UpdateK8sCRStatus(ctx, remote, remoteNode, func() {
+				remoteNode.Status = *node.Status.DeepCopy()
+			}); err != nil {
+				return fmt.Errorf("failed to update remote node status %s: %w", nodename, err)
+			}
+		}
+	}
+
+	return r.deleteReleasedNodes(ctx, remote, local, remoteNodePool)
+}
+
+// deleteReleasedNodes deletes the remote Node CRs of the remote nodepool, and their bmc-secrets, for the nodes no
+// longer allocated to the local mirror
+func (r *RemoteHubReconciler) deleteReleasedNodes(
+	ctx context.Context,
+	remote client.Client,
+	local, remoteNodePool *hwmgmtv1alpha1.NodePool) error {
+
+	remoteNodes := &hwmgmtv1alpha1.NodeList{}
+	if err := remote.List(ctx, remoteNodes, client.InNamespace(remoteNodePool.Namespace)); err != nil {
+		return fmt.Errorf("failed to list remote nodes: %w", err)
+	}
+
+	for i := range remoteNodes.Items {
+		remoteNode := &remoteNodes.Items[i]
+		if remoteNode.Spec.NodePool != remoteNodePool.Name ||
+			slices.Contains(local.Status.Properties.NodeNames, remoteNode.Name) {
+			continue
+		}
+
+		r.Logger.InfoContext(ctx, "Deleting remote node released by local mirror", slog.String("nodename", remoteNode.Name))
+		if remoteNode.Status.BMC != nil && remoteNode.Status.BMC.CredentialsName != "" {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      remoteNode.Status.BMC.CredentialsName,
+					Namespace: remoteNodePool.Namespace,
+				},
+			}
+			if err := remote.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete bmc-secret for remote node %s: %w", remoteNode.Name, err)
+			}
+		}
+		if err := remote.Delete(ctx, remoteNode); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete remote node %s: %w", remoteNode.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RemoteHubReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("remotehub").
+		For(&pluginv1alpha1.HardwareManager{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create remotehub controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotehub
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// objectStore holds objects of any type for a hub, serving the reads and changes made to them without an API server
+type objectStore struct {
+	client.Client
+	objects map[string]client.Object
+	deleted []string
+}
+
+func newObjectStore(objects ...client.Object) *objectStore {
+	store := &objectStore{objects: make(map[string]client.Object)}
+	for _, obj := range objects {
+		store.objects[storeKey(obj, obj.GetNamespace(), obj.GetName())] = obj
+	}
+	return store
+}
+
+func storeKey(obj client.Object, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", reflect.TypeOf(obj).Elem().Name(), namespace, name)
+}
+
+func (s *objectStore) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	stored, exists := s.objects[storeKey(obj, key.Namespace, key.Name)]
+	if !exists {
+		return k8serrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(stored.DeepCopyObject()).Elem())
+	return nil
+}
+
+func (s *objectStore) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	options := &client.ListOptions{}
+	options.ApplyOptions(opts)
+
+	var items []client.Object
+	for _, obj := range s.objects {
+		if options.Namespace != "" && obj.GetNamespace() != options.Namespace {
+			continue
+		}
+		if options.LabelSelector != nil && !options.LabelSelector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		items = append(items, obj.DeepCopyObject().(client.Object))
+	}
+	slices.SortFunc(items, func(a, b client.Object) int {
+		return slices.Compare([]string{a.GetNamespace(), a.GetName()}, []string{b.GetNamespace(), b.GetName()})
+	})
+
+	for _, item := range items {
+		switch typed := list.(type) {
+		case *hwmgmtv1alpha1.NodePoolList:
+			if nodepool, ok := item.(*hwmgmtv1alpha1.NodePool); ok {
+				typed.Items = append(typed.Items, *nodepool)
+			}
+		case *hwmgmtv1alpha1.NodeList:
+			if node, ok := item.(*hwmgmtv1alpha1.Node); ok {
+				typed.Items = append(typed.Items, *node)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *objectStore) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	key := storeKey(obj, obj.GetNamespace(), obj.GetName())
+	if _, exists := s.objects[key]; exists {
+		return k8serrors.NewAlreadyExists(schema.GroupResource{}, obj.GetName())
+	}
+	s.objects[key] = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func (s *objectStore) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	s.objects[storeKey(obj, obj.GetNamespace(), obj.GetName())] = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func (s *objectStore) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	s.objects[storeKey(obj, obj.GetNamespace(), obj.GetName())] = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func (s *objectStore) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	key := storeKey(obj, obj.GetNamespace(), obj.GetName())
+	if _, exists := s.objects[key]; !exists {
+		return k8serrors.NewNotFound(schema.GroupResource{}, obj.GetName())
+	}
+	delete(s.objects, key)
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func (s *objectStore) Status() client.SubResourceWriter {
+	return &objectStatusWriter{store: s}
+}
+
+func (s *objectStore) has(obj client.Object, namespace, name string) bool {
+	_, exists := s.objects[storeKey(obj, namespace, name)]
+	return exists
+}
+
+type objectStatusWriter struct {
+	client.SubResourceWriter
+	store *objectStore
+}
+
+func (w *objectStatusWriter) Update(ctx context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+	return w.store.Update(ctx, obj)
+}
+
+var _ = Describe("Remote hub synchronization", func() {
+	var (
+		ctx    context.Context
+		local  *objectStore
+		remote *objectStore
+		r      *RemoteHubReconciler
+		hwmgr  *pluginv1alpha1.HardwareManager
+	)
+
+	newNodePool := func(name, namespace string) *hwmgmtv1alpha1.NodePool {
+		return &hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Generation: 1},
+			Spec:       hwmgmtv1alpha1.NodePoolSpec{HwMgrId: "hwmgr", CloudID: name},
+		}
+	}
+
+	newMirror := func(name string) *hwmgmtv1alpha1.NodePool {
+		mirror := newNodePool(name, "plugin")
+		mirror.Labels = map[string]string{RemoteHubLabel: "hwmgr"}
+		mirror.Annotations = map[string]string{RemoteGenerationAnnotation: "1"}
+		return mirror
+	}
+
+	newNode := func(name, namespace, nodepool string) *hwmgmtv1alpha1.Node {
+		return &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       hwmgmtv1alpha1.NodeSpec{NodePool: nodepool},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		hwmgr = &pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "hwmgr", Namespace: "plugin"},
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				AdaptorID: pluginv1alpha1.SupportedAdaptors.Loopback,
+				RemoteHub: &pluginv1alpha1.RemoteHubConfig{KubeconfigSecret: "kubeconfig", Namespace: "remote"},
+			},
+		}
+		local = newObjectStore(hwmgr)
+		remote = newObjectStore()
+	})
+
+	newReconciler := func(c client.Client) *RemoteHubReconciler {
+		return &RemoteHubReconciler{
+			Client:    c,
+			Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Namespace: "plugin",
+		}
+	}
+
+	It("continues with the other NodePools when one fails to sync", func() {
+		// A local NodePool of the same name that is not a mirror blocks the sync of np1
+		local = newObjectStore(hwmgr, newNodePool("np1", "plugin"))
+		remote = newObjectStore(newNodePool("np1", "remote"), newNodePool("np2", "remote"))
+		r = newReconciler(local)
+
+		err := r.syncRemoteHub(ctx, remote, hwmgr)
+		Expect(err).To(MatchError(ContainSubstring("failed to sync nodepool np1")))
+		Expect(err).ToNot(MatchError(ContainSubstring("np2")))
+
+		mirror := &hwmgmtv1alpha1.NodePool{}
+		Expect(local.Get(ctx, types.NamespacedName{Name: "np2", Namespace: "plugin"}, mirror)).To(Succeed())
+		Expect(mirror.Labels).To(HaveKeyWithValue(RemoteHubLabel, "hwmgr"))
+	})
+
+	It("records the remote generation on a mirror without annotations", func() {
+		mirror := newMirror("np1")
+		mirror.Annotations = nil
+		local.objects[storeKey(mirror, "plugin", "np1")] = mirror
+		remoteNodePool := newNodePool("np1", "remote")
+		remoteNodePool.Generation = 2
+		remoteNodePool.Spec.CloudID = "changed"
+		r = newReconciler(local)
+
+		Expect(r.syncNodePool(ctx, remote, hwmgr, remoteNodePool)).To(Succeed())
+
+		updated := &hwmgmtv1alpha1.NodePool{}
+		Expect(local.Get(ctx, types.NamespacedName{Name: "np1", Namespace: "plugin"}, updated)).To(Succeed())
+		Expect(updated.Spec.CloudID).To(Equal("changed"))
+		Expect(updated.Annotations).To(HaveKeyWithValue(RemoteGenerationAnnotation, "2"))
+	})
+
+	It("deletes the remote Nodes and bmc-secrets of the nodes released by the mirror", func() {
+		mirror := newMirror("np1")
+		mirror.Status.Properties.NodeNames = []string{"node1"}
+		local = newObjectStore(hwmgr, mirror, newNode("node1", "plugin", "np1"))
+
+		released := newNode("node2", "remote", "np1")
+		released.Status.BMC = &hwmgmtv1alpha1.BMC{CredentialsName: "node2-bmc-secret"}
+		remote = newObjectStore(
+			newNode("node1", "remote", "np1"),
+			released,
+			newNode("node3", "remote", "np2"),
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "node2-bmc-secret", Namespace: "remote"}},
+		)
+		r = newReconciler(local)
+
+		remoteNodePool := newNodePool("np1", "remote")
+		Expect(r.syncNodePool(ctx, remote, hwmgr, remoteNodePool)).To(Succeed())
+
+		Expect(remote.has(&hwmgmtv1alpha1.Node{}, "remote", "node1")).To(BeTrue())
+		Expect(remote.has(&hwmgmtv1alpha1.Node{}, "remote", "node2")).To(BeFalse())
+		Expect(remote.has(&corev1.Secret{}, "remote", "node2-bmc-secret")).To(BeFalse())
+		Expect(remote.has(&hwmgmtv1alpha1.Node{}, "remote", "node3")).To(BeTrue())
+	})
+
+	It("deletes the local mirrors once the remote hub is removed", func() {
+		hwmgr.Spec.RemoteHub = nil
+		other := newMirror("np2")
+		other.Labels[RemoteHubLabel] = "other"
+		local = newObjectStore(hwmgr, newMirror("np1"), other, newNodePool("np3", "plugin"))
+		r = newReconciler(local)
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "hwmgr", Namespace: "plugin"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(local.deleted).To(Equal([]string{storeKey(&hwmgmtv1alpha1.NodePool{}, "plugin", "np1")}))
+	})
+})
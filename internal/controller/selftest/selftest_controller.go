@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// SelfTestReconciler runs the self-test of a HardwareManager when requested with the selfTest annotation
+type SelfTestReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+
+// Reconcile runs a pending self-test of a HardwareManager through the adaptor, and records the results in the status
+func (r *SelfTestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch HardwareManager", slog.String("error", err.Error()))
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	trigger, pending := utils.GetSelfTestTrigger(hwmgr)
+	if !pending {
+		return
+	}
+
+	r.Logger.InfoContext(ctx, "Running self-test", slog.String("trigger", trigger))
+	status := &pluginv1alpha1.SelfTestStatus{Trigger: trigger, StartTime: metav1.Now()}
+	steps, testErr := r.HwMgrAdaptor.SelfTest(ctx, hwmgr)
+	if testErr != nil {
+		steps = []pluginv1alpha1.SelfTestStep{{Name: "Setup", Message: testErr.Error()}}
+	}
+	status.CompletionTime = metav1.Now()
+	status.Steps = steps
+	status.Passed = len(steps) > 0
+	for _, step := range steps {
+		status.Passed = status.Passed && step.Passed
+	}
+
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	hwmgr.Status.SelfTest = status
+	if err = r.Client.Status().Patch(ctx, hwmgr, patch); err != nil {
+		err = fmt.Errorf("failed to update self-test results for hardware manager (%s): %w", hwmgr.Name, err)
+		return
+	}
+
+	r.Logger.InfoContext(ctx, "Self-test completed",
+		slog.Bool("passed", status.Passed),
+		slog.Any("steps", steps))
+
+	return
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SelfTestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("selftest").
+		For(&pluginv1alpha1.HardwareManager{}).
+		WithEventFilter(predicate.AnnotationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create self-test controller: %w", err)
+	}
+
+	return nil
+}
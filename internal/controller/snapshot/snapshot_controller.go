@@ -0,0 +1,184 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// SnapshotReconciler captures the inventory and allocation state of a HardwareManager when requested with the snapshot
+// annotation
+type SnapshotReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;patch;watch
+
+// Reconcile captures a pending snapshot of a HardwareManager, saving it to a configmap and recording the outcome in
+// the status
+func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = nil
+			return
+		}
+		r.Logger.ErrorContext(ctx, "Unable to fetch HardwareManager", slog.String("error", err.Error()))
+		return
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+
+	trigger, pending := utils.GetSnapshotTrigger(hwmgr)
+	if !pending {
+		return
+	}
+
+	r.Logger.InfoContext(ctx, "Capturing inventory snapshot", slog.String("trigger", trigger))
+	status := &pluginv1alpha1.SnapshotStatus{Trigger: trigger, CaptureTime: metav1.Now()}
+	snapshot, message, captureErr := r.capture(ctx, hwmgr, status.CaptureTime)
+	if captureErr != nil {
+		status.Message = "Snapshot failed: " + captureErr.Error()
+	} else {
+		status.Message = message
+		status.Nodes = len(snapshot.Nodes)
+
+		cm, buildErr := utils.NewInventorySnapshotConfigMap(r.Namespace, snapshot)
+		if buildErr != nil {
+			err = buildErr
+			return
+		}
+		if err = utils.ApplyK8sCR(ctx, r.Client, cm, hwmgr); err != nil {
+			err = fmt.Errorf("failed to save inventory snapshot %s: %w", cm.Name, err)
+			return
+		}
+		status.ConfigMap = cm.Name
+	}
+
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	hwmgr.Status.Snapshot = status
+	if err = r.Client.Status().Patch(ctx, hwmgr, patch); err != nil {
+		err = fmt.Errorf("failed to update snapshot status for hardware manager (%s): %w", hwmgr.Name, err)
+		return
+	}
+
+	r.Logger.InfoContext(ctx, "Inventory snapshot completed",
+		slog.String("configmap", status.ConfigMap),
+		slog.Int("nodes", status.Nodes),
+		slog.String("message", status.Message))
+
+	return
+}
+
+// capture builds the inventory snapshot of the hardware manager from its NodePools and Nodes, with the free nodes of
+// each of its resource pools listed by the adaptor. The resource pools for which the free nodes could not be listed
+// are described in the returned message, rather than failing the snapshot.
+func (r *SnapshotReconciler) capture(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	now metav1.Time) (*utils.InventorySnapshot, string, error) {
+
+	var nodepools []hwmgmtv1alpha1.NodePool
+	var nodes []hwmgmtv1alpha1.Node
+	for _, namespace := range utils.GetHardwareManagerWatchNamespaces(hwmgr) {
+		nodepoolList := &hwmgmtv1alpha1.NodePoolList{}
+		if err := r.Client.List(ctx, nodepoolList, client.InNamespace(namespace)); err != nil {
+			return nil, "", fmt.Errorf("failed to list nodepools: %w", err)
+		}
+		nodepools = append(nodepools, nodepoolList.Items...)
+
+		nodeList := &hwmgmtv1alpha1.NodeList{}
+		if err := r.Client.List(ctx, nodeList, client.InNamespace(namespace)); err != nil {
+			return nil, "", fmt.Errorf("failed to list nodes: %w", err)
+		}
+		nodes = append(nodes, nodeList.Items...)
+	}
+
+	var pools []string
+	for _, sitePools := range hwmgr.Status.ResourcePools {
+		pools = append(pools, sitePools...)
+	}
+	slices.Sort(pools)
+	pools = slices.Compact(pools)
+
+	var freenodes []utils.FreeNode
+	var skipped []string
+	for _, pool := range pools {
+		poolFreeNodes, err := r.HwMgrAdaptor.GetFreeNodes(ctx, hwmgr, utils.FreeNodeQuery{ResourcePoolId: pool})
+		if errors.Is(err, sdk.ErrNotSupported) {
+			return utils.BuildInventorySnapshot(hwmgr, nodepools, nodes, nil, now),
+				"Free nodes not captured, as listing free nodes is not supported by the adaptor", nil
+		}
+		if err != nil {
+			r.Logger.InfoContext(ctx, "Unable to list free nodes for snapshot",
+				slog.String("resourcePool", pool), slog.String("error", err.Error()))
+			skipped = append(skipped, pool)
+			continue
+		}
+		freenodes = append(freenodes, poolFreeNodes...)
+	}
+
+	message := ""
+	if len(skipped) > 0 {
+		message = "Free nodes not captured for resource pools: " + strings.Join(skipped, ", ")
+	}
+
+	return utils.BuildInventorySnapshot(hwmgr, nodepools, nodes, freenodes, now), message, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("snapshot").
+		For(&pluginv1alpha1.HardwareManager{}).
+		WithEventFilter(predicate.AnnotationChangedPredicate{}).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create snapshot controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// errorReasons are the condition reasons, across the plugin CRs, that report a problem requiring attention
+var errorReasons = []string{
+	string(hwmgmtv1alpha1.Failed),
+	string(hwmgmtv1alpha1.TimedOut),
+	string(utils.ReasonTimeout),
+	string(utils.ReasonExtensionFailed),
+	string(utils.ReasonUncorrectable),
+	string(utils.ReasonSparesInsufficient),
+	string(utils.ReasonSpreadViolated),
+	string(utils.ReasonNodesDegraded),
+	string(utils.ReasonDrifted),
+	string(utils.ReasonNoProgress),
+	string(sdk.ReasonBackendUnavailable),
+	string(sdk.ReasonBackendThrottled),
+	string(sdk.ReasonBackendAuthFailed),
+	string(sdk.ReasonBackendRejected),
+	string(sdk.ReasonBackendNotFound),
+	string(sdk.ReasonBackendConflict),
+	string(sdk.ReasonInsufficientResources),
+	string(sdk.ReasonBackendFailure),
+	string(pluginv1alpha1.ConditionReasons.Expiring),
+}
+
+// CloudSummary describes the NodePools allocated to a cloud
+type CloudSummary struct {
+	CloudID     string   `json:"cloudId"`
+	HwMgrs      []string `json:"hwMgrs"`
+	NodePools   int      `json:"nodePools"`
+	Provisioned int      `json:"provisioned"`
+	Nodes       int      `json:"nodes"`
+}
+
+// PoolSummary describes the capacity of a resource pool of a hardware manager
+type PoolSummary struct {
+	HwMgrId        string `json:"hwMgrId"`
+	AdaptorId      string `json:"adaptorId"`
+	Site           string `json:"site"`
+	ResourcePoolId string `json:"resourcePoolId"`
+	// The capacity is omitted for adaptors that do not support capacity reporting
+	TotalNodes    *int `json:"totalNodes,omitempty"`
+	FreeNodes     *int `json:"freeNodes,omitempty"`
+	ReservedNodes *int `json:"reservedNodes,omitempty"`
+}
+
+// AllocationSummary describes the allocation of a nodegroup of a NodePool
+type AllocationSummary struct {
+	Namespace      string `json:"namespace"`
+	NodePool       string `json:"nodePool"`
+	CloudID        string `json:"cloudId"`
+	HwMgrId        string `json:"hwMgrId"`
+	NodeGroup      string `json:"nodeGroup"`
+	ResourcePoolId string `json:"resourcePoolId"`
+	Size           int    `json:"size"`
+	Allocated      int    `json:"allocated"`
+	Status         string `json:"status"`
+}
+
+// ErrorSummary describes a condition reporting a problem with a plugin CR
+type ErrorSummary struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Since     string `json:"since"`
+}
+
+// Summary is a snapshot of the state of the plugin, across all hardware managers
+type Summary struct {
+	GeneratedAt string              `json:"generatedAt"`
+	Clouds      []CloudSummary      `json:"clouds"`
+	Pools       []PoolSummary       `json:"pools"`
+	Allocations []AllocationSummary `json:"allocations"`
+	Errors      []ErrorSummary      `json:"errors"`
+}
+
+// Collect builds the summary from the HardwareManager CRs in the plugin namespace, and the NodePool and Node CRs in the
+// namespaces watched by the plugin
+func Collect(ctx context.Context, c client.Client, namespace string) (*Summary, error) {
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := c.List(ctx, hwmgrs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list HardwareManagers: %w", err)
+	}
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.List(ctx, nodepools); err != nil {
+		return nil, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	nodes := &hwmgmtv1alpha1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list Nodes: %w", err)
+	}
+
+	s := &Summary{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Clouds:      []CloudSummary{},
+		Pools:       []PoolSummary{},
+		Allocations: []AllocationSummary{},
+		Errors:      []ErrorSummary{},
+	}
+
+	for i := range hwmgrs.Items {
+		hwmgr := &hwmgrs.Items[i]
+		s.addPools(hwmgr)
+		s.addErrors("HardwareManager", hwmgr.Namespace, hwmgr.Name, hwmgr.Status.Conditions)
+	}
+
+	// Count the allocated nodes of each nodegroup
+	allocated := make(map[string]int)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		allocated[node.Namespace+"/"+node.Spec.NodePool+"/"+node.Spec.GroupName]++
+		s.addErrors("Node", node.Namespace, node.Name, node.Status.Conditions)
+	}
+
+	clouds := make(map[string]*CloudSummary)
+	for i := range nodepools.Items {
+		nodepool := &nodepools.Items[i]
+		if utils.IsSplitNodePool(nodepool) {
+			// The nodegroups are summarized through the NodePools split from it
+			continue
+		}
+		status := conditionSummary(meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)))
+
+		cloud, exists := clouds[nodepool.Spec.CloudID]
+		if !exists {
+			cloud = &CloudSummary{CloudID: nodepool.Spec.CloudID, HwMgrs: []string{}}
+			clouds[nodepool.Spec.CloudID] = cloud
+		}
+		cloud.NodePools++
+		if meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+			cloud.Provisioned++
+		}
+		if !slices.Contains(cloud.HwMgrs, nodepool.Spec.HwMgrId) {
+			cloud.HwMgrs = append(cloud.HwMgrs, nodepool.Spec.HwMgrId)
+		}
+
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			count := allocated[nodepool.Namespace+"/"+nodepool.Name+"/"+nodegroup.NodePoolData.Name]
+			cloud.Nodes += count
+			s.Allocations = append(s.Allocations, AllocationSummary{
+				Namespace:      nodepool.Namespace,
+				NodePool:       nodepool.Name,
+				CloudID:        nodepool.Spec.CloudID,
+				HwMgrId:        nodepool.Spec.HwMgrId,
+				NodeGroup:      nodegroup.NodePoolData.Name,
+				ResourcePoolId: nodegroup.NodePoolData.ResourcePoolId,
+				Size:           nodegroup.Size,
+				Allocated:      count,
+				Status:         status,
+			})
+		}
+
+		s.addErrors("NodePool", nodepool.Namespace, nodepool.Name, nodepool.Status.Conditions)
+	}
+
+	for _, cloud := range clouds {
+		slices.Sort(cloud.HwMgrs)
+		s.Clouds = append(s.Clouds, *cloud)
+	}
+
+	slices.SortFunc(s.Clouds, func(a, b CloudSummary) int {
+		return strings.Compare(a.CloudID, b.CloudID)
+	})
+	slices.SortFunc(s.Pools, func(a, b PoolSummary) int {
+		return strings.Compare(a.HwMgrId+"/"+a.Site+"/"+a.ResourcePoolId, b.HwMgrId+"/"+b.Site+"/"+b.ResourcePoolId)
+	})
+	slices.SortFunc(s.Allocations, func(a, b AllocationSummary) int {
+		return slices.Compare([]string{a.Namespace, a.NodePool, a.NodeGroup}, []string{b.Namespace, b.NodePool, b.NodeGroup})
+	})
+	slices.SortFunc(s.Errors, func(a, b ErrorSummary) int {
+		return slices.Compare([]string{a.Kind, a.Namespace, a.Name, a.Condition}, []string{b.Kind, b.Namespace, b.Name, b.Condition})
+	})
+
+	return s, nil
+}
+
+// addPools adds the resource pools of a hardware manager, with their capacity if reported
+func (s *Summary) addPools(hwmgr *pluginv1alpha1.HardwareManager) {
+	capacity := make(map[string]pluginv1alpha1.ResourcePoolCapacity)
+	if hwmgr.Status.Capacity != nil {
+		for _, pool := range hwmgr.Status.Capacity.ResourcePools {
+			capacity[pool.ResourcePoolId] = pool
+		}
+	}
+
+	for site, pools := range hwmgr.Status.ResourcePools {
+		for _, poolId := range pools {
+			pool := PoolSummary{
+				HwMgrId:        hwmgr.Name,
+				AdaptorId:      string(hwmgr.Spec.AdaptorID),
+				Site:           site,
+				ResourcePoolId: poolId,
+			}
+			if poolCapacity, exists := capacity[poolId]; exists {
+				pool.TotalNodes = &poolCapacity.TotalNodes
+				pool.FreeNodes = &poolCapacity.FreeNodes
+				pool.ReservedNodes = &poolCapacity.ReservedNodes
+			}
+			s.Pools = append(s.Pools, pool)
+		}
+	}
+}
+
+// addErrors adds the conditions of a CR that report a problem
+func (s *Summary) addErrors(kind, namespace, name string, conditions []metav1.Condition) {
+	for _, condition := range conditions {
+		if !slices.Contains(errorReasons, condition.Reason) {
+			continue
+		}
+		s.Errors = append(s.Errors, ErrorSummary{
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+			Condition: condition.Type,
+			Reason:    condition.Reason,
+			Message:   condition.Message,
+			Since:     condition.LastTransitionTime.UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// Render formats the summary as human-readable tables
+func (s *Summary) Render() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Generated at %s\n", s.GeneratedAt)
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "\nCLOUDS")
+	fmt.Fprintln(w, "CLOUD\tHWMGRS\tNODEPOOLS\tPROVISIONED\tNODES")
+	for _, cloud := range s.Clouds {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\n",
+			cloud.CloudID, strings.Join(cloud.HwMgrs, ","), cloud.NodePools, cloud.Provisioned, cloud.Nodes)
+	}
+
+	fmt.Fprintln(w, "\nPOOLS")
+	fmt.Fprintln(w, "HWMGR\tADAPTOR\tSITE\tPOOL\tTOTAL\tFREE\tRESERVED")
+	for _, pool := range s.Pools {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", pool.HwMgrId, pool.AdaptorId, pool.Site, pool.ResourcePoolId,
+			formatCount(pool.TotalNodes), formatCount(pool.FreeNodes), formatCount(pool.ReservedNodes))
+	}
+
+	fmt.Fprintln(w, "\nALLOCATIONS")
+	fmt.Fprintln(w, "NAMESPACE\tNODEPOOL\tCLOUD\tHWMGR\tGROUP\tPOOL\tSIZE\tALLOCATED\tPROVISIONED")
+	for _, allocation := range s.Allocations {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\n", allocation.Namespace, allocation.NodePool,
+			allocation.CloudID, allocation.HwMgrId, allocation.NodeGroup, allocation.ResourcePoolId, allocation.Size,
+			allocation.Allocated, allocation.Status)
+	}
+
+	fmt.Fprintln(w, "\nERRORS")
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tCONDITION\tREASON\tSINCE\tMESSAGE")
+	for _, e := range s.Errors {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Kind, e.Namespace, e.Name, e.Condition, e.Reason, e.Since,
+			e.Message)
+	}
+
+	_ = w.Flush()
+	return buf.String()
+}
+
+// formatCount formats an optional node count
+func formatCount(count *int) string {
+	if count == nil {
+		return "-"
+	}
+	return fmt.Sprint(*count)
+}
+
+// conditionSummary formats the status and reason of a condition
+func conditionSummary(condition *metav1.Condition) string {
+	if condition == nil {
+		return "Unknown"
+	}
+	return fmt.Sprintf("%s (%s)", condition.Status, condition.Reason)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// SummaryConfigMapName is the name of the ConfigMap, in the plugin namespace, holding the status summary
+	SummaryConfigMapName = "hwmgr-plugin-status-summary"
+
+	// SummaryTextKey is the ConfigMap key holding the summary rendered as human-readable tables
+	SummaryTextKey = "summary.txt"
+
+	// SummaryJSONKey is the ConfigMap key holding the summary in JSON format, for dashboards
+	SummaryJSONKey = "summary.json"
+)
+
+// StatusSummaryReconciler maintains a summary of the clouds, resource pools, allocations and error conditions of the
+// plugin in a single ConfigMap, so that it can be surfaced without fetching each of the plugin CRs
+type StatusSummaryReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Logger    *slog.Logger
+	Namespace string
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;patch;watch
+
+// Reconcile refreshes the status summary ConfigMap
+func (r *StatusSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx = logging.NewReconcileContext(ctx)
+	result = utils.DoNotRequeue()
+
+	if err := r.update(ctx); err != nil {
+		r.Logger.InfoContext(ctx, "Status summary update failed", slog.String("error", err.Error()))
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	return
+}
+
+func (r *StatusSummaryReconciler) update(ctx context.Context) error {
+	summary, err := Collect(ctx, r.Client, r.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to collect status summary: %w", err)
+	}
+
+	// The summary is only rewritten when something other than its generation time has changed, to avoid an update
+	// for every Node status change that does not affect it
+	existing := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: SummaryConfigMapName, Namespace: r.Namespace}, existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get status summary configmap: %w", err)
+		}
+	} else {
+		var previous Summary
+		if err := json.Unmarshal([]byte(existing.Data[SummaryJSONKey]), &previous); err == nil {
+			previous.GeneratedAt = summary.GeneratedAt
+			if reflect.DeepEqual(&previous, summary) {
+				return nil
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status summary: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SummaryConfigMapName,
+			Namespace: r.Namespace,
+		},
+		Data: map[string]string{
+			SummaryTextKey: summary.Render(),
+			SummaryJSONKey: string(data),
+		},
+	}
+
+	if err := utils.ApplyK8sCR(ctx, r.Client, cm, nil); err != nil {
+		return fmt.Errorf("failed to update status summary configmap: %w", err)
+	}
+
+	r.Logger.DebugContext(ctx, "Updated status summary",
+		slog.Int("clouds", len(summary.Clouds)),
+		slog.Int("errors", len(summary.Errors)))
+
+	return nil
+}
+
+// mapToSummary maps any change to the single status summary request
+func (r *StatusSummaryReconciler) mapToSummary(ctx context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: SummaryConfigMapName, Namespace: r.Namespace}}}
+}
+
+// SetupWithManager sets up the controller with the Manager. All changes to the summarized CRs, as well as to the
+// summary ConfigMap itself, are collapsed onto a single request.
+func (r *StatusSummaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isSummary := func(obj client.Object) bool {
+		return obj.GetName() == SummaryConfigMapName && obj.GetNamespace() == r.Namespace
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("status-summary").
+		Watches(&pluginv1alpha1.HardwareManager{}, handler.EnqueueRequestsFromMapFunc(r.mapToSummary)).
+		Watches(&hwmgmtv1alpha1.NodePool{}, handler.EnqueueRequestsFromMapFunc(r.mapToSummary)).
+		Watches(&hwmgmtv1alpha1.Node{}, handler.EnqueueRequestsFromMapFunc(r.mapToSummary)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.mapToSummary),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isSummary))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create status summary controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summary
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// summaryClient lists the HardwareManager, NodePool and Node CRs summarized
+type summaryClient struct {
+	client.Client
+	hwmgrs    []pluginv1alpha1.HardwareManager
+	nodepools []hwmgmtv1alpha1.NodePool
+	nodes     []hwmgmtv1alpha1.Node
+}
+
+func (c *summaryClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	switch typed := list.(type) {
+	case *pluginv1alpha1.HardwareManagerList:
+		typed.Items = c.hwmgrs
+	case *hwmgmtv1alpha1.NodePoolList:
+		typed.Items = c.nodepools
+	case *hwmgmtv1alpha1.NodeList:
+		typed.Items = c.nodes
+	}
+	return nil
+}
+
+var _ = Describe("Status summary", func() {
+	var (
+		ctx   context.Context
+		c     *summaryClient
+		since = metav1.NewTime(time.Date(2024, 11, 5, 14, 2, 11, 0, time.UTC))
+	)
+
+	failed := []metav1.Condition{{
+		Type:               string(hwmgmtv1alpha1.Provisioned),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(hwmgmtv1alpha1.Failed),
+		Message:            "allocation failed",
+		LastTransitionTime: since,
+	}}
+	completed := []metav1.Condition{{
+		Type:   string(hwmgmtv1alpha1.Provisioned),
+		Status: metav1.ConditionTrue,
+		Reason: string(hwmgmtv1alpha1.Completed),
+	}}
+
+	newNodePool := func(namespace, name, cloudID string, conditions []metav1.Condition) hwmgmtv1alpha1.NodePool {
+		return hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: hwmgmtv1alpha1.NodePoolSpec{
+				CloudID: cloudID,
+				HwMgrId: "loopback",
+				NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+					{NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "master", ResourcePoolId: "master-pool"}, Size: 2},
+				},
+			},
+			Status: hwmgmtv1alpha1.NodePoolStatus{Conditions: conditions},
+		}
+	}
+
+	newNode := func(namespace, name, nodepool string) hwmgmtv1alpha1.Node {
+		return hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       hwmgmtv1alpha1.NodeSpec{NodePool: nodepool, GroupName: "master"},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		split := newNodePool("tenant-a", "cloud-3", "cloud-3", failed)
+		split.Spec.Extensions = map[string]string{utils.NodeGroupHwMgrKey: "{}"}
+
+		c = &summaryClient{
+			hwmgrs: []pluginv1alpha1.HardwareManager{{
+				ObjectMeta: metav1.ObjectMeta{Name: "loopback", Namespace: "plugin"},
+				Spec:       pluginv1alpha1.HardwareManagerSpec{AdaptorID: pluginv1alpha1.SupportedAdaptors.Loopback},
+				Status: pluginv1alpha1.HardwareManagerStatus{
+					ResourcePools: pluginv1alpha1.PerSiteResourcePoolList{
+						"site-1": {"worker-pool", "master-pool"},
+					},
+					Capacity: &pluginv1alpha1.CapacityStatus{
+						ResourcePools: []pluginv1alpha1.ResourcePoolCapacity{
+							{ResourcePoolId: "master-pool", TotalNodes: 5, FreeNodes: 2, ReservedNodes: 1},
+						},
+					},
+				},
+			}},
+			// NodePools of the same name in different namespaces are summarized separately
+			nodepools: []hwmgmtv1alpha1.NodePool{
+				newNodePool("tenant-b", "cloud-1", "cloud-1", failed),
+				newNodePool("tenant-a", "cloud-1", "cloud-1", completed),
+				newNodePool("tenant-a", "cloud-2", "cloud-2", nil),
+				split,
+			},
+			nodes: []hwmgmtv1alpha1.Node{
+				newNode("tenant-a", "node1", "cloud-1"),
+				newNode("tenant-a", "node2", "cloud-1"),
+				newNode("tenant-b", "node3", "cloud-1"),
+			},
+		}
+	})
+
+	It("summarizes the NodePools of each namespace", func() {
+		s, err := Collect(ctx, c, "plugin")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(s.Clouds).To(Equal([]CloudSummary{
+			{CloudID: "cloud-1", HwMgrs: []string{"loopback"}, NodePools: 2, Provisioned: 1, Nodes: 3},
+			{CloudID: "cloud-2", HwMgrs: []string{"loopback"}, NodePools: 1, Nodes: 0},
+		}))
+
+		Expect(s.Allocations).To(Equal([]AllocationSummary{
+			{Namespace: "tenant-a", NodePool: "cloud-1", CloudID: "cloud-1", HwMgrId: "loopback", NodeGroup: "master",
+				ResourcePoolId: "master-pool", Size: 2, Allocated: 2, Status: "True (Completed)"},
+			{Namespace: "tenant-a", NodePool: "cloud-2", CloudID: "cloud-2", HwMgrId: "loopback", NodeGroup: "master",
+				ResourcePoolId: "master-pool", Size: 2, Allocated: 0, Status: "Unknown"},
+			{Namespace: "tenant-b", NodePool: "cloud-1", CloudID: "cloud-1", HwMgrId: "loopback", NodeGroup: "master",
+				ResourcePoolId: "master-pool", Size: 2, Allocated: 1, Status: "False (Failed)"},
+		}))
+
+		Expect(s.Errors).To(Equal([]ErrorSummary{{
+			Kind:      "NodePool",
+			Namespace: "tenant-b",
+			Name:      "cloud-1",
+			Condition: string(hwmgmtv1alpha1.Provisioned),
+			Reason:    string(hwmgmtv1alpha1.Failed),
+			Message:   "allocation failed",
+			Since:     "2024-11-05T14:02:11Z",
+		}}))
+	})
+
+	It("reports the errors of each CR of the same name", func() {
+		c.nodepools[1].Status.Conditions = failed
+		c.nodes[0].Name = "cloud-1"
+		c.nodes[0].Status.Conditions = failed
+
+		s, err := Collect(ctx, c, "plugin")
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, e := range s.Errors {
+			names = append(names, e.Kind+" "+e.Namespace+"/"+e.Name)
+		}
+		Expect(names).To(Equal([]string{"Node tenant-a/cloud-1", "NodePool tenant-a/cloud-1", "NodePool tenant-b/cloud-1"}))
+	})
+
+	It("summarizes the resource pools with their capacity where reported", func() {
+		s, err := Collect(ctx, c, "plugin")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(s.Pools).To(HaveLen(2))
+		Expect(s.Pools[0].ResourcePoolId).To(Equal("master-pool"))
+		Expect(s.Pools[0].TotalNodes).To(HaveValue(Equal(5)))
+		Expect(s.Pools[0].FreeNodes).To(HaveValue(Equal(2)))
+		Expect(s.Pools[0].ReservedNodes).To(HaveValue(Equal(1)))
+		Expect(s.Pools[1].ResourcePoolId).To(Equal("worker-pool"))
+		Expect(s.Pools[1].TotalNodes).To(BeNil())
+	})
+
+	It("renders the summary as tables", func() {
+		s, err := Collect(ctx, c, "plugin")
+		Expect(err).ToNot(HaveOccurred())
+		s.GeneratedAt = "2024-11-05T14:02:11Z"
+
+		Expect(strings.Split(s.Render(), "\n")).To(Equal([]string{
+			"Generated at 2024-11-05T14:02:11Z",
+			"",
+			"CLOUDS",
+			"CLOUD    HWMGRS    NODEPOOLS  PROVISIONED  NODES",
+			"cloud-1  loopback  2          1            3",
+			"cloud-2  loopback  1          0            0",
+			"",
+			"POOLS",
+			"HWMGR     ADAPTOR   SITE    POOL         TOTAL  FREE  RESERVED",
+			"loopback  loopback  site-1  master-pool  5      2     1",
+			"loopback  loopback  site-1  worker-pool  -      -     -",
+			"",
+			"ALLOCATIONS",
+			"NAMESPACE  NODEPOOL  CLOUD    HWMGR     GROUP   POOL         SIZE  ALLOCATED  PROVISIONED",
+			"tenant-a   cloud-1   cloud-1  loopback  master  master-pool  2     2          True (Completed)",
+			"tenant-a   cloud-2   cloud-2  loopback  master  master-pool  2     0          Unknown",
+			"tenant-b   cloud-1   cloud-1  loopback  master  master-pool  2     1          False (Failed)",
+			"",
+			"ERRORS",
+			"KIND      NAMESPACE  NAME     CONDITION    REASON  SINCE                 MESSAGE",
+			"NodePool  tenant-b   cloud-1  Provisioned  Failed  2024-11-05T14:02:11Z  allocation failed",
+			"",
+		}))
+	})
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// AdoptNodesKey is the NodePool extensions key that lists the backend node IDs to be imported, keyed by nodegroup
+	// name, for nodes already allocated in the backend outside the plugin
+	AdoptNodesKey = "adoptNodes"
+
+	// AdoptedAnnotation marks a Node CR that was imported from an existing backend allocation
+	AdoptedAnnotation = "hwmgr-plugin.oran.openshift.io/adopted"
+)
+
+// GetNodePoolAdoptedNodes parses the backend node IDs to be adopted from the NodePool extensions
+func GetNodePoolAdoptedNodes(nodepool *hwmgmtv1alpha1.NodePool) (map[string][]string, error) {
+	data, exists := nodepool.Spec.Extensions[AdoptNodesKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var adopted map[string][]string
+	if err := yaml.Unmarshal([]byte(data), &adopted); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", AdoptNodesKey, err.Error())
+	}
+
+	return adopted, nil
+}
+
+// GetNodeGroupAdoptedNodes returns the backend node IDs to be adopted for a nodegroup
+func GetNodeGroupAdoptedNodes(nodepool *hwmgmtv1alpha1.NodePool, groupname string) ([]string, error) {
+	adopted, err := GetNodePoolAdoptedNodes(nodepool)
+	if err != nil {
+		return nil, err
+	}
+
+	return adopted[groupname], nil
+}
+
+// ValidateNodePoolAdoptedNodes validates that the adopted nodes reference defined nodegroups, fit within the size of
+// the nodegroup, and are not listed more than once
+func ValidateNodePoolAdoptedNodes(nodepool *hwmgmtv1alpha1.NodePool) error {
+	adopted, err := GetNodePoolAdoptedNodes(nodepool)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for groupname, nodeIds := range adopted {
+		index := slices.IndexFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		})
+		if index == -1 {
+			return NewInputError("adopted nodes specified for unknown nodegroup %s", groupname)
+		}
+
+		if len(nodeIds) > nodepool.Spec.NodeGroup[index].Size {
+			return NewInputError("nodegroup %s has %d adopted nodes, exceeding its size of %d",
+				groupname, len(nodeIds), nodepool.Spec.NodeGroup[index].Size)
+		}
+
+		for _, nodeId := range nodeIds {
+			if nodeId == "" {
+				return NewInputError("empty adopted node ID for nodegroup %s", groupname)
+			}
+			if seen[nodeId] {
+				return NewInputError("node %s is adopted more than once", nodeId)
+			}
+			seen[nodeId] = true
+		}
+	}
+
+	return nil
+}
+
+// SetNodeAdopted marks the Node CR as imported from an existing backend allocation
+func SetNodeAdopted(node client.Object) {
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[AdoptedAnnotation] = "true"
+	node.SetAnnotations(annotations)
+}
+
+// IsNodeAdopted returns true if the Node CR was imported from an existing backend allocation
+func IsNodeAdopted(node client.Object) bool {
+	return node.GetAnnotations()[AdoptedAnnotation] == "true"
+}
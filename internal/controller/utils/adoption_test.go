@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node adoption", func() {
+	It("parses the adopted nodes for each nodegroup", func() {
+		nodepool := newTestNodePool(map[string]string{
+			AdoptNodesKey: "master: [\"node-1\"]\n",
+		})
+		Expect(ValidateNodePoolAdoptedNodes(nodepool)).To(Succeed())
+
+		nodeIds, err := GetNodeGroupAdoptedNodes(nodepool, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeIds).To(Equal([]string{"node-1"}))
+
+		nodeIds, err = GetNodeGroupAdoptedNodes(nodepool, "worker")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodeIds).To(BeEmpty())
+	})
+
+	It("rejects invalid adoption lists", func() {
+		for _, data := range []string{
+			"unknown: [\"node-1\"]\n",
+			"master: [\"node-1\", \"node-2\"]\n",
+			"master: [\"\"]\n",
+			"master: [\"node-1\"]\nworker: [\"node-1\"]\n",
+			"master: node-1\n",
+		} {
+			nodepool := newTestNodePool(map[string]string{AdoptNodesKey: data})
+			nodepool.Spec.NodeGroup[1].Size = 1
+			Expect(ValidateNodePoolAdoptedNodes(nodepool)).ToNot(Succeed(), data)
+		}
+	})
+
+	It("marks adopted nodes", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		Expect(IsNodeAdopted(node)).To(BeFalse())
+		SetNodeAdopted(node)
+		Expect(IsNodeAdopted(node)).To(BeTrue())
+	})
+})
@@ -0,0 +1,230 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// ActiveAlarmsAnnotation records, on a Node, the hardware alarms raised for the node that have not been cleared,
+	// keyed by category
+	ActiveAlarmsAnnotation = "hwmgr-plugin.oran.openshift.io/activeAlarms"
+
+	// Extensions of the alarm event records, linking the alarm to the Node
+	AlarmExtensionNodeName  = "nodeName"
+	AlarmExtensionNamespace = "namespace"
+	AlarmExtensionNodePool  = "nodePool"
+	AlarmExtensionHwMgrId   = "hwMgrId"
+	AlarmExtensionCategory  = "category"
+	AlarmExtensionMessage   = "message"
+	AlarmExtensionMessageId = "messageId"
+	AlarmExtensionOrigin    = "origin"
+)
+
+// AlarmPerceivedSeverity is the severity of an alarm, as defined by the O2IMS alarm model
+type AlarmPerceivedSeverity int
+
+// O2IMS alarm perceived severities
+const (
+	AlarmSeverityCritical      AlarmPerceivedSeverity = 0
+	AlarmSeverityMajor         AlarmPerceivedSeverity = 1
+	AlarmSeverityMinor         AlarmPerceivedSeverity = 2
+	AlarmSeverityWarning       AlarmPerceivedSeverity = 3
+	AlarmSeverityIndeterminate AlarmPerceivedSeverity = 4
+	AlarmSeverityCleared       AlarmPerceivedSeverity = 5
+)
+
+// alarmNamespace is the namespace of the name-based UUIDs identifying the alarm records and definitions, so that the
+// records of an alarm carry the same ID from when it is raised until it is cleared
+var alarmNamespace = uuid.MustParse("371bf288-ca96-403f-a464-a8bfe1e23e5c")
+
+// AlarmEventRecord is the alarm event record posted to the O2IMS alarm subsystem for a hardware alarm of a node. The
+// resource ID is the backend node ID, as reported in the inventory export.
+type AlarmEventRecord struct {
+	AlarmEventRecordID string                 `json:"alarmEventRecordId"`
+	ResourceID         string                 `json:"resourceID"`
+	AlarmDefinitionID  string                 `json:"alarmDefinitionID"`
+	ProbableCauseID    string                 `json:"probableCauseID"`
+	AlarmRaisedTime    metav1.Time            `json:"alarmRaisedTime"`
+	AlarmChangedTime   *metav1.Time           `json:"alarmChangedTime,omitempty"`
+	AlarmClearedTime   *metav1.Time           `json:"alarmClearedTime,omitempty"`
+	PerceivedSeverity  AlarmPerceivedSeverity `json:"perceivedSeverity"`
+	Extensions         map[string]string      `json:"extensions,omitempty"`
+}
+
+// ActiveAlarm is a hardware alarm of a node that has not been cleared
+type ActiveAlarm struct {
+	RaisedTime metav1.Time            `json:"raisedTime"`
+	Severity   AlarmPerceivedSeverity `json:"severity"`
+	Message    string                 `json:"message"`
+}
+
+// GetAlarmSeverity returns the perceived severity of an alarm for the severity of a BMC event
+func GetAlarmSeverity(severity string) AlarmPerceivedSeverity {
+	switch severity {
+	case BMCEventSeverityCritical:
+		return AlarmSeverityCritical
+	case BMCEventSeverityWarning:
+		return AlarmSeverityWarning
+	case BMCEventSeverityOK:
+		return AlarmSeverityCleared
+	default:
+		return AlarmSeverityIndeterminate
+	}
+}
+
+// GetAlarmDefinitionID returns the ID of the alarm definition for a category of hardware alarms
+func GetAlarmDefinitionID(category BMCEventCategory) string {
+	return uuid.NewSHA1(alarmNamespace, []byte("definition/"+string(category))).String()
+}
+
+// GetAlarmProbableCauseID returns the ID of the probable cause for a category of hardware alarms
+func GetAlarmProbableCauseID(category BMCEventCategory) string {
+	return uuid.NewSHA1(alarmNamespace, []byte("probableCause/"+string(category))).String()
+}
+
+// GetAlarmEventRecordID returns the ID of the alarm record for a category of hardware alarms of a node
+func GetAlarmEventRecordID(node *hwmgmtv1alpha1.Node, category BMCEventCategory) string {
+	return uuid.NewSHA1(alarmNamespace, []byte("record/"+string(node.UID)+"/"+string(category))).String()
+}
+
+// GetNodeActiveAlarms parses the active alarms of the node from its annotations. Invalid annotations are treated as
+// having no active alarms.
+func GetNodeActiveAlarms(node *hwmgmtv1alpha1.Node) map[BMCEventCategory]ActiveAlarm {
+	alarms := make(map[BMCEventCategory]ActiveAlarm)
+	if data := node.GetAnnotations()[ActiveAlarmsAnnotation]; data != "" {
+		_ = json.Unmarshal([]byte(data), &alarms)
+	}
+	return alarms
+}
+
+// setNodeActiveAlarms records the active alarms of the node in its annotations
+func setNodeActiveAlarms(node *hwmgmtv1alpha1.Node, alarms map[BMCEventCategory]ActiveAlarm) {
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if len(alarms) == 0 {
+		delete(annotations, ActiveAlarmsAnnotation)
+	} else {
+		data, _ := json.Marshal(alarms)
+		annotations[ActiveAlarmsAnnotation] = string(data)
+	}
+	node.SetAnnotations(annotations)
+}
+
+// newAlarmEventRecord returns the alarm record for a category of hardware alarms of the node
+func newAlarmEventRecord(node *hwmgmtv1alpha1.Node, category BMCEventCategory, alarm ActiveAlarm) AlarmEventRecord {
+	return AlarmEventRecord{
+		AlarmEventRecordID: GetAlarmEventRecordID(node, category),
+		ResourceID:         node.Spec.HwMgrNodeId,
+		AlarmDefinitionID:  GetAlarmDefinitionID(category),
+		ProbableCauseID:    GetAlarmProbableCauseID(category),
+		AlarmRaisedTime:    alarm.RaisedTime,
+		PerceivedSeverity:  alarm.Severity,
+		Extensions: map[string]string{
+			AlarmExtensionNodeName:  node.Name,
+			AlarmExtensionNamespace: node.Namespace,
+			AlarmExtensionNodePool:  node.Spec.NodePool,
+			AlarmExtensionHwMgrId:   node.Spec.HwMgrId,
+			AlarmExtensionCategory:  string(category),
+			AlarmExtensionMessage:   alarm.Message,
+		},
+	}
+}
+
+// UpdateNodeHardwareAlarm reflects an event reported by the BMC of the node in its active alarms, returning the alarm
+// record to notify, or nil if the alarms are unchanged. A significant event raises, or changes, the alarm of its
+// category, and an OK event clears it. Each category is tracked separately, so the alarms are not affected by the
+// events of other categories. The annotations are not updated on the cluster.
+func UpdateNodeHardwareAlarm(node *hwmgmtv1alpha1.Node, event BMCEvent, now time.Time) *AlarmEventRecord {
+	alarms := GetNodeActiveAlarms(node)
+	category := event.Category()
+	current, active := alarms[category]
+	timestamp := metav1.NewTime(now.UTC().Truncate(time.Second))
+
+	if event.IsSignificant() {
+		alarm := ActiveAlarm{
+			RaisedTime: timestamp,
+			Severity:   GetAlarmSeverity(event.Severity),
+			Message:    event.String(),
+		}
+		if active {
+			if current.Severity == alarm.Severity && current.Message == alarm.Message {
+				return nil
+			}
+			alarm.RaisedTime = current.RaisedTime
+		}
+		alarms[category] = alarm
+		setNodeActiveAlarms(node, alarms)
+
+		record := newAlarmEventRecord(node, category, alarm)
+		record.Extensions[AlarmExtensionMessageId] = event.MessageId
+		record.Extensions[AlarmExtensionOrigin] = event.Origin
+		if active {
+			record.AlarmChangedTime = &timestamp
+		}
+		return &record
+	}
+
+	if event.Severity != BMCEventSeverityOK || !active {
+		return nil
+	}
+
+	delete(alarms, category)
+	setNodeActiveAlarms(node, alarms)
+
+	record := newAlarmEventRecord(node, category, current)
+	record.PerceivedSeverity = AlarmSeverityCleared
+	record.AlarmChangedTime = &timestamp
+	record.AlarmClearedTime = &timestamp
+	record.Extensions[AlarmExtensionMessage] = "Cleared by " + event.String()
+	return &record
+}
+
+// ClearNodeHardwareAlarms clears the active alarms of the node, such as when it is deleted, returning the alarm records
+// to notify. The annotations are not updated on the cluster.
+func ClearNodeHardwareAlarms(node *hwmgmtv1alpha1.Node, now time.Time) []AlarmEventRecord {
+	alarms := GetNodeActiveAlarms(node)
+	if len(alarms) == 0 {
+		return nil
+	}
+
+	timestamp := metav1.NewTime(now.UTC().Truncate(time.Second))
+	var records []AlarmEventRecord
+	for _, category := range []BMCEventCategory{BMCEventCategoryThermal, BMCEventCategoryPower, BMCEventCategoryHealth} {
+		alarm, active := alarms[category]
+		if !active {
+			continue
+		}
+		record := newAlarmEventRecord(node, category, alarm)
+		record.PerceivedSeverity = AlarmSeverityCleared
+		record.AlarmChangedTime = &timestamp
+		record.AlarmClearedTime = &timestamp
+		records = append(records, record)
+	}
+
+	setNodeActiveAlarms(node, nil)
+	return records
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Hardware alarms", func() {
+	newNode := func() *hwmgmtv1alpha1.Node {
+		return &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: "test", UID: "6c1f0d52-4a9e-4c52-9b1e-6b1a2b0c3d4e"},
+			Spec: hwmgmtv1alpha1.NodeSpec{
+				NodePool:    "cloud-1",
+				HwMgrId:     "loopback-1",
+				HwMgrNodeId: "dummy-sp-64g-0",
+			},
+		}
+	}
+
+	fanFailure := BMCEvent{MessageId: "Fan.1.0.FanFailed", Severity: BMCEventSeverityWarning, Message: "Fan 2 failed",
+		Origin: "/redfish/v1/Chassis/1/Thermal"}
+	raised := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	It("maps the BMC event severities to the alarm severities", func() {
+		Expect(GetAlarmSeverity(BMCEventSeverityCritical)).To(Equal(AlarmSeverityCritical))
+		Expect(GetAlarmSeverity(BMCEventSeverityWarning)).To(Equal(AlarmSeverityWarning))
+		Expect(GetAlarmSeverity(BMCEventSeverityOK)).To(Equal(AlarmSeverityCleared))
+		Expect(GetAlarmSeverity("Unknown")).To(Equal(AlarmSeverityIndeterminate))
+	})
+
+	It("raises an alarm linked to the node for a significant event", func() {
+		node := newNode()
+		record := UpdateNodeHardwareAlarm(node, fanFailure, raised)
+		Expect(record).ToNot(BeNil())
+		Expect(record.AlarmEventRecordID).To(Equal(GetAlarmEventRecordID(node, BMCEventCategoryThermal)))
+		Expect(record.AlarmDefinitionID).To(Equal(GetAlarmDefinitionID(BMCEventCategoryThermal)))
+		Expect(record.ProbableCauseID).To(Equal(GetAlarmProbableCauseID(BMCEventCategoryThermal)))
+		Expect(record.AlarmDefinitionID).ToNot(Equal(GetAlarmDefinitionID(BMCEventCategoryPower)))
+		Expect(record.ResourceID).To(Equal("dummy-sp-64g-0"))
+		Expect(record.PerceivedSeverity).To(Equal(AlarmSeverityWarning))
+		Expect(record.AlarmRaisedTime.Time).To(BeTemporally("==", raised))
+		Expect(record.AlarmChangedTime).To(BeNil())
+		Expect(record.Extensions).To(HaveKeyWithValue(AlarmExtensionNodeName, "node1"))
+		Expect(record.Extensions).To(HaveKeyWithValue(AlarmExtensionNodePool, "cloud-1"))
+		Expect(record.Extensions).To(HaveKeyWithValue(AlarmExtensionMessageId, "Fan.1.0.FanFailed"))
+		Expect(GetNodeActiveAlarms(node)).To(HaveKey(BMCEventCategoryThermal))
+
+		// A repeated event does not change the alarm
+		Expect(UpdateNodeHardwareAlarm(node, fanFailure, raised.Add(time.Minute))).To(BeNil())
+	})
+
+	It("changes and clears the alarm of each category separately", func() {
+		node := newNode()
+		Expect(UpdateNodeHardwareAlarm(node, fanFailure, raised)).ToNot(BeNil())
+
+		psuFailure := BMCEvent{MessageId: "Power.1.0.PowerSupplyFailed", Severity: BMCEventSeverityCritical}
+		record := UpdateNodeHardwareAlarm(node, psuFailure, raised.Add(time.Minute))
+		Expect(record.AlarmEventRecordID).To(Equal(GetAlarmEventRecordID(node, BMCEventCategoryPower)))
+		Expect(record.PerceivedSeverity).To(Equal(AlarmSeverityCritical))
+
+		critical := fanFailure
+		critical.Severity = BMCEventSeverityCritical
+		record = UpdateNodeHardwareAlarm(node, critical, raised.Add(2*time.Minute))
+		Expect(record.AlarmEventRecordID).To(Equal(GetAlarmEventRecordID(node, BMCEventCategoryThermal)))
+		Expect(record.PerceivedSeverity).To(Equal(AlarmSeverityCritical))
+		Expect(record.AlarmRaisedTime.Time).To(BeTemporally("==", raised))
+		Expect(record.AlarmChangedTime.Time).To(BeTemporally("==", raised.Add(2*time.Minute)))
+
+		recovered := BMCEvent{MessageId: "Fan.1.0.FanRestored", Severity: BMCEventSeverityOK}
+		record = UpdateNodeHardwareAlarm(node, recovered, raised.Add(3*time.Minute))
+		Expect(record.AlarmEventRecordID).To(Equal(GetAlarmEventRecordID(node, BMCEventCategoryThermal)))
+		Expect(record.PerceivedSeverity).To(Equal(AlarmSeverityCleared))
+		Expect(record.AlarmRaisedTime.Time).To(BeTemporally("==", raised))
+		Expect(record.AlarmClearedTime.Time).To(BeTemporally("==", raised.Add(3*time.Minute)))
+		Expect(GetNodeActiveAlarms(node)).To(HaveLen(1))
+
+		// An OK event without an active alarm is ignored
+		Expect(UpdateNodeHardwareAlarm(node, recovered, raised.Add(4*time.Minute))).To(BeNil())
+	})
+
+	It("clears the active alarms of a node", func() {
+		node := newNode()
+		Expect(ClearNodeHardwareAlarms(node, raised)).To(BeEmpty())
+
+		UpdateNodeHardwareAlarm(node, fanFailure, raised)
+		UpdateNodeHardwareAlarm(node, BMCEvent{MessageId: "Power.1.0.PowerSupplyFailed", Severity: BMCEventSeverityCritical}, raised)
+		records := ClearNodeHardwareAlarms(node, raised.Add(time.Hour))
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Extensions).To(HaveKeyWithValue(AlarmExtensionCategory, string(BMCEventCategoryThermal)))
+		Expect(records[1].Extensions).To(HaveKeyWithValue(AlarmExtensionCategory, string(BMCEventCategoryPower)))
+		for _, record := range records {
+			Expect(record.PerceivedSeverity).To(Equal(AlarmSeverityCleared))
+		}
+		Expect(node.Annotations).ToNot(HaveKey(ActiveAlarmsAnnotation))
+	})
+})
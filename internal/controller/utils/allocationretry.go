@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"time"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// AllocationFailuresAnnotation records, on a NodePool, the times of its transient allocation failures within the
+	// retry window, as a JSON list
+	AllocationFailuresAnnotation = "hwmgr-plugin.oran.openshift.io/allocationFailures"
+
+	DefaultAllocationMaxRetries  = 5
+	DefaultAllocationRetryWindow = 1 * time.Hour
+
+	// The delay before retrying an allocation doubles with each failure, from the base delay up to the max delay
+	allocationRetryBaseDelay = 15 * time.Second
+	allocationRetryMaxDelay  = 5 * time.Minute
+)
+
+// GetAllocationRetryBudget returns the number of allocation retries allowed for a NodePool within the retry window
+func GetAllocationRetryBudget(hwmgr *pluginv1alpha1.HardwareManager) (int, time.Duration) {
+	maxRetries, window := DefaultAllocationMaxRetries, DefaultAllocationRetryWindow
+	if config := hwmgr.Spec.AllocationRetry; config != nil {
+		if config.MaxRetries != nil {
+			maxRetries = *config.MaxRetries
+		}
+		if config.Window != nil {
+			window = config.Window.Duration
+		}
+	}
+	return maxRetries, window
+}
+
+// GetAllocationFailures returns the recorded allocation failure times of the NodePool, oldest first
+func GetAllocationFailures(nodepool *hwmgmtv1alpha1.NodePool) []time.Time {
+	var failures []time.Time
+	if data := nodepool.GetAnnotations()[AllocationFailuresAnnotation]; data != "" {
+		// An invalid annotation is treated as no failures
+		_ = json.Unmarshal([]byte(data), &failures)
+	}
+	return failures
+}
+
+// RecordAllocationFailure records an allocation failure on the NodePool, dropping any failures that have aged out of
+// the retry window, and returns the number of failures within the window, including the new one. The NodePool is not
+// updated on the cluster.
+func RecordAllocationFailure(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool, now time.Time) int {
+	_, window := GetAllocationRetryBudget(hwmgr)
+
+	var failures []time.Time
+	for _, failure := range GetAllocationFailures(nodepool) {
+		if now.Sub(failure) < window {
+			failures = append(failures, failure)
+		}
+	}
+	failures = append(failures, now.UTC().Truncate(time.Second))
+
+	data, _ := json.Marshal(failures)
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AllocationFailuresAnnotation] = string(data)
+	nodepool.SetAnnotations(annotations)
+
+	return len(failures)
+}
+
+// ClearAllocationFailures removes the recorded allocation failures from the NodePool, returning true if there were
+// any. The NodePool is not updated on the cluster.
+func ClearAllocationFailures(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	annotations := nodepool.GetAnnotations()
+	if _, exists := annotations[AllocationFailuresAnnotation]; !exists {
+		return false
+	}
+	delete(annotations, AllocationFailuresAnnotation)
+	nodepool.SetAnnotations(annotations)
+	return true
+}
+
+// GetAllocationRetryDelay returns the backoff delay before the allocation is retried after the given number of
+// failures
+func GetAllocationRetryDelay(failures int) time.Duration {
+	delay := allocationRetryBaseDelay
+	for i := 1; i < failures && delay < allocationRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, allocationRetryMaxDelay)
+}
+
+// GetAllocationRetryWait returns the time remaining until the allocation of the NodePool may be retried, after the
+// backoff delay of its most recent failure, or zero if it may be retried now
+func GetAllocationRetryWait(nodepool *hwmgmtv1alpha1.NodePool, now time.Time) time.Duration {
+	failures := GetAllocationFailures(nodepool)
+	if len(failures) == 0 {
+		return 0
+	}
+
+	retryAt := failures[len(failures)-1].Add(GetAllocationRetryDelay(len(failures)))
+	if wait := retryAt.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
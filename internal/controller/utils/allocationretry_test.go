@@ -0,0 +1,77 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Allocation retry budget", func() {
+	now := time.Date(2024, 12, 11, 15, 0, 0, 0, time.UTC)
+
+	It("defaults the budget", func() {
+		maxRetries, window := GetAllocationRetryBudget(&pluginv1alpha1.HardwareManager{})
+		Expect(maxRetries).To(Equal(DefaultAllocationMaxRetries))
+		Expect(window).To(Equal(DefaultAllocationRetryWindow))
+
+		zero := 0
+		hwmgr := &pluginv1alpha1.HardwareManager{Spec: pluginv1alpha1.HardwareManagerSpec{
+			AllocationRetry: &pluginv1alpha1.AllocationRetryConfig{MaxRetries: &zero, Window: &metav1.Duration{Duration: time.Minute}},
+		}}
+		maxRetries, window = GetAllocationRetryBudget(hwmgr)
+		Expect(maxRetries).To(Equal(0))
+		Expect(window).To(Equal(time.Minute))
+	})
+
+	It("counts the failures within the window", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{Spec: pluginv1alpha1.HardwareManagerSpec{
+			AllocationRetry: &pluginv1alpha1.AllocationRetryConfig{Window: &metav1.Duration{Duration: 10 * time.Minute}},
+		}}
+		nodepool := newTestNodePool(nil)
+
+		Expect(RecordAllocationFailure(hwmgr, nodepool, now)).To(Equal(1))
+		Expect(RecordAllocationFailure(hwmgr, nodepool, now.Add(time.Minute))).To(Equal(2))
+		Expect(GetAllocationFailures(nodepool)).To(HaveLen(2))
+
+		// The first failures age out of the window
+		Expect(RecordAllocationFailure(hwmgr, nodepool, now.Add(10*time.Minute+30*time.Second))).To(Equal(2))
+
+		Expect(ClearAllocationFailures(nodepool)).To(BeTrue())
+		Expect(ClearAllocationFailures(nodepool)).To(BeFalse())
+		Expect(GetAllocationFailures(nodepool)).To(BeEmpty())
+	})
+
+	It("backs off between retries", func() {
+		Expect(GetAllocationRetryDelay(1)).To(Equal(15 * time.Second))
+		Expect(GetAllocationRetryDelay(3)).To(Equal(time.Minute))
+		Expect(GetAllocationRetryDelay(20)).To(Equal(5 * time.Minute))
+
+		nodepool := newTestNodePool(nil)
+		Expect(GetAllocationRetryWait(nodepool, now)).To(BeZero())
+
+		RecordAllocationFailure(&pluginv1alpha1.HardwareManager{}, nodepool, now)
+		RecordAllocationFailure(&pluginv1alpha1.HardwareManager{}, nodepool, now)
+		Expect(GetAllocationRetryWait(nodepool, now.Add(10*time.Second))).To(Equal(20 * time.Second))
+		Expect(GetAllocationRetryWait(nodepool, now.Add(time.Minute))).To(BeZero())
+	})
+})
@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// PluginFieldOwner is the field manager of the changes applied by the plugin controllers. The adaptors apply their
+// changes with a field manager of their own, from AdaptorFieldOwner.
+const PluginFieldOwner = "oran-hwmgr-plugin"
+
+// AdaptorFieldOwner returns the field manager of the changes applied by an adaptor
+func AdaptorFieldOwner(adaptorID string) string {
+	return PluginFieldOwner + "-" + adaptorID
+}
+
+// FieldOwnerClient is a client whose changes, applied with ApplyK8sCR and updated with UpdateK8sCRStatus, are recorded
+// against its field owner
+type FieldOwnerClient struct {
+	client.Client
+	owner string
+}
+
+// FieldOwnerClient implements client.Client. This ensures that we've conformed to the interface with a compile-time
+// check
+var _ client.Client = (*FieldOwnerClient)(nil)
+
+// NewFieldOwnerClient returns a client that wraps the base client, applying changes as the field owner
+func NewFieldOwnerClient(base client.Client, owner string) *FieldOwnerClient {
+	return &FieldOwnerClient{Client: base, owner: owner}
+}
+
+// FieldOwner returns the field manager of the changes applied with the client
+func (c *FieldOwnerClient) FieldOwner() string {
+	return c.owner
+}
+
+// GetFieldOwner returns the field manager of the changes applied with the client, defaulting to the PluginFieldOwner
+func GetFieldOwner(c client.Client) string {
+	if owned, ok := c.(interface{ FieldOwner() string }); ok {
+		return owned.FieldOwner()
+	}
+	return PluginFieldOwner
+}
+
+// newApplyObject returns the apply configuration for the given top-level fields of the object. Only the identity of
+// the object is kept from its metadata, unless the metadata is among the fields, in which case its labels, annotations
+// and owner references are applied as well. The resource version is not set, so the change is applied without an
+// optimistic lock.
+func newApplyObject(c client.Client, object client.Object, fields ...string) (*unstructured.Unstructured, error) {
+	gvk, err := c.GroupVersionKindFor(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GroupVersionKind of %s: %w", object.GetName(), err)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", object.GetName(), err)
+	}
+
+	apply := &unstructured.Unstructured{Object: make(map[string]interface{})}
+	for _, field := range fields {
+		if value, exists := content[field]; exists && field != "metadata" {
+			apply.Object[field] = pruneNulls(value)
+		}
+	}
+	apply.SetGroupVersionKind(gvk)
+	apply.SetName(object.GetName())
+	apply.SetNamespace(object.GetNamespace())
+	for _, field := range fields {
+		if field == "metadata" {
+			apply.SetLabels(object.GetLabels())
+			apply.SetAnnotations(object.GetAnnotations())
+			apply.SetOwnerReferences(object.GetOwnerReferences())
+		}
+	}
+
+	return apply, nil
+}
+
+// pruneNulls removes the null values from the maps of an unstructured value, such as those of unset timestamps, as
+// they would otherwise be applied as changes to the fields
+func pruneNulls(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typed {
+			if item == nil {
+				delete(typed, key)
+				continue
+			}
+			typed[key] = pruneNulls(item)
+		}
+	case []interface{}:
+		for i := range typed {
+			typed[i] = pruneNulls(typed[i])
+		}
+	}
+	return value
+}
+
+// syncAppliedObject copies the object returned by the API server into the object that was applied, so the caller
+// sees the resource version and any fields set by other field managers
+func syncAppliedObject(applied *unstructured.Unstructured, object client.Object) error {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, object); err != nil {
+		return fmt.Errorf("failed to convert %s: %w", object.GetName(), err)
+	}
+	return nil
+}
+
+// ApplyK8sCR creates or updates an object wholly owned by the plugin, such as a bmc-secret or configmap, with
+// server-side apply. The object status is not applied. Fields previously applied by the same field owner that are no
+// longer set are removed, and conflicts with other field managers are resolved in favour of the plugin.
+func ApplyK8sCR(ctx context.Context, c client.Client, object client.Object, ownerObject client.Object) error {
+	// We can set the owner reference only for objects that live in the same namespace, as cross namespace owners are
+	// forbidden
+	if ownerObject != nil && ownerObject.GetNamespace() == object.GetNamespace() {
+		if err := controllerutil.SetControllerReference(ownerObject, object, c.Scheme()); err != nil {
+			return fmt.Errorf("failed to set controller reference: %w", err)
+		}
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s: %w", object.GetName(), err)
+	}
+	fields := []string{"metadata"}
+	for field := range content {
+		if field != "apiVersion" && field != "kind" && field != "metadata" && field != "status" {
+			fields = append(fields, field)
+		}
+	}
+
+	apply, err := newApplyObject(c, object, fields...)
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(ctx, apply, client.Apply, client.FieldOwner(GetFieldOwner(c)), client.ForceOwnership); err != nil {
+		return fmt.Errorf("failed to apply object %s/%s: %w", object.GetNamespace(), object.GetName(), err)
+	}
+
+	return syncAppliedObject(apply, object)
+}
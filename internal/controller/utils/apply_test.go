@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// gvkClient resolves the GroupVersionKind of objects, for building apply configurations without an API server
+type gvkClient struct {
+	client.Client
+}
+
+func (c gvkClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	switch obj.(type) {
+	case *corev1.Secret:
+		return corev1.SchemeGroupVersion.WithKind("Secret"), nil
+	default:
+		return hwmgmtv1alpha1.GroupVersion.WithKind("Node"), nil
+	}
+}
+
+var _ = Describe("Server-side apply", func() {
+	It("reports the field owner of the client", func() {
+		Expect(GetFieldOwner(gvkClient{})).To(Equal(PluginFieldOwner))
+		Expect(GetFieldOwner(NewFieldOwnerClient(gvkClient{}, AdaptorFieldOwner("loopback")))).To(
+			Equal("oran-hwmgr-plugin-loopback"))
+	})
+
+	It("builds the apply configuration of the given fields", func() {
+		node := &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "node-1",
+				Namespace:       "test",
+				ResourceVersion: "42",
+				Labels:          map[string]string{"a": "b"},
+			},
+			Spec:   hwmgmtv1alpha1.NodeSpec{HwProfile: "profile"},
+			Status: hwmgmtv1alpha1.NodeStatus{HwProfile: "profile"},
+		}
+
+		apply, err := newApplyObject(gvkClient{}, node, "spec")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apply.GetAPIVersion()).To(Equal(hwmgmtv1alpha1.GroupVersion.String()))
+		Expect(apply.GetKind()).To(Equal("Node"))
+		Expect(apply.GetName()).To(Equal("node-1"))
+		Expect(apply.GetNamespace()).To(Equal("test"))
+		Expect(apply.GetResourceVersion()).To(BeEmpty())
+		Expect(apply.GetLabels()).To(BeEmpty())
+		Expect(apply.Object).ToNot(HaveKey("status"))
+		Expect(apply.Object["spec"]).To(HaveKeyWithValue("hwProfile", "profile"))
+	})
+
+	It("includes the metadata when requested", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "node-1-bmc-secret",
+				Namespace:   "test",
+				Labels:      map[string]string{"a": "b"},
+				Annotations: map[string]string{"c": "d"},
+			},
+			Data: map[string][]byte{"username": []byte("admin")},
+		}
+
+		apply, err := newApplyObject(gvkClient{}, secret, "metadata", "data")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(apply.GetLabels()).To(Equal(map[string]string{"a": "b"}))
+		Expect(apply.GetAnnotations()).To(Equal(map[string]string{"c": "d"}))
+		Expect(apply.Object["data"]).To(HaveKeyWithValue("username", "YWRtaW4="))
+		Expect(apply.Object["metadata"]).ToNot(HaveKey("creationTimestamp"))
+	})
+
+	It("prunes null values", func() {
+		value := map[string]interface{}{
+			"a": nil,
+			"b": map[string]interface{}{"c": nil, "d": "e"},
+			"f": []interface{}{map[string]interface{}{"g": nil}},
+		}
+		Expect(pruneNulls(value)).To(Equal(map[string]interface{}{
+			"b": map[string]interface{}{"d": "e"},
+			"f": []interface{}{map[string]interface{}{}},
+		}))
+	})
+})
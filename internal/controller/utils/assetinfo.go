@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Annotations publishing the asset details of a node, as reported by the backend. The Node status is defined by the
+// O2IMS API, so these are recorded as annotations on the Node CR for inventory reconciliation with asset databases.
+const (
+	SerialNumberAnnotation = "hwmgr-plugin.oran.openshift.io/serialNumber"
+	AssetTagAnnotation     = "hwmgr-plugin.oran.openshift.io/assetTag"
+	ModelAnnotation        = "hwmgr-plugin.oran.openshift.io/model"
+	VendorAnnotation       = "hwmgr-plugin.oran.openshift.io/vendor"
+)
+
+// NodeAssetInfo holds the asset details of a node. Empty fields are not reported by the backend.
+type NodeAssetInfo struct {
+	SerialNumber string
+	AssetTag     string
+	Model        string
+	Vendor       string
+}
+
+func (info NodeAssetInfo) annotations() map[string]string {
+	return map[string]string{
+		SerialNumberAnnotation: info.SerialNumber,
+		AssetTagAnnotation:     info.AssetTag,
+		ModelAnnotation:        info.Model,
+		VendorAnnotation:       info.Vendor,
+	}
+}
+
+// GetNodeAssetInfo returns the asset details published on the node
+func GetNodeAssetInfo(node client.Object) NodeAssetInfo {
+	annotations := node.GetAnnotations()
+	return NodeAssetInfo{
+		SerialNumber: annotations[SerialNumberAnnotation],
+		AssetTag:     annotations[AssetTagAnnotation],
+		Model:        annotations[ModelAnnotation],
+		Vendor:       annotations[VendorAnnotation],
+	}
+}
+
+// SetNodeAssetInfo publishes the asset details on the node, removing any that are no longer reported, and returns true
+// if the annotations have changed. The node is not updated on the cluster.
+func SetNodeAssetInfo(node client.Object, info NodeAssetInfo) bool {
+	annotations := node.GetAnnotations()
+	changed := false
+	for key, value := range info.annotations() {
+		if annotations[key] == value {
+			continue
+		}
+		if value == "" {
+			delete(annotations, key)
+		} else {
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[key] = value
+		}
+		changed = true
+	}
+
+	if changed {
+		node.SetAnnotations(annotations)
+	}
+	return changed
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node asset info", func() {
+	It("publishes the asset details as annotations", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		info := NodeAssetInfo{SerialNumber: "SN0001", AssetTag: "TAG-1", Model: "R750", Vendor: "Dell Inc."}
+		Expect(SetNodeAssetInfo(node, info)).To(BeTrue())
+		Expect(GetNodeAssetInfo(node)).To(Equal(info))
+		Expect(node.Annotations).To(HaveKeyWithValue(SerialNumberAnnotation, "SN0001"))
+
+		Expect(SetNodeAssetInfo(node, info)).To(BeFalse())
+	})
+
+	It("removes details that are no longer reported", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Annotations = map[string]string{"other": "value"}
+		Expect(SetNodeAssetInfo(node, NodeAssetInfo{SerialNumber: "SN0001", AssetTag: "TAG-1"})).To(BeTrue())
+
+		Expect(SetNodeAssetInfo(node, NodeAssetInfo{SerialNumber: "SN0001"})).To(BeTrue())
+		Expect(node.Annotations).ToNot(HaveKey(AssetTagAnnotation))
+		Expect(node.Annotations).To(HaveKeyWithValue("other", "value"))
+
+		Expect(SetNodeAssetInfo(&hwmgmtv1alpha1.Node{}, NodeAssetInfo{})).To(BeFalse())
+	})
+})
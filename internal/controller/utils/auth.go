@@ -31,6 +31,7 @@ import (
 	"regexp"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	"golang.org/x/net/http/httpproxy"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 	"k8s.io/apimachinery/pkg/util/net"
@@ -62,6 +63,8 @@ type OAuthClientConfig struct {
 	Username string
 	// Password, for Password grant type
 	Password string
+	// Defines the proxy used for requests.  If not provided then the proxy is taken from the environment.
+	Proxy func(*http.Request) (*url.URL, error)
 }
 
 // Default values for backend URL and token:
@@ -158,10 +161,37 @@ func GetTransportWithCaBundle(config OAuthClientConfig, insecureSkipTLSVerify, l
 	}
 
 	if logMessages {
-		return LoggingRoundTripper{TLSClientConfig: tlsConfig}, nil
+		return LoggingRoundTripper{TLSClientConfig: tlsConfig, Proxy: config.Proxy}, nil
 	}
 
-	return net.SetTransportDefaults(&http.Transport{TLSClientConfig: tlsConfig}), nil
+	return net.SetTransportDefaults(&http.Transport{TLSClientConfig: tlsConfig, Proxy: config.Proxy}), nil
+}
+
+// GetProxyFunc returns the function selecting the proxy for a request, per the proxy configuration of a hardware
+// manager, or nil if no proxy is configured. The proxy environment of the process is not consulted.
+func GetProxyFunc(proxy *pluginv1alpha1.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == nil {
+		return nil, nil
+	}
+
+	for _, proxyUrl := range []string{proxy.HTTPProxy, proxy.HTTPSProxy} {
+		if proxyUrl == "" {
+			continue
+		}
+		if u, err := url.Parse(proxyUrl); err != nil || u.Host == "" {
+			return nil, NewInputError("invalid proxy URL: %s", proxyUrl)
+		}
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxy.HTTPProxy,
+		HTTPSProxy: proxy.HTTPSProxy,
+		NoProxy:    proxy.NoProxy,
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
 }
 
 // TODO: Determine whether to remove the message tracing altogether.
@@ -169,6 +199,7 @@ func GetTransportWithCaBundle(config OAuthClientConfig, insecureSkipTLSVerify, l
 // setting the loglevel of the utilsLog logger, so this needs some work here.
 type LoggingRoundTripper struct {
 	TLSClientConfig *tls.Config
+	Proxy           func(*http.Request) (*url.URL, error)
 }
 
 func redactObject(object interface{}) interface{} {
@@ -230,7 +261,8 @@ func (t LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 
 	// Do work before the request is sent
 	rt := http.Transport{
-		TLSClientConfig: t.TLSClientConfig}
+		TLSClientConfig: t.TLSClientConfig,
+		Proxy:           t.Proxy}
 	resp, err := rt.RoundTrip(req)
 	if err != nil {
 		return resp, err // nolint: wrapcheck
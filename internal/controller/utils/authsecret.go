@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+const (
+	// AuthSecretChecksumAnnotation records, on a HardwareManager, the checksum of the credentials secret data that
+	// was last validated against the backend
+	AuthSecretChecksumAnnotation = "hwmgr-plugin.oran.openshift.io/authSecretChecksum"
+)
+
+// GetHardwareManagerAuthSecretName returns the name of the credentials secret referenced by a hardware manager, or an
+// empty string if none is referenced
+func GetHardwareManagerAuthSecretName(hwmgr *pluginv1alpha1.HardwareManager) string {
+	switch {
+	case hwmgr.Spec.DellData != nil:
+		return hwmgr.Spec.DellData.AuthSecret
+	case hwmgr.Spec.RestData != nil:
+		return hwmgr.Spec.RestData.AuthSecret
+	}
+	return ""
+}
+
+// ComputeSecretChecksum returns a checksum of the secret data, independent of the order of the keys
+func ComputeSecretChecksum(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		// Include the lengths so that key and value boundaries are unambiguous
+		fmt.Fprintf(hash, "%d:%s:%d:", len(key), key, len(secret.Data[key]))
+		hash.Write(secret.Data[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// GetAuthSecretChecksum returns the checksum of the credentials secret referenced by a hardware manager, or an empty
+// string if none is referenced
+func GetAuthSecretChecksum(ctx context.Context, c client.Client, hwmgr *pluginv1alpha1.HardwareManager) (string, error) {
+	name := GetHardwareManagerAuthSecretName(hwmgr)
+	if name == "" {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: hwmgr.Namespace}, secret); err != nil {
+		return "", fmt.Errorf("failed to get auth secret %s: %w", name, err)
+	}
+
+	return ComputeSecretChecksum(secret), nil
+}
+
+// IsAuthSecretChanged returns true if the checksum differs from the one last validated for the hardware manager
+func IsAuthSecretChanged(hwmgr *pluginv1alpha1.HardwareManager, checksum string) bool {
+	return hwmgr.GetAnnotations()[AuthSecretChecksumAnnotation] != checksum
+}
+
+// SetAuthSecretChecksum records the checksum of the validated credentials secret on the hardware manager
+func SetAuthSecretChecksum(ctx context.Context, c client.Client, hwmgr *pluginv1alpha1.HardwareManager, checksum string) error {
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	annotations := hwmgr.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AuthSecretChecksumAnnotation] = checksum
+	hwmgr.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, hwmgr, patch); err != nil {
+		return fmt.Errorf("failed to annotate HardwareManager %s with auth secret checksum: %w", hwmgr.Name, err)
+	}
+	return nil
+}
+
+// MapAuthSecretToHardwareManagers returns a handler mapping a secret to the hardware managers of the adaptor that
+// reference it as their credentials secret, if the secret data has changed since it was last validated
+func MapAuthSecretToHardwareManagers(c client.Client, adaptorID pluginv1alpha1.HardwareManagerAdaptorID) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		secret, ok := obj.(*corev1.Secret)
+		if !ok {
+			return nil
+		}
+
+		hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+		if err := c.List(ctx, hwmgrs, client.InNamespace(secret.Namespace)); err != nil {
+			utilsLog.InfoContext(ctx, "Unable to list HardwareManagers for auth secret", "secret", secret.Name, "error", err.Error())
+			return nil
+		}
+
+		checksum := ComputeSecretChecksum(secret)
+
+		var requests []reconcile.Request
+		for i := range hwmgrs.Items {
+			hwmgr := &hwmgrs.Items[i]
+			if hwmgr.Spec.AdaptorID == adaptorID &&
+				GetHardwareManagerAuthSecretName(hwmgr) == secret.Name &&
+				IsAuthSecretChanged(hwmgr, checksum) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(hwmgr)})
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Auth secret checksum", func() {
+	It("changes only when the secret data changes", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "hwmgr-auth"},
+			Data: map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("secret"),
+			},
+		}
+
+		checksum := ComputeSecretChecksum(secret)
+		Expect(checksum).ToNot(BeEmpty())
+
+		secret.Labels = map[string]string{"edited": "true"}
+		Expect(ComputeSecretChecksum(secret)).To(Equal(checksum))
+
+		secret.Data["password"] = []byte("rotated")
+		Expect(ComputeSecretChecksum(secret)).ToNot(Equal(checksum))
+
+		// Moving data between the key and value must not produce the same checksum
+		Expect(ComputeSecretChecksum(&corev1.Secret{Data: map[string][]byte{"ab": []byte("c")}})).
+			ToNot(Equal(ComputeSecretChecksum(&corev1.Secret{Data: map[string][]byte{"a": []byte("bc")}})))
+	})
+
+	It("compares against the last validated checksum", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				RestData: &pluginv1alpha1.RestData{AuthSecret: "hwmgr-auth"},
+			},
+		}
+		Expect(GetHardwareManagerAuthSecretName(hwmgr)).To(Equal("hwmgr-auth"))
+		Expect(IsAuthSecretChanged(hwmgr, "abc")).To(BeTrue())
+
+		hwmgr.SetAnnotations(map[string]string{AuthSecretChecksumAnnotation: "abc"})
+		Expect(IsAuthSecretChanged(hwmgr, "abc")).To(BeFalse())
+		Expect(IsAuthSecretChanged(hwmgr, "def")).To(BeTrue())
+	})
+})
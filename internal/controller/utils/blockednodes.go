@@ -0,0 +1,42 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// GetBlockedNode returns the entry of the blockedNodes of the hardware manager that blocks the node in the resource
+// pool, or nil if the node is not blocked. An entry without a resource pool blocks the node in all resource pools, as
+// does an empty poolID for an entry with one, for callers that do not know the pool of the node.
+func GetBlockedNode(hwmgr *pluginv1alpha1.HardwareManager, poolID, nodeId string) *pluginv1alpha1.BlockedNode {
+	for i := range hwmgr.Spec.BlockedNodes {
+		blocked := &hwmgr.Spec.BlockedNodes[i]
+		if blocked.NodeId != nodeId {
+			continue
+		}
+		if blocked.ResourcePoolId == "" || poolID == "" || blocked.ResourcePoolId == poolID {
+			return blocked
+		}
+	}
+	return nil
+}
+
+// IsNodeBlocked returns true if the node is blocked from allocation in the resource pool
+func IsNodeBlocked(hwmgr *pluginv1alpha1.HardwareManager, poolID, nodeId string) bool {
+	return GetBlockedNode(hwmgr, poolID, nodeId) != nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Blocked nodes", func() {
+	hwmgr := &pluginv1alpha1.HardwareManager{
+		Spec: pluginv1alpha1.HardwareManagerSpec{
+			BlockedNodes: []pluginv1alpha1.BlockedNode{
+				{NodeId: "dummy-sp-64g-0", Reason: "DIMM failures"},
+				{NodeId: "dummy-sp-64g-1", ResourcePoolId: "master", Reason: "NIC firmware"},
+			},
+		},
+	}
+
+	It("blocks a node in all resource pools", func() {
+		Expect(IsNodeBlocked(hwmgr, "master", "dummy-sp-64g-0")).To(BeTrue())
+		Expect(IsNodeBlocked(hwmgr, "worker", "dummy-sp-64g-0")).To(BeTrue())
+		Expect(GetBlockedNode(hwmgr, "worker", "dummy-sp-64g-0").Reason).To(Equal("DIMM failures"))
+	})
+
+	It("blocks a node in a given resource pool", func() {
+		Expect(IsNodeBlocked(hwmgr, "master", "dummy-sp-64g-1")).To(BeTrue())
+		Expect(IsNodeBlocked(hwmgr, "worker", "dummy-sp-64g-1")).To(BeFalse())
+		Expect(IsNodeBlocked(hwmgr, "", "dummy-sp-64g-1")).To(BeTrue())
+	})
+
+	It("does not block other nodes", func() {
+		Expect(IsNodeBlocked(hwmgr, "master", "dummy-sp-64g-2")).To(BeFalse())
+		Expect(IsNodeBlocked(&pluginv1alpha1.HardwareManager{}, "master", "dummy-sp-64g-0")).To(BeFalse())
+	})
+})
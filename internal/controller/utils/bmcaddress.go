@@ -0,0 +1,248 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+const (
+	// DefaultRedfishSystemPath is the Redfish system path published for a BMC address without one
+	DefaultRedfishSystemPath = "/redfish/v1/Systems/1"
+	// DefaultIDRACSystemPath is the Redfish system path published for a BMC address without one, with an idrac scheme
+	DefaultIDRACSystemPath = "/redfish/v1/Systems/System.Embedded.1"
+)
+
+// redfishPathPrefix is the prefix of the path of a BMC address that identifies a Redfish system
+const redfishPathPrefix = "/redfish/"
+
+// GetBMCAddressFamily returns the BMC address family of the hardware manager, defaulting to Dual
+func GetBMCAddressFamily(hwmgr *pluginv1alpha1.HardwareManager) pluginv1alpha1.BMCAddressFamily {
+	if hwmgr == nil || hwmgr.Spec.BMCAddressFamily == "" {
+		return pluginv1alpha1.BMCAddressFamilies.Dual
+	}
+	return hwmgr.Spec.BMCAddressFamily
+}
+
+// splitBMCAddress splits a BMC address into its scheme prefix, host, port, and the remainder of the address. The
+// address may be a bare host, with an optional port, or a URL with a scheme such as redfish+https. IPv6 hosts may be
+// bracketed or, if no port is given, unbracketed.
+func splitBMCAddress(address string) (prefix, host, port, rest string, err error) {
+	authority := address
+	if index := strings.Index(authority, "://"); index != -1 {
+		prefix = authority[:index+3]
+		authority = authority[index+3:]
+	}
+	if index := strings.Index(authority, "/"); index != -1 {
+		rest = authority[index:]
+		authority = authority[:index]
+	}
+	if index := strings.LastIndex(authority, "@"); index != -1 {
+		prefix += authority[:index+1]
+		authority = authority[index+1:]
+	}
+
+	hasPort := false
+	switch {
+	case strings.HasPrefix(authority, "["):
+		end := strings.Index(authority, "]")
+		if end == -1 {
+			return "", "", "", "", NewInputError("invalid BMC address %q: missing closing bracket", address)
+		}
+		host = authority[1:end]
+		switch remainder := authority[end+1:]; {
+		case remainder == "":
+		case strings.HasPrefix(remainder, ":"):
+			port, hasPort = remainder[1:], true
+		default:
+			return "", "", "", "", NewInputError("invalid BMC address %q: unexpected characters after host", address)
+		}
+	case strings.Count(authority, ":") > 1:
+		// An unbracketed IPv6 address, which cannot include a port
+		host = authority
+	case strings.Contains(authority, ":"):
+		host, port, hasPort = strings.Cut(authority, ":")
+	default:
+		host = authority
+	}
+
+	if host == "" {
+		return "", "", "", "", NewInputError("invalid BMC address %q: missing host", address)
+	}
+	if hasPort {
+		if value, err := strconv.Atoi(port); err != nil || value < 1 || value > 65535 {
+			return "", "", "", "", NewInputError("invalid BMC address %q: invalid port %q", address, port)
+		}
+	}
+
+	return prefix, host, port, rest, nil
+}
+
+// NormalizeBMCAddress validates a BMC address, returning it with an IP address host in canonical form. IPv6 hosts are
+// bracketed, so the address can be parsed as a URL, and IPv4-mapped IPv6 hosts are converted to IPv4. Hostnames are
+// left as is.
+func NormalizeBMCAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return "", nil
+	}
+
+	prefix, host, port, rest, err := splitBMCAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if ip.Zone() != "" {
+			return "", NewInputError("invalid BMC address %q: IPv6 zones are not supported", address)
+		}
+		host = ip.Unmap().String()
+		if ip.Unmap().Is6() {
+			host = "[" + host + "]"
+		}
+	} else if strings.ContainsAny(host, ":[]") {
+		return "", NewInputError("invalid BMC address %q: invalid IP address %q", address, host)
+	}
+
+	if port != "" {
+		host += ":" + port
+	}
+
+	return prefix + host + rest, nil
+}
+
+// GetBMCAddressFamilyOf returns the IP address family of the host of a normalized BMC address, or an empty family if
+// the host is a hostname
+func GetBMCAddressFamilyOf(address string) pluginv1alpha1.BMCAddressFamily {
+	_, host, _, _, err := splitBMCAddress(address)
+	if err != nil {
+		return ""
+	}
+
+	ip, err := netip.ParseAddr(host)
+	switch {
+	case err != nil:
+		return ""
+	case ip.Unmap().Is4():
+		return pluginv1alpha1.BMCAddressFamilies.IPv4
+	default:
+		return pluginv1alpha1.BMCAddressFamilies.IPv6
+	}
+}
+
+// SelectBMCAddress normalizes the BMC addresses reported by the backend for a node, in order of preference, and
+// returns the address to be published according to the BMC address family of the hardware manager. An empty address is
+// returned if the backend has not reported any, and an input error if none matches the address family.
+func SelectBMCAddress(hwmgr *pluginv1alpha1.HardwareManager, addresses ...string) (string, error) {
+	family := GetBMCAddressFamily(hwmgr)
+
+	var normalized []string
+	for _, address := range addresses {
+		address, err := NormalizeBMCAddress(address)
+		if err != nil {
+			return "", err
+		}
+		if address != "" {
+			normalized = append(normalized, address)
+		}
+	}
+	if len(normalized) == 0 {
+		return "", nil
+	}
+
+	if family == pluginv1alpha1.BMCAddressFamilies.Dual {
+		return normalized[0], nil
+	}
+
+	for _, address := range normalized {
+		if found := GetBMCAddressFamilyOf(address); found == family || found == "" {
+			return address, nil
+		}
+	}
+
+	return "", NewInputError("no %s BMC address reported, found: %s", family, strings.Join(normalized, ", "))
+}
+
+// GetHwProfileBMCAddressFormat returns the BMC address format of the hardware profile, or nil if the profile does not
+// define one
+func GetHwProfileBMCAddressFormat(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string) *pluginv1alpha1.BMCAddressFormat {
+	if hwmgr == nil {
+		return nil
+	}
+	if profile := getHwProfile(hwmgr, hwprofile); profile != nil {
+		return profile.BMCAddressFormat
+	}
+	return nil
+}
+
+// ConvertBMCAddress converts a BMC address to the given format, returning the normalized address if the format is
+// nil. The scheme of the address is replaced, retaining its host and port. With a Redfish scheme, the Redfish path of
+// the address is retained, or the system path of the format added if the address has none. With the ipmi scheme, the
+// path is dropped.
+func ConvertBMCAddress(address string, format *pluginv1alpha1.BMCAddressFormat) (string, error) {
+	address, err := NormalizeBMCAddress(address)
+	if err != nil || address == "" || format == nil {
+		return address, err
+	}
+
+	prefix, host, port, path, err := splitBMCAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	// Retain any user info of the address
+	if index := strings.Index(prefix, "://"); index != -1 {
+		prefix = prefix[index+3:]
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+
+	scheme := string(format.Scheme)
+	if scheme == "ipmi" {
+		return scheme + "://" + prefix + host, nil
+	}
+
+	if !strings.HasPrefix(path, redfishPathPrefix) {
+		path = format.SystemPath
+		if path == "" {
+			path = DefaultRedfishSystemPath
+			if strings.HasPrefix(scheme, "idrac") {
+				path = DefaultIDRACSystemPath
+			}
+		}
+	}
+
+	return scheme + "://" + prefix + host + path, nil
+}
+
+// FormatBMCAddress selects the BMC address to be published, as SelectBMCAddress, and converts it to the BMC address
+// format of the hardware profile
+func FormatBMCAddress(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string, addresses ...string) (string, error) {
+	address, err := SelectBMCAddress(hwmgr, addresses...)
+	if err != nil {
+		return "", err
+	}
+	return ConvertBMCAddress(address, GetHwProfileBMCAddressFormat(hwmgr, hwprofile))
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("BMC addresses", func() {
+	newHwmgr := func(family pluginv1alpha1.BMCAddressFamily) *pluginv1alpha1.HardwareManager {
+		return &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{BMCAddressFamily: family},
+		}
+	}
+
+	It("normalizes BMC addresses", func() {
+		for address, expected := range map[string]string{
+			"":                    "",
+			"10.0.0.1":            "10.0.0.1",
+			"10.0.0.1:8443":       "10.0.0.1:8443",
+			"bmc.example.com:443": "bmc.example.com:443",
+			"fd00:0::1":           "[fd00::1]",
+			"[FD00::1]:8443":      "[fd00::1]:8443",
+			"::ffff:10.0.0.1":     "10.0.0.1",
+			"redfish+https://[fd00::0001]/redfish/v1":                                  "redfish+https://[fd00::1]/redfish/v1",
+			"idrac-virtualmedia+https://10.0.0.1/redfish/v1/Systems/System.Embedded.1": "idrac-virtualmedia+https://10.0.0.1/redfish/v1/Systems/System.Embedded.1",
+		} {
+			normalized, err := NormalizeBMCAddress(address)
+			Expect(err).ToNot(HaveOccurred(), address)
+			Expect(normalized).To(Equal(expected), address)
+		}
+	})
+
+	It("rejects invalid BMC addresses", func() {
+		for _, address := range []string{
+			"[fd00::1",
+			"[fd00::1]x",
+			"10.0.0.1:99999",
+			"10.0.0.1:",
+			"fe80::1%eth0",
+			"[fd00::zz]",
+			"https:///redfish/v1",
+		} {
+			_, err := NormalizeBMCAddress(address)
+			Expect(err).To(HaveOccurred(), address)
+			Expect(IsInputError(err)).To(BeTrue(), address)
+		}
+	})
+
+	It("reports the address family of BMC addresses", func() {
+		Expect(GetBMCAddressFamilyOf("redfish+https://10.0.0.1/redfish/v1")).To(Equal(pluginv1alpha1.BMCAddressFamilies.IPv4))
+		Expect(GetBMCAddressFamilyOf("[fd00::1]:8443")).To(Equal(pluginv1alpha1.BMCAddressFamilies.IPv6))
+		Expect(GetBMCAddressFamilyOf("bmc.example.com")).To(BeEmpty())
+	})
+
+	It("defaults to the dual address family", func() {
+		Expect(GetBMCAddressFamily(nil)).To(Equal(pluginv1alpha1.BMCAddressFamilies.Dual))
+		Expect(GetBMCAddressFamily(newHwmgr(""))).To(Equal(pluginv1alpha1.BMCAddressFamilies.Dual))
+	})
+
+	It("selects the BMC address of the preferred family", func() {
+		address, err := SelectBMCAddress(newHwmgr(""), "10.0.0.1", "fd00::1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address).To(Equal("10.0.0.1"))
+
+		address, err = SelectBMCAddress(newHwmgr(pluginv1alpha1.BMCAddressFamilies.Dual), "", "fd00::1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address).To(Equal("[fd00::1]"))
+
+		address, err = SelectBMCAddress(newHwmgr(pluginv1alpha1.BMCAddressFamilies.IPv6), "10.0.0.1", "fd00::1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address).To(Equal("[fd00::1]"))
+
+		address, err = SelectBMCAddress(newHwmgr(pluginv1alpha1.BMCAddressFamilies.IPv4), "[fd00::1]", "10.0.0.1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address).To(Equal("10.0.0.1"))
+
+		address, err = SelectBMCAddress(newHwmgr(pluginv1alpha1.BMCAddressFamilies.IPv6), "bmc.example.com")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address).To(Equal("bmc.example.com"))
+
+		address, err = SelectBMCAddress(newHwmgr(pluginv1alpha1.BMCAddressFamilies.IPv6), "", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(address).To(BeEmpty())
+	})
+
+	It("rejects a BMC address of the wrong family", func() {
+		_, err := SelectBMCAddress(newHwmgr(pluginv1alpha1.BMCAddressFamilies.IPv6), "10.0.0.1")
+		Expect(err).To(HaveOccurred())
+		Expect(IsInputError(err)).To(BeTrue())
+
+		_, err = SelectBMCAddress(newHwmgr(pluginv1alpha1.BMCAddressFamilies.IPv4), "redfish+https://[fd00::1]/redfish/v1")
+		Expect(err).To(HaveOccurred())
+		Expect(IsInputError(err)).To(BeTrue())
+	})
+
+	It("converts BMC addresses to the format of the hardware profile", func() {
+		redfish := &pluginv1alpha1.BMCAddressFormat{Scheme: "redfish-virtualmedia+https"}
+		for address, expected := range map[string]string{
+			"":         "",
+			"10.0.0.1": "redfish-virtualmedia+https://10.0.0.1/redfish/v1/Systems/1",
+			"fd00::1":  "redfish-virtualmedia+https://[fd00::1]/redfish/v1/Systems/1",
+			"idrac-virtualmedia+https://10.0.0.1:8443/redfish/v1/Systems/System.Embedded.1": "redfish-virtualmedia+https://10.0.0.1:8443/redfish/v1/Systems/System.Embedded.1",
+			"https://[fd00::1]/redfish/v1/Systems/2":                                        "redfish-virtualmedia+https://[fd00::1]/redfish/v1/Systems/2",
+			"https://bmc.example.com/index.html":                                            "redfish-virtualmedia+https://bmc.example.com/redfish/v1/Systems/1",
+		} {
+			Expect(ConvertBMCAddress(address, redfish)).To(Equal(expected), address)
+		}
+
+		idrac := &pluginv1alpha1.BMCAddressFormat{Scheme: "idrac-virtualmedia"}
+		Expect(ConvertBMCAddress("10.0.0.1", idrac)).To(Equal("idrac-virtualmedia://10.0.0.1/redfish/v1/Systems/System.Embedded.1"))
+		idrac.SystemPath = "/redfish/v1/Systems/Custom"
+		Expect(ConvertBMCAddress("10.0.0.1", idrac)).To(Equal("idrac-virtualmedia://10.0.0.1/redfish/v1/Systems/Custom"))
+
+		ipmi := &pluginv1alpha1.BMCAddressFormat{Scheme: "ipmi"}
+		Expect(ConvertBMCAddress("redfish+https://10.0.0.1:623/redfish/v1/Systems/1", ipmi)).To(Equal("ipmi://10.0.0.1:623"))
+
+		Expect(ConvertBMCAddress("FD00::1", nil)).To(Equal("[fd00::1]"))
+		_, err := ConvertBMCAddress("[fd00::1", redfish)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("formats the selected BMC address with the format of the hardware profile", func() {
+		hwmgr := newHwmgr(pluginv1alpha1.BMCAddressFamilies.IPv6)
+		hwmgr.Spec.HwProfiles = []pluginv1alpha1.HardwareProfile{
+			{Name: "profile-redfish", BMCAddressFormat: &pluginv1alpha1.BMCAddressFormat{Scheme: "redfish"}},
+		}
+		Expect(FormatBMCAddress(hwmgr, "profile-redfish", "10.0.0.1", "fd00::1")).To(Equal("redfish://[fd00::1]/redfish/v1/Systems/1"))
+		Expect(FormatBMCAddress(hwmgr, "profile-other", "10.0.0.1", "fd00::1")).To(Equal("[fd00::1]"))
+	})
+})
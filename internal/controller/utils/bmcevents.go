@@ -0,0 +1,159 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// BMCEventSubscriptionAnnotation records, on a Node, the URI of the Redfish event subscription created on its BMC
+	BMCEventSubscriptionAnnotation = "hwmgr-plugin.oran.openshift.io/bmcEventSubscription"
+	// BMCEventTokenAnnotation records, on a Node, the token carried as the context of the events of its subscription,
+	// which identifies the events delivered to the listener as those of the node
+	BMCEventTokenAnnotation = "hwmgr-plugin.oran.openshift.io/bmcEventToken"
+)
+
+// HardwareDegraded condition type and reasons, set on a Node from the events reported by its BMC. The reason is the
+// category of the last significant event, and the condition is cleared by an OK event of the same category.
+const (
+	NodeHardwareDegraded  hwmgmtv1alpha1.ConditionType   = "HardwareDegraded"
+	ReasonThermalEvent    hwmgmtv1alpha1.ConditionReason = "ThermalEvent"
+	ReasonPowerEvent      hwmgmtv1alpha1.ConditionReason = "PowerEvent"
+	ReasonHealthEvent     hwmgmtv1alpha1.ConditionReason = "HealthEvent"
+	ReasonHardwareHealthy hwmgmtv1alpha1.ConditionReason = "Healthy"
+)
+
+// BMCEventCategory is the hardware subsystem an event reported by a BMC relates to
+type BMCEventCategory string
+
+const (
+	BMCEventCategoryThermal BMCEventCategory = "Thermal"
+	BMCEventCategoryPower   BMCEventCategory = "Power"
+	BMCEventCategoryHealth  BMCEventCategory = "Health"
+)
+
+// Redfish event severities
+const (
+	BMCEventSeverityOK       = "OK"
+	BMCEventSeverityWarning  = "Warning"
+	BMCEventSeverityCritical = "Critical"
+)
+
+// BMCEvent is an event reported by the BMC of a node
+type BMCEvent struct {
+	MessageId string
+	Message   string
+	Severity  string
+	// Origin is the URI of the resource that raised the event, such as /redfish/v1/Chassis/1/Thermal
+	Origin string
+}
+
+// Category returns the category of the event, from the resource that raised it or, failing that, from its message
+// key. Events that are neither thermal nor power events are health events.
+func (e BMCEvent) Category() BMCEventCategory {
+	origin := strings.ToLower(e.Origin)
+	switch {
+	case strings.Contains(origin, "/thermal"):
+		return BMCEventCategoryThermal
+	case strings.Contains(origin, "/power"):
+		return BMCEventCategoryPower
+	}
+
+	// The message key is the last segment of the message ID, as in ResourceEvent.1.0.ResourceErrorThresholdExceeded
+	key := strings.ToLower(e.MessageId[strings.LastIndex(e.MessageId, ".")+1:])
+	for _, keyword := range []string{"temperature", "thermal", "fan", "cooling"} {
+		if strings.Contains(key, keyword) {
+			return BMCEventCategoryThermal
+		}
+	}
+	for _, keyword := range []string{"power", "voltage", "psu"} {
+		if strings.Contains(key, keyword) {
+			return BMCEventCategoryPower
+		}
+	}
+
+	return BMCEventCategoryHealth
+}
+
+// IsSignificant returns true if the event reports a problem with the hardware
+func (e BMCEvent) IsSignificant() bool {
+	return e.Severity == BMCEventSeverityWarning || e.Severity == BMCEventSeverityCritical
+}
+
+// Reason returns the HardwareDegraded condition reason for the category of the event
+func (e BMCEvent) Reason() hwmgmtv1alpha1.ConditionReason {
+	switch e.Category() {
+	case BMCEventCategoryThermal:
+		return ReasonThermalEvent
+	case BMCEventCategoryPower:
+		return ReasonPowerEvent
+	default:
+		return ReasonHealthEvent
+	}
+}
+
+// String returns a description of the event
+func (e BMCEvent) String() string {
+	description := fmt.Sprintf("%s %s event", e.Severity, strings.ToLower(string(e.Category())))
+	if e.Message != "" {
+		description += ": " + e.Message
+	}
+	if e.MessageId != "" {
+		description += " (" + e.MessageId + ")"
+	}
+	return description
+}
+
+// SetNodeHardwareDegradedCondition reflects an event reported by the BMC of the node in its HardwareDegraded
+// condition, returning true if the condition was changed. A significant event sets the condition, and an OK event
+// clears a condition set by an event of the same category. Other events are ignored. The status is not updated on
+// the cluster.
+func SetNodeHardwareDegradedCondition(node *hwmgmtv1alpha1.Node, event BMCEvent) bool {
+	current := meta.FindStatusCondition(node.Status.Conditions, string(NodeHardwareDegraded))
+
+	if event.IsSignificant() {
+		message := event.String()
+		if current != nil && current.Status == metav1.ConditionTrue &&
+			current.Reason == string(event.Reason()) && current.Message == message {
+			return false
+		}
+		SetStatusCondition(&node.Status.Conditions,
+			string(NodeHardwareDegraded),
+			string(event.Reason()),
+			metav1.ConditionTrue,
+			message)
+		return true
+	}
+
+	if event.Severity != BMCEventSeverityOK || current == nil ||
+		current.Status != metav1.ConditionTrue || current.Reason != string(event.Reason()) {
+		return false
+	}
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeHardwareDegraded),
+		string(ReasonHardwareHealthy),
+		metav1.ConditionFalse,
+		"Cleared by "+event.String())
+	return true
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("BMC events", func() {
+	It("classifies events by origin, then by message key", func() {
+		Expect(BMCEvent{Origin: "/redfish/v1/Chassis/1/Thermal#/Fans/0"}.Category()).To(Equal(BMCEventCategoryThermal))
+		Expect(BMCEvent{Origin: "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/0"}.Category()).To(Equal(BMCEventCategoryPower))
+		Expect(BMCEvent{MessageId: "EventLog.1.0.TemperatureWarning"}.Category()).To(Equal(BMCEventCategoryThermal))
+		Expect(BMCEvent{MessageId: "EventLog.1.0.PSUFailure"}.Category()).To(Equal(BMCEventCategoryPower))
+		Expect(BMCEvent{MessageId: "ResourceEvent.1.0.ResourceErrorsDetected",
+			Origin: "/redfish/v1/Systems/1/Memory/DIMM0"}.Category()).To(Equal(BMCEventCategoryHealth))
+		Expect(BMCEvent{}.Category()).To(Equal(BMCEventCategoryHealth))
+	})
+
+	It("sets the condition for significant events", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		event := BMCEvent{MessageId: "EventLog.1.0.TemperatureCritical", Message: "CPU1 temperature critical",
+			Severity: BMCEventSeverityCritical}
+
+		Expect(SetNodeHardwareDegradedCondition(node, event)).To(BeTrue())
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(NodeHardwareDegraded))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(ReasonThermalEvent)))
+		Expect(condition.Message).To(Equal("Critical thermal event: CPU1 temperature critical (EventLog.1.0.TemperatureCritical)"))
+		Expect(GetNodeDegradedReason(node)).To(Equal(string(NodeHardwareDegraded)))
+
+		// A repeated event leaves the condition unchanged
+		Expect(SetNodeHardwareDegradedCondition(node, event)).To(BeFalse())
+	})
+
+	It("clears the condition only with an OK event of the same category", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		Expect(SetNodeHardwareDegradedCondition(node, BMCEvent{
+			Origin: "/redfish/v1/Chassis/1/Power", Severity: BMCEventSeverityWarning})).To(BeTrue())
+
+		Expect(SetNodeHardwareDegradedCondition(node, BMCEvent{
+			Origin: "/redfish/v1/Chassis/1/Thermal", Severity: BMCEventSeverityOK})).To(BeFalse())
+		Expect(meta.IsStatusConditionTrue(node.Status.Conditions, string(NodeHardwareDegraded))).To(BeTrue())
+
+		Expect(SetNodeHardwareDegradedCondition(node, BMCEvent{
+			Origin: "/redfish/v1/Chassis/1/Power", Severity: BMCEventSeverityOK, Message: "PSU1 recovered"})).To(BeTrue())
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(NodeHardwareDegraded))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ReasonHardwareHealthy)))
+		Expect(GetNodeDegradedReason(node)).To(BeEmpty())
+	})
+
+	It("ignores OK events without a condition set", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		Expect(SetNodeHardwareDegradedCondition(node, BMCEvent{Severity: BMCEventSeverityOK})).To(BeFalse())
+		Expect(node.Status.Conditions).To(BeEmpty())
+	})
+})
@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BootCapabilitiesAnnotation publishes the boot capabilities of a node, as reported by the backend. The Node status
+	// is defined by the O2IMS API, so these are recorded as an annotation on the Node CR, for use by ZTP flows in
+	// choosing between PXE and virtual media boot.
+	BootCapabilitiesAnnotation = "hwmgr-plugin.oran.openshift.io/bootCapabilities"
+)
+
+// NodeBootCapabilities describes how a node can be booted
+type NodeBootCapabilities struct {
+	// VirtualMedia is true if a boot image can be attached to the node as virtual media
+	VirtualMedia bool `json:"virtualMedia"`
+	// BootDevices are the devices the node supports booting from, such as Pxe, Cd, Hdd, or UefiHttp
+	BootDevices []string `json:"bootDevices,omitempty"`
+	// VirtualMediaURLs are the BMC URLs through which a boot image can be attached as virtual media
+	VirtualMediaURLs []string `json:"virtualMediaURLs,omitempty"`
+}
+
+// NewNodeBootCapabilities returns the boot capabilities for the reported boot devices and virtual media URLs, with
+// virtual media boot supported if any virtual media URL is reported. Empty and duplicate entries are dropped.
+func NewNodeBootCapabilities(bootDevices, virtualMediaURLs []string) NodeBootCapabilities {
+	normalize := func(values []string) []string {
+		var result []string
+		for _, value := range values {
+			if value != "" && !slices.Contains(result, value) {
+				result = append(result, value)
+			}
+		}
+		return result
+	}
+
+	caps := NodeBootCapabilities{
+		BootDevices:      normalize(bootDevices),
+		VirtualMediaURLs: normalize(virtualMediaURLs),
+	}
+	caps.VirtualMedia = len(caps.VirtualMediaURLs) > 0
+	return caps
+}
+
+// IsEmpty returns true if no boot capabilities are reported
+func (caps NodeBootCapabilities) IsEmpty() bool {
+	return !caps.VirtualMedia && len(caps.BootDevices) == 0 && len(caps.VirtualMediaURLs) == 0
+}
+
+// GetNodeBootCapabilities returns the boot capabilities published on the node, or nil if there are none
+func GetNodeBootCapabilities(node client.Object) (*NodeBootCapabilities, error) {
+	data, exists := node.GetAnnotations()[BootCapabilitiesAnnotation]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	caps := &NodeBootCapabilities{}
+	if err := json.Unmarshal([]byte(data), caps); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", BootCapabilitiesAnnotation, err)
+	}
+	return caps, nil
+}
+
+// SetNodeBootCapabilities publishes the boot capabilities on the node, removing the annotation if none are reported,
+// and returns true if the annotation has changed. The node is not updated on the cluster.
+func SetNodeBootCapabilities(node client.Object, caps NodeBootCapabilities) (bool, error) {
+	annotations := node.GetAnnotations()
+	current, exists := annotations[BootCapabilitiesAnnotation]
+
+	if caps.IsEmpty() {
+		if !exists {
+			return false, nil
+		}
+		delete(annotations, BootCapabilitiesAnnotation)
+		node.SetAnnotations(annotations)
+		return true, nil
+	}
+
+	data, err := json.Marshal(caps)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal boot capabilities: %w", err)
+	}
+	if exists && current == string(data) {
+		return false, nil
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[BootCapabilitiesAnnotation] = string(data)
+	node.SetAnnotations(annotations)
+	return true, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Boot capabilities", func() {
+	It("derives virtual media support from the reported URLs", func() {
+		caps := NewNodeBootCapabilities([]string{"Pxe", "", "Cd", "Pxe"}, []string{"redfish-virtualmedia://10.0.0.1/redfish/v1/Systems/1"})
+		Expect(caps).To(Equal(NodeBootCapabilities{
+			VirtualMedia:     true,
+			BootDevices:      []string{"Pxe", "Cd"},
+			VirtualMediaURLs: []string{"redfish-virtualmedia://10.0.0.1/redfish/v1/Systems/1"},
+		}))
+
+		caps = NewNodeBootCapabilities([]string{"Pxe"}, nil)
+		Expect(caps.VirtualMedia).To(BeFalse())
+		Expect(caps.IsEmpty()).To(BeFalse())
+		Expect(NewNodeBootCapabilities(nil, []string{""}).IsEmpty()).To(BeTrue())
+	})
+
+	It("publishes the capabilities as an annotation", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		caps := NewNodeBootCapabilities([]string{"Pxe", "Cd"}, []string{"redfish-virtualmedia://10.0.0.1/redfish/v1/Systems/1"})
+
+		changed, err := SetNodeBootCapabilities(node, caps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(GetNodeBootCapabilities(node)).To(Equal(&caps))
+
+		changed, err = SetNodeBootCapabilities(node, caps)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		changed, err = SetNodeBootCapabilities(node, NodeBootCapabilities{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(node.Annotations).ToNot(HaveKey(BootCapabilitiesAnnotation))
+		Expect(GetNodeBootCapabilities(node)).To(BeNil())
+	})
+
+	It("rejects an invalid annotation", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Annotations = map[string]string{BootCapabilitiesAnnotation: "not-json"}
+		_, err := GetNodeBootCapabilities(node)
+		Expect(err).To(HaveOccurred())
+	})
+})
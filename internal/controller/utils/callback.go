@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// CallbackURLKey is the NodePool extensions key that holds the URL notified when the NodePool is provisioned or
+	// fails
+	CallbackURLKey = "callbackURL"
+
+	// CallbackNotifiedAnnotation records, on a NodePool, the provisioned state last notified to its callback URL
+	CallbackNotifiedAnnotation = "hwmgr-plugin.oran.openshift.io/callbackNotified"
+
+	// CallbackSigningKey is the entry of the signing secret holding the callback signing key
+	CallbackSigningKey = "key"
+
+	// CallbackTimestampHeader and CallbackSignatureHeader are the headers of a callback request carrying the time it
+	// was signed and its signature
+	CallbackTimestampHeader = "X-Hwmgr-Plugin-Timestamp"
+	CallbackSignatureHeader = "X-Hwmgr-Plugin-Signature"
+)
+
+// CallbackNotification is the JSON body posted to the callback URL of a NodePool
+type CallbackNotification struct {
+	NodePool  string `json:"nodePool"`
+	Namespace string `json:"namespace"`
+	CloudID   string `json:"cloudID"`
+	// Status is either Provisioned or Failed
+	Status  string      `json:"status"`
+	Reason  string      `json:"reason"`
+	Message string      `json:"message,omitempty"`
+	Time    metav1.Time `json:"time"`
+}
+
+// Callback notification statuses
+const (
+	CallbackStatusProvisioned = "Provisioned"
+	CallbackStatusFailed      = "Failed"
+)
+
+// GetNodePoolCallbackURL returns the callback URL from the NodePool extensions, or an empty string if none is specified
+func GetNodePoolCallbackURL(nodepool *hwmgmtv1alpha1.NodePool) string {
+	return strings.TrimSpace(nodepool.Spec.Extensions[CallbackURLKey])
+}
+
+// ValidateNodePoolCallbackURL validates that the callback URL of the NodePool, if any, is an absolute http or https URL
+func ValidateNodePoolCallbackURL(nodepool *hwmgmtv1alpha1.NodePool) error {
+	callbackURL := GetNodePoolCallbackURL(nodepool)
+	if callbackURL == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(callbackURL)
+	if err != nil {
+		return NewInputError("invalid %s extension: %s", CallbackURLKey, err.Error())
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return NewInputError("invalid %s extension %q: must be an absolute http or https URL", CallbackURLKey, callbackURL)
+	}
+
+	return nil
+}
+
+// callbackState identifies a terminal provisioned state of the NodePool, so each transition is notified once
+func callbackState(condition *metav1.Condition) string {
+	return condition.Reason + "/" + condition.LastTransitionTime.UTC().Format(time.RFC3339)
+}
+
+// GetPendingNodePoolCallback returns the notification to post to the callback URL of the NodePool, or nil if it has no
+// callback URL, is not provisioned or failed, or its current state has already been notified
+func GetPendingNodePoolCallback(nodepool *hwmgmtv1alpha1.NodePool) *CallbackNotification {
+	if GetNodePoolCallbackURL(nodepool) == "" {
+		return nil
+	}
+
+	condition := GetNodePoolProvisionedCondition(nodepool)
+	if condition == nil {
+		return nil
+	}
+
+	var status string
+	switch {
+	case condition.Status == metav1.ConditionTrue:
+		status = CallbackStatusProvisioned
+	case condition.Reason == string(hwmgmtv1alpha1.Failed):
+		status = CallbackStatusFailed
+	default:
+		return nil
+	}
+
+	if nodepool.GetAnnotations()[CallbackNotifiedAnnotation] == callbackState(condition) {
+		return nil
+	}
+
+	return &CallbackNotification{
+		NodePool:  nodepool.Name,
+		Namespace: nodepool.Namespace,
+		CloudID:   nodepool.Spec.CloudID,
+		Status:    status,
+		Reason:    condition.Reason,
+		Message:   condition.Message,
+		Time:      condition.LastTransitionTime,
+	}
+}
+
+// SetNodePoolCallbackNotified records the current provisioned state of the NodePool as notified
+func SetNodePoolCallbackNotified(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool) error {
+	condition := GetNodePoolProvisionedCondition(nodepool)
+	if condition == nil {
+		return nil
+	}
+
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[CallbackNotifiedAnnotation] = callbackState(condition)
+	nodepool.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, nodepool, patch); err != nil {
+		return fmt.Errorf("failed to annotate NodePool %s with callback state: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// SignCallback returns the signature of a callback body, an HMAC-SHA256 of the timestamp and body joined by a period,
+// so that a receiver can reject replayed notifications
+func SignCallback(key []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
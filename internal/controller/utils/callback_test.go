@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("NodePool callback", func() {
+	newCallbackNodePool := func(status metav1.ConditionStatus, reason hwmgmtv1alpha1.ConditionReason) *hwmgmtv1alpha1.NodePool {
+		nodepool := newTestNodePool(map[string]string{CallbackURLKey: "https://orchestrator.example.com/notify"})
+		nodepool.Name = "testcloud"
+		nodepool.Namespace = "oran"
+		nodepool.Status.Conditions = []metav1.Condition{{
+			Type:               string(hwmgmtv1alpha1.Provisioned),
+			Status:             status,
+			Reason:             string(reason),
+			Message:            "message",
+			LastTransitionTime: metav1.NewTime(time.Date(2024, 10, 24, 11, 4, 38, 0, time.UTC)),
+		}}
+		return nodepool
+	}
+
+	It("validates the callback URL", func() {
+		Expect(ValidateNodePoolCallbackURL(newTestNodePool(nil))).To(Succeed())
+		Expect(ValidateNodePoolCallbackURL(newTestNodePool(map[string]string{CallbackURLKey: "http://10.0.0.1:8080/cb"}))).To(Succeed())
+
+		for _, callbackURL := range []string{"/notify", "ftp://example.com/notify", "https://", "https://exa mple.com"} {
+			err := ValidateNodePoolCallbackURL(newTestNodePool(map[string]string{CallbackURLKey: callbackURL}))
+			Expect(err).To(HaveOccurred(), callbackURL)
+			Expect(IsInputError(err)).To(BeTrue(), callbackURL)
+		}
+	})
+
+	It("notifies terminal provisioned states", func() {
+		notification := GetPendingNodePoolCallback(newCallbackNodePool(metav1.ConditionTrue, hwmgmtv1alpha1.Completed))
+		Expect(notification).ToNot(BeNil())
+		Expect(notification.NodePool).To(Equal("testcloud"))
+		Expect(notification.Namespace).To(Equal("oran"))
+		Expect(notification.CloudID).To(Equal("testcloud"))
+		Expect(notification.Status).To(Equal(CallbackStatusProvisioned))
+		Expect(notification.Reason).To(Equal(string(hwmgmtv1alpha1.Completed)))
+
+		notification = GetPendingNodePoolCallback(newCallbackNodePool(metav1.ConditionFalse, hwmgmtv1alpha1.Failed))
+		Expect(notification).ToNot(BeNil())
+		Expect(notification.Status).To(Equal(CallbackStatusFailed))
+
+		Expect(GetPendingNodePoolCallback(newCallbackNodePool(metav1.ConditionFalse, hwmgmtv1alpha1.InProgress))).To(BeNil())
+	})
+
+	It("does not notify without a callback URL", func() {
+		nodepool := newCallbackNodePool(metav1.ConditionTrue, hwmgmtv1alpha1.Completed)
+		delete(nodepool.Spec.Extensions, CallbackURLKey)
+		Expect(GetPendingNodePoolCallback(nodepool)).To(BeNil())
+	})
+
+	It("notifies each transition once", func() {
+		nodepool := newCallbackNodePool(metav1.ConditionTrue, hwmgmtv1alpha1.Completed)
+		nodepool.Annotations = map[string]string{CallbackNotifiedAnnotation: "Completed/2024-10-24T11:04:38Z"}
+		Expect(GetPendingNodePoolCallback(nodepool)).To(BeNil())
+
+		// A later transition to the same state is notified again
+		nodepool.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Date(2024, 10, 25, 0, 0, 0, 0, time.UTC))
+		Expect(GetPendingNodePoolCallback(nodepool)).ToNot(BeNil())
+	})
+
+	It("signs the timestamp and body", func() {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(`1729767878.{"status":"Provisioned"}`))
+		Expect(SignCallback([]byte("secret"), "1729767878", []byte(`{"status":"Provisioned"}`))).
+			To(Equal("sha256=" + hex.EncodeToString(mac.Sum(nil))))
+	})
+})
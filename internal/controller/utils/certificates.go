@@ -0,0 +1,176 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+const (
+	// CaBundleChecksumAnnotation records, on a HardwareManager, the checksum of the CA bundle configmaps that were
+	// last validated against the backend
+	CaBundleChecksumAnnotation = "hwmgr-plugin.oran.openshift.io/caBundleChecksum"
+
+	// CertificateExpiryWarning is the time before the expiry of a backend certificate at which it is reported as
+	// expiring
+	CertificateExpiryWarning = 30 * 24 * time.Hour
+)
+
+// GetHardwareManagerCaBundleNames returns the names of the CA bundle configmaps referenced by a hardware manager, for
+// the backend and its proxy
+func GetHardwareManagerCaBundleNames(hwmgr *pluginv1alpha1.HardwareManager) []string {
+	var names []string
+	addName := func(name *string) {
+		if name != nil && *name != "" && !slices.Contains(names, *name) {
+			names = append(names, *name)
+		}
+	}
+
+	switch {
+	case hwmgr.Spec.DellData != nil:
+		addName(hwmgr.Spec.DellData.CaBundleName)
+	case hwmgr.Spec.RestData != nil:
+		addName(hwmgr.Spec.RestData.CaBundleName)
+	}
+	if hwmgr.Spec.Proxy != nil {
+		addName(hwmgr.Spec.Proxy.CaBundleName)
+	}
+
+	return names
+}
+
+// ComputeConfigMapChecksum returns a checksum of the configmap data, independent of the order of the keys
+func ComputeConfigMapChecksum(cm *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		// Include the lengths so that key and value boundaries are unambiguous
+		fmt.Fprintf(hash, "%d:%s:%d:%s", len(key), key, len(cm.Data[key]), cm.Data[key])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// GetCaBundleChecksum returns a checksum of the CA bundle configmaps referenced by a hardware manager, or an empty
+// string if none are referenced
+func GetCaBundleChecksum(ctx context.Context, c client.Client, hwmgr *pluginv1alpha1.HardwareManager) (string, error) {
+	names := GetHardwareManagerCaBundleNames(hwmgr)
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	hash := sha256.New()
+	for _, name := range names {
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: hwmgr.Namespace}, cm); err != nil {
+			return "", fmt.Errorf("failed to get CA bundle configmap %s: %w", name, err)
+		}
+		fmt.Fprintf(hash, "%s:%s:", name, ComputeConfigMapChecksum(cm))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// IsCaBundleChanged returns true if the checksum differs from the one last validated for the hardware manager
+func IsCaBundleChanged(hwmgr *pluginv1alpha1.HardwareManager, checksum string) bool {
+	return hwmgr.GetAnnotations()[CaBundleChecksumAnnotation] != checksum
+}
+
+// SetCaBundleChecksum records the checksum of the validated CA bundle configmaps on the hardware manager
+func SetCaBundleChecksum(ctx context.Context, c client.Client, hwmgr *pluginv1alpha1.HardwareManager, checksum string) error {
+	patch := client.MergeFrom(hwmgr.DeepCopy())
+	annotations := hwmgr.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[CaBundleChecksumAnnotation] = checksum
+	hwmgr.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, hwmgr, patch); err != nil {
+		return fmt.Errorf("failed to annotate HardwareManager %s with CA bundle checksum: %w", hwmgr.Name, err)
+	}
+	return nil
+}
+
+// MapCaBundleToHardwareManagers returns a handler mapping a configmap to the hardware managers of the adaptor that
+// reference it as a CA bundle, so that a rotation of the CA certificates is re-validated promptly
+func MapCaBundleToHardwareManagers(c client.Client, adaptorID pluginv1alpha1.HardwareManagerAdaptorID) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			return nil
+		}
+
+		hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+		if err := c.List(ctx, hwmgrs, client.InNamespace(cm.Namespace)); err != nil {
+			utilsLog.InfoContext(ctx, "Unable to list HardwareManagers for CA bundle", "configmap", cm.Name, "error", err.Error())
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range hwmgrs.Items {
+			hwmgr := &hwmgrs.Items[i]
+			if hwmgr.Spec.AdaptorID == adaptorID && slices.Contains(GetHardwareManagerCaBundleNames(hwmgr), cm.Name) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(hwmgr)})
+			}
+		}
+		return requests
+	}
+}
+
+// SetCertificateExpiringCondition sets the CertificateExpiring condition of the hardware manager for the backend
+// certificate expiring soonest, without updating the status on the cluster
+func SetCertificateExpiringCondition(hwmgr *pluginv1alpha1.HardwareManager, subject string, notAfter, now time.Time) {
+	reason := pluginv1alpha1.ConditionReasons.NotExpiring
+	status := metav1.ConditionFalse
+	message := fmt.Sprintf("Backend certificate %s expires at %s", subject, notAfter.UTC().Format(time.RFC3339))
+
+	switch {
+	case !now.Before(notAfter):
+		reason = pluginv1alpha1.ConditionReasons.Expiring
+		status = metav1.ConditionTrue
+		message = fmt.Sprintf("Backend certificate %s expired at %s", subject, notAfter.UTC().Format(time.RFC3339))
+	case notAfter.Sub(now) < CertificateExpiryWarning:
+		reason = pluginv1alpha1.ConditionReasons.Expiring
+		status = metav1.ConditionTrue
+	}
+
+	SetStatusCondition(&hwmgr.Status.Conditions,
+		string(pluginv1alpha1.ConditionTypes.CertificateExpiring),
+		string(reason),
+		status,
+		message)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/utils/ptr"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Certificates", func() {
+	It("lists the CA bundles of the backend and proxy", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{Spec: pluginv1alpha1.HardwareManagerSpec{
+			RestData: &pluginv1alpha1.RestData{CaBundleName: ptr.To("backend-ca")},
+			Proxy:    &pluginv1alpha1.ProxyConfig{CaBundleName: ptr.To("proxy-ca")},
+		}}
+		Expect(GetHardwareManagerCaBundleNames(hwmgr)).To(Equal([]string{"backend-ca", "proxy-ca"}))
+
+		hwmgr.Spec.Proxy.CaBundleName = ptr.To("backend-ca")
+		Expect(GetHardwareManagerCaBundleNames(hwmgr)).To(Equal([]string{"backend-ca"}))
+
+		hwmgr.Spec = pluginv1alpha1.HardwareManagerSpec{LoopbackData: &pluginv1alpha1.LoopbackData{}}
+		Expect(GetHardwareManagerCaBundleNames(hwmgr)).To(BeEmpty())
+	})
+
+	It("computes a configmap checksum independent of key order", func() {
+		cm1 := &corev1.ConfigMap{Data: map[string]string{"a": "1", "b": "2"}}
+		cm2 := &corev1.ConfigMap{Data: map[string]string{"b": "2", "a": "1"}}
+		cm3 := &corev1.ConfigMap{Data: map[string]string{"a": "12"}}
+		Expect(ComputeConfigMapChecksum(cm1)).To(Equal(ComputeConfigMapChecksum(cm2)))
+		Expect(ComputeConfigMapChecksum(cm1)).ToNot(Equal(ComputeConfigMapChecksum(cm3)))
+	})
+
+	It("reports a backend certificate nearing expiry", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		now := time.Now()
+		conditionType := string(pluginv1alpha1.ConditionTypes.CertificateExpiring)
+
+		SetCertificateExpiringCondition(hwmgr, "CN=hwmgr", now.Add(90*24*time.Hour), now)
+		condition := meta.FindStatusCondition(hwmgr.Status.Conditions, conditionType)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(string(pluginv1alpha1.ConditionReasons.NotExpiring)))
+		Expect(meta.IsStatusConditionFalse(hwmgr.Status.Conditions, conditionType)).To(BeTrue())
+
+		SetCertificateExpiringCondition(hwmgr, "CN=hwmgr", now.Add(7*24*time.Hour), now)
+		condition = meta.FindStatusCondition(hwmgr.Status.Conditions, conditionType)
+		Expect(condition.Reason).To(Equal(string(pluginv1alpha1.ConditionReasons.Expiring)))
+		Expect(condition.Message).To(ContainSubstring("CN=hwmgr expires at"))
+
+		SetCertificateExpiringCondition(hwmgr, "CN=hwmgr", now.Add(-time.Hour), now)
+		condition = meta.FindStatusCondition(hwmgr.Status.Conditions, conditionType)
+		Expect(condition.Status).To(BeEquivalentTo("True"))
+		Expect(condition.Message).To(ContainSubstring("expired at"))
+	})
+})
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Kinds of inconsistency reported by the consistency check
+const (
+	ConsistencyOrphanedBMCSecret  = "OrphanedBMCSecret"
+	ConsistencyMissingBMCSecret   = "MissingBMCSecret"
+	ConsistencyOrphanedAllocation = "OrphanedAllocation"
+	ConsistencyUnallocatedNode    = "UnallocatedNode"
+)
+
+// IsNodePoolSettled returns true if the NodePool has completed provisioning and is not being deleted, such that its
+// Nodes, bmc-secrets and allocation records are expected to be consistent. An interrupted allocation of a NodePool
+// that is still processing is resumed by the adaptor, and is not checked.
+func IsNodePoolSettled(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.DeletionTimestamp.IsZero() && IsNodePoolProvisionedCompleted(nodepool)
+}
+
+// isBMCSecretOwnedBy returns true if the secret is a bmc-secret owned by the NodePool
+func isBMCSecretOwnedBy(secret *corev1.Secret, nodepool *hwmgmtv1alpha1.NodePool) bool {
+	if !strings.HasSuffix(secret.Name, bmcSecretSuffix) {
+		return false
+	}
+	return slices.ContainsFunc(secret.OwnerReferences, func(owner metav1.OwnerReference) bool {
+		return owner.UID == nodepool.UID
+	})
+}
+
+// nodeBMCSecretName returns the name of the bmc-secret of a node, as referenced by its status if set
+func nodeBMCSecretName(node *hwmgmtv1alpha1.Node) string {
+	if node.Status.BMC != nil && node.Status.BMC.CredentialsName != "" {
+		return node.Status.BMC.CredentialsName
+	}
+	return BMCSecretName(node.Name)
+}
+
+// FindOrphanedBMCSecrets returns the names of the bmc-secrets owned by the NodePool that do not belong to any of its
+// Nodes, such as are left behind if the plugin restarts between the creation of the secret and the Node
+func FindOrphanedBMCSecrets(nodepool *hwmgmtv1alpha1.NodePool, nodes []hwmgmtv1alpha1.Node, secrets []corev1.Secret) (orphans []string) {
+	inuse := make(map[string]bool)
+	for i := range nodes {
+		inuse[nodeBMCSecretName(&nodes[i])] = true
+	}
+
+	for i := range secrets {
+		if isBMCSecretOwnedBy(&secrets[i], nodepool) && !inuse[secrets[i].Name] {
+			orphans = append(orphans, secrets[i].Name)
+		}
+	}
+
+	slices.Sort(orphans)
+	return
+}
+
+// FindNodesMissingBMCSecret returns the names of the Nodes with a BMC whose bmc-secret does not exist
+func FindNodesMissingBMCSecret(nodes []hwmgmtv1alpha1.Node, secrets []corev1.Secret) (missing []string) {
+	exists := make(map[string]bool)
+	for i := range secrets {
+		exists[secrets[i].Name] = true
+	}
+
+	for i := range nodes {
+		if nodes[i].Status.BMC != nil && !exists[nodeBMCSecretName(&nodes[i])] {
+			missing = append(missing, nodes[i].Name)
+		}
+	}
+
+	slices.Sort(missing)
+	return
+}
+
+// SortConsistencyIssues orders the issues by NodePool, kind and name, for a stable status
+func SortConsistencyIssues(issues []pluginv1alpha1.ConsistencyIssue) {
+	slices.SortFunc(issues, func(a, b pluginv1alpha1.ConsistencyIssue) int {
+		if c := strings.Compare(a.NodePool, b.NodePool); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Consistency check", func() {
+	newSecret := func(name string, owner types.UID) corev1.Secret {
+		secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if owner != "" {
+			secret.OwnerReferences = []metav1.OwnerReference{{Kind: "NodePool", UID: owner}}
+		}
+		return secret
+	}
+
+	newNode := func(name string, withBMC bool) hwmgmtv1alpha1.Node {
+		node := hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if withBMC {
+			node.Status.BMC = &hwmgmtv1alpha1.BMC{Address: "idrac-virtualmedia+https://192.0.2.1", CredentialsName: BMCSecretName(name)}
+		}
+		return node
+	}
+
+	It("only checks settled NodePools", func() {
+		nodepool := newTestNodePool(nil)
+		Expect(IsNodePoolSettled(nodepool)).To(BeFalse())
+
+		meta.SetStatusCondition(&nodepool.Status.Conditions, metav1.Condition{
+			Type:   string(hwmgmtv1alpha1.Provisioned),
+			Status: metav1.ConditionTrue,
+			Reason: string(hwmgmtv1alpha1.Completed),
+		})
+		Expect(IsNodePoolSettled(nodepool)).To(BeTrue())
+
+		now := metav1.Now()
+		nodepool.DeletionTimestamp = &now
+		Expect(IsNodePoolSettled(nodepool)).To(BeFalse())
+	})
+
+	It("finds the bmc-secrets of the NodePool with no Node", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.UID = "np-uid"
+
+		nodes := []hwmgmtv1alpha1.Node{newNode("node-a", true), newNode("node-b", false)}
+		secrets := []corev1.Secret{
+			newSecret(BMCSecretName("node-a"), "np-uid"),
+			newSecret(BMCSecretName("node-b"), "np-uid"),
+			newSecret(BMCSecretName("node-c"), "np-uid"),
+			newSecret(BMCSecretName("node-d"), "other-uid"),
+			newSecret(BMCSecretName("node-e"), ""),
+			newSecret("np-uid-pull-secret", "np-uid"),
+		}
+
+		Expect(FindOrphanedBMCSecrets(nodepool, nodes, secrets)).To(Equal([]string{BMCSecretName("node-c")}))
+		Expect(FindOrphanedBMCSecrets(nodepool, nodes, secrets[:2])).To(BeEmpty())
+	})
+
+	It("finds the Nodes with no bmc-secret", func() {
+		nodes := []hwmgmtv1alpha1.Node{newNode("node-b", true), newNode("node-a", true), newNode("node-c", false)}
+		secrets := []corev1.Secret{newSecret(BMCSecretName("node-b"), "")}
+
+		Expect(FindNodesMissingBMCSecret(nodes, secrets)).To(Equal([]string{"node-a"}))
+		Expect(FindNodesMissingBMCSecret(nodes, nil)).To(Equal([]string{"node-a", "node-b"}))
+	})
+
+	It("sorts the issues by NodePool, kind and name", func() {
+		issues := []pluginv1alpha1.ConsistencyIssue{
+			{NodePool: "np2", Kind: ConsistencyOrphanedBMCSecret, Name: "a"},
+			{NodePool: "np1", Kind: ConsistencyOrphanedBMCSecret, Name: "b"},
+			{NodePool: "np1", Kind: ConsistencyMissingBMCSecret, Name: "c"},
+			{NodePool: "np1", Kind: ConsistencyOrphanedBMCSecret, Name: "a"},
+		}
+		SortConsistencyIssues(issues)
+
+		Expect(issues).To(Equal([]pluginv1alpha1.ConsistencyIssue{
+			{NodePool: "np1", Kind: ConsistencyMissingBMCSecret, Name: "c"},
+			{NodePool: "np1", Kind: ConsistencyOrphanedBMCSecret, Name: "a"},
+			{NodePool: "np1", Kind: ConsistencyOrphanedBMCSecret, Name: "b"},
+			{NodePool: "np2", Kind: ConsistencyOrphanedBMCSecret, Name: "a"},
+		}))
+	})
+})
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// DecommissionAnnotation requests, on a Node, that the node be decommissioned. The value is the decommission mode.
+	DecommissionAnnotation = "hwmgr-plugin.oran.openshift.io/decommission"
+
+	// DecommissionReportKey is the key of the decommission report in the report configmap
+	DecommissionReportKey = "report"
+
+	decommissionReportSuffix = "-decommission-report"
+)
+
+// DecommissionMode selects whether the disks of the node are wiped when it is decommissioned
+type DecommissionMode string
+
+const (
+	DecommissionModeRetain DecommissionMode = "retain"
+	DecommissionModeWipe   DecommissionMode = "wipe"
+)
+
+// NodeDecommissioned is the condition type set on a Node when its decommission fails
+const NodeDecommissioned hwmgmtv1alpha1.ConditionType = "Decommissioned"
+
+// Disk wipe statuses reported in the decommission report
+const (
+	WipeStatusNotRequested = "NotRequested"
+	WipeStatusCompleted    = "Completed"
+	WipeStatusFailed       = "Failed"
+)
+
+// DecommissionDiskReport is the wipe status of a disk of a decommissioned node
+type DecommissionDiskReport struct {
+	Id           string `json:"id"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+	WipeStatus   string `json:"wipeStatus"`
+}
+
+// DecommissionReport records the details of a decommissioned node, for handoff to asset management
+type DecommissionReport struct {
+	NodeName       string                   `json:"nodeName"`
+	NodePool       string                   `json:"nodePool"`
+	CloudID        string                   `json:"cloudID"`
+	HwMgrId        string                   `json:"hwMgrId"`
+	HwMgrNodeId    string                   `json:"hwMgrNodeId"`
+	SerialNumber   string                   `json:"serialNumber,omitempty"`
+	Mode           DecommissionMode         `json:"mode"`
+	PoweredOff     bool                     `json:"poweredOff"`
+	Disks          []DecommissionDiskReport `json:"disks,omitempty"`
+	StartTime      metav1.Time              `json:"startTime"`
+	CompletionTime metav1.Time              `json:"completionTime"`
+}
+
+// GetNodeDecommissionMode returns the decommission mode requested for the node, and whether a decommission is requested
+func GetNodeDecommissionMode(node *hwmgmtv1alpha1.Node) (DecommissionMode, bool, error) {
+	value, exists := node.GetAnnotations()[DecommissionAnnotation]
+	if !exists {
+		return "", false, nil
+	}
+
+	switch mode := DecommissionMode(value); mode {
+	case DecommissionModeRetain, DecommissionModeWipe:
+		return mode, true, nil
+	default:
+		return "", true, NewInputError("invalid %s annotation %q: must be one of %s, %s",
+			DecommissionAnnotation, value, DecommissionModeRetain, DecommissionModeWipe)
+	}
+}
+
+// DecommissionReportName returns the name of the decommission report configmap for a node
+func DecommissionReportName(nodename string) string {
+	return nodename + decommissionReportSuffix
+}
+
+// NewDecommissionReportConfigMap builds the configmap that holds the decommission report of a node. The configmap is
+// not owned by the Node or NodePool, so that it is retained once they are deleted.
+func NewDecommissionReportConfigMap(namespace string, report *DecommissionReport) (*corev1.ConfigMap, error) {
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal decommission report: %w", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DecommissionReportName(report.NodeName),
+			Namespace: namespace,
+			Labels: map[string]string{
+				ManagedByLabel: ManagedByLabelValue,
+				HwMgrIdLabel:   report.HwMgrId,
+				CloudIdLabel:   report.CloudID,
+			},
+		},
+		Data: map[string]string{
+			DecommissionReportKey: string(data),
+		},
+	}, nil
+}
+
+// SetNodeDecommissionFailed sets the Decommissioned condition of the node to False with reason Failed. The status is not
+// updated on the cluster.
+func SetNodeDecommissionFailed(node *hwmgmtv1alpha1.Node, message string) {
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeDecommissioned),
+		string(hwmgmtv1alpha1.Failed),
+		metav1.ConditionFalse,
+		message)
+}
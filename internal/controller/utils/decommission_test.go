@@ -0,0 +1,77 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node decommission", func() {
+	newNode := func(annotations map[string]string) *hwmgmtv1alpha1.Node {
+		return &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: annotations}}
+	}
+
+	It("is not requested without the annotation", func() {
+		_, requested, err := GetNodeDecommissionMode(newNode(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requested).To(BeFalse())
+	})
+
+	It("returns the requested mode", func() {
+		mode, requested, err := GetNodeDecommissionMode(newNode(map[string]string{DecommissionAnnotation: "wipe"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requested).To(BeTrue())
+		Expect(mode).To(Equal(DecommissionModeWipe))
+	})
+
+	It("rejects an invalid mode", func() {
+		_, requested, err := GetNodeDecommissionMode(newNode(map[string]string{DecommissionAnnotation: "shred"}))
+		Expect(requested).To(BeTrue())
+		Expect(IsInputError(err)).To(BeTrue())
+	})
+
+	It("builds the report configmap", func() {
+		report := &DecommissionReport{
+			NodeName:     "node-1",
+			NodePool:     "np-1",
+			CloudID:      "cloud-1",
+			HwMgrId:      "loopback-1",
+			HwMgrNodeId:  "dummy-sp-64g-0",
+			SerialNumber: "SN0001",
+			Mode:         DecommissionModeWipe,
+			PoweredOff:   true,
+			Disks:        []DecommissionDiskReport{{Id: "disk0", SerialNumber: "DSN0001", WipeStatus: WipeStatusCompleted}},
+		}
+
+		cm, err := NewDecommissionReportConfigMap("test-ns", report)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cm.Name).To(Equal("node-1-decommission-report"))
+		Expect(cm.Namespace).To(Equal("test-ns"))
+		Expect(cm.Labels).To(HaveKeyWithValue(HwMgrIdLabel, "loopback-1"))
+		Expect(cm.Labels).To(HaveKeyWithValue(CloudIdLabel, "cloud-1"))
+
+		var parsed DecommissionReport
+		Expect(yaml.Unmarshal([]byte(cm.Data[DecommissionReportKey]), &parsed)).To(Succeed())
+		Expect(parsed.SerialNumber).To(Equal("SN0001"))
+		Expect(parsed.Disks).To(Equal(report.Disks))
+	})
+})
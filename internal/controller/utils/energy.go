@@ -0,0 +1,133 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// EnergyPolicyKey is the NodePool extensions key that holds the energy policy, overriding that of the hardware
+	// manager for the nodes of the NodePool
+	EnergyPolicyKey = "energyPolicy"
+)
+
+// PowerCap condition type and reasons, reporting the power cap achieved on a node. The condition is only set on nodes
+// with a power cap requested by the energy policy.
+const (
+	NodePowerCap          hwmgmtv1alpha1.ConditionType   = "PowerCap"
+	ReasonPowerCapApplied hwmgmtv1alpha1.ConditionReason = "Applied"
+	ReasonPowerCapLimited hwmgmtv1alpha1.ConditionReason = "Limited"
+	ReasonPowerCapFailed  hwmgmtv1alpha1.ConditionReason = "Failed"
+)
+
+// NodePoolEnergyPolicy is the energy policy of a NodePool, parsed from its extensions
+type NodePoolEnergyPolicy struct {
+	// PowerCapWatts is the power cap applied to each node of the NodePool, in watts. A value of 0 disables the power
+	// cap of the hardware manager for the NodePool
+	PowerCapWatts *int `json:"powerCapWatts,omitempty"`
+}
+
+// GetNodePoolEnergyPolicy parses the energy policy from the NodePool extensions, returning nil if none is specified
+func GetNodePoolEnergyPolicy(nodepool *hwmgmtv1alpha1.NodePool) (*NodePoolEnergyPolicy, error) {
+	data, exists := nodepool.Spec.Extensions[EnergyPolicyKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var policy NodePoolEnergyPolicy
+	if err := yaml.Unmarshal([]byte(data), &policy); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", EnergyPolicyKey, err.Error())
+	}
+
+	return &policy, nil
+}
+
+// ValidateNodePoolEnergyPolicy validates the energy policy of the NodePool
+func ValidateNodePoolEnergyPolicy(nodepool *hwmgmtv1alpha1.NodePool) error {
+	policy, err := GetNodePoolEnergyPolicy(nodepool)
+	if err != nil {
+		return err
+	}
+
+	if policy != nil && policy.PowerCapWatts != nil && *policy.PowerCapWatts < 0 {
+		return NewInputError("invalid power cap %d: must not be negative", *policy.PowerCapWatts)
+	}
+
+	return nil
+}
+
+// GetNodePoolPowerCap returns the power cap for the nodes of the NodePool, in watts, with the energy policy of the
+// NodePool taking precedence over that of the hardware manager. A value of 0 indicates the nodes are not capped.
+func GetNodePoolPowerCap(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (int, error) {
+	policy, err := GetNodePoolEnergyPolicy(nodepool)
+	if err != nil {
+		return 0, err
+	}
+
+	if policy != nil && policy.PowerCapWatts != nil {
+		return *policy.PowerCapWatts, nil
+	}
+
+	if hwmgr.Spec.EnergyPolicy != nil {
+		return hwmgr.Spec.EnergyPolicy.PowerCapWatts, nil
+	}
+
+	return 0, nil
+}
+
+// SetNodePowerCapStatus sets the PowerCap condition on the node with the outcome of applying the requested power cap,
+// returning true if the condition has changed. A requested cap of 0 removes the condition. The status is not updated
+// on the cluster.
+func SetNodePowerCapStatus(node *hwmgmtv1alpha1.Node, requested, achieved int, applyErr error) bool {
+	if requested == 0 {
+		return meta.RemoveStatusCondition(&node.Status.Conditions, string(NodePowerCap))
+	}
+
+	var reason hwmgmtv1alpha1.ConditionReason
+	var status metav1.ConditionStatus
+	var message string
+	switch {
+	case applyErr != nil:
+		reason = ReasonPowerCapFailed
+		status = metav1.ConditionFalse
+		message = fmt.Sprintf("Failed to apply power cap of %dW: %s", requested, applyErr.Error())
+	case achieved != requested:
+		reason = ReasonPowerCapLimited
+		status = metav1.ConditionTrue
+		message = fmt.Sprintf("Power cap of %dW applied, adjusted from the requested %dW", achieved, requested)
+	default:
+		reason = ReasonPowerCapApplied
+		status = metav1.ConditionTrue
+		message = fmt.Sprintf("Power cap of %dW applied", achieved)
+	}
+
+	condition := meta.FindStatusCondition(node.Status.Conditions, string(NodePowerCap))
+	if condition != nil && condition.Reason == string(reason) && condition.Message == message {
+		return false
+	}
+
+	SetStatusCondition(&node.Status.Conditions, string(NodePowerCap), string(reason), status, message)
+	return true
+}
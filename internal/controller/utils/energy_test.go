@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Energy policy", func() {
+	hwmgr := &pluginv1alpha1.HardwareManager{
+		Spec: pluginv1alpha1.HardwareManagerSpec{
+			EnergyPolicy: &pluginv1alpha1.EnergyPolicy{PowerCapWatts: 400},
+		},
+	}
+
+	It("uses the power cap of the hardware manager by default", func() {
+		powercap, err := GetNodePoolPowerCap(hwmgr, newTestNodePool(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(powercap).To(Equal(400))
+
+		powercap, err = GetNodePoolPowerCap(&pluginv1alpha1.HardwareManager{}, newTestNodePool(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(powercap).To(BeZero())
+	})
+
+	It("overrides the power cap from the NodePool extension", func() {
+		powercap, err := GetNodePoolPowerCap(hwmgr, newTestNodePool(map[string]string{EnergyPolicyKey: "powerCapWatts: 250"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(powercap).To(Equal(250))
+
+		powercap, err = GetNodePoolPowerCap(hwmgr, newTestNodePool(map[string]string{EnergyPolicyKey: "powerCapWatts: 0"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(powercap).To(BeZero())
+	})
+
+	It("rejects a negative power cap", func() {
+		nodepool := newTestNodePool(map[string]string{EnergyPolicyKey: "powerCapWatts: -1"})
+		Expect(ValidateNodePoolEnergyPolicy(nodepool)).To(MatchError(ContainSubstring("must not be negative")))
+	})
+
+	It("reports the achieved power cap on the node", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		Expect(SetNodePowerCapStatus(node, 300, 300, nil)).To(BeTrue())
+		Expect(SetNodePowerCapStatus(node, 300, 300, nil)).To(BeFalse())
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(NodePowerCap))
+		Expect(condition.Reason).To(Equal(string(ReasonPowerCapApplied)))
+
+		Expect(SetNodePowerCapStatus(node, 300, 350, nil)).To(BeTrue())
+		condition = meta.FindStatusCondition(node.Status.Conditions, string(NodePowerCap))
+		Expect(condition.Reason).To(Equal(string(ReasonPowerCapLimited)))
+		Expect(condition.Message).To(ContainSubstring("350W applied"))
+
+		Expect(SetNodePowerCapStatus(node, 300, 0, errors.New("not supported"))).To(BeTrue())
+		condition = meta.FindStatusCondition(node.Status.Conditions, string(NodePowerCap))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+		Expect(SetNodePowerCapStatus(node, 0, 0, nil)).To(BeTrue())
+		Expect(meta.FindStatusCondition(node.Status.Conditions, string(NodePowerCap))).To(BeNil())
+	})
+})
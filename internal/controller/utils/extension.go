@@ -0,0 +1,151 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodePoolExtensionAnnotation records, on a NodePool being extended, the number of nodes being added to each
+	// nodegroup
+	NodePoolExtensionAnnotation = "hwmgr-plugin.oran.openshift.io/extension"
+)
+
+// Extended condition type and reasons, reporting the nodes added to the nodegroups of a provisioned NodePool
+const (
+	NodePoolExtended      hwmgmtv1alpha1.ConditionType   = "Extended"
+	ReasonExtending       hwmgmtv1alpha1.ConditionReason = "Extending"
+	ReasonExtended        hwmgmtv1alpha1.ConditionReason = "Extended"
+	ReasonExtensionFailed hwmgmtv1alpha1.ConditionReason = "ExtensionFailed"
+)
+
+// GetNodePoolExtension returns the number of nodes to be added to each nodegroup whose size exceeds its allocated
+// nodes. Nodegroups that have shrunk are not included, as the existing nodes are never released by an extension.
+func GetNodePoolExtension(nodepool *hwmgmtv1alpha1.NodePool, nodelist *hwmgmtv1alpha1.NodeList) map[string]int {
+	allocated := make(map[string]int)
+	for _, node := range nodelist.Items {
+		allocated[node.Spec.GroupName]++
+	}
+
+	var extension map[string]int
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		groupname := nodegroup.NodePoolData.Name
+		if delta := nodegroup.Size - allocated[groupname]; delta > 0 {
+			if extension == nil {
+				extension = make(map[string]int)
+			}
+			extension[groupname] = delta
+		}
+	}
+
+	return extension
+}
+
+// FormatNodePoolExtension returns a summary of the nodes added to each nodegroup, ordered by nodegroup name
+func FormatNodePoolExtension(extension map[string]int) string {
+	groupnames := make([]string, 0, len(extension))
+	for groupname := range extension {
+		groupnames = append(groupnames, groupname)
+	}
+	slices.Sort(groupnames)
+
+	deltas := make([]string, 0, len(groupnames))
+	for _, groupname := range groupnames {
+		deltas = append(deltas, fmt.Sprintf("%s: +%d", groupname, extension[groupname]))
+	}
+
+	return strings.Join(deltas, ", ")
+}
+
+// IsNodePoolExtending returns true if an extension of the NodePool is in progress, including one that is being
+// retried after a failure
+func IsNodePoolExtending(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolExtended))
+	return condition != nil && condition.Status == metav1.ConditionFalse
+}
+
+// IsNodePoolExtension returns true if a spec change of a provisioned NodePool is to be handled as an extension,
+// either because one is in progress or because a nodegroup has grown beyond its allocated nodes
+func IsNodePoolExtension(nodepool *hwmgmtv1alpha1.NodePool, nodelist *hwmgmtv1alpha1.NodeList) bool {
+	return IsNodePoolExtending(nodepool) || len(GetNodePoolExtension(nodepool, nodelist)) > 0
+}
+
+// GetNodePoolExtensionAnnotation returns the extension recorded on the NodePool, if any
+func GetNodePoolExtensionAnnotation(nodepool *hwmgmtv1alpha1.NodePool) (map[string]int, error) {
+	data, exists := nodepool.GetAnnotations()[NodePoolExtensionAnnotation]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var extension map[string]int
+	if err := yaml.Unmarshal([]byte(data), &extension); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", NodePoolExtensionAnnotation, err)
+	}
+
+	return extension, nil
+}
+
+// SetNodePoolExtensionAnnotation records the extension on the NodePool. A nil extension removes the annotation.
+func SetNodePoolExtensionAnnotation(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, extension map[string]int) error {
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	annotations := nodepool.GetAnnotations()
+	if extension == nil {
+		if _, exists := annotations[NodePoolExtensionAnnotation]; !exists {
+			return nil
+		}
+		delete(annotations, NodePoolExtensionAnnotation)
+	} else {
+		data, err := yaml.Marshal(extension)
+		if err != nil {
+			return fmt.Errorf("failed to marshal extension for NodePool %s: %w", nodepool.Name, err)
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[NodePoolExtensionAnnotation] = string(data)
+	}
+	nodepool.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, nodepool, patch); err != nil {
+		return fmt.Errorf("failed to annotate NodePool %s with extension: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// IsNodePoolHwProfileApplied returns true if every node has the hardware profile of its nodegroup. Otherwise, the
+// spec change that extended the NodePool also changed a hardware profile, which is applied after the extension.
+func IsNodePoolHwProfileApplied(nodepool *hwmgmtv1alpha1.NodePool, nodelist *hwmgmtv1alpha1.NodeList) bool {
+	for _, node := range nodelist.Items {
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			if node.Spec.GroupName == nodegroup.NodePoolData.Name && node.Spec.HwProfile != nodegroup.NodePoolData.HwProfile {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+func newTestNodeList(groupnames ...string) *hwmgmtv1alpha1.NodeList {
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	for _, groupname := range groupnames {
+		nodelist.Items = append(nodelist.Items, hwmgmtv1alpha1.Node{Spec: hwmgmtv1alpha1.NodeSpec{GroupName: groupname}})
+	}
+	return nodelist
+}
+
+var _ = Describe("NodePool extension", func() {
+	It("reports no extension for a fully allocated nodepool", func() {
+		nodepool := newTestNodePool(nil)
+		nodelist := newTestNodeList("master")
+
+		Expect(GetNodePoolExtension(nodepool, nodelist)).To(BeNil())
+		Expect(IsNodePoolExtension(nodepool, nodelist)).To(BeFalse())
+	})
+
+	It("reports the nodes to add to each grown nodegroup", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.NodeGroup[0].Size = 3
+		nodepool.Spec.NodeGroup[1].Size = 2
+		nodelist := newTestNodeList("master")
+
+		extension := GetNodePoolExtension(nodepool, nodelist)
+		Expect(extension).To(Equal(map[string]int{"master": 2, "worker": 2}))
+		Expect(FormatNodePoolExtension(extension)).To(Equal("master: +2, worker: +2"))
+		Expect(IsNodePoolExtension(nodepool, nodelist)).To(BeTrue())
+	})
+
+	It("ignores nodegroups that have shrunk", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.NodeGroup[1].Size = 1
+		nodelist := newTestNodeList("master", "master", "worker")
+
+		Expect(GetNodePoolExtension(nodepool, nodelist)).To(BeNil())
+	})
+
+	It("treats a failed extension as still in progress", func() {
+		nodepool := newTestNodePool(nil)
+		nodelist := newTestNodeList("master")
+		Expect(IsNodePoolExtending(nodepool)).To(BeFalse())
+
+		SetStatusCondition(&nodepool.Status.Conditions, string(NodePoolExtended), string(ReasonExtensionFailed),
+			metav1.ConditionFalse, "Extension failed")
+		Expect(IsNodePoolExtending(nodepool)).To(BeTrue())
+		Expect(IsNodePoolExtension(nodepool, nodelist)).To(BeTrue())
+
+		SetStatusCondition(&nodepool.Status.Conditions, string(NodePoolExtended), string(ReasonExtended),
+			metav1.ConditionTrue, "Added nodes: worker: +1")
+		Expect(IsNodePoolExtending(nodepool)).To(BeFalse())
+	})
+
+	It("parses the recorded extension", func() {
+		nodepool := newTestNodePool(nil)
+		extension, err := GetNodePoolExtensionAnnotation(nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(extension).To(BeNil())
+
+		nodepool.SetAnnotations(map[string]string{NodePoolExtensionAnnotation: "worker: 2\n"})
+		extension, err = GetNodePoolExtensionAnnotation(nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(extension).To(Equal(map[string]int{"worker": 2}))
+
+		nodepool.SetAnnotations(map[string]string{NodePoolExtensionAnnotation: "worker: [2]"})
+		_, err = GetNodePoolExtensionAnnotation(nodepool)
+		Expect(err).To(MatchError(ContainSubstring("failed to parse")))
+	})
+
+	It("detects hardware profile changes left to apply", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.NodeGroup[0].NodePoolData.HwProfile = "profile-v2"
+		nodelist := newTestNodeList("master", "master")
+		nodelist.Items[0].Spec.HwProfile = "profile-v2"
+		nodelist.Items[1].Spec.HwProfile = "profile-v1"
+		Expect(IsNodePoolHwProfileApplied(nodepool, nodelist)).To(BeFalse())
+
+		nodelist.Items[1].Spec.HwProfile = "profile-v2"
+		Expect(IsNodePoolHwProfileApplied(nodepool, nodelist)).To(BeTrue())
+	})
+})
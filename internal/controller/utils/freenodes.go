@@ -0,0 +1,37 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+// FreeNodeQuery describes a prospective nodegroup, for listing the free nodes of a resource pool that could be
+// allocated to it
+type FreeNodeQuery struct {
+	// ResourcePoolId is the resource pool of the nodegroup
+	ResourcePoolId string
+	// Selector is the node selector of the nodegroup. A nil selector matches any node.
+	Selector *NodeSelector
+	// HwProfile is the hardware profile of the nodegroup, which may restrict the nodes that can be allocated, such
+	// as by its storage layout
+	HwProfile string
+}
+
+// FreeNode is a free node that could be allocated to a prospective nodegroup
+type FreeNode struct {
+	NodeId         string
+	ResourcePoolId string
+	Attributes     NodeAttributes
+	Site           string
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// FulfillmentPolicyKey is the NodePool extensions key that holds the fulfillment policies, keyed by nodegroup name
+	FulfillmentPolicyKey = "fulfillmentPolicy"
+)
+
+// Fulfillment condition type and reasons, reporting the shortfall of nodegroups with a BestEffort fulfillment policy
+const (
+	NodePoolFulfillment      hwmgmtv1alpha1.ConditionType   = "Fulfillment"
+	ReasonFullyFulfilled     hwmgmtv1alpha1.ConditionReason = "FullyFulfilled"
+	ReasonPartiallyFulfilled hwmgmtv1alpha1.ConditionReason = "PartiallyFulfilled"
+)
+
+// FulfillmentPolicy defines how a nodegroup is allocated when there are not enough free nodes to satisfy its size
+type FulfillmentPolicy string
+
+const (
+	// FulfillmentAll fails the allocation unless every node of the nodegroup can be allocated
+	FulfillmentAll FulfillmentPolicy = "All"
+	// FulfillmentBestEffort allocates the nodes that are available, reporting the shortfall in the NodePool status
+	FulfillmentBestEffort FulfillmentPolicy = "BestEffort"
+)
+
+// GetNodePoolFulfillmentPolicies parses the fulfillment policies from the NodePool extensions
+func GetNodePoolFulfillmentPolicies(nodepool *hwmgmtv1alpha1.NodePool) (map[string]FulfillmentPolicy, error) {
+	data, exists := nodepool.Spec.Extensions[FulfillmentPolicyKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var policies map[string]FulfillmentPolicy
+	if err := yaml.Unmarshal([]byte(data), &policies); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", FulfillmentPolicyKey, err.Error())
+	}
+
+	return policies, nil
+}
+
+// GetNodeGroupFulfillmentPolicy returns the fulfillment policy for a nodegroup, defaulting to All
+func GetNodeGroupFulfillmentPolicy(nodepool *hwmgmtv1alpha1.NodePool, groupname string) (FulfillmentPolicy, error) {
+	policies, err := GetNodePoolFulfillmentPolicies(nodepool)
+	if err != nil {
+		return "", err
+	}
+
+	if policy, exists := policies[groupname]; exists && policy != "" {
+		return policy, nil
+	}
+
+	return FulfillmentAll, nil
+}
+
+// IsNodeGroupBestEffort returns true if the nodegroup has a BestEffort fulfillment policy. An invalid policy is
+// treated as All, as it is rejected on admission.
+func IsNodeGroupBestEffort(nodepool *hwmgmtv1alpha1.NodePool, groupname string) bool {
+	policy, err := GetNodeGroupFulfillmentPolicy(nodepool, groupname)
+	return err == nil && policy == FulfillmentBestEffort
+}
+
+// ValidateNodePoolFulfillmentPolicies validates that the fulfillment policies reference defined nodegroups, with
+// supported values
+func ValidateNodePoolFulfillmentPolicies(nodepool *hwmgmtv1alpha1.NodePool) error {
+	policies, err := GetNodePoolFulfillmentPolicies(nodepool)
+	if err != nil {
+		return err
+	}
+
+	for groupname, policy := range policies {
+		if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		}) {
+			return NewInputError("fulfillment policy specified for unknown nodegroup %s", groupname)
+		}
+		if policy != FulfillmentAll && policy != FulfillmentBestEffort {
+			return NewInputError("unsupported fulfillment policy %q for nodegroup %s", policy, groupname)
+		}
+	}
+
+	return nil
+}
+
+// DescribeNodePoolFulfillment summarizes the allocation of the nodegroups with a BestEffort fulfillment policy, given
+// the number of nodes allocated to each nodegroup, returning false if any of them has a shortfall
+func DescribeNodePoolFulfillment(nodepool *hwmgmtv1alpha1.NodePool, allocated map[string]int) (bool, string) {
+	fulfilled := true
+	var counts []string
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		groupname := nodegroup.NodePoolData.Name
+		if !IsNodeGroupBestEffort(nodepool, groupname) {
+			continue
+		}
+		if allocated[groupname] < nodegroup.Size {
+			fulfilled = false
+		}
+		counts = append(counts, fmt.Sprintf("%s: %d/%d", groupname, allocated[groupname], nodegroup.Size))
+	}
+
+	return fulfilled, "Allocated nodes: " + strings.Join(counts, ", ")
+}
+
+// UpdateNodePoolFulfillmentCondition reports the shortfall of the nodegroups with a BestEffort fulfillment policy in
+// the Fulfillment condition, based on the group names of the allocated nodes. The condition is not set for a NodePool
+// without a BestEffort nodegroup.
+func UpdateNodePoolFulfillmentCondition(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodelist *hwmgmtv1alpha1.NodeList) error {
+
+	if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+		return IsNodeGroupBestEffort(nodepool, nodegroup.NodePoolData.Name)
+	}) {
+		return nil
+	}
+
+	allocated := make(map[string]int)
+	for _, node := range nodelist.Items {
+		allocated[node.Spec.GroupName]++
+	}
+
+	reason := ReasonFullyFulfilled
+	status := metav1.ConditionTrue
+	fulfilled, message := DescribeNodePoolFulfillment(nodepool, allocated)
+	if !fulfilled {
+		reason = ReasonPartiallyFulfilled
+		status = metav1.ConditionFalse
+	}
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolFulfillment))
+	if current != nil && current.Reason == string(reason) && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolFulfillment, reason, status, message)
+}
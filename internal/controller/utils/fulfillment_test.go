@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fulfillment policies", func() {
+	It("parses and defaults the policy for a nodegroup", func() {
+		nodepool := newTestNodePool(map[string]string{FulfillmentPolicyKey: `
+worker: BestEffort
+`})
+		Expect(ValidateNodePoolFulfillmentPolicies(nodepool)).To(Succeed())
+
+		policy, err := GetNodeGroupFulfillmentPolicy(nodepool, "worker")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy).To(Equal(FulfillmentBestEffort))
+		Expect(IsNodeGroupBestEffort(nodepool, "worker")).To(BeTrue())
+
+		policy, err = GetNodeGroupFulfillmentPolicy(nodepool, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy).To(Equal(FulfillmentAll))
+		Expect(IsNodeGroupBestEffort(nodepool, "master")).To(BeFalse())
+	})
+
+	It("rejects an invalid policy", func() {
+		Expect(ValidateNodePoolFulfillmentPolicies(newTestNodePool(map[string]string{FulfillmentPolicyKey: `
+storage: BestEffort
+`}))).To(MatchError(ContainSubstring("unknown nodegroup storage")))
+
+		Expect(ValidateNodePoolFulfillmentPolicies(newTestNodePool(map[string]string{FulfillmentPolicyKey: `
+master: Most
+`}))).To(MatchError(ContainSubstring("unsupported fulfillment policy")))
+	})
+
+	It("describes the shortfall of best effort nodegroups", func() {
+		nodepool := newTestNodePool(map[string]string{FulfillmentPolicyKey: `
+master: BestEffort
+`})
+		nodepool.Spec.NodeGroup[1].Size = 2
+
+		fulfilled, message := DescribeNodePoolFulfillment(nodepool, map[string]int{"master": 1, "worker": 1})
+		Expect(fulfilled).To(BeTrue())
+		Expect(message).To(Equal("Allocated nodes: master: 1/1"))
+
+		fulfilled, message = DescribeNodePoolFulfillment(nodepool, map[string]int{"worker": 2})
+		Expect(fulfilled).To(BeFalse())
+		Expect(message).To(Equal("Allocated nodes: master: 0/1"))
+	})
+})
@@ -19,11 +19,18 @@ package utils
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -55,6 +62,21 @@ func IsHardwareManagerValidationFailed(hwmgr *pluginv1alpha1.HardwareManager) bo
 	return false
 }
 
+// HardwareManagerSupports returns true if the capability is published in the status of the hardware manager
+func HardwareManagerSupports(hwmgr *pluginv1alpha1.HardwareManager, capability pluginv1alpha1.AdaptorCapability) bool {
+	return slices.Contains(hwmgr.Status.Capabilities, capability)
+}
+
+// SetHardwareManagerCapabilities records the capabilities in the status of the hardware manager, returning true if they
+// have changed. The hardware manager is not updated on the cluster.
+func SetHardwareManagerCapabilities(hwmgr *pluginv1alpha1.HardwareManager, capabilities []pluginv1alpha1.AdaptorCapability) bool {
+	if slices.Equal(hwmgr.Status.Capabilities, capabilities) {
+		return false
+	}
+	hwmgr.Status.Capabilities = slices.Clone(capabilities)
+	return true
+}
+
 func IsHardwareManagerLogMessagesEnabled(hwmgr *pluginv1alpha1.HardwareManager) bool {
 	annotations := hwmgr.GetAnnotations()
 	if annotations == nil {
@@ -73,15 +95,119 @@ func UpdateHardwareManagerStatusCondition(
 	conditionStatus metav1.ConditionStatus,
 	message string) error {
 
-	SetStatusCondition(&hwmgr.Status.Conditions,
-		string(conditionType),
-		string(conditionReason),
-		conditionStatus,
-		message)
-
-	if err := UpdateK8sCRStatus(ctx, c, hwmgr); err != nil {
+	if err := UpdateK8sCRStatus(ctx, c, hwmgr, func() {
+		SetStatusCondition(&hwmgr.Status.Conditions,
+			string(conditionType),
+			string(conditionReason),
+			conditionStatus,
+			message)
+	}); err != nil {
 		return fmt.Errorf("failed to update hwmgr status %s: %w", hwmgr.Name, err)
 	}
 
 	return nil
 }
+
+// HardwareManagerSelectsNodePool returns true if the NodePool labels match the nodePoolSelector of the hardware
+// manager. All NodePools are selected if no selector is set.
+func HardwareManagerSelectsNodePool(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
+	if hwmgr.Spec.NodePoolSelector == nil {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(hwmgr.Spec.NodePoolSelector)
+	if err != nil {
+		return false, NewInputError("invalid nodePoolSelector for HardwareManager %s: %s", hwmgr.Name, err.Error())
+	}
+
+	return selector.Matches(labels.Set(nodepool.GetLabels())), nil
+}
+
+// NotSelected condition type and reasons, set on a NodePool that is not served by the HardwareManager referenced by its
+// hwMgrId. The condition does not fail the NodePool, which is processed once it is served by the HardwareManager.
+const (
+	NodePoolNotSelected hwmgmtv1alpha1.ConditionType   = "NotSelected"
+	ReasonNotSelected   hwmgmtv1alpha1.ConditionReason = "NotSelected"
+	ReasonSelected      hwmgmtv1alpha1.ConditionReason = "Selected"
+)
+
+// IsNodePoolNotSelected returns true if the NodePool has been marked with the NotSelected condition
+func IsNodePoolNotSelected(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(NodePoolNotSelected))
+}
+
+// UpdateNodePoolNotSelectedCondition sets the NotSelected condition of the NodePool with the given reason and message,
+// or clears it if the message is empty. The condition is only cleared if it was previously set, and the status is only
+// updated if the condition has changed.
+func UpdateNodePoolNotSelectedCondition(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	reason hwmgmtv1alpha1.ConditionReason,
+	message string) error {
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolNotSelected))
+	if message == "" {
+		if current == nil || current.Status == metav1.ConditionFalse {
+			return nil
+		}
+		return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolNotSelected, ReasonSelected,
+			metav1.ConditionFalse, "NodePool is served by HardwareManager "+nodepool.Spec.HwMgrId)
+	}
+
+	if current != nil && current.Status == metav1.ConditionTrue && current.Reason == string(reason) &&
+		current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolNotSelected, reason, metav1.ConditionTrue, message)
+}
+
+// HardwareManagerConfigChanged is a predicate that passes HardwareManager updates that change its spec or the outcome
+// of its validation, so that the NodePools it serves pick up the new configuration without waiting for a requeue
+func HardwareManagerConfigChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldHwmgr, ok := e.ObjectOld.(*pluginv1alpha1.HardwareManager)
+			if !ok {
+				return false
+			}
+			newHwmgr, ok := e.ObjectNew.(*pluginv1alpha1.HardwareManager)
+			if !ok {
+				return false
+			}
+
+			return oldHwmgr.Generation != newHwmgr.Generation ||
+				IsHardwareManagerValidationCompleted(oldHwmgr) != IsHardwareManagerValidationCompleted(newHwmgr)
+		},
+	}
+}
+
+// MapHardwareManagerToNodePools enqueues the NodePools served by a HardwareManager
+func MapHardwareManagerToNodePools(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		hwmgr, ok := obj.(*pluginv1alpha1.HardwareManager)
+		if !ok {
+			return nil
+		}
+
+		// NodePools are listed in each of the watch namespaces, as a HardwareManager may serve NodePools in other
+		// namespaces
+		var requests []reconcile.Request
+		for _, namespace := range GetHardwareManagerWatchNamespaces(hwmgr) {
+			nodepools := &hwmgmtv1alpha1.NodePoolList{}
+			if err := c.List(ctx, nodepools, client.InNamespace(namespace)); err != nil {
+				utilsLog.InfoContext(ctx, "Unable to list NodePools for HardwareManager",
+					"hwmgr", hwmgr.Name, "namespace", namespace, "error", err.Error())
+				continue
+			}
+
+			for i := range nodepools.Items {
+				if nodepools.Items[i].Spec.HwMgrId == hwmgr.Name {
+					requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&nodepools.Items[i])})
+				}
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("HardwareManager NodePool selector", func() {
+	It("selects all NodePools when unset", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		selected, err := HardwareManagerSelectsNodePool(hwmgr, newTestNodePool(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selected).To(BeTrue())
+	})
+
+	It("selects NodePools by label", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				NodePoolSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			},
+		}
+
+		nodepool := newTestNodePool(nil)
+		selected, err := HardwareManagerSelectsNodePool(hwmgr, nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selected).To(BeFalse())
+
+		nodepool.SetLabels(map[string]string{"tenant": "b"})
+		selected, err = HardwareManagerSelectsNodePool(hwmgr, nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selected).To(BeFalse())
+
+		nodepool.SetLabels(map[string]string{"tenant": "a"})
+		selected, err = HardwareManagerSelectsNodePool(hwmgr, nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selected).To(BeTrue())
+	})
+
+	It("rejects an invalid selector", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				NodePoolSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: "Bogus"}},
+				},
+			},
+		}
+		selected, err := HardwareManagerSelectsNodePool(hwmgr, newTestNodePool(nil))
+		Expect(err).To(HaveOccurred())
+		Expect(selected).To(BeFalse())
+	})
+})
+
+var _ = Describe("HardwareManager capabilities", func() {
+	It("records and checks the capabilities in the status", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		Expect(HardwareManagerSupports(hwmgr, pluginv1alpha1.CapabilityPowerControl)).To(BeFalse())
+
+		capabilities := []pluginv1alpha1.AdaptorCapability{pluginv1alpha1.CapabilityPowerControl, pluginv1alpha1.CapabilityScaleUp}
+		Expect(SetHardwareManagerCapabilities(hwmgr, capabilities)).To(BeTrue())
+		Expect(HardwareManagerSupports(hwmgr, pluginv1alpha1.CapabilityPowerControl)).To(BeTrue())
+		Expect(HardwareManagerSupports(hwmgr, pluginv1alpha1.CapabilityScaleDown)).To(BeFalse())
+
+		Expect(SetHardwareManagerCapabilities(hwmgr, capabilities)).To(BeFalse())
+		Expect(SetHardwareManagerCapabilities(hwmgr, nil)).To(BeTrue())
+		Expect(hwmgr.Status.Capabilities).To(BeEmpty())
+	})
+})
@@ -0,0 +1,288 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// TenantServiceAccountAnnotation names the service account, in the namespace of the NodePool, that is impersonated
+	// for the changes triggered by the NodePool when tenant impersonation is enabled on its HardwareManager
+	TenantServiceAccountAnnotation = "hwmgr-plugin.oran.openshift.io/tenantServiceAccount"
+)
+
+// TenantAuthorized condition type and reasons, reporting whether the tenant service account of a NodePool is permitted
+// to make the changes triggered by the NodePool
+const (
+	NodePoolTenantAuthorized hwmgmtv1alpha1.ConditionType   = "TenantAuthorized"
+	ReasonTenantAuthorized   hwmgmtv1alpha1.ConditionReason = "Authorized"
+	ReasonTenantForbidden    hwmgmtv1alpha1.ConditionReason = "Forbidden"
+)
+
+// TenantRequiredPermissions are the permissions a tenant service account needs in the namespace of its NodePool, for
+// the plugin to create and maintain the Node CRs and bmc-secrets, and report the NodePool status, on its behalf
+var TenantRequiredPermissions = []authorizationv1.ResourceAttributes{
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodes", Verb: "create"},
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodes", Verb: "update"},
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodes", Verb: "patch"},
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodes", Verb: "delete"},
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodes", Subresource: "status", Verb: "update"},
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodes", Subresource: "status", Verb: "patch"},
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodepools", Verb: "patch"},
+	{Group: hwmgmtv1alpha1.GroupVersion.Group, Resource: "nodepools", Subresource: "status", Verb: "update"},
+	{Group: "", Resource: "secrets", Verb: "create"},
+	{Group: "", Resource: "secrets", Verb: "update"},
+	{Group: "", Resource: "secrets", Verb: "patch"},
+	{Group: "", Resource: "secrets", Verb: "delete"},
+}
+
+// GetNodePoolTenantServiceAccount returns the tenant service account of the NodePool, or an empty string if tenant
+// impersonation is not enabled on the HardwareManager. An InputError is returned if impersonation is enabled but the
+// NodePool does not name a valid service account.
+func GetNodePoolTenantServiceAccount(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
+	if !hwmgr.Spec.TenantImpersonation {
+		return "", nil
+	}
+
+	name := nodepool.GetAnnotations()[TenantServiceAccountAnnotation]
+	if name == "" {
+		return "", NewInputError("tenant impersonation is enabled on HardwareManager %s, but the NodePool has no %s annotation",
+			hwmgr.Name, TenantServiceAccountAnnotation)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", NewInputError("invalid %s annotation %q: %s", TenantServiceAccountAnnotation, name, strings.Join(errs, ", "))
+	}
+
+	return name, nil
+}
+
+// TenantUsername returns the username of a service account, as impersonated for the tenant
+func TenantUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// CheckTenantAccess verifies, with a SubjectAccessReview for each of the TenantRequiredPermissions, that the tenant
+// service account is permitted to make the changes triggered by a NodePool in its namespace. An InputError describing
+// the first missing permission is returned if not.
+func CheckTenantAccess(ctx context.Context, c client.Client, namespace, name string) error {
+	username := TenantUsername(namespace, name)
+	for _, attrs := range TenantRequiredPermissions {
+		attrs.Namespace = namespace
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:               username,
+				Groups:             []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace},
+				ResourceAttributes: &attrs,
+			},
+		}
+		if err := c.Create(ctx, review); err != nil {
+			return fmt.Errorf("failed to review access of %s: %w", username, err)
+		}
+		if !review.Status.Allowed {
+			resource := attrs.Resource
+			if attrs.Subresource != "" {
+				resource += "/" + attrs.Subresource
+			}
+			return NewInputError("service account %s is not permitted to %s %s in namespace %s",
+				name, attrs.Verb, resource, namespace)
+		}
+	}
+
+	return nil
+}
+
+// UpdateNodePoolTenantCondition reports the outcome of the access check of the tenant service account in the
+// TenantAuthorized condition
+func UpdateNodePoolTenantCondition(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, name string, accessErr error) error {
+	reason := ReasonTenantAuthorized
+	status := metav1.ConditionTrue
+	message := "Changes are made as service account " + name
+	if accessErr != nil {
+		reason = ReasonTenantForbidden
+		status = metav1.ConditionFalse
+		message = accessErr.Error()
+	}
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolTenantAuthorized))
+	if current != nil && current.Reason == string(reason) && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolTenantAuthorized, reason, status, message)
+}
+
+type tenantContextKey struct{}
+
+// tenant identifies the service account impersonated for the changes in its namespace
+type tenant struct {
+	namespace string
+	name      string
+}
+
+// WithTenant returns a context in which a TenantClient makes the changes to objects in the namespace as the named
+// service account
+func WithTenant(ctx context.Context, namespace, name string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant{namespace: namespace, name: name})
+}
+
+// GetTenant returns the username of the service account impersonated in the context for changes to objects in the
+// namespace, if any
+func GetTenant(ctx context.Context, namespace string) (string, bool) {
+	t, ok := ctx.Value(tenantContextKey{}).(tenant)
+	if !ok || t.namespace != namespace {
+		return "", false
+	}
+	return TenantUsername(t.namespace, t.name), true
+}
+
+// TenantClient is a client that makes changes to objects in the namespace of the tenant set in the context by
+// WithTenant, impersonating its service account. Reads, and changes to objects in other namespaces, such as the
+// plugin namespace, are made with the base client.
+type TenantClient struct {
+	client.Client
+	config  *rest.Config
+	options client.Options
+
+	mu      sync.Mutex
+	clients map[string]client.Client
+}
+
+// TenantClient implements client.Client. This ensures that we've conformed to the interface with a compile-time check
+var _ client.Client = (*TenantClient)(nil)
+
+// NewTenantClient returns a TenantClient that wraps the base client, creating the impersonating clients from the
+// rest config
+func NewTenantClient(base client.Client, config *rest.Config) *TenantClient {
+	return &TenantClient{
+		Client:  base,
+		config:  config,
+		options: client.Options{Scheme: base.Scheme(), Mapper: base.RESTMapper()},
+		clients: make(map[string]client.Client),
+	}
+}
+
+// writer returns the client used to change the object, creating the impersonating client for the tenant if needed.
+// Impersonating clients read from the API server directly, but are only used for changes.
+func (c *TenantClient) writer(ctx context.Context, obj client.Object) (client.Client, error) {
+	username, ok := GetTenant(ctx, obj.GetNamespace())
+	if !ok {
+		return c.Client, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if impersonating, exists := c.clients[username]; exists {
+		return impersonating, nil
+	}
+
+	config := rest.CopyConfig(c.config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: username}
+	base, err := client.New(config, c.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client impersonating %s: %w", username, err)
+	}
+	impersonating := NewReadOnlyClient(base)
+	c.clients[username] = impersonating
+	return impersonating, nil
+}
+
+func (c *TenantClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	w, err := c.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return w.Create(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (c *TenantClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	w, err := c.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return w.Delete(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (c *TenantClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w, err := c.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return w.Update(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (c *TenantClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w, err := c.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return w.Patch(ctx, obj, patch, opts...) // nolint: wrapcheck
+}
+
+func (c *TenantClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	w, err := c.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return w.DeleteAllOf(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+// Status returns a status writer that likewise impersonates the tenant for the status of objects in its namespace
+func (c *TenantClient) Status() client.SubResourceWriter {
+	return &tenantStatusWriter{client: c}
+}
+
+type tenantStatusWriter struct {
+	client *TenantClient
+}
+
+func (w *tenantStatusWriter) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	c, err := w.client.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return c.Status().Create(ctx, obj, subResource, opts...) // nolint: wrapcheck
+}
+
+func (w *tenantStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	c, err := w.client.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return c.Status().Update(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (w *tenantStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	c, err := w.client.writer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return c.Status().Patch(ctx, obj, patch, opts...) // nolint: wrapcheck
+}
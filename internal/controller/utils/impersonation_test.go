@@ -0,0 +1,72 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Tenant impersonation", func() {
+	It("ignores the tenant annotation when impersonation is disabled", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		nodepool := newTestNodePool(nil)
+		nodepool.Annotations = map[string]string{TenantServiceAccountAnnotation: "team-a"}
+
+		name, err := GetNodePoolTenantServiceAccount(hwmgr, nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(BeEmpty())
+	})
+
+	It("requires a valid tenant service account when impersonation is enabled", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		hwmgr.Name = "hwmgr"
+		hwmgr.Spec.TenantImpersonation = true
+		nodepool := newTestNodePool(nil)
+
+		_, err := GetNodePoolTenantServiceAccount(hwmgr, nodepool)
+		Expect(IsInputError(err)).To(BeTrue())
+
+		nodepool.Annotations = map[string]string{TenantServiceAccountAnnotation: "Team_A"}
+		_, err = GetNodePoolTenantServiceAccount(hwmgr, nodepool)
+		Expect(IsInputError(err)).To(BeTrue())
+
+		nodepool.Annotations[TenantServiceAccountAnnotation] = "team-a"
+		name, err := GetNodePoolTenantServiceAccount(hwmgr, nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal("team-a"))
+	})
+
+	It("scopes the impersonated tenant to its namespace", func() {
+		Expect(TenantUsername("tenants", "team-a")).To(Equal("system:serviceaccount:tenants:team-a"))
+
+		_, ok := GetTenant(context.Background(), "tenants")
+		Expect(ok).To(BeFalse())
+
+		ctx := WithTenant(context.Background(), "tenants", "team-a")
+		username, ok := GetTenant(ctx, "tenants")
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("system:serviceaccount:tenants:team-a"))
+
+		_, ok = GetTenant(ctx, "oran-hwmgr-plugin")
+		Expect(ok).To(BeFalse())
+	})
+})
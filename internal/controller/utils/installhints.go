@@ -0,0 +1,182 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// InstallHintsAnnotation publishes the hostname and provisioning network details intended for a node, as reported
+	// by the backend. The Node status is defined by the O2IMS API, so these are recorded as an annotation on the Node
+	// CR, for use by cluster installers in building the install-config and agent-config of the cluster.
+	InstallHintsAnnotation = "hwmgr-plugin.oran.openshift.io/installHints"
+)
+
+// IPConfigMode is how the provisioning interface of a node is addressed
+type IPConfigMode string
+
+// Supported IP configuration modes
+const (
+	IPConfigDHCP   IPConfigMode = "dhcp"
+	IPConfigStatic IPConfigMode = "static"
+)
+
+// ParseIPConfigMode returns the IP configuration mode for a value reported by a backend, ignoring case. An empty value
+// is not reported.
+func ParseIPConfigMode(value string) (IPConfigMode, error) {
+	switch mode := IPConfigMode(strings.ToLower(value)); mode {
+	case "", IPConfigDHCP, IPConfigStatic:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid IP configuration mode %q, expected %s or %s", value, IPConfigDHCP, IPConfigStatic)
+	}
+}
+
+// StaticIPConfig is the static addressing of the provisioning interface of a node
+type StaticIPConfig struct {
+	// Address is the IP address of the interface, with its prefix length, such as 192.168.1.10/24
+	Address    string   `json:"address"`
+	Gateway    string   `json:"gateway,omitempty"`
+	DNSServers []string `json:"dnsServers,omitempty"`
+}
+
+// NodeInstallHints holds the hostname and provisioning network details intended for a node. Empty fields are not
+// reported by the backend.
+type NodeInstallHints struct {
+	Hostname               string          `json:"hostname,omitempty"`
+	ProvisioningMACAddress string          `json:"provisioningMacAddress,omitempty"`
+	IPConfig               IPConfigMode    `json:"ipConfig,omitempty"`
+	Static                 *StaticIPConfig `json:"static,omitempty"`
+}
+
+// IsEmpty returns true if no install hints are reported
+func (hints NodeInstallHints) IsEmpty() bool {
+	return hints.Hostname == "" && hints.ProvisioningMACAddress == "" && hints.IPConfig == "" && hints.Static == nil
+}
+
+// ValidateNodeInstallHints checks that the install hints reported by a backend are usable by a cluster installer
+func ValidateNodeInstallHints(hints NodeInstallHints) error {
+	if hints.Hostname != "" {
+		if errs := validation.IsDNS1123Subdomain(hints.Hostname); len(errs) > 0 {
+			return fmt.Errorf("invalid hostname %q: %s", hints.Hostname, strings.Join(errs, ", "))
+		}
+	}
+
+	if hints.ProvisioningMACAddress != "" {
+		if _, err := net.ParseMAC(hints.ProvisioningMACAddress); err != nil {
+			return fmt.Errorf("invalid provisioning MAC address %q: %w", hints.ProvisioningMACAddress, err)
+		}
+	}
+
+	if mode, err := ParseIPConfigMode(string(hints.IPConfig)); err != nil {
+		return err
+	} else if mode != hints.IPConfig {
+		return fmt.Errorf("invalid IP configuration mode %q, expected %s", hints.IPConfig, mode)
+	}
+
+	if hints.Static == nil {
+		if hints.IPConfig == IPConfigStatic {
+			return fmt.Errorf("static IP configuration missing address")
+		}
+		return nil
+	}
+	if hints.IPConfig != IPConfigStatic {
+		return fmt.Errorf("static addressing is only valid for the %s IP configuration mode", IPConfigStatic)
+	}
+	if _, _, err := net.ParseCIDR(hints.Static.Address); err != nil {
+		return fmt.Errorf("invalid static address %q, expected an address with prefix length: %w", hints.Static.Address, err)
+	}
+	if hints.Static.Gateway != "" && net.ParseIP(hints.Static.Gateway) == nil {
+		return fmt.Errorf("invalid gateway address %q", hints.Static.Gateway)
+	}
+	for _, server := range hints.Static.DNSServers {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("invalid DNS server address %q", server)
+		}
+	}
+
+	return nil
+}
+
+// ResolveInstallHints returns the install hints with the provisioning MAC address, if not reported by the backend,
+// taken from the first interface with the provisioning role. The hostname and MAC address are case-insensitive, and
+// are reported in lowercase.
+func ResolveInstallHints(hints NodeInstallHints, roles []NodeInterfaceRole) NodeInstallHints {
+	hints.Hostname = strings.ToLower(hints.Hostname)
+	if hints.ProvisioningMACAddress == "" {
+		for _, role := range roles {
+			if role.Role == InterfaceRoleProvisioning && role.MACAddress != "" {
+				hints.ProvisioningMACAddress = role.MACAddress
+				break
+			}
+		}
+	}
+	hints.ProvisioningMACAddress = strings.ToLower(hints.ProvisioningMACAddress)
+	return hints
+}
+
+// GetNodeInstallHints returns the install hints published on the node, or nil if there are none
+func GetNodeInstallHints(node client.Object) (*NodeInstallHints, error) {
+	data, exists := node.GetAnnotations()[InstallHintsAnnotation]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	hints := &NodeInstallHints{}
+	if err := json.Unmarshal([]byte(data), hints); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", InstallHintsAnnotation, err)
+	}
+	return hints, nil
+}
+
+// SetNodeInstallHints publishes the install hints on the node, removing the annotation if none are reported, and
+// returns true if the annotation has changed. The node is not updated on the cluster.
+func SetNodeInstallHints(node client.Object, hints NodeInstallHints) (bool, error) {
+	annotations := node.GetAnnotations()
+	current, exists := annotations[InstallHintsAnnotation]
+
+	if hints.IsEmpty() {
+		if !exists {
+			return false, nil
+		}
+		delete(annotations, InstallHintsAnnotation)
+		node.SetAnnotations(annotations)
+		return true, nil
+	}
+
+	data, err := json.Marshal(hints)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal install hints: %w", err)
+	}
+	if exists && current == string(data) {
+		return false, nil
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[InstallHintsAnnotation] = string(data)
+	node.SetAnnotations(annotations)
+	return true, nil
+}
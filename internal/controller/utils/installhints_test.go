@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Install hints", func() {
+	staticHints := func() NodeInstallHints {
+		return NodeInstallHints{
+			Hostname:               "master-0.cluster1.example.com",
+			ProvisioningMACAddress: "aa:bb:cc:dd:ee:01",
+			IPConfig:               IPConfigStatic,
+			Static: &StaticIPConfig{
+				Address:    "192.168.1.10/24",
+				Gateway:    "192.168.1.1",
+				DNSServers: []string{"192.168.1.2", "2001:db8::53"},
+			},
+		}
+	}
+
+	It("parses the IP configuration mode", func() {
+		Expect(ParseIPConfigMode("DHCP")).To(Equal(IPConfigDHCP))
+		Expect(ParseIPConfigMode("static")).To(Equal(IPConfigStatic))
+		Expect(ParseIPConfigMode("")).To(BeEmpty())
+		_, err := ParseIPConfigMode("bootp")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("validates the hints", func() {
+		Expect(ValidateNodeInstallHints(staticHints())).To(Succeed())
+		Expect(ValidateNodeInstallHints(NodeInstallHints{IPConfig: IPConfigDHCP})).To(Succeed())
+		Expect(ValidateNodeInstallHints(NodeInstallHints{})).To(Succeed())
+
+		for _, mutate := range []func(*NodeInstallHints){
+			func(h *NodeInstallHints) { h.Hostname = "Master_0" },
+			func(h *NodeInstallHints) { h.ProvisioningMACAddress = "not-a-mac" },
+			func(h *NodeInstallHints) { h.IPConfig = "DHCP" },
+			func(h *NodeInstallHints) { h.IPConfig = IPConfigDHCP },
+			func(h *NodeInstallHints) { h.Static = nil },
+			func(h *NodeInstallHints) { h.Static.Address = "192.168.1.10" },
+			func(h *NodeInstallHints) { h.Static.Gateway = "gateway" },
+			func(h *NodeInstallHints) { h.Static.DNSServers = []string{"dns"} },
+		} {
+			hints := staticHints()
+			mutate(&hints)
+			Expect(ValidateNodeInstallHints(hints)).ToNot(Succeed())
+		}
+	})
+
+	It("takes the provisioning MAC address from the interface roles if not reported", func() {
+		roles := []NodeInterfaceRole{
+			{Name: "eth0", Label: "data-interface", MACAddress: "AA:BB:CC:DD:EE:00", Role: InterfaceRoleData},
+			{Name: "eth1", Label: "bootable-interface", MACAddress: "AA:BB:CC:DD:EE:01", Role: InterfaceRoleProvisioning},
+		}
+		Expect(ResolveInstallHints(NodeInstallHints{Hostname: "Master-0"}, roles)).To(Equal(NodeInstallHints{
+			Hostname:               "master-0",
+			ProvisioningMACAddress: "aa:bb:cc:dd:ee:01",
+		}))
+
+		hints := ResolveInstallHints(NodeInstallHints{ProvisioningMACAddress: "AA:BB:CC:DD:EE:02"}, roles)
+		Expect(hints.ProvisioningMACAddress).To(Equal("aa:bb:cc:dd:ee:02"))
+
+		Expect(ResolveInstallHints(NodeInstallHints{}, nil).IsEmpty()).To(BeTrue())
+	})
+
+	It("publishes the hints as an annotation", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		hints := staticHints()
+
+		changed, err := SetNodeInstallHints(node, hints)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(GetNodeInstallHints(node)).To(Equal(&hints))
+
+		changed, err = SetNodeInstallHints(node, hints)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		changed, err = SetNodeInstallHints(node, NodeInstallHints{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(node.Annotations).ToNot(HaveKey(InstallHintsAnnotation))
+		Expect(GetNodeInstallHints(node)).To(BeNil())
+	})
+
+	It("rejects an invalid annotation", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Annotations = map[string]string{InstallHintsAnnotation: "not-json"}
+		_, err := GetNodeInstallHints(node)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// InterfaceRolesAnnotation publishes the roles of the interfaces in the Node status. The Interface type is defined
+	// by the O2IMS API, so the roles are recorded as an annotation on the Node CR, for use by cluster installers in
+	// templating the node network configuration.
+	InterfaceRolesAnnotation = "hwmgr-plugin.oran.openshift.io/interfaceRoles"
+)
+
+// NodeInterfaceRole tags an interface of the Node status with its role
+type NodeInterfaceRole struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	MACAddress string `json:"macAddress"`
+	Role       string `json:"role"`
+}
+
+// GetHwProfileInterfaceRoles returns the interface roles defined for a hardware profile, keyed by interface label
+func GetHwProfileInterfaceRoles(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string) map[string]string {
+	for i := range hwmgr.Spec.HwProfiles {
+		if hwmgr.Spec.HwProfiles[i].Name != hwprofile || len(hwmgr.Spec.HwProfiles[i].InterfaceRoles) == 0 {
+			continue
+		}
+		roles := make(map[string]string)
+		for _, tag := range hwmgr.Spec.HwProfiles[i].InterfaceRoles {
+			roles[tag.Label] = tag.Role
+		}
+		return roles
+	}
+	return nil
+}
+
+// ResolveInterfaceRoles returns the role of each interface that has one, in the order of the interfaces. The role
+// reported by the backend for an interface label takes precedence over the role defined by the hardware profile.
+func ResolveInterfaceRoles(interfaces []*hwmgmtv1alpha1.Interface, backendRoles, profileRoles map[string]string) []NodeInterfaceRole {
+	var roles []NodeInterfaceRole
+	for _, iface := range interfaces {
+		role, exists := backendRoles[iface.Label]
+		if !exists || role == "" {
+			role = profileRoles[iface.Label]
+		}
+		if role == "" {
+			continue
+		}
+		roles = append(roles, NodeInterfaceRole{Name: iface.Name, Label: iface.Label, MACAddress: iface.MACAddress, Role: role})
+	}
+	return roles
+}
+
+// GetNodeInterfaceRoles returns the interface roles published on the node
+func GetNodeInterfaceRoles(node client.Object) ([]NodeInterfaceRole, error) {
+	data, exists := node.GetAnnotations()[InterfaceRolesAnnotation]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var roles []NodeInterfaceRole
+	if err := json.Unmarshal([]byte(data), &roles); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", InterfaceRolesAnnotation, err)
+	}
+	return roles, nil
+}
+
+// SetNodeInterfaceRoles publishes the interface roles on the node, removing the annotation if there are none, and
+// returns true if the annotation has changed. The node is not updated on the cluster.
+func SetNodeInterfaceRoles(node client.Object, roles []NodeInterfaceRole) (bool, error) {
+	annotations := node.GetAnnotations()
+	current, exists := annotations[InterfaceRolesAnnotation]
+
+	if len(roles) == 0 {
+		if !exists {
+			return false, nil
+		}
+		delete(annotations, InterfaceRolesAnnotation)
+		node.SetAnnotations(annotations)
+		return true, nil
+	}
+
+	data, err := json.Marshal(roles)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal interface roles: %w", err)
+	}
+	if exists && current == string(data) {
+		return false, nil
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[InterfaceRolesAnnotation] = string(data)
+	node.SetAnnotations(annotations)
+	return true, nil
+}
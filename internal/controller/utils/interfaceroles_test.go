@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Interface roles", func() {
+	interfaces := []*hwmgmtv1alpha1.Interface{
+		{Name: "eno1", Label: "bootable-interface", MACAddress: "aa:bb:cc:dd:ee:01"},
+		{Name: "eno2", Label: "data-interface", MACAddress: "aa:bb:cc:dd:ee:02"},
+		{Name: "eno3", Label: "spare-interface", MACAddress: "aa:bb:cc:dd:ee:03"},
+	}
+
+	It("returns the roles of the hardware profile", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		hwmgr.Spec.HwProfiles = []pluginv1alpha1.HardwareProfile{
+			{Name: "profile-a", InterfaceRoles: []pluginv1alpha1.InterfaceRoleTag{
+				{Label: "bootable-interface", Role: "provisioning"},
+				{Label: "data-interface", Role: "data"},
+			}},
+			{Name: "profile-b"},
+		}
+
+		Expect(GetHwProfileInterfaceRoles(hwmgr, "profile-a")).To(Equal(map[string]string{
+			"bootable-interface": "provisioning",
+			"data-interface":     "data",
+		}))
+		Expect(GetHwProfileInterfaceRoles(hwmgr, "profile-b")).To(BeNil())
+		Expect(GetHwProfileInterfaceRoles(hwmgr, "unknown")).To(BeNil())
+	})
+
+	It("prefers the backend role over the profile role", func() {
+		roles := ResolveInterfaceRoles(interfaces,
+			map[string]string{"data-interface": "storage"},
+			map[string]string{"bootable-interface": "provisioning", "data-interface": "data"})
+
+		Expect(roles).To(Equal([]NodeInterfaceRole{
+			{Name: "eno1", Label: "bootable-interface", MACAddress: "aa:bb:cc:dd:ee:01", Role: "provisioning"},
+			{Name: "eno2", Label: "data-interface", MACAddress: "aa:bb:cc:dd:ee:02", Role: "storage"},
+		}))
+		Expect(ResolveInterfaceRoles(interfaces, nil, nil)).To(BeEmpty())
+	})
+
+	It("publishes the roles as an annotation", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		roles := ResolveInterfaceRoles(interfaces, nil, map[string]string{"data-interface": "data"})
+
+		changed, err := SetNodeInterfaceRoles(node, roles)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(GetNodeInterfaceRoles(node)).To(Equal(roles))
+
+		changed, err = SetNodeInterfaceRoles(node, roles)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		changed, err = SetNodeInterfaceRoles(node, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(node.Annotations).ToNot(HaveKey(InterfaceRolesAnnotation))
+	})
+})
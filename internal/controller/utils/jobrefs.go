@@ -0,0 +1,192 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// JobRefsAnnotation records, as a JSON list, the backend jobs issued for a NodePool or Node, most recent last
+	JobRefsAnnotation = "hwmgr-plugin.oran.openshift.io/jobRefs"
+
+	// MaxJobRefs is the number of job references retained on a CR
+	MaxJobRefs = 10
+)
+
+// BackendJob condition type, set on a NodePool or Node to identify the latest backend job issued for it.
+// BackendJobPhase condition type, set on a NodePool or Node to report the phase of its running backend job, for
+// backends that report the progress of their jobs.
+const (
+	BackendJob      hwmgmtv1alpha1.ConditionType = "BackendJob"
+	BackendJobPhase hwmgmtv1alpha1.ConditionType = "BackendJobPhase"
+)
+
+// JobRef identifies a job or transaction issued to the backend hardware manager
+type JobRef struct {
+	Id             string `json:"id"`
+	Operation      string `json:"operation,omitempty"`
+	StartTime      string `json:"startTime"`
+	CompletionTime string `json:"completionTime,omitempty"`
+	// Phase is the latest phase reported by the backend for the running job, entered at PhaseTime
+	Phase     string `json:"phase,omitempty"`
+	PhaseTime string `json:"phaseTime,omitempty"`
+}
+
+// GetJobRefs returns the job references recorded on the object, ignoring an invalid annotation
+func GetJobRefs(object client.Object) []JobRef {
+	data, exists := object.GetAnnotations()[JobRefsAnnotation]
+	if !exists {
+		return nil
+	}
+
+	var refs []JobRef
+	if err := json.Unmarshal([]byte(data), &refs); err != nil {
+		return nil
+	}
+	return refs
+}
+
+func setJobRefs(object client.Object, refs []JobRef) {
+	if len(refs) > MaxJobRefs {
+		refs = refs[len(refs)-MaxJobRefs:]
+	}
+
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return
+	}
+
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[JobRefsAnnotation] = string(data)
+	object.SetAnnotations(annotations)
+}
+
+// addJobRef appends a reference for a newly started job
+func addJobRef(object client.Object, jobId, operation string) {
+	refs := append(GetJobRefs(object), JobRef{
+		Id:        jobId,
+		Operation: operation,
+		StartTime: time.Now().UTC().Format(time.RFC3339),
+	})
+	setJobRefs(object, refs)
+}
+
+// completeJobRef records the completion time of the referenced job
+func completeJobRef(object client.Object, jobId string) {
+	refs := GetJobRefs(object)
+	for i := len(refs) - 1; i >= 0; i-- {
+		if refs[i].Id == jobId && refs[i].CompletionTime == "" {
+			refs[i].CompletionTime = time.Now().UTC().Format(time.RFC3339)
+			setJobRefs(object, refs)
+			return
+		}
+	}
+}
+
+// SetJobPhase records the phase of the referenced running job, returning true if the phase has changed
+func SetJobPhase(object client.Object, jobId, phase string) bool {
+	refs := GetJobRefs(object)
+	for i := len(refs) - 1; i >= 0; i-- {
+		if refs[i].Id == jobId && refs[i].CompletionTime == "" {
+			if refs[i].Phase == phase {
+				return false
+			}
+			refs[i].Phase = phase
+			refs[i].PhaseTime = time.Now().UTC().Format(time.RFC3339)
+			setJobRefs(object, refs)
+			return true
+		}
+	}
+	return false
+}
+
+// SetBackendJobCondition sets the BackendJob condition on a NodePool or Node from its latest job reference, along with
+// the BackendJobPhase condition if the backend has reported the phase of the job. The status is not updated on the
+// cluster.
+func SetBackendJobCondition(object client.Object) {
+	var conditions *[]metav1.Condition
+	switch obj := object.(type) {
+	case *hwmgmtv1alpha1.NodePool:
+		conditions = &obj.Status.Conditions
+	case *hwmgmtv1alpha1.Node:
+		conditions = &obj.Status.Conditions
+	default:
+		return
+	}
+
+	refs := GetJobRefs(object)
+	if len(refs) == 0 {
+		return
+	}
+	ref := refs[len(refs)-1]
+
+	message := fmt.Sprintf("jobId=%s operation=%s started=%s", ref.Id, ref.Operation, ref.StartTime)
+	if ref.CompletionTime == "" {
+		SetStatusCondition(conditions,
+			string(BackendJob),
+			string(hwmgmtv1alpha1.InProgress),
+			metav1.ConditionTrue,
+			message)
+	} else {
+		SetStatusCondition(conditions,
+			string(BackendJob),
+			string(hwmgmtv1alpha1.Completed),
+			metav1.ConditionFalse,
+			message+" completed="+ref.CompletionTime)
+	}
+
+	switch {
+	case ref.CompletionTime != "":
+		if meta.FindStatusCondition(*conditions, string(BackendJobPhase)) != nil {
+			SetStatusCondition(conditions,
+				string(BackendJobPhase),
+				string(hwmgmtv1alpha1.Completed),
+				metav1.ConditionFalse,
+				fmt.Sprintf("jobId=%s operation=%s completed=%s", ref.Id, ref.Operation, ref.CompletionTime))
+		}
+	case ref.Phase != "":
+		SetStatusCondition(conditions,
+			string(BackendJobPhase),
+			ref.Phase,
+			metav1.ConditionTrue,
+			fmt.Sprintf("jobId=%s operation=%s phase=%s since=%s", ref.Id, ref.Operation, ref.Phase, ref.PhaseTime))
+	default:
+		// The phase of an earlier job no longer applies
+		meta.RemoveStatusCondition(conditions, string(BackendJobPhase))
+	}
+}
+
+// UpdateBackendJobStatus sets the BackendJob condition on a NodePool or Node and updates its status
+func UpdateBackendJobStatus(ctx context.Context, c client.Client, object client.Object) error {
+	if err := UpdateK8sCRStatus(ctx, c, object, func() { SetBackendJobCondition(object) }); err != nil {
+		return fmt.Errorf("failed to update backend job status for %s: %w", object.GetName(), err)
+	}
+	return nil
+}
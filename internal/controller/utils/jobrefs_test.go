@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Backend job references", func() {
+	It("records started and completed jobs", func() {
+		nodepool := newTestNodePool(nil)
+
+		SetJobId(nodepool, "job-1", "CreateResourceGroup")
+		Expect(GetJobId(nodepool)).To(Equal("job-1"))
+		refs := GetJobRefs(nodepool)
+		Expect(refs).To(HaveLen(1))
+		Expect(refs[0].Id).To(Equal("job-1"))
+		Expect(refs[0].Operation).To(Equal("CreateResourceGroup"))
+		Expect(refs[0].CompletionTime).To(BeEmpty())
+
+		SetBackendJobCondition(nodepool)
+		condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(BackendJob))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(hwmgmtv1alpha1.InProgress)))
+		Expect(condition.Message).To(ContainSubstring("jobId=job-1"))
+
+		ClearJobId(nodepool)
+		Expect(GetJobId(nodepool)).To(BeEmpty())
+		refs = GetJobRefs(nodepool)
+		Expect(refs).To(HaveLen(1))
+		Expect(refs[0].CompletionTime).ToNot(BeEmpty())
+
+		SetBackendJobCondition(nodepool)
+		condition = meta.FindStatusCondition(nodepool.Status.Conditions, string(BackendJob))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(hwmgmtv1alpha1.Completed)))
+	})
+
+	It("reports the phase of a running job", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		SetJobId(node, "job-1", "UpdateResourceProfile")
+		SetBackendJobCondition(node)
+		Expect(meta.FindStatusCondition(node.Status.Conditions, string(BackendJobPhase))).To(BeNil())
+
+		Expect(SetJobPhase(node, "job-1", "Rebooting")).To(BeTrue())
+		Expect(SetJobPhase(node, "job-1", "Rebooting")).To(BeFalse())
+		Expect(SetJobPhase(node, "job-2", "Verifying")).To(BeFalse())
+
+		SetBackendJobCondition(node)
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(BackendJobPhase))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("Rebooting"))
+		Expect(condition.Message).To(ContainSubstring("phase=Rebooting"))
+
+		ClearJobId(node)
+		SetBackendJobCondition(node)
+		condition = meta.FindStatusCondition(node.Status.Conditions, string(BackendJobPhase))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(hwmgmtv1alpha1.Completed)))
+
+		SetJobId(node, "job-2", "UpdateResourceProfile")
+		SetBackendJobCondition(node)
+		Expect(meta.FindStatusCondition(node.Status.Conditions, string(BackendJobPhase))).To(BeNil())
+	})
+
+	It("retains only the most recent jobs", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		for i := 0; i < MaxJobRefs+3; i++ {
+			SetJobId(node, fmt.Sprintf("job-%d", i), "UpdateResourceProfile")
+			ClearJobId(node)
+		}
+
+		refs := GetJobRefs(node)
+		Expect(refs).To(HaveLen(MaxJobRefs))
+		Expect(refs[0].Id).To(Equal("job-3"))
+		Expect(refs[MaxJobRefs-1].Id).To(Equal(fmt.Sprintf("job-%d", MaxJobRefs+2)))
+	})
+})
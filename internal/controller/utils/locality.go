@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// LocalityPreferenceKey is the NodePool extensions key that holds the locality preference for its nodes
+	LocalityPreferenceKey = "localityPreference"
+)
+
+// LocalityKey identifies the location metadata of a node that is matched against the NodePool
+type LocalityKey string
+
+const (
+	// LocalitySite matches the site of the node against the NodePool spec.site
+	LocalitySite LocalityKey = "site"
+	// LocalityLocation matches the location, or zone, of the node against the NodePool spec.location
+	LocalityLocation LocalityKey = "location"
+)
+
+// NodeLocality is the location metadata of a node, as reported by the backend
+type NodeLocality struct {
+	Site     string `json:"site,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// Get returns the locality of the node for the given key
+func (l NodeLocality) Get(key LocalityKey) string {
+	switch key {
+	case LocalitySite:
+		return l.Site
+	case LocalityLocation:
+		return l.Location
+	default:
+		return ""
+	}
+}
+
+// LocalityPreference selects the nodes of a NodePool whose location metadata matches the location of the NodePool, so
+// that latency-sensitive clusters are assembled from hardware in the right location
+type LocalityPreference struct {
+	// TopologyKey is the location metadata of the node that is matched against the NodePool
+	TopologyKey LocalityKey `json:"topologyKey"`
+	// AllowFallback allows nodes in other locations to be allocated when no local node is free
+	AllowFallback bool `json:"allowFallback,omitempty"`
+}
+
+// GetNodePoolLocalityPreference parses the locality preference from the NodePool extensions, returning nil if none is
+// specified
+func GetNodePoolLocalityPreference(nodepool *hwmgmtv1alpha1.NodePool) (*LocalityPreference, error) {
+	data, exists := nodepool.Spec.Extensions[LocalityPreferenceKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	preference := &LocalityPreference{}
+	if err := yaml.Unmarshal([]byte(data), preference); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", LocalityPreferenceKey, err.Error())
+	}
+
+	return preference, nil
+}
+
+// GetNodePoolLocality returns the location of the NodePool for the given key
+func GetNodePoolLocality(nodepool *hwmgmtv1alpha1.NodePool, key LocalityKey) string {
+	return NodeLocality{Site: nodepool.Spec.Site, Location: nodepool.Spec.Location}.Get(key)
+}
+
+// ValidateNodePoolLocalityPreference validates that the locality preference has a supported topology key, for which
+// the NodePool specifies a location
+func ValidateNodePoolLocalityPreference(nodepool *hwmgmtv1alpha1.NodePool) error {
+	preference, err := GetNodePoolLocalityPreference(nodepool)
+	if err != nil || preference == nil {
+		return err
+	}
+
+	if preference.TopologyKey != LocalitySite && preference.TopologyKey != LocalityLocation {
+		return NewInputError("unsupported topologyKey %q in locality preference, expected %s or %s",
+			preference.TopologyKey, LocalitySite, LocalityLocation)
+	}
+	if GetNodePoolLocality(nodepool, preference.TopologyKey) == "" {
+		return NewInputError("locality preference requires the NodePool %s to be set", preference.TopologyKey)
+	}
+
+	return nil
+}
+
+// FilterLocalCandidates returns the candidate nodes, in order, whose locality matches the location of the NodePool. If
+// no candidate is local and fallback is allowed, all candidates are returned. A nil preference returns all candidates.
+func FilterLocalCandidates(
+	preference *LocalityPreference,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	candidates []string,
+	localityOf func(nodeId string) NodeLocality) ([]string, error) {
+
+	if preference == nil {
+		return candidates, nil
+	}
+
+	want := GetNodePoolLocality(nodepool, preference.TopologyKey)
+	if want == "" {
+		return nil, NewInputError("locality preference requires the NodePool %s to be set", preference.TopologyKey)
+	}
+
+	var filtered []string
+	for _, nodeId := range candidates {
+		if localityOf(nodeId).Get(preference.TopologyKey) == want {
+			filtered = append(filtered, nodeId)
+		}
+	}
+
+	if len(filtered) == 0 {
+		if !preference.AllowFallback {
+			return nil, fmt.Errorf("no free node in %s %s", preference.TopologyKey, want)
+		}
+		return candidates, nil
+	}
+
+	return filtered, nil
+}
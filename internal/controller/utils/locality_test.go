@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Locality preference", func() {
+	localities := map[string]NodeLocality{
+		"node-1": {Site: "site-a", Location: "zone-1"},
+		"node-2": {Site: "site-b", Location: "zone-1"},
+		"node-3": {Site: "site-a", Location: "zone-2"},
+		"node-4": {},
+	}
+	localityOf := func(nodeId string) NodeLocality { return localities[nodeId] }
+	candidates := []string{"node-1", "node-2", "node-3", "node-4"}
+
+	It("parses and validates the preference", func() {
+		nodepool := newTestNodePool(map[string]string{LocalityPreferenceKey: `
+topologyKey: site
+allowFallback: true
+`})
+		nodepool.Spec.Site = "site-a"
+		Expect(ValidateNodePoolLocalityPreference(nodepool)).To(Succeed())
+
+		preference, err := GetNodePoolLocalityPreference(nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(preference).To(Equal(&LocalityPreference{TopologyKey: LocalitySite, AllowFallback: true}))
+
+		preference, err = GetNodePoolLocalityPreference(newTestNodePool(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(preference).To(BeNil())
+	})
+
+	It("rejects an invalid preference", func() {
+		nodepool := newTestNodePool(map[string]string{LocalityPreferenceKey: "topologyKey: rack"})
+		Expect(ValidateNodePoolLocalityPreference(nodepool)).To(MatchError(ContainSubstring("unsupported topologyKey")))
+
+		nodepool = newTestNodePool(map[string]string{LocalityPreferenceKey: "topologyKey: location"})
+		Expect(ValidateNodePoolLocalityPreference(nodepool)).To(MatchError(ContainSubstring("NodePool location to be set")))
+	})
+
+	It("selects the local nodes", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.Site = "site-a"
+		nodepool.Spec.Location = "zone-1"
+
+		filtered, err := FilterLocalCandidates(&LocalityPreference{TopologyKey: LocalitySite}, nodepool, candidates, localityOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal([]string{"node-1", "node-3"}))
+
+		filtered, err = FilterLocalCandidates(&LocalityPreference{TopologyKey: LocalityLocation}, nodepool, candidates, localityOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal([]string{"node-1", "node-2"}))
+
+		filtered, err = FilterLocalCandidates(nil, nodepool, candidates, localityOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal(candidates))
+	})
+
+	It("only falls back to other locations when allowed", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.Site = "site-c"
+
+		_, err := FilterLocalCandidates(&LocalityPreference{TopologyKey: LocalitySite}, nodepool, candidates, localityOf)
+		Expect(err).To(MatchError(ContainSubstring("no free node in site site-c")))
+
+		filtered, err := FilterLocalCandidates(&LocalityPreference{TopologyKey: LocalitySite, AllowFallback: true},
+			nodepool, candidates, localityOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal(candidates))
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// MaintenancePending condition type and reasons, set on a NodePool that draws nodes or spares from a resource pool
+// under maintenance
+const (
+	NodePoolMaintenancePending hwmgmtv1alpha1.ConditionType   = "MaintenancePending"
+	ReasonPoolInMaintenance    hwmgmtv1alpha1.ConditionReason = "PoolInMaintenance"
+	ReasonMaintenanceCompleted hwmgmtv1alpha1.ConditionReason = "MaintenanceCompleted"
+)
+
+// IsResourcePoolInMaintenance returns true if the resource pool is listed in the maintenancePools of the hardware
+// manager
+func IsResourcePoolInMaintenance(hwmgr *pluginv1alpha1.HardwareManager, poolID string) bool {
+	return slices.Contains(hwmgr.Spec.MaintenancePools, poolID)
+}
+
+// GetNodePoolMaintenancePools returns the resource pools under maintenance from which the NodePool draws nodes or
+// spares, sorted. Nodegroups without nodes are not considered.
+func GetNodePoolMaintenancePools(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) []string {
+	if len(hwmgr.Spec.MaintenancePools) == 0 {
+		return nil
+	}
+
+	// An invalid spare configuration is reported when the spares are reconciled
+	spares, _ := GetNodePoolSpareConfig(nodepool)
+
+	var pools []string
+	add := func(poolID string) {
+		if IsResourcePoolInMaintenance(hwmgr, poolID) && !slices.Contains(pools, poolID) {
+			pools = append(pools, poolID)
+		}
+	}
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if nodegroup.Size > 0 {
+			add(nodegroup.NodePoolData.ResourcePoolId)
+		}
+		if config, exists := spares[nodegroup.NodePoolData.Name]; exists && config.Count > 0 {
+			add(config.SparePoolId)
+		}
+	}
+
+	slices.Sort(pools)
+	return pools
+}
+
+// IsNodePoolMaintenancePending returns true if the NodePool has been marked with the MaintenancePending condition
+func IsNodePoolMaintenancePending(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(NodePoolMaintenancePending))
+}
+
+// UpdateNodePoolMaintenanceCondition sets the MaintenancePending condition of the NodePool from the resource pools
+// under maintenance. The condition is only cleared if it was previously set, and the status is only updated if the
+// condition has changed.
+func UpdateNodePoolMaintenanceCondition(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, pools []string) error {
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolMaintenancePending))
+	if len(pools) == 0 {
+		if current == nil || current.Status == metav1.ConditionFalse {
+			return nil
+		}
+		return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolMaintenancePending, ReasonMaintenanceCompleted,
+			metav1.ConditionFalse, "Resource pool maintenance completed")
+	}
+
+	message := "Resource pools under maintenance: " + strings.Join(pools, ", ")
+	if current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolMaintenancePending, ReasonPoolInMaintenance,
+		metav1.ConditionTrue, message)
+}
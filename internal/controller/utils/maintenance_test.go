@@ -0,0 +1,56 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Resource pool maintenance", func() {
+	newHwMgr := func(pools ...string) *pluginv1alpha1.HardwareManager {
+		return &pluginv1alpha1.HardwareManager{Spec: pluginv1alpha1.HardwareManagerSpec{MaintenancePools: pools}}
+	}
+
+	It("checks whether a resource pool is under maintenance", func() {
+		hwmgr := newHwMgr("master")
+		Expect(IsResourcePoolInMaintenance(hwmgr, "master")).To(BeTrue())
+		Expect(IsResourcePoolInMaintenance(hwmgr, "worker")).To(BeFalse())
+		Expect(IsResourcePoolInMaintenance(newHwMgr(), "master")).To(BeFalse())
+	})
+
+	It("reports the maintenance pools of nodegroups with nodes", func() {
+		nodepool := newTestNodePool(nil)
+		Expect(GetNodePoolMaintenancePools(newHwMgr("master", "worker"), nodepool)).To(Equal([]string{"master"}))
+		Expect(GetNodePoolMaintenancePools(newHwMgr("other"), nodepool)).To(BeEmpty())
+		Expect(GetNodePoolMaintenancePools(newHwMgr(), nodepool)).To(BeEmpty())
+	})
+
+	It("reports the maintenance pools of spares", func() {
+		nodepool := newTestNodePool(map[string]string{SpareNodesKey: `
+master:
+  count: 1
+  sparePoolId: spares
+worker:
+  count: 1
+`})
+		Expect(GetNodePoolMaintenancePools(newHwMgr("spares", "worker", "master"), nodepool)).To(
+			Equal([]string{"master", "spares", "worker"}))
+	})
+})
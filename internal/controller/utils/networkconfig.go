@@ -0,0 +1,226 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"slices"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// NetworkConfigKey is the extensions key, on both NodePool and Node CRs, that holds the network configuration
+	NetworkConfigKey = "networkConfig"
+)
+
+// Supported interface roles
+const (
+	InterfaceRoleBMC          = "bmc"
+	InterfaceRoleProvisioning = "provisioning"
+	InterfaceRoleData         = "data"
+	InterfaceRoleStorage      = "storage"
+)
+
+var supportedInterfaceRoles = []string{
+	InterfaceRoleBMC,
+	InterfaceRoleProvisioning,
+	InterfaceRoleData,
+	InterfaceRoleStorage,
+}
+
+// Supported bond modes
+var supportedBondModes = []string{
+	"balance-rr",
+	"active-backup",
+	"balance-xor",
+	"broadcast",
+	"802.3ad",
+	"balance-tlb",
+	"balance-alb",
+}
+
+// InterfaceRole assigns a role to an interface, identified by its label
+type InterfaceRole struct {
+	Label string `json:"label"`
+	Role  string `json:"role"`
+}
+
+// BondConfig describes a bond interface built from a set of member interfaces, identified by label
+type BondConfig struct {
+	Name    string   `json:"name"`
+	Mode    string   `json:"mode"`
+	Members []string `json:"members"`
+}
+
+// VLANConfig describes a VLAN interface on top of a base interface or bond
+type VLANConfig struct {
+	ID   int    `json:"id"`
+	Base string `json:"base"`
+	Role string `json:"role,omitempty"`
+}
+
+// NetworkConfig describes the network configuration requested for the nodes of a nodegroup
+type NetworkConfig struct {
+	Interfaces []InterfaceRole `json:"interfaces,omitempty"`
+	Bonds      []BondConfig    `json:"bonds,omitempty"`
+	VLANs      []VLANConfig    `json:"vlans,omitempty"`
+}
+
+// GetNodePoolNetworkConfig parses the per-nodegroup network configuration from the NodePool extensions
+func GetNodePoolNetworkConfig(nodepool *hwmgmtv1alpha1.NodePool) (map[string]NetworkConfig, error) {
+	data, exists := nodepool.Spec.Extensions[NetworkConfigKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	config := make(map[string]NetworkConfig)
+	if err := yaml.UnmarshalStrict([]byte(data), &config); err != nil {
+		return nil, NewInputError("the value of extensions key %s is not valid: %s", NetworkConfigKey, err.Error())
+	}
+
+	return config, nil
+}
+
+// GetNodeGroupNetworkConfig returns the network configuration for the specified nodegroup, if any
+func GetNodeGroupNetworkConfig(nodepool *hwmgmtv1alpha1.NodePool, groupname string) (*NetworkConfig, error) {
+	config, err := GetNodePoolNetworkConfig(nodepool)
+	if err != nil {
+		return nil, err
+	}
+
+	if groupConfig, exists := config[groupname]; exists {
+		return &groupConfig, nil
+	}
+
+	return nil, nil
+}
+
+// ValidateNetworkConfig performs schema validation of a nodegroup network configuration. Bond members must reference
+// declared interfaces, and VLANs must be based on a declared interface or bond.
+func ValidateNetworkConfig(config NetworkConfig) error {
+	interfaces := make(map[string]bool)
+	for _, intf := range config.Interfaces {
+		if intf.Label == "" {
+			return fmt.Errorf("interface role entry missing label")
+		}
+		if !slices.Contains(supportedInterfaceRoles, intf.Role) {
+			return fmt.Errorf("invalid role %q for interface %s, expected one of %v", intf.Role, intf.Label, supportedInterfaceRoles)
+		}
+		if interfaces[intf.Label] {
+			return fmt.Errorf("duplicate interface %s", intf.Label)
+		}
+		interfaces[intf.Label] = true
+	}
+
+	bonds := make(map[string]bool)
+	for _, bond := range config.Bonds {
+		if bond.Name == "" {
+			return fmt.Errorf("bond entry missing name")
+		}
+		if interfaces[bond.Name] || bonds[bond.Name] {
+			return fmt.Errorf("bond name %s conflicts with an existing interface or bond", bond.Name)
+		}
+		if !slices.Contains(supportedBondModes, bond.Mode) {
+			return fmt.Errorf("invalid mode %q for bond %s, expected one of %v", bond.Mode, bond.Name, supportedBondModes)
+		}
+		if len(bond.Members) < 2 {
+			return fmt.Errorf("bond %s requires at least two members", bond.Name)
+		}
+		members := make(map[string]bool)
+		for _, member := range bond.Members {
+			if !interfaces[member] {
+				return fmt.Errorf("bond %s member %s is not a declared interface", bond.Name, member)
+			}
+			if members[member] {
+				return fmt.Errorf("duplicate member %s in bond %s", member, bond.Name)
+			}
+			members[member] = true
+		}
+		bonds[bond.Name] = true
+	}
+
+	vlans := make(map[string]bool)
+	for _, vlan := range config.VLANs {
+		if vlan.ID < 1 || vlan.ID > 4094 {
+			return fmt.Errorf("invalid VLAN id %d, expected a value between 1 and 4094", vlan.ID)
+		}
+		if vlan.Base == "" {
+			return fmt.Errorf("VLAN %d missing base interface", vlan.ID)
+		}
+		if !interfaces[vlan.Base] && !bonds[vlan.Base] {
+			return fmt.Errorf("VLAN %d base %s is not a declared interface or bond", vlan.ID, vlan.Base)
+		}
+		if vlan.Role != "" && !slices.Contains(supportedInterfaceRoles, vlan.Role) {
+			return fmt.Errorf("invalid role %q for VLAN %d, expected one of %v", vlan.Role, vlan.ID, supportedInterfaceRoles)
+		}
+		key := fmt.Sprintf("%s.%d", vlan.Base, vlan.ID)
+		if vlans[key] {
+			return fmt.Errorf("duplicate VLAN %d on base interface %s", vlan.ID, vlan.Base)
+		}
+		vlans[key] = true
+	}
+
+	return nil
+}
+
+// ValidateNodePoolNetworkConfig validates the network configuration for all nodegroups in a NodePool
+func ValidateNodePoolNetworkConfig(nodepool *hwmgmtv1alpha1.NodePool) error {
+	config, err := GetNodePoolNetworkConfig(nodepool)
+	if err != nil {
+		return err
+	}
+
+	for groupname, groupConfig := range config {
+		found := false
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			if nodegroup.NodePoolData.Name == groupname {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return NewInputError("network configuration specified for unknown nodegroup %s", groupname)
+		}
+
+		if err := ValidateNetworkConfig(groupConfig); err != nil {
+			return NewInputError("invalid network configuration for nodegroup %s: %s", groupname, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// SetNodeNetworkConfig records the nodegroup network configuration in the Node extensions for downstream installers
+func SetNodeNetworkConfig(node *hwmgmtv1alpha1.Node, config *NetworkConfig) error {
+	if config == nil {
+		return nil
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal network config: %w", err)
+	}
+
+	if node.Spec.Extensions == nil {
+		node.Spec.Extensions = make(map[string]string)
+	}
+	node.Spec.Extensions[NetworkConfigKey] = string(data)
+
+	return nil
+}
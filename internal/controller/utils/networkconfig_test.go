@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+func newTestNodePool(extensions map[string]string) *hwmgmtv1alpha1.NodePool {
+	return &hwmgmtv1alpha1.NodePool{
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			CloudID: "testcloud",
+			NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+				{NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "master", ResourcePoolId: "master"}, Size: 1},
+				{NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "worker", ResourcePoolId: "worker"}, Size: 0},
+			},
+			Extensions: extensions,
+		},
+	}
+}
+
+var _ = Describe("Network configuration", func() {
+	It("accepts a nodepool without network configuration", func() {
+		Expect(ValidateNodePoolNetworkConfig(newTestNodePool(nil))).To(Succeed())
+	})
+
+	It("accepts a valid network configuration and records it on the node", func() {
+		nodepool := newTestNodePool(map[string]string{NetworkConfigKey: `
+master:
+  interfaces:
+    - label: data-1
+      role: data
+    - label: data-2
+      role: data
+  bonds:
+    - name: bond0
+      mode: active-backup
+      members: [data-1, data-2]
+  vlans:
+    - id: 100
+      base: bond0
+`})
+		Expect(ValidateNodePoolNetworkConfig(nodepool)).To(Succeed())
+
+		config, err := GetNodeGroupNetworkConfig(nodepool, "master")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).NotTo(BeNil())
+		Expect(config.Bonds).To(HaveLen(1))
+
+		node := &hwmgmtv1alpha1.Node{}
+		Expect(SetNodeNetworkConfig(node, config)).To(Succeed())
+		Expect(node.Spec.Extensions).To(HaveKey(NetworkConfigKey))
+
+		config, err = GetNodeGroupNetworkConfig(nodepool, "worker")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(BeNil())
+	})
+
+	DescribeTable("rejects invalid network configuration",
+		func(data, message string) {
+			nodepool := newTestNodePool(map[string]string{NetworkConfigKey: data})
+			err := ValidateNodePoolNetworkConfig(nodepool)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+			Expect(IsInputError(err)).To(BeTrue())
+		},
+		Entry("unknown nodegroup", "other: {}", "unknown nodegroup other"),
+		Entry("unknown field", "master: {routes: []}", "not valid"),
+		Entry("invalid role", "master: {interfaces: [{label: eth0, role: mgmt}]}", "invalid role \"mgmt\""),
+		Entry("duplicate interface", "master: {interfaces: [{label: eth0, role: data}, {label: eth0, role: storage}]}", "duplicate interface eth0"),
+		Entry("invalid bond mode", "master: {interfaces: [{label: a, role: data}, {label: b, role: data}], "+
+			"bonds: [{name: bond0, mode: fast, members: [a, b]}]}", "invalid mode \"fast\""),
+		Entry("single member bond", "master: {interfaces: [{label: a, role: data}], "+
+			"bonds: [{name: bond0, mode: active-backup, members: [a]}]}", "at least two members"),
+		Entry("undeclared bond member", "master: {interfaces: [{label: a, role: data}], "+
+			"bonds: [{name: bond0, mode: active-backup, members: [a, b]}]}", "member b is not a declared interface"),
+		Entry("duplicate bond member", "master: {interfaces: [{label: a, role: data}, {label: b, role: data}], "+
+			"bonds: [{name: bond0, mode: active-backup, members: [a, a]}]}", "duplicate member a"),
+		Entry("bond name conflicting with an interface", "master: {interfaces: [{label: a, role: data}, {label: b, role: data}], "+
+			"bonds: [{name: a, mode: active-backup, members: [a, b]}]}", "bond name a conflicts"),
+		Entry("out of range vlan", "master: {interfaces: [{label: eth0, role: data}], vlans: [{id: 4095, base: eth0}]}", "invalid VLAN id 4095"),
+		Entry("undeclared vlan base", "master: {interfaces: [{label: eth0, role: data}], vlans: [{id: 10, base: bond0}]}", "base bond0 is not a declared interface or bond"),
+		Entry("duplicate vlan", "master: {interfaces: [{label: eth0, role: data}], "+
+			"vlans: [{id: 10, base: eth0}, {id: 10, base: eth0}]}", "duplicate VLAN 10"),
+	)
+})
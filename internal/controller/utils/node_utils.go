@@ -76,6 +76,7 @@ func GetChildNodes(
 	nodelist := &hwmgmtv1alpha1.NodeList{}
 
 	opts := []client.ListOption{
+		client.InNamespace(nodepool.Namespace),
 		client.MatchingFields{"spec.nodePool": nodepool.Name},
 	}
 
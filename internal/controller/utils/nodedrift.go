@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodeFinalizer is added to Node CRs by the Node controller, so that the bmc-secret of a deleted node is removed
+	NodeFinalizer = "oran-hwmgr-plugin/node-finalizer"
+)
+
+// DriftCorrected condition type and reasons, set on a Node when the Node controller detects that its desired state
+// has been modified. The condition transition time is updated on each detected drift.
+const (
+	NodeDriftCorrected  hwmgmtv1alpha1.ConditionType   = "DriftCorrected"
+	ReasonCorrected     hwmgmtv1alpha1.ConditionReason = "Corrected"
+	ReasonUncorrectable hwmgmtv1alpha1.ConditionReason = "Uncorrectable"
+)
+
+// BMCSecretNodeName returns the name of the node for a bmc-secret, or an empty string if the name is not that of a
+// bmc-secret
+func BMCSecretNodeName(secretName string) string {
+	nodename, found := strings.CutSuffix(secretName, bmcSecretSuffix)
+	if !found {
+		return ""
+	}
+	return nodename
+}
+
+// ApplyNodeStatusDrift restores the plugin-managed fields of the Node status, returning a description of each
+// correction made. The status is not updated on the cluster.
+func ApplyNodeStatusDrift(node *hwmgmtv1alpha1.Node) []string {
+	var corrections []string
+
+	if node.Status.BMC != nil && node.Status.BMC.CredentialsName != BMCSecretName(node.Name) {
+		corrections = append(corrections, "BMC credentials name restored")
+		node.Status.BMC.CredentialsName = BMCSecretName(node.Name)
+	}
+
+	return corrections
+}
+
+// SetNodeDriftCondition records the drift corrections made to a Node, along with any drift that could not be
+// corrected. The status is not updated on the cluster.
+func SetNodeDriftCondition(node *hwmgmtv1alpha1.Node, corrections []string, uncorrected error) {
+	reason := ReasonCorrected
+	status := metav1.ConditionTrue
+	message := "Drift corrected: " + strings.Join(corrections, "; ")
+	if uncorrected != nil {
+		reason = ReasonUncorrectable
+		status = metav1.ConditionFalse
+		message = "Unable to correct drift: " + uncorrected.Error()
+		if len(corrections) > 0 {
+			message += "; corrected: " + strings.Join(corrections, "; ")
+		}
+	}
+
+	// Remove the existing condition so the transition time reflects the latest drift
+	meta.RemoveStatusCondition(&node.Status.Conditions, string(NodeDriftCorrected))
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeDriftCorrected),
+		string(reason),
+		status,
+		message)
+}
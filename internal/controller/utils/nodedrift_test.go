@@ -0,0 +1,70 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node drift", func() {
+	It("maps bmc-secret names back to the node name", func() {
+		Expect(BMCSecretNodeName(BMCSecretName("node-1"))).To(Equal("node-1"))
+		Expect(BMCSecretNodeName("pull-secret")).To(BeEmpty())
+	})
+
+	It("restores the BMC credentials name", func() {
+		node := &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: hwmgmtv1alpha1.NodeStatus{
+				BMC: &hwmgmtv1alpha1.BMC{Address: "redfish+https://192.168.1.1", CredentialsName: "edited"},
+			},
+		}
+
+		Expect(ApplyNodeStatusDrift(node)).To(HaveLen(1))
+		Expect(node.Status.BMC.CredentialsName).To(Equal("node-1-bmc-secret"))
+		Expect(ApplyNodeStatusDrift(node)).To(BeEmpty())
+
+		// A node without published BMC details is left alone
+		node.Status.BMC = nil
+		Expect(ApplyNodeStatusDrift(node)).To(BeEmpty())
+		Expect(node.Status.BMC).To(BeNil())
+	})
+
+	It("records corrected and uncorrectable drift", func() {
+		node := &hwmgmtv1alpha1.Node{}
+
+		SetNodeDriftCondition(node, []string{"bmc-secret restored"}, nil)
+		cond := meta.FindStatusCondition(node.Status.Conditions, string(NodeDriftCorrected))
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(string(ReasonCorrected)))
+		Expect(cond.Message).To(ContainSubstring("bmc-secret restored"))
+
+		SetNodeDriftCondition(node, nil, errors.New("backend unavailable"))
+		cond = meta.FindStatusCondition(node.Status.Conditions, string(NodeDriftCorrected))
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(string(ReasonUncorrectable)))
+		Expect(cond.Message).To(ContainSubstring("backend unavailable"))
+	})
+})
@@ -0,0 +1,198 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodeMetadataKey is the NodePool extensions key that holds the labels and annotations propagated to the Node CRs
+	// and bmc-secrets
+	NodeMetadataKey = "nodeMetadata"
+
+	// Standard labels stamped on the Node CRs and bmc-secrets, in addition to the NodePool standard labels
+	NodePoolLabel  = "hwmgr-plugin.oran.openshift.io/nodePool"
+	NodeGroupLabel = "hwmgr-plugin.oran.openshift.io/nodeGroup"
+)
+
+// NodeMetadata defines labels and annotations to be applied to an object
+type NodeMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NodeMetadataConfig defines the labels and annotations propagated from the NodePool and its nodegroups to the Node
+// CRs and bmc-secrets
+type NodeMetadataConfig struct {
+	// PropagateLabels are the keys of the NodePool labels to be copied. A key ending in "/" matches all labels with
+	// that prefix.
+	PropagateLabels []string `json:"propagateLabels,omitempty"`
+	// PropagateAnnotations are the keys of the NodePool annotations to be copied. A key ending in "/" matches all
+	// annotations with that prefix.
+	PropagateAnnotations []string `json:"propagateAnnotations,omitempty"`
+	// NodeGroups are additional labels and annotations for the nodes of each nodegroup, keyed by nodegroup name
+	NodeGroups map[string]NodeMetadata `json:"nodeGroups,omitempty"`
+}
+
+// GetNodePoolNodeMetadataConfig parses the node metadata configuration from the NodePool extensions
+func GetNodePoolNodeMetadataConfig(nodepool *hwmgmtv1alpha1.NodePool) (*NodeMetadataConfig, error) {
+	data, exists := nodepool.Spec.Extensions[NodeMetadataKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	config := &NodeMetadataConfig{}
+	if err := yaml.Unmarshal([]byte(data), config); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", NodeMetadataKey, err.Error())
+	}
+
+	return config, nil
+}
+
+// ValidateNodePoolNodeMetadata validates that the node metadata configuration references defined nodegroups, and
+// that the labels and annotations are valid
+func ValidateNodePoolNodeMetadata(nodepool *hwmgmtv1alpha1.NodePool) error {
+	config, err := GetNodePoolNodeMetadataConfig(nodepool)
+	if err != nil || config == nil {
+		return err
+	}
+
+	for _, key := range slices.Concat(config.PropagateLabels, config.PropagateAnnotations) {
+		name := key
+		if strings.HasSuffix(key, "/") {
+			// Validate the prefix with a placeholder name
+			name += "x"
+		}
+		if errs := validation.IsQualifiedName(name); len(errs) != 0 {
+			return NewInputError("invalid propagated key %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+
+	for groupname, metadata := range config.NodeGroups {
+		if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		}) {
+			return NewInputError("node metadata specified for unknown nodegroup %s", groupname)
+		}
+
+		for key, value := range metadata.Labels {
+			if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+				return NewInputError("invalid label key %q for nodegroup %s: %s", key, groupname, strings.Join(errs, "; "))
+			}
+			if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+				return NewInputError("invalid label value %q for nodegroup %s: %s", value, groupname, strings.Join(errs, "; "))
+			}
+		}
+		for key := range metadata.Annotations {
+			if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+				return NewInputError("invalid annotation key %q for nodegroup %s: %s", key, groupname, strings.Join(errs, "; "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesPropagatedKey returns true if the key is selected by the list of propagated keys and prefixes
+func matchesPropagatedKey(keys []string, key string) bool {
+	return slices.ContainsFunc(keys, func(k string) bool {
+		return k == key || (strings.HasSuffix(k, "/") && strings.HasPrefix(key, k))
+	})
+}
+
+// GetNodeMetadata returns the labels and annotations for the Node CRs and bmc-secrets of a nodegroup. The nodegroup
+// metadata takes precedence over that propagated from the NodePool, and the standard labels take precedence over both.
+func GetNodeMetadata(nodepool *hwmgmtv1alpha1.NodePool, groupname string) (*NodeMetadata, error) {
+	config, err := GetNodePoolNodeMetadataConfig(nodepool)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &NodeMetadata{
+		Labels:      make(map[string]string),
+		Annotations: make(map[string]string),
+	}
+
+	if config != nil {
+		for key, value := range nodepool.GetLabels() {
+			if matchesPropagatedKey(config.PropagateLabels, key) {
+				metadata.Labels[key] = value
+			}
+		}
+		for key, value := range nodepool.GetAnnotations() {
+			if matchesPropagatedKey(config.PropagateAnnotations, key) {
+				metadata.Annotations[key] = value
+			}
+		}
+
+		group := config.NodeGroups[groupname]
+		maps.Copy(metadata.Labels, group.Labels)
+		maps.Copy(metadata.Annotations, group.Annotations)
+	}
+
+	standard := map[string]string{
+		ManagedByLabel: ManagedByLabelValue,
+		HwMgrIdLabel:   nodepool.Spec.HwMgrId,
+		CloudIdLabel:   nodepool.Spec.CloudID,
+		NodePoolLabel:  nodepool.Name,
+		NodeGroupLabel: groupname,
+	}
+	for key, value := range standard {
+		if value == "" || len(validation.IsValidLabelValue(value)) != 0 {
+			continue
+		}
+		metadata.Labels[key] = value
+	}
+
+	return metadata, nil
+}
+
+// SetNodeMetadata applies the labels and annotations for a nodegroup to the object, preserving its other labels and
+// annotations
+func SetNodeMetadata(obj metav1.Object, nodepool *hwmgmtv1alpha1.NodePool, groupname string) error {
+	metadata, err := GetNodeMetadata(nodepool, groupname)
+	if err != nil {
+		return err
+	}
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	maps.Copy(labels, metadata.Labels)
+	obj.SetLabels(labels)
+
+	if len(metadata.Annotations) > 0 {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		maps.Copy(annotations, metadata.Annotations)
+		obj.SetAnnotations(annotations)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node metadata", func() {
+	It("stamps the standard labels without a configuration", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Name = "np1"
+		nodepool.Spec.HwMgrId = "hwmgr"
+		Expect(ValidateNodePoolNodeMetadata(nodepool)).To(Succeed())
+
+		metadata, err := GetNodeMetadata(nodepool, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metadata.Labels).To(Equal(map[string]string{
+			ManagedByLabel: ManagedByLabelValue,
+			HwMgrIdLabel:   "hwmgr",
+			CloudIdLabel:   "testcloud",
+			NodePoolLabel:  "np1",
+			NodeGroupLabel: "master",
+		}))
+		Expect(metadata.Annotations).To(BeEmpty())
+	})
+
+	It("propagates the selected NodePool and nodegroup metadata", func() {
+		nodepool := newTestNodePool(map[string]string{NodeMetadataKey: `
+propagateLabels: [site, example.com/]
+propagateAnnotations: [owner]
+nodeGroups:
+  master:
+    labels:
+      site: override
+      role: control-plane
+    annotations:
+      note: primary
+`})
+		nodepool.Name = "np1"
+		nodepool.Labels = map[string]string{
+			"site":                "site-1",
+			"example.com/cluster": "cluster-1",
+			"other":               "ignored",
+		}
+		nodepool.Annotations = map[string]string{"owner": "team-a", "other": "ignored"}
+		Expect(ValidateNodePoolNodeMetadata(nodepool)).To(Succeed())
+
+		node := &hwmgmtv1alpha1.Node{}
+		node.Annotations = map[string]string{"existing": "value"}
+		Expect(SetNodeMetadata(node, nodepool, "master")).To(Succeed())
+		Expect(node.Labels).To(HaveKeyWithValue("site", "override"))
+		Expect(node.Labels).To(HaveKeyWithValue("role", "control-plane"))
+		Expect(node.Labels).To(HaveKeyWithValue("example.com/cluster", "cluster-1"))
+		Expect(node.Labels).To(HaveKeyWithValue(NodeGroupLabel, "master"))
+		Expect(node.Labels).ToNot(HaveKey("other"))
+		Expect(node.Annotations).To(Equal(map[string]string{"existing": "value", "owner": "team-a", "note": "primary"}))
+
+		metadata, err := GetNodeMetadata(nodepool, "worker")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metadata.Labels).To(HaveKeyWithValue("site", "site-1"))
+		Expect(metadata.Labels).ToNot(HaveKey("role"))
+	})
+
+	It("rejects an invalid configuration", func() {
+		Expect(ValidateNodePoolNodeMetadata(newTestNodePool(map[string]string{NodeMetadataKey: `
+nodeGroups:
+  storage:
+    labels: {role: storage}
+`}))).To(MatchError(ContainSubstring("unknown nodegroup storage")))
+
+		Expect(ValidateNodePoolNodeMetadata(newTestNodePool(map[string]string{NodeMetadataKey: `
+nodeGroups:
+  master:
+    labels: {role: "not a valid value"}
+`}))).To(MatchError(ContainSubstring("invalid label value")))
+
+		Expect(ValidateNodePoolNodeMetadata(newTestNodePool(map[string]string{NodeMetadataKey: `
+propagateLabels: ["bad key"]
+`}))).To(MatchError(ContainSubstring("invalid propagated key")))
+
+		Expect(ValidateNodePoolNodeMetadata(newTestNodePool(map[string]string{NodeMetadataKey: `{`}))).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,248 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodeNameTemplateKey is the NodePool extensions key that overrides the HardwareManager node naming template
+	NodeNameTemplateKey = "nodeNameTemplate"
+
+	bmcSecretSuffix = "-bmc-secret"
+
+	// maxNodeNameLength leaves room for the bmc-secret suffix within the DNS subdomain name limit
+	maxNodeNameLength = 253 - len(bmcSecretSuffix)
+
+	// maxNodeNameAttempts bounds the search for a free name
+	maxNodeNameAttempts = 1000
+)
+
+// Supported node naming template placeholders
+const (
+	NodeNamePlaceholderCloudID     = "{cloudID}"
+	NodeNamePlaceholderNodePool    = "{nodepool}"
+	NodeNamePlaceholderGroup       = "{group}"
+	NodeNamePlaceholderIndex       = "{index}"
+	NodeNamePlaceholderBackendName = "{backendName}"
+	NodeNamePlaceholderUUID        = "{uuid}"
+)
+
+var (
+	supportedNodeNamePlaceholders = []string{
+		NodeNamePlaceholderCloudID,
+		NodeNamePlaceholderNodePool,
+		NodeNamePlaceholderGroup,
+		NodeNamePlaceholderIndex,
+		NodeNamePlaceholderBackendName,
+		NodeNamePlaceholderUUID,
+	}
+
+	// uniqueNodeNamePlaceholders are the placeholders that distinguish nodes within a nodegroup
+	uniqueNodeNamePlaceholders = []string{
+		NodeNamePlaceholderIndex,
+		NodeNamePlaceholderBackendName,
+		NodeNamePlaceholderUUID,
+	}
+
+	nodeNamePlaceholderRegex = regexp.MustCompile(`{[^{}]*}`)
+	invalidNodeNameCharRegex = regexp.MustCompile(`[^a-z0-9.-]+`)
+)
+
+// BMCSecretName returns the name of the bmc-secret for a node
+func BMCSecretName(nodename string) string {
+	return nodename + bmcSecretSuffix
+}
+
+// GetNodeNameTemplate returns the node naming template for a NodePool, with the NodePool extension taking precedence
+// over the HardwareManager policy. An empty template indicates the default uuid-based naming.
+func GetNodeNameTemplate(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) string {
+	if template, exists := nodepool.Spec.Extensions[NodeNameTemplateKey]; exists && template != "" {
+		return template
+	}
+
+	if hwmgr != nil && hwmgr.Spec.NodeNaming != nil {
+		return hwmgr.Spec.NodeNaming.Template
+	}
+
+	return ""
+}
+
+// ValidateNodeNameTemplate validates that a template uses only supported placeholders, and includes at least one
+// placeholder that makes names unique within a nodegroup
+func ValidateNodeNameTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+
+	unique := false
+	for _, placeholder := range nodeNamePlaceholderRegex.FindAllString(template, -1) {
+		if !slices.Contains(supportedNodeNamePlaceholders, placeholder) {
+			return NewInputError("unsupported placeholder %s in node name template %q, expected one of %v",
+				placeholder, template, supportedNodeNamePlaceholders)
+		}
+		if slices.Contains(uniqueNodeNamePlaceholders, placeholder) {
+			unique = true
+		}
+	}
+
+	if !unique {
+		return NewInputError("node name template %q must include at least one of %v", template, uniqueNodeNamePlaceholders)
+	}
+
+	return nil
+}
+
+// ValidateNodePoolNodeNameTemplate validates the node naming template extension of a NodePool, if present
+func ValidateNodePoolNodeNameTemplate(nodepool *hwmgmtv1alpha1.NodePool) error {
+	return ValidateNodeNameTemplate(nodepool.Spec.Extensions[NodeNameTemplateKey])
+}
+
+// sanitizeNodeName converts a rendered template into a valid DNS subdomain name
+func sanitizeNodeName(name string) string {
+	name = invalidNodeNameCharRegex.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > maxNodeNameLength {
+		name = name[:maxNodeNameLength]
+	}
+	return strings.Trim(name, "-.")
+}
+
+// NodeNamer generates Node CR names according to the naming policy of a NodePool. Names handed out by a NodeNamer
+// are reserved, so that multiple nodes allocated in a single pass do not collide before their CRs are created.
+type NodeNamer struct {
+	client    client.Client
+	namespace string
+	template  string
+	nodepool  *hwmgmtv1alpha1.NodePool
+	reserved  map[string]bool
+}
+
+// NewNodeNamer returns a NodeNamer for the naming policy that applies to the NodePool
+func NewNodeNamer(
+	c client.Client,
+	namespace string,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (*NodeNamer, error) {
+
+	template := GetNodeNameTemplate(hwmgr, nodepool)
+	if err := ValidateNodeNameTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return &NodeNamer{
+		client:    c,
+		namespace: namespace,
+		template:  template,
+		nodepool:  nodepool,
+		reserved:  make(map[string]bool),
+	}, nil
+}
+
+// Reserve marks names as in use, for names that are allocated but may not yet have a Node CR
+func (n *NodeNamer) Reserve(names ...string) {
+	for _, name := range names {
+		n.reserved[name] = true
+	}
+}
+
+func (n *NodeNamer) render(groupname, backendName string, index int) string {
+	return sanitizeNodeName(strings.NewReplacer(
+		NodeNamePlaceholderCloudID, n.nodepool.Spec.CloudID,
+		NodeNamePlaceholderNodePool, n.nodepool.Name,
+		NodeNamePlaceholderGroup, groupname,
+		NodeNamePlaceholderIndex, strconv.Itoa(index),
+		NodeNamePlaceholderBackendName, backendName,
+		NodeNamePlaceholderUUID, uuid.NewString(),
+	).Replace(n.template))
+}
+
+func (n *NodeNamer) inUse(ctx context.Context, name string) (bool, error) {
+	if n.reserved[name] {
+		return true, nil
+	}
+
+	objects := map[string]client.Object{
+		name:                &hwmgmtv1alpha1.Node{},
+		BMCSecretName(name): &corev1.Secret{},
+	}
+	for objName, obj := range objects {
+		if err := n.client.Get(ctx, types.NamespacedName{Name: objName, Namespace: n.namespace}, obj); err == nil {
+			return true, nil
+		} else if !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to check for existing %s: %w", objName, err)
+		}
+	}
+
+	return false, nil
+}
+
+// Generate returns a free name for a node in the specified nodegroup. The backendName is the name of the node on the
+// hardware manager backend, if any. With an {index} placeholder, the lowest free index is used. Otherwise, a short
+// suffix is appended if the rendered name is already in use.
+func (n *NodeNamer) Generate(ctx context.Context, groupname, backendName string) (string, error) {
+	if n.template == "" {
+		name := GenerateNodeName()
+		n.Reserve(name)
+		return name, nil
+	}
+
+	if strings.Contains(n.template, NodeNamePlaceholderBackendName) && backendName == "" {
+		return "", NewInputError("node name template %q requires a backend name, which is not provided by this adaptor", n.template)
+	}
+
+	indexed := strings.Contains(n.template, NodeNamePlaceholderIndex)
+	base := n.render(groupname, backendName, 0)
+	for attempt := 0; attempt < maxNodeNameAttempts; attempt++ {
+		name := base
+		switch {
+		case indexed:
+			name = n.render(groupname, backendName, attempt)
+		case attempt > 0:
+			name = sanitizeNodeName(fmt.Sprintf("%s-%s", base, uuid.NewString()[:8]))
+		}
+
+		if name == "" {
+			return "", NewInputError("node name template %q rendered an empty name", n.template)
+		}
+
+		inUse, err := n.inUse(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if !inUse {
+			n.Reserve(name)
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to find a free node name for template %q after %d attempts", n.template, maxNodeNameAttempts)
+}
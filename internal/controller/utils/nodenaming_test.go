@@ -0,0 +1,53 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Node naming", func() {
+	It("prefers the nodepool template over the hardware manager policy", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				NodeNaming: &pluginv1alpha1.NodeNamingConfig{Template: "{cloudID}-{uuid}"},
+			},
+		}
+		Expect(GetNodeNameTemplate(hwmgr, newTestNodePool(nil))).To(Equal("{cloudID}-{uuid}"))
+		Expect(GetNodeNameTemplate(hwmgr, newTestNodePool(map[string]string{NodeNameTemplateKey: "{group}-{index}"}))).
+			To(Equal("{group}-{index}"))
+		Expect(GetNodeNameTemplate(nil, newTestNodePool(nil))).To(BeEmpty())
+	})
+
+	It("validates templates", func() {
+		Expect(ValidateNodeNameTemplate("")).To(Succeed())
+		Expect(ValidateNodeNameTemplate("{cloudID}-{group}-{index}")).To(Succeed())
+		Expect(ValidateNodeNameTemplate("{backendName}")).To(Succeed())
+		Expect(ValidateNodeNameTemplate("{cloudID}-{group}")).To(MatchError(ContainSubstring("must include")))
+		Expect(ValidateNodeNameTemplate("{cloudID}-{rack}-{index}")).To(MatchError(ContainSubstring("unsupported placeholder {rack}")))
+	})
+
+	It("renders sanitized names", func() {
+		namer := &NodeNamer{template: "{cloudID}-{group}-{index}", nodepool: newTestNodePool(nil)}
+		namer.nodepool.Spec.CloudID = "Test_Cloud"
+		Expect(namer.render("Master", "", 2)).To(Equal("test-cloud-master-2"))
+		Expect(sanitizeNodeName("--Node 1--")).To(Equal("node-1"))
+	})
+})
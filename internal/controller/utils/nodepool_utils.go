@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,12 +32,48 @@ import (
 const (
 	NodepoolFinalizer = "oran-hwmgr-plugin/nodepool-finalizer"
 	ResourceTypeIdKey = "resourceTypeId"
+	PausedAnnotation  = "hwmgr-plugin.oran.openshift.io/paused"
+
+	// DeletionPolicyAnnotation overrides the HardwareManager deletion policy for a NodePool
+	DeletionPolicyAnnotation = "hwmgr-plugin.oran.openshift.io/deletionPolicy"
+)
+
+// Paused condition type and reasons, set on a NodePool when processing is suspended via the paused annotation
+const (
+	NodePoolPaused hwmgmtv1alpha1.ConditionType   = "Paused"
+	ReasonPaused   hwmgmtv1alpha1.ConditionReason = "Paused"
+	ReasonResumed  hwmgmtv1alpha1.ConditionReason = "Resumed"
 )
 
 func GetResourceTypeId(nodepool *hwmgmtv1alpha1.NodePool) string {
 	return nodepool.Spec.Extensions[ResourceTypeIdKey]
 }
 
+// GetNodePoolSize returns the total number of nodes requested across the nodegroups of the NodePool
+func GetNodePoolSize(nodepool *hwmgmtv1alpha1.NodePool) int {
+	size := 0
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		size += nodegroup.Size
+	}
+	return size
+}
+
+// IsNodeGroupPlaceholder returns true if the nodegroup has a size of zero. A placeholder nodegroup is registered with the
+// allocation of its NodePool without reserving any nodes, so that it can later be scaled up as an extension.
+func IsNodeGroupPlaceholder(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+	return nodegroup.Size == 0
+}
+
+// ValidateNodePoolNodeGroupSizes validates that no nodegroup of the NodePool has a negative size
+func ValidateNodePoolNodeGroupSizes(nodepool *hwmgmtv1alpha1.NodePool) error {
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if nodegroup.Size < 0 {
+			return NewInputError("nodegroup %s has invalid size %d", nodegroup.NodePoolData.Name, nodegroup.Size)
+		}
+	}
+	return nil
+}
+
 func GetNodePoolProvisionedCondition(nodepool *hwmgmtv1alpha1.NodePool) *metav1.Condition {
 	return meta.FindStatusCondition(
 		nodepool.Status.Conditions,
@@ -61,6 +98,49 @@ func IsNodePoolProvisionedFailed(nodepool *hwmgmtv1alpha1.NodePool) bool {
 	return false
 }
 
+// IsNodePoolPauseRequested returns true if the NodePool has been annotated to suspend processing
+func IsNodePoolPauseRequested(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.GetAnnotations()[PausedAnnotation] == "true"
+}
+
+func IsNodePoolPaused(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(NodePoolPaused))
+}
+
+// ValidateNodePoolDeletionPolicy validates the deletion policy annotation of a NodePool, if present
+func ValidateNodePoolDeletionPolicy(nodepool *hwmgmtv1alpha1.NodePool) error {
+	policy, exists := nodepool.GetAnnotations()[DeletionPolicyAnnotation]
+	if !exists {
+		return nil
+	}
+
+	switch pluginv1alpha1.DeletionPolicy(policy) {
+	case pluginv1alpha1.DeletionPolicies.Release, pluginv1alpha1.DeletionPolicies.Retain:
+		return nil
+	default:
+		return NewInputError("invalid %s annotation %q, expected %s or %s", DeletionPolicyAnnotation, policy,
+			pluginv1alpha1.DeletionPolicies.Release, pluginv1alpha1.DeletionPolicies.Retain)
+	}
+}
+
+// GetNodePoolDeletionPolicy returns the deletion policy for a NodePool, with the NodePool annotation taking precedence
+// over the HardwareManager default. An unrecognized annotation value is treated as Retain, so that hardware is not
+// released unintentionally.
+func GetNodePoolDeletionPolicy(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) pluginv1alpha1.DeletionPolicy {
+	if _, exists := nodepool.GetAnnotations()[DeletionPolicyAnnotation]; exists {
+		if ValidateNodePoolDeletionPolicy(nodepool) != nil {
+			return pluginv1alpha1.DeletionPolicies.Retain
+		}
+		return pluginv1alpha1.DeletionPolicy(nodepool.GetAnnotations()[DeletionPolicyAnnotation])
+	}
+
+	if hwmgr.Spec.DeletionPolicy != "" {
+		return hwmgr.Spec.DeletionPolicy
+	}
+
+	return pluginv1alpha1.DeletionPolicies.Release
+}
+
 func UpdateNodePoolStatusCondition(
 	ctx context.Context,
 	c client.Client,
@@ -70,30 +150,9 @@ func UpdateNodePoolStatusCondition(
 	conditionStatus metav1.ConditionStatus,
 	message string) error {
 
-	SetStatusCondition(&nodepool.Status.Conditions,
-		string(conditionType),
-		string(conditionReason),
-		conditionStatus,
-		message)
-
-	// nolint: wrapcheck
-	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
-		newNodepool := &hwmgmtv1alpha1.NodePool{}
-		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
-			return err
-		}
-		SetStatusCondition(&newNodepool.Status.Conditions,
-			string(conditionType),
-			string(conditionReason),
-			conditionStatus,
-			message)
-		if err := c.Status().Update(ctx, newNodepool); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
+	if err := NewNodePoolStatusBuilder(nodepool).
+		WithCondition(conditionType, conditionReason, conditionStatus, message).
+		Update(ctx, c); err != nil {
 		return fmt.Errorf("failed to update nodepool condition: %s, %w", nodepool.Name, err)
 	}
 
@@ -105,21 +164,8 @@ func UpdateNodePoolProperties(
 	c client.Client,
 	nodepool *hwmgmtv1alpha1.NodePool) error {
 
-	// nolint: wrapcheck
-	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
-		newNodepool := &hwmgmtv1alpha1.NodePool{}
-		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
-			return err
-		}
-		newNodepool.Status.Properties = nodepool.Status.Properties
-		if err := c.Status().Update(ctx, newNodepool); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to update nodepool condition: %w", err)
+	if err := NewNodePoolStatusBuilder(nodepool).WithProperties(nodepool.Status.Properties).Update(ctx, c); err != nil {
+		return fmt.Errorf("failed to update nodepool properties: %w", err)
 	}
 
 	return nil
@@ -130,20 +176,7 @@ func UpdateNodePoolPluginStatus(
 	c client.Client,
 	nodepool *hwmgmtv1alpha1.NodePool) error {
 
-	// nolint: wrapcheck
-	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
-		newNodepool := &hwmgmtv1alpha1.NodePool{}
-		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
-			return err
-		}
-		newNodepool.Status.HwMgrPlugin.ObservedGeneration = newNodepool.ObjectMeta.Generation
-		if err := c.Status().Update(ctx, newNodepool); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
+	if err := NewNodePoolStatusBuilder(nodepool).WithObservedGeneration().Update(ctx, c); err != nil {
 		return fmt.Errorf("failed to update nodepool condition: %w", err)
 	}
 
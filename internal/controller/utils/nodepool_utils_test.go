@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Deletion policy", func() {
+	It("defaults to Release", func() {
+		Expect(GetNodePoolDeletionPolicy(&pluginv1alpha1.HardwareManager{}, newTestNodePool(nil))).
+			To(Equal(pluginv1alpha1.DeletionPolicies.Release))
+	})
+
+	It("prefers the nodepool annotation over the hardware manager default", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{DeletionPolicy: pluginv1alpha1.DeletionPolicies.Retain},
+		}
+		nodepool := newTestNodePool(nil)
+		Expect(GetNodePoolDeletionPolicy(hwmgr, nodepool)).To(Equal(pluginv1alpha1.DeletionPolicies.Retain))
+
+		nodepool.SetAnnotations(map[string]string{DeletionPolicyAnnotation: "Release"})
+		Expect(ValidateNodePoolDeletionPolicy(nodepool)).To(Succeed())
+		Expect(GetNodePoolDeletionPolicy(hwmgr, nodepool)).To(Equal(pluginv1alpha1.DeletionPolicies.Release))
+	})
+
+	It("rejects, and retains hardware for, an unrecognized annotation", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.SetAnnotations(map[string]string{DeletionPolicyAnnotation: "Retian"})
+		Expect(ValidateNodePoolDeletionPolicy(nodepool)).To(HaveOccurred())
+		Expect(GetNodePoolDeletionPolicy(&pluginv1alpha1.HardwareManager{}, nodepool)).
+			To(Equal(pluginv1alpha1.DeletionPolicies.Retain))
+	})
+})
+
+var _ = Describe("Nodegroup sizes", func() {
+	It("treats a zero-size nodegroup as a placeholder", func() {
+		Expect(IsNodeGroupPlaceholder(hwmgmtv1alpha1.NodeGroup{Size: 0})).To(BeTrue())
+		Expect(IsNodeGroupPlaceholder(hwmgmtv1alpha1.NodeGroup{Size: 1})).To(BeFalse())
+	})
+
+	It("accepts placeholder nodegroups and rejects negative sizes", func() {
+		nodepool := newTestNodePool(nil)
+		Expect(ValidateNodePoolNodeGroupSizes(nodepool)).To(Succeed())
+
+		nodepool.Spec.NodeGroup[1].Size = -1
+		Expect(ValidateNodePoolNodeGroupSizes(nodepool)).To(MatchError(ContainSubstring("nodegroup worker has invalid size -1")))
+	})
+})
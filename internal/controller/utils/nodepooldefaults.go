@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Standard labels stamped on NodePools on creation
+const (
+	ManagedByLabel      = "app.kubernetes.io/managed-by"
+	ManagedByLabelValue = "oran-hwmgr-plugin"
+	HwMgrIdLabel        = "hwmgr-plugin.oran.openshift.io/hwMgrId"
+	CloudIdLabel        = "hwmgr-plugin.oran.openshift.io/cloudId"
+)
+
+// DefaultNodePoolHwMgrId sets the HwMgrId of the NodePool, if not specified, when there is exactly one candidate
+// HardwareManager. Returns true if the HwMgrId was defaulted.
+func DefaultNodePoolHwMgrId(nodepool *hwmgmtv1alpha1.NodePool, hwmgrs []string) bool {
+	if nodepool.Spec.HwMgrId != "" || len(hwmgrs) != 1 {
+		return false
+	}
+
+	nodepool.Spec.HwMgrId = hwmgrs[0]
+	return true
+}
+
+// NormalizeNodePoolResourcePools strips surrounding whitespace from the resource pool IDs of the nodegroups
+func NormalizeNodePoolResourcePools(nodepool *hwmgmtv1alpha1.NodePool) {
+	for i := range nodepool.Spec.NodeGroup {
+		data := &nodepool.Spec.NodeGroup[i].NodePoolData
+		data.ResourcePoolId = strings.TrimSpace(data.ResourcePoolId)
+	}
+}
+
+// SetNodePoolStandardLabels stamps the standard labels on the NodePool, preserving any existing values. Identifiers
+// that are not valid label values are skipped.
+func SetNodePoolStandardLabels(nodepool *hwmgmtv1alpha1.NodePool) {
+	labels := nodepool.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	standard := map[string]string{
+		ManagedByLabel: ManagedByLabelValue,
+		HwMgrIdLabel:   nodepool.Spec.HwMgrId,
+		CloudIdLabel:   nodepool.Spec.CloudID,
+	}
+	for key, value := range standard {
+		if _, exists := labels[key]; exists || value == "" {
+			continue
+		}
+		if len(validation.IsValidLabelValue(value)) != 0 {
+			continue
+		}
+		labels[key] = value
+	}
+
+	nodepool.SetLabels(labels)
+}
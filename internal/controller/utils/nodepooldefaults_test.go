@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NodePool defaults", func() {
+	It("defaults the HwMgrId only when there is a single hardware manager", func() {
+		nodepool := newTestNodePool(nil)
+		Expect(DefaultNodePoolHwMgrId(nodepool, []string{"hwmgr-a", "hwmgr-b"})).To(BeFalse())
+		Expect(nodepool.Spec.HwMgrId).To(BeEmpty())
+
+		Expect(DefaultNodePoolHwMgrId(nodepool, []string{"hwmgr-a"})).To(BeTrue())
+		Expect(nodepool.Spec.HwMgrId).To(Equal("hwmgr-a"))
+
+		Expect(DefaultNodePoolHwMgrId(nodepool, []string{"hwmgr-b"})).To(BeFalse())
+		Expect(nodepool.Spec.HwMgrId).To(Equal("hwmgr-a"))
+	})
+
+	It("normalizes resource pool IDs", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.NodeGroup[0].NodePoolData.ResourcePoolId = "  master\n"
+		NormalizeNodePoolResourcePools(nodepool)
+		Expect(nodepool.Spec.NodeGroup[0].NodePoolData.ResourcePoolId).To(Equal("master"))
+		Expect(nodepool.Spec.NodeGroup[1].NodePoolData.ResourcePoolId).To(Equal("worker"))
+	})
+
+	It("stamps the standard labels without overriding existing values", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.HwMgrId = "hwmgr-a"
+		nodepool.SetLabels(map[string]string{CloudIdLabel: "custom"})
+
+		SetNodePoolStandardLabels(nodepool)
+		Expect(nodepool.GetLabels()).To(HaveKeyWithValue(ManagedByLabel, ManagedByLabelValue))
+		Expect(nodepool.GetLabels()).To(HaveKeyWithValue(HwMgrIdLabel, "hwmgr-a"))
+		Expect(nodepool.GetLabels()).To(HaveKeyWithValue(CloudIdLabel, "custom"))
+	})
+
+	It("skips identifiers that are not valid label values", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.CloudID = "not a valid label/value"
+
+		SetNodePoolStandardLabels(nodepool)
+		Expect(nodepool.GetLabels()).ToNot(HaveKey(CloudIdLabel))
+		Expect(nodepool.GetLabels()).ToNot(HaveKey(HwMgrIdLabel))
+	})
+})
@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Drifted condition type and reasons, set on a NodePool when its allocation in the backend no longer matches the
+// requested configuration, such as after an out-of-band change to the backend
+const (
+	NodePoolDrifted hwmgmtv1alpha1.ConditionType   = "Drifted"
+	ReasonDrifted   hwmgmtv1alpha1.ConditionReason = "Drifted"
+	ReasonInSync    hwmgmtv1alpha1.ConditionReason = "InSync"
+)
+
+// BackendNodeState is the state of a node allocated to a NodePool, as reported by the backend. Empty fields are not
+// reported by the backend, and are not checked for drift.
+type BackendNodeState struct {
+	// NodeName is the name of the Node CR for the node
+	NodeName string
+	// GroupName is the nodegroup the backend has allocated the node to
+	GroupName string
+	// HwProfile is the hardware profile applied to the node
+	HwProfile string
+	// ResourcePoolId is the resource pool the node belongs to
+	ResourcePoolId string
+}
+
+// DetectNodePoolDrift compares the requested configuration of the NodePool, along with its Node CRs, against the
+// allocation reported by the backend, returning a description of each divergence. A node may belong to the spare pool
+// of its nodegroup, as failed nodes are replaced from the spares.
+func DetectNodePoolDrift(
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodelist *hwmgmtv1alpha1.NodeList,
+	backend []BackendNodeState) ([]string, error) {
+
+	spares, err := GetNodePoolSpareConfig(nodepool)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []string
+
+	sizes := make(map[string]int)
+	allocated := make(map[string]bool)
+	for _, state := range backend {
+		sizes[state.GroupName]++
+		allocated[state.NodeName] = true
+
+		index := slices.IndexFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == state.GroupName
+		})
+		if index == -1 {
+			drift = append(drift, fmt.Sprintf("node %s is allocated to unknown nodegroup %s", state.NodeName, state.GroupName))
+			continue
+		}
+		nodegroup := nodepool.Spec.NodeGroup[index].NodePoolData
+
+		if state.HwProfile != "" && state.HwProfile != nodegroup.HwProfile {
+			drift = append(drift, fmt.Sprintf("node %s has hwProfile %s, expected %s", state.NodeName, state.HwProfile, nodegroup.HwProfile))
+		}
+		if state.ResourcePoolId != "" && state.ResourcePoolId != nodegroup.ResourcePoolId &&
+			state.ResourcePoolId != spares[state.GroupName].SparePoolId {
+			drift = append(drift, fmt.Sprintf("node %s is in resource pool %s, expected %s", state.NodeName, state.ResourcePoolId, nodegroup.ResourcePoolId))
+		}
+	}
+
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		groupname := nodegroup.NodePoolData.Name
+		if sizes[groupname] != nodegroup.Size {
+			drift = append(drift, fmt.Sprintf("nodegroup %s has %d nodes allocated, expected %d", groupname, sizes[groupname], nodegroup.Size))
+		}
+	}
+
+	if nodelist != nil {
+		for _, node := range nodelist.Items {
+			if !allocated[node.Name] {
+				drift = append(drift, fmt.Sprintf("node %s is not allocated in the backend", node.Name))
+			}
+		}
+	}
+
+	slices.Sort(drift)
+	return drift, nil
+}
+
+// UpdateNodePoolDriftCondition sets the Drifted condition of the NodePool from the detected drift. The status is only
+// updated if the condition has changed.
+func UpdateNodePoolDriftCondition(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, drift []string) error {
+	reason := ReasonInSync
+	status := metav1.ConditionFalse
+	message := "NodePool allocation matches the requested configuration"
+	if len(drift) > 0 {
+		reason = ReasonDrifted
+		status = metav1.ConditionTrue
+		message = "Allocation has drifted: " + strings.Join(drift, "; ")
+	}
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolDrifted))
+	if current != nil && current.Reason == string(reason) && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolDrifted, reason, status, message)
+}
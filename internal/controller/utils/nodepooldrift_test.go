@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestDriftNodeList(names ...string) *hwmgmtv1alpha1.NodeList {
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	for _, name := range names {
+		nodelist.Items = append(nodelist.Items, hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return nodelist
+}
+
+var _ = Describe("NodePool drift", func() {
+	It("reports no drift for an allocation matching the request", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.NodeGroup[0].NodePoolData.HwProfile = "profile-a"
+
+		drift, err := DetectNodePoolDrift(nodepool, newTestDriftNodeList("node-1"), []BackendNodeState{
+			{NodeName: "node-1", GroupName: "master", HwProfile: "profile-a", ResourcePoolId: "master"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drift).To(BeEmpty())
+	})
+
+	It("reports changed hwProfiles, resource pools and group sizes", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.NodeGroup[0].NodePoolData.HwProfile = "profile-a"
+
+		drift, err := DetectNodePoolDrift(nodepool, newTestDriftNodeList("node-1", "node-2"), []BackendNodeState{
+			{NodeName: "node-1", GroupName: "master", HwProfile: "profile-b", ResourcePoolId: "other"},
+			{NodeName: "node-2", GroupName: "master"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drift).To(Equal([]string{
+			"node node-1 has hwProfile profile-b, expected profile-a",
+			"node node-1 is in resource pool other, expected master",
+			"nodegroup master has 2 nodes allocated, expected 1",
+		}))
+	})
+
+	It("reports nodes no longer allocated in the backend", func() {
+		nodepool := newTestNodePool(nil)
+
+		drift, err := DetectNodePoolDrift(nodepool, newTestDriftNodeList("node-1"), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drift).To(Equal([]string{
+			"node node-1 is not allocated in the backend",
+			"nodegroup master has 0 nodes allocated, expected 1",
+		}))
+	})
+
+	It("accepts nodes from the spare pool of the nodegroup", func() {
+		nodepool := newTestNodePool(map[string]string{SpareNodesKey: `
+master:
+  sparePoolId: master-spares
+  count: 1
+`})
+
+		drift, err := DetectNodePoolDrift(nodepool, newTestDriftNodeList("node-1"), []BackendNodeState{
+			{NodeName: "node-1", GroupName: "master", ResourcePoolId: "master-spares"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(drift).To(BeEmpty())
+	})
+})
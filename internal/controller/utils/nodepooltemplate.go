@@ -0,0 +1,181 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodePoolTemplateLabel is set on the NodePools instantiated from a NodePoolTemplate, naming the template
+	NodePoolTemplateLabel = "hwmgr-plugin.oran.openshift.io/nodepool-template"
+
+	// Parameters defined by each instance of a NodePoolTemplate
+	TemplateParamCloudID = "cloudID"
+	TemplateParamName    = "name"
+	TemplateParamSite    = "site"
+)
+
+// templateParamRegex matches the parameter references in the values of a NodePoolTemplate
+var templateParamRegex = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// GetNodePoolInstanceParameters returns the parameters of a NodePool instance, with the parameters of the instance
+// overriding the defaults of the template, and the instance fields overriding both
+func GetNodePoolInstanceParameters(tmpl *pluginv1alpha1.NodePoolTemplate, instance *pluginv1alpha1.NodePoolInstance) map[string]string {
+	params := make(map[string]string)
+	maps.Copy(params, tmpl.Spec.Parameters)
+	maps.Copy(params, instance.Parameters)
+	params[TemplateParamCloudID] = instance.CloudID
+	params[TemplateParamName] = instance.Name
+	if instance.Site != "" {
+		params[TemplateParamSite] = instance.Site
+	} else if _, exists := params[TemplateParamSite]; !exists {
+		params[TemplateParamSite] = ""
+	}
+	return params
+}
+
+// SubstituteTemplateParameters replaces the ${name} references in a value with the parameters. A reference to an
+// undefined parameter is an error, catching typos that would otherwise be carried into every instance.
+func SubstituteTemplateParameters(value string, params map[string]string) (string, error) {
+	var missing []string
+	result := templateParamRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		name := templateParamRegex.FindStringSubmatch(ref)[1]
+		param, exists := params[name]
+		if !exists {
+			missing = append(missing, name)
+			return ref
+		}
+		return param
+	})
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return "", NewInputError("undefined template parameters: %s", strings.Join(slices.Compact(missing), ", "))
+	}
+	return result, nil
+}
+
+// nodePoolSpecFromTemplate builds a NodePool spec from the content of a NodePoolTemplate
+func nodePoolSpecFromTemplate(content *pluginv1alpha1.NodePoolTemplateContent) hwmgmtv1alpha1.NodePoolSpec {
+	spec := hwmgmtv1alpha1.NodePoolSpec{
+		LocationSpec: hwmgmtv1alpha1.LocationSpec{
+			Location: content.Location,
+			Site:     content.Site,
+		},
+		HwMgrId:    content.HwMgrId,
+		Extensions: maps.Clone(content.Extensions),
+	}
+	for _, nodegroup := range content.NodeGroups {
+		spec.NodeGroup = append(spec.NodeGroup, hwmgmtv1alpha1.NodeGroup{
+			NodePoolData: hwmgmtv1alpha1.NodePoolData{
+				Name:           nodegroup.Name,
+				Role:           nodegroup.Role,
+				HwProfile:      nodegroup.HwProfile,
+				ResourcePoolId: nodegroup.ResourcePoolId,
+			},
+			Size: nodegroup.Size,
+		})
+	}
+	return spec
+}
+
+// InstantiateNodePool builds a NodePool instance of a NodePoolTemplate, from either the content of the template or the
+// source NodePool that it references. The cloudID, site, and nodegroup sizes of the instance are applied, and the
+// parameters are substituted in the location, site, nodegroup profiles and resource pools, and extension values.
+func InstantiateNodePool(
+	tmpl *pluginv1alpha1.NodePoolTemplate,
+	source *hwmgmtv1alpha1.NodePool,
+	instance *pluginv1alpha1.NodePoolInstance) (*hwmgmtv1alpha1.NodePool, error) {
+
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: tmpl.Namespace,
+			Labels:    make(map[string]string),
+		},
+	}
+
+	switch {
+	case tmpl.Spec.Template != nil:
+		nodepool.Spec = nodePoolSpecFromTemplate(tmpl.Spec.Template)
+	case source != nil:
+		nodepool.Spec = *source.Spec.DeepCopy()
+		maps.Copy(nodepool.Labels, source.Labels)
+		delete(nodepool.Labels, SplitParentLabel)
+	default:
+		return nil, NewInputError("no template or source NodePool specified")
+	}
+	nodepool.Labels[NodePoolTemplateLabel] = tmpl.Name
+	nodepool.Spec.CloudID = instance.CloudID
+
+	for groupname := range instance.Sizes {
+		if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		}) {
+			return nil, NewInputError("size specified for unknown nodegroup %s", groupname)
+		}
+	}
+
+	params := GetNodePoolInstanceParameters(tmpl, instance)
+	substitute := func(value *string) error {
+		result, err := SubstituteTemplateParameters(*value, params)
+		if err != nil {
+			return err
+		}
+		*value = result
+		return nil
+	}
+
+	if err := substitute(&nodepool.Spec.Location); err != nil {
+		return nil, err
+	}
+	if instance.Site != "" {
+		nodepool.Spec.Site = instance.Site
+	} else if err := substitute(&nodepool.Spec.Site); err != nil {
+		return nil, err
+	}
+
+	for i := range nodepool.Spec.NodeGroup {
+		nodegroup := &nodepool.Spec.NodeGroup[i]
+		if size, exists := instance.Sizes[nodegroup.NodePoolData.Name]; exists {
+			nodegroup.Size = size
+		}
+		if err := substitute(&nodegroup.NodePoolData.HwProfile); err != nil {
+			return nil, err
+		}
+		if err := substitute(&nodegroup.NodePoolData.ResourcePoolId); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, value := range nodepool.Spec.Extensions {
+		if err := substitute(&value); err != nil {
+			return nil, NewInputError("invalid %s extension: %s", key, err.Error())
+		}
+		nodepool.Spec.Extensions[key] = value
+	}
+
+	return nodepool, nil
+}
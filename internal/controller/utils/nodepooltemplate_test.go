@@ -0,0 +1,131 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("NodePool templates", func() {
+	newTemplate := func() *pluginv1alpha1.NodePoolTemplate {
+		return &pluginv1alpha1.NodePoolTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "sites", Namespace: "oran-hwmgr-plugin"},
+			Spec: pluginv1alpha1.NodePoolTemplateSpec{
+				Template: &pluginv1alpha1.NodePoolTemplateContent{
+					HwMgrId:  "loopback-1",
+					Location: "${region}",
+					Site:     "site-${cloudID}",
+					NodeGroups: []pluginv1alpha1.NodePoolTemplateNodeGroup{
+						{Name: "master", Role: "master", HwProfile: "${profile}", ResourcePoolId: "master", Size: 3},
+						{Name: "worker", Role: "worker", HwProfile: "profile-worker", ResourcePoolId: "worker", Size: 0},
+					},
+					Extensions: map[string]string{CallbackURLKey: "https://${cloudID}.example.com/callback"},
+				},
+				Parameters: map[string]string{"region": "east", "profile": "profile-spr"},
+				Instances: []pluginv1alpha1.NodePoolInstance{
+					{Name: "site-a", CloudID: "cloud-a"},
+					{Name: "site-b", CloudID: "cloud-b", Site: "remote",
+						Sizes:      map[string]int{"worker": 2},
+						Parameters: map[string]string{"profile": "profile-gnr"}},
+				},
+			},
+		}
+	}
+
+	It("substitutes parameters", func() {
+		params := map[string]string{"cloudID": "cloud-a", "site": ""}
+		Expect(SubstituteTemplateParameters("${cloudID}-${site}x", params)).To(Equal("cloud-a-x"))
+		Expect(SubstituteTemplateParameters("no references", params)).To(Equal("no references"))
+
+		_, err := SubstituteTemplateParameters("${cloudId}/${rack}/${rack}", params)
+		Expect(err).To(HaveOccurred())
+		Expect(IsInputError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("cloudId, rack"))
+	})
+
+	It("instantiates a NodePool from the template with the defaults", func() {
+		tmpl := newTemplate()
+		nodepool, err := InstantiateNodePool(tmpl, nil, &tmpl.Spec.Instances[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodepool.Name).To(Equal("site-a"))
+		Expect(nodepool.Namespace).To(Equal("oran-hwmgr-plugin"))
+		Expect(nodepool.Labels).To(HaveKeyWithValue(NodePoolTemplateLabel, "sites"))
+		Expect(nodepool.Spec.CloudID).To(Equal("cloud-a"))
+		Expect(nodepool.Spec.HwMgrId).To(Equal("loopback-1"))
+		Expect(nodepool.Spec.Location).To(Equal("east"))
+		Expect(nodepool.Spec.Site).To(Equal("site-cloud-a"))
+		Expect(nodepool.Spec.NodeGroup).To(HaveLen(2))
+		Expect(nodepool.Spec.NodeGroup[0].NodePoolData.HwProfile).To(Equal("profile-spr"))
+		Expect(nodepool.Spec.NodeGroup[0].Size).To(Equal(3))
+		Expect(nodepool.Spec.NodeGroup[1].Size).To(Equal(0))
+		Expect(nodepool.Spec.Extensions).To(HaveKeyWithValue(CallbackURLKey, "https://cloud-a.example.com/callback"))
+
+		// The template itself is left untouched
+		Expect(tmpl.Spec.Template.Extensions[CallbackURLKey]).To(Equal("https://${cloudID}.example.com/callback"))
+	})
+
+	It("applies the overrides of the instance", func() {
+		tmpl := newTemplate()
+		nodepool, err := InstantiateNodePool(tmpl, nil, &tmpl.Spec.Instances[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodepool.Spec.Site).To(Equal("remote"))
+		Expect(nodepool.Spec.NodeGroup[0].NodePoolData.HwProfile).To(Equal("profile-gnr"))
+		Expect(nodepool.Spec.NodeGroup[0].Size).To(Equal(3))
+		Expect(nodepool.Spec.NodeGroup[1].Size).To(Equal(2))
+	})
+
+	It("clones a source NodePool", func() {
+		source := newTestNodePool(map[string]string{CallbackURLKey: "https://hub.example.com/${name}"})
+		source.Name = "golden"
+		source.Labels = map[string]string{"tier": "edge", SplitParentLabel: "parent"}
+		source.Spec.Site = "golden-site"
+
+		tmpl := newTemplate()
+		tmpl.Spec.Template = nil
+		tmpl.Spec.NodePoolRef = source.Name
+		nodepool, err := InstantiateNodePool(tmpl, source, &tmpl.Spec.Instances[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodepool.Labels).To(Equal(map[string]string{"tier": "edge", NodePoolTemplateLabel: "sites"}))
+		Expect(nodepool.Spec.CloudID).To(Equal("cloud-b"))
+		Expect(nodepool.Spec.Site).To(Equal("remote"))
+		Expect(nodepool.Spec.NodeGroup[0].Size).To(Equal(1))
+		Expect(nodepool.Spec.NodeGroup[1].Size).To(Equal(2))
+		Expect(nodepool.Spec.Extensions).To(HaveKeyWithValue(CallbackURLKey, "https://hub.example.com/site-b"))
+		Expect(source.Spec.CloudID).To(Equal("testcloud"))
+		Expect(source.Spec.NodeGroup[1].Size).To(Equal(0))
+	})
+
+	It("rejects invalid instances", func() {
+		tmpl := newTemplate()
+		instance := pluginv1alpha1.NodePoolInstance{Name: "site-c", CloudID: "cloud-c", Sizes: map[string]int{"storage": 1}}
+		_, err := InstantiateNodePool(tmpl, nil, &instance)
+		Expect(IsInputError(err)).To(BeTrue())
+
+		delete(tmpl.Spec.Parameters, "profile")
+		_, err = InstantiateNodePool(tmpl, nil, &tmpl.Spec.Instances[0])
+		Expect(IsInputError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("profile"))
+
+		tmpl.Spec.Template = nil
+		_, err = InstantiateNodePool(tmpl, nil, &tmpl.Spec.Instances[0])
+		Expect(IsInputError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// ReleaseAnnotation requests, on a Node, that the node be released from its NodePool back to the free nodes of its
+	// resource pool. The value is the reason code for the release.
+	ReleaseAnnotation = "hwmgr-plugin.oran.openshift.io/release"
+
+	// ReleaseMessageAnnotation provides, on a Node, an optional operator message recorded with the release
+	ReleaseMessageAnnotation = "hwmgr-plugin.oran.openshift.io/release-message"
+)
+
+// ReleaseReason is the operator-supplied reason code for the manual release of a node
+type ReleaseReason string
+
+const (
+	ReleaseReasonHardwareFault ReleaseReason = "HardwareFault"
+	ReleaseReasonMaintenance   ReleaseReason = "Maintenance"
+	ReleaseReasonRepurpose     ReleaseReason = "Repurpose"
+	ReleaseReasonOther         ReleaseReason = "Other"
+)
+
+// releaseReasons are the supported release reason codes
+var releaseReasons = []ReleaseReason{
+	ReleaseReasonHardwareFault,
+	ReleaseReasonMaintenance,
+	ReleaseReasonRepurpose,
+	ReleaseReasonOther,
+}
+
+const (
+	// NodeReleased is the condition type set on a Node when its release fails
+	NodeReleased hwmgmtv1alpha1.ConditionType = "Released"
+
+	// NodePoolNodeReleased is the condition type set on a NodePool when one of its nodes is manually released, with
+	// the release reason code as its reason
+	NodePoolNodeReleased hwmgmtv1alpha1.ConditionType = "NodeReleased"
+)
+
+// GetNodeReleaseReason returns the reason code and message of the release requested for the node, and whether a
+// release is requested
+func GetNodeReleaseReason(node *hwmgmtv1alpha1.Node) (ReleaseReason, string, bool, error) {
+	value, exists := node.GetAnnotations()[ReleaseAnnotation]
+	if !exists {
+		return "", "", false, nil
+	}
+
+	reason := ReleaseReason(value)
+	if !slices.Contains(releaseReasons, reason) {
+		return "", "", true, NewInputError("invalid %s annotation %q: expected one of %v", ReleaseAnnotation, value, releaseReasons)
+	}
+
+	return reason, node.GetAnnotations()[ReleaseMessageAnnotation], true, nil
+}
+
+// FormatNodeRelease describes the release of a node, for the NodePool status and events
+func FormatNodeRelease(node *hwmgmtv1alpha1.Node, reason ReleaseReason, message string) string {
+	description := fmt.Sprintf("Node %s (%s) released from nodegroup %s with reason %s",
+		node.Name, node.Spec.HwMgrNodeId, node.Spec.GroupName, reason)
+	if message != "" {
+		description += ": " + message
+	}
+	return description
+}
+
+// WithNodeReleased records the last manual release of a node of the NodePool in its NodeReleased condition
+func (b *NodePoolStatusBuilder) WithNodeReleased(node *hwmgmtv1alpha1.Node, reason ReleaseReason, message string) *NodePoolStatusBuilder {
+	return b.WithCondition(NodePoolNodeReleased, hwmgmtv1alpha1.ConditionReason(reason), metav1.ConditionTrue,
+		FormatNodeRelease(node, reason, message))
+}
+
+// SetNodeReleaseFailed sets the Released condition of the node to False with reason Failed. The status is not updated
+// on the cluster.
+func SetNodeReleaseFailed(node *hwmgmtv1alpha1.Node, message string) {
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeReleased),
+		string(hwmgmtv1alpha1.Failed),
+		metav1.ConditionFalse,
+		message)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node release", func() {
+	newNode := func(annotations map[string]string) *hwmgmtv1alpha1.Node {
+		return &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: annotations},
+			Spec:       hwmgmtv1alpha1.NodeSpec{GroupName: "worker", HwMgrNodeId: "dummy-sp-64g-0"},
+		}
+	}
+
+	It("is not requested without the annotation", func() {
+		_, _, requested, err := GetNodeReleaseReason(newNode(map[string]string{ReleaseMessageAnnotation: "spare"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requested).To(BeFalse())
+	})
+
+	It("returns the requested reason and message", func() {
+		reason, message, requested, err := GetNodeReleaseReason(newNode(map[string]string{
+			ReleaseAnnotation:        "Repurpose",
+			ReleaseMessageAnnotation: "moving to lab cluster",
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requested).To(BeTrue())
+		Expect(reason).To(Equal(ReleaseReasonRepurpose))
+		Expect(message).To(Equal("moving to lab cluster"))
+	})
+
+	It("rejects an unknown reason code", func() {
+		_, _, requested, err := GetNodeReleaseReason(newNode(map[string]string{ReleaseAnnotation: "repurpose"}))
+		Expect(requested).To(BeTrue())
+		Expect(IsInputError(err)).To(BeTrue())
+	})
+
+	It("records the release in the NodePool status", func() {
+		nodepool := newTestNodePool(nil)
+		NewNodePoolStatusBuilder(nodepool).WithNodeReleased(newNode(nil), ReleaseReasonHardwareFault, "PSU failure").apply(nodepool)
+
+		condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolNodeReleased))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(string(ReleaseReasonHardwareFault)))
+		Expect(condition.Message).To(Equal("Node node-1 (dummy-sp-64g-0) released from nodegroup worker with reason HardwareFault: PSU failure"))
+
+		Expect(FormatNodeRelease(newNode(nil), ReleaseReasonOther, "")).
+			To(Equal("Node node-1 (dummy-sp-64g-0) released from nodegroup worker with reason Other"))
+	})
+})
@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"slices"
+	"text/template"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodeSecretLabel is set on the additional secrets of a node, holding the name of the node
+	NodeSecretLabel = "hwmgr-plugin.oran.openshift.io/node-secret-of"
+)
+
+// NodeSecretValues are the values available to the templates of the additional secrets of a node
+type NodeSecretValues struct {
+	NodeName  string
+	NodeId    string
+	GroupName string
+	HwProfile string
+	// Backend is the secret data reported by the backend for the node
+	Backend map[string]string
+}
+
+// NewNodeSecretValues returns the template values for the additional secrets of a node
+func NewNodeSecretValues(node *hwmgmtv1alpha1.Node, backend map[string]string) NodeSecretValues {
+	return NodeSecretValues{
+		NodeName:  node.Name,
+		NodeId:    node.Spec.HwMgrNodeId,
+		GroupName: node.Spec.GroupName,
+		HwProfile: node.Spec.HwProfile,
+		Backend:   backend,
+	}
+}
+
+// NodeSecretName returns the name of an additional secret of a node
+func NodeSecretName(nodename, name string) string {
+	return nodename + "-" + name
+}
+
+// GetNodeSecretTemplates returns the additional secrets defined for the nodes allocated with a hardware profile
+func GetNodeSecretTemplates(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string) []pluginv1alpha1.NodeSecretTemplate {
+	var templates []pluginv1alpha1.NodeSecretTemplate
+	for _, tmpl := range hwmgr.Spec.NodeSecrets {
+		if len(tmpl.HwProfiles) == 0 || slices.Contains(tmpl.HwProfiles, hwprofile) {
+			templates = append(templates, tmpl)
+		}
+	}
+	return templates
+}
+
+// RenderNodeSecretData renders the data of an additional secret for a node. A template that references backend data
+// not reported for the node fails to render.
+func RenderNodeSecretData(tmpl pluginv1alpha1.NodeSecretTemplate, values NodeSecretValues) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(tmpl.Data))
+	for key, text := range tmpl.Data {
+		parsed, err := template.New(key).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, NewInputError("invalid template for key %s of node secret %s: %s", key, tmpl.Name, err.Error())
+		}
+
+		var buf bytes.Buffer
+		if err := parsed.Execute(&buf, values); err != nil {
+			return nil, NewInputError("failed to render key %s of node secret %s: %s", key, tmpl.Name, err.Error())
+		}
+		data[key] = buf.Bytes()
+	}
+	return data, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Node secrets", func() {
+	var (
+		hwmgr *pluginv1alpha1.HardwareManager
+		node  *hwmgmtv1alpha1.Node
+	)
+
+	BeforeEach(func() {
+		hwmgr = &pluginv1alpha1.HardwareManager{Spec: pluginv1alpha1.HardwareManagerSpec{
+			NodeSecrets: []pluginv1alpha1.NodeSecretTemplate{
+				{Name: "console", Data: map[string]string{
+					"username": "{{ .Backend.consoleUser }}",
+					"endpoint": "{{ .NodeId }}:{{ .Backend.consolePort }}",
+				}},
+				{Name: "ipmi", HwProfiles: []string{"profile-ipmi"}, Data: map[string]string{"username": "ipmi-{{ .GroupName }}"}},
+			},
+		}}
+		node = &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       hwmgmtv1alpha1.NodeSpec{GroupName: "worker", HwProfile: "profile-a", HwMgrNodeId: "id-1"},
+		}
+	})
+
+	It("selects the secrets for the hardware profile of the node", func() {
+		Expect(GetNodeSecretTemplates(hwmgr, "profile-a")).To(HaveLen(1))
+		Expect(GetNodeSecretTemplates(hwmgr, "profile-ipmi")).To(HaveLen(2))
+		Expect(NodeSecretName("node-1", "console")).To(Equal("node-1-console"))
+	})
+
+	It("renders the secret data from the node and backend data", func() {
+		values := NewNodeSecretValues(node, map[string]string{"consoleUser": "admin", "consolePort": "2200"})
+		data, err := RenderNodeSecretData(hwmgr.Spec.NodeSecrets[0], values)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(map[string][]byte{
+			"username": []byte("admin"),
+			"endpoint": []byte("id-1:2200"),
+		}))
+
+		data, err = RenderNodeSecretData(hwmgr.Spec.NodeSecrets[1], values)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(map[string][]byte{"username": []byte("ipmi-worker")}))
+	})
+
+	It("fails on backend data not reported for the node", func() {
+		_, err := RenderNodeSecretData(hwmgr.Spec.NodeSecrets[0], NewNodeSecretValues(node, nil))
+		Expect(err).To(MatchError(ContainSubstring("failed to render key")))
+		Expect(IsInputError(err)).To(BeTrue())
+	})
+
+	It("rejects an invalid template", func() {
+		tmpl := pluginv1alpha1.NodeSecretTemplate{Name: "bad", Data: map[string]string{"key": "{{ .NodeName"}}
+		_, err := RenderNodeSecretData(tmpl, NewNodeSecretValues(node, nil))
+		Expect(err).To(MatchError(ContainSubstring("invalid template for key key of node secret bad")))
+	})
+})
@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"slices"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// NodeSelectorKey is the NodePool extensions key that holds the node selectors, keyed by nodegroup name
+	NodeSelectorKey = "nodeSelector"
+)
+
+// NodeAttributes are the hardware attributes of a node, used to match it against a NodeSelector
+type NodeAttributes struct {
+	CPUs      int
+	MemoryGiB int
+	NICModels []string
+	Location  string
+}
+
+// NodeSelector defines the hardware attributes required of the nodes allocated to a nodegroup
+type NodeSelector struct {
+	// MinCPUs is the minimum number of CPUs
+	MinCPUs int `json:"minCpus,omitempty"`
+	// MinMemoryGiB is the minimum amount of memory, in GiB
+	MinMemoryGiB int `json:"minMemoryGiB,omitempty"`
+	// NICModels are the NIC models that must all be present on the node
+	NICModels []string `json:"nicModels,omitempty"`
+	// Locations restricts the node to one of the listed locations
+	Locations []string `json:"locations,omitempty"`
+}
+
+// Matches returns true if the node attributes satisfy the selector. A nil selector matches any node.
+func (s *NodeSelector) Matches(attrs NodeAttributes) bool {
+	if s == nil {
+		return true
+	}
+
+	if attrs.CPUs < s.MinCPUs || attrs.MemoryGiB < s.MinMemoryGiB {
+		return false
+	}
+
+	for _, model := range s.NICModels {
+		if !slices.Contains(attrs.NICModels, model) {
+			return false
+		}
+	}
+
+	if len(s.Locations) > 0 && !slices.Contains(s.Locations, attrs.Location) {
+		return false
+	}
+
+	return true
+}
+
+// GetNodePoolNodeSelectors parses the node selectors from the NodePool extensions
+func GetNodePoolNodeSelectors(nodepool *hwmgmtv1alpha1.NodePool) (map[string]NodeSelector, error) {
+	data, exists := nodepool.Spec.Extensions[NodeSelectorKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var selectors map[string]NodeSelector
+	if err := yaml.Unmarshal([]byte(data), &selectors); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", NodeSelectorKey, err.Error())
+	}
+
+	return selectors, nil
+}
+
+// GetNodeGroupNodeSelector returns the node selector for a nodegroup, or nil if none is specified
+func GetNodeGroupNodeSelector(nodepool *hwmgmtv1alpha1.NodePool, groupname string) (*NodeSelector, error) {
+	selectors, err := GetNodePoolNodeSelectors(nodepool)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, exists := selectors[groupname]
+	if !exists {
+		return nil, nil
+	}
+
+	return &selector, nil
+}
+
+// ValidateNodePoolNodeSelectors validates that the node selectors reference defined nodegroups
+func ValidateNodePoolNodeSelectors(nodepool *hwmgmtv1alpha1.NodePool) error {
+	selectors, err := GetNodePoolNodeSelectors(nodepool)
+	if err != nil {
+		return err
+	}
+
+	for groupname, selector := range selectors {
+		if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		}) {
+			return NewInputError("node selector specified for unknown nodegroup %s", groupname)
+		}
+		if selector.MinCPUs < 0 || selector.MinMemoryGiB < 0 {
+			return NewInputError("invalid node selector for nodegroup %s: minimums must not be negative", groupname)
+		}
+	}
+
+	return nil
+}
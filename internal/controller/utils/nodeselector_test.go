@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node selectors", func() {
+	attrs := NodeAttributes{CPUs: 64, MemoryGiB: 128, NICModels: []string{"e810", "cx6"}, Location: "rack-1"}
+
+	It("matches any node with a nil selector", func() {
+		var selector *NodeSelector
+		Expect(selector.Matches(NodeAttributes{})).To(BeTrue())
+	})
+
+	It("matches on the node attributes", func() {
+		selector := &NodeSelector{
+			MinCPUs:      64,
+			MinMemoryGiB: 96,
+			NICModels:    []string{"cx6"},
+			Locations:    []string{"rack-1", "rack-2"},
+		}
+		Expect(selector.Matches(attrs)).To(BeTrue())
+		Expect((&NodeSelector{MinCPUs: 96}).Matches(attrs)).To(BeFalse())
+		Expect((&NodeSelector{MinMemoryGiB: 256}).Matches(attrs)).To(BeFalse())
+		Expect((&NodeSelector{NICModels: []string{"e810", "x710"}}).Matches(attrs)).To(BeFalse())
+		Expect((&NodeSelector{Locations: []string{"rack-2"}}).Matches(attrs)).To(BeFalse())
+	})
+
+	It("parses the selector for a nodegroup", func() {
+		nodepool := newTestNodePool(map[string]string{NodeSelectorKey: `
+worker:
+  minCpus: 32
+  nicModels:
+    - e810
+`})
+		Expect(ValidateNodePoolNodeSelectors(nodepool)).To(Succeed())
+
+		selector, err := GetNodeGroupNodeSelector(nodepool, "worker")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selector).To(Equal(&NodeSelector{MinCPUs: 32, NICModels: []string{"e810"}}))
+
+		selector, err = GetNodeGroupNodeSelector(nodepool, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selector).To(BeNil())
+	})
+
+	It("rejects a selector for an unknown nodegroup", func() {
+		nodepool := newTestNodePool(map[string]string{NodeSelectorKey: `
+storage:
+  minCpus: 8
+`})
+		Expect(ValidateNodePoolNodeSelectors(nodepool)).To(MatchError(ContainSubstring("unknown nodegroup storage")))
+	})
+
+	It("rejects negative minimums", func() {
+		nodepool := newTestNodePool(map[string]string{NodeSelectorKey: `
+master:
+  minMemoryGiB: -1
+`})
+		Expect(ValidateNodePoolNodeSelectors(nodepool)).To(MatchError(ContainSubstring("must not be negative")))
+	})
+})
@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodeBMCUnreachable is the condition type set on a Node when its BMC fails the probe of the adaptor
+const NodeBMCUnreachable hwmgmtv1alpha1.ConditionType = "BMCUnreachable"
+
+// NodesReady condition type and reasons, summarizing the conditions of the nodes of a NodePool
+const (
+	NodePoolNodesReady  hwmgmtv1alpha1.ConditionType   = "NodesReady"
+	ReasonAllNodesReady hwmgmtv1alpha1.ConditionReason = "AllNodesReady"
+	ReasonNodesPending  hwmgmtv1alpha1.ConditionReason = "NodesPending"
+	ReasonNodesDegraded hwmgmtv1alpha1.ConditionReason = "NodesDegraded"
+)
+
+// DegradedNode is a node of a NodePool with a condition reporting a problem
+type DegradedNode struct {
+	Name   string
+	Reason string
+}
+
+// NodeStatusSummary summarizes the conditions of the nodes of a NodePool
+type NodeStatusSummary struct {
+	TotalCount    int
+	ReadyCount    int
+	DegradedNodes []DegradedNode
+}
+
+// GetNodeDegradedReason returns the condition that reports a problem with the node, or an empty string if there is none
+func GetNodeDegradedReason(node *hwmgmtv1alpha1.Node) string {
+	if provisioned := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)); provisioned != nil &&
+		(provisioned.Reason == string(hwmgmtv1alpha1.Failed) || provisioned.Reason == string(ReasonTimeout)) {
+		return provisioned.Reason
+	}
+
+	if meta.IsStatusConditionTrue(node.Status.Conditions, string(NodeBMCUnreachable)) {
+		return string(NodeBMCUnreachable)
+	}
+
+	if meta.IsStatusConditionTrue(node.Status.Conditions, string(NodeHardwareDegraded)) {
+		return string(NodeHardwareDegraded)
+	}
+
+	if storage := meta.FindStatusCondition(node.Status.Conditions, string(NodeStorageConfigured)); storage != nil &&
+		storage.Reason == string(ReasonStorageFailed) {
+		return string(NodeStorageConfigured)
+	}
+
+	if drift := meta.FindStatusCondition(node.Status.Conditions, string(NodeDriftCorrected)); drift != nil &&
+		drift.Reason == string(ReasonUncorrectable) {
+		return string(NodeDriftCorrected)
+	}
+
+	return ""
+}
+
+// SummarizeNodeStatus counts the ready nodes, which are provisioned with no problem reported, and lists the degraded
+// nodes in the order given
+func SummarizeNodeStatus(nodes []hwmgmtv1alpha1.Node) NodeStatusSummary {
+	summary := NodeStatusSummary{TotalCount: len(nodes)}
+	for i := range nodes {
+		node := &nodes[i]
+		if reason := GetNodeDegradedReason(node); reason != "" {
+			summary.DegradedNodes = append(summary.DegradedNodes, DegradedNode{Name: node.Name, Reason: reason})
+			continue
+		}
+		if meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+			summary.ReadyCount++
+		}
+	}
+	return summary
+}
+
+// UpdateNodePoolNodesReadyCondition reports the node status summary in the NodesReady condition of the NodePool,
+// updating the NodePool only if the condition has changed
+func UpdateNodePoolNodesReadyCondition(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	summary NodeStatusSummary) error {
+
+	message := fmt.Sprintf("%d/%d nodes ready", summary.ReadyCount, summary.TotalCount)
+	reason, status := ReasonAllNodesReady, metav1.ConditionTrue
+	switch {
+	case len(summary.DegradedNodes) > 0:
+		reason, status = ReasonNodesDegraded, metav1.ConditionFalse
+		degraded := make([]string, 0, len(summary.DegradedNodes))
+		for _, node := range summary.DegradedNodes {
+			degraded = append(degraded, fmt.Sprintf("%s (%s)", node.Name, node.Reason))
+		}
+		message += "; degraded: " + strings.Join(degraded, ", ")
+	case summary.ReadyCount < summary.TotalCount:
+		reason, status = ReasonNodesPending, metav1.ConditionFalse
+	}
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolNodesReady))
+	if current != nil && current.Reason == string(reason) && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolNodesReady, reason, status, message)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node status summary", func() {
+	newNode := func(name string, conditions ...metav1.Condition) hwmgmtv1alpha1.Node {
+		node := hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		for _, condition := range conditions {
+			SetStatusCondition(&node.Status.Conditions, condition.Type, condition.Reason, condition.Status, condition.Message)
+		}
+		return node
+	}
+	provisioned := metav1.Condition{
+		Type: string(hwmgmtv1alpha1.Provisioned), Reason: string(hwmgmtv1alpha1.Completed), Status: metav1.ConditionTrue}
+	inProgress := metav1.Condition{
+		Type: string(hwmgmtv1alpha1.Provisioned), Reason: string(hwmgmtv1alpha1.InProgress), Status: metav1.ConditionFalse}
+	failed := metav1.Condition{
+		Type: string(hwmgmtv1alpha1.Provisioned), Reason: string(hwmgmtv1alpha1.Failed), Status: metav1.ConditionFalse}
+	bmcUnreachable := metav1.Condition{
+		Type: string(NodeBMCUnreachable), Reason: "ConnectionFailed", Status: metav1.ConditionTrue}
+
+	It("counts the ready nodes", func() {
+		summary := SummarizeNodeStatus([]hwmgmtv1alpha1.Node{
+			newNode("node-0", provisioned),
+			newNode("node-1", inProgress),
+			newNode("node-2"),
+		})
+		Expect(summary).To(Equal(NodeStatusSummary{TotalCount: 3, ReadyCount: 1}))
+	})
+
+	It("lists the degraded nodes with their reason", func() {
+		summary := SummarizeNodeStatus([]hwmgmtv1alpha1.Node{
+			newNode("node-0", provisioned),
+			newNode("node-1", failed),
+			newNode("node-2", provisioned, bmcUnreachable),
+		})
+		Expect(summary.ReadyCount).To(Equal(1))
+		Expect(summary.DegradedNodes).To(Equal([]DegradedNode{
+			{Name: "node-1", Reason: string(hwmgmtv1alpha1.Failed)},
+			{Name: "node-2", Reason: string(NodeBMCUnreachable)},
+		}))
+	})
+})
@@ -0,0 +1,243 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// SpreadPolicyKey is the NodePool extensions key that holds the spread policies, keyed by nodegroup name
+	SpreadPolicyKey = "spreadPolicy"
+)
+
+// PlacementWarning condition type and reasons, reporting nodes of a nodegroup with a spread policy that share a
+// failure domain
+const (
+	NodePoolPlacementWarning hwmgmtv1alpha1.ConditionType   = "PlacementWarning"
+	ReasonSpreadViolated     hwmgmtv1alpha1.ConditionReason = "SpreadViolated"
+	ReasonSpreadSatisfied    hwmgmtv1alpha1.ConditionReason = "SpreadSatisfied"
+)
+
+// FailureDomainKey identifies the level of the failure domain hierarchy across which nodes are spread
+type FailureDomainKey string
+
+const (
+	FailureDomainRack    FailureDomainKey = "rack"
+	FailureDomainChassis FailureDomainKey = "chassis"
+	FailureDomainPDU     FailureDomainKey = "pdu"
+)
+
+// SpreadMode is the enforcement mode of a spread policy
+type SpreadMode string
+
+const (
+	// SpreadRequired refuses to allocate a node that would share a failure domain with another node of the nodegroup
+	SpreadRequired SpreadMode = "Required"
+	// SpreadPreferred prefers nodes in distinct failure domains, allocating in a shared failure domain if needed
+	SpreadPreferred SpreadMode = "Preferred"
+)
+
+// FailureDomain is the failure domain metadata of a node, as reported by the backend
+type FailureDomain struct {
+	Rack    string `json:"rack,omitempty"`
+	Chassis string `json:"chassis,omitempty"`
+	PDU     string `json:"pdu,omitempty"`
+}
+
+// Get returns the failure domain of the node at the given level
+func (d FailureDomain) Get(key FailureDomainKey) string {
+	switch key {
+	case FailureDomainRack:
+		return d.Rack
+	case FailureDomainChassis:
+		return d.Chassis
+	case FailureDomainPDU:
+		return d.PDU
+	default:
+		return ""
+	}
+}
+
+// SpreadPolicy requires the nodes of a nodegroup to be placed in distinct failure domains
+type SpreadPolicy struct {
+	// TopologyKey is the level of the failure domain hierarchy across which the nodes are spread
+	TopologyKey FailureDomainKey `json:"topologyKey"`
+	// Mode is the enforcement mode. Defaults to Required
+	Mode SpreadMode `json:"mode,omitempty"`
+}
+
+// GetNodePoolSpreadPolicies parses the spread policies from the NodePool extensions, defaulting the mode of each
+func GetNodePoolSpreadPolicies(nodepool *hwmgmtv1alpha1.NodePool) (map[string]SpreadPolicy, error) {
+	data, exists := nodepool.Spec.Extensions[SpreadPolicyKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var policies map[string]SpreadPolicy
+	if err := yaml.Unmarshal([]byte(data), &policies); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", SpreadPolicyKey, err.Error())
+	}
+
+	for groupname, policy := range policies {
+		if policy.Mode == "" {
+			policy.Mode = SpreadRequired
+			policies[groupname] = policy
+		}
+	}
+
+	return policies, nil
+}
+
+// GetNodeGroupSpreadPolicy returns the spread policy for a nodegroup, or nil if none is specified
+func GetNodeGroupSpreadPolicy(nodepool *hwmgmtv1alpha1.NodePool, groupname string) (*SpreadPolicy, error) {
+	policies, err := GetNodePoolSpreadPolicies(nodepool)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, exists := policies[groupname]
+	if !exists {
+		return nil, nil
+	}
+
+	return &policy, nil
+}
+
+// ValidateNodePoolSpreadPolicies validates that the spread policies reference defined nodegroups, with supported
+// topology keys and modes
+func ValidateNodePoolSpreadPolicies(nodepool *hwmgmtv1alpha1.NodePool) error {
+	policies, err := GetNodePoolSpreadPolicies(nodepool)
+	if err != nil {
+		return err
+	}
+
+	for groupname, policy := range policies {
+		if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		}) {
+			return NewInputError("spread policy specified for unknown nodegroup %s", groupname)
+		}
+		if !slices.Contains([]FailureDomainKey{FailureDomainRack, FailureDomainChassis, FailureDomainPDU}, policy.TopologyKey) {
+			return NewInputError("unsupported topologyKey %q in spread policy for nodegroup %s", policy.TopologyKey, groupname)
+		}
+		if policy.Mode != SpreadRequired && policy.Mode != SpreadPreferred {
+			return NewInputError("unsupported mode %q in spread policy for nodegroup %s", policy.Mode, groupname)
+		}
+	}
+
+	return nil
+}
+
+// FilterSpreadCandidates returns the candidate nodes, in order, that do not share a failure domain with the nodes
+// already allocated to the nodegroup. Nodes without failure domain metadata at the topology key are excluded. If no
+// candidate satisfies a Preferred policy, all candidates are returned. A nil policy returns all candidates.
+func FilterSpreadCandidates(
+	policy *SpreadPolicy,
+	candidates []string,
+	allocated []FailureDomain,
+	domainOf func(nodeId string) FailureDomain) ([]string, error) {
+
+	if policy == nil {
+		return candidates, nil
+	}
+
+	used := make(map[string]bool)
+	for _, domain := range allocated {
+		used[domain.Get(policy.TopologyKey)] = true
+	}
+
+	var filtered []string
+	for _, nodeId := range candidates {
+		if domain := domainOf(nodeId).Get(policy.TopologyKey); domain != "" && !used[domain] {
+			filtered = append(filtered, nodeId)
+		}
+	}
+
+	if len(filtered) == 0 {
+		if policy.Mode == SpreadRequired {
+			return nil, fmt.Errorf("no free node in a distinct %s", policy.TopologyKey)
+		}
+		return candidates, nil
+	}
+
+	return filtered, nil
+}
+
+// FindSpreadViolations returns a description of each failure domain shared by nodes of the nodegroup, and of nodes
+// without failure domain metadata at the topology key, given the failure domain of each node keyed by node name
+func FindSpreadViolations(policy *SpreadPolicy, groupname string, nodes map[string]FailureDomain) []string {
+	if policy == nil {
+		return nil
+	}
+
+	members := make(map[string][]string)
+	for nodename, domain := range nodes {
+		value := domain.Get(policy.TopologyKey)
+		members[value] = append(members[value], nodename)
+	}
+
+	var violations []string
+	for value, nodenames := range members {
+		slices.Sort(nodenames)
+		if value == "" {
+			violations = append(violations, fmt.Sprintf("%s: unknown %s for %s",
+				groupname, policy.TopologyKey, strings.Join(nodenames, ", ")))
+		} else if len(nodenames) > 1 {
+			violations = append(violations, fmt.Sprintf("%s: %s %s shared by %s",
+				groupname, policy.TopologyKey, value, strings.Join(nodenames, ", ")))
+		}
+	}
+
+	slices.Sort(violations)
+	return violations
+}
+
+// UpdateNodePoolPlacementCondition reports the spread policy violations of the NodePool in the PlacementWarning
+// condition, which is True while any violation remains
+func UpdateNodePoolPlacementCondition(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	violations []string) error {
+
+	reason := ReasonSpreadSatisfied
+	status := metav1.ConditionFalse
+	message := "All nodes are placed in distinct failure domains"
+	if len(violations) > 0 {
+		reason = ReasonSpreadViolated
+		status = metav1.ConditionTrue
+		message = "Spread policy violated: " + strings.Join(violations, "; ")
+	}
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolPlacementWarning))
+	if current != nil && current.Reason == string(reason) && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolPlacementWarning, reason, status, message)
+}
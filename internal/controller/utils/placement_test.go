@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Spread policies", func() {
+	domains := map[string]FailureDomain{
+		"node-1": {Rack: "rack-1", PDU: "pdu-1"},
+		"node-2": {Rack: "rack-1", PDU: "pdu-2"},
+		"node-3": {Rack: "rack-2", PDU: "pdu-1"},
+		"node-4": {},
+	}
+	domainOf := func(nodeId string) FailureDomain { return domains[nodeId] }
+
+	It("parses and defaults the policy for a nodegroup", func() {
+		nodepool := newTestNodePool(map[string]string{SpreadPolicyKey: `
+master:
+  topologyKey: rack
+`})
+		Expect(ValidateNodePoolSpreadPolicies(nodepool)).To(Succeed())
+
+		policy, err := GetNodeGroupSpreadPolicy(nodepool, "master")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy).To(Equal(&SpreadPolicy{TopologyKey: FailureDomainRack, Mode: SpreadRequired}))
+
+		policy, err = GetNodeGroupSpreadPolicy(nodepool, "worker")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy).To(BeNil())
+	})
+
+	It("rejects an invalid policy", func() {
+		Expect(ValidateNodePoolSpreadPolicies(newTestNodePool(map[string]string{SpreadPolicyKey: `
+storage:
+  topologyKey: rack
+`}))).To(MatchError(ContainSubstring("unknown nodegroup storage")))
+
+		Expect(ValidateNodePoolSpreadPolicies(newTestNodePool(map[string]string{SpreadPolicyKey: `
+master:
+  topologyKey: row
+`}))).To(MatchError(ContainSubstring("unsupported topologyKey")))
+
+		Expect(ValidateNodePoolSpreadPolicies(newTestNodePool(map[string]string{SpreadPolicyKey: `
+master:
+  topologyKey: rack
+  mode: Sometimes
+`}))).To(MatchError(ContainSubstring("unsupported mode")))
+	})
+
+	It("filters candidates in used failure domains", func() {
+		candidates := []string{"node-2", "node-4", "node-3"}
+		policy := &SpreadPolicy{TopologyKey: FailureDomainRack, Mode: SpreadRequired}
+
+		filtered, err := FilterSpreadCandidates(nil, candidates, []FailureDomain{domains["node-1"]}, domainOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal(candidates))
+
+		filtered, err = FilterSpreadCandidates(policy, candidates, []FailureDomain{domains["node-1"]}, domainOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal([]string{"node-3"}))
+
+		_, err = FilterSpreadCandidates(policy, []string{"node-2", "node-4"}, []FailureDomain{domains["node-1"]}, domainOf)
+		Expect(err).To(MatchError(ContainSubstring("no free node in a distinct rack")))
+
+		policy.Mode = SpreadPreferred
+		filtered, err = FilterSpreadCandidates(policy, []string{"node-2", "node-4"}, []FailureDomain{domains["node-1"]}, domainOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal([]string{"node-2", "node-4"}))
+	})
+
+	It("reports shared and unknown failure domains", func() {
+		policy := &SpreadPolicy{TopologyKey: FailureDomainRack}
+		Expect(FindSpreadViolations(nil, "master", domains)).To(BeEmpty())
+		Expect(FindSpreadViolations(policy, "master", map[string]FailureDomain{
+			"node-1": domains["node-1"],
+			"node-3": domains["node-3"],
+		})).To(BeEmpty())
+		Expect(FindSpreadViolations(policy, "master", domains)).To(Equal([]string{
+			"master: rack rack-1 shared by node-1, node-2",
+			"master: unknown rack for node-4",
+		}))
+
+		policy.TopologyKey = FailureDomainPDU
+		Expect(FindSpreadViolations(policy, "master", map[string]FailureDomain{
+			"node-1": domains["node-1"],
+			"node-3": domains["node-3"],
+		})).To(Equal([]string{"master: pdu pdu-1 shared by node-1, node-3"}))
+	})
+})
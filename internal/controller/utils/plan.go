@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodePoolPlanAnnotation records, on a NodePool, the plan of the changes to be made to its nodes for the latest
+	// spec change, written before the change is applied
+	NodePoolPlanAnnotation = "hwmgr-plugin.oran.openshift.io/plan"
+)
+
+// NodeProfileChange is a change of the hardware profile of an allocated node
+type NodeProfileChange struct {
+	Node string `json:"node"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NodeGroupPlan is the set of changes to be made to the nodes of a nodegroup
+type NodeGroupPlan struct {
+	Name string `json:"name"`
+	// AddNodes is the number of nodes to be allocated to the nodegroup
+	AddNodes int `json:"addNodes,omitempty"`
+	// ExcessNodes is the number of allocated nodes beyond the size of the nodegroup, which are not released
+	ExcessNodes int `json:"excessNodes,omitempty"`
+	// ProfileChanges are the allocated nodes to be updated to the hardware profile of the nodegroup
+	ProfileChanges []NodeProfileChange `json:"profileChanges,omitempty"`
+}
+
+// NodePoolPlan is the plan of the changes to be made to the nodes of a NodePool for a generation of its spec
+type NodePoolPlan struct {
+	Generation int64           `json:"generation"`
+	NodeGroups []NodeGroupPlan `json:"nodeGroups,omitempty"`
+}
+
+// ComputeNodePoolPlan compares the spec of the NodePool against its allocated nodes, returning the changes to be made
+// to each nodegroup. Nodegroups without changes are omitted.
+func ComputeNodePoolPlan(nodepool *hwmgmtv1alpha1.NodePool, nodelist *hwmgmtv1alpha1.NodeList) *NodePoolPlan {
+	plan := &NodePoolPlan{Generation: nodepool.Generation}
+
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		groupPlan := NodeGroupPlan{Name: nodegroup.NodePoolData.Name}
+
+		allocated := 0
+		for i := range nodelist.Items {
+			node := &nodelist.Items[i]
+			if node.Spec.GroupName != groupPlan.Name {
+				continue
+			}
+			allocated++
+			if node.Spec.HwProfile != nodegroup.NodePoolData.HwProfile {
+				groupPlan.ProfileChanges = append(groupPlan.ProfileChanges, NodeProfileChange{
+					Node: node.Name,
+					From: node.Spec.HwProfile,
+					To:   nodegroup.NodePoolData.HwProfile,
+				})
+			}
+		}
+		slices.SortFunc(groupPlan.ProfileChanges, func(a, b NodeProfileChange) int {
+			return strings.Compare(a.Node, b.Node)
+		})
+
+		if nodegroup.Size > allocated {
+			groupPlan.AddNodes = nodegroup.Size - allocated
+		} else {
+			groupPlan.ExcessNodes = allocated - nodegroup.Size
+		}
+
+		if groupPlan.AddNodes > 0 || groupPlan.ExcessNodes > 0 || len(groupPlan.ProfileChanges) > 0 {
+			plan.NodeGroups = append(plan.NodeGroups, groupPlan)
+		}
+	}
+
+	return plan
+}
+
+// GetNodePoolPlan returns the plan recorded on the NodePool, or nil if there is none or it is invalid
+func GetNodePoolPlan(nodepool *hwmgmtv1alpha1.NodePool) *NodePoolPlan {
+	data, exists := nodepool.GetAnnotations()[NodePoolPlanAnnotation]
+	if !exists {
+		return nil
+	}
+
+	var plan NodePoolPlan
+	if err := json.Unmarshal([]byte(data), &plan); err != nil {
+		return nil
+	}
+	return &plan
+}
+
+// IsNodePoolPlanDue returns true if the spec of the NodePool has changed since it was last processed, and the plan for
+// the change has not yet been recorded
+func IsNodePoolPlanDue(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	if nodepool.Generation == nodepool.Status.HwMgrPlugin.ObservedGeneration {
+		return false
+	}
+
+	plan := GetNodePoolPlan(nodepool)
+	return plan == nil || plan.Generation != nodepool.Generation
+}
+
+// SetNodePoolPlan records the plan in the NodePool annotation. The NodePool is not updated on the cluster.
+func SetNodePoolPlan(nodepool *hwmgmtv1alpha1.NodePool, plan *NodePoolPlan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[NodePoolPlanAnnotation] = string(data)
+	nodepool.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NodePool plan", func() {
+	It("plans the nodes to add and the profiles to change", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Generation = 2
+		nodepool.Status.HwMgrPlugin.ObservedGeneration = 1
+		nodepool.Spec.NodeGroup[0].NodePoolData.HwProfile = "profile-v2"
+		nodepool.Spec.NodeGroup[1].Size = 2
+		nodelist := newTestNodeList("master")
+		nodelist.Items[0].Name = "master-0"
+		nodelist.Items[0].Spec.HwProfile = "profile-v1"
+
+		Expect(IsNodePoolPlanDue(nodepool)).To(BeTrue())
+		plan := ComputeNodePoolPlan(nodepool, nodelist)
+		Expect(plan).To(Equal(&NodePoolPlan{
+			Generation: 2,
+			NodeGroups: []NodeGroupPlan{
+				{Name: "master", ProfileChanges: []NodeProfileChange{{Node: "master-0", From: "profile-v1", To: "profile-v2"}}},
+				{Name: "worker", AddNodes: 2},
+			},
+		}))
+
+		Expect(SetNodePoolPlan(nodepool, plan)).To(Succeed())
+		Expect(GetNodePoolPlan(nodepool)).To(Equal(plan))
+		Expect(IsNodePoolPlanDue(nodepool)).To(BeFalse())
+	})
+
+	It("reports the excess nodes of a shrunk nodegroup", func() {
+		nodepool := newTestNodePool(nil)
+		plan := ComputeNodePoolPlan(nodepool, newTestNodeList("master", "master"))
+		Expect(plan.NodeGroups).To(Equal([]NodeGroupPlan{{Name: "master", ExcessNodes: 1}}))
+	})
+
+	It("is not due once the spec change has been processed", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Generation = 3
+		nodepool.Status.HwMgrPlugin.ObservedGeneration = 3
+		Expect(IsNodePoolPlanDue(nodepool)).To(BeFalse())
+	})
+})
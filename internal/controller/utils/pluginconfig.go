@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"log/slog"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// PluginSettings are the plugin-wide settings from the PluginConfig CR, applied at runtime
+type PluginSettings struct {
+	LogLevel                        slog.Level
+	DisabledAdaptors                []pluginv1alpha1.HardwareManagerAdaptorID
+	DefaultMaxConcurrentAllocations int
+	DisableBackendRequestMetrics    bool
+	AllocationCleanupDisabled       bool
+	AllocationCleanupGracePeriod    time.Duration
+	CallbackSigningSecret           string
+	CallbackTimeout                 time.Duration
+	ProvenanceSigningSecret         string
+}
+
+// DefaultAllocationCleanupGracePeriod is the time for which the allocations of a cloud must have had no NodePool
+// before they are released
+const DefaultAllocationCleanupGracePeriod = 1 * time.Hour
+
+// DefaultCallbackTimeout is the time allowed for each NodePool callback request
+const DefaultCallbackTimeout = 10 * time.Second
+
+// The settings are replaced as a whole on each change, so readers always see a consistent set
+var pluginSettings atomic.Pointer[PluginSettings]
+
+// GetPluginSettings returns the current plugin-wide settings, or the defaults if no PluginConfig has been applied
+func GetPluginSettings() PluginSettings {
+	if settings := pluginSettings.Load(); settings != nil {
+		return *settings
+	}
+	return PluginSettings{LogLevel: slog.LevelInfo}
+}
+
+// ParsePluginSettings validates the PluginConfig spec and converts it to the plugin-wide settings
+func ParsePluginSettings(spec *pluginv1alpha1.PluginConfigSpec) (*PluginSettings, error) {
+	settings := &PluginSettings{
+		DisabledAdaptors:                spec.DisabledAdaptors,
+		DefaultMaxConcurrentAllocations: spec.DefaultMaxConcurrentAllocations,
+	}
+
+	switch spec.LogLevel {
+	case pluginv1alpha1.LogLevels.Debug:
+		settings.LogLevel = slog.LevelDebug
+	case pluginv1alpha1.LogLevels.Info, "":
+		settings.LogLevel = slog.LevelInfo
+	case pluginv1alpha1.LogLevels.Warn:
+		settings.LogLevel = slog.LevelWarn
+	case pluginv1alpha1.LogLevels.Error:
+		settings.LogLevel = slog.LevelError
+	default:
+		return nil, NewInputError("unsupported log level: %s", spec.LogLevel)
+	}
+
+	supported := []pluginv1alpha1.HardwareManagerAdaptorID{
+		pluginv1alpha1.SupportedAdaptors.Loopback,
+		pluginv1alpha1.SupportedAdaptors.Dell,
+		pluginv1alpha1.SupportedAdaptors.Rest,
+	}
+	for _, adaptorID := range spec.DisabledAdaptors {
+		if !slices.Contains(supported, adaptorID) {
+			return nil, NewInputError("unsupported adaptor ID in disabledAdaptors: %s", adaptorID)
+		}
+	}
+
+	if spec.DefaultMaxConcurrentAllocations < 0 {
+		return nil, NewInputError("defaultMaxConcurrentAllocations must not be negative")
+	}
+
+	if spec.Metrics != nil {
+		settings.DisableBackendRequestMetrics = spec.Metrics.DisableBackendRequestMetrics
+	}
+
+	if spec.AllocationCleanup != nil {
+		settings.AllocationCleanupDisabled = spec.AllocationCleanup.Disabled
+		if spec.AllocationCleanup.GracePeriod != nil {
+			if spec.AllocationCleanup.GracePeriod.Duration < 0 {
+				return nil, NewInputError("allocationCleanup gracePeriod must not be negative")
+			}
+			settings.AllocationCleanupGracePeriod = spec.AllocationCleanup.GracePeriod.Duration
+		}
+	}
+
+	if spec.Callbacks != nil {
+		settings.CallbackSigningSecret = spec.Callbacks.SigningSecret
+		if spec.Callbacks.Timeout != nil {
+			if spec.Callbacks.Timeout.Duration < 0 {
+				return nil, NewInputError("callbacks timeout must not be negative")
+			}
+			settings.CallbackTimeout = spec.Callbacks.Timeout.Duration
+		}
+	}
+
+	if spec.BMCSecretProvenance != nil {
+		settings.ProvenanceSigningSecret = spec.BMCSecretProvenance.SigningSecret
+	}
+
+	return settings, nil
+}
+
+// SetPluginSettings replaces the plugin-wide settings. A nil value restores the defaults.
+func SetPluginSettings(settings *PluginSettings) {
+	pluginSettings.Store(settings)
+}
+
+// IsAdaptorDisabled returns true if NodePool processing is suspended for the adaptor
+func IsAdaptorDisabled(adaptorID pluginv1alpha1.HardwareManagerAdaptorID) bool {
+	return slices.Contains(GetPluginSettings().DisabledAdaptors, adaptorID)
+}
+
+// GetMaxConcurrentAllocations returns the allocation limit for a hardware manager, falling back to the plugin-wide
+// default if not set. Zero is unlimited.
+func GetMaxConcurrentAllocations(hwmgr *pluginv1alpha1.HardwareManager) int {
+	if hwmgr.Spec.MaxConcurrentAllocations > 0 {
+		return hwmgr.Spec.MaxConcurrentAllocations
+	}
+	return GetPluginSettings().DefaultMaxConcurrentAllocations
+}
+
+// GetAllocationCleanupGracePeriod returns the grace period before orphaned allocations are released, and whether the
+// cleanup is enabled
+func GetAllocationCleanupGracePeriod() (time.Duration, bool) {
+	settings := GetPluginSettings()
+	if settings.AllocationCleanupDisabled {
+		return 0, false
+	}
+	if settings.AllocationCleanupGracePeriod > 0 {
+		return settings.AllocationCleanupGracePeriod, true
+	}
+	return DefaultAllocationCleanupGracePeriod, true
+}
+
+// GetCallbackTimeout returns the time allowed for each NodePool callback request
+func GetCallbackTimeout() time.Duration {
+	if timeout := GetPluginSettings().CallbackTimeout; timeout > 0 {
+		return timeout
+	}
+	return DefaultCallbackTimeout
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Plugin settings", func() {
+	AfterEach(func() {
+		SetPluginSettings(nil)
+	})
+
+	It("defaults when no PluginConfig is applied", func() {
+		Expect(GetPluginSettings().LogLevel).To(Equal(slog.LevelInfo))
+		Expect(IsAdaptorDisabled(pluginv1alpha1.SupportedAdaptors.Loopback)).To(BeFalse())
+		Expect(GetMaxConcurrentAllocations(&pluginv1alpha1.HardwareManager{})).To(Equal(0))
+	})
+
+	It("parses and applies the PluginConfig spec", func() {
+		settings, err := ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			LogLevel:                        pluginv1alpha1.LogLevels.Debug,
+			DisabledAdaptors:                []pluginv1alpha1.HardwareManagerAdaptorID{pluginv1alpha1.SupportedAdaptors.Dell},
+			DefaultMaxConcurrentAllocations: 4,
+			Metrics:                         &pluginv1alpha1.MetricsConfig{DisableBackendRequestMetrics: true},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		SetPluginSettings(settings)
+
+		Expect(GetPluginSettings().LogLevel).To(Equal(slog.LevelDebug))
+		Expect(GetPluginSettings().DisableBackendRequestMetrics).To(BeTrue())
+		Expect(IsAdaptorDisabled(pluginv1alpha1.SupportedAdaptors.Dell)).To(BeTrue())
+		Expect(IsAdaptorDisabled(pluginv1alpha1.SupportedAdaptors.Rest)).To(BeFalse())
+
+		// The HardwareManager setting takes precedence over the plugin-wide default
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		Expect(GetMaxConcurrentAllocations(hwmgr)).To(Equal(4))
+		hwmgr.Spec.MaxConcurrentAllocations = 2
+		Expect(GetMaxConcurrentAllocations(hwmgr)).To(Equal(2))
+	})
+
+	It("defaults the allocation cleanup grace period", func() {
+		gracePeriod, enabled := GetAllocationCleanupGracePeriod()
+		Expect(enabled).To(BeTrue())
+		Expect(gracePeriod).To(Equal(DefaultAllocationCleanupGracePeriod))
+
+		settings, err := ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			AllocationCleanup: &pluginv1alpha1.AllocationCleanupConfig{GracePeriod: &metav1.Duration{Duration: 10 * time.Minute}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		SetPluginSettings(settings)
+		gracePeriod, enabled = GetAllocationCleanupGracePeriod()
+		Expect(enabled).To(BeTrue())
+		Expect(gracePeriod).To(Equal(10 * time.Minute))
+
+		settings, err = ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			AllocationCleanup: &pluginv1alpha1.AllocationCleanupConfig{Disabled: true},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		SetPluginSettings(settings)
+		_, enabled = GetAllocationCleanupGracePeriod()
+		Expect(enabled).To(BeFalse())
+	})
+
+	It("defaults the callback timeout", func() {
+		Expect(GetCallbackTimeout()).To(Equal(DefaultCallbackTimeout))
+
+		settings, err := ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			Callbacks: &pluginv1alpha1.CallbackConfig{SigningSecret: "callback-key", Timeout: &metav1.Duration{Duration: 30 * time.Second}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		SetPluginSettings(settings)
+		Expect(GetPluginSettings().CallbackSigningSecret).To(Equal("callback-key"))
+		Expect(GetCallbackTimeout()).To(Equal(30 * time.Second))
+	})
+
+	It("parses the bmc-secret provenance signing secret", func() {
+		settings, err := ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			BMCSecretProvenance: &pluginv1alpha1.BMCSecretProvenanceConfig{SigningSecret: "provenance-key"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.ProvenanceSigningSecret).To(Equal("provenance-key"))
+	})
+
+	It("rejects invalid settings", func() {
+		_, err := ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{LogLevel: "trace"})
+		Expect(err).To(HaveOccurred())
+
+		_, err = ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			DisabledAdaptors: []pluginv1alpha1.HardwareManagerAdaptorID{"unknown"},
+		})
+		Expect(err).To(HaveOccurred())
+
+		_, err = ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			AllocationCleanup: &pluginv1alpha1.AllocationCleanupConfig{GracePeriod: &metav1.Duration{Duration: -time.Minute}},
+		})
+		Expect(err).To(HaveOccurred())
+
+		_, err = ParsePluginSettings(&pluginv1alpha1.PluginConfigSpec{
+			Callbacks: &pluginv1alpha1.CallbackConfig{Timeout: &metav1.Duration{Duration: -time.Second}},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,176 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Node condition types used to report the power state and boot progress of the node, as queried from the backend.
+// The reason of each condition holds the current value.
+const (
+	NodePowerState   hwmgmtv1alpha1.ConditionType = "PowerState"
+	NodeBootProgress hwmgmtv1alpha1.ConditionType = "BootProgress"
+)
+
+// PowerStateAnnotation requests, on a Node, the desired power state of the node, On or Off. It is reconciled by the
+// adaptors that support power control, with the actual power state reported in the PowerState condition.
+const PowerStateAnnotation = "hwmgr-plugin.oran.openshift.io/powerState"
+
+// NodePowerControl is the condition type set on a Node when the desired power state cannot be applied
+const NodePowerControl hwmgmtv1alpha1.ConditionType = "PowerControl"
+
+// PowerState is the power state of a node, as reported by the BMC or backend
+type PowerState string
+
+const (
+	PowerStateOn      PowerState = "On"
+	PowerStateOff     PowerState = "Off"
+	PowerStateUnknown PowerState = "Unknown"
+)
+
+// BootProgress is the boot progress of a node, as reported by the BMC or backend
+type BootProgress string
+
+const (
+	BootProgressNone      BootProgress = "None"
+	BootProgressBooting   BootProgress = "Booting"
+	BootProgressOSRunning BootProgress = "OSRunning"
+	BootProgressUnknown   BootProgress = "Unknown"
+)
+
+// NormalizePowerState maps a backend-specific power state string to a PowerState
+func NormalizePowerState(state string) PowerState {
+	switch {
+	case strings.EqualFold(state, string(PowerStateOn)):
+		return PowerStateOn
+	case strings.EqualFold(state, string(PowerStateOff)):
+		return PowerStateOff
+	default:
+		return PowerStateUnknown
+	}
+}
+
+// NormalizeBootProgress maps a backend-specific boot progress string to a BootProgress
+func NormalizeBootProgress(progress string) BootProgress {
+	for _, known := range []BootProgress{BootProgressNone, BootProgressBooting, BootProgressOSRunning} {
+		if strings.EqualFold(progress, string(known)) {
+			return known
+		}
+	}
+	return BootProgressUnknown
+}
+
+// GetNodePowerState returns the last reported power state of the node
+func GetNodePowerState(node *hwmgmtv1alpha1.Node) PowerState {
+	condition := meta.FindStatusCondition(node.Status.Conditions, string(NodePowerState))
+	if condition == nil {
+		return PowerStateUnknown
+	}
+	return PowerState(condition.Reason)
+}
+
+// GetNodeDesiredPowerState returns the power state requested for the node, and whether a power state is requested
+func GetNodeDesiredPowerState(node *hwmgmtv1alpha1.Node) (PowerState, bool, error) {
+	value, exists := node.GetAnnotations()[PowerStateAnnotation]
+	if !exists {
+		return "", false, nil
+	}
+
+	state := NormalizePowerState(value)
+	if state == PowerStateUnknown {
+		return "", true, NewInputError("invalid %s annotation %q: must be one of %s, %s",
+			PowerStateAnnotation, value, PowerStateOn, PowerStateOff)
+	}
+	return state, true, nil
+}
+
+// GetNodeBootProgress returns the last reported boot progress of the node
+func GetNodeBootProgress(node *hwmgmtv1alpha1.Node) BootProgress {
+	condition := meta.FindStatusCondition(node.Status.Conditions, string(NodeBootProgress))
+	if condition == nil {
+		return BootProgressUnknown
+	}
+	return BootProgress(condition.Reason)
+}
+
+// SetNodePowerStatus sets the PowerState and BootProgress conditions on the node, returning true
+// if either value has changed
+func SetNodePowerStatus(node *hwmgmtv1alpha1.Node, powerState PowerState, bootProgress BootProgress) bool {
+	powerCondition := meta.FindStatusCondition(node.Status.Conditions, string(NodePowerState))
+	bootCondition := meta.FindStatusCondition(node.Status.Conditions, string(NodeBootProgress))
+	if powerCondition != nil && powerCondition.Reason == string(powerState) &&
+		bootCondition != nil && bootCondition.Reason == string(bootProgress) {
+		return false
+	}
+
+	powerStatus := metav1.ConditionUnknown
+	switch powerState {
+	case PowerStateOn:
+		powerStatus = metav1.ConditionTrue
+	case PowerStateOff:
+		powerStatus = metav1.ConditionFalse
+	}
+
+	bootStatus := metav1.ConditionUnknown
+	switch bootProgress {
+	case BootProgressOSRunning:
+		bootStatus = metav1.ConditionTrue
+	case BootProgressNone, BootProgressBooting:
+		bootStatus = metav1.ConditionFalse
+	}
+
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodePowerState),
+		string(powerState),
+		powerStatus,
+		"Power state is "+string(powerState))
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeBootProgress),
+		string(bootProgress),
+		bootStatus,
+		"Boot progress is "+string(bootProgress))
+
+	return true
+}
+
+// SetNodePowerControlStatus sets the PowerControl condition of the node, which is True once the desired power state is
+// applied, or False with reason Failed and the error otherwise, returning true if the condition has changed. The status
+// is not updated on the cluster.
+func SetNodePowerControlStatus(node *hwmgmtv1alpha1.Node, desired PowerState, err error) bool {
+	reason := hwmgmtv1alpha1.Completed
+	status := metav1.ConditionTrue
+	message := "Power state " + string(desired) + " applied"
+	if err != nil {
+		reason = hwmgmtv1alpha1.Failed
+		status = metav1.ConditionFalse
+		message = "Unable to apply power state: " + err.Error()
+	}
+
+	condition := meta.FindStatusCondition(node.Status.Conditions, string(NodePowerControl))
+	if condition != nil && condition.Reason == string(reason) && condition.Message == message {
+		return false
+	}
+
+	SetStatusCondition(&node.Status.Conditions, string(NodePowerControl), string(reason), status, message)
+	return true
+}
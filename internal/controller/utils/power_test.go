@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Node power control", func() {
+	newNode := func(annotations map[string]string) *hwmgmtv1alpha1.Node {
+		return &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: annotations}}
+	}
+
+	It("parses the desired power state", func() {
+		state, requested, err := GetNodeDesiredPowerState(newNode(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requested).To(BeFalse())
+		Expect(state).To(BeEmpty())
+
+		state, requested, err = GetNodeDesiredPowerState(newNode(map[string]string{PowerStateAnnotation: "off"}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requested).To(BeTrue())
+		Expect(state).To(Equal(PowerStateOff))
+
+		_, requested, err = GetNodeDesiredPowerState(newNode(map[string]string{PowerStateAnnotation: "standby"}))
+		Expect(requested).To(BeTrue())
+		Expect(IsInputError(err)).To(BeTrue())
+	})
+
+	It("reports the outcome in the PowerControl condition", func() {
+		node := newNode(nil)
+		Expect(SetNodePowerControlStatus(node, PowerStateOff, errors.New("backend unavailable"))).To(BeTrue())
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(NodePowerControl))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(hwmgmtv1alpha1.Failed)))
+		Expect(condition.Message).To(Equal("Unable to apply power state: backend unavailable"))
+		Expect(SetNodePowerControlStatus(node, PowerStateOff, errors.New("backend unavailable"))).To(BeFalse())
+
+		Expect(SetNodePowerControlStatus(node, PowerStateOff, nil)).To(BeTrue())
+		condition = meta.FindStatusCondition(node.Status.Conditions, string(NodePowerControl))
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(Equal("Power state Off applied"))
+	})
+})
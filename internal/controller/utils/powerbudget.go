@@ -0,0 +1,215 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// PowerBudgetExceeded condition type and reasons, set on a NodePool whose allocation would exceed the power budget of
+// a resource pool
+const (
+	NodePoolPowerBudgetExceeded hwmgmtv1alpha1.ConditionType   = "PowerBudgetExceeded"
+	ReasonPowerBudgetEnforced   hwmgmtv1alpha1.ConditionReason = "BudgetEnforced"
+	ReasonPowerBudgetWarning    hwmgmtv1alpha1.ConditionReason = "BudgetWarning"
+	ReasonWithinPowerBudget     hwmgmtv1alpha1.ConditionReason = "WithinBudget"
+)
+
+// PowerBudgetViolation describes a resource pool whose power budget would be exceeded by the allocation of a NodePool
+type PowerBudgetViolation struct {
+	ResourcePoolId string
+	BudgetWatts    int
+	CommittedWatts int
+	RequestedWatts int
+	Enforced       bool
+}
+
+// GetPowerBudget returns the power budget of a resource pool, or nil if it has none
+func GetPowerBudget(hwmgr *pluginv1alpha1.HardwareManager, poolID string) *pluginv1alpha1.PowerBudget {
+	for i := range hwmgr.Spec.PowerBudgets {
+		if hwmgr.Spec.PowerBudgets[i].ResourcePoolId == poolID {
+			return &hwmgr.Spec.PowerBudgets[i]
+		}
+	}
+	return nil
+}
+
+// IsPowerBudgetEnforced returns true if allocations exceeding the budget are held
+func IsPowerBudgetEnforced(budget *pluginv1alpha1.PowerBudget) bool {
+	return budget.Enforcement != pluginv1alpha1.PowerBudgetEnforcements.Warn
+}
+
+// EstimateNodePower returns the estimated power draw of a node of the NodePool with a hardware profile, in watts. The
+// estimate of the profile falls back to the default of the budget, and is limited by the power cap of the NodePool.
+func EstimateNodePower(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	budget *pluginv1alpha1.PowerBudget,
+	hwprofile string) int {
+
+	watts := budget.DefaultNodePowerWatts
+	if profile := getHwProfile(hwmgr, hwprofile); profile != nil && profile.EstimatedPowerWatts > 0 {
+		watts = profile.EstimatedPowerWatts
+	}
+
+	// An invalid energy policy is reported when the nodes are configured
+	if powercap, err := GetNodePoolPowerCap(hwmgr, nodepool); err == nil && powercap > 0 {
+		watts = min(watts, powercap)
+	}
+
+	return watts
+}
+
+// GetNodePoolPowerDraw returns the estimated power draw of the nodes and spares of the NodePool, in watts, for each
+// resource pool with a power budget
+func GetNodePoolPowerDraw(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) map[string]int {
+	draw := make(map[string]int)
+	if len(hwmgr.Spec.PowerBudgets) == 0 {
+		return draw
+	}
+
+	// An invalid spare configuration is reported when the spares are reconciled
+	spares, _ := GetNodePoolSpareConfig(nodepool)
+
+	add := func(poolID, hwprofile string, count int) {
+		if budget := GetPowerBudget(hwmgr, poolID); budget != nil && count > 0 {
+			draw[poolID] += count * EstimateNodePower(hwmgr, nodepool, budget, hwprofile)
+		}
+	}
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		add(nodegroup.NodePoolData.ResourcePoolId, nodegroup.NodePoolData.HwProfile, nodegroup.Size)
+		if config, exists := spares[nodegroup.NodePoolData.Name]; exists {
+			add(config.SparePoolId, nodegroup.NodePoolData.HwProfile, config.Count)
+		}
+	}
+
+	return draw
+}
+
+// IsNodePoolPowerCommitted returns true if the power draw of the NodePool is counted against the power budgets, which
+// is the case once the NodePool has been accepted for processing and until it is deleted
+func IsNodePoolPowerCommitted(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(nodepool, NodepoolFinalizer)
+}
+
+// CheckNodePoolPowerBudgets returns the resource pools whose power budget would be exceeded by the allocation of the
+// NodePool, sorted, on top of the power committed to the other NodePools of the hardware manager
+func CheckNodePoolPowerBudgets(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	others []hwmgmtv1alpha1.NodePool) []PowerBudgetViolation {
+
+	requested := GetNodePoolPowerDraw(hwmgr, nodepool)
+	if len(requested) == 0 {
+		return nil
+	}
+
+	committed := make(map[string]int)
+	for i := range others {
+		other := &others[i]
+		if other.Name == nodepool.Name && other.Namespace == nodepool.Namespace {
+			continue
+		}
+		// The nodegroups of a split NodePool are counted through the NodePools split from it
+		if other.Spec.HwMgrId != hwmgr.Name || IsSplitNodePool(other) || !IsNodePoolPowerCommitted(other) {
+			continue
+		}
+		for poolID, watts := range GetNodePoolPowerDraw(hwmgr, other) {
+			committed[poolID] += watts
+		}
+	}
+
+	var violations []PowerBudgetViolation
+	for poolID, watts := range requested {
+		budget := GetPowerBudget(hwmgr, poolID)
+		if committed[poolID]+watts <= budget.BudgetWatts {
+			continue
+		}
+		violations = append(violations, PowerBudgetViolation{
+			ResourcePoolId: poolID,
+			BudgetWatts:    budget.BudgetWatts,
+			CommittedWatts: committed[poolID],
+			RequestedWatts: watts,
+			Enforced:       IsPowerBudgetEnforced(budget),
+		})
+	}
+
+	slices.SortFunc(violations, func(a, b PowerBudgetViolation) int {
+		return strings.Compare(a.ResourcePoolId, b.ResourcePoolId)
+	})
+	return violations
+}
+
+// IsPowerBudgetEnforcedViolation returns true if any of the violations is of an enforced budget
+func IsPowerBudgetEnforcedViolation(violations []PowerBudgetViolation) bool {
+	return slices.ContainsFunc(violations, func(violation PowerBudgetViolation) bool {
+		return violation.Enforced
+	})
+}
+
+// FormatPowerBudgetViolations returns a description of the violations, for the condition message
+func FormatPowerBudgetViolations(violations []PowerBudgetViolation) string {
+	var details []string
+	for _, violation := range violations {
+		details = append(details, fmt.Sprintf("%s (requested %dW, committed %dW, budget %dW)",
+			violation.ResourcePoolId, violation.RequestedWatts, violation.CommittedWatts, violation.BudgetWatts))
+	}
+	return "Power budget exceeded for resource pools: " + strings.Join(details, ", ")
+}
+
+// UpdateNodePoolPowerBudgetCondition sets the PowerBudgetExceeded condition of the NodePool from the violations of the
+// power budgets. The condition is only cleared if it was previously set, and the status is only updated if the
+// condition has changed.
+func UpdateNodePoolPowerBudgetCondition(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	violations []PowerBudgetViolation) error {
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolPowerBudgetExceeded))
+	if len(violations) == 0 {
+		if current == nil || current.Status == metav1.ConditionFalse {
+			return nil
+		}
+		return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolPowerBudgetExceeded, ReasonWithinPowerBudget,
+			metav1.ConditionFalse, "Allocation is within the power budgets")
+	}
+
+	reason := ReasonPowerBudgetWarning
+	if IsPowerBudgetEnforcedViolation(violations) {
+		reason = ReasonPowerBudgetEnforced
+	}
+	message := FormatPowerBudgetViolations(violations)
+	if current != nil && current.Status == metav1.ConditionTrue && current.Reason == string(reason) &&
+		current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolPowerBudgetExceeded, reason,
+		metav1.ConditionTrue, message)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Power budgets", func() {
+	newHwMgr := func(budgets ...pluginv1alpha1.PowerBudget) *pluginv1alpha1.HardwareManager {
+		return &pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "loopback-1"},
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				HwProfiles: []pluginv1alpha1.HardwareProfile{
+					{Name: "profile-master", EstimatedPowerWatts: 400},
+					{Name: "profile-worker", EstimatedPowerWatts: 600},
+				},
+				PowerBudgets: budgets,
+			},
+		}
+	}
+
+	newNodePool := func(name string, masters, workers int, committed bool) hwmgmtv1alpha1.NodePool {
+		nodepool := newTestNodePool(nil)
+		nodepool.Name = name
+		nodepool.Spec.HwMgrId = "loopback-1"
+		nodepool.Spec.NodeGroup[0].Size = masters
+		nodepool.Spec.NodeGroup[0].NodePoolData.HwProfile = "profile-master"
+		nodepool.Spec.NodeGroup[1].Size = workers
+		nodepool.Spec.NodeGroup[1].NodePoolData.HwProfile = "profile-worker"
+		if committed {
+			nodepool.Finalizers = []string{NodepoolFinalizer}
+		}
+		return *nodepool
+	}
+
+	It("estimates the power draw of a NodePool per budgeted resource pool", func() {
+		hwmgr := newHwMgr(pluginv1alpha1.PowerBudget{ResourcePoolId: "worker", BudgetWatts: 2000})
+		nodepool := newNodePool("site-a", 1, 2, false)
+		Expect(GetNodePoolPowerDraw(hwmgr, &nodepool)).To(Equal(map[string]int{"worker": 1200}))
+		Expect(GetNodePoolPowerDraw(newHwMgr(), &nodepool)).To(BeEmpty())
+	})
+
+	It("estimates unknown profiles from the default, limited by the power cap", func() {
+		budget := pluginv1alpha1.PowerBudget{ResourcePoolId: "worker", BudgetWatts: 2000, DefaultNodePowerWatts: 350}
+		hwmgr := newHwMgr(budget)
+		nodepool := newNodePool("site-a", 1, 2, false)
+		Expect(EstimateNodePower(hwmgr, &nodepool, &budget, "profile-other")).To(Equal(350))
+		Expect(EstimateNodePower(hwmgr, &nodepool, &budget, "profile-worker")).To(Equal(600))
+
+		hwmgr.Spec.EnergyPolicy = &pluginv1alpha1.EnergyPolicy{PowerCapWatts: 450}
+		Expect(EstimateNodePower(hwmgr, &nodepool, &budget, "profile-worker")).To(Equal(450))
+		Expect(EstimateNodePower(hwmgr, &nodepool, &budget, "profile-other")).To(Equal(350))
+	})
+
+	It("counts the spares against the budget of the spare pool", func() {
+		hwmgr := newHwMgr(pluginv1alpha1.PowerBudget{ResourcePoolId: "spares", BudgetWatts: 2000})
+		nodepool := newNodePool("site-a", 1, 2, false)
+		nodepool.Spec.Extensions = map[string]string{SpareNodesKey: `
+worker:
+  count: 2
+  sparePoolId: spares
+`}
+		Expect(GetNodePoolPowerDraw(hwmgr, &nodepool)).To(Equal(map[string]int{"spares": 1200}))
+	})
+
+	It("reports the budgets exceeded on top of the committed NodePools", func() {
+		hwmgr := newHwMgr(
+			pluginv1alpha1.PowerBudget{ResourcePoolId: "master", BudgetWatts: 1000},
+			pluginv1alpha1.PowerBudget{ResourcePoolId: "worker", BudgetWatts: 3000,
+				Enforcement: pluginv1alpha1.PowerBudgetEnforcements.Warn})
+		nodepool := newNodePool("site-c", 1, 3, false)
+
+		others := []hwmgmtv1alpha1.NodePool{
+			newNodePool("site-a", 1, 2, true),
+			// Not yet committed
+			newNodePool("site-b", 1, 2, false),
+			// The NodePool itself is not counted twice
+			newNodePool("site-c", 1, 3, true),
+		}
+		other := newNodePool("site-d", 1, 2, true)
+		other.Spec.HwMgrId = "dell-1"
+		others = append(others, other)
+
+		violations := CheckNodePoolPowerBudgets(hwmgr, &nodepool, others)
+		Expect(violations).To(BeEmpty())
+
+		others = append(others, newNodePool("site-e", 1, 1, true))
+		violations = CheckNodePoolPowerBudgets(hwmgr, &nodepool, others)
+		Expect(violations).To(Equal([]PowerBudgetViolation{
+			{ResourcePoolId: "master", BudgetWatts: 1000, CommittedWatts: 800, RequestedWatts: 400, Enforced: true},
+			{ResourcePoolId: "worker", BudgetWatts: 3000, CommittedWatts: 1800, RequestedWatts: 1800, Enforced: false},
+		}))
+		Expect(IsPowerBudgetEnforcedViolation(violations)).To(BeTrue())
+		Expect(IsPowerBudgetEnforcedViolation(violations[1:])).To(BeFalse())
+		Expect(FormatPowerBudgetViolations(violations[:1])).To(Equal(
+			"Power budget exceeded for resource pools: master (requested 400W, committed 800W, budget 1000W)"))
+	})
+})
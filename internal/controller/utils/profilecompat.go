@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// ProfileCompatible condition type and reasons, reporting whether the free nodes of the NodePool resource pools could
+// satisfy the hardware profiles of its nodegroups at allocation. The condition is only set once a profile has been
+// found incompatible.
+const (
+	NodePoolProfileCompatible hwmgmtv1alpha1.ConditionType   = "ProfileCompatible"
+	ReasonProfileCompatible   hwmgmtv1alpha1.ConditionReason = "Compatible"
+	ReasonProfileIncompatible hwmgmtv1alpha1.ConditionReason = "ProfileIncompatible"
+)
+
+// NodeCapabilities are the hardware capabilities of a node, checked against the requirements of a hardware profile.
+// A capability of zero is not reported by the backend, and is not checked.
+type NodeCapabilities struct {
+	PhysicalDisks int
+	Interfaces    int
+	// Security is checked only for the states that are reported. A node whose secure boot is disabled is compatible,
+	// as it may be enabled on allocation.
+	Security NodeSecurityState
+}
+
+// ProfileIncompatibleError is returned when the free nodes of a resource pool that could otherwise be allocated to a
+// nodegroup cannot satisfy its hardware profile
+type ProfileIncompatibleError struct {
+	HwProfile      string
+	ResourcePoolId string
+	Incompatible   int
+	Candidates     int
+	// Reason describes why the first incompatible node does not satisfy the profile
+	Reason string
+}
+
+func (e *ProfileIncompatibleError) Error() string {
+	return fmt.Sprintf("%d of %d free nodes in resource pool %s are incompatible with hardware profile %s: %s",
+		e.Incompatible, e.Candidates, e.ResourcePoolId, e.HwProfile, e.Reason)
+}
+
+// AsProfileIncompatibleError returns the ProfileIncompatibleError in the chain of err, if any
+func AsProfileIncompatibleError(err error) (*ProfileIncompatibleError, bool) {
+	var profileErr *ProfileIncompatibleError
+	if errors.As(err, &profileErr) {
+		return profileErr, true
+	}
+	return nil, false
+}
+
+// getHwProfile returns the hardware profile of the hardware manager, or nil if it is not defined
+func getHwProfile(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string) *pluginv1alpha1.HardwareProfile {
+	for i := range hwmgr.Spec.HwProfiles {
+		if hwmgr.Spec.HwProfiles[i].Name == hwprofile {
+			return &hwmgr.Spec.HwProfiles[i]
+		}
+	}
+	return nil
+}
+
+// CheckHwProfileCompatibility returns an error describing each requirement of the hardware profile that the node
+// capabilities do not satisfy, or nil if the node is compatible. A profile not defined on the hardware manager has no
+// requirements.
+func CheckHwProfileCompatibility(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string, capabilities NodeCapabilities) error {
+	profile := getHwProfile(hwmgr, hwprofile)
+	if profile == nil {
+		return nil
+	}
+
+	var problems []string
+	if required := GetStorageLayoutPhysicalDisks(profile.Storage); capabilities.PhysicalDisks != 0 && capabilities.PhysicalDisks < required {
+		problems = append(problems, fmt.Sprintf("storage layout requires %d physical disks, but node has %d",
+			required, capabilities.PhysicalDisks))
+	}
+	if capabilities.Interfaces != 0 && capabilities.Interfaces < profile.MinInterfaces {
+		problems = append(problems, fmt.Sprintf("profile requires %d interfaces, but node has %d",
+			profile.MinInterfaces, capabilities.Interfaces))
+	}
+	problems = append(problems, checkSecurity(profile.Security, capabilities.Security, false)...)
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// FilterProfileCompatibleCandidates returns the candidate nodes, in order, whose capabilities satisfy the hardware
+// profile. A ProfileIncompatibleError is returned if fewer than the required number of candidates are compatible, when
+// incompatible candidates would otherwise have made up the shortfall.
+func FilterProfileCompatibleCandidates(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	hwprofile, resourcePoolId string,
+	required int,
+	candidates []string,
+	capabilitiesOf func(nodeId string) NodeCapabilities) ([]string, error) {
+
+	var filtered []string
+	var reason string
+	for _, nodeId := range candidates {
+		if err := CheckHwProfileCompatibility(hwmgr, hwprofile, capabilitiesOf(nodeId)); err != nil {
+			if reason == "" {
+				reason = fmt.Sprintf("node %s: %s", nodeId, err.Error())
+			}
+			continue
+		}
+		filtered = append(filtered, nodeId)
+	}
+
+	if len(filtered) < required && len(filtered) < len(candidates) {
+		return nil, &ProfileIncompatibleError{
+			HwProfile:      hwprofile,
+			ResourcePoolId: resourcePoolId,
+			Incompatible:   len(candidates) - len(filtered),
+			Candidates:     len(candidates),
+			Reason:         reason,
+		}
+	}
+
+	return filtered, nil
+}
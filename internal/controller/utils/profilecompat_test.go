@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Hardware profile compatibility", func() {
+	hwmgr := &pluginv1alpha1.HardwareManager{
+		Spec: pluginv1alpha1.HardwareManagerSpec{
+			HwProfiles: []pluginv1alpha1.HardwareProfile{{
+				Name: "profile-raid",
+				Storage: &pluginv1alpha1.StorageLayout{
+					VirtualDisks: []pluginv1alpha1.VirtualDisk{{Name: "os", RAIDLevel: pluginv1alpha1.RAID1}},
+				},
+				MinInterfaces: 2,
+			}},
+		},
+	}
+
+	capabilities := map[string]NodeCapabilities{
+		"node-0": {PhysicalDisks: 2, Interfaces: 2},
+		"node-1": {PhysicalDisks: 1, Interfaces: 2},
+		"node-2": {PhysicalDisks: 4, Interfaces: 1},
+		"node-3": {},
+	}
+	capabilitiesOf := func(nodeId string) NodeCapabilities {
+		return capabilities[nodeId]
+	}
+
+	It("checks the node capabilities against the profile", func() {
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-raid", capabilities["node-0"])).To(Succeed())
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-raid", capabilities["node-1"])).
+			To(MatchError(ContainSubstring("requires 2 physical disks, but node has 1")))
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-raid", capabilities["node-2"])).
+			To(MatchError(ContainSubstring("requires 2 interfaces, but node has 1")))
+	})
+
+	It("does not check unreported capabilities or undefined profiles", func() {
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-raid", capabilities["node-3"])).To(Succeed())
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-other", capabilities["node-1"])).To(Succeed())
+	})
+
+	It("filters the compatible candidates", func() {
+		filtered, err := FilterProfileCompatibleCandidates(hwmgr, "profile-raid", "master", 2,
+			[]string{"node-0", "node-1", "node-2", "node-3"}, capabilitiesOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal([]string{"node-0", "node-3"}))
+	})
+
+	It("fails when incompatible candidates are needed", func() {
+		_, err := FilterProfileCompatibleCandidates(hwmgr, "profile-raid", "master", 2,
+			[]string{"node-0", "node-1", "node-2"}, capabilitiesOf)
+		profileErr, ok := AsProfileIncompatibleError(err)
+		Expect(ok).To(BeTrue())
+		Expect(profileErr.Incompatible).To(Equal(2))
+		Expect(profileErr.Candidates).To(Equal(3))
+		Expect(profileErr.Error()).To(ContainSubstring("node node-1: storage layout requires 2 physical disks"))
+	})
+
+	It("leaves a shortage of free nodes to the caller", func() {
+		filtered, err := FilterProfileCompatibleCandidates(hwmgr, "profile-raid", "master", 2,
+			[]string{"node-0"}, capabilitiesOf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filtered).To(Equal([]string{"node-0"}))
+	})
+})
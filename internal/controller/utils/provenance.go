@@ -0,0 +1,171 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Annotations recording, on a bmc-secret, how its credentials reached the cluster, so that security teams can audit
+// them. The signature is only recorded if a provenance signing secret is configured in the PluginConfig.
+const (
+	BMCSecretSourceAnnotation          = "hwmgr-plugin.oran.openshift.io/credentialSource"
+	BMCSecretRetrievedAtAnnotation     = "hwmgr-plugin.oran.openshift.io/credentialRetrievedAt"
+	BMCSecretRetrievalMethodAnnotation = "hwmgr-plugin.oran.openshift.io/credentialRetrievalMethod"
+	BMCSecretSignatureAnnotation       = "hwmgr-plugin.oran.openshift.io/credentialSignature"
+
+	// ProvenanceSigningKey is the entry of the provenance signing secret holding the HMAC-SHA256 key
+	ProvenanceSigningKey = "key"
+)
+
+// CredentialRetrievalMethod describes how an adaptor retrieved the BMC credentials of a node from its backend
+type CredentialRetrievalMethod string
+
+const (
+	// CredentialsFromConfigMap credentials are read from the loopback nodelist configmap
+	CredentialsFromConfigMap CredentialRetrievalMethod = "ConfigMap"
+	// CredentialsFromNodeAPI credentials are returned with the node details by the backend API
+	CredentialsFromNodeAPI CredentialRetrievalMethod = "NodeAPI"
+	// CredentialsFromSecretAPI credentials are fetched from the secret store of the backend API
+	CredentialsFromSecretAPI CredentialRetrievalMethod = "SecretAPI"
+)
+
+// ErrProvenanceNotSigned is returned when verifying a bmc-secret whose provenance has not been signed
+var ErrProvenanceNotSigned = errors.New("bmc-secret provenance is not signed")
+
+// BMCSecretProvenance describes how the credentials of a bmc-secret were retrieved
+type BMCSecretProvenance struct {
+	// Source identifies the backend, as the adaptor ID and HardwareManager name joined by a slash
+	Source      string
+	RetrievedAt time.Time
+	Method      CredentialRetrievalMethod
+}
+
+// NewBMCSecretProvenance returns the provenance of credentials retrieved now from the backend of the NodePool
+func NewBMCSecretProvenance(
+	adaptorID pluginv1alpha1.HardwareManagerAdaptorID,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	method CredentialRetrievalMethod) BMCSecretProvenance {
+	return BMCSecretProvenance{
+		Source:      string(adaptorID) + "/" + nodepool.Spec.HwMgrId,
+		RetrievedAt: time.Now().UTC().Truncate(time.Second),
+		Method:      method,
+	}
+}
+
+// provenancePayload returns the canonical form of the provenance that is signed. It covers the secret name, the
+// provenance, and a SHA-256 digest of each data entry in key order, so that changes to the credentials are detected.
+func provenancePayload(secret *corev1.Secret, provenance BMCSecretProvenance) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s/%s\n", secret.Namespace, secret.Name)
+	fmt.Fprintf(&b, "source=%s\n", provenance.Source)
+	fmt.Fprintf(&b, "retrievedAt=%s\n", provenance.RetrievedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "method=%s\n", provenance.Method)
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		digest := sha256.Sum256(secret.Data[key])
+		fmt.Fprintf(&b, "%s=%s\n", key, hex.EncodeToString(digest[:]))
+	}
+	return []byte(b.String())
+}
+
+// SignBMCSecretProvenance returns the signature of the provenance of a bmc-secret, an HMAC-SHA256 of its canonical
+// payload
+func SignBMCSecretProvenance(key []byte, secret *corev1.Secret, provenance BMCSecretProvenance) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(provenancePayload(secret, provenance))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetBMCSecretProvenance records the provenance on the bmc-secret, signed if a key is given. As the signature covers
+// the secret data, this must be called once the data is set. The secret is not updated on the cluster.
+func SetBMCSecretProvenance(secret *corev1.Secret, provenance BMCSecretProvenance, key []byte) {
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[BMCSecretSourceAnnotation] = provenance.Source
+	annotations[BMCSecretRetrievedAtAnnotation] = provenance.RetrievedAt.UTC().Format(time.RFC3339)
+	annotations[BMCSecretRetrievalMethodAnnotation] = string(provenance.Method)
+	if len(key) > 0 {
+		annotations[BMCSecretSignatureAnnotation] = SignBMCSecretProvenance(key, secret, provenance)
+	} else {
+		delete(annotations, BMCSecretSignatureAnnotation)
+	}
+	secret.SetAnnotations(annotations)
+}
+
+// GetBMCSecretProvenance returns the provenance recorded on the bmc-secret, or nil if none is recorded
+func GetBMCSecretProvenance(secret *corev1.Secret) (*BMCSecretProvenance, error) {
+	annotations := secret.GetAnnotations()
+	source, exists := annotations[BMCSecretSourceAnnotation]
+	if !exists {
+		return nil, nil
+	}
+
+	retrievedAt, err := time.Parse(time.RFC3339, annotations[BMCSecretRetrievedAtAnnotation])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", BMCSecretRetrievedAtAnnotation, err)
+	}
+
+	return &BMCSecretProvenance{
+		Source:      source,
+		RetrievedAt: retrievedAt,
+		Method:      CredentialRetrievalMethod(annotations[BMCSecretRetrievalMethodAnnotation]),
+	}, nil
+}
+
+// VerifyBMCSecretProvenance checks the signature of the provenance recorded on the bmc-secret against the signing
+// key, returning the verified provenance. An error is returned if the provenance is missing or unsigned, or if the
+// provenance or credentials have been modified since it was signed.
+func VerifyBMCSecretProvenance(secret *corev1.Secret, key []byte) (*BMCSecretProvenance, error) {
+	provenance, err := GetBMCSecretProvenance(secret)
+	if err != nil {
+		return nil, err
+	}
+	if provenance == nil {
+		return nil, fmt.Errorf("no provenance recorded on bmc-secret %s", secret.Name)
+	}
+
+	signature, exists := secret.GetAnnotations()[BMCSecretSignatureAnnotation]
+	if !exists {
+		return nil, ErrProvenanceNotSigned
+	}
+
+	expected := SignBMCSecretProvenance(key, secret, *provenance)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, fmt.Errorf("provenance signature mismatch for bmc-secret %s", secret.Name)
+	}
+
+	return provenance, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("BMC secret provenance", func() {
+	key := []byte("provenance-key")
+
+	var (
+		secret     *corev1.Secret
+		provenance BMCSecretProvenance
+	)
+
+	BeforeEach(func() {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "master-0-bmc-secret", Namespace: "oran-hwmgr-plugin"},
+			Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("secret")},
+		}
+		nodepool := &hwmgmtv1alpha1.NodePool{}
+		nodepool.Spec.HwMgrId = "dell-1"
+		provenance = NewBMCSecretProvenance(pluginv1alpha1.SupportedAdaptors.Dell, nodepool, CredentialsFromSecretAPI)
+	})
+
+	It("records the provenance of the credentials", func() {
+		Expect(provenance.Source).To(Equal("dell-hwmgr/dell-1"))
+		Expect(provenance.RetrievedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+
+		SetBMCSecretProvenance(secret, provenance, nil)
+		Expect(secret.Annotations).To(HaveKeyWithValue(BMCSecretRetrievalMethodAnnotation, "SecretAPI"))
+		Expect(secret.Annotations).ToNot(HaveKey(BMCSecretSignatureAnnotation))
+		Expect(GetBMCSecretProvenance(secret)).To(Equal(&provenance))
+
+		_, err := VerifyBMCSecretProvenance(secret, key)
+		Expect(err).To(MatchError(ErrProvenanceNotSigned))
+	})
+
+	It("verifies a signed provenance", func() {
+		SetBMCSecretProvenance(secret, provenance, key)
+		Expect(secret.Annotations).To(HaveKey(BMCSecretSignatureAnnotation))
+		Expect(VerifyBMCSecretProvenance(secret, key)).To(Equal(&provenance))
+
+		_, err := VerifyBMCSecretProvenance(secret, []byte("other-key"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("detects modified credentials and provenance", func() {
+		SetBMCSecretProvenance(secret, provenance, key)
+
+		tampered := secret.DeepCopy()
+		tampered.Data["password"] = []byte("changed")
+		_, err := VerifyBMCSecretProvenance(tampered, key)
+		Expect(err).To(HaveOccurred())
+
+		tampered = secret.DeepCopy()
+		tampered.Annotations[BMCSecretSourceAnnotation] = "rest/other"
+		_, err = VerifyBMCSecretProvenance(tampered, key)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reports a secret without provenance", func() {
+		Expect(GetBMCSecretProvenance(secret)).To(BeNil())
+		_, err := VerifyBMCSecretProvenance(secret, key)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// TimedOutNodesAnnotation records, as a JSON list, the backend IDs of the nodes of a NodePool that were released
+	// after timing out, which count against the retry budget and are excluded from reallocation
+	TimedOutNodesAnnotation = "hwmgr-plugin.oran.openshift.io/timedOutNodes"
+
+	DefaultNodeProvisioningTimeout = 1 * time.Hour
+)
+
+// ReasonTimeout is the Provisioned condition reason for a node that was not provisioned by the backend in time
+const ReasonTimeout hwmgmtv1alpha1.ConditionReason = "Timeout"
+
+// GetNodeProvisioningTimeout returns the node provisioning timeout for a hardware manager, and whether it is enabled
+func GetNodeProvisioningTimeout(hwmgr *pluginv1alpha1.HardwareManager) (time.Duration, bool) {
+	if hwmgr.Spec.NodeProvisioning == nil {
+		return 0, false
+	}
+
+	if hwmgr.Spec.NodeProvisioning.Timeout != nil {
+		return hwmgr.Spec.NodeProvisioning.Timeout.Duration, true
+	}
+
+	return DefaultNodeProvisioningTimeout, true
+}
+
+// IsNodeProvisioningTimedOut returns true if the timeout is enabled and has elapsed since the node was allocated,
+// based on the creation time of its Node CR
+func IsNodeProvisioningTimedOut(hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node) bool {
+	timeout, enabled := GetNodeProvisioningTimeout(hwmgr)
+	if !enabled {
+		return false
+	}
+
+	return time.Since(node.CreationTimestamp.Time) >= timeout
+}
+
+// SetNodeProvisioningTimedOut sets the Provisioned condition of the node to False with reason Timeout. The status is
+// not updated on the cluster.
+func SetNodeProvisioningTimedOut(hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node) {
+	timeout, _ := GetNodeProvisioningTimeout(hwmgr)
+	SetStatusCondition(&node.Status.Conditions,
+		string(hwmgmtv1alpha1.Provisioned),
+		string(ReasonTimeout),
+		metav1.ConditionFalse,
+		fmt.Sprintf("Node was not provisioned by the backend within %s", timeout))
+}
+
+// GetNodePoolTimedOutNodes returns the backend IDs of the nodes of the NodePool released after timing out
+func GetNodePoolTimedOutNodes(nodepool *hwmgmtv1alpha1.NodePool) []string {
+	data, exists := nodepool.GetAnnotations()[TimedOutNodesAnnotation]
+	if !exists {
+		return nil
+	}
+
+	var nodeIds []string
+	if err := json.Unmarshal([]byte(data), &nodeIds); err != nil {
+		return nil
+	}
+	return nodeIds
+}
+
+// AddNodePoolTimedOutNode records a node released after timing out in the NodePool annotations. The NodePool is not
+// updated on the cluster.
+func AddNodePoolTimedOutNode(nodepool *hwmgmtv1alpha1.NodePool, nodeId string) error {
+	nodeIds := GetNodePoolTimedOutNodes(nodepool)
+	if slices.Contains(nodeIds, nodeId) {
+		return nil
+	}
+
+	data, err := json.Marshal(append(nodeIds, nodeId))
+	if err != nil {
+		return fmt.Errorf("failed to marshal timed out nodes: %w", err)
+	}
+
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[TimedOutNodesAnnotation] = string(data)
+	nodepool.SetAnnotations(annotations)
+	return nil
+}
+
+// CanRetryNodeProvisioning returns true if the retry budget for timed-out nodes of the NodePool is not exhausted
+func CanRetryNodeProvisioning(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) bool {
+	if hwmgr.Spec.NodeProvisioning == nil {
+		return false
+	}
+
+	return len(GetNodePoolTimedOutNodes(nodepool)) < hwmgr.Spec.NodeProvisioning.MaxRetries
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node provisioning timeout", func() {
+	It("only times out nodes when enabled and the timeout has elapsed", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		node := &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+		}
+		Expect(IsNodeProvisioningTimedOut(hwmgr, node)).To(BeFalse())
+
+		hwmgr.Spec.NodeProvisioning = &pluginv1alpha1.NodeProvisioningConfig{}
+		Expect(IsNodeProvisioningTimedOut(hwmgr, node)).To(BeTrue())
+
+		hwmgr.Spec.NodeProvisioning.Timeout = &metav1.Duration{Duration: 3 * time.Hour}
+		Expect(IsNodeProvisioningTimedOut(hwmgr, node)).To(BeFalse())
+	})
+
+	It("sets the Provisioned condition with reason Timeout", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{NodeProvisioning: &pluginv1alpha1.NodeProvisioningConfig{}},
+		}
+		node := &hwmgmtv1alpha1.Node{}
+		SetNodeProvisioningTimedOut(hwmgr, node)
+
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ReasonTimeout)))
+	})
+
+	It("tracks the retry budget with the timed out nodes", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				NodeProvisioning: &pluginv1alpha1.NodeProvisioningConfig{MaxRetries: 2},
+			},
+		}
+		nodepool := newTestNodePool(nil)
+		Expect(CanRetryNodeProvisioning(hwmgr, nodepool)).To(BeTrue())
+
+		Expect(AddNodePoolTimedOutNode(nodepool, "node-1")).To(Succeed())
+		Expect(AddNodePoolTimedOutNode(nodepool, "node-1")).To(Succeed())
+		Expect(GetNodePoolTimedOutNodes(nodepool)).To(Equal([]string{"node-1"}))
+		Expect(CanRetryNodeProvisioning(hwmgr, nodepool)).To(BeTrue())
+
+		Expect(AddNodePoolTimedOutNode(nodepool, "node-2")).To(Succeed())
+		Expect(CanRetryNodeProvisioning(hwmgr, nodepool)).To(BeFalse())
+	})
+})
@@ -0,0 +1,304 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// ProvisioningWindowsKey is the NodePool extensions key that holds the provisioning windows, overriding those of
+	// the hardware manager for the NodePool
+	ProvisioningWindowsKey = "provisioningWindows"
+
+	// MaxProvisioningWindowDuration is the longest supported provisioning window
+	MaxProvisioningWindowDuration = 7 * 24 * time.Hour
+
+	// cronSearchLimit bounds the search for the next start time of a schedule, so that schedules that never match,
+	// such as the 31st of February, are not searched indefinitely
+	cronSearchLimit = 5 * 366 * 24 * time.Hour
+)
+
+// WaitingForWindow condition type and reasons, set on a NodePool whose allocation or release is deferred until the
+// next provisioning window
+const (
+	NodePoolWaitingForWindow hwmgmtv1alpha1.ConditionType   = "WaitingForWindow"
+	ReasonOutsideWindow      hwmgmtv1alpha1.ConditionReason = "OutsideWindow"
+	ReasonWithinWindow       hwmgmtv1alpha1.ConditionReason = "WithinWindow"
+)
+
+// cronField is the set of values matched by a field of a cron expression
+type cronField uint64
+
+func (f cronField) has(value int) bool {
+	return f&(1<<uint(value)) != 0
+}
+
+// cronSchedule is a parsed cron expression of five fields
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+
+	// domAny and dowAny record whether the day fields are unrestricted. When both are restricted, a day matches if
+	// either field matches, as in cron.
+	domAny, dowAny bool
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps, such as "*/15" or "1-5,10"
+func parseCronField(spec string, low, high int) (cronField, error) {
+	var field cronField
+	for _, part := range strings.Split(spec, ",") {
+		rangeSpec, stepSpec, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepSpec); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepSpec)
+			}
+		}
+
+		start, end := low, high
+		if rangeSpec != "*" {
+			startSpec, endSpec, isRange := strings.Cut(rangeSpec, "-")
+			var err error
+			if start, err = strconv.Atoi(startSpec); err != nil {
+				return 0, fmt.Errorf("invalid value %q", startSpec)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endSpec); err != nil {
+					return 0, fmt.Errorf("invalid value %q", endSpec)
+				}
+			} else if hasStep {
+				// A single value with a step, such as "5/15", runs to the end of the range
+				end = high
+			}
+		}
+
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("value %q out of range %d-%d", rangeSpec, low, high)
+		}
+		for value := start; value <= end; value += step {
+			field |= 1 << uint(value)
+		}
+	}
+
+	return field, nil
+}
+
+// parseCronSchedule parses a cron expression of five fields: minute, hour, day of month, month and day of week. Day
+// of week 7 is equivalent to 0, Sunday. Names of months and weekdays are not supported.
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, found %d", len(fields))
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	names := [5]string{"minute", "hour", "day of month", "month", "day of week"}
+	var parsed [5]cronField
+	for i := range fields {
+		field, err := parseCronField(fields[i], bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field: %w", names[i], err)
+		}
+		parsed[i] = field
+	}
+
+	// Sunday may be given as either 0 or 7
+	if parsed[4].has(7) {
+		parsed[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute: parsed[0],
+		hour:   parsed[1],
+		dom:    parsed[2],
+		month:  parsed[3],
+		dow:    parsed[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+// matchesDay returns true if the schedule runs on the day of the given time
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom.has(t.Day())
+	dow := s.dow.has(int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// next returns the first start time of the schedule after the given time, or false if there is none within the
+// search limit
+func (s *cronSchedule) next(after time.Time) (time.Time, bool) {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+	for t.Before(limit) {
+		switch {
+		case !s.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.minute.has(t.Minute()):
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// ValidateProvisioningWindows validates the schedule, duration and time zone of each provisioning window
+func ValidateProvisioningWindows(windows []pluginv1alpha1.ProvisioningWindow) error {
+	for i, window := range windows {
+		if _, err := parseCronSchedule(window.Schedule); err != nil {
+			return NewInputError("invalid schedule %q for provisioning window %d: %s", window.Schedule, i, err.Error())
+		}
+		if window.Duration.Duration <= 0 || window.Duration.Duration > MaxProvisioningWindowDuration {
+			return NewInputError("invalid duration %s for provisioning window %d: must be positive and at most %s",
+				window.Duration.Duration, i, MaxProvisioningWindowDuration)
+		}
+		if _, err := time.LoadLocation(window.TimeZone); err != nil {
+			return NewInputError("invalid time zone %q for provisioning window %d: %s", window.TimeZone, i, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// GetNodePoolProvisioningWindows parses the provisioning windows from the NodePool extensions, returning nil if none
+// are specified
+func GetNodePoolProvisioningWindows(nodepool *hwmgmtv1alpha1.NodePool) ([]pluginv1alpha1.ProvisioningWindow, error) {
+	data, exists := nodepool.Spec.Extensions[ProvisioningWindowsKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var windows []pluginv1alpha1.ProvisioningWindow
+	if err := yaml.Unmarshal([]byte(data), &windows); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", ProvisioningWindowsKey, err.Error())
+	}
+
+	return windows, nil
+}
+
+// ValidateNodePoolProvisioningWindows validates the provisioning windows of the NodePool
+func ValidateNodePoolProvisioningWindows(nodepool *hwmgmtv1alpha1.NodePool) error {
+	windows, err := GetNodePoolProvisioningWindows(nodepool)
+	if err != nil {
+		return err
+	}
+
+	return ValidateProvisioningWindows(windows)
+}
+
+// GetProvisioningWindows returns the provisioning windows that apply to the NodePool, with the windows of the NodePool
+// taking precedence over those of the hardware manager
+func GetProvisioningWindows(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) ([]pluginv1alpha1.ProvisioningWindow, error) {
+	windows, err := GetNodePoolProvisioningWindows(nodepool)
+	if err != nil {
+		return nil, err
+	}
+	if windows != nil {
+		return windows, nil
+	}
+
+	return hwmgr.Spec.ProvisioningWindows, nil
+}
+
+// IsWithinProvisioningWindow returns true if hardware changes are permitted at the given time. Otherwise, the start of
+// the next provisioning window is returned, which is zero if none of the windows start again. Hardware changes are
+// always permitted if there are no windows.
+func IsWithinProvisioningWindow(windows []pluginv1alpha1.ProvisioningWindow, now time.Time) (bool, time.Time, error) {
+	if len(windows) == 0 {
+		return true, time.Time{}, nil
+	}
+
+	var next time.Time
+	for i, window := range windows {
+		schedule, err := parseCronSchedule(window.Schedule)
+		if err != nil {
+			return false, time.Time{}, NewInputError("invalid schedule %q for provisioning window %d: %s",
+				window.Schedule, i, err.Error())
+		}
+		location, err := time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return false, time.Time{}, NewInputError("invalid time zone %q for provisioning window %d: %s",
+				window.TimeZone, i, err.Error())
+		}
+
+		local := now.In(location)
+
+		// The window is open if it started within its duration before now
+		if start, found := schedule.next(local.Add(-window.Duration.Duration)); found && !start.After(local) {
+			return true, time.Time{}, nil
+		}
+
+		if start, found := schedule.next(local); found && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+
+	return false, next, nil
+}
+
+// UpdateNodePoolWindowCondition sets the WaitingForWindow condition of the NodePool. The condition is only cleared if
+// it was previously set, and the status is only updated if the condition has changed.
+func UpdateNodePoolWindowCondition(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, open bool, next time.Time) error {
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolWaitingForWindow))
+	if open {
+		if current == nil || current.Status == metav1.ConditionFalse {
+			return nil
+		}
+		return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolWaitingForWindow, ReasonWithinWindow,
+			metav1.ConditionFalse, "Provisioning window is open")
+	}
+
+	message := FormatWaitingForWindow(next)
+	if current != nil && current.Status == metav1.ConditionTrue && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolWaitingForWindow, ReasonOutsideWindow,
+		metav1.ConditionTrue, message)
+}
+
+// FormatWaitingForWindow returns the message reported for a NodePool waiting for the provisioning window that starts
+// at the given time
+func FormatWaitingForWindow(next time.Time) string {
+	if next.IsZero() {
+		return "Waiting for a provisioning window: no further windows are scheduled"
+	}
+	return "Waiting for the provisioning window starting at " + next.UTC().Format(time.RFC3339)
+}
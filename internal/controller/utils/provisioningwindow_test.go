@@ -0,0 +1,147 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+var _ = Describe("Provisioning windows", func() {
+	window := func(schedule string, duration time.Duration, tz string) pluginv1alpha1.ProvisioningWindow {
+		return pluginv1alpha1.ProvisioningWindow{Schedule: schedule, Duration: metav1.Duration{Duration: duration}, TimeZone: tz}
+	}
+	at := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		Expect(err).ToNot(HaveOccurred())
+		return t
+	}
+
+	It("parses cron schedules", func() {
+		schedule, err := parseCronSchedule("*/15 1-3,22 * * 7")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.minute.has(45)).To(BeTrue())
+		Expect(schedule.minute.has(10)).To(BeFalse())
+		Expect(schedule.hour.has(2)).To(BeTrue())
+		Expect(schedule.hour.has(22)).To(BeTrue())
+		Expect(schedule.hour.has(4)).To(BeFalse())
+		Expect(schedule.dow.has(0)).To(BeTrue())
+
+		for _, spec := range []string{"* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+			_, err := parseCronSchedule(spec)
+			Expect(err).To(HaveOccurred(), spec)
+		}
+	})
+
+	It("finds the next start time", func() {
+		schedule, err := parseCronSchedule("0 2 * * 6")
+		Expect(err).ToNot(HaveOccurred())
+		// 2026-10-14 is a Wednesday
+		next, found := schedule.next(at("2026-10-14T10:00:00Z"))
+		Expect(found).To(BeTrue())
+		Expect(next).To(Equal(at("2026-10-17T02:00:00Z")))
+
+		// A start time is not returned for itself
+		next, found = schedule.next(at("2026-10-17T02:00:00Z"))
+		Expect(found).To(BeTrue())
+		Expect(next).To(Equal(at("2026-10-24T02:00:00Z")))
+
+		// Restricted days match on either the day of month or day of week
+		schedule, err = parseCronSchedule("0 0 1 * 5")
+		Expect(err).ToNot(HaveOccurred())
+		next, _ = schedule.next(at("2026-10-14T10:00:00Z"))
+		Expect(next).To(Equal(at("2026-10-16T00:00:00Z")))
+
+		schedule, err = parseCronSchedule("0 0 31 2 *")
+		Expect(err).ToNot(HaveOccurred())
+		_, found = schedule.next(at("2026-10-14T10:00:00Z"))
+		Expect(found).To(BeFalse())
+	})
+
+	It("checks whether a provisioning window is open", func() {
+		windows := []pluginv1alpha1.ProvisioningWindow{window("0 22 * * 1-5", 4*time.Hour, "")}
+
+		open, _, err := IsWithinProvisioningWindow(windows, at("2026-10-14T23:30:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+
+		// The window that opened on Wednesday evening runs into Thursday
+		open, _, err = IsWithinProvisioningWindow(windows, at("2026-10-15T01:59:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+
+		open, next, err := IsWithinProvisioningWindow(windows, at("2026-10-15T02:00:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(next).To(Equal(at("2026-10-15T22:00:00Z")))
+
+		open, _, err = IsWithinProvisioningWindow(nil, at("2026-10-15T02:00:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+	})
+
+	It("evaluates windows in their time zone", func() {
+		windows := []pluginv1alpha1.ProvisioningWindow{
+			window("0 1 * * *", time.Hour, "America/New_York"),
+			window("0 12 * * *", time.Hour, ""),
+		}
+
+		open, next, err := IsWithinProvisioningWindow(windows, at("2026-10-14T01:30:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(next.Equal(at("2026-10-14T05:00:00Z"))).To(BeTrue())
+
+		open, _, err = IsWithinProvisioningWindow(windows, at("2026-10-14T05:30:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(BeTrue())
+	})
+
+	It("validates provisioning windows", func() {
+		Expect(ValidateProvisioningWindows([]pluginv1alpha1.ProvisioningWindow{window("0 2 * * *", time.Hour, "UTC")})).To(Succeed())
+		Expect(ValidateProvisioningWindows([]pluginv1alpha1.ProvisioningWindow{window("0 2 * *", time.Hour, "")})).ToNot(Succeed())
+		Expect(ValidateProvisioningWindows([]pluginv1alpha1.ProvisioningWindow{window("0 2 * * *", 0, "")})).ToNot(Succeed())
+		Expect(ValidateProvisioningWindows([]pluginv1alpha1.ProvisioningWindow{window("0 2 * * *", 8*24*time.Hour, "")})).ToNot(Succeed())
+		Expect(ValidateProvisioningWindows([]pluginv1alpha1.ProvisioningWindow{window("0 2 * * *", time.Hour, "Mars/Olympus")})).ToNot(Succeed())
+	})
+
+	It("prefers the provisioning windows of the NodePool", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{Spec: pluginv1alpha1.HardwareManagerSpec{
+			ProvisioningWindows: []pluginv1alpha1.ProvisioningWindow{window("0 2 * * *", time.Hour, "")},
+		}}
+
+		windows, err := GetProvisioningWindows(hwmgr, newTestNodePool(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(windows).To(Equal(hwmgr.Spec.ProvisioningWindows))
+
+		nodepool := newTestNodePool(map[string]string{ProvisioningWindowsKey: `
+- schedule: "30 4 * * 0"
+  duration: 2h
+  timeZone: Europe/Paris
+`})
+		Expect(ValidateNodePoolProvisioningWindows(nodepool)).To(Succeed())
+		windows, err = GetProvisioningWindows(hwmgr, nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(windows).To(Equal([]pluginv1alpha1.ProvisioningWindow{window("30 4 * * 0", 2*time.Hour, "Europe/Paris")}))
+
+		Expect(ValidateNodePoolProvisioningWindows(newTestNodePool(map[string]string{ProvisioningWindowsKey: "bad: ["}))).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,169 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrReadOnly is returned for changes blocked while the plugin is in read-only mode
+var ErrReadOnly = errors.New("the plugin is in read-only mode")
+
+var readOnly atomic.Bool
+
+// API groups of the objects, such as SubjectAccessReviews, that are created to query the API server and are permitted
+// in read-only mode
+var readOnlyExemptGroups = []string{
+	authenticationv1.GroupName,
+	authorizationv1.GroupName,
+}
+
+// SetReadOnly enables or disables read-only mode, in which the plugin reconciles, validates and reports status, but
+// makes no changes to CRs other than their status, and no mutating requests to backends
+func SetReadOnly(enabled bool) {
+	readOnly.Store(enabled)
+}
+
+// IsReadOnly returns true if the plugin is in read-only mode
+func IsReadOnly() bool {
+	return readOnly.Load()
+}
+
+type readOnlyExemptionKey struct{}
+
+// WithReadOnlyExemption returns a context in which backend requests are permitted in read-only mode, for requests
+// that do not change the backend regardless of their method, such as token and session requests or searches
+func WithReadOnlyExemption(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyExemptionKey{}, true)
+}
+
+// IsReadOnlyExempt returns true if backend requests made with the context are permitted in read-only mode
+func IsReadOnlyExempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(readOnlyExemptionKey{}).(bool)
+	return exempt
+}
+
+// ReadOnlyClient is a client that blocks changes to objects, other than to their status, while the plugin is in
+// read-only mode
+type ReadOnlyClient struct {
+	client.Client
+}
+
+// ReadOnlyClient implements client.Client. This ensures that we've conformed to the interface with a compile-time check
+var _ client.Client = (*ReadOnlyClient)(nil)
+
+// NewReadOnlyClient returns a ReadOnlyClient that wraps the base client
+func NewReadOnlyClient(base client.Client) *ReadOnlyClient {
+	return &ReadOnlyClient{Client: base}
+}
+
+// blocked returns the error for a change to the object, or nil if the plugin is not in read-only mode
+func (c *ReadOnlyClient) blocked(verb string, obj client.Object) error {
+	if !IsReadOnly() {
+		return nil
+	}
+
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+		if slices.Contains(readOnlyExemptGroups, gvk.Group) {
+			// Reviews are created to query the API server, and are not persisted
+			return nil
+		}
+		kind = gvk.Kind
+	}
+	return fmt.Errorf("%w: refusing to %s %s %s", ErrReadOnly, verb, kind, client.ObjectKeyFromObject(obj))
+}
+
+func (c *ReadOnlyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.blocked("create", obj); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (c *ReadOnlyClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.blocked("delete", obj); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (c *ReadOnlyClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.blocked("update", obj); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (c *ReadOnlyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.blocked("patch", obj); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...) // nolint: wrapcheck
+}
+
+func (c *ReadOnlyClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if err := c.blocked("delete all of", obj); err != nil {
+		return err
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+// SubResource returns the client for the subresource. Changes to the status subresource are always permitted, while
+// those to other subresources are blocked in read-only mode.
+func (c *ReadOnlyClient) SubResource(subResource string) client.SubResourceClient {
+	if subResource == "status" {
+		return c.Client.SubResource(subResource)
+	}
+	return &readOnlySubResourceClient{SubResourceClient: c.Client.SubResource(subResource), parent: c, name: subResource}
+}
+
+// readOnlySubResourceClient blocks changes to a subresource other than status in read-only mode
+type readOnlySubResourceClient struct {
+	client.SubResourceClient
+	parent *ReadOnlyClient
+	name   string
+}
+
+func (c *readOnlySubResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	if err := c.parent.blocked("create "+c.name+" of", obj); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Create(ctx, obj, subResource, opts...) // nolint: wrapcheck
+}
+
+func (c *readOnlySubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if err := c.parent.blocked("update "+c.name+" of", obj); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Update(ctx, obj, opts...) // nolint: wrapcheck
+}
+
+func (c *readOnlySubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if err := c.parent.blocked("patch "+c.name+" of", obj); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Patch(ctx, obj, patch, opts...) // nolint: wrapcheck
+}
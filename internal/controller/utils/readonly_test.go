@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// recordingClient records the changes made through it, without an API server
+type recordingClient struct {
+	client.Client
+	changes []string
+}
+
+func (c *recordingClient) Create(_ context.Context, _ client.Object, _ ...client.CreateOption) error {
+	c.changes = append(c.changes, "create")
+	return nil
+}
+
+func (c *recordingClient) Update(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+	c.changes = append(c.changes, "update")
+	return nil
+}
+
+func (c *recordingClient) Delete(_ context.Context, _ client.Object, _ ...client.DeleteOption) error {
+	c.changes = append(c.changes, "delete")
+	return nil
+}
+
+func (c *recordingClient) Status() client.SubResourceWriter {
+	return &recordingStatusWriter{parent: c}
+}
+
+func (c *recordingClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return apiutil.GVKForObject(obj, clientgoscheme.Scheme) // nolint: wrapcheck
+}
+
+type recordingStatusWriter struct {
+	client.SubResourceWriter
+	parent *recordingClient
+}
+
+func (w *recordingStatusWriter) Update(_ context.Context, _ client.Object, _ ...client.SubResourceUpdateOption) error {
+	w.parent.changes = append(w.parent.changes, "status update")
+	return nil
+}
+
+var _ = Describe("Read-only mode", func() {
+	AfterEach(func() {
+		SetReadOnly(false)
+	})
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bmc-secret", Namespace: "test"}}
+
+	It("permits changes when not in read-only mode", func() {
+		base := &recordingClient{}
+		c := NewReadOnlyClient(base)
+		Expect(c.Create(context.Background(), secret)).To(Succeed())
+		Expect(c.Update(context.Background(), secret)).To(Succeed())
+		Expect(c.Delete(context.Background(), secret)).To(Succeed())
+		Expect(base.changes).To(Equal([]string{"create", "update", "delete"}))
+	})
+
+	It("blocks changes other than to the status in read-only mode", func() {
+		SetReadOnly(true)
+		base := &recordingClient{}
+		c := NewReadOnlyClient(base)
+
+		err := c.Create(context.Background(), secret)
+		Expect(err).To(MatchError(ErrReadOnly))
+		Expect(err).To(MatchError(ContainSubstring("create Secret test/bmc-secret")))
+		Expect(c.Update(context.Background(), secret)).To(MatchError(ErrReadOnly))
+		Expect(c.Delete(context.Background(), secret)).To(MatchError(ErrReadOnly))
+		Expect(base.changes).To(BeEmpty())
+
+		Expect(c.Status().Update(context.Background(), secret)).To(Succeed())
+		Expect(base.changes).To(Equal([]string{"status update"}))
+	})
+
+	It("permits access reviews in read-only mode", func() {
+		SetReadOnly(true)
+		base := &recordingClient{}
+		c := NewReadOnlyClient(base)
+		Expect(c.Create(context.Background(), &authorizationv1.SubjectAccessReview{})).To(Succeed())
+		Expect(base.changes).To(Equal([]string{"create"}))
+	})
+
+	It("exempts backend requests by context", func() {
+		Expect(IsReadOnlyExempt(context.Background())).To(BeFalse())
+		Expect(IsReadOnlyExempt(WithReadOnlyExemption(context.Background()))).To(BeTrue())
+	})
+})
@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// LastNodeResyncAnnotation records, on a NodePool, the time its nodes were last resynced from the backend
+	LastNodeResyncAnnotation = "hwmgr-plugin.oran.openshift.io/lastNodeResync"
+
+	DefaultNodeResyncInterval = 1 * time.Hour
+)
+
+// HardwareChanged condition type and reason, set on a Node when a resync detects a change to its hardware details.
+// The condition transition time is updated on each detected change.
+const (
+	NodeHardwareChanged   hwmgmtv1alpha1.ConditionType   = "HardwareChanged"
+	ReasonHardwareChanged hwmgmtv1alpha1.ConditionReason = "Changed"
+)
+
+// GetNodeResyncInterval returns the node resync interval for a hardware manager, and whether resync is enabled
+func GetNodeResyncInterval(hwmgr *pluginv1alpha1.HardwareManager) (time.Duration, bool) {
+	if hwmgr.Spec.NodeResync == nil {
+		return 0, false
+	}
+
+	if hwmgr.Spec.NodeResync.Interval != nil {
+		return hwmgr.Spec.NodeResync.Interval.Duration, true
+	}
+
+	return DefaultNodeResyncInterval, true
+}
+
+// IsNodeResyncDue returns true if node resync is enabled and the interval has elapsed since the last resync
+func IsNodeResyncDue(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) bool {
+	interval, enabled := GetNodeResyncInterval(hwmgr)
+	if !enabled {
+		return false
+	}
+
+	lastResync, err := time.Parse(time.RFC3339, nodepool.GetAnnotations()[LastNodeResyncAnnotation])
+	if err != nil {
+		// Never resynced, or the annotation is invalid
+		return true
+	}
+
+	return time.Since(lastResync) >= interval
+}
+
+// SetNodeResyncTime records the time of the last node resync on the NodePool
+func SetNodeResyncTime(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool) error {
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[LastNodeResyncAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	nodepool.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, nodepool, patch); err != nil {
+		return fmt.Errorf("failed to annotate NodePool %s with resync time: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// ApplyNodeHardwareResync updates the interfaces and BMC address in the Node status with the latest data from the
+// backend, returning true if either has changed. On a change, the HardwareChanged condition is bumped so that
+// downstream consumers can react. The status is not updated on the cluster.
+func ApplyNodeHardwareResync(node *hwmgmtv1alpha1.Node, interfaces []*hwmgmtv1alpha1.Interface, bmcAddress string) bool {
+	var changes []string
+
+	if !reflect.DeepEqual(node.Status.Interfaces, interfaces) {
+		changes = append(changes, "interfaces updated")
+		node.Status.Interfaces = interfaces
+	}
+
+	if node.Status.BMC == nil {
+		node.Status.BMC = &hwmgmtv1alpha1.BMC{CredentialsName: BMCSecretName(node.Name)}
+	}
+	if node.Status.BMC.Address != bmcAddress {
+		changes = append(changes, fmt.Sprintf("BMC address changed from %q to %q", node.Status.BMC.Address, bmcAddress))
+		node.Status.BMC.Address = bmcAddress
+	}
+
+	if len(changes) == 0 {
+		return false
+	}
+
+	// Remove the existing condition so the transition time reflects the latest change
+	meta.RemoveStatusCondition(&node.Status.Conditions, string(NodeHardwareChanged))
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeHardwareChanged),
+		string(ReasonHardwareChanged),
+		metav1.ConditionTrue,
+		"Hardware resync detected changes: "+strings.Join(changes, "; "))
+
+	return true
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Node hardware resync", func() {
+	It("is only due when enabled and the interval has elapsed", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		nodepool := newTestNodePool(nil)
+		Expect(IsNodeResyncDue(hwmgr, nodepool)).To(BeFalse())
+
+		hwmgr.Spec.NodeResync = &pluginv1alpha1.NodeResyncConfig{Interval: &metav1.Duration{Duration: time.Hour}}
+		Expect(IsNodeResyncDue(hwmgr, nodepool)).To(BeTrue())
+
+		nodepool.SetAnnotations(map[string]string{
+			LastNodeResyncAnnotation: time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339),
+		})
+		Expect(IsNodeResyncDue(hwmgr, nodepool)).To(BeFalse())
+
+		nodepool.SetAnnotations(map[string]string{
+			LastNodeResyncAnnotation: time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+		})
+		Expect(IsNodeResyncDue(hwmgr, nodepool)).To(BeTrue())
+	})
+
+	It("detects interface and BMC address changes", func() {
+		node := &hwmgmtv1alpha1.Node{
+			Status: hwmgmtv1alpha1.NodeStatus{
+				BMC:        &hwmgmtv1alpha1.BMC{Address: "idrac-virtualmedia+https://192.168.1.1"},
+				Interfaces: []*hwmgmtv1alpha1.Interface{{Name: "eno1", MACAddress: "00:00:00:01:20:30"}},
+			},
+		}
+
+		Expect(ApplyNodeHardwareResync(node, node.Status.Interfaces, node.Status.BMC.Address)).To(BeFalse())
+		Expect(meta.FindStatusCondition(node.Status.Conditions, string(NodeHardwareChanged))).To(BeNil())
+
+		interfaces := []*hwmgmtv1alpha1.Interface{{Name: "eno1", MACAddress: "00:00:00:01:20:31"}}
+		Expect(ApplyNodeHardwareResync(node, interfaces, "idrac-virtualmedia+https://192.168.1.2")).To(BeTrue())
+		Expect(node.Status.Interfaces).To(Equal(interfaces))
+		Expect(node.Status.BMC.Address).To(Equal("idrac-virtualmedia+https://192.168.1.2"))
+
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(NodeHardwareChanged))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("interfaces updated"))
+		Expect(condition.Message).To(ContainSubstring("BMC address changed"))
+	})
+})
@@ -0,0 +1,206 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// SecurityCompliant condition type and reasons, set on a Node allocated with a hardware profile that defines security
+// requirements
+const (
+	NodeSecurityCompliant      hwmgmtv1alpha1.ConditionType   = "SecurityCompliant"
+	ReasonSecurityCompliant    hwmgmtv1alpha1.ConditionReason = "Compliant"
+	ReasonSecurityNonCompliant hwmgmtv1alpha1.ConditionReason = "NonCompliant"
+)
+
+// SecureBootState is the secure boot state of a node, as reported by the backend
+type SecureBootState string
+
+const (
+	SecureBootEnabled     SecureBootState = "Enabled"
+	SecureBootDisabled    SecureBootState = "Disabled"
+	SecureBootUnsupported SecureBootState = "Unsupported"
+)
+
+// TPMNone is the TPM version reported for a node without an enabled TPM
+const TPMNone = "None"
+
+// NodeSecurityState is the platform security state of a node, as reported by the backend. An empty field is not
+// reported.
+type NodeSecurityState struct {
+	SecureBoot SecureBootState
+	// TPMVersion is the version of the enabled TPM, such as 2.0, or TPMNone. A backend that reports an enabled TPM
+	// without its version reports a version of "Unknown"
+	TPMVersion string
+}
+
+// GetHwProfileSecurity returns the security requirements defined for a hardware profile, or nil if none are defined
+func GetHwProfileSecurity(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string) *pluginv1alpha1.SecurityRequirements {
+	profile := getHwProfile(hwmgr, hwprofile)
+	if profile == nil || profile.Security == nil {
+		return nil
+	}
+	if !profile.Security.SecureBoot && profile.Security.TPM == nil {
+		return nil
+	}
+	return profile.Security
+}
+
+// NeedsSecureBootEnabled returns true if the requirements call for secure boot, and the node supports secure boot but
+// has it disabled, so that an adaptor able to configure it should enable it
+func NeedsSecureBootEnabled(requirements *pluginv1alpha1.SecurityRequirements, state NodeSecurityState) bool {
+	return requirements != nil && requirements.SecureBoot && state.SecureBoot == SecureBootDisabled
+}
+
+// compareTPMVersion compares two TPM versions, returning false if either is not a version number
+func compareTPMVersion(version, minVersion string) (int, bool) {
+	v, err := strconv.ParseFloat(version, 64)
+	if err != nil {
+		return 0, false
+	}
+	m, err := strconv.ParseFloat(minVersion, 64)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case v < m:
+		return -1, true
+	case v > m:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// checkSecurity returns a description of each requirement that the security state does not satisfy. In strict mode,
+// a requirement fails if the state it depends on is not reported. Otherwise, only a reported state is checked.
+func checkSecurity(requirements *pluginv1alpha1.SecurityRequirements, state NodeSecurityState, strict bool) []string {
+	if requirements == nil {
+		return nil
+	}
+
+	var problems []string
+	if requirements.SecureBoot {
+		switch state.SecureBoot {
+		case SecureBootEnabled:
+		case "":
+			if strict {
+				problems = append(problems, "secure boot is required, but its state is not reported")
+			}
+		case SecureBootDisabled:
+			if strict {
+				problems = append(problems, "secure boot is required, but is disabled")
+			}
+		case SecureBootUnsupported:
+			problems = append(problems, "secure boot is required, but node does not support it")
+		default:
+			if strict {
+				problems = append(problems, fmt.Sprintf("secure boot is required, but its state is %s", state.SecureBoot))
+			}
+		}
+	}
+
+	if requirements.TPM != nil {
+		minVersion := string(requirements.TPM.MinVersion)
+		switch {
+		case state.TPMVersion == "":
+			if strict {
+				problems = append(problems, "TPM is required, but its state is not reported")
+			}
+		case state.TPMVersion == TPMNone:
+			problems = append(problems, "TPM is required, but node has no enabled TPM")
+		case minVersion != "":
+			if cmp, ok := compareTPMVersion(state.TPMVersion, minVersion); !ok {
+				if strict {
+					problems = append(problems, fmt.Sprintf("TPM %s is required, but node TPM version %s cannot be verified",
+						minVersion, state.TPMVersion))
+				}
+			} else if cmp < 0 {
+				problems = append(problems, fmt.Sprintf("TPM %s is required, but node has TPM %s", minVersion, state.TPMVersion))
+			}
+		}
+	}
+
+	return problems
+}
+
+// CheckNodeSecurity returns an error describing each security requirement that the node does not satisfy, or nil if
+// it is compliant. A requirement whose state is not reported by the backend is not satisfied, as it cannot be verified.
+func CheckNodeSecurity(requirements *pluginv1alpha1.SecurityRequirements, state NodeSecurityState) error {
+	if problems := checkSecurity(requirements, state, true); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// DescribeSecurityRequirements returns a short description of the security requirements, such as
+// "secure boot, TPM 2.0"
+func DescribeSecurityRequirements(requirements *pluginv1alpha1.SecurityRequirements) string {
+	var parts []string
+	if requirements.SecureBoot {
+		parts = append(parts, "secure boot")
+	}
+	if requirements.TPM != nil {
+		tpm := "TPM"
+		if requirements.TPM.MinVersion != "" {
+			tpm += " " + string(requirements.TPM.MinVersion)
+		}
+		parts = append(parts, tpm)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// VerifyNodeSecurity checks the security state of the node against the security requirements of its hardware
+// profile, returning true if the node is compliant or the profile has no requirements. The SecurityCompliant condition
+// is set, and a node that is not compliant has its Provisioned condition set to Failed. The status is not updated on
+// the cluster.
+func VerifyNodeSecurity(hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node, state NodeSecurityState) bool {
+	requirements := GetHwProfileSecurity(hwmgr, node.Spec.HwProfile)
+	if requirements == nil {
+		return true
+	}
+
+	if err := CheckNodeSecurity(requirements, state); err != nil {
+		SetStatusCondition(&node.Status.Conditions,
+			string(NodeSecurityCompliant),
+			string(ReasonSecurityNonCompliant),
+			metav1.ConditionFalse,
+			err.Error())
+		SetStatusCondition(&node.Status.Conditions,
+			string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.Failed),
+			metav1.ConditionFalse,
+			"Security requirements not satisfied: "+err.Error())
+		return false
+	}
+
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeSecurityCompliant),
+		string(ReasonSecurityCompliant),
+		metav1.ConditionTrue,
+		"Satisfies "+DescribeSecurityRequirements(requirements))
+	return true
+}
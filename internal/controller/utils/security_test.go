@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Security requirements", func() {
+	requirements := &pluginv1alpha1.SecurityRequirements{
+		SecureBoot: true,
+		TPM:        &pluginv1alpha1.TPMRequirement{MinVersion: pluginv1alpha1.TPMVersion20},
+	}
+	hwmgr := &pluginv1alpha1.HardwareManager{
+		Spec: pluginv1alpha1.HardwareManagerSpec{
+			HwProfiles: []pluginv1alpha1.HardwareProfile{
+				{Name: "profile-secure", Security: requirements},
+				{Name: "profile-empty", Security: &pluginv1alpha1.SecurityRequirements{}},
+			},
+		},
+	}
+
+	newNode := func(hwprofile string) *hwmgmtv1alpha1.Node {
+		node := &hwmgmtv1alpha1.Node{}
+		node.Spec.HwProfile = hwprofile
+		return node
+	}
+
+	It("returns the requirements of the profile", func() {
+		Expect(GetHwProfileSecurity(hwmgr, "profile-secure")).To(Equal(requirements))
+		Expect(GetHwProfileSecurity(hwmgr, "profile-empty")).To(BeNil())
+		Expect(GetHwProfileSecurity(hwmgr, "profile-other")).To(BeNil())
+		Expect(DescribeSecurityRequirements(requirements)).To(Equal("secure boot, TPM 2.0"))
+	})
+
+	It("checks the security state of the node", func() {
+		Expect(CheckNodeSecurity(requirements, NodeSecurityState{SecureBoot: SecureBootEnabled, TPMVersion: "2.0"})).To(Succeed())
+		Expect(CheckNodeSecurity(requirements, NodeSecurityState{SecureBoot: SecureBootDisabled, TPMVersion: "1.2"})).
+			To(MatchError("secure boot is required, but is disabled; TPM 2.0 is required, but node has TPM 1.2"))
+		Expect(CheckNodeSecurity(requirements, NodeSecurityState{SecureBoot: SecureBootUnsupported, TPMVersion: TPMNone})).
+			To(MatchError("secure boot is required, but node does not support it; TPM is required, but node has no enabled TPM"))
+	})
+
+	It("fails requirements that cannot be verified", func() {
+		Expect(CheckNodeSecurity(requirements, NodeSecurityState{})).
+			To(MatchError("secure boot is required, but its state is not reported; TPM is required, but its state is not reported"))
+		Expect(CheckNodeSecurity(requirements, NodeSecurityState{SecureBoot: SecureBootEnabled, TPMVersion: "Unknown"})).
+			To(MatchError(ContainSubstring("node TPM version Unknown cannot be verified")))
+
+		// An enabled TPM of unknown version satisfies a requirement without a minimum version
+		Expect(CheckNodeSecurity(&pluginv1alpha1.SecurityRequirements{TPM: &pluginv1alpha1.TPMRequirement{}},
+			NodeSecurityState{TPMVersion: "Unknown"})).To(Succeed())
+	})
+
+	It("enables secure boot only where supported", func() {
+		Expect(NeedsSecureBootEnabled(requirements, NodeSecurityState{SecureBoot: SecureBootDisabled})).To(BeTrue())
+		Expect(NeedsSecureBootEnabled(requirements, NodeSecurityState{SecureBoot: SecureBootUnsupported})).To(BeFalse())
+		Expect(NeedsSecureBootEnabled(nil, NodeSecurityState{SecureBoot: SecureBootDisabled})).To(BeFalse())
+	})
+
+	It("filters out free nodes that cannot satisfy the profile", func() {
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-secure", NodeCapabilities{
+			Security: NodeSecurityState{SecureBoot: SecureBootDisabled, TPMVersion: "2.0"}})).To(Succeed())
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-secure", NodeCapabilities{})).To(Succeed())
+		Expect(CheckHwProfileCompatibility(hwmgr, "profile-secure", NodeCapabilities{
+			Security: NodeSecurityState{SecureBoot: SecureBootUnsupported}})).
+			To(MatchError(ContainSubstring("node does not support it")))
+	})
+
+	It("fails the node if it is not compliant", func() {
+		node := newNode("profile-secure")
+		Expect(VerifyNodeSecurity(hwmgr, node, NodeSecurityState{SecureBoot: SecureBootEnabled})).To(BeFalse())
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(NodeSecurityCompliant))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ReasonSecurityNonCompliant)))
+		provisioned := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		Expect(provisioned).ToNot(BeNil())
+		Expect(provisioned.Reason).To(Equal(string(hwmgmtv1alpha1.Failed)))
+
+		Expect(VerifyNodeSecurity(hwmgr, node, NodeSecurityState{SecureBoot: SecureBootEnabled, TPMVersion: "2.0"})).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(node.Status.Conditions, string(NodeSecurityCompliant))).To(BeTrue())
+
+		node = newNode("profile-other")
+		Expect(VerifyNodeSecurity(hwmgr, node, NodeSecurityState{})).To(BeTrue())
+		Expect(node.Status.Conditions).To(BeEmpty())
+	})
+})
@@ -0,0 +1,39 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// SelfTestAnnotation requests, on a HardwareManager, a self-test of the connectivity to the backend. A self-test is run
+// whenever the value, such as a timestamp, differs from the trigger of the last recorded self-test.
+const SelfTestAnnotation = "hwmgr-plugin.oran.openshift.io/selfTest"
+
+// GetSelfTestTrigger returns the value of the selfTest annotation, and whether a self-test is pending for it
+func GetSelfTestTrigger(hwmgr *pluginv1alpha1.HardwareManager) (string, bool) {
+	trigger := hwmgr.GetAnnotations()[SelfTestAnnotation]
+	if trigger == "" {
+		return "", false
+	}
+
+	if hwmgr.Status.SelfTest != nil && hwmgr.Status.SelfTest.Trigger == trigger {
+		return trigger, false
+	}
+
+	return trigger, true
+}
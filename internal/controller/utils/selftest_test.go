@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("HardwareManager self-test", func() {
+	It("is pending when the trigger differs from the last self-test", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		_, pending := GetSelfTestTrigger(hwmgr)
+		Expect(pending).To(BeFalse())
+
+		hwmgr.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{SelfTestAnnotation: "run-1"}}
+		trigger, pending := GetSelfTestTrigger(hwmgr)
+		Expect(pending).To(BeTrue())
+		Expect(trigger).To(Equal("run-1"))
+
+		hwmgr.Status.SelfTest = &pluginv1alpha1.SelfTestStatus{Trigger: "run-1"}
+		_, pending = GetSelfTestTrigger(hwmgr)
+		Expect(pending).To(BeFalse())
+
+		hwmgr.Annotations[SelfTestAnnotation] = "run-2"
+		_, pending = GetSelfTestTrigger(hwmgr)
+		Expect(pending).To(BeTrue())
+	})
+})
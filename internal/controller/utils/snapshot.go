@@ -0,0 +1,258 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// SnapshotAnnotation requests, on a HardwareManager, a snapshot of its inventory and allocation state. A snapshot
+	// is captured whenever the value, such as a timestamp, differs from the trigger of the last recorded snapshot.
+	SnapshotAnnotation = "hwmgr-plugin.oran.openshift.io/snapshot"
+
+	// InventorySnapshotKey is the configmap key holding the YAML encoded InventorySnapshot
+	InventorySnapshotKey = "snapshot.yaml"
+
+	// InventorySnapshotVersion is the version of the InventorySnapshot format. Snapshots written with a newer version
+	// are rejected.
+	InventorySnapshotVersion = 1
+
+	inventorySnapshotSuffix = "-snapshot"
+)
+
+// InventorySnapshot is a portable record of the inventory and allocation state of a hardware manager, for reproducing
+// the state of a fleet in the lab. BMC credentials are not captured.
+type InventorySnapshot struct {
+	SchemaVersion int                                     `json:"schemaVersion"`
+	HwMgrId       string                                  `json:"hwMgrId"`
+	AdaptorId     pluginv1alpha1.HardwareManagerAdaptorID `json:"adaptorId"`
+	CapturedAt    metav1.Time                             `json:"capturedAt"`
+	ResourcePools pluginv1alpha1.PerSiteResourcePoolList  `json:"resourcePools,omitempty"`
+	// Nodes are the free and allocated nodes of the hardware manager, ordered by node ID
+	Nodes []SnapshotNode `json:"nodes"`
+	// NodePools are the NodePools allocated from the hardware manager, ordered by name
+	NodePools []SnapshotNodePool `json:"nodePools,omitempty"`
+}
+
+// SnapshotNode is a node of an InventorySnapshot. The allocation fields are empty for a free node.
+type SnapshotNode struct {
+	NodeId         string                      `json:"nodeId"`
+	ResourcePoolId string                      `json:"resourcePoolId,omitempty"`
+	Site           string                      `json:"site,omitempty"`
+	CPUs           int                         `json:"cpus,omitempty"`
+	MemoryGiB      int                         `json:"memoryGiB,omitempty"`
+	NICModels      []string                    `json:"nicModels,omitempty"`
+	Location       string                      `json:"location,omitempty"`
+	NodeName       string                      `json:"nodeName,omitempty"`
+	NodePool       string                      `json:"nodePool,omitempty"`
+	GroupName      string                      `json:"groupName,omitempty"`
+	HwProfile      string                      `json:"hwProfile,omitempty"`
+	BMCAddress     string                      `json:"bmcAddress,omitempty"`
+	Interfaces     []*hwmgmtv1alpha1.Interface `json:"interfaces,omitempty"`
+	Failed         bool                        `json:"failed,omitempty"`
+}
+
+// IsAllocated returns true if the node is allocated to a NodePool
+func (node *SnapshotNode) IsAllocated() bool {
+	return node.NodePool != ""
+}
+
+// SnapshotNodePool is a NodePool of an InventorySnapshot
+type SnapshotNodePool struct {
+	Name      string                      `json:"name"`
+	Namespace string                      `json:"namespace"`
+	Spec      hwmgmtv1alpha1.NodePoolSpec `json:"spec"`
+	// Provisioned is the reason of the Provisioned condition of the NodePool, if set
+	Provisioned string `json:"provisioned,omitempty"`
+}
+
+// GetSnapshotTrigger returns the value of the snapshot annotation, and whether a snapshot is pending for it
+func GetSnapshotTrigger(hwmgr *pluginv1alpha1.HardwareManager) (string, bool) {
+	trigger := hwmgr.GetAnnotations()[SnapshotAnnotation]
+	if trigger == "" {
+		return "", false
+	}
+
+	if hwmgr.Status.Snapshot != nil && hwmgr.Status.Snapshot.Trigger == trigger {
+		return trigger, false
+	}
+
+	return trigger, true
+}
+
+// InventorySnapshotName returns the name of the configmap holding the inventory snapshot of a hardware manager
+func InventorySnapshotName(hwmgrName string) string {
+	return hwmgrName + inventorySnapshotSuffix
+}
+
+// BuildInventorySnapshot builds the inventory snapshot of a hardware manager from its NodePools and Nodes, which are
+// filtered by HwMgrId, and the free nodes listed by the adaptor
+func BuildInventorySnapshot(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepools []hwmgmtv1alpha1.NodePool,
+	nodes []hwmgmtv1alpha1.Node,
+	freenodes []FreeNode,
+	now metav1.Time) *InventorySnapshot {
+
+	snapshot := &InventorySnapshot{
+		SchemaVersion: InventorySnapshotVersion,
+		HwMgrId:       hwmgr.Name,
+		AdaptorId:     hwmgr.Spec.AdaptorID,
+		CapturedAt:    now,
+		ResourcePools: hwmgr.Status.ResourcePools,
+		Nodes:         []SnapshotNode{},
+	}
+
+	// Map each nodegroup to its resource pool and site
+	type groupKey struct{ nodepool, group string }
+	groupPools := make(map[groupKey]string)
+	sites := make(map[string]string)
+	for _, nodepool := range nodepools {
+		if nodepool.Spec.HwMgrId != hwmgr.Name {
+			continue
+		}
+		sites[nodepool.Name] = nodepool.Spec.Site
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			groupPools[groupKey{nodepool.Name, nodegroup.NodePoolData.Name}] = nodegroup.NodePoolData.ResourcePoolId
+		}
+
+		entry := SnapshotNodePool{
+			Name:      nodepool.Name,
+			Namespace: nodepool.Namespace,
+			Spec:      *nodepool.Spec.DeepCopy(),
+		}
+		if condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)); condition != nil {
+			entry.Provisioned = condition.Reason
+		}
+		snapshot.NodePools = append(snapshot.NodePools, entry)
+	}
+
+	seen := make(map[string]bool)
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Spec.HwMgrId != hwmgr.Name || node.Spec.HwMgrNodeId == "" || seen[node.Spec.HwMgrNodeId] {
+			continue
+		}
+		seen[node.Spec.HwMgrNodeId] = true
+
+		entry := SnapshotNode{
+			NodeId:         node.Spec.HwMgrNodeId,
+			ResourcePoolId: groupPools[groupKey{node.Spec.NodePool, node.Spec.GroupName}],
+			Site:           sites[node.Spec.NodePool],
+			NodeName:       node.Name,
+			NodePool:       node.Spec.NodePool,
+			GroupName:      node.Spec.GroupName,
+			HwProfile:      node.Spec.HwProfile,
+			Interfaces:     node.Status.Interfaces,
+			Failed:         IsNodeFailed(node),
+		}
+		if node.Status.BMC != nil {
+			entry.BMCAddress = node.Status.BMC.Address
+		}
+		snapshot.Nodes = append(snapshot.Nodes, entry)
+	}
+
+	for _, freenode := range freenodes {
+		if seen[freenode.NodeId] {
+			continue
+		}
+		seen[freenode.NodeId] = true
+
+		snapshot.Nodes = append(snapshot.Nodes, SnapshotNode{
+			NodeId:         freenode.NodeId,
+			ResourcePoolId: freenode.ResourcePoolId,
+			Site:           freenode.Site,
+			CPUs:           freenode.Attributes.CPUs,
+			MemoryGiB:      freenode.Attributes.MemoryGiB,
+			NICModels:      freenode.Attributes.NICModels,
+			Location:       freenode.Attributes.Location,
+		})
+	}
+
+	slices.SortFunc(snapshot.Nodes, func(a, b SnapshotNode) int {
+		return cmp.Compare(a.NodeId, b.NodeId)
+	})
+	slices.SortFunc(snapshot.NodePools, func(a, b SnapshotNodePool) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	return snapshot
+}
+
+// GetAdoptedNodes returns the node IDs allocated to each nodegroup of the NodePool in the snapshot, in the format of the
+// adoptNodes extension of the NodePool
+func (snapshot *InventorySnapshot) GetAdoptedNodes(nodepool string) map[string][]string {
+	adopted := make(map[string][]string)
+	for _, node := range snapshot.Nodes {
+		if node.NodePool == nodepool && node.GroupName != "" {
+			adopted[node.GroupName] = append(adopted[node.GroupName], node.NodeId)
+		}
+	}
+	return adopted
+}
+
+// NewInventorySnapshotConfigMap builds the configmap that holds the inventory snapshot of a hardware manager
+func NewInventorySnapshotConfigMap(namespace string, snapshot *InventorySnapshot) (*corev1.ConfigMap, error) {
+	data, err := yaml.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal inventory snapshot: %w", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      InventorySnapshotName(snapshot.HwMgrId),
+			Namespace: namespace,
+			Labels: map[string]string{
+				ManagedByLabel: ManagedByLabelValue,
+				HwMgrIdLabel:   snapshot.HwMgrId,
+			},
+		},
+		Data: map[string]string{
+			InventorySnapshotKey: string(data),
+		},
+	}, nil
+}
+
+// ParseInventorySnapshot parses a YAML or JSON encoded inventory snapshot, rejecting snapshots written with a newer
+// version of the format
+func ParseInventorySnapshot(data []byte) (*InventorySnapshot, error) {
+	snapshot := &InventorySnapshot{}
+	if err := yaml.Unmarshal(data, snapshot); err != nil {
+		return nil, NewInputError("failed to parse inventory snapshot: %s", err.Error())
+	}
+
+	if snapshot.SchemaVersion > InventorySnapshotVersion {
+		return nil, NewInputError("inventory snapshot version %d is newer than the supported version %d",
+			snapshot.SchemaVersion, InventorySnapshotVersion)
+	}
+	if snapshot.HwMgrId == "" {
+		return nil, NewInputError("inventory snapshot has no hwMgrId")
+	}
+
+	return snapshot, nil
+}
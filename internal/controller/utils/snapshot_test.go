@@ -0,0 +1,146 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Inventory snapshot", func() {
+	hwmgr := &pluginv1alpha1.HardwareManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "hwmgr-1", Namespace: "plugin"},
+		Spec:       pluginv1alpha1.HardwareManagerSpec{AdaptorID: pluginv1alpha1.SupportedAdaptors.Loopback},
+		Status: pluginv1alpha1.HardwareManagerStatus{
+			ResourcePools: pluginv1alpha1.PerSiteResourcePoolList{"site-1": {"master", "worker"}},
+		},
+	}
+
+	nodepool := hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-1", Namespace: "plugin"},
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			CloudID:      "cloud-1",
+			HwMgrId:      "hwmgr-1",
+			LocationSpec: hwmgmtv1alpha1.LocationSpec{Site: "site-1"},
+			NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+				{NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "controller", HwProfile: "profile-1", ResourcePoolId: "master"}, Size: 1},
+			},
+		},
+	}
+	nodepool.Status.Conditions = []metav1.Condition{
+		{Type: string(hwmgmtv1alpha1.Provisioned), Status: metav1.ConditionTrue, Reason: string(hwmgmtv1alpha1.Completed)},
+	}
+
+	newNode := func(name, hwmgrId, nodeId string) hwmgmtv1alpha1.Node {
+		node := hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "plugin"}}
+		node.Spec = hwmgmtv1alpha1.NodeSpec{
+			NodePool:    "cluster-1",
+			GroupName:   "controller",
+			HwProfile:   "profile-1",
+			HwMgrId:     hwmgrId,
+			HwMgrNodeId: nodeId,
+		}
+		node.Status.BMC = &hwmgmtv1alpha1.BMC{Address: "idrac-virtualmedia+https://192.168.1.10/redfish/v1/Systems/1"}
+		return node
+	}
+
+	now := metav1.NewTime(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	It("captures the free and allocated nodes of the hardware manager", func() {
+		nodes := []hwmgmtv1alpha1.Node{
+			newNode("node-1", "hwmgr-1", "dummy-sp-64g-1"),
+			newNode("node-2", "hwmgr-2", "other-node"),
+		}
+		freenodes := []FreeNode{
+			{NodeId: "dummy-sp-96g-0", ResourcePoolId: "worker", Site: "site-1", Attributes: NodeAttributes{CPUs: 32, MemoryGiB: 96}},
+			{NodeId: "dummy-sp-64g-1", ResourcePoolId: "master"},
+		}
+
+		snapshot := BuildInventorySnapshot(hwmgr, []hwmgmtv1alpha1.NodePool{nodepool}, nodes, freenodes, now)
+		Expect(snapshot.SchemaVersion).To(Equal(InventorySnapshotVersion))
+		Expect(snapshot.HwMgrId).To(Equal("hwmgr-1"))
+		Expect(snapshot.AdaptorId).To(Equal(pluginv1alpha1.SupportedAdaptors.Loopback))
+		Expect(snapshot.NodePools).To(HaveLen(1))
+		Expect(snapshot.NodePools[0].Provisioned).To(Equal(string(hwmgmtv1alpha1.Completed)))
+
+		Expect(snapshot.Nodes).To(HaveLen(2))
+		allocated := snapshot.Nodes[0]
+		Expect(allocated.NodeId).To(Equal("dummy-sp-64g-1"))
+		Expect(allocated.IsAllocated()).To(BeTrue())
+		Expect(allocated.ResourcePoolId).To(Equal("master"))
+		Expect(allocated.Site).To(Equal("site-1"))
+		Expect(allocated.BMCAddress).To(Equal("idrac-virtualmedia+https://192.168.1.10/redfish/v1/Systems/1"))
+
+		free := snapshot.Nodes[1]
+		Expect(free.NodeId).To(Equal("dummy-sp-96g-0"))
+		Expect(free.IsAllocated()).To(BeFalse())
+		Expect(free.CPUs).To(Equal(32))
+		Expect(free.MemoryGiB).To(Equal(96))
+
+		Expect(snapshot.GetAdoptedNodes("cluster-1")).To(Equal(map[string][]string{"controller": {"dummy-sp-64g-1"}}))
+	})
+
+	It("round-trips through the snapshot configmap", func() {
+		snapshot := BuildInventorySnapshot(hwmgr, []hwmgmtv1alpha1.NodePool{nodepool},
+			[]hwmgmtv1alpha1.Node{newNode("node-1", "hwmgr-1", "dummy-sp-64g-1")}, nil, now)
+
+		cm, err := NewInventorySnapshotConfigMap("plugin", snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cm.Name).To(Equal("hwmgr-1-snapshot"))
+		Expect(cm.Labels).To(HaveKeyWithValue(HwMgrIdLabel, "hwmgr-1"))
+
+		parsed, err := ParseInventorySnapshot([]byte(cm.Data[InventorySnapshotKey]))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed.Nodes).To(Equal(snapshot.Nodes))
+		Expect(parsed.NodePools).To(Equal(snapshot.NodePools))
+		Expect(parsed.CapturedAt.Equal(&now)).To(BeTrue())
+	})
+
+	It("parses JSON snapshots and rejects newer versions", func() {
+		parsed, err := ParseInventorySnapshot([]byte(`{"schemaVersion": 1, "hwMgrId": "hwmgr-1", "nodes": [{"nodeId": "node-a"}]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed.Nodes).To(HaveLen(1))
+
+		_, err = ParseInventorySnapshot([]byte("schemaVersion: 2\nhwMgrId: hwmgr-1\n"))
+		Expect(err).To(MatchError(ContainSubstring("newer than the supported version")))
+		Expect(IsInputError(err)).To(BeTrue())
+
+		_, err = ParseInventorySnapshot([]byte("schemaVersion: 1\n"))
+		Expect(err).To(MatchError(ContainSubstring("no hwMgrId")))
+	})
+
+	It("reports a pending snapshot for a new trigger", func() {
+		hwmgr := hwmgr.DeepCopy()
+		_, pending := GetSnapshotTrigger(hwmgr)
+		Expect(pending).To(BeFalse())
+
+		hwmgr.SetAnnotations(map[string]string{SnapshotAnnotation: "t1"})
+		trigger, pending := GetSnapshotTrigger(hwmgr)
+		Expect(trigger).To(Equal("t1"))
+		Expect(pending).To(BeTrue())
+
+		hwmgr.Status.Snapshot = &pluginv1alpha1.SnapshotStatus{Trigger: "t1"}
+		_, pending = GetSnapshotTrigger(hwmgr)
+		Expect(pending).To(BeFalse())
+	})
+})
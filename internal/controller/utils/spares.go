@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// SpareNodesKey is the NodePool extensions key that holds the spare node configuration, keyed by nodegroup name
+	SpareNodesKey = "spareNodes"
+)
+
+// Spares condition type and reasons, reporting the number of ready spare nodes for each nodegroup
+const (
+	NodePoolSpares           hwmgmtv1alpha1.ConditionType   = "Spares"
+	ReasonSparesReady        hwmgmtv1alpha1.ConditionReason = "SparesReady"
+	ReasonSparesInsufficient hwmgmtv1alpha1.ConditionReason = "SparesInsufficient"
+)
+
+// SpareConfig defines the spare nodes maintained for a nodegroup
+type SpareConfig struct {
+	// SparePoolId is the resource pool from which spare nodes are allocated. Defaults to the nodegroup resource pool
+	SparePoolId string `json:"sparePoolId,omitempty"`
+	// Count is the number of spare nodes to keep ready
+	Count int `json:"count"`
+}
+
+// GetNodePoolSpareConfig parses the spare node configuration from the NodePool extensions, defaulting the spare pool
+// of each nodegroup to its resource pool
+func GetNodePoolSpareConfig(nodepool *hwmgmtv1alpha1.NodePool) (map[string]SpareConfig, error) {
+	data, exists := nodepool.Spec.Extensions[SpareNodesKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var config map[string]SpareConfig
+	if err := yaml.Unmarshal([]byte(data), &config); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", SpareNodesKey, err.Error())
+	}
+
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		spares, exists := config[nodegroup.NodePoolData.Name]
+		if exists && spares.SparePoolId == "" {
+			spares.SparePoolId = nodegroup.NodePoolData.ResourcePoolId
+			config[nodegroup.NodePoolData.Name] = spares
+		}
+	}
+
+	return config, nil
+}
+
+// ValidateNodePoolSpareConfig validates that the spare node configuration references defined nodegroups
+func ValidateNodePoolSpareConfig(nodepool *hwmgmtv1alpha1.NodePool) error {
+	config, err := GetNodePoolSpareConfig(nodepool)
+	if err != nil {
+		return err
+	}
+
+	for groupname, spares := range config {
+		if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		}) {
+			return NewInputError("spare nodes configured for unknown nodegroup %s", groupname)
+		}
+		if spares.Count < 0 {
+			return NewInputError("invalid spare count %d for nodegroup %s", spares.Count, groupname)
+		}
+	}
+
+	return nil
+}
+
+// IsNodeFailed returns true if the node has been marked as failed, making it a candidate for replacement by a spare
+func IsNodeFailed(node *hwmgmtv1alpha1.Node) bool {
+	provisioned := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+	return provisioned != nil && provisioned.Reason == string(hwmgmtv1alpha1.Failed)
+}
+
+// UpdateNodePoolSparesCondition reports the ready spare count of each configured nodegroup in the Spares condition
+func UpdateNodePoolSparesCondition(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	config map[string]SpareConfig,
+	ready map[string]int) error {
+
+	if len(config) == 0 {
+		return nil
+	}
+
+	groupnames := make([]string, 0, len(config))
+	for groupname := range config {
+		groupnames = append(groupnames, groupname)
+	}
+	slices.Sort(groupnames)
+
+	reason := ReasonSparesReady
+	status := metav1.ConditionTrue
+	counts := make([]string, 0, len(groupnames))
+	for _, groupname := range groupnames {
+		if ready[groupname] < config[groupname].Count {
+			reason = ReasonSparesInsufficient
+			status = metav1.ConditionFalse
+		}
+		counts = append(counts, fmt.Sprintf("%s: %d/%d", groupname, ready[groupname], config[groupname].Count))
+	}
+
+	message := "Ready spares: " + strings.Join(counts, ", ")
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolSpares))
+	if current != nil && current.Reason == string(reason) && current.Message == message {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolSpares, reason, status, message)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Spare nodes", func() {
+	It("defaults the spare pool to the nodegroup resource pool", func() {
+		nodepool := newTestNodePool(map[string]string{SpareNodesKey: `
+master:
+  count: 1
+worker:
+  sparePoolId: worker-spares
+  count: 2
+`})
+		Expect(ValidateNodePoolSpareConfig(nodepool)).To(Succeed())
+
+		config, err := GetNodePoolSpareConfig(nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(Equal(map[string]SpareConfig{
+			"master": {SparePoolId: "master", Count: 1},
+			"worker": {SparePoolId: "worker-spares", Count: 2},
+		}))
+	})
+
+	It("rejects spares for an unknown nodegroup", func() {
+		nodepool := newTestNodePool(map[string]string{SpareNodesKey: `
+storage:
+  count: 1
+`})
+		Expect(ValidateNodePoolSpareConfig(nodepool)).To(MatchError(ContainSubstring("unknown nodegroup storage")))
+	})
+
+	It("rejects a negative spare count", func() {
+		nodepool := newTestNodePool(map[string]string{SpareNodesKey: `
+master:
+  count: -1
+`})
+		Expect(ValidateNodePoolSpareConfig(nodepool)).To(MatchError(ContainSubstring("invalid spare count")))
+	})
+})
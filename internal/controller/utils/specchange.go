@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// SpecChangeProgressAnnotation records, on a NodePool, the progress of the workflow applying its latest spec change
+	SpecChangeProgressAnnotation = "hwmgr-plugin.oran.openshift.io/specChangeProgress"
+)
+
+// SpecChangePhase is a phase of the workflow applying a NodePool spec change
+type SpecChangePhase string
+
+// SpecChangePhases defines the phases of a spec change workflow, in order
+var SpecChangePhases = struct {
+	Validating SpecChangePhase
+	Staging    SpecChangePhase
+	Applying   SpecChangePhase
+	Completed  SpecChangePhase
+	Failed     SpecChangePhase
+}{
+	Validating: "Validating",
+	Staging:    "Staging",
+	Applying:   "Applying",
+	Completed:  "Completed",
+	Failed:     "Failed",
+}
+
+// SpecChangeProgress is the progress of the workflow applying a spec change to the nodes of a NodePool
+type SpecChangeProgress struct {
+	// Generation is the NodePool generation being applied
+	Generation int64           `json:"generation"`
+	Phase      SpecChangePhase `json:"phase"`
+	// Nodes are the names of the nodes to be updated, staged for the apply in order
+	Nodes   []string `json:"nodes,omitempty"`
+	Applied int      `json:"applied"`
+	// NextStep is the earliest time of the next step of the workflow
+	NextStep time.Time `json:"nextStep,omitempty"`
+	// Message describes the failure, for the Failed phase
+	Message string `json:"message,omitempty"`
+}
+
+// NewSpecChangeProgress returns the progress of a spec change workflow starting for the current NodePool generation
+func NewSpecChangeProgress(nodepool *hwmgmtv1alpha1.NodePool) *SpecChangeProgress {
+	return &SpecChangeProgress{
+		Generation: nodepool.Generation,
+		Phase:      SpecChangePhases.Validating,
+	}
+}
+
+// IsDone returns true if the workflow has completed or failed
+func (p *SpecChangeProgress) IsDone() bool {
+	return p.Phase == SpecChangePhases.Completed || p.Phase == SpecChangePhases.Failed
+}
+
+// Describe returns a description of the progress, suitable for the Configured condition message
+func (p *SpecChangeProgress) Describe() string {
+	switch p.Phase {
+	case SpecChangePhases.Validating:
+		return "Validating configuration change"
+	case SpecChangePhases.Staging:
+		return "Staging configuration change"
+	case SpecChangePhases.Applying:
+		return fmt.Sprintf("Applying configuration change: %d of %d nodes updated", p.Applied, len(p.Nodes))
+	case SpecChangePhases.Completed:
+		return fmt.Sprintf("Configuration change applied to %d nodes", len(p.Nodes))
+	case SpecChangePhases.Failed:
+		return "Configuration change failed: " + p.Message
+	}
+	return string(p.Phase)
+}
+
+// GetSpecChangeProgress returns the progress of the spec change workflow of the current NodePool generation, or nil if
+// no workflow has started for it
+func GetSpecChangeProgress(nodepool *hwmgmtv1alpha1.NodePool) (*SpecChangeProgress, error) {
+	value, exists := nodepool.GetAnnotations()[SpecChangeProgressAnnotation]
+	if !exists {
+		return nil, nil
+	}
+
+	var progress SpecChangeProgress
+	if err := json.Unmarshal([]byte(value), &progress); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", SpecChangeProgressAnnotation, err)
+	}
+
+	if progress.Generation != nodepool.Generation {
+		// The progress is for an earlier spec change
+		return nil, nil
+	}
+	return &progress, nil
+}
+
+// SetSpecChangeProgress records the progress of the spec change workflow on the NodePool
+func SetSpecChangeProgress(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, progress *SpecChangeProgress) error {
+	value, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to encode spec change progress: %w", err)
+	}
+
+	patch := client.MergeFrom(nodepool.DeepCopy())
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[SpecChangeProgressAnnotation] = string(value)
+	nodepool.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, nodepool, patch); err != nil {
+		return fmt.Errorf("failed to annotate NodePool %s with spec change progress: %w", nodepool.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NodePool spec change progress", func() {
+	It("is only returned for the current generation", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Generation = 2
+		progress, err := GetSpecChangeProgress(nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress).To(BeNil())
+
+		nodepool.SetAnnotations(map[string]string{
+			SpecChangeProgressAnnotation: `{"generation":1,"phase":"Completed","nodes":["node-1"],"applied":1}`,
+		})
+		progress, err = GetSpecChangeProgress(nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress).To(BeNil())
+
+		nodepool.SetAnnotations(map[string]string{
+			SpecChangeProgressAnnotation: `{"generation":2,"phase":"Applying","nodes":["node-1","node-2"],"applied":1}`,
+		})
+		progress, err = GetSpecChangeProgress(nodepool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(progress.Phase).To(Equal(SpecChangePhases.Applying))
+		Expect(progress.IsDone()).To(BeFalse())
+		Expect(progress.Describe()).To(Equal("Applying configuration change: 1 of 2 nodes updated"))
+
+		nodepool.SetAnnotations(map[string]string{SpecChangeProgressAnnotation: "invalid"})
+		_, err = GetSpecChangeProgress(nodepool)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("starts with validation of the current generation", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Generation = 3
+		progress := NewSpecChangeProgress(nodepool)
+		Expect(progress.Generation).To(Equal(int64(3)))
+		Expect(progress.Phase).To(Equal(SpecChangePhases.Validating))
+
+		progress.Phase = SpecChangePhases.Failed
+		progress.Message = "node node-1 is incompatible"
+		Expect(progress.IsDone()).To(BeTrue())
+		Expect(progress.Describe()).To(Equal("Configuration change failed: node node-1 is incompatible"))
+	})
+})
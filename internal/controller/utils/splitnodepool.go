@@ -0,0 +1,314 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodeGroupHwMgrKey is the NodePool extensions key that holds the HardwareManager serving each nodegroup, keyed by
+	// nodegroup name, for NodePools served by multiple HardwareManagers. Nodegroups that are not listed are served by
+	// the HardwareManager of the NodePool.
+	NodeGroupHwMgrKey = "nodeGroupHwMgr"
+
+	// SplitParentLabel is set on the NodePools split from a NodePool served by multiple HardwareManagers, naming the
+	// parent NodePool
+	SplitParentLabel = "hwmgr-plugin.oran.openshift.io/split-parent"
+
+	pluginAnnotationPrefix = "hwmgr-plugin.oran.openshift.io/"
+)
+
+// splitConditionTypes are the conditions of the split NodePools that are merged into the status of the parent
+var splitConditionTypes = []string{
+	string(hwmgmtv1alpha1.Provisioned),
+	string(hwmgmtv1alpha1.Configured),
+	string(NodePoolNodesReady),
+}
+
+// nodeGroupExtensionKeys are the NodePool extensions keyed by nodegroup name, which are filtered to the nodegroups of
+// each split NodePool
+var nodeGroupExtensionKeys = []string{
+	AdoptNodesKey,
+	FulfillmentPolicyKey,
+	NetworkConfigKey,
+	NodeSelectorKey,
+	SpareNodesKey,
+	SpreadPolicyKey,
+}
+
+// GetNodePoolNodeGroupHwMgrs parses the HardwareManager IDs of the nodegroups from the NodePool extensions
+func GetNodePoolNodeGroupHwMgrs(nodepool *hwmgmtv1alpha1.NodePool) (map[string]string, error) {
+	data, exists := nodepool.Spec.Extensions[NodeGroupHwMgrKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var hwmgrs map[string]string
+	if err := yaml.Unmarshal([]byte(data), &hwmgrs); err != nil {
+		return nil, NewInputError("failed to parse %s extension: %s", NodeGroupHwMgrKey, err.Error())
+	}
+
+	return hwmgrs, nil
+}
+
+// IsSplitNodePool returns true if the NodePool assigns its nodegroups to HardwareManagers, in which case it is split
+// into a NodePool for each HardwareManager rather than processed by an adaptor
+func IsSplitNodePool(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	_, exists := nodepool.Spec.Extensions[NodeGroupHwMgrKey]
+	return exists
+}
+
+// GetNodeGroupHwMgrId returns the ID of the HardwareManager serving a nodegroup, defaulting to that of the NodePool
+func GetNodeGroupHwMgrId(nodepool *hwmgmtv1alpha1.NodePool, groupname string) string {
+	hwmgrs, err := GetNodePoolNodeGroupHwMgrs(nodepool)
+	if err == nil && hwmgrs[groupname] != "" {
+		return hwmgrs[groupname]
+	}
+	return nodepool.Spec.HwMgrId
+}
+
+// SplitNodePoolName returns the name of the NodePool split from the parent for a HardwareManager
+func SplitNodePoolName(parent, hwMgrId string) string {
+	return parent + "-" + hwMgrId
+}
+
+// ValidateNodePoolNodeGroupHwMgrs validates that the nodegroup HardwareManagers reference defined nodegroups, with
+// HardwareManager IDs that yield valid names for the split NodePools
+func ValidateNodePoolNodeGroupHwMgrs(nodepool *hwmgmtv1alpha1.NodePool) error {
+	hwmgrs, err := GetNodePoolNodeGroupHwMgrs(nodepool)
+	if err != nil {
+		return err
+	}
+
+	for groupname, hwMgrId := range hwmgrs {
+		if !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		}) {
+			return NewInputError("hardware manager specified for unknown nodegroup %s", groupname)
+		}
+		if hwMgrId == "" {
+			return NewInputError("no hardware manager specified for nodegroup %s", groupname)
+		}
+	}
+
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		hwMgrId := GetNodeGroupHwMgrId(nodepool, nodegroup.NodePoolData.Name)
+		if hwMgrId == "" {
+			return NewInputError("no hardware manager specified for nodegroup %s", nodegroup.NodePoolData.Name)
+		}
+		if errs := validation.IsDNS1123Subdomain(SplitNodePoolName(nodepool.Name, hwMgrId)); len(errs) > 0 {
+			return NewInputError("invalid hardware manager %q for nodegroup %s: %s",
+				hwMgrId, nodegroup.NodePoolData.Name, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ValidateSplitNodePoolAdaptors validates that the nodegroups of a split NodePool are served by at most one loopback
+// HardwareManager, as the loopback HardwareManagers share the nodelist configmap, in which the allocations of the split
+// NodePools would be held under the same cloud. HardwareManagers that are not in the list are not validated, as they
+// are reported when the split NodePools are processed.
+func ValidateSplitNodePoolAdaptors(nodepool *hwmgmtv1alpha1.NodePool, hwmgrs []pluginv1alpha1.HardwareManager) error {
+	var loopbacks []string
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		hwMgrId := GetNodeGroupHwMgrId(nodepool, nodegroup.NodePoolData.Name)
+		index := slices.IndexFunc(hwmgrs, func(hwmgr pluginv1alpha1.HardwareManager) bool {
+			return hwmgr.Name == hwMgrId
+		})
+		if index >= 0 && hwmgrs[index].Spec.AdaptorID == pluginv1alpha1.SupportedAdaptors.Loopback &&
+			!slices.Contains(loopbacks, hwMgrId) {
+			loopbacks = append(loopbacks, hwMgrId)
+		}
+	}
+
+	if len(loopbacks) > 1 {
+		return NewInputError("nodegroups are split across loopback hardware managers %s, which share their allocations",
+			strings.Join(loopbacks, ", "))
+	}
+
+	return nil
+}
+
+// BuildSplitNodePools returns the NodePools split from a NodePool served by multiple HardwareManagers, one for each
+// HardwareManager in the order of their first nodegroup. Each has the nodegroups served by its HardwareManager, with
+// the per-nodegroup extensions filtered to those nodegroups. The completion callback is sent for the parent only.
+func BuildSplitNodePools(nodepool *hwmgmtv1alpha1.NodePool) ([]*hwmgmtv1alpha1.NodePool, error) {
+	if err := ValidateNodePoolNodeGroupHwMgrs(nodepool); err != nil {
+		return nil, err
+	}
+
+	var children []*hwmgmtv1alpha1.NodePool
+	byHwMgr := make(map[string]*hwmgmtv1alpha1.NodePool)
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		hwMgrId := GetNodeGroupHwMgrId(nodepool, nodegroup.NodePoolData.Name)
+		child, exists := byHwMgr[hwMgrId]
+		if !exists {
+			child = &hwmgmtv1alpha1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        SplitNodePoolName(nodepool.Name, hwMgrId),
+					Namespace:   nodepool.Namespace,
+					Labels:      maps.Clone(nodepool.Labels),
+					Annotations: make(map[string]string),
+				},
+				Spec: *nodepool.Spec.DeepCopy(),
+			}
+			if child.Labels == nil {
+				child.Labels = make(map[string]string)
+			}
+			child.Labels[SplitParentLabel] = nodepool.Name
+			for key, value := range nodepool.Annotations {
+				if strings.HasPrefix(key, pluginAnnotationPrefix) {
+					child.Annotations[key] = value
+				}
+			}
+			child.Spec.HwMgrId = hwMgrId
+			child.Spec.NodeGroup = nil
+			byHwMgr[hwMgrId] = child
+			children = append(children, child)
+		}
+		child.Spec.NodeGroup = append(child.Spec.NodeGroup, *nodegroup.DeepCopy())
+	}
+
+	for _, child := range children {
+		delete(child.Spec.Extensions, NodeGroupHwMgrKey)
+		delete(child.Spec.Extensions, CallbackURLKey)
+		for _, key := range nodeGroupExtensionKeys {
+			if err := filterNodeGroupExtension(child, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return children, nil
+}
+
+// filterNodeGroupExtension removes the entries of a per-nodegroup extension for nodegroups not in the NodePool
+func filterNodeGroupExtension(nodepool *hwmgmtv1alpha1.NodePool, key string) error {
+	data, exists := nodepool.Spec.Extensions[key]
+	if !exists || data == "" {
+		return nil
+	}
+
+	var entries map[string]any
+	if err := yaml.Unmarshal([]byte(data), &entries); err != nil {
+		return NewInputError("failed to parse %s extension: %s", key, err.Error())
+	}
+
+	maps.DeleteFunc(entries, func(groupname string, _ any) bool {
+		return !slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+			return nodegroup.NodePoolData.Name == groupname
+		})
+	})
+	if len(entries) == 0 {
+		delete(nodepool.Spec.Extensions, key)
+		return nil
+	}
+
+	filtered, err := yaml.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s extension: %w", key, err)
+	}
+	nodepool.Spec.Extensions[key] = string(filtered)
+	return nil
+}
+
+// MergeSplitNodePoolStatus merges the status of the split NodePools, in the order returned by BuildSplitNodePools,
+// into that of the parent, returning true if it has changed. Each merged condition is only true once it is true for
+// all the split NodePools, and otherwise reports the first failed NodePool, or the first that is not yet true. The
+// status is not updated on the cluster.
+func MergeSplitNodePoolStatus(nodepool *hwmgmtv1alpha1.NodePool, children []hwmgmtv1alpha1.NodePool) bool {
+	original := nodepool.Status.DeepCopy()
+
+	for _, conditionType := range splitConditionTypes {
+		mergeSplitCondition(nodepool, children, conditionType)
+	}
+
+	var nodenames []string
+	observed := true
+	for i := range children {
+		nodenames = append(nodenames, children[i].Status.Properties.NodeNames...)
+		if children[i].Status.HwMgrPlugin.ObservedGeneration != children[i].Generation {
+			observed = false
+		}
+	}
+	nodepool.Status.Properties.NodeNames = nodenames
+	if observed {
+		nodepool.Status.HwMgrPlugin.ObservedGeneration = nodepool.Generation
+	}
+
+	return !equality.Semantic.DeepEqual(original, &nodepool.Status)
+}
+
+// mergeSplitCondition merges a condition of the split NodePools into the parent, if reported by any of them
+func mergeSplitCondition(nodepool *hwmgmtv1alpha1.NodePool, children []hwmgmtv1alpha1.NodePool, conditionType string) {
+	const (
+		rankTrue = iota
+		rankMissing
+		rankFalse
+		rankFailed
+	)
+
+	var worst *metav1.Condition
+	worstChild := ""
+	worstRank := -1
+	reported := false
+	for i := range children {
+		condition := meta.FindStatusCondition(children[i].Status.Conditions, conditionType)
+		rank := rankMissing
+		switch {
+		case condition == nil:
+		case condition.Status == metav1.ConditionTrue:
+			rank = rankTrue
+		case condition.Reason == string(hwmgmtv1alpha1.Failed):
+			rank = rankFailed
+		default:
+			rank = rankFalse
+		}
+		reported = reported || condition != nil
+		if rank > worstRank {
+			worst, worstChild, worstRank = condition, children[i].Name, rank
+		}
+	}
+	if !reported {
+		return
+	}
+
+	switch worstRank {
+	case rankTrue:
+		SetStatusCondition(&nodepool.Status.Conditions, conditionType, worst.Reason, metav1.ConditionTrue, worst.Message)
+	case rankMissing:
+		SetStatusCondition(&nodepool.Status.Conditions, conditionType, string(hwmgmtv1alpha1.InProgress),
+			metav1.ConditionFalse, fmt.Sprintf("Waiting for NodePool %s", worstChild))
+	default:
+		SetStatusCondition(&nodepool.Status.Conditions, conditionType, worst.Reason, worst.Status,
+			fmt.Sprintf("NodePool %s: %s", worstChild, worst.Message))
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Split NodePools", func() {
+	newSplitNodePool := func() *hwmgmtv1alpha1.NodePool {
+		nodepool := newTestNodePool(map[string]string{
+			NodeGroupHwMgrKey: `
+worker: loopback-1
+`,
+			SpareNodesKey: `
+master:
+  count: 1
+  sparePoolId: spares
+worker:
+  count: 2
+  sparePoolId: spares
+`,
+			CallbackURLKey: "https://example.com/callback",
+		})
+		nodepool.Name = "np1"
+		nodepool.Spec.HwMgrId = "dell-1"
+		return nodepool
+	}
+
+	It("validates the nodegroup hardware managers", func() {
+		nodepool := newSplitNodePool()
+		Expect(IsSplitNodePool(nodepool)).To(BeTrue())
+		Expect(IsSplitNodePool(newTestNodePool(nil))).To(BeFalse())
+		Expect(ValidateNodePoolNodeGroupHwMgrs(nodepool)).To(Succeed())
+		Expect(GetNodeGroupHwMgrId(nodepool, "master")).To(Equal("dell-1"))
+		Expect(GetNodeGroupHwMgrId(nodepool, "worker")).To(Equal("loopback-1"))
+
+		nodepool.Spec.Extensions[NodeGroupHwMgrKey] = "storage: loopback-1\n"
+		Expect(IsInputError(ValidateNodePoolNodeGroupHwMgrs(nodepool))).To(BeTrue())
+
+		nodepool.Spec.Extensions[NodeGroupHwMgrKey] = "worker: Loopback_1\n"
+		Expect(IsInputError(ValidateNodePoolNodeGroupHwMgrs(nodepool))).To(BeTrue())
+	})
+
+	It("rejects a split across more than one loopback hardware manager", func() {
+		newHwMgr := func(name string, adaptorID pluginv1alpha1.HardwareManagerAdaptorID) pluginv1alpha1.HardwareManager {
+			return pluginv1alpha1.HardwareManager{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       pluginv1alpha1.HardwareManagerSpec{AdaptorID: adaptorID},
+			}
+		}
+		hwmgrs := []pluginv1alpha1.HardwareManager{
+			newHwMgr("dell-1", pluginv1alpha1.SupportedAdaptors.Dell),
+			newHwMgr("loopback-1", pluginv1alpha1.SupportedAdaptors.Loopback),
+			newHwMgr("loopback-2", pluginv1alpha1.SupportedAdaptors.Loopback),
+		}
+
+		nodepool := newSplitNodePool()
+		Expect(ValidateSplitNodePoolAdaptors(nodepool, hwmgrs)).To(Succeed())
+
+		nodepool.Spec.HwMgrId = "loopback-2"
+		err := ValidateSplitNodePoolAdaptors(nodepool, hwmgrs)
+		Expect(IsInputError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("loopback-2, loopback-1")))
+
+		// HardwareManagers that do not exist are not validated
+		Expect(ValidateSplitNodePoolAdaptors(nodepool, hwmgrs[:1])).To(Succeed())
+	})
+
+	It("splits the nodegroups by hardware manager", func() {
+		children, err := BuildSplitNodePools(newSplitNodePool())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(children).To(HaveLen(2))
+
+		Expect(children[0].Name).To(Equal("np1-dell-1"))
+		Expect(children[0].Labels[SplitParentLabel]).To(Equal("np1"))
+		Expect(children[0].Spec.HwMgrId).To(Equal("dell-1"))
+		Expect(children[0].Spec.NodeGroup).To(HaveLen(1))
+		Expect(children[0].Spec.NodeGroup[0].NodePoolData.Name).To(Equal("master"))
+
+		Expect(children[1].Name).To(Equal("np1-loopback-1"))
+		Expect(children[1].Spec.HwMgrId).To(Equal("loopback-1"))
+		Expect(children[1].Spec.NodeGroup[0].NodePoolData.Name).To(Equal("worker"))
+
+		for _, child := range children {
+			Expect(IsSplitNodePool(child)).To(BeFalse())
+			Expect(child.Spec.Extensions).ToNot(HaveKey(CallbackURLKey))
+			Expect(ValidateNodePoolSpareConfig(child)).To(Succeed())
+		}
+		config, err := GetNodePoolSpareConfig(children[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(HaveKey("worker"))
+		Expect(config).ToNot(HaveKey("master"))
+	})
+
+	It("merges the status of the split NodePools", func() {
+		nodepool := newSplitNodePool()
+		nodepool.Generation = 2
+		children := []hwmgmtv1alpha1.NodePool{{}, {}}
+		children[0].Name = "np1-dell-1"
+		children[1].Name = "np1-loopback-1"
+		children[0].Generation, children[1].Generation = 1, 1
+		children[0].Status.Properties.NodeNames = []string{"master-0"}
+		SetStatusCondition(&children[0].Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.Completed), metav1.ConditionTrue, "Created")
+
+		Expect(MergeSplitNodePoolStatus(nodepool, children)).To(BeTrue())
+		condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(hwmgmtv1alpha1.InProgress)))
+		Expect(condition.Message).To(ContainSubstring("np1-loopback-1"))
+		Expect(nodepool.Status.Properties.NodeNames).To(Equal([]string{"master-0"}))
+		Expect(nodepool.Status.HwMgrPlugin.ObservedGeneration).To(BeZero())
+
+		SetStatusCondition(&children[1].Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.Failed), metav1.ConditionFalse, "no free nodes")
+		Expect(MergeSplitNodePoolStatus(nodepool, children)).To(BeTrue())
+		condition = meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		Expect(condition.Reason).To(Equal(string(hwmgmtv1alpha1.Failed)))
+		Expect(condition.Message).To(Equal("NodePool np1-loopback-1: no free nodes"))
+
+		SetStatusCondition(&children[1].Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.Completed), metav1.ConditionTrue, "Created")
+		children[1].Status.Properties.NodeNames = []string{"worker-0"}
+		children[0].Status.HwMgrPlugin.ObservedGeneration = 1
+		children[1].Status.HwMgrPlugin.ObservedGeneration = 1
+		Expect(MergeSplitNodePoolStatus(nodepool, children)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))).To(BeTrue())
+		Expect(nodepool.Status.Properties.NodeNames).To(Equal([]string{"master-0", "worker-0"}))
+		Expect(nodepool.Status.HwMgrPlugin.ObservedGeneration).To(Equal(int64(2)))
+
+		Expect(MergeSplitNodePoolStatus(nodepool, children)).To(BeFalse())
+	})
+})
@@ -0,0 +1,215 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// ProgressAnnotation records, on a NodePool being provisioned, the last observed allocation progress and the time
+	// it was observed, as JSON
+	ProgressAnnotation = "hwmgr-plugin.oran.openshift.io/progress"
+
+	DefaultStallTimeout = 30 * time.Minute
+)
+
+// Stalled condition type and reasons, set on a NodePool that has made no progress for the stall timeout while being
+// provisioned
+const (
+	NodePoolStalled   hwmgmtv1alpha1.ConditionType   = "Stalled"
+	ReasonNoProgress  hwmgmtv1alpha1.ConditionReason = "NoProgress"
+	ReasonProgressing hwmgmtv1alpha1.ConditionReason = "Progressing"
+)
+
+// NodePoolProgress is the allocation progress of a NodePool being provisioned
+type NodePoolProgress struct {
+	// Nodes is the number of Node CRs allocated to the NodePool
+	Nodes int `json:"nodes"`
+	// Provisioned is the number of allocated nodes reported as provisioned by the backend
+	Provisioned int `json:"provisioned"`
+	// Time is when the progress was first observed
+	Time time.Time `json:"time"`
+}
+
+// GetStallTimeout returns the stall timeout for a hardware manager, and whether stall detection is enabled
+func GetStallTimeout(hwmgr *pluginv1alpha1.HardwareManager) (time.Duration, bool) {
+	if hwmgr.Spec.StallDetection == nil {
+		return 0, false
+	}
+
+	if hwmgr.Spec.StallDetection.Timeout != nil {
+		return hwmgr.Spec.StallDetection.Timeout.Duration, true
+	}
+
+	return DefaultStallTimeout, true
+}
+
+// IsNodePoolProcessing returns true if the NodePool is being provisioned, having neither completed nor failed
+func IsNodePoolProcessing(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return !IsNodePoolProvisionedCompleted(nodepool) && !IsNodePoolProvisionedFailed(nodepool)
+}
+
+// GetNodePoolProgress returns the progress recorded on the NodePool, or nil if there is none
+func GetNodePoolProgress(nodepool *hwmgmtv1alpha1.NodePool) *NodePoolProgress {
+	data, exists := nodepool.GetAnnotations()[ProgressAnnotation]
+	if !exists {
+		return nil
+	}
+
+	progress := &NodePoolProgress{}
+	if err := json.Unmarshal([]byte(data), progress); err != nil {
+		// An invalid annotation is treated as no recorded progress
+		return nil
+	}
+	return progress
+}
+
+// CountNodePoolProgress returns the current allocation progress of the NodePool from its Node CRs
+func CountNodePoolProgress(nodelist *hwmgmtv1alpha1.NodeList, now time.Time) NodePoolProgress {
+	progress := NodePoolProgress{Nodes: len(nodelist.Items), Time: now.UTC().Truncate(time.Second)}
+	for i := range nodelist.Items {
+		if meta.IsStatusConditionTrue(nodelist.Items[i].Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+			progress.Provisioned++
+		}
+	}
+	return progress
+}
+
+// RecordNodePoolProgress records the current progress on the NodePool if it has changed from the recorded progress,
+// returning true if the annotation was updated. The NodePool is not updated on the cluster.
+func RecordNodePoolProgress(nodepool *hwmgmtv1alpha1.NodePool, current NodePoolProgress) bool {
+	if recorded := GetNodePoolProgress(nodepool); recorded != nil &&
+		recorded.Nodes == current.Nodes && recorded.Provisioned == current.Provisioned {
+		return false
+	}
+
+	data, _ := json.Marshal(current)
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[ProgressAnnotation] = string(data)
+	nodepool.SetAnnotations(annotations)
+	return true
+}
+
+// ClearNodePoolProgress removes the recorded progress from the NodePool, returning true if there was any. The
+// NodePool is not updated on the cluster.
+func ClearNodePoolProgress(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	annotations := nodepool.GetAnnotations()
+	if _, exists := annotations[ProgressAnnotation]; !exists {
+		return false
+	}
+	delete(annotations, ProgressAnnotation)
+	nodepool.SetAnnotations(annotations)
+	return true
+}
+
+// IsNodePoolStalled returns true if the time since the NodePool last made progress exceeds the stall timeout of the
+// hardware manager
+func IsNodePoolStalled(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool, now time.Time) bool {
+	timeout, enabled := GetStallTimeout(hwmgr)
+	if !enabled {
+		return false
+	}
+
+	progress := GetNodePoolProgress(nodepool)
+	if progress == nil {
+		return false
+	}
+
+	return now.Sub(progress.Time) >= timeout
+}
+
+// GetNodePoolStallDiagnostics describes the nodegroups of a stalled NodePool that are short of nodes, or of
+// provisioned nodes, along with the last error reported by the backend, if any
+func GetNodePoolStallDiagnostics(nodepool *hwmgmtv1alpha1.NodePool, nodelist *hwmgmtv1alpha1.NodeList, lastError string) []string {
+	allocated := make(map[string]int)
+	provisioned := make(map[string]int)
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		allocated[node.Spec.GroupName]++
+		if meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+			provisioned[node.Spec.GroupName]++
+		}
+	}
+
+	var diagnostics []string
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		groupname := nodegroup.NodePoolData.Name
+		if allocated[groupname] < nodegroup.Size || provisioned[groupname] < nodegroup.Size {
+			diagnostics = append(diagnostics, fmt.Sprintf("nodegroup %s has %d of %d nodes allocated, %d provisioned",
+				groupname, allocated[groupname], nodegroup.Size, provisioned[groupname]))
+		}
+	}
+
+	if lastError != "" {
+		diagnostics = append(diagnostics, "last backend error: "+lastError)
+	}
+
+	return diagnostics
+}
+
+// UpdateNodePoolStalledCondition sets the Stalled condition of the NodePool, returning true if the NodePool has newly
+// stalled. The update is skipped if the condition is unchanged.
+func UpdateNodePoolStalledCondition(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	stalled bool,
+	since time.Time,
+	diagnostics []string) (bool, error) {
+
+	reason := ReasonProgressing
+	status := metav1.ConditionFalse
+	message := "NodePool is making progress"
+	if stalled {
+		reason = ReasonNoProgress
+		status = metav1.ConditionTrue
+		message = "No progress since " + since.UTC().Format(time.RFC3339)
+		if len(diagnostics) > 0 {
+			message += ": " + strings.Join(diagnostics, "; ")
+		}
+	}
+
+	current := meta.FindStatusCondition(nodepool.Status.Conditions, string(NodePoolStalled))
+	if current == nil && !stalled {
+		// The condition is only reported once a NodePool has stalled
+		return false, nil
+	}
+	if current != nil && current.Reason == string(reason) && current.Message == message {
+		return false, nil
+	}
+
+	newlyStalled := stalled && (current == nil || current.Status != metav1.ConditionTrue)
+	if err := UpdateNodePoolStatusCondition(ctx, c, nodepool, NodePoolStalled, reason, status, message); err != nil {
+		return false, err
+	}
+	return newlyStalled, nil
+}
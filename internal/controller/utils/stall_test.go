@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("NodePool stall detection", func() {
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	It("only reports a stall when enabled and the timeout has elapsed", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		nodepool := newTestNodePool(nil)
+		Expect(RecordNodePoolProgress(nodepool, NodePoolProgress{Time: now})).To(BeTrue())
+		Expect(IsNodePoolStalled(hwmgr, nodepool, now.Add(time.Hour))).To(BeFalse())
+
+		hwmgr.Spec.StallDetection = &pluginv1alpha1.StallDetectionConfig{}
+		Expect(IsNodePoolStalled(hwmgr, nodepool, now.Add(29*time.Minute))).To(BeFalse())
+		Expect(IsNodePoolStalled(hwmgr, nodepool, now.Add(30*time.Minute))).To(BeTrue())
+
+		hwmgr.Spec.StallDetection.Timeout = &metav1.Duration{Duration: 2 * time.Hour}
+		Expect(IsNodePoolStalled(hwmgr, nodepool, now.Add(time.Hour))).To(BeFalse())
+	})
+
+	It("only records progress when it changes", func() {
+		nodepool := newTestNodePool(nil)
+		nodelist := newTestNodeList("master")
+		Expect(RecordNodePoolProgress(nodepool, CountNodePoolProgress(nodelist, now))).To(BeTrue())
+		Expect(RecordNodePoolProgress(nodepool, CountNodePoolProgress(nodelist, now.Add(time.Minute)))).To(BeFalse())
+		Expect(GetNodePoolProgress(nodepool).Time).To(Equal(now))
+
+		SetStatusCondition(&nodelist.Items[0].Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.Completed), metav1.ConditionTrue, "")
+		Expect(RecordNodePoolProgress(nodepool, CountNodePoolProgress(nodelist, now.Add(time.Minute)))).To(BeTrue())
+		Expect(*GetNodePoolProgress(nodepool)).To(Equal(NodePoolProgress{Nodes: 1, Provisioned: 1, Time: now.Add(time.Minute)}))
+
+		Expect(ClearNodePoolProgress(nodepool)).To(BeTrue())
+		Expect(GetNodePoolProgress(nodepool)).To(BeNil())
+		Expect(ClearNodePoolProgress(nodepool)).To(BeFalse())
+	})
+
+	It("describes the nodegroups that are short and the last backend error", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Spec.NodeGroup[1].Size = 2
+		nodelist := newTestNodeList("master", "worker")
+		SetStatusCondition(&nodelist.Items[0].Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.Completed), metav1.ConditionTrue, "")
+
+		Expect(GetNodePoolStallDiagnostics(nodepool, nodelist, "")).To(Equal([]string{
+			"nodegroup worker has 1 of 2 nodes allocated, 0 provisioned",
+		}))
+		Expect(GetNodePoolStallDiagnostics(nodepool, nodelist, "backend unavailable")).To(Equal([]string{
+			"nodegroup worker has 1 of 2 nodes allocated, 0 provisioned",
+			"last backend error: backend unavailable",
+		}))
+	})
+
+	It("only considers NodePools that have neither completed nor failed as processing", func() {
+		nodepool := newTestNodePool(nil)
+		Expect(IsNodePoolProcessing(nodepool)).To(BeTrue())
+
+		SetStatusCondition(&nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.InProgress), metav1.ConditionFalse, "")
+		Expect(IsNodePoolProcessing(nodepool)).To(BeTrue())
+
+		SetStatusCondition(&nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned),
+			string(hwmgmtv1alpha1.Failed), metav1.ConditionFalse, "")
+		Expect(IsNodePoolProcessing(nodepool)).To(BeFalse())
+	})
+})
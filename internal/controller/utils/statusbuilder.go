@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// nodePoolCondition is a condition queued by a NodePoolStatusBuilder
+type nodePoolCondition struct {
+	conditionType   hwmgmtv1alpha1.ConditionType
+	conditionReason hwmgmtv1alpha1.ConditionReason
+	conditionStatus metav1.ConditionStatus
+	message         string
+}
+
+// NodePoolStatusBuilder accumulates changes to the status of a NodePool, so that they are applied with a single status
+// update rather than one update per change. The changes are made to the NodePool passed to the builder as well as to
+// the NodePool on the cluster.
+type NodePoolStatusBuilder struct {
+	nodepool           *hwmgmtv1alpha1.NodePool
+	conditions         []nodePoolCondition
+	observedGeneration bool
+	properties         *hwmgmtv1alpha1.Properties
+}
+
+// NewNodePoolStatusBuilder returns a status builder for the NodePool
+func NewNodePoolStatusBuilder(nodepool *hwmgmtv1alpha1.NodePool) *NodePoolStatusBuilder {
+	return &NodePoolStatusBuilder{nodepool: nodepool}
+}
+
+// WithCondition sets a condition of the NodePool. Conditions are set in the order they are added.
+func (b *NodePoolStatusBuilder) WithCondition(
+	conditionType hwmgmtv1alpha1.ConditionType,
+	conditionReason hwmgmtv1alpha1.ConditionReason,
+	conditionStatus metav1.ConditionStatus,
+	message string) *NodePoolStatusBuilder {
+
+	b.conditions = append(b.conditions, nodePoolCondition{
+		conditionType:   conditionType,
+		conditionReason: conditionReason,
+		conditionStatus: conditionStatus,
+		message:         message,
+	})
+	return b
+}
+
+// WithProvisioned sets the Provisioned condition of the NodePool. The status is derived from the reason, being True
+// only once provisioning has completed, so that the reason and status are consistent across adaptors.
+func (b *NodePoolStatusBuilder) WithProvisioned(reason hwmgmtv1alpha1.ConditionReason, message string) *NodePoolStatusBuilder {
+	status := metav1.ConditionFalse
+	if reason == hwmgmtv1alpha1.Completed {
+		status = metav1.ConditionTrue
+	}
+	return b.WithCondition(hwmgmtv1alpha1.Provisioned, reason, status, message)
+}
+
+// WithConfigApplied sets the Configured condition of the NodePool, reporting that the requested configuration has been
+// applied to all nodes
+func (b *NodePoolStatusBuilder) WithConfigApplied() *NodePoolStatusBuilder {
+	return b.WithCondition(hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.ConfigApplied, metav1.ConditionTrue,
+		string(hwmgmtv1alpha1.ConfigSuccess))
+}
+
+// WithObservedGeneration records the generation of the NodePool as observed by the plugin
+func (b *NodePoolStatusBuilder) WithObservedGeneration() *NodePoolStatusBuilder {
+	b.observedGeneration = true
+	return b
+}
+
+// WithProperties sets the properties of the NodePool
+func (b *NodePoolStatusBuilder) WithProperties(properties hwmgmtv1alpha1.Properties) *NodePoolStatusBuilder {
+	b.properties = properties.DeepCopy()
+	return b
+}
+
+// apply makes the queued changes to the status of the given NodePool
+func (b *NodePoolStatusBuilder) apply(nodepool *hwmgmtv1alpha1.NodePool) {
+	for _, condition := range b.conditions {
+		SetStatusCondition(&nodepool.Status.Conditions,
+			string(condition.conditionType),
+			string(condition.conditionReason),
+			condition.conditionStatus,
+			condition.message)
+	}
+	if b.observedGeneration {
+		nodepool.Status.HwMgrPlugin.ObservedGeneration = nodepool.Generation
+	}
+	if b.properties != nil {
+		nodepool.Status.Properties = *b.properties.DeepCopy()
+	}
+}
+
+// Update applies the queued changes to the NodePool and updates its status on the cluster, retrying on conflict. The
+// NodePool conditions are an atomic list, so the changes are made to the latest copy of the NodePool rather than
+// applied, which would replace the conditions set by other controllers since the NodePool was read.
+func (b *NodePoolStatusBuilder) Update(ctx context.Context, c client.Client) error {
+	b.apply(b.nodepool)
+
+	// nolint: wrapcheck
+	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
+		newNodepool := &hwmgmtv1alpha1.NodePool{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(b.nodepool), newNodepool); err != nil {
+			return err
+		}
+		b.apply(newNodepool)
+		if err := c.Status().Update(ctx, newNodepool); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to update status of nodepool %s: %w", b.nodepool.Name, err)
+	}
+
+	return nil
+}
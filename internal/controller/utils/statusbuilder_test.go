@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("NodePool status builder", func() {
+	It("applies the queued changes to the status", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Generation = 3
+
+		NewNodePoolStatusBuilder(nodepool).
+			WithProvisioned(hwmgmtv1alpha1.Completed, "Created").
+			WithConfigApplied().
+			WithObservedGeneration().
+			WithProperties(hwmgmtv1alpha1.Properties{NodeNames: []string{"node-1"}}).
+			apply(nodepool)
+
+		provisioned := meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+		Expect(provisioned).ToNot(BeNil())
+		Expect(provisioned.Status).To(Equal(metav1.ConditionTrue))
+		Expect(provisioned.Reason).To(Equal(string(hwmgmtv1alpha1.Completed)))
+		Expect(meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Configured))).To(BeTrue())
+		Expect(nodepool.Status.HwMgrPlugin.ObservedGeneration).To(Equal(int64(3)))
+		Expect(nodepool.Status.Properties.NodeNames).To(Equal([]string{"node-1"}))
+	})
+
+	It("derives the Provisioned status from the reason", func() {
+		nodepool := newTestNodePool(nil)
+		for _, reason := range []hwmgmtv1alpha1.ConditionReason{hwmgmtv1alpha1.InProgress, hwmgmtv1alpha1.Failed} {
+			NewNodePoolStatusBuilder(nodepool).WithProvisioned(reason, "").apply(nodepool)
+			Expect(meta.IsStatusConditionFalse(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))).To(BeTrue())
+		}
+	})
+
+	It("leaves unrequested fields unchanged", func() {
+		nodepool := newTestNodePool(nil)
+		nodepool.Generation = 2
+		nodepool.Status.HwMgrPlugin.ObservedGeneration = 1
+		nodepool.Status.Properties.NodeNames = []string{"node-1"}
+
+		NewNodePoolStatusBuilder(nodepool).WithProvisioned(hwmgmtv1alpha1.InProgress, "Handling creation").apply(nodepool)
+		Expect(nodepool.Status.HwMgrPlugin.ObservedGeneration).To(Equal(int64(1)))
+		Expect(nodepool.Status.Properties.NodeNames).To(Equal([]string{"node-1"}))
+	})
+})
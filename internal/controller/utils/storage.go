@@ -0,0 +1,175 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// StorageConfigured condition type and reasons, set on a Node allocated with a hardware profile that defines a storage
+// layout. The message describes the applied layout.
+const (
+	NodeStorageConfigured   hwmgmtv1alpha1.ConditionType   = "StorageConfigured"
+	ReasonStorageConfigured hwmgmtv1alpha1.ConditionReason = "Configured"
+	ReasonStorageFailed     hwmgmtv1alpha1.ConditionReason = "Failed"
+)
+
+// minPhysicalDisks is the minimum number of physical disks required for each RAID level
+var minPhysicalDisks = map[pluginv1alpha1.RAIDLevel]int{
+	pluginv1alpha1.RAID0:  1,
+	pluginv1alpha1.RAID1:  2,
+	pluginv1alpha1.RAID5:  3,
+	pluginv1alpha1.RAID6:  4,
+	pluginv1alpha1.RAID10: 4,
+}
+
+// GetVirtualDiskPhysicalDisks returns the number of physical disks used by the virtual disk, defaulting to the minimum
+// required for its RAID level
+func GetVirtualDiskPhysicalDisks(disk pluginv1alpha1.VirtualDisk) int {
+	if disk.PhysicalDisks == 0 {
+		return minPhysicalDisks[disk.RAIDLevel]
+	}
+	return disk.PhysicalDisks
+}
+
+// GetStorageLayoutPhysicalDisks returns the total number of physical disks required by the storage layout
+func GetStorageLayoutPhysicalDisks(layout *pluginv1alpha1.StorageLayout) int {
+	if layout == nil {
+		return 0
+	}
+
+	total := 0
+	for _, disk := range layout.VirtualDisks {
+		total += GetVirtualDiskPhysicalDisks(disk)
+	}
+	return total
+}
+
+// GetHwProfileStorageLayout returns the storage layout defined for a hardware profile, or nil if none is defined
+func GetHwProfileStorageLayout(hwmgr *pluginv1alpha1.HardwareManager, hwprofile string) *pluginv1alpha1.StorageLayout {
+	if profile := getHwProfile(hwmgr, hwprofile); profile != nil {
+		return profile.Storage
+	}
+	return nil
+}
+
+// ValidateStorageLayout validates that the virtual disks of a storage layout are uniquely named and have enough
+// physical disks for their RAID level, that at most one uses the remaining capacity, and that the boot disk hints
+// reference a defined virtual disk
+func ValidateStorageLayout(layout *pluginv1alpha1.StorageLayout) error {
+	if layout == nil {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	remaining := 0
+	for _, disk := range layout.VirtualDisks {
+		if disk.Name == "" {
+			return NewInputError("virtual disk name must not be empty")
+		}
+		if names[disk.Name] {
+			return NewInputError("duplicate virtual disk %s", disk.Name)
+		}
+		names[disk.Name] = true
+
+		required, known := minPhysicalDisks[disk.RAIDLevel]
+		if !known {
+			return NewInputError("unsupported RAID level %q for virtual disk %s", disk.RAIDLevel, disk.Name)
+		}
+		if disk.PhysicalDisks != 0 && disk.PhysicalDisks < required {
+			return NewInputError("virtual disk %s requires at least %d physical disks for %s",
+				disk.Name, required, disk.RAIDLevel)
+		}
+		if disk.RAIDLevel == pluginv1alpha1.RAID10 && GetVirtualDiskPhysicalDisks(disk)%2 != 0 {
+			return NewInputError("virtual disk %s requires an even number of physical disks for %s",
+				disk.Name, disk.RAIDLevel)
+		}
+
+		if disk.SizeGiB == 0 {
+			remaining++
+		}
+	}
+
+	if remaining > 1 {
+		return NewInputError("only one virtual disk may use the remaining capacity")
+	}
+
+	if layout.BootDisk != nil && layout.BootDisk.VirtualDisk != "" && !names[layout.BootDisk.VirtualDisk] {
+		return NewInputError("boot disk references unknown virtual disk %s", layout.BootDisk.VirtualDisk)
+	}
+
+	return nil
+}
+
+// DescribeStorageLayout returns a summary of the storage layout, as reported in the StorageConfigured condition
+func DescribeStorageLayout(layout *pluginv1alpha1.StorageLayout) string {
+	var parts []string
+	for _, disk := range layout.VirtualDisks {
+		size := "remaining capacity"
+		if disk.SizeGiB != 0 {
+			size = fmt.Sprintf("%dGiB", disk.SizeGiB)
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s %s on %d disks",
+			disk.Name, disk.RAIDLevel, size, GetVirtualDiskPhysicalDisks(disk)))
+	}
+
+	if hints := layout.BootDisk; hints != nil {
+		var boot []string
+		if hints.VirtualDisk != "" {
+			boot = append(boot, "virtualDisk="+hints.VirtualDisk)
+		}
+		if hints.DeviceName != "" {
+			boot = append(boot, "deviceName="+hints.DeviceName)
+		}
+		if hints.MinSizeGiB != 0 {
+			boot = append(boot, fmt.Sprintf("minSizeGiB=%d", hints.MinSizeGiB))
+		}
+		if len(boot) > 0 {
+			parts = append(parts, "boot disk: "+strings.Join(boot, ","))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SetNodeStorageCondition sets the StorageConfigured condition of the node, reporting the applied storage layout or
+// the error that prevented it from being applied. The status is not updated on the cluster.
+func SetNodeStorageCondition(node *hwmgmtv1alpha1.Node, layout *pluginv1alpha1.StorageLayout, applyErr error) {
+	if applyErr != nil {
+		SetStatusCondition(&node.Status.Conditions,
+			string(NodeStorageConfigured),
+			string(ReasonStorageFailed),
+			metav1.ConditionFalse,
+			"Failed to apply storage layout: "+applyErr.Error())
+		return
+	}
+
+	SetStatusCondition(&node.Status.Conditions,
+		string(NodeStorageConfigured),
+		string(ReasonStorageConfigured),
+		metav1.ConditionTrue,
+		"Applied storage layout: "+DescribeStorageLayout(layout))
+}
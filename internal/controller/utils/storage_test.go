@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+var _ = Describe("Storage layout", func() {
+	layout := &pluginv1alpha1.StorageLayout{
+		VirtualDisks: []pluginv1alpha1.VirtualDisk{
+			{Name: "os", RAIDLevel: pluginv1alpha1.RAID1, SizeGiB: 100},
+			{Name: "data", RAIDLevel: pluginv1alpha1.RAID5, PhysicalDisks: 4},
+		},
+		BootDisk: &pluginv1alpha1.BootDiskHints{VirtualDisk: "os"},
+	}
+
+	It("looks up the layout of a hardware profile", func() {
+		hwmgr := &pluginv1alpha1.HardwareManager{
+			Spec: pluginv1alpha1.HardwareManagerSpec{
+				HwProfiles: []pluginv1alpha1.HardwareProfile{{Name: "profile-1", Storage: layout}},
+			},
+		}
+		Expect(GetHwProfileStorageLayout(hwmgr, "profile-1")).To(Equal(layout))
+		Expect(GetHwProfileStorageLayout(hwmgr, "profile-2")).To(BeNil())
+	})
+
+	It("counts the physical disks required", func() {
+		Expect(GetStorageLayoutPhysicalDisks(layout)).To(Equal(6))
+		Expect(GetStorageLayoutPhysicalDisks(nil)).To(Equal(0))
+	})
+
+	It("validates the layout", func() {
+		Expect(ValidateStorageLayout(nil)).To(Succeed())
+		Expect(ValidateStorageLayout(layout)).To(Succeed())
+
+		invalid := []pluginv1alpha1.StorageLayout{
+			{VirtualDisks: []pluginv1alpha1.VirtualDisk{{Name: "os", RAIDLevel: pluginv1alpha1.RAID1, SizeGiB: 1}, {Name: "os", RAIDLevel: pluginv1alpha1.RAID0, SizeGiB: 1}}},
+			{VirtualDisks: []pluginv1alpha1.VirtualDisk{{Name: "os", RAIDLevel: pluginv1alpha1.RAID5, PhysicalDisks: 2}}},
+			{VirtualDisks: []pluginv1alpha1.VirtualDisk{{Name: "os", RAIDLevel: pluginv1alpha1.RAID10, PhysicalDisks: 5}}},
+			{VirtualDisks: []pluginv1alpha1.VirtualDisk{{Name: "os", RAIDLevel: pluginv1alpha1.RAID1}, {Name: "data", RAIDLevel: pluginv1alpha1.RAID0}}},
+			{VirtualDisks: []pluginv1alpha1.VirtualDisk{{Name: "os", RAIDLevel: "RAID7"}}},
+			{BootDisk: &pluginv1alpha1.BootDiskHints{VirtualDisk: "os"}},
+		}
+		for i := range invalid {
+			Expect(ValidateStorageLayout(&invalid[i])).ToNot(Succeed())
+		}
+	})
+
+	It("reports the applied layout on the node", func() {
+		node := &hwmgmtv1alpha1.Node{}
+		SetNodeStorageCondition(node, layout, nil)
+		condition := meta.FindStatusCondition(node.Status.Conditions, string(NodeStorageConfigured))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(Equal("Applied storage layout: os: RAID1 100GiB on 2 disks; " +
+			"data: RAID5 remaining capacity on 4 disks; boot disk: virtualDisk=os"))
+
+		SetNodeStorageCondition(node, layout, errors.New("not enough disks"))
+		condition = meta.FindStatusCondition(node.Status.Conditions, string(NodeStorageConfigured))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(string(ReasonStorageFailed)))
+	})
+})
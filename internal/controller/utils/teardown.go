@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Teardown condition type and reasons, set on a deleted NodePool while the release of its nodes is deferred
+const (
+	NodePoolTeardown         hwmgmtv1alpha1.ConditionType   = "Teardown"
+	ReasonWaitingForWorkers  hwmgmtv1alpha1.ConditionReason = "WaitingForWorkers"
+	ReasonReleaseRateLimited hwmgmtv1alpha1.ConditionReason = "RateLimited"
+)
+
+// ControlPlaneRole is the nodegroup role of the control plane nodes
+const ControlPlaneRole = "master"
+
+// IsControlPlaneNodeGroup returns true if the nodegroup holds control plane nodes
+func IsControlPlaneNodeGroup(nodegroup *hwmgmtv1alpha1.NodeGroup) bool {
+	return nodegroup.NodePoolData.Role == ControlPlaneRole
+}
+
+// HasControlPlaneNodeGroup returns true if any nodegroup of the NodePool holds control plane nodes
+func HasControlPlaneNodeGroup(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return slices.ContainsFunc(nodepool.Spec.NodeGroup, func(nodegroup hwmgmtv1alpha1.NodeGroup) bool {
+		return IsControlPlaneNodeGroup(&nodegroup)
+	})
+}
+
+// GetNodePoolTeardownBlockers returns the names of the NodePools whose release must complete before that of the
+// specified NodePool. When a cloud is torn down, a NodePool with control plane nodes is released only once the other
+// deleted NodePools of the cloud that hold only worker nodes have been released. NodePools that are not being deleted
+// do not hold up the release, and NodePools with only worker nodes are never held up, so the ordering cannot deadlock.
+func GetNodePoolTeardownBlockers(nodepool *hwmgmtv1alpha1.NodePool, nodepools []hwmgmtv1alpha1.NodePool) []string {
+	if !HasControlPlaneNodeGroup(nodepool) {
+		return nil
+	}
+
+	var blockers []string
+	for i := range nodepools {
+		other := &nodepools[i]
+		if other.Name == nodepool.Name || other.Spec.CloudID != nodepool.Spec.CloudID {
+			continue
+		}
+		if other.GetDeletionTimestamp() == nil || !controllerutil.ContainsFinalizer(other, NodepoolFinalizer) {
+			continue
+		}
+		if HasControlPlaneNodeGroup(other) {
+			continue
+		}
+		blockers = append(blockers, other.Name)
+	}
+
+	slices.Sort(blockers)
+	return blockers
+}
+
+// SortNodesForTeardown orders the nodes of a NodePool for release, with worker nodes ahead of control plane nodes
+func SortNodesForTeardown(nodepool *hwmgmtv1alpha1.NodePool, nodes []hwmgmtv1alpha1.Node) {
+	controlPlane := make(map[string]bool)
+	for i := range nodepool.Spec.NodeGroup {
+		nodegroup := &nodepool.Spec.NodeGroup[i]
+		controlPlane[nodegroup.NodePoolData.Name] = IsControlPlaneNodeGroup(nodegroup)
+	}
+
+	slices.SortStableFunc(nodes, func(a, b hwmgmtv1alpha1.Node) int {
+		switch {
+		case controlPlane[a.Spec.GroupName] == controlPlane[b.Spec.GroupName]:
+			return 0
+		case controlPlane[b.Spec.GroupName]:
+			return -1
+		default:
+			return 1
+		}
+	})
+}
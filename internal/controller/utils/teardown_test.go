@@ -0,0 +1,80 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// newTeardownNodePool returns a deleted NodePool of the test cloud, with a nodegroup for each role
+func newTeardownNodePool(name string, roles ...string) hwmgmtv1alpha1.NodePool {
+	now := metav1.Now()
+	nodepool := hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: name, DeletionTimestamp: &now, Finalizers: []string{NodepoolFinalizer}},
+		Spec:       hwmgmtv1alpha1.NodePoolSpec{CloudID: "testcloud"},
+	}
+	for _, role := range roles {
+		nodepool.Spec.NodeGroup = append(nodepool.Spec.NodeGroup, hwmgmtv1alpha1.NodeGroup{
+			NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: role, Role: role},
+		})
+	}
+	return nodepool
+}
+
+var _ = Describe("NodePool teardown", func() {
+	It("releases the control plane after the deleted worker NodePools of the cloud", func() {
+		controlPlane := newTeardownNodePool("np-cp", "master")
+		workers := newTeardownNodePool("np-workers", "worker")
+		mixed := newTeardownNodePool("np-mixed", "master", "worker")
+		other := newTeardownNodePool("np-other", "worker")
+		other.Spec.CloudID = "othercloud"
+		retained := newTeardownNodePool("np-retained", "worker")
+		retained.DeletionTimestamp = nil
+		nodepools := []hwmgmtv1alpha1.NodePool{controlPlane, workers, mixed, other, retained}
+
+		Expect(GetNodePoolTeardownBlockers(&controlPlane, nodepools)).To(Equal([]string{"np-workers"}))
+		Expect(GetNodePoolTeardownBlockers(&mixed, nodepools)).To(Equal([]string{"np-workers"}))
+		Expect(GetNodePoolTeardownBlockers(&workers, nodepools)).To(BeEmpty())
+
+		// A released NodePool no longer holds up the teardown
+		nodepools[1].Finalizers = nil
+		Expect(GetNodePoolTeardownBlockers(&controlPlane, nodepools)).To(BeEmpty())
+	})
+
+	It("orders worker nodes ahead of control plane nodes", func() {
+		nodepool := newTeardownNodePool("np", "master", "worker")
+		nodes := []hwmgmtv1alpha1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-1"}, Spec: hwmgmtv1alpha1.NodeSpec{GroupName: "master"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}, Spec: hwmgmtv1alpha1.NodeSpec{GroupName: "worker"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "cp-2"}, Spec: hwmgmtv1alpha1.NodeSpec{GroupName: "master"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker-2"}, Spec: hwmgmtv1alpha1.NodeSpec{GroupName: "worker"}},
+		}
+
+		SortNodesForTeardown(&nodepool, nodes)
+
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		Expect(names).To(Equal([]string{"worker-1", "worker-2", "cp-1", "cp-2"}))
+	})
+})
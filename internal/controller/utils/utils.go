@@ -46,12 +46,21 @@ const (
 	JobIdAnnotation = "hwmgr-plugin.oran.openshift.io/jobId"
 )
 
-func UpdateK8sCRStatus(ctx context.Context, c client.Client, object client.Object) error {
+// UpdateK8sCRStatus makes the status changes of mutate to the object and updates its status on the cluster, as the
+// field owner of the client. The status conditions of the CRs are atomic lists, so the update is made with an
+// optimistic lock: on a conflict, the latest copy of the object is read and mutate is called again to make the changes
+// to it, retrying, so that the conditions set by other writers since the object was read are not replaced.
+func UpdateK8sCRStatus(ctx context.Context, c client.Client, object client.Object, mutate func()) error {
+	mutate()
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		if err := c.Status().Update(ctx, object); err != nil {
-			return fmt.Errorf("failed to update status: %w", err)
+		err := c.Status().Update(ctx, object, client.FieldOwner(GetFieldOwner(c)))
+		if errors.IsConflict(err) {
+			if getErr := c.Get(ctx, client.ObjectKeyFromObject(object), object); getErr != nil {
+				return getErr // nolint: wrapcheck
+			}
+			mutate()
 		}
-		return nil
+		return err // nolint: wrapcheck
 	})
 
 	if err != nil {
@@ -226,7 +235,8 @@ func GetJobId(object client.Object) string {
 	return annotations[JobIdAnnotation]
 }
 
-func SetJobId(object client.Object, jobId string) {
+// SetJobId records the jobId annotation on the object, adding the job to its job references
+func SetJobId(object client.Object, jobId, operation string) {
 	annotations := object.GetAnnotations()
 	if annotations == nil {
 		annotations = make(map[string]string)
@@ -234,11 +244,16 @@ func SetJobId(object client.Object, jobId string) {
 
 	annotations[JobIdAnnotation] = jobId
 	object.SetAnnotations(annotations)
+	addJobRef(object, jobId, operation)
 }
 
+// ClearJobId removes the jobId annotation from the object, marking the job as completed in its job references
 func ClearJobId(object client.Object) {
 	annotations := object.GetAnnotations()
 	if annotations != nil {
+		if jobId, exists := annotations[JobIdAnnotation]; exists {
+			completeJobRef(object, jobId)
+		}
 		delete(annotations, JobIdAnnotation)
 	}
 }
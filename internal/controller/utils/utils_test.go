@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// nodeStatusClient stores a single Node, rejecting status updates made against a stale resourceVersion as the API
+// server does
+type nodeStatusClient struct {
+	client.Client
+	node    *hwmgmtv1alpha1.Node
+	updates int
+}
+
+func (c *nodeStatusClient) Get(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	c.node.DeepCopyInto(obj.(*hwmgmtv1alpha1.Node))
+	return nil
+}
+
+func (c *nodeStatusClient) Status() client.SubResourceWriter {
+	return &nodeStatusWriter{parent: c}
+}
+
+type nodeStatusWriter struct {
+	client.SubResourceWriter
+	parent *nodeStatusClient
+}
+
+func (w *nodeStatusWriter) Update(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+	node := obj.(*hwmgmtv1alpha1.Node)
+	if node.ResourceVersion != w.parent.node.ResourceVersion {
+		return k8serrors.NewConflict(hwmgmtv1alpha1.GroupVersion.WithResource("nodes").GroupResource(), node.Name,
+			nil)
+	}
+
+	version, _ := strconv.Atoi(w.parent.node.ResourceVersion)
+	w.parent.node.Status = *node.Status.DeepCopy()
+	w.parent.node.ResourceVersion = strconv.Itoa(version + 1)
+	node.ResourceVersion = w.parent.node.ResourceVersion
+	w.parent.updates++
+	return nil
+}
+
+var _ = Describe("Status updates", func() {
+	var (
+		ctx context.Context
+		c   *nodeStatusClient
+	)
+
+	// read returns a copy of the Node, as a writer with a cached copy holds
+	read := func() *hwmgmtv1alpha1.Node {
+		node := &hwmgmtv1alpha1.Node{}
+		Expect(c.Get(ctx, client.ObjectKey{}, node)).To(Succeed())
+		return node
+	}
+
+	setCondition := func(node *hwmgmtv1alpha1.Node, conditionType hwmgmtv1alpha1.ConditionType) {
+		SetStatusCondition(&node.Status.Conditions, string(conditionType), string(hwmgmtv1alpha1.Completed),
+			metav1.ConditionTrue, "Set")
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = &nodeStatusClient{node: &hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", Namespace: "test", ResourceVersion: "1"},
+		}}
+	})
+
+	It("keeps the conditions of writers updating from stale copies", func() {
+		first, second := read(), read()
+
+		Expect(UpdateK8sCRStatus(ctx, c, first, func() {
+			setCondition(first, hwmgmtv1alpha1.Provisioned)
+		})).To(Succeed())
+		Expect(UpdateK8sCRStatus(ctx, c, second, func() {
+			setCondition(second, NodePowerControl)
+		})).To(Succeed())
+
+		node := read()
+		Expect(meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(node.Status.Conditions, string(NodePowerControl))).To(BeTrue())
+		Expect(node.ResourceVersion).To(Equal("3"))
+		Expect(second.ResourceVersion).To(Equal("3"))
+	})
+
+	It("updates a current copy without reading it again", func() {
+		node := read()
+		Expect(UpdateK8sCRStatus(ctx, c, node, func() {
+			node.Status.HwProfile = "profile"
+		})).To(Succeed())
+		Expect(c.updates).To(Equal(1))
+		Expect(read().Status.HwProfile).To(Equal("profile"))
+	})
+})
@@ -0,0 +1,59 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"slices"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// ReasonOutsideWatchNamespaces is the reason of the NotSelected condition of a NodePool outside the watch namespaces
+// of its hardware manager
+const ReasonOutsideWatchNamespaces hwmgmtv1alpha1.ConditionReason = "OutsideWatchNamespaces"
+
+// GetHardwareManagerWatchNamespaces returns the namespaces from which the hardware manager serves NodePools,
+// defaulting to the namespace of the HardwareManager
+func GetHardwareManagerWatchNamespaces(hwmgr *pluginv1alpha1.HardwareManager) []string {
+	if len(hwmgr.Spec.WatchNamespaces) == 0 {
+		return []string{hwmgr.Namespace}
+	}
+	return hwmgr.Spec.WatchNamespaces
+}
+
+// HardwareManagerWatchesNodePool returns true if the NodePool is in one of the watch namespaces of the hardware manager
+func HardwareManagerWatchesNodePool(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return slices.Contains(GetHardwareManagerWatchNamespaces(hwmgr), nodepool.Namespace)
+}
+
+// GetPluginWatchNamespaces returns the sorted namespaces to be watched by the plugin, which are the plugin namespace
+// and the watch namespaces of the HardwareManagers of the enabled adaptors. All adaptors are enabled if the list of
+// enabled adaptors is empty.
+func GetPluginWatchNamespaces(pluginNamespace string, hwmgrs []pluginv1alpha1.HardwareManager, enabledAdaptors []string) []string {
+	namespaces := []string{pluginNamespace}
+	for i := range hwmgrs {
+		hwmgr := &hwmgrs[i]
+		if len(enabledAdaptors) > 0 && !slices.Contains(enabledAdaptors, string(hwmgr.Spec.AdaptorID)) {
+			continue
+		}
+		namespaces = append(namespaces, GetHardwareManagerWatchNamespaces(hwmgr)...)
+	}
+
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// nodePoolListClient lists a fixed set of NodePools, honouring the namespace of the list options and recording the
+// namespaces listed
+type nodePoolListClient struct {
+	client.Client
+	nodepools []hwmgmtv1alpha1.NodePool
+	listed    []string
+}
+
+func (c *nodePoolListClient) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	options := &client.ListOptions{}
+	options.ApplyOptions(opts)
+	c.listed = append(c.listed, options.Namespace)
+
+	nodepools := list.(*hwmgmtv1alpha1.NodePoolList)
+	for i := range c.nodepools {
+		if options.Namespace == "" || c.nodepools[i].Namespace == options.Namespace {
+			nodepools.Items = append(nodepools.Items, *c.nodepools[i].DeepCopy())
+		}
+	}
+	return nil
+}
+
+var _ = Describe("HardwareManager watch namespaces", func() {
+	newHwmgr := func(adaptorID pluginv1alpha1.HardwareManagerAdaptorID, namespaces ...string) pluginv1alpha1.HardwareManager {
+		return pluginv1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "plugin"},
+			Spec:       pluginv1alpha1.HardwareManagerSpec{AdaptorID: adaptorID, WatchNamespaces: namespaces},
+		}
+	}
+
+	newNodePool := func(namespace, name, hwMgrId string) hwmgmtv1alpha1.NodePool {
+		return hwmgmtv1alpha1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       hwmgmtv1alpha1.NodePoolSpec{HwMgrId: hwMgrId},
+		}
+	}
+
+	It("defaults to the namespace of the HardwareManager", func() {
+		hwmgr := newHwmgr(pluginv1alpha1.SupportedAdaptors.Loopback)
+		Expect(GetHardwareManagerWatchNamespaces(&hwmgr)).To(Equal([]string{"plugin"}))
+
+		nodepool := newTestNodePool(nil)
+		nodepool.Namespace = "plugin"
+		Expect(HardwareManagerWatchesNodePool(&hwmgr, nodepool)).To(BeTrue())
+		nodepool.Namespace = "tenant-a"
+		Expect(HardwareManagerWatchesNodePool(&hwmgr, nodepool)).To(BeFalse())
+	})
+
+	It("restricts the NodePools to the listed namespaces", func() {
+		hwmgr := newHwmgr(pluginv1alpha1.SupportedAdaptors.Loopback, "tenant-a")
+
+		nodepool := newTestNodePool(nil)
+		nodepool.Namespace = "tenant-a"
+		Expect(HardwareManagerWatchesNodePool(&hwmgr, nodepool)).To(BeTrue())
+		nodepool.Namespace = "plugin"
+		Expect(HardwareManagerWatchesNodePool(&hwmgr, nodepool)).To(BeFalse())
+	})
+
+	It("watches the namespaces of the HardwareManagers of the enabled adaptors", func() {
+		hwmgrs := []pluginv1alpha1.HardwareManager{
+			newHwmgr(pluginv1alpha1.SupportedAdaptors.Loopback, "tenant-b", "tenant-a"),
+			newHwmgr(pluginv1alpha1.SupportedAdaptors.Dell, "tenant-c"),
+			newHwmgr(pluginv1alpha1.SupportedAdaptors.Rest),
+		}
+
+		Expect(GetPluginWatchNamespaces("plugin", hwmgrs, nil)).To(Equal([]string{"plugin", "tenant-a", "tenant-b", "tenant-c"}))
+		Expect(GetPluginWatchNamespaces("plugin", hwmgrs, []string{"loopback"})).To(Equal([]string{"plugin", "tenant-a", "tenant-b"}))
+		Expect(GetPluginWatchNamespaces("plugin", nil, nil)).To(Equal([]string{"plugin"}))
+	})
+
+	It("maps a HardwareManager to its NodePools in each of its watch namespaces", func() {
+		c := &nodePoolListClient{
+			nodepools: []hwmgmtv1alpha1.NodePool{
+				newNodePool("tenant-a", "np1", "hwmgr"),
+				newNodePool("tenant-a", "np2", "other"),
+				newNodePool("tenant-b", "np3", "hwmgr"),
+				newNodePool("tenant-c", "np4", "hwmgr"),
+			},
+		}
+
+		hwmgr := newHwmgr(pluginv1alpha1.SupportedAdaptors.Loopback, "tenant-a", "tenant-b")
+		hwmgr.Name = "hwmgr"
+
+		requests := MapHardwareManagerToNodePools(c)(context.Background(), &hwmgr)
+		Expect(requests).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: "tenant-a", Name: "np1"}},
+			{NamespacedName: types.NamespacedName{Namespace: "tenant-b", Name: "np3"}},
+		}))
+		Expect(c.listed).To(Equal([]string{"tenant-a", "tenant-b"}))
+	})
+
+	It("maps a HardwareManager without watch namespaces to the NodePools of its own namespace", func() {
+		c := &nodePoolListClient{
+			nodepools: []hwmgmtv1alpha1.NodePool{
+				newNodePool("plugin", "np1", "hwmgr"),
+				newNodePool("tenant-a", "np2", "hwmgr"),
+			},
+		}
+
+		hwmgr := newHwmgr(pluginv1alpha1.SupportedAdaptors.Loopback)
+		hwmgr.Name = "hwmgr"
+
+		Expect(MapHardwareManagerToNodePools(c)(context.Background(), &hwmgr)).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: "plugin", Name: "np1"}},
+		}))
+		Expect(c.listed).To(Equal([]string{"plugin"}))
+	})
+})
@@ -19,6 +19,9 @@ package logging
 import (
 	"context"
 	"log/slog"
+	"slices"
+
+	"github.com/google/uuid"
 )
 
 //
@@ -30,12 +33,24 @@ import (
 type loggingContextKey string
 
 const (
-	slogFields loggingContextKey = "slog_fields"
+	slogFields    loggingContextKey = "slog_fields"
+	correlationId loggingContextKey = "correlation_id"
 )
 
+const (
+	// CorrelationIdAttr is the slog attribute holding the correlation ID of a request
+	CorrelationIdAttr = "correlationId"
+
+	// CorrelationIdHeader is the HTTP header used to propagate the correlation ID to backend calls
+	CorrelationIdHeader = "X-Correlation-ID"
+)
+
+// LogLevel is the level shared by the plugin loggers, adjusted at runtime from the PluginConfig
+var LogLevel = new(slog.LevelVar)
+
 type LoggingContextHandler struct {
 	handler slog.Handler
-	level   slog.Level
+	level   slog.Leveler
 }
 
 // Handle adds attributes from the context to the log record
@@ -50,7 +65,7 @@ func (h LoggingContextHandler) Handle(ctx context.Context, record slog.Record) e
 }
 
 func (h LoggingContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 func (h LoggingContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
@@ -67,7 +82,7 @@ func (h LoggingContextHandler) WithGroup(name string) slog.Handler {
 	return LoggingContextHandler{handler: h.handler.WithGroup(name), level: h.level}
 }
 
-func NewLoggingContextHandler(level slog.Level) *LoggingContextHandler {
+func NewLoggingContextHandler(level slog.Leveler) *LoggingContextHandler {
 	return &LoggingContextHandler{
 		handler: slog.Default().Handler(),
 		level:   level,
@@ -82,7 +97,8 @@ func AppendCtx(ctx context.Context, attr slog.Attr) context.Context {
 	}
 
 	if v, ok := ctx.Value(slogFields).([]slog.Attr); ok {
-		v = append(v, attr)
+		// Clone the attributes, so that contexts derived from the same parent do not share the underlying array
+		v = append(slices.Clip(v), attr)
 		return context.WithValue(ctx, slogFields, v)
 	}
 
@@ -90,3 +106,27 @@ func AppendCtx(ctx context.Context, attr slog.Attr) context.Context {
 	v = append(v, attr)
 	return context.WithValue(ctx, slogFields, v)
 }
+
+// WithCorrelationId adds a correlation ID to the provided context, included in any Record created with such context
+// and propagated to backend calls
+func WithCorrelationId(ctx context.Context, id string) context.Context {
+	ctx = AppendCtx(ctx, slog.String(CorrelationIdAttr, id))
+	return context.WithValue(ctx, correlationId, id)
+}
+
+// GetCorrelationId returns the correlation ID from the provided context, or an empty string if none is set
+func GetCorrelationId(ctx context.Context) string {
+	if id, ok := ctx.Value(correlationId).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// NewReconcileContext returns a context with a new correlation ID for the processing of a single request, unless the
+// context already carries one
+func NewReconcileContext(ctx context.Context) context.Context {
+	if GetCorrelationId(ctx) != "" {
+		return ctx
+	}
+	return WithCorrelationId(ctx, uuid.NewString())
+}
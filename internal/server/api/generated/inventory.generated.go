@@ -56,6 +56,28 @@ type APIVersions struct {
 	UriPrefix   *string       `json:"uriPrefix,omitempty"`
 }
 
+// FreeResourceInfo Information about a free resource that could be allocated to a prospective nodegroup.
+type FreeResourceInfo struct {
+	// Cpus Number of CPUs of the resource, if reported by the backend.
+	Cpus *int `json:"cpus,omitempty"`
+
+	// Location Location of the resource, if reported by the backend.
+	Location *string `json:"location,omitempty"`
+
+	// MemoryGiB Amount of memory of the resource, in GiB, if reported by the backend.
+	MemoryGiB *int `json:"memoryGiB,omitempty"`
+
+	// NicModels NIC models present on the resource, if reported by the backend.
+	NicModels *[]string `json:"nicModels,omitempty"`
+
+	// ResourceId Identifier for the Resource.
+	ResourceId     string `json:"resourceId"`
+	ResourcePoolId string `json:"resourcePoolId"`
+
+	// SiteId Site of the resource, if reported by the backend.
+	SiteId *string `json:"siteId,omitempty"`
+}
+
 // ProblemDetails defines model for ProblemDetails.
 type ProblemDetails struct {
 	// AdditionalAttributes Any number of additional attributes, as defined in a specification or by an implementation.
@@ -128,6 +150,24 @@ type ResourcePoolInfo struct {
 	SiteId *string `json:"siteId,omitempty"`
 }
 
+// GetResourcePoolFreeResourcesParams defines parameters for GetResourcePoolFreeResources.
+type GetResourcePoolFreeResourcesParams struct {
+	// MinCpus Minimum number of CPUs of the node
+	MinCpus *int `form:"minCpus,omitempty" json:"minCpus,omitempty"`
+
+	// MinMemoryGiB Minimum amount of memory of the node, in GiB
+	MinMemoryGiB *int `form:"minMemoryGiB,omitempty" json:"minMemoryGiB,omitempty"`
+
+	// NicModels NIC models that must all be present on the node
+	NicModels *[]string `form:"nicModels,omitempty" json:"nicModels,omitempty"`
+
+	// Locations Locations, one of which the node must be in
+	Locations *[]string `form:"locations,omitempty" json:"locations,omitempty"`
+
+	// HwProfile Hardware profile of the nodegroup
+	HwProfile *string `form:"hwProfile,omitempty" json:"hwProfile,omitempty"`
+}
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// Get minor API versions
@@ -139,6 +179,9 @@ type ServerInterface interface {
 	// Retrieve exactly one resource pool
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId})
 	GetResourcePool(w http.ResponseWriter, r *http.Request, hwMgrId string, resourcePoolId string)
+	// Retrieve the free resources of a resource pool that could be allocated to a prospective nodegroup
+	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}/freeResources)
+	GetResourcePoolFreeResources(w http.ResponseWriter, r *http.Request, hwMgrId string, resourcePoolId string, params GetResourcePoolFreeResourcesParams)
 	// Retrieve the list of resources for a given resource pool
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}/resources)
 	GetResourcePoolResources(w http.ResponseWriter, r *http.Request, hwMgrId string, resourcePoolId string)
@@ -235,6 +278,83 @@ func (siw *ServerInterfaceWrapper) GetResourcePool(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r)
 }
 
+// GetResourcePoolFreeResources operation middleware
+func (siw *ServerInterfaceWrapper) GetResourcePoolFreeResources(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "hwMgrId" -------------
+	var hwMgrId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "hwMgrId", r.PathValue("hwMgrId"), &hwMgrId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "hwMgrId", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "resourcePoolId" -------------
+	var resourcePoolId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "resourcePoolId", r.PathValue("resourcePoolId"), &resourcePoolId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "resourcePoolId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetResourcePoolFreeResourcesParams
+
+	// ------------- Optional query parameter "minCpus" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minCpus", r.URL.Query(), &params.MinCpus)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "minCpus", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "minMemoryGiB" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "minMemoryGiB", r.URL.Query(), &params.MinMemoryGiB)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "minMemoryGiB", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "nicModels" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "nicModels", r.URL.Query(), &params.NicModels)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "nicModels", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "locations" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "locations", r.URL.Query(), &params.Locations)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "locations", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "hwProfile" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "hwProfile", r.URL.Query(), &params.HwProfile)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "hwProfile", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetResourcePoolFreeResources(w, r, hwMgrId, resourcePoolId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetResourcePoolResources operation middleware
 func (siw *ServerInterfaceWrapper) GetResourcePoolResources(w http.ResponseWriter, r *http.Request) {
 
@@ -465,6 +585,7 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/api_versions", wrapper.GetMinorVersions)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools", wrapper.GetResourcePools)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}", wrapper.GetResourcePool)
+	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}/freeResources", wrapper.GetResourcePoolFreeResources)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}/resources", wrapper.GetResourcePoolResources)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resources", wrapper.GetResources)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resources/{resourceId}", wrapper.GetResource)
@@ -596,6 +717,52 @@ func (response GetResourcePool500ApplicationProblemPlusJSONResponse) VisitGetRes
 	return json.NewEncoder(w).Encode(response)
 }
 
+type GetResourcePoolFreeResourcesRequestObject struct {
+	HwMgrId        string `json:"hwMgrId"`
+	ResourcePoolId string `json:"resourcePoolId"`
+	Params         GetResourcePoolFreeResourcesParams
+}
+
+type GetResourcePoolFreeResourcesResponseObject interface {
+	VisitGetResourcePoolFreeResourcesResponse(w http.ResponseWriter) error
+}
+
+type GetResourcePoolFreeResources200JSONResponse []FreeResourceInfo
+
+func (response GetResourcePoolFreeResources200JSONResponse) VisitGetResourcePoolFreeResourcesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetResourcePoolFreeResources400ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetResourcePoolFreeResources400ApplicationProblemPlusJSONResponse) VisitGetResourcePoolFreeResourcesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetResourcePoolFreeResources500ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetResourcePoolFreeResources500ApplicationProblemPlusJSONResponse) VisitGetResourcePoolFreeResourcesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetResourcePoolFreeResources501ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetResourcePoolFreeResources501ApplicationProblemPlusJSONResponse) VisitGetResourcePoolFreeResourcesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(501)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type GetResourcePoolResourcesRequestObject struct {
 	HwMgrId        string `json:"hwMgrId"`
 	ResourcePoolId string `json:"resourcePoolId"`
@@ -757,6 +924,9 @@ type StrictServerInterface interface {
 	// Retrieve exactly one resource pool
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId})
 	GetResourcePool(ctx context.Context, request GetResourcePoolRequestObject) (GetResourcePoolResponseObject, error)
+	// Retrieve the free resources of a resource pool that could be allocated to a prospective nodegroup
+	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}/freeResources)
+	GetResourcePoolFreeResources(ctx context.Context, request GetResourcePoolFreeResourcesRequestObject) (GetResourcePoolFreeResourcesResponseObject, error)
 	// Retrieve the list of resources for a given resource pool
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}/resources)
 	GetResourcePoolResources(ctx context.Context, request GetResourcePoolResourcesRequestObject) (GetResourcePoolResourcesResponseObject, error)
@@ -877,6 +1047,34 @@ func (sh *strictHandler) GetResourcePool(w http.ResponseWriter, r *http.Request,
 	}
 }
 
+// GetResourcePoolFreeResources operation middleware
+func (sh *strictHandler) GetResourcePoolFreeResources(w http.ResponseWriter, r *http.Request, hwMgrId string, resourcePoolId string, params GetResourcePoolFreeResourcesParams) {
+	var request GetResourcePoolFreeResourcesRequestObject
+
+	request.HwMgrId = hwMgrId
+	request.ResourcePoolId = resourcePoolId
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetResourcePoolFreeResources(ctx, request.(GetResourcePoolFreeResourcesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetResourcePoolFreeResources")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetResourcePoolFreeResourcesResponseObject); ok {
+		if err := validResponse.VisitGetResourcePoolFreeResourcesResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // GetResourcePoolResources operation middleware
 func (sh *strictHandler) GetResourcePoolResources(w http.ResponseWriter, r *http.Request, hwMgrId string, resourcePoolId string) {
 	var request GetResourcePoolResourcesRequestObject
@@ -984,37 +1182,46 @@ func (sh *strictHandler) GetAllVersions(w http.ResponseWriter, r *http.Request)
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xabXPbuBH+KztoP7RTWvLF6U2qb46dnDUXOx5bvptO7LmBiKWIlARYYClb9ei/dwC+",
-	"SqQtupc7O718k/iCfbD7PPuC4T0LdZpphYosm9wzG8aYcv/z8Hz6ExortXL/BNrQyIz8XzZVkTYpd/+A",
-	"z3VOwGFZPAw6AooRDs+no2vFApYZnaEhiX7VZbMk3vE0S5BN2Hej/dE+CxitMvfXkpFqwdbr+oqef8aQ",
-	"2DpoobLDYCXSksNUGrY78PFMttevMX5qQS/xrm8CJglT/+CfDUZswv40bvw5Lp05bnmy2RI3hq/c/9zI",
-	"c4ORvNv0yVi/kqndkyoy3JLJQ8oNTtUSFWmzGi+/G+avc6PnCabHSFwmHunWfoWQzl88OSQycp7T9vXz",
-	"jee3TAZbEThUK1B5Okfj/NwsArxePQBuQWAkFQqQCjjYDEMZybAInDYwXwFXIJ0nUlTkr49Yz+6E31aX",
-	"CIcQ5ylXewa54PMEAe+yhKvCQGUOSAPF0oIOw9wYVCFW5MgKrzmbTUSOtFIY+iVIg+DE59wikExRgM6p",
-	"G5CASWWJqxD7IF5dTMFghIVlijmBFKhIRhKth1EjfRghXKspQcpXsJKYCIhyQzEakC0pyAgE1pZEQfvi",
-	"Nps4/vUht8Qp79HYLEY4mc3OoXgAQi0QIm0GuLI2KVXLWVIRLtB4aUhKel1lY20o2A6qzdOUm9WWJXDr",
-	"jmBK7q08EaA0QRhztUCIjE7bGEk/jDi4VngXYkZ+d1luMm3Rp49EhzyR/yloCdPIWwRpYSGXqIArAdoH",
-	"gWKu4Jr5VDSZJ1z965oFhaNqPYCNeZIAT6yGuTe+lKIKUicqxYVdXOJhqI2QauE2OH03ew8X74/g4B9v",
-	"vodPBze9VOs4T1pAFerc8AWK4hX3nDNUYrTXaisgQod5LdiSFM3Sf8HRYgS5lWpxMjv98Fe4jVFtMhN+",
-	"dpe8g1L0WURaH7/MoEVFwbWSZGHJk9w7nFubO/WR992WpwsXNvqNiTI7GY8rRrZ8OAp1ulMT64AZ/Hcu",
-	"DQo2+VQJpE5CNz356QKtzk2IrjANK1emfGPUrUwileqSOGG/KP19aclwkkv08qwpXa3q/KHy1MG/Ovvw",
-	"8ejHd8csYJcnV7PZ9OyHX44//nzGAlbfuDr78cxdugl25P1tPCeOF9Dworm5jWgzxV7qdPPpwi2eEK09",
-	"dMAsEj3nyaG1SFPR4+iK68ZVF4tG8qRVptp4Apct+ZLLxCHfRHdn3ny/T3ehisTi1as+HIqnPdG5dNkL",
-	"3L1aE/3b323AEYIXJfURKrSe2smDd2eHbz/4aB9PL6ufjwW+WmOHo6udXvyPO63MnGudFKaat028l2md",
-	"7D3yem75AmsXVZudHn94xwJ2eDSb/uR+vL26/Oej293SfGvvHYSbkii5ELRl2xO+DaCPZRBv5MlZBJyb",
-	"uqnkC2m3Xv3XC7hfOFtQvIJ2YxjAji65BvMY3Duub3UXY27ELTcIKVd84duuot97MiIraaiiXPsxMBxG",
-	"5AdsKKlrHpfMbQPpUnPtu9uCkKFWxENyP4tAsgsUcMJdl5ebpFV7b29vRwZFzMmX3O78cD71+7Rolq5/",
-	"OakcfFo7uJyA6m27Elw2jqzzeD0wuXmPBd0ZzmtS8UyyCTsY7Y+cuzJOsdfJuIrvXhnfsWwNYGOeyV+W",
-	"rWFxgdQN4AVSblTRZFWzaCqVNg5RM5XWww6KpptO+WdtHhys62TieMN+QDp1y9bTq2d5ppUtNP9qf78K",
-	"FSoqRt0sKYeu8WdbpIJiZB0+0NqCCFv1Lg9DtPbaj7uvH7Vb9mJ/e5r9raG2B8JbLsBxHK3Pon9/FhBT",
-	"RWh8DUazRANojDYjr79ydCni1sMHx2m+sE6eKRJ3wya7cS/uoGR18T6+PV2YqViP2+pus7TDnouNB50K",
-	"DE+R0Fh/+tEkFatT3FuiEtrsVbmIuWTAJl46VQaZsBIEa+cbMjkGLRdv56abX8nbQScynaraOZd5kNVR",
-	"nkAF8MXw+/X+wTOAeK/NXAqBavTCNXaBZCQucSMJb5TNtuBqQX0JxY3vN8vreqgEn0+BweOtdo+VTgfx",
-	"+8n9aSr/2lT9+hlAzJqDRxSbKoFbXhzHRDpX4qtRPd7xkJIVaLXVLP9uoh83zepA+V+0uttveeALl/3/",
-	"h5L/goT3lGpr/XTDy6Py31qNg+T2R2l2v7H++Vj/W3C7qTID28oXUko6Z7+PVJIX2E1+6ySHgjirOsWv",
-	"RLl9fWJLuDaf10vbUrx9H2mcaiVJO0Y+/YTQbTJBajLIg4eE81XxiQCapQzxgRPBwyR5OeeBUZ44586J",
-	"+08/erdbbXX07fBw6OHhgGND945fpcj6m4Y+7h0lOhfdo3a38KV/beMYfzIe+w8gYm1p8mb/TfEhVGn2",
-	"vuc8v0LS/ialyfQ1Tlcktl1QHd63W7XyvaaMrm/W/w0AAP//SdcT+8cmAAA=",
+	"H4sIAAAAAAAC/+xba2/buNL+KwTf98M5OLKdJt0ix9/S9BJjk2yQyy4ONsGCFkcWuxKpHZJOfIr89wNS",
+	"F0sWndhtt0nRfot14TyceebCGeUjjVVeKAnSaDr+SHWcQs78nwdnk18BtVDS/eKgYxSF8T/pRCYKc+Z+",
+	"ETZV1hBG5uXDRCXEpEAOzibDa0kjWqAqAI0Av+p8uSTcsbzIgI7pi+HOcIdG1CwK91MbFHJG7++bK2r6",
+	"AWJD76MWKr0ZrExo4zBVgvUj+Fgh2us3GH9vQa/w3t9EVBjI/YP/j5DQMf2/0VKfo0qZo5Yml1tiiGzh",
+	"flsUZwiJuOvqZKR2Ra4HQibItEEbG4swkXOQRuFiNH+xmb7eIcA5aGUxBqeezZSWIADB6jViUmZIrGzG",
+	"yRQIyzIVMwOcGEUYKVDpAmIj5kCk4jBDZYthT69xYQMGO7X5FNCZ5PDsqjFNLTgiIiEIhUInbbrwN6cs",
+	"/hMkdxIaZb162excSAMzQLd1DzNI3+PqzicLpKhuBwELRDSHXOHivXjdl3qQKys9F8uHAtIleS9eb4xi",
+	"96dXoX1LEZ8oDllI35NDkvt7pEDQ4ODIT1PB7/Tt/osd2vaBnjJWqV4LmfAADTlIIxIBSBKFXmzN267q",
+	"73D/1Y65i2XCZ7u7IRvUYs6UykpRy7cxHRRKZYMHXtfCBBFeCAOfwRhu94Iui/CXFQjcqbSloN42bgLe",
+	"fYZqmkH+BgwTpb1XohnnwqFn2YExKKbWrF4/6zzf08UKg+WCyMZll4sQ1qweEaYJh0RI4I7PjLjoIBJR",
+	"exw6LTFJhNNLDtL460Ma2B332wo4EkltzuQAgXE2zYDAXZExWQqoxbnoZFKhiYpjiwgyboxXlFrr2udQ",
+	"SenCmPMHRTgzbMo0ECNy4ERZE2KKkNowGUMI4tX5hCAkUEr2IVTUFNceRoN0PUJyLSeG5GxBFgIyThKL",
+	"JgUkohWzRUI4NJJ4mdTK23TsskuQ44aZUEC+TIEcXV6ekfIBEisOlT8+pspGpJCGhuKSESYLqkqnCk20",
+	"alRt85zhYkUScesOycS4t1xGksqQOGVyBiRBlbcxGrUecXQt4S6GwvjdFRYLpcFnIJc2MvHfkpZkkniJ",
+	"RGgyE3OQhElOlDeCSZkk19TnzPE0Y/LPaxqVimr8geiUZRlhmVYudxao5oLXRloTLx/jEotjhVzImdvg",
+	"5O3lO3L+7pDs/Xv/Ffl97yZItZ7yhCYgY2WRzYCXr7jnnKAKo76WKwbhKraNwzZBul76HzCcDYnVQs6O",
+	"Lk+O/0luU5BdZpLf3CWvoBx8FBHa26/KRdG1FEaTOcusVzjT2uZlnTGFVU2XKlz6b2pMocejUc3Ilg6H",
+	"scof9YmVSFw5SBOEQtF3+7oKWxltNVLnQl4YZiDslP6+0AaZr7QcvF4ycvqQNnfwr06Pfzn8+e0bGtGL",
+	"o6vLy8np+z/e/PLbKY1oc+Pq9OdTd+kmeiTur+I5crwgS14sb64i6obYC5V3ny7V4gnR2kMPzCxTU5Yd",
+	"aA3mkcpBIdGAgmWtNNVL12zOROaQb1tXSJYHrHPhohdx9xqfwE8sXBwhWJlSH6BC66lHefD29OD1sbf2",
+	"m8lF/edDhv82SjSr2QwaFdWbnbw5fksjenB4OfnV/fH66uI/D253i+qr6xIVF6K22wbM1wH6UATxQraO",
+	"IsSpqR9KvpDvNqt/vgOHHWcFivegxzFswI4+uTbmMXHvuLrVXUwZ8luGQHIm2cyXXWW9tzWidUeKAJJs",
+	"zdk0pIptjhQNjyvmtoH0qXnvq9uSkLGShsXG/Vkakp4DJ0fMOH5j1sq9t7e3QwSeMuNTbv/8cDbx+9SA",
+	"c1e/HNUKPmkUXPU3mm1r2hSOtPd40w5x3Rwa9Ts03iclKwQd073hztCpq2Am9X4yqu07qOw7Eq32yogV",
+	"4o95qxU0A9M34DkYi7IssupOUy6kQodo2XNqDjvAl9V0zj4oXNs2a4KJ4w19D+bELdv0pjzLCyV16fO7",
+	"Ozu1qUCaspFVZNWha/RBl6GgbEht3q7SJRFW8p2NY9D62jezXj4ot6rF/rWd/JVDbQDCa8aJ4zhoH0V/",
+	"ehIQE2kAfQ4GnAMSQFQ49P5XHV1KuwX44DjNZtq5Zw6GucMmvXEvPkLJ+uLH9PZkhhN+P2p7d5ulPfac",
+	"dx50XoAsBwOofW9zGVS0ymEwB8kVDpoOmgsGdOxdp44gY1qBoO14Y9BC1FLxamy6+UzebtRv7WXVXitq",
+	"LasTm5Ea4LPh98udvScA8U7hVHAOcvjMfewcDAqYQycId9Jm2+Eah/oSHjf62E2v95u64NN5YPRwqR2Q",
+	"0qsgvp67b+fl35pXv3wCEJfLxiPwrpeQW1a2YxJlJf9mvB7uWGyyBVFypVj+ak4/SlpDtvW14rHQpqwU",
+	"YyVjBAPlnE0qDiRmkgvODOhg4V+26HJm4tTf9O9oyCA2Cn1PsjmsFKgSkfmj1JrpnB80VWO8sifPASNi",
+	"FEkhKwiHqZ217ydMZBZBE259U1VIbZNExAKk6Y4K9TBUubY99V1HVd9HFIxWmXAipMhtTmRw/Ons1D7n",
+	"7e1WgP6ygIslolzIw8L3KJei83JlOt7pd+DX42BrJpMOST2VbCN6sbu/FtJJMwD9dFytMWXJe6uNI2TZ",
+	"Qu/MLVeVtRxLuqGQu1mZJwR3OSmNQjXmI/PM+2jdXFlHPhqphNymou2xfiNTIEJ2MZfj5M1A187wpUAf",
+	"BQKHSVvhog2VVg8NdIED1+vPYFCgikFrhYNXL9/TMOj09qx8kT756aD3RcQ3fjp4Rjm6hPPiCeCcKkO0",
+	"LbpzeMZZ4dJjRejVdt5Dx4huVitzaSAhb/VRzNerRzBQizyYlb+3jPxV2xA/gswXDjKbn/6177ayanT/",
+	"d58ONnK376X59oP1T8f6v4PbyyyzYZvrmaSS3iz6gUzyDLtbPzpb25SAvnP1jXhuqG/Vclxtp83SunLe",
+	"0CfhJ0oKoxwjt59Yuk1mYJYRZO3Qsqqo3b5EDGsmlAdZ9nzmk4nNnHKnhvlPUYPbrbc6/DHM3HSYucEY",
+	"073jVymjflfQL4PDTFneH/27hS/8a53PCsajkf8gM1XajPd39st/u6jEfgx8X1AjaX8j22pQVXdpvw+y",
+	"/JigXapV7y3T6P3N/f8GAAslnus1MwAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -2,11 +2,27 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/sdk"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/inventory"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
 )
 
 type InventoryServer struct {
+	Client    client.Client
+	Namespace string
+	// Adaptor queries the adaptors for the free nodes and capacity of a hardware manager
+	Adaptor inventory.AdaptorInventory
 }
 
 // InventoryServer implements StrictServerInterface. This ensures that we've conformed to the `StrictServerInterface` with a compile-time check
@@ -44,27 +60,176 @@ func (i *InventoryServer) GetMinorVersions(_ context.Context, _ generated.GetMin
 	}), nil
 }
 
+// getHardwareManager returns the specified hardware manager, or nil if it does not exist
+func (i *InventoryServer) getHardwareManager(ctx context.Context, hwMgrId string) (*pluginv1alpha1.HardwareManager, error) {
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := i.Client.Get(ctx, types.NamespacedName{Name: hwMgrId, Namespace: i.Namespace}, hwmgr); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get hardware manager %s: %w", hwMgrId, err)
+	}
+	return hwmgr, nil
+}
+
+// getInventory collects the inventory for the specified hardware manager, returning nil if it does not exist
+func (i *InventoryServer) getInventory(ctx context.Context, hwMgrId string) (*inventory.Inventory, error) {
+	hwmgr, err := i.getHardwareManager(ctx, hwMgrId)
+	if err != nil || hwmgr == nil {
+		return nil, err
+	}
+
+	inv, err := inventory.Collect(ctx, i.Client, i.Adaptor, hwmgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect inventory for %s: %w", hwMgrId, err)
+	}
+
+	return inv, nil
+}
+
+func problemDetails(status int, detail string) generated.ProblemDetails {
+	return generated.ProblemDetails{
+		Status: status,
+		Detail: detail,
+	}
+}
+
 func (i *InventoryServer) GetResourcePools(ctx context.Context, request generated.GetResourcePoolsRequestObject) (generated.GetResourcePoolsResponseObject, error) {
-	// TODO implement me
-	return generated.GetResourcePools200JSONResponse([]generated.ResourcePoolInfo{}), nil
+	inv, err := i.getInventory(ctx, request.HwMgrId)
+	if err != nil {
+		return generated.GetResourcePools500ApplicationProblemPlusJSONResponse(problemDetails(http.StatusInternalServerError, err.Error())), nil
+	}
+	if inv == nil {
+		return generated.GetResourcePools400ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusBadRequest, "unknown hardware manager: "+request.HwMgrId)), nil
+	}
+	return generated.GetResourcePools200JSONResponse(inv.ResourcePools), nil
 }
 
 func (i *InventoryServer) GetResourcePool(ctx context.Context, request generated.GetResourcePoolRequestObject) (generated.GetResourcePoolResponseObject, error) {
-	// TODO implement me
-	return generated.GetResourcePool200JSONResponse{}, nil
+	inv, err := i.getInventory(ctx, request.HwMgrId)
+	if err != nil {
+		return generated.GetResourcePool500ApplicationProblemPlusJSONResponse(problemDetails(http.StatusInternalServerError, err.Error())), nil
+	}
+	if inv == nil {
+		return generated.GetResourcePool404ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusNotFound, "unknown hardware manager: "+request.HwMgrId)), nil
+	}
+	pool := inv.GetResourcePool(request.ResourcePoolId)
+	if pool == nil {
+		return generated.GetResourcePool404ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusNotFound, "unknown resource pool: "+request.ResourcePoolId)), nil
+	}
+	return generated.GetResourcePool200JSONResponse(*pool), nil
 }
 
 func (i *InventoryServer) GetResourcePoolResources(ctx context.Context, request generated.GetResourcePoolResourcesRequestObject) (generated.GetResourcePoolResourcesResponseObject, error) {
-	// TODO implement me
-	return generated.GetResourcePoolResources200JSONResponse([]generated.ResourceInfo{}), nil
+	inv, err := i.getInventory(ctx, request.HwMgrId)
+	if err != nil {
+		return generated.GetResourcePoolResources500ApplicationProblemPlusJSONResponse(problemDetails(http.StatusInternalServerError, err.Error())), nil
+	}
+	if inv == nil {
+		return generated.GetResourcePoolResources400ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusBadRequest, "unknown hardware manager: "+request.HwMgrId)), nil
+	}
+	if inv.GetResourcePool(request.ResourcePoolId) == nil {
+		return generated.GetResourcePoolResources400ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusBadRequest, "unknown resource pool: "+request.ResourcePoolId)), nil
+	}
+	return generated.GetResourcePoolResources200JSONResponse(inv.GetResourcePoolResources(request.ResourcePoolId)), nil
 }
 
 func (i *InventoryServer) GetResources(ctx context.Context, request generated.GetResourcesRequestObject) (generated.GetResourcesResponseObject, error) {
-	// TODO implement me
-	return generated.GetResources200JSONResponse([]generated.ResourceInfo{}), nil
+	inv, err := i.getInventory(ctx, request.HwMgrId)
+	if err != nil {
+		return generated.GetResources500ApplicationProblemPlusJSONResponse(problemDetails(http.StatusInternalServerError, err.Error())), nil
+	}
+	if inv == nil {
+		return generated.GetResources400ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusBadRequest, "unknown hardware manager: "+request.HwMgrId)), nil
+	}
+	return generated.GetResources200JSONResponse(inv.Resources), nil
 }
 
 func (i *InventoryServer) GetResource(ctx context.Context, request generated.GetResourceRequestObject) (generated.GetResourceResponseObject, error) {
-	// TODO implement me
-	return generated.GetResource200JSONResponse{}, nil
+	inv, err := i.getInventory(ctx, request.HwMgrId)
+	if err != nil {
+		return generated.GetResource500ApplicationProblemPlusJSONResponse(problemDetails(http.StatusInternalServerError, err.Error())), nil
+	}
+	if inv == nil {
+		return generated.GetResource404ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusNotFound, "unknown hardware manager: "+request.HwMgrId)), nil
+	}
+	resource := inv.GetResource(request.ResourceId)
+	if resource == nil {
+		return generated.GetResource404ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusNotFound, "unknown resource: "+request.ResourceId)), nil
+	}
+	return generated.GetResource200JSONResponse(*resource), nil
+}
+
+func (i *InventoryServer) GetResourcePoolFreeResources(ctx context.Context, request generated.GetResourcePoolFreeResourcesRequestObject) (generated.GetResourcePoolFreeResourcesResponseObject, error) {
+	hwmgr, err := i.getHardwareManager(ctx, request.HwMgrId)
+	if err != nil {
+		return generated.GetResourcePoolFreeResources500ApplicationProblemPlusJSONResponse(problemDetails(http.StatusInternalServerError, err.Error())), nil
+	}
+	if hwmgr == nil {
+		return generated.GetResourcePoolFreeResources400ApplicationProblemPlusJSONResponse(
+			problemDetails(http.StatusBadRequest, "unknown hardware manager: "+request.HwMgrId)), nil
+	}
+
+	query := utils.FreeNodeQuery{
+		ResourcePoolId: request.ResourcePoolId,
+		Selector: &utils.NodeSelector{
+			MinCPUs:      ptr.Deref(request.Params.MinCpus, 0),
+			MinMemoryGiB: ptr.Deref(request.Params.MinMemoryGiB, 0),
+			NICModels:    ptr.Deref(request.Params.NicModels, nil),
+			Locations:    ptr.Deref(request.Params.Locations, nil),
+		},
+		HwProfile: ptr.Deref(request.Params.HwProfile, ""),
+	}
+
+	freenodes, err := i.Adaptor.GetFreeNodes(ctx, hwmgr, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, sdk.ErrNotSupported):
+			return generated.GetResourcePoolFreeResources501ApplicationProblemPlusJSONResponse(
+				problemDetails(http.StatusNotImplemented, "listing free resources is not supported by adaptor "+string(hwmgr.Spec.AdaptorID))), nil
+		case utils.IsInputError(err):
+			return generated.GetResourcePoolFreeResources400ApplicationProblemPlusJSONResponse(
+				problemDetails(http.StatusBadRequest, err.Error())), nil
+		default:
+			return generated.GetResourcePoolFreeResources500ApplicationProblemPlusJSONResponse(problemDetails(http.StatusInternalServerError, err.Error())), nil
+		}
+	}
+
+	resources := []generated.FreeResourceInfo{}
+	for _, freenode := range freenodes {
+		resources = append(resources, freeNodeToResource(freenode))
+	}
+	return generated.GetResourcePoolFreeResources200JSONResponse(resources), nil
+}
+
+// freeNodeToResource translates a free node into a free resource, omitting the attributes not reported by the backend
+func freeNodeToResource(freenode utils.FreeNode) generated.FreeResourceInfo {
+	resource := generated.FreeResourceInfo{
+		ResourceId:     freenode.NodeId,
+		ResourcePoolId: freenode.ResourcePoolId,
+	}
+	if freenode.Attributes.CPUs != 0 {
+		resource.Cpus = &freenode.Attributes.CPUs
+	}
+	if freenode.Attributes.MemoryGiB != 0 {
+		resource.MemoryGiB = &freenode.Attributes.MemoryGiB
+	}
+	if len(freenode.Attributes.NICModels) > 0 {
+		resource.NicModels = &freenode.Attributes.NICModels
+	}
+	if freenode.Attributes.Location != "" {
+		resource.Location = &freenode.Attributes.Location
+	}
+	if freenode.Site != "" {
+		resource.SiteId = &freenode.Site
+	}
+	return resource
 }
@@ -11,6 +11,9 @@ import (
 	"syscall"
 	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/inventory"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
 )
@@ -23,7 +26,7 @@ const (
 )
 
 // RunServer starts the API server and blocks until it terminates or context is canceled.
-func RunServer(ctx context.Context, address string) error {
+func RunServer(ctx context.Context, address string, c client.Client, namespace string, adaptor inventory.AdaptorInventory) error {
 	slog.Info("Starting inventory API server")
 	// Channel for shutdown signals
 	shutdown := make(chan os.Signal, 1)
@@ -40,7 +43,11 @@ func RunServer(ctx context.Context, address string) error {
 
 	// Init server
 	// Create the handler
-	server := api.InventoryServer{}
+	server := api.InventoryServer{
+		Client:    c,
+		Namespace: namespace,
+		Adaptor:   adaptor,
+	}
 
 	serverStrictHandler := generated.NewStrictHandlerWithOptions(&server, nil,
 		generated.StrictHTTPServerOptions{
@@ -0,0 +1,302 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodePoolWebhook defaults and validates NodePool CRs on admission
+type NodePoolWebhook struct {
+	Client    client.Client
+	Logger    *slog.Logger
+	Namespace string
+}
+
+// NodePoolWebhook implements CustomDefaulter and CustomValidator. This ensures that we've conformed to the interfaces
+// with a compile-time check
+var _ admission.CustomDefaulter = (*NodePoolWebhook)(nil)
+var _ admission.CustomValidator = (*NodePoolWebhook)(nil)
+
+//+kubebuilder:webhook:path=/mutate-o2ims-hardwaremanagement-oran-openshift-io-v1alpha1-nodepool,mutating=true,failurePolicy=fail,sideEffects=None,groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=create;update,versions=v1alpha1,name=mnodepool.hwmgr-plugin.oran.openshift.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-o2ims-hardwaremanagement-oran-openshift-io-v1alpha1-nodepool,mutating=false,failurePolicy=fail,sideEffects=None,groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=create;update,versions=v1alpha1,name=vnodepool.hwmgr-plugin.oran.openshift.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the NodePool webhook with the manager
+func (w *NodePoolWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&hwmgmtv1alpha1.NodePool{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete(); err != nil {
+		return fmt.Errorf("failed to setup nodepool webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Default normalizes the resource pool IDs of a NodePool CR. On creation, the HwMgrId is defaulted if there is exactly
+// one HardwareManager whose nodePoolSelector and watch namespaces match the NodePool, and the standard labels are
+// stamped.
+func (w *NodePoolWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	nodepool, ok := obj.(*hwmgmtv1alpha1.NodePool)
+	if !ok {
+		return fmt.Errorf("expected a NodePool object but got %T", obj)
+	}
+
+	ctx = logging.NewReconcileContext(ctx)
+
+	utils.NormalizeNodePoolResourcePools(nodepool)
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get admission request: %w", err)
+	}
+	if req.Operation != admissionv1.Create {
+		return nil
+	}
+
+	if nodepool.Spec.HwMgrId == "" {
+		hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+		if err := w.Client.List(ctx, hwmgrs, client.InNamespace(w.Namespace)); err != nil {
+			return fmt.Errorf("failed to list HardwareManagers: %w", err)
+		}
+
+		var names []string
+		for i := range hwmgrs.Items {
+			if selected, _ := utils.HardwareManagerSelectsNodePool(&hwmgrs.Items[i], nodepool); selected &&
+				utils.HardwareManagerWatchesNodePool(&hwmgrs.Items[i], nodepool) {
+				names = append(names, hwmgrs.Items[i].Name)
+			}
+		}
+
+		if utils.DefaultNodePoolHwMgrId(nodepool, names) {
+			w.Logger.InfoContext(ctx, "Defaulted NodePool HwMgrId",
+				slog.String("nodepool", nodepool.Name),
+				slog.String("hwMgrId", nodepool.Spec.HwMgrId))
+		}
+	}
+
+	utils.SetNodePoolStandardLabels(nodepool)
+
+	return nil
+}
+
+// validate performs the admission checks common to create and update requests
+func (w *NodePoolWebhook) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nodepool, ok := obj.(*hwmgmtv1alpha1.NodePool)
+	if !ok {
+		return nil, fmt.Errorf("expected a NodePool object but got %T", obj)
+	}
+
+	ctx = logging.NewReconcileContext(ctx)
+
+	if err := utils.ValidateNodePoolNodeGroupSizes(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid nodegroup size",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid nodegroup size: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNetworkConfig(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid network configuration",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNodeNameTemplate(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid node name template",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid node naming policy: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolSpareConfig(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid spare node configuration",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid spare node configuration: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolDeletionPolicy(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid deletion policy",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid deletion policy: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNodeSelectors(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid node selector",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid node selector: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolSpreadPolicies(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid spread policy",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid spread policy: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolLocalityPreference(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid locality preference",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid locality preference: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolEnergyPolicy(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid energy policy",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid energy policy: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolAdoptedNodes(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid adopted nodes",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid adopted nodes: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolFulfillmentPolicies(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid fulfillment policy",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid fulfillment policy: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolNodeGroupHwMgrs(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid nodegroup hardware managers",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid nodegroup hardware managers: %w", err)
+	}
+
+	if err := w.validateSplitAdaptors(ctx, nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid nodegroup hardware managers",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	if err := utils.ValidateNodePoolNodeMetadata(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid node metadata",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid node metadata: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolCallbackURL(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid callback URL",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid callback URL: %w", err)
+	}
+
+	if err := utils.ValidateNodePoolProvisioningWindows(nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool with invalid provisioning windows",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid provisioning windows: %w", err)
+	}
+
+	if err := w.validateHwMgrSelector(ctx, nodepool); err != nil {
+		w.Logger.InfoContext(ctx, "Rejecting NodePool not selected by its HardwareManager",
+			slog.String("nodepool", nodepool.Name),
+			slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// validateHwMgrSelector checks that the NodePool matches the nodePoolSelector of the HardwareManager it references.
+// A HardwareManager that does not yet exist is not validated, as it is reported by the controller.
+func (w *NodePoolWebhook) validateHwMgrSelector(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if nodepool.Spec.HwMgrId == "" {
+		return nil
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Name: nodepool.Spec.HwMgrId, Namespace: w.Namespace}, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get HardwareManager %s: %w", nodepool.Spec.HwMgrId, err)
+	}
+
+	selected, err := utils.HardwareManagerSelectsNodePool(hwmgr, nodepool)
+	if err != nil {
+		return err
+	}
+	if !selected {
+		return fmt.Errorf("NodePool labels do not match the nodePoolSelector of HardwareManager %s", hwmgr.Name)
+	}
+
+	return nil
+}
+
+// validateSplitAdaptors checks that a NodePool split across HardwareManagers is not served by more than one loopback
+// HardwareManager
+func (w *NodePoolWebhook) validateSplitAdaptors(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if !utils.IsSplitNodePool(nodepool) {
+		return nil
+	}
+
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := w.Client.List(ctx, hwmgrs, client.InNamespace(w.Namespace)); err != nil {
+		return fmt.Errorf("failed to list HardwareManagers: %w", err)
+	}
+
+	if err := utils.ValidateSplitNodePoolAdaptors(nodepool, hwmgrs.Items); err != nil {
+		return fmt.Errorf("invalid nodegroup hardware managers: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateCreate validates a new NodePool CR
+func (w *NodePoolWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(ctx, obj)
+}
+
+// ValidateUpdate validates an updated NodePool CR
+func (w *NodePoolWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return w.validate(ctx, newObj)
+}
+
+// ValidateDelete allows all NodePool deletions
+func (w *NodePoolWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,418 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fixtures loads declarative adaptor test cases from YAML. Each case describes the HardwareManager, the
+// inventory ConfigMap and the NodePools to create, along with the outcome expected once the adaptor has reconciled
+// them, so that table-driven envtest suites can add a regression test by adding a file.
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	hwmgrpluginoranopenshiftiov1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	imsv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultNamespace is applied to any fixture object that does not specify a namespace
+const DefaultNamespace = "default"
+
+// Case is a single declarative test case
+type Case struct {
+	// Name identifies the case in the test report, defaulting to the file name
+	Name string `json:"name,omitempty"`
+
+	// Description explains what the case covers
+	Description string `json:"description,omitempty"`
+
+	// HardwareManager is the hardware manager the NodePools are allocated from
+	HardwareManager *hwmgrpluginoranopenshiftiov1alpha1.HardwareManager `json:"hardwareManager"`
+
+	// Inventory is the ConfigMap describing the available resources, as used by the loopback adaptor
+	Inventory *corev1.ConfigMap `json:"inventory,omitempty"`
+
+	// NodePools are created, in order, once the HardwareManager and inventory exist
+	NodePools []imsv1alpha1.NodePool `json:"nodePools"`
+
+	// Expected is the outcome to verify once the NodePools have been reconciled
+	Expected Expected `json:"expected"`
+
+	// File is the path the case was loaded from
+	File string `json:"-"`
+}
+
+// Expected describes the state the cluster must reach for a case to pass
+type Expected struct {
+	NodePools []ExpectedNodePool `json:"nodePools,omitempty"`
+	Nodes     []ExpectedNode     `json:"nodes,omitempty"`
+}
+
+// ExpectedNodePool describes the expected status of a NodePool
+type ExpectedNodePool struct {
+	Name string `json:"name"`
+
+	// Conditions that must be present on the NodePool
+	Conditions []ExpectedCondition `json:"conditions,omitempty"`
+
+	// NodeCount, if set, is the number of nodes that must be allocated to the NodePool
+	NodeCount *int `json:"nodeCount,omitempty"`
+}
+
+// ExpectedCondition describes a NodePool condition. An empty Status or Reason is not checked.
+type ExpectedCondition struct {
+	Type   string                 `json:"type"`
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+	Reason string                 `json:"reason,omitempty"`
+}
+
+// ExpectedNode describes a Node that must be allocated. Empty fields other than HwMgrNodeId are not checked.
+type ExpectedNode struct {
+	HwMgrNodeId string `json:"hwMgrNodeId"`
+	NodePool    string `json:"nodePool,omitempty"`
+	GroupName   string `json:"groupName,omitempty"`
+	HwProfile   string `json:"hwProfile,omitempty"`
+}
+
+// Load reads all cases matching the glob pattern from fsys, sorted by file name
+func Load(fsys fs.FS, pattern string) ([]Case, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture pattern %s: %w", pattern, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures match %s", pattern)
+	}
+
+	cases := make([]Case, 0, len(files))
+	for _, file := range files {
+		tc, err := LoadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, *tc)
+	}
+
+	return cases, nil
+}
+
+// LoadFile reads a single case from fsys. Unknown fields are rejected so that typos in a fixture fail loudly rather
+// than silently weakening the test.
+func LoadFile(fsys fs.FS, file string) (*Case, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", file, err)
+	}
+
+	tc := &Case{}
+	if err := yaml.UnmarshalStrict(data, tc); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", file, err)
+	}
+	tc.File = file
+	if tc.Name == "" {
+		tc.Name = strings.TrimSuffix(path.Base(file), path.Ext(file))
+	}
+
+	if err := tc.validate(); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", file, err)
+	}
+	tc.setDefaults()
+
+	return tc, nil
+}
+
+func (tc *Case) validate() error {
+	if tc.HardwareManager == nil {
+		return errors.New("hardwareManager is required")
+	}
+	if len(tc.NodePools) == 0 {
+		return errors.New("at least one nodePool is required")
+	}
+
+	names := make(map[string]bool)
+	for _, np := range tc.NodePools {
+		if np.Name == "" {
+			return errors.New("nodePool name is required")
+		}
+		names[np.Name] = true
+	}
+	for _, expected := range tc.Expected.NodePools {
+		if !names[expected.Name] {
+			return fmt.Errorf("expected nodePool %s is not defined by the fixture", expected.Name)
+		}
+	}
+	for _, expected := range tc.Expected.Nodes {
+		if expected.HwMgrNodeId == "" {
+			return errors.New("expected node hwMgrNodeId is required")
+		}
+	}
+
+	return nil
+}
+
+func (tc *Case) setDefaults() {
+	for _, obj := range tc.Objects() {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(DefaultNamespace)
+		}
+	}
+	for i := range tc.NodePools {
+		if tc.NodePools[i].Spec.HwMgrId == "" {
+			tc.NodePools[i].Spec.HwMgrId = tc.HardwareManager.Name
+		}
+	}
+}
+
+// Objects returns the objects of the case in creation order
+func (tc *Case) Objects() []client.Object {
+	var objs []client.Object
+	if tc.Inventory != nil {
+		objs = append(objs, tc.Inventory)
+	}
+	objs = append(objs, tc.HardwareManager)
+	for i := range tc.NodePools {
+		objs = append(objs, &tc.NodePools[i])
+	}
+	return objs
+}
+
+// Create creates the objects of the case. Fresh copies are created, so the case can be run repeatedly.
+func (tc *Case) Create(ctx context.Context, c client.Client) error {
+	for _, obj := range tc.Objects() {
+		obj = obj.DeepCopyObject().(client.Object)
+		if err := c.Create(ctx, obj); err != nil {
+			return fmt.Errorf("failed to create %T %s: %w", obj, obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// Delete removes the objects of the case, along with the Nodes and secrets created for its NodePools, in reverse
+// creation order. Envtest does not run garbage collection, so the children are removed here to keep them from
+// leaking into later cases. Objects that no longer exist are ignored.
+func (tc *Case) Delete(ctx context.Context, c client.Client) error {
+	var errs []error
+
+	var children []client.Object
+	for i := range tc.NodePools {
+		owned, err := tc.getChildren(ctx, c, &tc.NodePools[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		children = append(children, owned...)
+	}
+
+	objs := tc.Objects()
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i].DeepCopyObject().(client.Object)
+		if err := c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %T %s: %w", obj, obj.GetName(), err))
+		}
+	}
+
+	for _, obj := range children {
+		if err := c.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %T %s: %w", obj, obj.GetName(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// getChildren returns the Nodes allocated to the NodePool and the secrets it owns
+func (tc *Case) getChildren(ctx context.Context, c client.Client, nodepool *imsv1alpha1.NodePool) ([]client.Object, error) {
+	var children []client.Object
+
+	nodes, err := tc.getNodes(ctx, c, nodepool)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes {
+		children = append(children, &nodes[i])
+	}
+
+	uid, err := getNodePoolUID(ctx, c, nodepool)
+	if err != nil || uid == "" {
+		return children, err
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, client.InNamespace(nodepool.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for i := range secrets.Items {
+		if isOwnedBy(&secrets.Items[i], uid) {
+			children = append(children, &secrets.Items[i])
+		}
+	}
+
+	return children, nil
+}
+
+// VerifyDeleted returns an error if any object of the case still exists, such as a NodePool held by its finalizer,
+// so that a suite can wait for a case to be cleaned up before creating the next.
+func (tc *Case) VerifyDeleted(ctx context.Context, c client.Client) error {
+	for _, obj := range tc.Objects() {
+		obj = obj.DeepCopyObject().(client.Object)
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to get %T %s: %w", obj, obj.GetName(), err)
+		} else if err == nil {
+			return fmt.Errorf("%T %s still exists", obj, obj.GetName())
+		}
+	}
+	return nil
+}
+
+// Verify checks the cluster against the expected outcome of the case, returning an error describing the first
+// mismatch. It is intended to be polled, for example with gomega's Eventually.
+func (tc *Case) Verify(ctx context.Context, c client.Client) error {
+	for _, expected := range tc.Expected.NodePools {
+		if err := tc.verifyNodePool(ctx, c, expected); err != nil {
+			return err
+		}
+	}
+
+	if len(tc.Expected.Nodes) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]imsv1alpha1.Node)
+	for i := range tc.NodePools {
+		nodelist, err := tc.getNodes(ctx, c, &tc.NodePools[i])
+		if err != nil {
+			return err
+		}
+		for _, node := range nodelist {
+			nodes[node.Spec.HwMgrNodeId] = node
+		}
+	}
+
+	for _, expected := range tc.Expected.Nodes {
+		node, exists := nodes[expected.HwMgrNodeId]
+		if !exists {
+			return fmt.Errorf("node %s has not been allocated", expected.HwMgrNodeId)
+		}
+		if expected.NodePool != "" && node.Spec.NodePool != expected.NodePool {
+			return fmt.Errorf("node %s: expected nodePool %s, got %s", expected.HwMgrNodeId, expected.NodePool, node.Spec.NodePool)
+		}
+		if expected.GroupName != "" && node.Spec.GroupName != expected.GroupName {
+			return fmt.Errorf("node %s: expected groupName %s, got %s", expected.HwMgrNodeId, expected.GroupName, node.Spec.GroupName)
+		}
+		if expected.HwProfile != "" && node.Spec.HwProfile != expected.HwProfile {
+			return fmt.Errorf("node %s: expected hwProfile %s, got %s", expected.HwMgrNodeId, expected.HwProfile, node.Spec.HwProfile)
+		}
+	}
+
+	return nil
+}
+
+func (tc *Case) verifyNodePool(ctx context.Context, c client.Client, expected ExpectedNodePool) error {
+	var nodepool *imsv1alpha1.NodePool
+	for i := range tc.NodePools {
+		if tc.NodePools[i].Name == expected.Name {
+			nodepool = &tc.NodePools[i]
+			break
+		}
+	}
+
+	current := &imsv1alpha1.NodePool{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), current); err != nil {
+		return fmt.Errorf("failed to get NodePool %s: %w", expected.Name, err)
+	}
+
+	for _, cond := range expected.Conditions {
+		found := meta.FindStatusCondition(current.Status.Conditions, cond.Type)
+		if found == nil {
+			return fmt.Errorf("NodePool %s: condition %s not found", expected.Name, cond.Type)
+		}
+		if cond.Status != "" && found.Status != cond.Status {
+			return fmt.Errorf("NodePool %s: expected condition %s status %s, got %s (%s)",
+				expected.Name, cond.Type, cond.Status, found.Status, found.Message)
+		}
+		if cond.Reason != "" && found.Reason != cond.Reason {
+			return fmt.Errorf("NodePool %s: expected condition %s reason %s, got %s (%s)",
+				expected.Name, cond.Type, cond.Reason, found.Reason, found.Message)
+		}
+	}
+
+	if expected.NodeCount != nil {
+		nodes, err := tc.getNodes(ctx, c, nodepool)
+		if err != nil {
+			return err
+		}
+		if len(nodes) != *expected.NodeCount {
+			return fmt.Errorf("NodePool %s: expected %d nodes, got %d", expected.Name, *expected.NodeCount, len(nodes))
+		}
+	}
+
+	return nil
+}
+
+// getNodes lists the Nodes allocated to the NodePool. The envtest client is uncached, so the nodes are filtered here
+// rather than through a field index. Nodes left behind by an earlier NodePool of the same name are excluded by
+// checking the owner reference.
+func (tc *Case) getNodes(ctx context.Context, c client.Client, nodepool *imsv1alpha1.NodePool) ([]imsv1alpha1.Node, error) {
+	uid, err := getNodePoolUID(ctx, c, nodepool)
+	if err != nil {
+		return nil, err
+	}
+
+	nodelist := &imsv1alpha1.NodeList{}
+	if err := c.List(ctx, nodelist, client.InNamespace(nodepool.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var nodes []imsv1alpha1.Node
+	for _, node := range nodelist.Items {
+		if node.Spec.NodePool != nodepool.Name || (uid != "" && !isOwnedBy(&node, uid)) {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// getNodePoolUID returns the UID of the NodePool, or an empty UID if it does not exist
+func getNodePoolUID(ctx context.Context, c client.Client, nodepool *imsv1alpha1.NodePool) (types.UID, error) {
+	current := &imsv1alpha1.NodePool{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), current); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get NodePool %s: %w", nodepool.Name, err)
+	}
+	return current.UID, nil
+}
+
+func isOwnedBy(obj metav1.Object, uid types.UID) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
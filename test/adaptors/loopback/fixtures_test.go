@@ -0,0 +1,60 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+//nolint:all
+package loopback
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift-kni/oran-hwmgr-plugin/test/adaptors/fixtures"
+)
+
+// cases are loaded while building the spec tree, so each file under testdata/cases becomes a table entry
+var cases = func() []TableEntry {
+	loaded, err := fixtures.Load(os.DirFS("testdata"), "cases/*.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	entries := make([]TableEntry, 0, len(loaded))
+	for i := range loaded {
+		entries = append(entries, Entry(loaded[i].Name, &loaded[i]))
+	}
+	return entries
+}()
+
+var _ = Describe("reconcile fixtures via the loopback adaptor", func() {
+	ctx := context.Background()
+	timeout, interval := 30, 1
+
+	DescribeTable("must reach the expected outcome",
+		func(tc *fixtures.Case) {
+			By(tc.Description)
+
+			Expect(tc.Create(ctx, k8sClient)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(tc.Delete(ctx, k8sClient)).To(Succeed())
+				Eventually(func() error { return tc.VerifyDeleted(ctx, k8sClient) }, timeout, interval).Should(Succeed())
+			})
+
+			Eventually(func() error { return tc.Verify(ctx, k8sClient) }, timeout, interval).Should(Succeed())
+		},
+		cases,
+	)
+})
@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//nolint:all
+package scale
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	masterPoolId = "scale-master"
+	workerPoolId = "scale-worker"
+
+	// inventoryHeadroom is the percentage of nodes generated in each resource pool beyond those needed by the test
+	inventoryHeadroom = 10
+)
+
+// The inventory and allocations of the loopback nodelist configmap, limited to the fields used by the test
+type inventoryBMC struct {
+	Address        string `json:"address"`
+	UsernameBase64 string `json:"username-base64"`
+	PasswordBase64 string `json:"password-base64"`
+}
+
+type inventoryInterface struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	MACAddress string `json:"macAddress"`
+}
+
+type inventoryNode struct {
+	PoolID     string               `json:"poolID"`
+	BMC        inventoryBMC         `json:"bmc"`
+	Interfaces []inventoryInterface `json:"interfaces"`
+}
+
+type inventory struct {
+	ResourcePools []string                 `json:"resourcepools"`
+	Nodes         map[string]inventoryNode `json:"nodes"`
+}
+
+type allocatedCloud struct {
+	CloudID    string              `json:"cloudID"`
+	Nodegroups map[string][]string `json:"nodegroups"`
+	Pending    map[string]string   `json:"pending,omitempty"`
+}
+
+type allocations struct {
+	Clouds []allocatedCloud `json:"clouds"`
+}
+
+// poolSize returns the number of nodes to generate for a resource pool that must provide the given number of nodes
+func poolSize(required int) int {
+	return required + max(1, required*inventoryHeadroom/100)
+}
+
+// generateInventory builds a loopback nodelist configmap with enough nodes in the master and worker resource pools for
+// the given number of masters and workers
+func generateInventory(masters, workers int) (*corev1.ConfigMap, error) {
+	inv := inventory{
+		ResourcePools: []string{masterPoolId, workerPoolId},
+		Nodes:         make(map[string]inventoryNode),
+	}
+
+	index := 0
+	for _, pool := range []struct {
+		id    string
+		count int
+	}{{masterPoolId, poolSize(masters)}, {workerPoolId, poolSize(workers)}} {
+		for i := 0; i < pool.count; i++ {
+			inv.Nodes[fmt.Sprintf("%s-%04d", pool.id, i)] = inventoryNode{
+				PoolID: pool.id,
+				BMC: inventoryBMC{
+					Address:        fmt.Sprintf("idrac-virtualmedia+https://10.%d.%d.%d/redfish/v1/Systems/System.Embedded.1", index>>16&0xff, index>>8&0xff, index&0xff),
+					UsernameBase64: "YWRtaW4=",
+					PasswordBase64: "bXlwYXNz",
+				},
+				Interfaces: []inventoryInterface{{
+					Name:       "eth0",
+					Label:      "bootable-interface",
+					MACAddress: fmt.Sprintf("c6:b6:13:%02x:%02x:%02x", index>>16&0xff, index>>8&0xff, index&0xff),
+				}},
+			}
+			index++
+		}
+	}
+
+	data, err := yaml.Marshal(inv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal inventory: %w", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "loopback-adaptor-nodelist",
+			Namespace: "default",
+		},
+		Data: map[string]string{
+			"resources": string(data),
+		},
+	}, nil
+}
+
+// parseAllocations parses the allocations recorded in the loopback nodelist configmap
+func parseAllocations(cm *corev1.ConfigMap) (*allocations, error) {
+	result := &allocations{}
+	if err := yaml.Unmarshal([]byte(cm.Data["allocations"]), result); err != nil {
+		return nil, fmt.Errorf("failed to parse allocations: %w", err)
+	}
+	return result, nil
+}
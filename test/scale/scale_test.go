@@ -0,0 +1,258 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//nolint:all
+package scale
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	hwmgrpluginoranopenshiftiov1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	imsv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	scaleHwMgrId   = "loopback-scale"
+	pollInterval   = 500 * time.Millisecond
+	masterGroup    = "controller"
+	workerGroup    = "worker"
+	scaleHwProfile = "profile-spr-single-processor-64G"
+)
+
+// latencyStats summarizes the latencies of the NodePools
+type latencyStats struct {
+	P50, P90, P99, Max time.Duration
+}
+
+func newLatencyStats(latencies []time.Duration) latencyStats {
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	percentile := func(p int) time.Duration {
+		return sorted[min(len(sorted)-1, len(sorted)*p/100)]
+	}
+	return latencyStats{P50: percentile(50), P90: percentile(90), P99: percentile(99), Max: sorted[len(sorted)-1]}
+}
+
+func newScaleNodePool(index int) *imsv1alpha1.NodePool {
+	return &imsv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("np-scale-%04d", index),
+			Namespace: "default",
+		},
+		Spec: imsv1alpha1.NodePoolSpec{
+			CloudID: fmt.Sprintf("scale-cloud-%04d", index),
+			HwMgrId: scaleHwMgrId,
+			LocationSpec: imsv1alpha1.LocationSpec{
+				Location: "ottawa",
+				Site:     "building-1",
+			},
+			NodeGroup: []imsv1alpha1.NodeGroup{
+				{
+					NodePoolData: imsv1alpha1.NodePoolData{
+						Name:           masterGroup,
+						Role:           "master",
+						HwProfile:      scaleHwProfile,
+						ResourcePoolId: masterPoolId,
+					},
+					Size: 1,
+				},
+				{
+					NodePoolData: imsv1alpha1.NodePoolData{
+						Name:           workerGroup,
+						Role:           "worker",
+						HwProfile:      scaleHwProfile,
+						ResourcePoolId: workerPoolId,
+					},
+					Size: scaleWorkers,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("scale via the loopback adaptor", Ordered, func() {
+	var (
+		cm        *corev1.ConfigMap
+		hwmgr     *hwmgrpluginoranopenshiftiov1alpha1.HardwareManager
+		nodepools []*imsv1alpha1.NodePool
+	)
+
+	BeforeAll(func() {
+		By(fmt.Sprintf("generating an inventory for %d NodePools of %d nodes", scaleNodePools, 1+scaleWorkers))
+		var err error
+		cm, err = generateInventory(scaleNodePools, scaleNodePools*scaleWorkers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+		hwmgr = &hwmgrpluginoranopenshiftiov1alpha1.HardwareManager{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      scaleHwMgrId,
+				Namespace: "default",
+			},
+			Spec: hwmgrpluginoranopenshiftiov1alpha1.HardwareManagerSpec{
+				AdaptorID:    hwmgrpluginoranopenshiftiov1alpha1.SupportedAdaptors.Loopback,
+				LoopbackData: &hwmgrpluginoranopenshiftiov1alpha1.LoopbackData{},
+			},
+		}
+		Expect(k8sClient.Create(ctx, hwmgr)).To(Succeed())
+	})
+
+	AfterAll(func() {
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, cm))).To(Succeed())
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, hwmgr))).To(Succeed())
+	})
+
+	It("must provision the NodePools within the latency and throughput bounds", func() {
+		created := make(map[string]time.Time)
+		start := time.Now()
+		for i := 0; i < scaleNodePools; i++ {
+			nodepool := newScaleNodePool(i)
+			created[nodepool.Name] = time.Now()
+			Expect(k8sClient.Create(ctx, nodepool)).To(Succeed())
+			nodepools = append(nodepools, nodepool)
+		}
+
+		By("waiting for the NodePools to be provisioned")
+		latencies := make(map[string]time.Duration)
+		Eventually(func(g Gomega) {
+			list := &imsv1alpha1.NodePoolList{}
+			g.Expect(k8sClient.List(ctx, list, client.InNamespace("default"))).To(Succeed())
+			now := time.Now()
+			for i := range list.Items {
+				nodepool := &list.Items[i]
+				if _, done := latencies[nodepool.Name]; done {
+					continue
+				}
+				Expect(utils.IsNodePoolProvisionedFailed(nodepool)).To(BeFalse(), "NodePool %s failed to provision", nodepool.Name)
+				if utils.IsNodePoolProvisionedCompleted(nodepool) {
+					latencies[nodepool.Name] = now.Sub(created[nodepool.Name])
+				}
+			}
+			g.Expect(latencies).To(HaveLen(scaleNodePools))
+		}, scaleTimeout, pollInterval).Should(Succeed())
+
+		elapsed := time.Since(start)
+		throughput := float64(scaleNodePools) / elapsed.Seconds()
+		var values []time.Duration
+		for _, latency := range latencies {
+			values = append(values, latency)
+		}
+		stats := newLatencyStats(values)
+
+		AddReportEntry("allocation", ReportEntryVisibilityAlways, fmt.Sprintf(
+			"%d NodePools provisioned in %s: %.2f NodePools/s, latency p50 %s, p90 %s, p99 %s, max %s",
+			scaleNodePools, elapsed.Round(time.Millisecond), throughput,
+			stats.P50.Round(time.Millisecond), stats.P90.Round(time.Millisecond),
+			stats.P99.Round(time.Millisecond), stats.Max.Round(time.Millisecond)))
+
+		Expect(stats.P99).To(BeNumerically("<=", scaleMaxLatency), "p99 allocation latency exceeds the bound")
+		Expect(throughput).To(BeNumerically(">=", scaleMinThroughput), "allocation throughput is below the bound")
+	})
+
+	It("must keep the bookkeeping consistent", func() {
+		verifyBookkeeping(nodepools)
+	})
+
+	It("must release the NodePools", func() {
+		start := time.Now()
+		for _, nodepool := range nodepools {
+			Expect(k8sClient.Delete(ctx, nodepool)).To(Succeed())
+		}
+
+		By("waiting for the allocations to be released")
+		Eventually(func(g Gomega) {
+			list := &imsv1alpha1.NodePoolList{}
+			g.Expect(k8sClient.List(ctx, list, client.InNamespace("default"))).To(Succeed())
+			g.Expect(list.Items).To(BeEmpty())
+
+			latest := &corev1.ConfigMap{}
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cm), latest)).To(Succeed())
+			allocated, err := parseAllocations(latest)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(allocated.Clouds).To(BeEmpty())
+		}, scaleTimeout, pollInterval).Should(Succeed())
+
+		elapsed := time.Since(start)
+		AddReportEntry("release", ReportEntryVisibilityAlways, fmt.Sprintf(
+			"%d NodePools released in %s: %.2f NodePools/s",
+			scaleNodePools, elapsed.Round(time.Millisecond), float64(scaleNodePools)/elapsed.Seconds()))
+	})
+})
+
+// verifyBookkeeping checks that the Nodes, bmc-secrets and allocations of the NodePools agree with each other and with
+// the inventory, with each node allocated exactly once
+func verifyBookkeeping(nodepools []*imsv1alpha1.NodePool) {
+	latest := &corev1.ConfigMap{}
+	Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "loopback-adaptor-nodelist", Namespace: "default"}, latest)).To(Succeed())
+	allocated, err := parseAllocations(latest)
+	Expect(err).NotTo(HaveOccurred())
+
+	clouds := make(map[string]allocatedCloud)
+	for _, cloud := range allocated.Clouds {
+		Expect(clouds).NotTo(HaveKey(cloud.CloudID), "cloud %s is allocated more than once", cloud.CloudID)
+		Expect(cloud.Pending).To(BeEmpty(), "cloud %s has pending claims", cloud.CloudID)
+		clouds[cloud.CloudID] = cloud
+	}
+	Expect(clouds).To(HaveLen(len(nodepools)))
+
+	nodelist := &imsv1alpha1.NodeList{}
+	Expect(k8sClient.List(ctx, nodelist, client.InNamespace("default"))).To(Succeed())
+	Expect(nodelist.Items).To(HaveLen(len(nodepools) * (1 + scaleWorkers)))
+
+	owners := make(map[string]string)
+	groups := make(map[string]map[string][]string)
+	for _, node := range nodelist.Items {
+		owner, duplicate := owners[node.Spec.HwMgrNodeId]
+		Expect(duplicate).To(BeFalse(), "node %s is allocated to both %s and %s", node.Spec.HwMgrNodeId, owner, node.Spec.NodePool)
+		owners[node.Spec.HwMgrNodeId] = node.Spec.NodePool
+
+		poolId := masterPoolId
+		if node.Spec.GroupName == workerGroup {
+			poolId = workerPoolId
+		}
+		Expect(node.Spec.HwMgrNodeId).To(HavePrefix(poolId), "node %s is allocated from the wrong resource pool", node.Name)
+
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: utils.BMCSecretName(node.Name), Namespace: node.Namespace}, secret)).
+			To(Succeed(), "node %s has no bmc-secret", node.Name)
+
+		if groups[node.Spec.NodePool] == nil {
+			groups[node.Spec.NodePool] = make(map[string][]string)
+		}
+		groups[node.Spec.NodePool][node.Spec.GroupName] = append(groups[node.Spec.NodePool][node.Spec.GroupName], node.Name)
+	}
+
+	for _, nodepool := range nodepools {
+		cloud, exists := clouds[nodepool.Spec.CloudID]
+		Expect(exists).To(BeTrue(), "NodePool %s has no allocation", nodepool.Name)
+		for _, nodegroup := range nodepool.Spec.NodeGroup {
+			groupname := nodegroup.NodePoolData.Name
+			Expect(groups[nodepool.Name][groupname]).To(HaveLen(nodegroup.Size),
+				"nodegroup %s of NodePool %s has the wrong number of nodes", groupname, nodepool.Name)
+			Expect(cloud.Nodegroups[groupname]).To(ConsistOf(groups[nodepool.Name][groupname]),
+				"allocation of nodegroup %s of NodePool %s does not match its Nodes", groupname, nodepool.Name)
+		}
+	}
+}